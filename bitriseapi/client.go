@@ -12,7 +12,8 @@ import (
 	"time"
 )
 
-// defaultTimeout caps a single API request (connect through full body read).
+// defaultTimeout caps a single API request (connect through full body read),
+// including any retries the transport makes and the waits between them.
 // It applies to every ordinary call; the long-lived log-streaming path opts
 // out via streamHTTPClient and relies on the caller's context instead.
 const defaultTimeout = 30 * time.Second
@@ -32,6 +33,12 @@ func WithHTTPClient(hc *http.Client) Option {
 	return func(c *Client) { c.httpClient = hc }
 }
 
+// WithTransport sets the RoundTripper of the client's HTTP client, keeping
+// its timeout. The CLI passes its retrying transport here.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) { c.httpClient.Transport = rt }
+}
+
 // New creates a Client authenticated with the given token and base URL.
 func New(baseURL, token string, opts ...Option) *Client {
 	c := &Client{
@@ -157,25 +164,30 @@ func (c *Client) do(req *http.Request) ([]byte, error) {
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		var e errorBody
-		_ = json.Unmarshal(body, &e)
-		msg := e.pick()
-		apiErr := &APIError{
-			StatusCode:  resp.StatusCode,
-			Message:     msg,
-			RequestInfo: req.Method + " " + req.URL.RequestURI(),
-		}
-		if msg == "" {
-			// No structured field — keep the raw body so the user has
-			// something concrete to see (e.g. an unmarshalable Rails 500
-			// HTML page or an undocumented error shape).
-			apiErr.Body = strings.TrimSpace(string(body))
-		}
-		return nil, apiErr
+		return nil, newAPIError(resp.StatusCode, body, req.Method+" "+req.URL.RequestURI())
 	}
 	return body, nil
 }
 
+// newAPIError builds the *APIError for a non-2xx response.
+func newAPIError(statusCode int, body []byte, requestInfo string) *APIError {
+	var e errorBody
+	_ = json.Unmarshal(body, &e)
+	msg := e.pick()
+	apiErr := &APIError{
+		StatusCode:  statusCode,
+		Message:     msg,
+		RequestInfo: requestInfo,
+	}
+	if msg == "" {
+		// No structured field — keep the raw body so the user has
+		// something concrete to see (e.g. an unmarshalable Rails 500
+		// HTML page or an undocumented error shape).
+		apiErr.Body = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
+
 // get performs a GET request and decodes the "data" field into T.
 func get[T any](ctx context.Context, c *Client, path string, params url.Values) (T, error) {
 	var zero T
@@ -249,3 +261,14 @@ func postDecode[Req, Resp any](ctx context.Context, c *Client, path string, body
 	}
 	return resp, nil
 }
+
+// del performs a DELETE request on path; the response body is discarded.
+func del(ctx context.Context, c *Client, path string) error {
+	req, err := c.newRequest(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Method = http.MethodDelete
+	_, err = c.do(req)
+	return err
+}
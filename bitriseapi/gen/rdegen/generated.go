@@ -0,0 +1,835 @@
+// Code generated by tools/genapi from tools/genapi/specs/rde.json; DO NOT EDIT.
+
+// Package rdegen holds the models and client methods generated from the
+// Remote Dev Environments API swagger document (Remote Dev Environments API 1.0).
+package rdegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
+)
+
+// Client calls the generated endpoints through the hand-written client's
+// authenticated RawRequest, so base URL, auth, retries, and caching are
+// shared with the rest of the CLI.
+type Client struct {
+	raw *rde.Client
+}
+
+// New returns a Client sending its requests through raw.
+func New(raw *rde.Client) *Client {
+	return &Client{raw: raw}
+}
+
+// do sends in (if non-nil) as the JSON body and decodes a 2xx response into
+// out (if non-nil). A non-2xx response is returned as the hand-written
+// client's API error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var (
+		body   io.Reader
+		header http.Header
+	)
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body, header = bytes.NewReader(data), http.Header{"Content-Type": {"application/json"}}
+	}
+	resp, err := c.raw.RawRequest(ctx, method, path, query, header, body)
+	if err != nil {
+		return err
+	}
+	if err := resp.Err(); err != nil {
+		return err
+	}
+	if out == nil || len(resp.Body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Body, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// ListSavedInputs calls GET /v1/saved-inputs.
+//
+// List the caller's saved inputs
+func (c *Client) ListSavedInputs(ctx context.Context) (*ListSavedInputsResponse, error) {
+	var out ListSavedInputsResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/saved-inputs", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateSavedInput calls POST /v1/saved-inputs.
+//
+// Create a saved input
+func (c *Client) CreateSavedInput(ctx context.Context, body *CreateSavedInputRequest) (*SavedInputResponse, error) {
+	var out SavedInputResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/saved-inputs", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteSavedInput calls DELETE /v1/saved-inputs/{savedInputId}.
+//
+// Delete a saved input
+func (c *Client) DeleteSavedInput(ctx context.Context, savedInputID string) (*Empty, error) {
+	var out Empty
+	if err := c.do(ctx, http.MethodDelete, "/v1/saved-inputs/"+url.PathEscape(savedInputID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSavedInput calls GET /v1/saved-inputs/{savedInputId}.
+//
+// Get a saved input
+func (c *Client) GetSavedInput(ctx context.Context, savedInputID string) (*SavedInputResponse, error) {
+	var out SavedInputResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/saved-inputs/"+url.PathEscape(savedInputID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateSavedInput calls PATCH /v1/saved-inputs/{savedInputId}.
+//
+// Update a saved input
+func (c *Client) UpdateSavedInput(ctx context.Context, savedInputID string, body *UpdateSavedInputRequest) (*SavedInputResponse, error) {
+	var out SavedInputResponse
+	if err := c.do(ctx, http.MethodPatch, "/v1/saved-inputs/"+url.PathEscape(savedInputID), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListMachineTypes calls GET /v1/workspaces/{workspaceId}/machine-types.
+//
+// List the machine types sessions can run on
+func (c *Client) ListMachineTypes(ctx context.Context, workspaceID string) (*ListMachineTypesResponse, error) {
+	var out ListMachineTypesResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/machine-types", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSessions calls GET /v1/workspaces/{workspaceId}/sessions.
+//
+// List the caller's sessions in the workspace
+func (c *Client) ListSessions(ctx context.Context, workspaceID string, params ListSessionsParams) (*ListSessionsResponse, error) {
+	var out ListSessionsResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateSession calls POST /v1/workspaces/{workspaceId}/sessions.
+//
+// Create a session
+func (c *Client) CreateSession(ctx context.Context, workspaceID string, body *CreateSessionRequest) (*CreateSessionResponse, error) {
+	var out CreateSessionResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteSession calls DELETE /v1/workspaces/{workspaceId}/sessions/{sessionId}.
+//
+// Delete a session
+func (c *Client) DeleteSession(ctx context.Context, workspaceID string, sessionID string) (*Empty, error) {
+	var out Empty
+	if err := c.do(ctx, http.MethodDelete, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSession calls GET /v1/workspaces/{workspaceId}/sessions/{sessionId}.
+//
+// Get a session
+func (c *Client) GetSession(ctx context.Context, workspaceID string, sessionID string) (*SessionResponse, error) {
+	var out SessionResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateSession calls PATCH /v1/workspaces/{workspaceId}/sessions/{sessionId}.
+//
+// Update a session's name, description, auto-terminate minutes or labels
+func (c *Client) UpdateSession(ctx context.Context, workspaceID string, sessionID string, body *UpdateSessionRequest) (*SessionResponse, error) {
+	var out SessionResponse
+	if err := c.do(ctx, http.MethodPatch, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SessionCompleteFileUpload calls POST /v1/workspaces/{workspaceId}/sessions/{sessionId}/complete-file-upload.
+//
+// Extract an uploaded archive on the session
+func (c *Client) SessionCompleteFileUpload(ctx context.Context, workspaceID string, sessionID string, body *CompleteFileUploadRequest) (*Empty, error) {
+	var out Empty
+	if err := c.do(ctx, http.MethodPost, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID)+"/complete-file-upload", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SessionDownloadFile calls POST /v1/workspaces/{workspaceId}/sessions/{sessionId}/download-file.
+//
+// Archive a path on the session and get a signed URL to download it
+func (c *Client) SessionDownloadFile(ctx context.Context, workspaceID string, sessionID string, body *DownloadFileRequest) (*DownloadFileResponse, error) {
+	var out DownloadFileResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID)+"/download-file", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StreamSessionLogs calls GET /v1/workspaces/{workspaceId}/sessions/{sessionId}/logs/{stage}.
+//
+// Stream a session's startup or warmup log
+func (c *Client) StreamSessionLogs(ctx context.Context, workspaceID string, sessionID string, stage string) (*StreamSessionLogsResponse, error) {
+	var out StreamSessionLogsResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID)+"/logs/"+url.PathEscape(stage), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSessionNotifications calls GET /v1/workspaces/{workspaceId}/sessions/{sessionId}/notifications.
+//
+// List a session's notifications
+func (c *Client) ListSessionNotifications(ctx context.Context, workspaceID string, sessionID string, params ListSessionNotificationsParams) (*ListSessionNotificationsResponse, error) {
+	var out ListSessionNotificationsResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID)+"/notifications", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RestoreSession calls POST /v1/workspaces/{workspaceId}/sessions/{sessionId}/restore.
+//
+// Restore a terminated session
+func (c *Client) RestoreSession(ctx context.Context, workspaceID string, sessionID string, body *Empty) (*SessionResponse, error) {
+	var out SessionResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID)+"/restore", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SessionStartFileUpload calls POST /v1/workspaces/{workspaceId}/sessions/{sessionId}/start-file-upload.
+//
+// Get a signed URL to upload an archive to the session
+func (c *Client) SessionStartFileUpload(ctx context.Context, workspaceID string, sessionID string, body *StartFileUploadRequest) (*StartFileUploadResponse, error) {
+	var out StartFileUploadResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID)+"/start-file-upload", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CompareSessionTemplate calls GET /v1/workspaces/{workspaceId}/sessions/{sessionId}/template-diff.
+//
+// Compare a session's template snapshot with the template's current config
+func (c *Client) CompareSessionTemplate(ctx context.Context, workspaceID string, sessionID string) (*CompareSessionTemplateResponse, error) {
+	var out CompareSessionTemplateResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID)+"/template-diff", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TerminateSession calls POST /v1/workspaces/{workspaceId}/sessions/{sessionId}/terminate.
+//
+// Terminate a session, keeping its persistent disk
+func (c *Client) TerminateSession(ctx context.Context, workspaceID string, sessionID string, body *Empty) (*SessionResponse, error) {
+	var out SessionResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions/"+url.PathEscape(sessionID)+"/terminate", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTerminatedSessions calls POST /v1/workspaces/{workspaceId}/sessions:delete-terminated.
+//
+// Delete every terminated session of the caller in the workspace
+func (c *Client) DeleteTerminatedSessions(ctx context.Context, workspaceID string, body *Empty) (*DeleteTerminatedSessionsResponse, error) {
+	var out DeleteTerminatedSessionsResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/sessions:delete-terminated", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListStacks calls GET /v1/workspaces/{workspaceId}/stacks.
+//
+// List the stacks sessions can run on
+func (c *Client) ListStacks(ctx context.Context, workspaceID string) (*ListStacksResponse, error) {
+	var out ListStacksResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/stacks", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTemplates calls GET /v1/workspaces/{workspaceId}/templates.
+//
+// List the workspace's templates
+func (c *Client) ListTemplates(ctx context.Context, workspaceID string) (*ListTemplatesResponse, error) {
+	var out ListTemplatesResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/templates", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateTemplate calls POST /v1/workspaces/{workspaceId}/templates.
+//
+// Create a template
+func (c *Client) CreateTemplate(ctx context.Context, workspaceID string, body *CreateTemplateRequest) (*TemplateResponse, error) {
+	var out TemplateResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/templates", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTemplate calls DELETE /v1/workspaces/{workspaceId}/templates/{templateId}.
+//
+// Delete a template
+func (c *Client) DeleteTemplate(ctx context.Context, workspaceID string, templateID string) (*Empty, error) {
+	var out Empty
+	if err := c.do(ctx, http.MethodDelete, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/templates/"+url.PathEscape(templateID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTemplate calls GET /v1/workspaces/{workspaceId}/templates/{templateId}.
+//
+// Get a template
+func (c *Client) GetTemplate(ctx context.Context, workspaceID string, templateID string) (*TemplateResponse, error) {
+	var out TemplateResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/templates/"+url.PathEscape(templateID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTemplate calls PATCH /v1/workspaces/{workspaceId}/templates/{templateId}.
+//
+// Update a template
+func (c *Client) UpdateTemplate(ctx context.Context, workspaceID string, templateID string, body *UpdateTemplateRequest) (*TemplateResponse, error) {
+	var out TemplateResponse
+	if err := c.do(ctx, http.MethodPatch, "/v1/workspaces/"+url.PathEscape(workspaceID)+"/templates/"+url.PathEscape(templateID), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AutoMappedInput is the AutoMappedInput definition.
+type AutoMappedInput struct {
+	SavedInputID    string `json:"savedInputId,omitempty"`
+	SessionInputKey string `json:"sessionInputKey,omitempty"`
+}
+
+// CompareSessionTemplateResponse is the CompareSessionTemplateResponse definition.
+type CompareSessionTemplateResponse struct {
+	ChangedVariableKeys []string        `json:"changedVariableKeys,omitempty"`
+	Current             *TemplateConfig `json:"current,omitempty"`
+	Snapshot            *TemplateConfig `json:"snapshot,omitempty"`
+}
+
+// CompleteFileUploadRequest is the CompleteFileUploadRequest definition.
+type CompleteFileUploadRequest struct {
+	DestinationFolder string `json:"destinationFolder,omitempty"`
+	UploadID          string `json:"uploadId,omitempty"`
+}
+
+// CreateSavedInputRequest is the CreateSavedInputRequest definition.
+type CreateSavedInputRequest struct {
+	IsSecret bool   `json:"isSecret,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+// CreateSessionRequest is the CreateSessionRequest definition.
+type CreateSessionRequest struct {
+	AiPrompt                string              `json:"aiPrompt,omitempty"`
+	AutoTerminateMinutes    int64               `json:"autoTerminateMinutes,omitempty"`
+	Cluster                 string              `json:"cluster,omitempty"`
+	Description             string              `json:"description,omitempty"`
+	EnabledFeatureFlagNames []string            `json:"enabledFeatureFlagNames,omitempty"`
+	Labels                  map[string]string   `json:"labels,omitempty"`
+	MachineType             string              `json:"machineType,omitempty"`
+	MapSavedToSessionInputs bool                `json:"mapSavedToSessionInputs,omitempty"`
+	Name                    string              `json:"name,omitempty"`
+	SessionInputs           []SessionInputValue `json:"sessionInputs,omitempty"`
+	StackID                 string              `json:"stackId,omitempty"`
+	TemplateID              string              `json:"templateId,omitempty"`
+}
+
+// CreateSessionResponse is the CreateSessionResponse definition.
+type CreateSessionResponse struct {
+	AutoMappedInputs []AutoMappedInput `json:"autoMappedInputs,omitempty"`
+	Session          *Session          `json:"session,omitempty"`
+}
+
+// CreateTemplateRequest is the CreateTemplateRequest definition.
+type CreateTemplateRequest struct {
+	Description       string                   `json:"description,omitempty"`
+	FeatureFlags      []FeatureFlagCreate      `json:"featureFlags,omitempty"`
+	MachineType       string                   `json:"machineType,omitempty"`
+	Name              string                   `json:"name,omitempty"`
+	SessionInputs     []SessionInputCreate     `json:"sessionInputs,omitempty"`
+	StackID           string                   `json:"stackId,omitempty"`
+	StartupScript     string                   `json:"startupScript,omitempty"`
+	TemplateVariables []TemplateVariableCreate `json:"templateVariables,omitempty"`
+	WarmupScript      string                   `json:"warmupScript,omitempty"`
+	WorkingDirectory  string                   `json:"workingDirectory,omitempty"`
+	WorkspaceLinks    []WorkspaceLinkCreate    `json:"workspaceLinks,omitempty"`
+}
+
+// DeleteTerminatedSessionsResponse is the DeleteTerminatedSessionsResponse definition.
+type DeleteTerminatedSessionsResponse struct {
+	DeletedCount int64 `json:"deletedCount,omitempty"`
+}
+
+// DownloadFileRequest is the DownloadFileRequest definition.
+type DownloadFileRequest struct {
+	OnlyContentsOfFolder bool   `json:"onlyContentsOfFolder,omitempty"`
+	SourcePath           string `json:"sourcePath,omitempty"`
+}
+
+// DownloadFileResponse is the DownloadFileResponse definition.
+type DownloadFileResponse struct {
+	SignedURL string `json:"signedUrl,omitempty"`
+}
+
+// Empty is the Empty definition.
+type Empty map[string]any
+
+// FeatureFlag is the FeatureFlag definition.
+type FeatureFlag struct {
+	Description string `json:"description,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// FeatureFlagCreate is the FeatureFlagCreate definition.
+type FeatureFlagCreate struct {
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// FieldViolation is the FieldViolation definition.
+type FieldViolation struct {
+	Description string `json:"description,omitempty"`
+	Field       string `json:"field,omitempty"`
+}
+
+// ListMachineTypesResponse is the ListMachineTypesResponse definition.
+type ListMachineTypesResponse struct {
+	MachineTypes []MachineType `json:"machineTypes,omitempty"`
+}
+
+// ListSavedInputsResponse is the ListSavedInputsResponse definition.
+type ListSavedInputsResponse struct {
+	SavedInputs []SavedInput `json:"savedInputs,omitempty"`
+}
+
+// ListSessionNotificationsParams are the query parameters of GET /v1/workspaces/{workspaceId}/sessions/{sessionId}/notifications.
+type ListSessionNotificationsParams struct {
+	// Only notifications created strictly before this time
+	CreatedBefore string
+	// Only notifications created strictly after this time
+	CreatedAfter string
+	// Max notifications returned (default: 50)
+	Limit int32
+	// Sort order by creation time (default: SORT_ORDER_DESC)
+	Order string
+}
+
+func (p ListSessionNotificationsParams) values() url.Values {
+	q := url.Values{}
+	if p.CreatedBefore != "" {
+		q.Set("createdBefore", p.CreatedBefore)
+	}
+	if p.CreatedAfter != "" {
+		q.Set("createdAfter", p.CreatedAfter)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", fmt.Sprint(p.Limit))
+	}
+	if p.Order != "" {
+		q.Set("order", p.Order)
+	}
+	return q
+}
+
+// ListSessionNotificationsResponse is the ListSessionNotificationsResponse definition.
+type ListSessionNotificationsResponse struct {
+	Notifications []SessionNotification `json:"notifications,omitempty"`
+}
+
+// ListSessionsParams are the query parameters of GET /v1/workspaces/{workspaceId}/sessions.
+type ListSessionsParams struct {
+	// key=value exact-match label filters, ANDed
+	LabelSelectors []string
+}
+
+func (p ListSessionsParams) values() url.Values {
+	q := url.Values{}
+	for _, v := range p.LabelSelectors {
+		q.Add("labelSelectors", fmt.Sprint(v))
+	}
+	return q
+}
+
+// ListSessionsResponse is the ListSessionsResponse definition.
+type ListSessionsResponse struct {
+	Sessions []Session `json:"sessions,omitempty"`
+}
+
+// ListStacksResponse is the ListStacksResponse definition.
+type ListStacksResponse struct {
+	Stacks []Stack `json:"stacks,omitempty"`
+}
+
+// ListTemplatesResponse is the ListTemplatesResponse definition.
+type ListTemplatesResponse struct {
+	Templates []Template `json:"templates,omitempty"`
+}
+
+// LogChunk is the LogChunk definition.
+type LogChunk struct {
+	HeartbeatMessage bool   `json:"heartbeatMessage,omitempty"`
+	LogContent       string `json:"logContent,omitempty"`
+}
+
+// MachineType is the MachineType definition.
+type MachineType struct {
+	ClusterName string `json:"clusterName,omitempty"`
+	CPU         string `json:"cpu,omitempty"`
+	ID          string `json:"id,omitempty"`
+	IsDefault   bool   `json:"isDefault,omitempty"`
+	Name        string `json:"name,omitempty"`
+	OS          string `json:"os,omitempty"`
+	Ram         string `json:"ram,omitempty"`
+	Title       string `json:"title,omitempty"`
+}
+
+// SavedInput is the SavedInput definition.
+type SavedInput struct {
+	CreatedAt string `json:"createdAt,omitempty"`
+	ID        string `json:"id,omitempty"`
+	IsSecret  bool   `json:"isSecret,omitempty"`
+	Key       string `json:"key,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"`
+	Value     string `json:"value,omitempty"`
+}
+
+// SavedInputResponse is the SavedInputResponse definition.
+type SavedInputResponse struct {
+	SavedInput *SavedInput `json:"savedInput,omitempty"`
+}
+
+// Session is the Session definition.
+type Session struct {
+	AgentSessionStatus          string                   `json:"agentSessionStatus,omitempty"`
+	AgentSessionStatusUpdatedAt string                   `json:"agentSessionStatusUpdatedAt,omitempty"`
+	AiConfigured                bool                     `json:"aiConfigured,omitempty"`
+	AiEnabled                   bool                     `json:"aiEnabled,omitempty"`
+	AiPrompt                    string                   `json:"aiPrompt,omitempty"`
+	AutoTerminateAt             string                   `json:"autoTerminateAt,omitempty"`
+	AutoTerminateMinutes        int64                    `json:"autoTerminateMinutes,omitempty"`
+	CreatedAt                   string                   `json:"createdAt,omitempty"`
+	Description                 string                   `json:"description,omitempty"`
+	ID                          string                   `json:"id,omitempty"`
+	Labels                      map[string]string        `json:"labels,omitempty"`
+	Name                        string                   `json:"name,omitempty"`
+	PersistentDiskStatus        string                   `json:"persistentDiskStatus,omitempty"`
+	SSHAddress                  string                   `json:"sshAddress,omitempty"`
+	SSHConnectionOpen           bool                     `json:"sshConnectionOpen,omitempty"`
+	SSHPassword                 string                   `json:"sshPassword,omitempty"`
+	Status                      string                   `json:"status,omitempty"`
+	TemplateDeleted             bool                     `json:"templateDeleted,omitempty"`
+	TemplateID                  string                   `json:"templateId,omitempty"`
+	TemplateOutdated            bool                     `json:"templateOutdated,omitempty"`
+	TemplateSnapshot            *SessionTemplateSnapshot `json:"templateSnapshot,omitempty"`
+	UpdatedAt                   string                   `json:"updatedAt,omitempty"`
+	VNCAddress                  string                   `json:"vncAddress,omitempty"`
+	VNCPassword                 string                   `json:"vncPassword,omitempty"`
+	VNCUsername                 string                   `json:"vncUsername,omitempty"`
+}
+
+// SessionInputCreate is the SessionInputCreate definition.
+type SessionInputCreate struct {
+	DefaultValue   string `json:"defaultValue,omitempty"`
+	Description    string `json:"description,omitempty"`
+	ExposeAsEnvVar bool   `json:"exposeAsEnvVar,omitempty"`
+	Key            string `json:"key,omitempty"`
+	Required       bool   `json:"required,omitempty"`
+}
+
+// SessionInputDef is the SessionInputDef definition.
+type SessionInputDef struct {
+	DefaultValue   string `json:"defaultValue,omitempty"`
+	Description    string `json:"description,omitempty"`
+	ExposeAsEnvVar bool   `json:"exposeAsEnvVar,omitempty"`
+	ID             string `json:"id,omitempty"`
+	Key            string `json:"key,omitempty"`
+	Required       bool   `json:"required,omitempty"`
+}
+
+// SessionInputValue is the SessionInputValue definition.
+type SessionInputValue struct {
+	IsSecret     bool   `json:"isSecret,omitempty"`
+	Key          string `json:"key,omitempty"`
+	SavedInputID string `json:"savedInputId,omitempty"`
+	Value        string `json:"value,omitempty"`
+}
+
+// SessionNotification is the SessionNotification definition.
+type SessionNotification struct {
+	Body      string `json:"body,omitempty"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	ID        string `json:"id,omitempty"`
+	SessionID string `json:"sessionId,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Type      string `json:"type,omitempty"`
+}
+
+// SessionResponse is the SessionResponse definition.
+type SessionResponse struct {
+	Session *Session `json:"session,omitempty"`
+}
+
+// SessionTemplateSnapshot is the SessionTemplateSnapshot definition.
+type SessionTemplateSnapshot struct {
+	FeatureFlags     []SnapshotFlag  `json:"featureFlags,omitempty"`
+	HasStartupScript bool            `json:"hasStartupScript,omitempty"`
+	HasWarmupScript  bool            `json:"hasWarmupScript,omitempty"`
+	Image            string          `json:"image,omitempty"`
+	MachineType      string          `json:"machineType,omitempty"`
+	SessionInputs    []SnapshotInput `json:"sessionInputs,omitempty"`
+	StackID          string          `json:"stackId,omitempty"`
+	TemplateName     string          `json:"templateName,omitempty"`
+	UpdatedAt        string          `json:"updatedAt,omitempty"`
+	WorkingDirectory string          `json:"workingDirectory,omitempty"`
+	WorkspaceLinks   []SnapshotLink  `json:"workspaceLinks,omitempty"`
+}
+
+// SnapshotFlag is the SnapshotFlag definition.
+type SnapshotFlag struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	Name    string `json:"name,omitempty"`
+}
+
+// SnapshotInput is the SnapshotInput definition.
+type SnapshotInput struct {
+	ExposeAsEnvVar bool   `json:"exposeAsEnvVar,omitempty"`
+	IsSecret       bool   `json:"isSecret,omitempty"`
+	Key            string `json:"key,omitempty"`
+	Value          string `json:"value,omitempty"`
+}
+
+// SnapshotLink is the SnapshotLink definition.
+type SnapshotLink struct {
+	FolderPath string `json:"folderPath,omitempty"`
+	Label      string `json:"label,omitempty"`
+	SortOrder  int64  `json:"sortOrder,omitempty"`
+}
+
+// Stack is the Stack definition.
+type Stack struct {
+	ClusterNames    []string `json:"clusterNames,omitempty"`
+	Description     string   `json:"description,omitempty"`
+	DescriptionLink string   `json:"descriptionLink,omitempty"`
+	ID              string   `json:"id,omitempty"`
+	IsDefault       bool     `json:"isDefault,omitempty"`
+	OS              string   `json:"os,omitempty"`
+	OSVersion       int32    `json:"osVersion,omitempty"`
+	Status          string   `json:"status,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	XcodeVersion    string   `json:"xcodeVersion,omitempty"`
+}
+
+// StartFileUploadRequest is the StartFileUploadRequest definition.
+type StartFileUploadRequest struct {
+	DestinationFolder string `json:"destinationFolder,omitempty"`
+}
+
+// StartFileUploadResponse is the StartFileUploadResponse definition.
+type StartFileUploadResponse struct {
+	SignedURL string `json:"signedUrl,omitempty"`
+	UploadID  string `json:"uploadId,omitempty"`
+}
+
+// Status is the Status definition.
+type Status struct {
+	Code    int64          `json:"code,omitempty"`
+	Details []StatusDetail `json:"details,omitempty"`
+	Message string         `json:"message,omitempty"`
+}
+
+// StatusDetail is the StatusDetail definition.
+type StatusDetail struct {
+	Type            string           `json:"@type,omitempty"`
+	FieldViolations []FieldViolation `json:"fieldViolations,omitempty"`
+}
+
+// StreamSessionLogsResponse is the StreamSessionLogsResponse definition.
+type StreamSessionLogsResponse struct {
+	Error  *Status   `json:"error,omitempty"`
+	Result *LogChunk `json:"result,omitempty"`
+}
+
+// Template is the Template definition.
+type Template struct {
+	CreatedAt         string             `json:"createdAt,omitempty"`
+	CreatedByEmail    string             `json:"createdByEmail,omitempty"`
+	Description       string             `json:"description,omitempty"`
+	FeatureFlags      []FeatureFlag      `json:"featureFlags,omitempty"`
+	ID                string             `json:"id,omitempty"`
+	Image             string             `json:"image,omitempty"`
+	MachineType       string             `json:"machineType,omitempty"`
+	Name              string             `json:"name,omitempty"`
+	SessionInputs     []SessionInputDef  `json:"sessionInputs,omitempty"`
+	StackID           string             `json:"stackId,omitempty"`
+	StartupScript     string             `json:"startupScript,omitempty"`
+	TemplateVariables []TemplateVariable `json:"templateVariables,omitempty"`
+	UpdatedAt         string             `json:"updatedAt,omitempty"`
+	WarmupScript      string             `json:"warmupScript,omitempty"`
+	WorkingDirectory  string             `json:"workingDirectory,omitempty"`
+	WorkspaceID       string             `json:"workspaceId,omitempty"`
+	WorkspaceLinks    []WorkspaceLink    `json:"workspaceLinks,omitempty"`
+}
+
+// TemplateConfig is the TemplateConfig definition.
+type TemplateConfig struct {
+	FeatureFlags      []TemplateConfigFlag     `json:"featureFlags,omitempty"`
+	Image             string                   `json:"image,omitempty"`
+	MachineType       string                   `json:"machineType,omitempty"`
+	SessionInputs     []TemplateConfigInput    `json:"sessionInputs,omitempty"`
+	StackID           string                   `json:"stackId,omitempty"`
+	StartupScript     string                   `json:"startupScript,omitempty"`
+	TemplateName      string                   `json:"templateName,omitempty"`
+	TemplateVariables []TemplateConfigVariable `json:"templateVariables,omitempty"`
+	UpdatedAt         string                   `json:"updatedAt,omitempty"`
+	WarmupScript      string                   `json:"warmupScript,omitempty"`
+	WorkingDirectory  string                   `json:"workingDirectory,omitempty"`
+	WorkspaceLinks    []SnapshotLink           `json:"workspaceLinks,omitempty"`
+}
+
+// TemplateConfigFlag is the TemplateConfigFlag definition.
+type TemplateConfigFlag struct {
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// TemplateConfigInput is the TemplateConfigInput definition.
+type TemplateConfigInput struct {
+	DefaultValue   string `json:"defaultValue,omitempty"`
+	Description    string `json:"description,omitempty"`
+	ExposeAsEnvVar bool   `json:"exposeAsEnvVar,omitempty"`
+	IsSecret       bool   `json:"isSecret,omitempty"`
+	Key            string `json:"key,omitempty"`
+	Required       bool   `json:"required,omitempty"`
+}
+
+// TemplateConfigVariable is the TemplateConfigVariable definition.
+type TemplateConfigVariable struct {
+	ExposeAsEnvVar bool   `json:"exposeAsEnvVar,omitempty"`
+	IsSecret       bool   `json:"isSecret,omitempty"`
+	Key            string `json:"key,omitempty"`
+}
+
+// TemplateResponse is the TemplateResponse definition.
+type TemplateResponse struct {
+	Template *Template `json:"template,omitempty"`
+}
+
+// TemplateVariable is the TemplateVariable definition.
+type TemplateVariable struct {
+	ExposeAsEnvVar bool   `json:"exposeAsEnvVar,omitempty"`
+	ID             string `json:"id,omitempty"`
+	IsSecret       bool   `json:"isSecret,omitempty"`
+	Key            string `json:"key,omitempty"`
+	Value          string `json:"value,omitempty"`
+}
+
+// TemplateVariableCreate is the TemplateVariableCreate definition.
+type TemplateVariableCreate struct {
+	ExposeAsEnvVar bool   `json:"exposeAsEnvVar,omitempty"`
+	IsSecret       bool   `json:"isSecret,omitempty"`
+	Key            string `json:"key,omitempty"`
+	Value          string `json:"value,omitempty"`
+}
+
+// UpdateSavedInputRequest is the UpdateSavedInputRequest definition.
+type UpdateSavedInputRequest struct {
+	IsSecret bool   `json:"isSecret,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+// UpdateSessionRequest is the UpdateSessionRequest definition.
+type UpdateSessionRequest struct {
+	AutoTerminateMinutes int64             `json:"autoTerminateMinutes,omitempty"`
+	Description          string            `json:"description,omitempty"`
+	Labels               map[string]string `json:"labels,omitempty"`
+	Name                 string            `json:"name,omitempty"`
+	RemoveLabels         []string          `json:"removeLabels,omitempty"`
+}
+
+// UpdateTemplateRequest is the UpdateTemplateRequest definition.
+type UpdateTemplateRequest struct {
+	Description             string                   `json:"description,omitempty"`
+	FeatureFlags            []FeatureFlagCreate      `json:"featureFlags,omitempty"`
+	MachineType             string                   `json:"machineType,omitempty"`
+	Name                    string                   `json:"name,omitempty"`
+	SessionInputs           []SessionInputCreate     `json:"sessionInputs,omitempty"`
+	StackID                 string                   `json:"stackId,omitempty"`
+	StartupScript           string                   `json:"startupScript,omitempty"`
+	TemplateVariables       []TemplateVariableCreate `json:"templateVariables,omitempty"`
+	UpdateFeatureFlags      bool                     `json:"updateFeatureFlags,omitempty"`
+	UpdateSessionInputs     bool                     `json:"updateSessionInputs,omitempty"`
+	UpdateTemplateVariables bool                     `json:"updateTemplateVariables,omitempty"`
+	UpdateWorkspaceLinks    bool                     `json:"updateWorkspaceLinks,omitempty"`
+	WarmupScript            string                   `json:"warmupScript,omitempty"`
+	WorkingDirectory        string                   `json:"workingDirectory,omitempty"`
+	WorkspaceLinks          []WorkspaceLinkCreate    `json:"workspaceLinks,omitempty"`
+}
+
+// WorkspaceLink is the WorkspaceLink definition.
+type WorkspaceLink struct {
+	FolderPath string `json:"folderPath,omitempty"`
+	Label      string `json:"label,omitempty"`
+	SortOrder  int64  `json:"sortOrder,omitempty"`
+}
+
+// WorkspaceLinkCreate is the WorkspaceLinkCreate definition.
+type WorkspaceLinkCreate struct {
+	FeatureFlagName string `json:"featureFlagName,omitempty"`
+	FolderPath      string `json:"folderPath,omitempty"`
+	Label           string `json:"label,omitempty"`
+}
@@ -0,0 +1,785 @@
+// Code generated by tools/genapi from tools/genapi/specs/v0.1.json; DO NOT EDIT.
+
+// Package v01gen holds the models and client methods generated from the
+// Bitrise API v0.1 swagger document (Bitrise API 0.1).
+package v01gen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/bitrise-io/bitrise-cli/bitriseapi"
+)
+
+// Client calls the generated endpoints through the hand-written client's
+// authenticated RawRequest, so base URL, auth, retries, and caching are
+// shared with the rest of the CLI.
+type Client struct {
+	raw *bitriseapi.Client
+}
+
+// New returns a Client sending its requests through raw.
+func New(raw *bitriseapi.Client) *Client {
+	return &Client{raw: raw}
+}
+
+// do sends in (if non-nil) as the JSON body and decodes a 2xx response into
+// out (if non-nil). A non-2xx response is returned as the hand-written
+// client's API error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var (
+		body   io.Reader
+		header http.Header
+	)
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body, header = bytes.NewReader(data), http.Header{"Content-Type": {"application/json"}}
+	}
+	resp, err := c.raw.RawRequest(ctx, method, path, query, header, body)
+	if err != nil {
+		return err
+	}
+	if err := resp.Err(); err != nil {
+		return err
+	}
+	if out == nil || len(resp.Body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Body, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// AppList calls GET /apps.
+//
+// Get list of the apps
+func (c *Client) AppList(ctx context.Context, params AppListParams) (*AppListResponseModel, error) {
+	var out AppListResponseModel
+	if err := c.do(ctx, http.MethodGet, "/apps", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AppCreate calls POST /apps/register.
+//
+// Add a new app
+func (c *Client) AppCreate(ctx context.Context, body *RegisterAppRequest) (*RegisterAppResponse, error) {
+	var out RegisterAppResponse
+	if err := c.do(ctx, http.MethodPost, "/apps/register", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AppShow calls GET /apps/{app-slug}.
+//
+// Get a specific app
+func (c *Client) AppShow(ctx context.Context, appSlug string) (*AppResponseModel, error) {
+	var out AppResponseModel
+	if err := c.do(ctx, http.MethodGet, "/apps/"+url.PathEscape(appSlug), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AppConfigDatastoreShow calls GET /apps/{app-slug}/bitrise.yml.
+//
+// Get bitrise.yml of a specific app
+func (c *Client) AppConfigDatastoreShow(ctx context.Context, appSlug string) error {
+	return c.do(ctx, http.MethodGet, "/apps/"+url.PathEscape(appSlug)+"/bitrise.yml", nil, nil, nil)
+}
+
+// AppConfigCreate calls POST /apps/{app-slug}/bitrise.yml.
+//
+// Upload a new bitrise.yml for your application
+func (c *Client) AppConfigCreate(ctx context.Context, appSlug string, body *AppConfigUploadParams) (*AppConfigUploadResponse, error) {
+	var out AppConfigUploadResponse
+	if err := c.do(ctx, http.MethodPost, "/apps/"+url.PathEscape(appSlug)+"/bitrise.yml", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BuildList calls GET /apps/{app-slug}/builds.
+//
+// List all builds of an app
+func (c *Client) BuildList(ctx context.Context, appSlug string, params BuildListParams) (*BuildListResponseModel, error) {
+	var out BuildListResponseModel
+	if err := c.do(ctx, http.MethodGet, "/apps/"+url.PathEscape(appSlug)+"/builds", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BuildTrigger calls POST /apps/{app-slug}/builds.
+//
+// Trigger a new build
+func (c *Client) BuildTrigger(ctx context.Context, appSlug string, body *BuildTriggerParams) (*BuildTriggerResp, error) {
+	var out BuildTriggerResp
+	if err := c.do(ctx, http.MethodPost, "/apps/"+url.PathEscape(appSlug)+"/builds", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BuildShow calls GET /apps/{app-slug}/builds/{build-slug}.
+//
+// Get a build of a given app
+func (c *Client) BuildShow(ctx context.Context, appSlug string, buildSlug string) (*BuildResponseModel, error) {
+	var out BuildResponseModel
+	if err := c.do(ctx, http.MethodGet, "/apps/"+url.PathEscape(appSlug)+"/builds/"+url.PathEscape(buildSlug), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BuildAbort calls POST /apps/{app-slug}/builds/{build-slug}/abort.
+//
+// Abort a specific build
+func (c *Client) BuildAbort(ctx context.Context, appSlug string, buildSlug string, body *BuildAbortParams) (*BuildAbortResp, error) {
+	var out BuildAbortResp
+	if err := c.do(ctx, http.MethodPost, "/apps/"+url.PathEscape(appSlug)+"/builds/"+url.PathEscape(buildSlug)+"/abort", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BuildBitriseYmlShow calls GET /apps/{app-slug}/builds/{build-slug}/bitrise.yml.
+//
+// Get the bitrise.yml of a build
+func (c *Client) BuildBitriseYmlShow(ctx context.Context, appSlug string, buildSlug string) error {
+	return c.do(ctx, http.MethodGet, "/apps/"+url.PathEscape(appSlug)+"/builds/"+url.PathEscape(buildSlug)+"/bitrise.yml", nil, nil, nil)
+}
+
+// BuildLog calls GET /apps/{app-slug}/builds/{build-slug}/log.
+//
+// Get the build log of a build
+func (c *Client) BuildLog(ctx context.Context, appSlug string, buildSlug string, params BuildLogParams) (*BuildLogResponse, error) {
+	var out BuildLogResponse
+	if err := c.do(ctx, http.MethodGet, "/apps/"+url.PathEscape(appSlug)+"/builds/"+url.PathEscape(buildSlug)+"/log", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AppFinish calls POST /apps/{app-slug}/finish.
+//
+// Save the application at the end of the app registration process
+func (c *Client) AppFinish(ctx context.Context, appSlug string, body *FinishAppRequest) (*FinishAppResponse, error) {
+	var out FinishAppResponse
+	if err := c.do(ctx, http.MethodPost, "/apps/"+url.PathEscape(appSlug)+"/finish", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UserProfile calls GET /me.
+//
+// Get your profile data
+func (c *Client) UserProfile(ctx context.Context) (*UserResponseModel, error) {
+	var out UserResponseModel
+	if err := c.do(ctx, http.MethodGet, "/me", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AccessTokenList calls GET /me/access-tokens.
+//
+// List your personal access tokens
+func (c *Client) AccessTokenList(ctx context.Context) (*AccessTokenListResponseModel, error) {
+	var out AccessTokenListResponseModel
+	if err := c.do(ctx, http.MethodGet, "/me/access-tokens", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AccessTokenCreate calls POST /me/access-tokens.
+//
+// Create a personal access token
+func (c *Client) AccessTokenCreate(ctx context.Context, body *CreateAccessTokenRequest) (*CreatedAccessTokenResponseModel, error) {
+	var out CreatedAccessTokenResponseModel
+	if err := c.do(ctx, http.MethodPost, "/me/access-tokens", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AccessTokenRevoke calls DELETE /me/access-tokens/{id}.
+//
+// Revoke a personal access token
+func (c *Client) AccessTokenRevoke(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/me/access-tokens/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// OrgList calls GET /organizations.
+//
+// List the organizations that the user is part of
+func (c *Client) OrgList(ctx context.Context) (*OrganizationListResponseModel, error) {
+	var out OrganizationListResponseModel
+	if err := c.do(ctx, http.MethodGet, "/organizations", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StepSearch calls GET /search-steps.
+//
+// Search steps in the StepLib
+func (c *Client) StepSearch(ctx context.Context, params StepSearchParams) ([]StepResponse, error) {
+	var out []StepResponse
+	if err := c.do(ctx, http.MethodGet, "/search-steps", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StepInputs calls GET /step-inputs.
+//
+// List the inputs and outputs of a step
+func (c *Client) StepInputs(ctx context.Context, params StepInputsParams) ([]StepInputOutputResponse, error) {
+	var out []StepInputOutputResponse
+	if err := c.do(ctx, http.MethodGet, "/step-inputs", params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TokenInfo calls GET /token-info.
+//
+// Introspect the token used for the request
+func (c *Client) TokenInfo(ctx context.Context) (*TokenInfoResponseModel, error) {
+	var out TokenInfoResponseModel
+	if err := c.do(ctx, http.MethodGet, "/token-info", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// BitriseYmlValidate calls POST /validate-bitrise-yml.
+//
+// Validate a bitrise.yml
+func (c *Client) BitriseYmlValidate(ctx context.Context, body *ValidateBitriseYMLRequest, params BitriseYmlValidateParams) (*ValidateBitriseYMLResponse, error) {
+	var out ValidateBitriseYMLResponse
+	if err := c.do(ctx, http.MethodPost, "/validate-bitrise-yml", params.values(), body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AccessToken is the AccessToken definition.
+type AccessToken struct {
+	CreatedAt   string   `json:"created_at,omitempty"`
+	Description string   `json:"description,omitempty"`
+	ExpiresAt   string   `json:"expires_at,omitempty"`
+	ID          string   `json:"id,omitempty"`
+	LastUsedAt  string   `json:"last_used_at,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+}
+
+// AccessTokenListResponseModel is the AccessTokenListResponseModel definition.
+type AccessTokenListResponseModel struct {
+	Data []AccessToken `json:"data,omitempty"`
+}
+
+// App is the App definition.
+type App struct {
+	AvatarURL             string    `json:"avatar_url,omitempty"`
+	IsDisabled            bool      `json:"is_disabled,omitempty"`
+	IsGithubChecksEnabled bool      `json:"is_github_checks_enabled,omitempty"`
+	IsPublic              bool      `json:"is_public,omitempty"`
+	Owner                 *AppOwner `json:"owner,omitempty"`
+	ProjectID             string    `json:"project_id,omitempty"`
+	ProjectType           string    `json:"project_type,omitempty"`
+	Provider              string    `json:"provider,omitempty"`
+	RepoOwner             string    `json:"repo_owner,omitempty"`
+	RepoSlug              string    `json:"repo_slug,omitempty"`
+	RepoURL               string    `json:"repo_url,omitempty"`
+	Slug                  string    `json:"slug,omitempty"`
+	Status                int64     `json:"status,omitempty"`
+	Title                 string    `json:"title,omitempty"`
+}
+
+// AppConfigUploadParams is the AppConfigUploadParams definition.
+type AppConfigUploadParams struct {
+	AppConfigDatastoreYaml string `json:"app_config_datastore_yaml,omitempty"`
+}
+
+// AppConfigUploadResponse is the AppConfigUploadResponse definition.
+type AppConfigUploadResponse struct {
+	Status string `json:"status,omitempty"`
+}
+
+// AppListParams are the query parameters of GET /apps.
+type AppListParams struct {
+	// Order of the apps: sort them based on when they were created or the time of their last build
+	SortBy string
+	// Slug of the first app in the response
+	Next string
+	// Max number of elements per page (default: 50)
+	Limit int
+	// Filter apps by title
+	Title string
+	// Filter apps by project type
+	ProjectType string
+}
+
+func (p AppListParams) values() url.Values {
+	q := url.Values{}
+	if p.SortBy != "" {
+		q.Set("sort_by", p.SortBy)
+	}
+	if p.Next != "" {
+		q.Set("next", p.Next)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", fmt.Sprint(p.Limit))
+	}
+	if p.Title != "" {
+		q.Set("title", p.Title)
+	}
+	if p.ProjectType != "" {
+		q.Set("project_type", p.ProjectType)
+	}
+	return q
+}
+
+// AppListResponseModel is the AppListResponseModel definition.
+type AppListResponseModel struct {
+	Data   []App   `json:"data,omitempty"`
+	Paging *Paging `json:"paging,omitempty"`
+}
+
+// AppOwner is the AppOwner definition.
+type AppOwner struct {
+	AccountType string `json:"account_type,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Slug        string `json:"slug,omitempty"`
+}
+
+// AppResponseModel is the AppResponseModel definition.
+type AppResponseModel struct {
+	Data *App `json:"data,omitempty"`
+}
+
+// BitriseYmlValidateParams are the query parameters of POST /validate-bitrise-yml.
+type BitriseYmlValidateParams struct {
+	// App whose context (stacks, secrets) the bitrise.yml is validated in
+	AppSlug string
+}
+
+func (p BitriseYmlValidateParams) values() url.Values {
+	q := url.Values{}
+	if p.AppSlug != "" {
+		q.Set("app_slug", p.AppSlug)
+	}
+	return q
+}
+
+// Build is the Build definition.
+type Build struct {
+	AbortReason                  string `json:"abort_reason,omitempty"`
+	Branch                       string `json:"branch,omitempty"`
+	BuildNumber                  int64  `json:"build_number,omitempty"`
+	CommitHash                   string `json:"commit_hash,omitempty"`
+	CommitMessage                string `json:"commit_message,omitempty"`
+	CommitViewURL                string `json:"commit_view_url,omitempty"`
+	CreditCost                   int64  `json:"credit_cost,omitempty"`
+	EnvironmentPrepareFinishedAt string `json:"environment_prepare_finished_at,omitempty"`
+	FinishedAt                   string `json:"finished_at,omitempty"`
+	IsOnHold                     bool   `json:"is_on_hold,omitempty"`
+	IsProcessed                  bool   `json:"is_processed,omitempty"`
+	IsStatusSent                 bool   `json:"is_status_sent,omitempty"`
+	LogFormat                    string `json:"log_format,omitempty"`
+	MachineTypeID                string `json:"machine_type_id,omitempty"`
+	PipelineWorkflowID           string `json:"pipeline_workflow_id,omitempty"`
+	PullRequestID                int64  `json:"pull_request_id,omitempty"`
+	PullRequestTargetBranch      string `json:"pull_request_target_branch,omitempty"`
+	PullRequestViewURL           string `json:"pull_request_view_url,omitempty"`
+	Rebuildable                  bool   `json:"rebuildable,omitempty"`
+	Slug                         string `json:"slug,omitempty"`
+	StackIdentifier              string `json:"stack_identifier,omitempty"`
+	StartedOnWorkerAt            string `json:"started_on_worker_at,omitempty"`
+	Status                       int64  `json:"status,omitempty"`
+	StatusText                   string `json:"status_text,omitempty"`
+	Tag                          string `json:"tag,omitempty"`
+	TriggeredAt                  string `json:"triggered_at,omitempty"`
+	TriggeredBy                  string `json:"triggered_by,omitempty"`
+	TriggeredWorkflow            string `json:"triggered_workflow,omitempty"`
+}
+
+// BuildAbortParams is the BuildAbortParams definition.
+type BuildAbortParams struct {
+	AbortReason         string `json:"abort_reason,omitempty"`
+	AbortWithSuccess    bool   `json:"abort_with_success,omitempty"`
+	SkipGitStatusReport bool   `json:"skip_git_status_report,omitempty"`
+	SkipNotifications   bool   `json:"skip_notifications,omitempty"`
+}
+
+// BuildAbortResp is the BuildAbortResp definition.
+type BuildAbortResp struct {
+	Status string `json:"status,omitempty"`
+}
+
+// BuildListParams are the query parameters of GET /apps/{app-slug}/builds.
+type BuildListParams struct {
+	// Order of builds: sort them based on when they were created or the time when they were triggered
+	SortBy string
+	// The branch which was built
+	Branch string
+	// The name of the workflow used for the build
+	Workflow string
+	// The commit message of the build
+	CommitMessage string
+	// The event that triggered the build (push, pull-request, tag)
+	TriggerEventType string
+	// The id of the pull request that triggered the build
+	PullRequestID int
+	// The build number
+	BuildNumber int
+	// List builds run after a given date (Unix Timestamp)
+	After int
+	// List builds run before a given date (Unix Timestamp)
+	Before int
+	// The status of the build: not finished (0), successful (1), failed (2), aborted with failure (3), aborted with success (4)
+	Status int
+	// Whether the build is part of a pipeline
+	IsPipelineBuild bool
+	// Slug of the first build in the response
+	Next string
+	// Max number of elements per page (default: 50)
+	Limit int
+}
+
+func (p BuildListParams) values() url.Values {
+	q := url.Values{}
+	if p.SortBy != "" {
+		q.Set("sort_by", p.SortBy)
+	}
+	if p.Branch != "" {
+		q.Set("branch", p.Branch)
+	}
+	if p.Workflow != "" {
+		q.Set("workflow", p.Workflow)
+	}
+	if p.CommitMessage != "" {
+		q.Set("commit_message", p.CommitMessage)
+	}
+	if p.TriggerEventType != "" {
+		q.Set("trigger_event_type", p.TriggerEventType)
+	}
+	if p.PullRequestID != 0 {
+		q.Set("pull_request_id", fmt.Sprint(p.PullRequestID))
+	}
+	if p.BuildNumber != 0 {
+		q.Set("build_number", fmt.Sprint(p.BuildNumber))
+	}
+	if p.After != 0 {
+		q.Set("after", fmt.Sprint(p.After))
+	}
+	if p.Before != 0 {
+		q.Set("before", fmt.Sprint(p.Before))
+	}
+	if p.Status != 0 {
+		q.Set("status", fmt.Sprint(p.Status))
+	}
+	if p.IsPipelineBuild {
+		q.Set("is_pipeline_build", "true")
+	}
+	if p.Next != "" {
+		q.Set("next", p.Next)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", fmt.Sprint(p.Limit))
+	}
+	return q
+}
+
+// BuildListResponseModel is the BuildListResponseModel definition.
+type BuildListResponseModel struct {
+	Data   []Build `json:"data,omitempty"`
+	Paging *Paging `json:"paging,omitempty"`
+}
+
+// BuildLogChunk is the BuildLogChunk definition.
+type BuildLogChunk struct {
+	Chunk    string `json:"chunk,omitempty"`
+	Position int64  `json:"position,omitempty"`
+}
+
+// BuildLogParams are the query parameters of GET /apps/{app-slug}/builds/{build-slug}/log.
+type BuildLogParams struct {
+	// Only return log chunks newer than this
+	AfterTimestamp string
+}
+
+func (p BuildLogParams) values() url.Values {
+	q := url.Values{}
+	if p.AfterTimestamp != "" {
+		q.Set("after_timestamp", p.AfterTimestamp)
+	}
+	return q
+}
+
+// BuildLogResponse is the BuildLogResponse definition.
+type BuildLogResponse struct {
+	ExpiringRawLogURL     string          `json:"expiring_raw_log_url,omitempty"`
+	GeneratedLogChunksNum int64           `json:"generated_log_chunks_num,omitempty"`
+	IsArchived            bool            `json:"is_archived,omitempty"`
+	LogChunks             []BuildLogChunk `json:"log_chunks,omitempty"`
+	NextAfterTimestamp    string          `json:"next_after_timestamp,omitempty"`
+	Timestamp             string          `json:"timestamp,omitempty"`
+}
+
+// BuildResponseModel is the BuildResponseModel definition.
+type BuildResponseModel struct {
+	Data *Build `json:"data,omitempty"`
+}
+
+// BuildTriggerBuildParams is the BuildTriggerBuildParams definition.
+type BuildTriggerBuildParams struct {
+	Branch        string            `json:"branch,omitempty"`
+	BranchDest    string            `json:"branch_dest,omitempty"`
+	CommitHash    string            `json:"commit_hash,omitempty"`
+	CommitMessage string            `json:"commit_message,omitempty"`
+	Environments  []BuildTriggerEnv `json:"environments,omitempty"`
+	PipelineID    string            `json:"pipeline_id,omitempty"`
+	Priority      int64             `json:"priority,omitempty"`
+	PullRequestID int64             `json:"pull_request_id,omitempty"`
+	Tag           string            `json:"tag,omitempty"`
+	WorkflowID    string            `json:"workflow_id,omitempty"`
+}
+
+// BuildTriggerEnv is the BuildTriggerEnv definition.
+type BuildTriggerEnv struct {
+	IsExpand bool   `json:"is_expand,omitempty"`
+	MappedTo string `json:"mapped_to,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+// BuildTriggerHookInfo is the BuildTriggerHookInfo definition.
+type BuildTriggerHookInfo struct {
+	Type string `json:"type,omitempty"`
+}
+
+// BuildTriggerParams is the BuildTriggerParams definition.
+type BuildTriggerParams struct {
+	BuildParams *BuildTriggerBuildParams `json:"build_params,omitempty"`
+	HookInfo    *BuildTriggerHookInfo    `json:"hook_info,omitempty"`
+}
+
+// BuildTriggerResp is the BuildTriggerResp definition.
+type BuildTriggerResp struct {
+	BuildNumber       int64                  `json:"build_number,omitempty"`
+	BuildSlug         string                 `json:"build_slug,omitempty"`
+	BuildURL          string                 `json:"build_url,omitempty"`
+	Message           string                 `json:"message,omitempty"`
+	Results           []BuildTriggerRespItem `json:"results,omitempty"`
+	Service           string                 `json:"service,omitempty"`
+	Slug              string                 `json:"slug,omitempty"`
+	Status            string                 `json:"status,omitempty"`
+	TriggeredWorkflow string                 `json:"triggered_workflow,omitempty"`
+}
+
+// BuildTriggerRespItem is the BuildTriggerRespItem definition.
+type BuildTriggerRespItem struct {
+	BuildNumber       int64  `json:"build_number,omitempty"`
+	BuildSlug         string `json:"build_slug,omitempty"`
+	BuildURL          string `json:"build_url,omitempty"`
+	Message           string `json:"message,omitempty"`
+	Status            string `json:"status,omitempty"`
+	TriggeredPipeline string `json:"triggered_pipeline,omitempty"`
+	TriggeredWorkflow string `json:"triggered_workflow,omitempty"`
+}
+
+// CreateAccessTokenRequest is the CreateAccessTokenRequest definition.
+type CreateAccessTokenRequest struct {
+	Description string `json:"description,omitempty"`
+	ExpiresIn   int64  `json:"expires_in,omitempty"`
+}
+
+// CreatedAccessToken is the CreatedAccessToken definition.
+type CreatedAccessToken struct {
+	CreatedAt   string   `json:"created_at,omitempty"`
+	Description string   `json:"description,omitempty"`
+	ExpiresAt   string   `json:"expires_at,omitempty"`
+	ID          string   `json:"id,omitempty"`
+	LastUsedAt  string   `json:"last_used_at,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+	Token       string   `json:"token,omitempty"`
+}
+
+// CreatedAccessTokenResponseModel is the CreatedAccessTokenResponseModel definition.
+type CreatedAccessTokenResponseModel struct {
+	Data *CreatedAccessToken `json:"data,omitempty"`
+}
+
+// FinishAppRequest is the FinishAppRequest definition.
+type FinishAppRequest struct {
+	Config      string            `json:"config,omitempty"`
+	Envs        map[string]string `json:"envs,omitempty"`
+	FlowType    string            `json:"flow_type,omitempty"`
+	Mode        string            `json:"mode,omitempty"`
+	ProjectType string            `json:"project_type,omitempty"`
+	StackID     string            `json:"stack_id,omitempty"`
+}
+
+// FinishAppResponse is the FinishAppResponse definition.
+type FinishAppResponse struct {
+	BranchName                string `json:"branch_name,omitempty"`
+	BuildTriggerToken         string `json:"build_trigger_token,omitempty"`
+	IsWebhookAutoRegSupported bool   `json:"is_webhook_auto_reg_supported,omitempty"`
+	Status                    string `json:"status,omitempty"`
+}
+
+// Organization is the Organization definition.
+type Organization struct {
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// OrganizationListResponseModel is the OrganizationListResponseModel definition.
+type OrganizationListResponseModel struct {
+	Data   []Organization `json:"data,omitempty"`
+	Paging *Paging        `json:"paging,omitempty"`
+}
+
+// Paging is the Paging definition.
+type Paging struct {
+	Next           string `json:"next,omitempty"`
+	PageItemLimit  int64  `json:"page_item_limit,omitempty"`
+	TotalItemCount int64  `json:"total_item_count,omitempty"`
+}
+
+// RegisterAppRequest is the RegisterAppRequest definition.
+type RegisterAppRequest struct {
+	DefaultBranchName string `json:"default_branch_name,omitempty"`
+	FlowType          string `json:"flow_type,omitempty"`
+	IsPublic          bool   `json:"is_public,omitempty"`
+	OrganizationSlug  string `json:"organization_slug,omitempty"`
+	Provider          string `json:"provider,omitempty"`
+	RepoURL           string `json:"repo_url,omitempty"`
+	Title             string `json:"title,omitempty"`
+}
+
+// RegisterAppResponse is the RegisterAppResponse definition.
+type RegisterAppResponse struct {
+	Slug   string `json:"slug,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+// StepInputOutputResponse is the StepInputOutputResponse definition.
+type StepInputOutputResponse struct {
+	DefaultValue string   `json:"default_value,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	IsRequired   bool     `json:"is_required,omitempty"`
+	IsSensitive  bool     `json:"is_sensitive,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	Title        string   `json:"title,omitempty"`
+	ValueOptions []string `json:"value_options,omitempty"`
+}
+
+// StepInputsParams are the query parameters of GET /step-inputs.
+type StepInputsParams struct {
+	// Step reference, e.g. git-clone@8
+	StepRef string
+}
+
+func (p StepInputsParams) values() url.Values {
+	q := url.Values{}
+	if p.StepRef != "" {
+		q.Set("step_ref", p.StepRef)
+	}
+	return q
+}
+
+// StepResponse is the StepResponse definition.
+type StepResponse struct {
+	Description         string                    `json:"description,omitempty"`
+	ID                  string                    `json:"id,omitempty"`
+	Inputs              []StepInputOutputResponse `json:"inputs,omitempty"`
+	IsDeprecated        bool                      `json:"is_deprecated,omitempty"`
+	IsLatest            bool                      `json:"is_latest,omitempty"`
+	LatestVersionNumber string                    `json:"latest_version_number,omitempty"`
+	Maintainer          string                    `json:"maintainer,omitempty"`
+	Outputs             []StepInputOutputResponse `json:"outputs,omitempty"`
+	StepRef             string                    `json:"step_ref,omitempty"`
+	Summary             string                    `json:"summary,omitempty"`
+	Title               string                    `json:"title,omitempty"`
+	Version             string                    `json:"version,omitempty"`
+}
+
+// StepSearchParams are the query parameters of GET /search-steps.
+type StepSearchParams struct {
+	// Free-text search
+	Query string
+	// Step categories
+	Categories []string
+	// Step maintainers
+	Maintainers []string
+}
+
+func (p StepSearchParams) values() url.Values {
+	q := url.Values{}
+	if p.Query != "" {
+		q.Set("query", p.Query)
+	}
+	for _, v := range p.Categories {
+		q.Add("categories", fmt.Sprint(v))
+	}
+	for _, v := range p.Maintainers {
+		q.Add("maintainers", fmt.Sprint(v))
+	}
+	return q
+}
+
+// TokenInfo is the TokenInfo definition.
+type TokenInfo struct {
+	Description string        `json:"description,omitempty"`
+	ExpiresAt   string        `json:"expires_at,omitempty"`
+	ID          string        `json:"id,omitempty"`
+	Scopes      []string      `json:"scopes,omitempty"`
+	Type        string        `json:"type,omitempty"`
+	User        *User         `json:"user,omitempty"`
+	Workspace   *Organization `json:"workspace,omitempty"`
+}
+
+// TokenInfoResponseModel is the TokenInfoResponseModel definition.
+type TokenInfoResponseModel struct {
+	Data *TokenInfo `json:"data,omitempty"`
+}
+
+// User is the User definition.
+type User struct {
+	AvatarURL string `json:"avatar_url,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+// UserResponseModel is the UserResponseModel definition.
+type UserResponseModel struct {
+	Data *User `json:"data,omitempty"`
+}
+
+// ValidateBitriseYMLRequest is the ValidateBitriseYMLRequest definition.
+type ValidateBitriseYMLRequest struct {
+	BitriseYml string `json:"bitrise_yml,omitempty"`
+}
+
+// ValidateBitriseYMLResponse is the ValidateBitriseYMLResponse definition.
+type ValidateBitriseYMLResponse struct {
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
@@ -18,6 +18,17 @@ type RawResponse struct {
 	StatusCode int
 	Header     http.Header
 	Body       []byte
+	// requestInfo is "METHOD /path?query", for Err.
+	requestInfo string
+}
+
+// Err returns nil for a 2xx response and the *APIError the typed client
+// methods would have returned otherwise.
+func (r *RawResponse) Err() error {
+	if r.StatusCode >= 200 && r.StatusCode < 300 {
+		return nil
+	}
+	return newAPIError(r.StatusCode, r.Body, r.requestInfo)
 }
 
 // RawRequest issues an arbitrary authenticated request to the Bitrise API and
@@ -71,6 +82,8 @@ func (c *Client) RawRequest(ctx context.Context, method, path string, query url.
 		StatusCode: resp.StatusCode,
 		Header:     resp.Header,
 		Body:       respBody,
+
+		requestInfo: method + " " + req.URL.RequestURI(),
 	}, nil
 }
 
@@ -122,4 +122,7 @@ func TestRawRequest_NonSuccessIsNotError(t *testing.T) {
 	if string(resp.Body) != `{"message":"not found"}` {
 		t.Errorf("Body = %q", resp.Body)
 	}
+	if err := resp.Err(); err == nil || err.Error() != "GET /nope: bitrise API 404: not found" {
+		t.Errorf("Err() = %v, want the typed methods' *APIError", err)
+	}
 }
@@ -48,6 +48,12 @@ func WithHTTPClient(hc *http.Client) Option {
 	return func(c *Client) { c.httpClient = hc }
 }
 
+// WithTransport sets the RoundTripper of the client's HTTP client, keeping
+// its timeout. The CLI passes its retrying transport here.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) { c.httpClient.Transport = rt }
+}
+
 // New creates a Client authenticated with the given token and base URL.
 // baseURL should be the RDE API root (e.g. https://api.bitrise.io/rde) —
 // resource paths are appended verbatim.
@@ -0,0 +1,83 @@
+package rde
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RawResponse is the result of a RawRequest call: the full HTTP response with
+// its status, headers, and body read into memory. A non-2xx status is a
+// normal response here; Err turns it into the same *APIError the typed
+// methods return.
+type RawResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Err returns nil for a 2xx response and the parsed *APIError otherwise.
+func (r *RawResponse) Err() error {
+	if r.StatusCode >= 200 && r.StatusCode < 300 {
+		return nil
+	}
+	return parseAPIError(r.StatusCode, r.Body)
+}
+
+// RawRequest issues an arbitrary authenticated request to the RDE API and
+// returns the full response — the `api --service rde` passthrough. path is
+// relative to the base URL (e.g. "/v1/me"; the leading slash is optional) or
+// an absolute http(s):// URL, used verbatim. query is merged onto any query
+// already in path; header values override the defaults (Authorization,
+// Accept, User-Agent, X-Request-Source).
+//
+// Only transport or request-construction failures return an error.
+func (c *Client) RawRequest(ctx context.Context, method, path string, query url.Values, header http.Header, body io.Reader) (*RawResponse, error) {
+	raw := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		raw = c.baseURL + "/" + strings.TrimLeft(path, "/")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse URL: %w", err)
+	}
+	if len(query) > 0 {
+		merged := u.Query()
+		for k, vs := range query {
+			for _, v := range vs {
+				merged.Add(k, v)
+			}
+		}
+		u.RawQuery = merged.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set("X-Request-Source", RequestSource)
+	for k, vs := range header {
+		req.Header.Del(k)
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req) //nolint:gosec // the raw API command intentionally lets the user control the method and URL
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return &RawResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}, nil
+}
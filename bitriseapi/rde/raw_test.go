@@ -0,0 +1,41 @@
+package rde
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestRawRequest_AuthQueryAndError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("X-Request-Source"); got != RequestSource {
+			t.Errorf("X-Request-Source = %q", got)
+		}
+		if r.URL.Path != "/rde/v1/sessions" || r.URL.Query().Get("pageSize") != "5" || r.URL.Query().Get("pageToken") != "t" {
+			t.Errorf("got %s", r.URL.RequestURI())
+		}
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `{"code":7,"message":"no access"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(srv.URL+"/rde/", "tok")
+	resp, err := c.RawRequest(context.Background(), http.MethodGet, "v1/sessions?pageSize=5", url.Values{"pageToken": {"t"}}, nil, nil)
+	if err != nil {
+		t.Fatalf("RawRequest: %v", err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("StatusCode = %d, want 403", resp.StatusCode)
+	}
+	apiErr, ok := errors.AsType[*APIError](resp.Err())
+	if !ok || apiErr.Message != "no access" {
+		t.Errorf("Err() = %v, want the parsed *APIError", resp.Err())
+	}
+}
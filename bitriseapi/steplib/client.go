@@ -0,0 +1,322 @@
+// Package steplib reads step metadata from the Bitrise StepLib: the git
+// repository that bitrise.yml files name as default_step_lib_source
+// (https://github.com/bitrise-io/bitrise-steplib), through the GitHub REST
+// API.
+//
+// The StepLib keeps one directory per step, steps/STEP_ID, holding
+// step-info.yml (maintainer, deprecation) and one directory per published
+// version with that version's step.yml. This is the layout stepman reads
+// (https://github.com/bitrise-io/stepman, models.StepModel and
+// models.StepGroupInfoModel). Release notes are not part of the StepLib;
+// they are the GitHub releases of the step's source repository (the
+// source_code_url of its step.yml).
+//
+// This is a sibling of bitriseapi/ rather than part of it: it talks to
+// GitHub, sends no Bitrise token, and decodes YAML.
+package steplib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Repo is the GitHub "owner/repo" of the default StepLib.
+	Repo = "bitrise-io/bitrise-steplib"
+
+	// maxBodyBytes caps a response read into memory.
+	maxBodyBytes = 8 << 20
+	// assetsDir sits beside the version directories of a step and holds
+	// its icons.
+	assetsDir = "assets"
+)
+
+// Client reads the StepLib and step releases from GitHub.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTransport sets the RoundTripper of the client's HTTP client. The CLI
+// passes its caching, retrying transport here.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) { c.httpClient.Transport = rt }
+}
+
+// New returns a Client for the GitHub API at baseURL
+// (https://api.github.com in production).
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{baseURL: strings.TrimRight(baseURL, "/"), httpClient: &http.Client{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is a non-2xx response from GitHub.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("GitHub API %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("GitHub API %d", e.StatusCode)
+}
+
+// IsNotFound reports whether err is a 404 from GitHub.
+func IsNotFound(err error) bool {
+	apiErr, ok := errors.AsType[*APIError](err)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Step is the step.yml of one step version (the fields the CLI shows).
+type Step struct {
+	Title           string    `yaml:"title"`
+	Summary         string    `yaml:"summary"`
+	Description     string    `yaml:"description"`
+	Website         string    `yaml:"website"`
+	SourceCodeURL   string    `yaml:"source_code_url"`
+	SupportURL      string    `yaml:"support_url"`
+	PublishedAt     time.Time `yaml:"published_at"`
+	HostOSTags      []string  `yaml:"host_os_tags"`
+	ProjectTypeTags []string  `yaml:"project_type_tags"`
+	TypeTags        []string  `yaml:"type_tags"`
+	Inputs          []EnvItem `yaml:"inputs"`
+	Outputs         []EnvItem `yaml:"outputs"`
+}
+
+// EnvItem is one input or output of a step.yml: a single KEY: value pair,
+// optionally with an opts mapping.
+type EnvItem struct {
+	Key   string
+	Value string
+	Opts  EnvOpts
+}
+
+// EnvOpts is the opts mapping of an EnvItem.
+type EnvOpts struct {
+	Title        string   `yaml:"title"`
+	Summary      string   `yaml:"summary"`
+	Description  string   `yaml:"description"`
+	IsRequired   bool     `yaml:"is_required"`
+	IsSensitive  bool     `yaml:"is_sensitive"`
+	ValueOptions []string `yaml:"value_options"`
+}
+
+// UnmarshalYAML decodes an env item mapping. Values may be any scalar
+// (true, 10); they are kept as their YAML text.
+func (e *EnvItem) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: env item must be a mapping", n.Line)
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i].Value, n.Content[i+1]
+		if key == "opts" {
+			if err := val.Decode(&e.Opts); err != nil {
+				return err
+			}
+			continue
+		}
+		if e.Key != "" {
+			return fmt.Errorf("line %d: env item defines both %s and %s", n.Line, e.Key, key)
+		}
+		e.Key, e.Value = key, val.Value
+	}
+	return nil
+}
+
+// Info is a step's step-info.yml: what holds for every version.
+type Info struct {
+	Maintainer     string `yaml:"maintainer"`
+	DeprecateNotes string `yaml:"deprecate_notes"`
+	RemovalDate    string `yaml:"removal_date"`
+}
+
+// Release is one GitHub release of a step's source repository.
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"published_at"`
+	Draft       bool      `json:"draft"`
+}
+
+// Versions returns the published versions of stepID, in no particular
+// order: the version directories under steps/STEP_ID.
+func (c *Client) Versions(ctx context.Context, stepID string) ([]string, error) {
+	var entries []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+	if err := c.getJSON(ctx, contentsPath("steps", stepID), &entries); err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Type == "dir" && e.Name != assetsDir {
+			versions = append(versions, e.Name)
+		}
+	}
+	return versions, nil
+}
+
+// Step returns the step.yml of stepID at version.
+func (c *Client) Step(ctx context.Context, stepID, version string) (Step, error) {
+	var s Step
+	if err := c.getYAML(ctx, contentsPath("steps", stepID, version, "step.yml"), &s); err != nil {
+		return Step{}, err
+	}
+	return s, nil
+}
+
+// Info returns the step-info.yml of stepID. Steps without one get a zero
+// Info.
+func (c *Client) Info(ctx context.Context, stepID string) (Info, error) {
+	var info Info
+	err := c.getYAML(ctx, contentsPath("steps", stepID, "step-info.yml"), &info)
+	if IsNotFound(err) {
+		return Info{}, nil
+	}
+	return info, err
+}
+
+// Releases returns the published releases of the GitHub repository at
+// sourceURL (https://github.com/OWNER/REPO, with or without .git), newest
+// first, following the Link header across pages. Sources not hosted on
+// GitHub have no releases here: nil, nil.
+func (c *Client) Releases(ctx context.Context, sourceURL string) ([]Release, error) {
+	repo, ok := githubRepo(sourceURL)
+	if !ok {
+		return nil, nil
+	}
+	var releases []Release
+	next := c.baseURL + "/repos/" + repo + "/releases?per_page=100"
+	for next != "" {
+		body, header, err := c.do(ctx, next, "application/vnd.github+json")
+		if err != nil {
+			return nil, err
+		}
+		var page []Release
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("decode releases of %s: %w", repo, err)
+		}
+		releases = append(releases, page...)
+		next = nextLink(header.Get("Link"))
+		// Only follow pages of the same API; the client sends nothing
+		// secret, but shouldn't be steered elsewhere either.
+		if !strings.HasPrefix(next, c.baseURL+"/") {
+			next = ""
+		}
+	}
+	return slices.DeleteFunc(releases, func(r Release) bool { return r.Draft }), nil
+}
+
+// nextLink returns the rel="next" URL of a Link header
+// (<https://…?page=2>; rel="next", <…>; rel="last"), or "".
+func nextLink(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok {
+			continue
+		}
+		for _, p := range strings.Split(params, ";") {
+			if strings.TrimSpace(p) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// contentsPath is the contents API path of a file or directory in the
+// StepLib repository.
+func contentsPath(parts ...string) string {
+	escaped := make([]string, len(parts))
+	for i, p := range parts {
+		escaped[i] = url.PathEscape(p)
+	}
+	return "/repos/" + Repo + "/contents/" + strings.Join(escaped, "/")
+}
+
+// githubRepo extracts "owner/repo" from a github.com repository URL.
+func githubRepo(sourceURL string) (string, bool) {
+	u, err := url.Parse(sourceURL)
+	if err != nil || !strings.EqualFold(u.Host, "github.com") {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+	return parts[0] + "/" + strings.TrimSuffix(parts[1], ".git"), true
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, v any) error {
+	body, err := c.get(ctx, path, "application/vnd.github+json")
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Client) getYAML(ctx context.Context, path string, v any) error {
+	// The raw media type returns the file itself rather than a base64
+	// envelope.
+	body, err := c.get(ctx, path, "application/vnd.github.raw")
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, path, accept string) ([]byte, error) {
+	body, _, err := c.do(ctx, c.baseURL+path, accept)
+	return body, err
+}
+
+func (c *Client) do(ctx context.Context, rawURL, accept string) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var e struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return nil, nil, &APIError{StatusCode: resp.StatusCode, Message: e.Message}
+	}
+	return body, resp.Header, nil
+}
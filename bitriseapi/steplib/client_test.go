@@ -0,0 +1,114 @@
+package steplib
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStep_DecodesEnvItems(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/bitrise-io/bitrise-steplib/contents/steps/script/1.2.0/step.yml" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		_, _ = io.WriteString(w, `title: Script
+inputs:
+- is_debug: "no"
+  opts:
+    title: Debug
+    value_options: ["yes", "no"]
+- timeout: 10
+  opts:
+    is_required: true
+    value_options: [10, 20]
+outputs:
+- RESULT:
+`)
+	}))
+	defer srv.Close()
+
+	s, err := New(srv.URL).Step(context.Background(), "script", "1.2.0")
+	if err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	if len(s.Inputs) != 2 || len(s.Outputs) != 1 {
+		t.Fatalf("got %d inputs, %d outputs", len(s.Inputs), len(s.Outputs))
+	}
+	if in := s.Inputs[0]; in.Key != "is_debug" || in.Value != "no" || in.Opts.Title != "Debug" {
+		t.Errorf("inputs[0] = %+v", in)
+	}
+	if in := s.Inputs[1]; in.Value != "10" || !in.Opts.IsRequired || len(in.Opts.ValueOptions) != 2 || in.Opts.ValueOptions[1] != "20" {
+		t.Errorf("inputs[1] = %+v, want scalar values kept as text", in)
+	}
+	if out := s.Outputs[0]; out.Key != "RESULT" || out.Value != "" {
+		t.Errorf("outputs[0] = %+v", out)
+	}
+}
+
+func TestInfo_MissingFileIsZero(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"message":"Not Found"}`)
+	}))
+	defer srv.Close()
+
+	info, err := New(srv.URL).Info(context.Background(), "script")
+	if err != nil || info != (Info{}) {
+		t.Errorf("Info = %+v, %v; want zero, nil", info, err)
+	}
+	if _, err := New(srv.URL).Versions(context.Background(), "script"); !IsNotFound(err) {
+		t.Errorf("Versions err = %v, want a 404", err)
+	}
+}
+
+func TestReleases_OnlyGitHubSources(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		_, _ = io.WriteString(w, `[{"tag_name":"2.0.0","draft":true},{"tag_name":"1.0.0","body":"First."}]`)
+	}))
+	defer srv.Close()
+	c := New(srv.URL)
+
+	got, err := c.Releases(context.Background(), "https://github.com/bitrise-steplib/steps-script.git")
+	if err != nil {
+		t.Fatalf("Releases: %v", err)
+	}
+	if len(got) != 1 || got[0].TagName != "1.0.0" {
+		t.Errorf("releases = %+v, want drafts dropped", got)
+	}
+	if got, err := c.Releases(context.Background(), "https://gitlab.com/org/step"); got != nil || err != nil {
+		t.Errorf("non-GitHub source: %+v, %v", got, err)
+	}
+	if len(paths) != 1 || paths[0] != "/repos/bitrise-steplib/steps-script/releases" {
+		t.Errorf("requests = %v", paths)
+	}
+}
+
+func TestReleases_FollowsNextLink(t *testing.T) {
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<`+srvURL+`/repositories/1/releases?per_page=100&page=2>; rel="next", <`+srvURL+`/repositories/1/releases?per_page=100&page=2>; rel="last"`)
+			_, _ = io.WriteString(w, `[{"tag_name":"2.0.0"}]`)
+		case "2":
+			w.Header().Set("Link", `<`+srvURL+`/repositories/1/releases?per_page=100&page=1>; rel="prev"`)
+			_, _ = io.WriteString(w, `[{"tag_name":"1.0.0"}]`)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	got, err := New(srv.URL).Releases(context.Background(), "https://github.com/bitrise-steplib/steps-script")
+	if err != nil {
+		t.Fatalf("Releases: %v", err)
+	}
+	if len(got) != 2 || got[1].TagName != "1.0.0" {
+		t.Errorf("releases = %+v, want both pages", got)
+	}
+}
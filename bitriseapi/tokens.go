@@ -0,0 +1,75 @@
+package bitriseapi
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// AccessToken is a Personal Access Token's metadata as returned by
+// GET /me/access-tokens. The token secret is never listed; it is returned
+// once, by CreateAccessToken.
+type AccessToken struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	Scopes      []string  `json:"scopes,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitzero"`
+	ExpiresAt   time.Time `json:"expires_at,omitzero"` // zero when the token never expires
+	LastUsedAt  time.Time `json:"last_used_at,omitzero"`
+}
+
+// CreateAccessTokenRequest is the JSON body for POST /me/access-tokens.
+type CreateAccessTokenRequest struct {
+	Description string `json:"description"`
+	// ExpiresIn is the lifetime in seconds; 0 mints a non-expiring token
+	// and nil leaves the server's default lifetime.
+	ExpiresIn *int64 `json:"expires_in,omitempty"`
+}
+
+// CreatedAccessToken is a freshly minted token: its metadata plus the
+// secret, which the API returns only in this response.
+type CreatedAccessToken struct {
+	AccessToken
+	Token string `json:"token"`
+}
+
+// TokenInfo describes the token the client authenticates with. Type is
+// "personal" or "workspace"; User is set for a personal token and Workspace
+// for a workspace token.
+type TokenInfo struct {
+	Type        string        `json:"type"`
+	ID          string        `json:"id,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Scopes      []string      `json:"scopes,omitempty"`
+	ExpiresAt   time.Time     `json:"expires_at,omitzero"`
+	User        *User         `json:"user,omitempty"`
+	Workspace   *Organization `json:"workspace,omitempty"`
+}
+
+// AccessTokens lists the authenticated user's Personal Access Tokens.
+// Endpoint: GET /me/access-tokens.
+func (c *Client) AccessTokens(ctx context.Context) ([]AccessToken, error) {
+	return get[[]AccessToken](ctx, c, "/me/access-tokens", nil)
+}
+
+// CreateAccessToken mints a new Personal Access Token for the authenticated
+// user. Endpoint: POST /me/access-tokens.
+func (c *Client) CreateAccessToken(ctx context.Context, req CreateAccessTokenRequest) (CreatedAccessToken, error) {
+	env, err := postDecode[CreateAccessTokenRequest, envelope[CreatedAccessToken]](ctx, c, "/me/access-tokens", req)
+	if err != nil {
+		return CreatedAccessToken{}, err
+	}
+	return env.Data, nil
+}
+
+// RevokeAccessToken deletes one of the authenticated user's Personal Access
+// Tokens. Endpoint: DELETE /me/access-tokens/{id}.
+func (c *Client) RevokeAccessToken(ctx context.Context, id string) error {
+	return del(ctx, c, "/me/access-tokens/"+url.PathEscape(id))
+}
+
+// TokenInfo introspects the token the client authenticates with.
+// Endpoint: GET /token-info.
+func (c *Client) TokenInfo(ctx context.Context) (TokenInfo, error) {
+	return get[TokenInfo](ctx, c, "/token-info", nil)
+}
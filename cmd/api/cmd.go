@@ -3,6 +3,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,23 +11,37 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 
 	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
 	internalapi "github.com/bitrise-io/bitrise-cli/internal/api"
+	"github.com/bitrise-io/bitrise-cli/internal/httpcache"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+// Services accepted by --service.
+const (
+	serviceV01 = "v0.1"
+	serviceRDE = "rde"
 )
 
 // NewCmd returns the `bitrise-cli api` command.
 func NewCmd() *cobra.Command {
 	var (
-		method   string
-		fields   []string
-		headers  []string
-		input    string
-		paginate bool
-		include  bool
+		method    string
+		fields    []string
+		headers   []string
+		input     string
+		paginate  bool
+		pageLimit int
+		include   bool
+		service   string
+		cacheTTL  time.Duration
+		fromFile  string
+		vars      []string
 	)
 
 	c := &cobra.Command{
@@ -36,7 +51,9 @@ func NewCmd() *cobra.Command {
 
 PATH is resolved against the configured API base URL (https://api.bitrise.io/v0.1
 by default), so "/me" and "me" both work; an absolute http(s):// URL is used
-verbatim.
+verbatim. With --service rde, PATH is resolved against the Remote Dev
+Environments API base URL instead (rde_api_base_url), requests use its Bearer
+auth, and errors are reported the way the rde commands report them.
 
 The method defaults to GET, or POST when a body is supplied via --field or
 --input. Use -X to set it explicitly.
@@ -47,24 +64,94 @@ Parameters (--field/-f, key=value):
 For request bodies the CLI can't express as flat key=value pairs (e.g. nested
 objects), pass the JSON directly with --input.
 
+Pagination:
+  --all follows the cursor of list endpoints and merges every page of the
+  v0.1 {"data":[…],"paging":{"next":…}} envelope. --paginate-limit N stops
+  after N pages (and implies --all), with a warning on stderr if more were
+  left. The RDE API's lists have no cursor, so --service rde rejects both.
+
+Caching:
+  --cache TTL (e.g. 5m) keeps the GET response on disk and answers the same
+  request from there until it is TTL old. --no-cache fetches it fresh.
+
 Output:
-  --output is ignored — the response body is written to stdout as-is. JSON is
+  By default the response body is written to stdout as-is. JSON is
   pretty-printed when stdout is a terminal; piped output is passed through
-  unmodified, so "... | jq" works. A non-2xx status still prints the body but
-  exits non-zero, with a diagnostic on stderr.`,
+  unmodified, so "... | jq" works. --jq and --template filter a JSON response,
+  and --output yaml|csv|tsv converts it; --output json and human leave it
+  as-is. A non-2xx status still prints the body but exits non-zero, with a
+  diagnostic on stderr.
+
+Request files (--from-file):
+  Replays the requests of a REST-client style .http file in order, stopping at
+  the first non-2xx response. Requests are separated by "###" lines; each is
+  a request line (METHOD PATH), optional headers, a blank line, and an
+  optional body. "@name = value" lines define variables used as {{name}};
+  --var name=value overrides them. -H headers apply to every request.
+  Each request's status goes to stderr and its body to stdout.`,
 		Example: `  bitrise-cli api /me
   bitrise-cli api /apps -f sort_by=last_build_at --all | jq '.data[].title'
-  bitrise-cli api /apps/APP_ID/builds?limit=10
+  bitrise-cli api /apps/APP_ID/builds?limit=10 --jq '.data[].status_text'
   bitrise-cli api /apps/APP_ID/builds -X POST --input body.json
-  bitrise-cli api -X DELETE /apps/APP_ID/builds/BUILD_ID -i`,
-		Args: cmdutil.RequireArgs("PATH"),
+  bitrise-cli api -X DELETE /apps/APP_ID/builds/BUILD_ID -i
+  bitrise-cli api --service rde /v1/workspaces/WORKSPACE_ID/sessions --all -o csv
+  bitrise-cli api /organizations --cache 10m
+  bitrise-cli api --from-file requests.http --var app=APP_ID`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if fromFile != "" {
+				if len(args) > 0 {
+					return fmt.Errorf("PATH cannot be combined with --from-file")
+				}
+				return nil
+			}
+			return cmdutil.RequireArgs("PATH")(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			client, err := cmdutil.NewAPIClient(cmd)
+			if pageLimit < 0 {
+				return fmt.Errorf("--paginate-limit must not be negative")
+			}
+			if cacheTTL < 0 {
+				return fmt.Errorf("--cache must not be negative")
+			}
+			svc, err := newService(cmd, service)
 			if err != nil {
 				return err
 			}
+			ctx := cmd.Context()
+			if cacheTTL > 0 {
+				ctx = httpcache.WithTTL(ctx, cacheTTL)
+			}
+			hdr, err := parseHeaders(headers)
+			if err != nil {
+				return err
+			}
+			x := exchange{
+				svc:       svc,
+				out:       cmd.OutOrStdout(),
+				errOut:    cmd.ErrOrStderr(),
+				quiet:     cmdutil.IsQuiet(cmd),
+				format:    cmdutil.ResolveFormat(cmd),
+				include:   include,
+				paginate:  paginate || pageLimit > 0,
+				pageLimit: pageLimit,
+			}
 
-			path := args[0]
+			if fromFile != "" {
+				varMap, err := parseVars(vars)
+				if err != nil {
+					return err
+				}
+				r, cleanup, err := openInput(cmd, fromFile, "--from-file")
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+				reqs, err := internalapi.ParseHTTPFile(r, varMap)
+				if err != nil {
+					return fmt.Errorf("parse %s: %w", fromFile, err)
+				}
+				return x.replay(ctx, reqs, hdr)
+			}
 
 			hasBody := len(fields) > 0 || input != ""
 			m := strings.ToUpper(method)
@@ -79,14 +166,10 @@ Output:
 			if err != nil {
 				return err
 			}
-			hdr, err := parseHeaders(headers)
-			if err != nil {
-				return err
-			}
 
 			var body io.Reader
 			if input != "" {
-				r, cleanup, err := openInput(cmd, input)
+				r, cleanup, err := openInput(cmd, input, "--input")
 				if err != nil {
 					return err
 				}
@@ -94,27 +177,13 @@ Output:
 				body = r
 			}
 
-			svc := internalapi.NewService(client)
-			resp, err := svc.Do(cmd.Context(), internalapi.Request{
-				Method:   m,
-				Path:     path,
-				Fields:   kvFields,
-				Headers:  hdr,
-				Body:     body,
-				Paginate: paginate,
+			return x.do(ctx, internalapi.Request{
+				Method:  m,
+				Path:    args[0],
+				Fields:  kvFields,
+				Headers: hdr,
+				Body:    body,
 			})
-			if err != nil {
-				return err
-			}
-
-			if err := writeResponse(cmd.OutOrStdout(), resp, include); err != nil {
-				return err
-			}
-
-			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-				return fmt.Errorf("bitrise API responded with HTTP %d", resp.StatusCode)
-			}
-			return nil
 		},
 	}
 
@@ -122,12 +191,127 @@ Output:
 	c.Flags().StringArrayVarP(&fields, "field", "f", nil, "add a key=value parameter (repeatable): query param for GET, JSON body field otherwise")
 	c.Flags().StringArrayVarP(&headers, "header", "H", nil, "add or override a request header in 'Name: value' form (repeatable)")
 	c.Flags().StringVar(&input, "input", "", `read the request body from a file (use "-" for stdin)`)
-	c.Flags().BoolVar(&paginate, "all", false, "follow cursor pagination and merge every page's data array")
+	c.Flags().BoolVar(&paginate, "all", false, "follow cursor pagination and merge every page's list")
+	c.Flags().IntVar(&pageLimit, "paginate-limit", 0, "fetch at most this many pages (implies --all)")
 	c.Flags().BoolVarP(&include, "include", "i", false, "print the response status line and headers before the body")
+	c.Flags().StringVar(&service, "service", serviceV01, "API to call: v0.1 or rde")
+	c.Flags().DurationVar(&cacheTTL, "cache", 0, "cache the GET response on disk for this long (e.g. 5m)")
+	c.Flags().StringVar(&fromFile, "from-file", "", `replay the requests of a .http request file (use "-" for stdin)`)
+	c.Flags().StringArrayVar(&vars, "var", nil, "set a --from-file {{variable}} as name=value (repeatable)")
 	c.MarkFlagsMutuallyExclusive("field", "input")
+	c.MarkFlagsMutuallyExclusive("from-file", "field")
+	c.MarkFlagsMutuallyExclusive("from-file", "input")
+	c.MarkFlagsMutuallyExclusive("from-file", "method")
+	_ = c.RegisterFlagCompletionFunc("service", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{
+			serviceV01 + "\tBitrise API (api_base_url)",
+			serviceRDE + "\tRemote Dev Environments API (rde_api_base_url)",
+		}, cobra.ShellCompDirectiveNoFileComp
+	})
 	return c
 }
 
+func newService(cmd *cobra.Command, service string) (*internalapi.Service, error) {
+	switch service {
+	case serviceV01:
+		client, err := cmdutil.NewAPIClient(cmd)
+		if err != nil {
+			return nil, err
+		}
+		return internalapi.NewService(client), nil
+	case serviceRDE:
+		client, err := cmdutil.NewRDEClient(cmd)
+		if err != nil {
+			return nil, err
+		}
+		return internalapi.NewRDEService(client), nil
+	}
+	return nil, fmt.Errorf("invalid --service %q (expected: %s, %s)", service, serviceV01, serviceRDE)
+}
+
+// exchange performs requests and writes their responses per the command's
+// flags.
+type exchange struct {
+	svc       *internalapi.Service
+	out       io.Writer
+	errOut    io.Writer
+	quiet     bool
+	format    output.Format
+	include   bool
+	paginate  bool
+	pageLimit int
+}
+
+// do performs req and writes the response, returning an error for a non-2xx
+// status.
+func (x exchange) do(ctx context.Context, req internalapi.Request) error {
+	req.Paginate, req.PageLimit = x.paginate, x.pageLimit
+	resp, err := x.svc.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	if err := writeResponse(x.out, resp, x.include, x.format); err != nil {
+		return err
+	}
+	if resp.Truncated && !x.quiet {
+		if _, err := fmt.Fprintf(x.errOut, "Stopped after %d page(s) (--paginate-limit); more results are available\n", x.pageLimit); err != nil {
+			return err
+		}
+	}
+	return statusErr(resp)
+}
+
+// replay performs the requests of a request file in order, stopping at the
+// first failure. headers (-H) override each request's own.
+func (x exchange) replay(ctx context.Context, reqs []internalapi.FileRequest, headers http.Header) error {
+	paginate := x.paginate
+	for i, fr := range reqs {
+		hdr := fr.Headers
+		for k, vs := range headers {
+			hdr[k] = vs
+		}
+		var body io.Reader
+		if fr.Body != nil {
+			body = bytes.NewReader(fr.Body)
+		}
+		if !x.quiet {
+			if _, err := fmt.Fprintf(x.errOut, "[%d/%d] %s\n", i+1, len(reqs), fr.Label()); err != nil {
+				return err
+			}
+		}
+		x.paginate = paginate && fr.Method == http.MethodGet
+		err := x.do(ctx, internalapi.Request{Method: fr.Method, Path: fr.Path, Headers: hdr, Body: body})
+		if err != nil {
+			return fmt.Errorf("request %d (%s, line %d): %w", i+1, fr.Label(), fr.Line, err)
+		}
+	}
+	return nil
+}
+
+// statusErr reports a non-2xx response: the RDE API's parsed error, or the
+// bare status for the v0.1 API.
+func statusErr(resp internalapi.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.Err != nil {
+		return resp.Err
+	}
+	return fmt.Errorf("bitrise API responded with HTTP %d", resp.StatusCode)
+}
+
+func parseVars(raw []string) (map[string]string, error) {
+	out := make(map[string]string, len(raw))
+	for _, kv := range raw {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected name=value", kv)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
 func parseFields(raw []string) ([]internalapi.KeyValue, error) {
 	out := make([]internalapi.KeyValue, 0, len(raw))
 	for _, f := range raw {
@@ -155,18 +339,18 @@ func parseHeaders(raw []string) (http.Header, error) {
 	return h, nil
 }
 
-func openInput(cmd *cobra.Command, path string) (io.Reader, func(), error) {
+func openInput(cmd *cobra.Command, path, flag string) (io.Reader, func(), error) {
 	if path == "-" {
 		return cmd.InOrStdin(), func() {}, nil
 	}
-	f, err := os.Open(path) //nolint:gosec // the api command intentionally reads a user-named request-body file
+	f, err := os.Open(path) //nolint:gosec // the api command intentionally reads a user-named request file
 	if err != nil {
-		return nil, nil, fmt.Errorf("open --input file: %w", err)
+		return nil, nil, fmt.Errorf("open %s file: %w", flag, err)
 	}
 	return f, func() { _ = f.Close() }, nil
 }
 
-func writeResponse(out io.Writer, resp internalapi.Response, include bool) error {
+func writeResponse(out io.Writer, resp internalapi.Response, include bool, format output.Format) error {
 	if include {
 		ew := cmdutil.NewErrWriter(out)
 		ew.F("HTTP %d %s\n", resp.StatusCode, http.StatusText(resp.StatusCode))
@@ -186,6 +370,18 @@ func writeResponse(out io.Writer, resp internalapi.Response, include bool) error
 		}
 	}
 
+	ok := resp.StatusCode >= 200 && resp.StatusCode < 300
+	if ok && (output.Filtering() || (format.Structured() && format != output.JSON)) {
+		if !json.Valid(resp.Body) {
+			flag := output.FilterFlag()
+			if flag == "" {
+				flag = "--output " + string(format)
+			}
+			return fmt.Errorf("response is not JSON, so %s cannot apply to it", flag)
+		}
+		return output.Render(out, format, json.RawMessage(resp.Body), nil)
+	}
+
 	body := resp.Body
 	if shouldPrettyPrint(out, resp.Header) {
 		var buf bytes.Buffer
@@ -11,23 +11,32 @@ import (
 	"testing"
 
 	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
 )
 
 // run executes a fresh api command against srvURL with the given args,
 // returning stdout, stderr, and the execution error.
 func run(t *testing.T, srvURL string, args []string, stdin string) (string, string, error) {
+	t.Helper()
+	return runResolved(t, config.Resolved{
+		Output:        "human",
+		APIBaseURL:    srvURL,
+		RDEAPIBaseURL: srvURL,
+		Token:         "tok",
+	}, args, stdin)
+}
+
+// runResolved is run with explicit Resolved settings (e.g. another --output).
+func runResolved(t *testing.T, r config.Resolved, args []string, stdin string) (string, string, error) {
 	t.Helper()
 	c := NewCmd()
+	c.SilenceUsage = true // production root sets this; detached test cmd must too
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
 	c.SetOut(stdout)
 	c.SetErr(stderr)
 	c.SetIn(strings.NewReader(stdin))
-	c.SetContext(config.WithResolved(context.Background(), config.Resolved{
-		Output:     "human",
-		APIBaseURL: srvURL,
-		Token:      "tok",
-	}))
+	c.SetContext(config.WithResolved(context.Background(), r))
 	c.SetArgs(args)
 	err := c.Execute()
 	return stdout.String(), stderr.String(), err
@@ -182,3 +191,130 @@ func TestAPICmd_RequiresPath(t *testing.T) {
 		t.Errorf("expected missing PATH error, got %v", err)
 	}
 }
+
+func TestAPICmd_ServiceRDE(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q, want Bearer auth", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path != "/v1/workspaces/w/sessions":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"code":5,"message":"workspace not found"}`)
+		default:
+			_, _ = io.WriteString(w, `{"sessions":[{"id":"a"}]}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	stdout, _, err := run(t, srv.URL, []string{"--service", "rde", "/v1/workspaces/w/sessions"}, "")
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if want := `{"sessions":[{"id":"a"}]}`; stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+	if _, _, err := run(t, srv.URL, []string{"--service", "rde", "/v1/workspaces/w/sessions", "--paginate-limit", "2"}, ""); err == nil {
+		t.Error("expected --paginate-limit to be rejected for the RDE API")
+	}
+
+	_, _, err = run(t, srv.URL, []string{"--service", "rde", "/v1/workspaces/x/sessions"}, "")
+	if err == nil || !strings.Contains(err.Error(), "workspace not found") {
+		t.Errorf("err = %v, want the parsed RDE API error", err)
+	}
+}
+
+func TestAPICmd_OutputConvertsJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"data":[{"slug":"a","title":"One"},{"slug":"b","title":"Two"}]}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	stdout, _, err := runResolved(t, config.Resolved{Output: "csv", APIBaseURL: srv.URL, Token: "tok"}, []string{"/apps"}, "")
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if want := "slug,title\na,One\nb,Two\n"; stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+}
+
+func TestAPICmd_NonJSONNamesTheFlagGiven(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "plain text")
+	}))
+	t.Cleanup(srv.Close)
+	r := config.Resolved{Output: "yaml", APIBaseURL: srv.URL, Token: "tok"}
+
+	_, _, err := runResolved(t, r, []string{"/logs"}, "")
+	if err == nil || !strings.Contains(err.Error(), "so --output yaml cannot") {
+		t.Errorf("--output yaml: err = %v", err)
+	}
+
+	if err := output.Configure(output.Options{JQ: "."}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = output.Configure(output.Options{}) })
+	r.Output = "human"
+	_, _, err = runResolved(t, r, []string{"/logs"}, "")
+	if err == nil || !strings.Contains(err.Error(), "so --jq cannot") {
+		t.Errorf("--jq: err = %v", err)
+	}
+}
+
+func TestAPICmd_FromFileReplaysUntilFailure(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("X-Env")+" "+string(body))
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	const file = `@app = a1
+
+### List
+GET /apps/{{app}}/builds?limit=1
+
+### Trigger
+POST /apps/{{app}}/builds
+
+{"branch": "{{branch}}"}
+
+### Broken
+GET /fail
+
+### Never reached
+GET /after
+`
+	stdout, stderr, err := run(t, srv.URL, []string{"--from-file", "-", "--var", "branch=main", "-H", "X-Env: test"}, file)
+	if err == nil || !strings.Contains(err.Error(), "request 3 (Broken, line 12)") {
+		t.Errorf("err = %v, want the failing request named", err)
+	}
+	want := []string{
+		"GET /apps/a1/builds?limit=1 test ",
+		`POST /apps/a1/builds test {"branch": "main"}`,
+		"GET /fail test ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if stdout != "{}{}{}" {
+		t.Errorf("stdout = %q, want each body", stdout)
+	}
+	if !strings.Contains(stderr, "[2/4] Trigger") {
+		t.Errorf("stderr should report progress, got %q", stderr)
+	}
+}
+
+func TestAPICmd_FromFileRejectsPath(t *testing.T) {
+	_, _, err := run(t, "https://example.invalid", []string{"/me", "--from-file", "x.http"}, "")
+	if err == nil || !strings.Contains(err.Error(), "--from-file") {
+		t.Errorf("expected PATH/--from-file conflict, got %v", err)
+	}
+}
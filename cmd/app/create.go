@@ -50,6 +50,8 @@ bitrise.yml handling:
   --bitrise-yml PATH   upload that file as the app's config
   (no flag, ./bitrise.yml exists)   upload it
   (no flag, no file)   skip — server preset for --project-type takes effect
+  Run 'bitrise-cli yml init' first to scaffold ./bitrise.yml from the local
+  project instead of relying on the preset.
 
 The new app's ID is saved as the global default app_id, so subsequent
 commands (build trigger, build list, ...) target it without --app.`,
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -11,11 +15,10 @@ import (
 	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
 	"github.com/bitrise-io/bitrise-cli/internal/auth"
 	"github.com/bitrise-io/bitrise-cli/internal/config"
-	"github.com/bitrise-io/bitrise-cli/internal/oauth"
 	"github.com/bitrise-io/bitrise-cli/internal/output"
 	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internaltoken "github.com/bitrise-io/bitrise-cli/internal/token"
 	internaluser "github.com/bitrise-io/bitrise-cli/internal/user"
-	"github.com/bitrise-io/bitrise-cli/internal/webclient"
 )
 
 // newAuthCmd returns the `bitrise-cli auth` parent command and its subcommands.
@@ -33,20 +36,39 @@ func newAuthCmd() *cobra.Command {
 Both Personal Access Tokens (PAT) and Workspace API Tokens (WAT) work the
 same way on the wire — paste either kind here.
 
-Storage:
-  YAML file at $XDG_CONFIG_HOME/bitrise/auth.yaml (or ~/.config/bitrise/auth.yaml).
-  Written with 0600 permissions, separate from preferences in config.yaml.
+Storage (the credential_store config key):
+  file            YAML at $XDG_CONFIG_HOME/bitrise/auth.yaml (or
+                  ~/.config/bitrise/auth.yaml), 0600, separate from preferences
+                  in config.yaml. The default.
+  keychain        the OS credential manager: macOS Keychain, Windows Credential
+                  Manager, or the Secret Service (GNOME Keyring, KWallet) via
+                  secret-tool on Linux.
+  encrypted-file  auth.enc next to auth.yaml, encrypted with a passphrase you
+                  enter once per command (or set BITRISE_CREDENTIAL_PASSPHRASE).
+  Switch with 'bitrise-cli auth migrate-store --to STORE', which moves the
+  saved tokens across.
+
+Profiles:
+  The file holds one token per profile, so several accounts (say, a client's
+  workspace and your own, or production and staging) can stay signed in side
+  by side. Every auth command acts on the active profile — "default" unless
+  --profile, BITRISE_PROFILE, or 'config use-profile' picks another.
 
 Env override:
   BITRISE_TOKEN takes precedence over the saved token; useful for CI.`,
 		Example: `  bitrise-cli auth status
   bitrise-cli auth login
+  bitrise-cli auth login --profile staging
+  bitrise-cli auth token create --description ci --expires-in 90d
+  bitrise-cli auth migrate-store --to keychain
   bitrise-cli auth logout`,
 	}
 	c.AddCommand(
 		newAuthLoginCmd(),
 		newAuthLogoutCmd(),
 		newAuthStatusCmd(),
+		newAuthTokenCmd(),
+		newAuthMigrateStoreCmd(),
 	)
 	return c
 }
@@ -58,6 +80,7 @@ func newAuthLoginCmd() *cobra.Command {
 		passwordStdin bool
 		oauthLogin    bool
 		webLogin      bool
+		deviceLogin   bool
 	)
 	c := &cobra.Command{
 		Use:   "login",
@@ -76,7 +99,14 @@ Bitrise (OAuth) and stores a managed, auto-refreshing token. The modes:
 
      This needs the browser on the same machine as the CLI (the sign-in is
      handed back over a loopback address). On a remote/headless host over SSH
-     it can't complete — pipe a token instead (see below).
+     or in an RDE session it can't complete — use --device instead.
+
+  Device code (--device).
+     Prints a URL and a short code; open the URL on any device (your laptop,
+     your phone), enter the code and approve. The CLI waits for the approval
+     and stores the same managed, auto-refreshing token as browser sign-in:
+
+         bitrise-cli auth login --device
 
   Token (--with-token, or any non-interactive stdin).
      Reads a Personal Access Token from stdin. This is also used automatically
@@ -95,16 +125,21 @@ Bitrise (OAuth) and stores a managed, auto-refreshing token. The modes:
          bitrise-cli auth login --email alice@example.com
          printf '%s' "$PW" | bitrise-cli auth login --email alice@example.com --password-stdin
 
-The resulting token is written to $XDG_CONFIG_HOME/bitrise/auth.yaml with 0600
-permissions and is never echoed (use 'auth status' to verify, 'auth logout' to
-clear).`,
+The resulting token is written to the credential store (auth.yaml with 0600
+permissions by default; see 'bitrise-cli auth') and is never echoed (use 'auth status' to verify, 'auth logout' to
+clear). It replaces the active profile's token only; pass --profile NAME to
+sign in to another account without losing this one.`,
 		Example: `  bitrise-cli auth login                                     # browser sign-in (OAuth)
+  bitrise-cli auth login --device                            # sign in from an SSH/headless host
   echo "$BITRISE_PAT" | bitrise-cli auth login --with-token  # paste/pipe a token
-  bitrise-cli auth login --email alice@example.com           # email/password`,
+  bitrise-cli auth login --email alice@example.com           # email/password
+  bitrise-cli auth login --profile staging                   # a second account`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			switch {
 			case oauthLogin || webLogin:
 				return runOAuthLogin(cmd)
+			case deviceLogin:
+				return runDeviceLogin(cmd)
 			case emailLogin != "":
 				return runEmailLogin(cmd, emailLogin, passwordStdin)
 			case withToken:
@@ -128,13 +163,16 @@ clear).`,
 	// --web is a hidden alias for --oauth ("open in the browser").
 	c.Flags().BoolVar(&webLogin, cmdutil.FlagWeb, false, "alias for --oauth")
 	_ = c.Flags().MarkHidden(cmdutil.FlagWeb)
-	// The three login modes are mutually exclusive. --oauth and --web are
-	// aliases, so they're not exclusive with each other.
-	for _, mode := range []string{"oauth", cmdutil.FlagWeb} {
+	c.Flags().BoolVar(&deviceLogin, "device", false, "sign in with a device code on another device (for SSH/headless hosts)")
+	// The login modes are mutually exclusive. --oauth and --web are aliases,
+	// so they're not exclusive with each other.
+	for _, mode := range []string{"oauth", cmdutil.FlagWeb, "device"} {
 		c.MarkFlagsMutuallyExclusive(mode, "with-token")
 		c.MarkFlagsMutuallyExclusive(mode, "email")
 		c.MarkFlagsMutuallyExclusive(mode, "password-stdin")
 	}
+	c.MarkFlagsMutuallyExclusive("device", "oauth")
+	c.MarkFlagsMutuallyExclusive("device", cmdutil.FlagWeb)
 	c.MarkFlagsMutuallyExclusive("with-token", "email")
 	c.MarkFlagsMutuallyExclusive("with-token", "password-stdin")
 	return c
@@ -151,7 +189,7 @@ func runTokenLogin(cmd *cobra.Command) error {
 	if tok == "" {
 		return fmt.Errorf("token is empty")
 	}
-	if err := auth.Save(auth.Auth{Token: tok}); err != nil {
+	if err := auth.SaveProfile(resolvedFromCmd(cmd).Profile, auth.Auth{Token: tok}); err != nil {
 		return err
 	}
 	return confirmLoginSaved(cmd)
@@ -165,7 +203,7 @@ func runEmailLogin(cmd *cobra.Command, email string, passwordStdin bool) error {
 	if pw == "" {
 		return fmt.Errorf("password is empty")
 	}
-	wc, err := webclient.New(cmdutil.ResolveWebBaseURL(cmd))
+	wc, err := cmdutil.NewWebClient(cmd)
 	if err != nil {
 		return err
 	}
@@ -181,7 +219,7 @@ func runEmailLogin(cmd *cobra.Command, email string, passwordStdin bool) error {
 		}
 		return err
 	}
-	if err := auth.Save(auth.Auth{Token: tok}); err != nil {
+	if err := auth.SaveProfile(resolvedFromCmd(cmd).Profile, auth.Auth{Token: tok}); err != nil {
 		return err
 	}
 	return confirmLoginSaved(cmd)
@@ -191,13 +229,25 @@ func runEmailLogin(cmd *cobra.Command, email string, passwordStdin bool) error {
 // dance via internal/oauth, exchanges the result for a Personal Access Token,
 // and persists the PAT plus the refresh material that keeps it fresh.
 func runOAuthLogin(cmd *cobra.Command) error {
-	r := resolvedFromCmd(cmd)
-	a, err := oauth.NewConfig(r.OAuthIssuer, r.OIDCTokenEndpoint, r.OAuthClientID).
-		Login(cmd.Context(), cmdutil.OpenBrowser, cmd.ErrOrStderr())
+	a, err := cmdutil.NewOAuthConfig(cmd).Login(cmd.Context(), cmdutil.OpenBrowser, cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	if err := auth.SaveProfile(resolvedFromCmd(cmd).Profile, a); err != nil {
+		return err
+	}
+	return confirmLoginSaved(cmd)
+}
+
+// runDeviceLogin signs in with the OAuth device-code flow, for hosts where no
+// browser can reach a loopback address (SSH, RDE sessions). The stored
+// credentials are the same managed, refreshable PAT as runOAuthLogin's.
+func runDeviceLogin(cmd *cobra.Command) error {
+	a, err := cmdutil.NewOAuthConfig(cmd).DeviceLogin(cmd.Context(), cmd.ErrOrStderr())
 	if err != nil {
 		return err
 	}
-	if err := auth.Save(a); err != nil {
+	if err := auth.SaveProfile(resolvedFromCmd(cmd).Profile, a); err != nil {
 		return err
 	}
 	return confirmLoginSaved(cmd)
@@ -212,7 +262,7 @@ func runOAuthLogin(cmd *cobra.Command) error {
 func confirmLoginSaved(cmd *cobra.Command) error {
 	ew := cmdutil.NewErrWriter(cmd.ErrOrStderr())
 	if !quiet {
-		ew.Ln("Saved access token")
+		ew.Ln("Saved access token" + profileSuffix(resolvedFromCmd(cmd).Profile))
 	}
 	if os.Getenv(config.EnvToken) != "" {
 		s := style.New(cmd.ErrOrStderr())
@@ -226,14 +276,18 @@ func newAuthLogoutCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "logout",
 		Short: "Remove the saved access token",
-		Long: `Remove the auth.yaml file. Does not affect tokens set via the
+		Long: `Remove the active profile's token from the credential store; a file store is
+removed once no profile has a token left. Does not affect tokens set via the
 BITRISE_TOKEN environment variable or the legacy 'config set token'.`,
+		Example: `  bitrise-cli auth logout
+  bitrise-cli auth logout --profile staging`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			if err := auth.Clear(); err != nil {
+			profile := resolvedFromCmd(cmd).Profile
+			if err := auth.ClearProfile(profile); err != nil {
 				return err
 			}
 			if !quiet {
-				if _, err := fmt.Fprintln(cmd.ErrOrStderr(), "Cleared saved access token"); err != nil {
+				if _, err := fmt.Fprintln(cmd.ErrOrStderr(), "Cleared saved access token"+profileSuffix(profile)); err != nil {
 					return err
 				}
 			}
@@ -242,8 +296,17 @@ BITRISE_TOKEN environment variable or the legacy 'config set token'.`,
 	}
 }
 
+// profileSuffix names a non-default profile in login/logout confirmations.
+func profileSuffix(profile string) string {
+	if auth.IsDefaultProfile(profile) {
+		return ""
+	}
+	return fmt.Sprintf(" for profile %q", profile)
+}
+
 // authStatus is the JSON shape of `bitrise-cli auth status`.
 type authStatus struct {
+	Profile   string `json:"profile"`
 	HasToken  bool   `json:"has_token"`
 	TokenType string `json:"token_type,omitempty"`
 	Source    string `json:"source"`
@@ -251,50 +314,168 @@ type authStatus struct {
 	// expiry the CLI tracks for background refresh. No token material is
 	// ever included.
 	TokenExpiry string `json:"token_expiry,omitempty"`
-	Path        string `json:"path"`
+	// Path is the credentials file; empty for the keychain store.
+	Path  string `json:"path,omitempty"`
+	Store string `json:"store"`
+	// CredentialError is set when the credential store couldn't be read.
+	CredentialError string `json:"credential_error,omitempty"`
+	// Account is what the API reports for the token: owner, expiry and
+	// scopes. Nil with --offline or when the lookup failed (see APIError).
+	Account  *internaltoken.Info `json:"account,omitempty"`
+	APIError string              `json:"api_error,omitempty"`
+	// Profiles lists every profile known to config.yaml or auth.yaml, so
+	// one can see at a glance which accounts are signed in.
+	Profiles []profileStatus `json:"profiles"`
+}
+
+// profileStatus is one profile's entry in authStatus.Profiles, reflecting
+// its saved token only (BITRISE_TOKEN is not attributed to any profile).
+type profileStatus struct {
+	Name        string `json:"name"`
+	Active      bool   `json:"active"`
+	HasToken    bool   `json:"has_token"`
+	TokenType   string `json:"token_type,omitempty"`
+	OAuth       bool   `json:"oauth"`
+	TokenExpiry string `json:"token_expiry,omitempty"`
+}
+
+// profileNames lists the default profile followed by every named profile
+// in config.yaml or auth.yaml, sorted.
+func profileNames(globalCfg config.Config, authFile auth.File) []string {
+	seen := map[string]bool{auth.DefaultProfile: true}
+	var named []string
+	for _, n := range slices.Concat(slices.Collect(maps.Keys(globalCfg.Profiles)), authFile.Names()) {
+		if !seen[n] {
+			seen[n] = true
+			named = append(named, n)
+		}
+	}
+	slices.Sort(named)
+	return append([]string{auth.DefaultProfile}, named...)
+}
+
+// profileStatuses builds the profiles table of `auth status`.
+func profileStatuses(active string) ([]profileStatus, error) {
+	globalCfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	authFile, err := auth.LoadFile()
+	if err != nil {
+		return nil, err
+	}
+	var out []profileStatus
+	for _, name := range profileNames(globalCfg, authFile) {
+		a := authFile.Profile(name)
+		ps := profileStatus{
+			Name:     name,
+			Active:   name == active || (auth.IsDefaultProfile(name) && auth.IsDefaultProfile(active)),
+			HasToken: a.Token != "",
+			OAuth:    a.IsOAuthManaged(),
+		}
+		if a.Token != "" {
+			ps.TokenType = auth.TokenType(a.Token)
+		}
+		if !a.TokenExpiry.IsZero() {
+			ps.TokenExpiry = a.TokenExpiry.Format(time.RFC3339)
+		}
+		out = append(out, ps)
+	}
+	return out, nil
 }
 
 func newAuthStatusCmd() *cobra.Command {
-	return &cobra.Command{
+	var offline bool
+	c := &cobra.Command{
 		Use:   "status",
 		Short: "Show whether an access token is configured and where it came from",
 		Long: `Show whether an access token is configured and which source supplied it.
 
 Sources, in precedence order:
   env        BITRISE_TOKEN environment variable
-  auth file  auth.yaml, written by 'bitrise-cli auth login' (OAuth or a
-             pasted/email token — a new login overwrites the previous one).
-             OAuth logins are shown as "oauth (auth file)" and refreshed
-             automatically.
-  none       no token configured`,
+  auth file  the active profile's entry in auth.yaml, written by
+             'bitrise-cli auth login' (OAuth or a pasted/email token — a new
+             login overwrites that profile's previous one). OAuth logins are
+             shown as "oauth (auth file)" and refreshed automatically.
+  none       no token configured
+
+The token is then looked up on the API to show who owns it (user or
+workspace), when it expires, and its scopes; a rejected token is reported as
+a warning. --offline skips the lookup.
+
+All profiles are listed below the active one, with whether each is signed in.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			r := resolvedFromCmd(cmd)
-			p, err := auth.Path()
+			store := cmp.Or(r.CredentialStore, auth.StoreFile)
+			p, err := storePath(store)
 			if err != nil {
 				return err
 			}
+			// The saved token is only read when BITRISE_TOKEN isn't set; a
+			// store that can't be read is reported rather than failing.
+			tok := r.Token
+			var credErr error
+			if tok == "" {
+				var a auth.Auth
+				a, credErr = auth.LoadProfile(r.Profile)
+				tok = a.Token
+			}
 			s := authStatus{
-				HasToken: r.Token != "",
+				Profile:  cmp.Or(r.Profile, auth.DefaultProfile),
+				HasToken: tok != "",
 				Path:     p,
-				Source:   tokenSource(r.Token),
+				Store:    store,
+				Source:   tokenSource(tok),
+			}
+			if credErr != nil {
+				s.CredentialError = credErr.Error()
+			} else if s.Profiles, err = profileStatuses(r.Profile); err != nil {
+				return err
 			}
-			if r.Token != "" {
-				s.TokenType = auth.TokenType(r.Token)
+			if tok != "" {
+				s.TokenType = auth.TokenType(tok)
 				// When the token comes from the auth file (not env), surface
 				// OAuth-managed details: a clearer source label and the PAT
 				// expiry the CLI refreshes against. Token material is omitted.
 				if os.Getenv(config.EnvToken) == "" {
-					if a, err := auth.Load(); err == nil && a.IsOAuthManaged() {
+					if a, err := auth.LoadProfile(r.Profile); err == nil && a.IsOAuthManaged() {
 						s.Source = "oauth (auth file)"
 						if !a.TokenExpiry.IsZero() {
 							s.TokenExpiry = a.TokenExpiry.Format(time.RFC3339)
 						}
 					}
 				}
+				if !offline {
+					s.Account, s.APIError = lookupToken(cmd)
+					// The API knows the token's kind; the prefix is a guess.
+					if s.Account != nil {
+						s.TokenType = cmp.Or(apiTokenTypes[s.Account.Type], s.TokenType)
+					}
+				}
 			}
 			return output.Render(cmd.OutOrStdout(), resolveFormat(cmd), s, renderAuthStatusHuman)
 		},
 	}
+	c.Flags().BoolVar(&offline, "offline", false, "don't look the token up on the API")
+	return c
+}
+
+// apiTokenTypes maps the API's token kinds onto auth.TokenType's labels.
+var apiTokenTypes = map[string]string{"personal": "PAT", "workspace": "WAT"}
+
+// lookupToken asks the API about the token in use. A failure is returned as
+// text rather than an error: status should still report the local picture
+// when the token is rejected or the network is down.
+func lookupToken(cmd *cobra.Command) (*internaltoken.Info, string) {
+	client, err := cmdutil.NewAPIClient(cmd)
+	if err != nil {
+		return nil, err.Error()
+	}
+	info, err := internaltoken.NewService(client).Current(cmd.Context())
+	if err != nil {
+		return nil, err.Error()
+	}
+	return &info, ""
 }
 
 // tokenSource reports which configuration layer supplied the resolved token.
@@ -315,21 +496,202 @@ func renderAuthStatusHuman(w io.Writer, st authStatus) error {
 	s := style.New(w)
 	ew := cmdutil.NewErrWriter(w)
 	if !st.HasToken {
-		ew.F("%s No access token configured.\n\n", s.Failure.Render("✗"))
-		ew.Ln("Run 'bitrise-cli auth login' to save one,")
+		if auth.IsDefaultProfile(st.Profile) {
+			ew.F("%s No access token configured.\n\n", s.Failure.Render("✗"))
+			ew.Ln("Run 'bitrise-cli auth login' to save one,")
+		} else {
+			ew.F("%s No access token configured for profile %q.\n\n", s.Failure.Render("✗"), st.Profile)
+			ew.F("Run 'bitrise-cli auth login --profile %s' to save one,\n", st.Profile)
+		}
 		ew.Ln("or set the BITRISE_TOKEN environment variable.")
-		return ew.Err
+		if st.CredentialError != "" {
+			ew.F("\n%s could not read the %s credential store: %s\n", s.Warn.Render("Warning:"), st.Store, st.CredentialError)
+		}
+		if ew.Err != nil {
+			return ew.Err
+		}
+		return renderProfilesTable(w, s, st.Profiles)
 	}
 	lbl := func(label string) string {
 		return s.Label.Render(fmt.Sprintf("%-16s", label))
 	}
 	ew.F("%s %s\n", s.Success.Render("✓"), s.Bold.Render("Access token configured"))
 	ew.F("%s%s\n", lbl("Token:"), s.Dim.Render("******** (set)"))
+	ew.F("%s%s\n", lbl("Profile:"), s.Slug.Render(st.Profile))
 	ew.F("%s%s\n", lbl("Type:"), st.TokenType)
 	ew.F("%s%s\n", lbl("Source:"), st.Source)
-	if st.TokenExpiry != "" {
+	if a := st.Account; a != nil {
+		if a.Username != "" {
+			ew.F("%s%s\n", lbl("User:"), accountLabel(a.Username, a.Email))
+		}
+		if a.WorkspaceID != "" {
+			ew.F("%s%s\n", lbl("Workspace:"), accountLabel(a.WorkspaceName, a.WorkspaceID))
+		}
+		if len(a.Scopes) > 0 {
+			ew.F("%s%s\n", lbl("Scopes:"), strings.Join(a.Scopes, ", "))
+		}
+	}
+	switch {
+	case st.TokenExpiry != "":
 		ew.F("%s%s\n", lbl("Expires:"), st.TokenExpiry)
+	case st.Account != nil && st.Account.ExpiresAt != nil:
+		ew.F("%s%s\n", lbl("Expires:"), st.Account.ExpiresAt.Format(time.RFC3339))
+	case st.Account != nil:
+		ew.F("%s%s\n", lbl("Expires:"), "never")
 	}
-	ew.F("%s%s\n", lbl("Path:"), s.Dim.Render(st.Path))
-	return ew.Err
+	ew.F("%s%s\n", lbl("Store:"), st.Store)
+	if st.Path != "" {
+		ew.F("%s%s\n", lbl("Path:"), s.Dim.Render(st.Path))
+	}
+	if st.APIError != "" {
+		ew.F("\n%s could not verify the token with the API: %s\n", s.Warn.Render("Warning:"), st.APIError)
+	}
+	if ew.Err != nil {
+		return ew.Err
+	}
+	return renderProfilesTable(w, s, st.Profiles)
+}
+
+// accountLabel renders "name (detail)", or just the one that is set.
+func accountLabel(name, detail string) string {
+	switch {
+	case name == "":
+		return detail
+	case detail == "":
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, detail)
+}
+
+// renderProfilesTable lists the known profiles. It is skipped while the
+// default profile is the only one, to keep single-account output unchanged.
+func renderProfilesTable(w io.Writer, s style.Styles, profiles []profileStatus) error {
+	if len(profiles) < 2 {
+		return nil
+	}
+	ew := cmdutil.NewErrWriter(w)
+	ew.F("\n%s\n", s.Header.Render("Profiles"))
+	if ew.Err != nil {
+		return ew.Err
+	}
+	rows := make([][]string, 0, len(profiles))
+	for _, p := range profiles {
+		marker, token := "", "not signed in"
+		if p.Active {
+			marker = "*"
+		}
+		if p.HasToken {
+			token = p.TokenType
+			if p.OAuth {
+				token += " (oauth)"
+			}
+		}
+		rows = append(rows, []string{marker, p.Name, token, p.TokenExpiry})
+	}
+	styler := func(row, col int, content string) string {
+		switch {
+		case col == 1 && profiles[row].Active:
+			return s.Bold.Render(content)
+		case col == 1:
+			return s.Slug.Render(content)
+		case col == 2 && !profiles[row].HasToken:
+			return s.Dim.Render(content)
+		}
+		return content
+	}
+	return style.SectionTable(w, []string{"", "PROFILE", "TOKEN", "EXPIRES"}, rows, s.Header, styler)
+}
+
+// storePath returns the file a credential store writes; empty for the
+// keychain, which has none.
+func storePath(kind string) (string, error) {
+	switch kind {
+	case auth.StoreKeychain:
+		return "", nil
+	case auth.StoreEncryptedFile:
+		return auth.EncryptedPath()
+	default:
+		return auth.Path()
+	}
+}
+
+func newAuthMigrateStoreCmd() *cobra.Command {
+	var to string
+	c := &cobra.Command{
+		Use:   "migrate-store",
+		Short: "Move saved credentials to another credential store",
+		Long: `Move every profile's saved credentials from the current credential store to
+another one, and make it the configured store (credential_store in
+config.yaml).
+
+Stores:
+  file            plaintext auth.yaml (0600)
+  keychain        the OS credential manager (macOS Keychain, Windows
+                  Credential Manager, or the Secret Service on Linux)
+  encrypted-file  auth.enc, encrypted with a passphrase
+
+The credentials are written to the new store and read back before the old
+copy is removed, so a failure leaves them where they were.`,
+		Example: `  bitrise-cli auth migrate-store --to keychain
+  bitrise-cli auth migrate-store --to encrypted-file
+  BITRISE_CREDENTIAL_PASSPHRASE=... bitrise-cli auth migrate-store --to encrypted-file`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			kind, err := auth.ParseStoreKind(to)
+			if err != nil {
+				return err
+			}
+			from := auth.ActiveStore()
+			if from.Kind() == kind {
+				return fmt.Errorf("credentials are already in the %s store", kind)
+			}
+			target, err := auth.OpenStore(kind, auth.StoreOptions{Passphrase: cmdutil.CredentialPassphrase(cmd)})
+			if err != nil {
+				return err
+			}
+
+			// Point config at the new store first and roll back on failure, so
+			// there is no window where the tokens live in a store config
+			// doesn't name.
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+			prev := cfg.CredentialStore
+			cfg.CredentialStore = kind
+			if kind == auth.StoreFile {
+				cfg.CredentialStore = ""
+			}
+			if err := config.Save(cfg); err != nil {
+				return err
+			}
+			moved, err := auth.Migrate(from, target)
+			if err != nil {
+				cfg.CredentialStore = prev
+				if rerr := config.Save(cfg); rerr != nil {
+					return fmt.Errorf("%w (and restoring credential_store failed: %v)", err, rerr)
+				}
+				return err
+			}
+			auth.UseStore(target)
+
+			ew := cmdutil.NewErrWriter(cmd.ErrOrStderr())
+			if !quiet {
+				if len(moved) == 0 {
+					ew.F("No saved credentials to move; new logins will use the %s store\n", kind)
+				} else {
+					ew.F("Moved credentials for %s to the %s store\n", strings.Join(moved, ", "), kind)
+				}
+			}
+			if env := os.Getenv(config.EnvCredentialStore); env != "" && env != kind {
+				s := style.New(cmd.ErrOrStderr())
+				ew.F("%s %s=%s is set and overrides this setting.\n", s.Warn.Render("Warning:"), config.EnvCredentialStore, env)
+			}
+			return ew.Err
+		},
+	}
+	c.Flags().StringVar(&to, "to", "", "destination store: "+strings.Join(auth.StoreKinds, "|"))
+	_ = c.MarkFlagRequired("to")
+	_ = c.RegisterFlagCompletionFunc("to", cobra.FixedCompletions(auth.StoreKinds, cobra.ShellCompDirectiveNoFileComp))
+	return c
 }
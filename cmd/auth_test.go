@@ -3,9 +3,12 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -129,6 +132,25 @@ func TestAuthLogin_OAuthRejectsWithToken(t *testing.T) {
 	}
 }
 
+func TestAuthLogin_DeviceRejectsOtherModes(t *testing.T) {
+	for _, other := range []string{"--oauth", "--with-token", "--email=a@example.com"} {
+		t.Run(other, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+			c := newAuthLoginCmd()
+			c.SetOut(io.Discard)
+			c.SetErr(io.Discard)
+			c.SetArgs([]string{"--device", other})
+			c.SetContext(config.WithResolved(context.Background(), config.Resolved{Output: "human"}))
+
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), "none of the others can be") {
+				t.Fatalf("expected --device/%s to be mutually exclusive, got %v", other, err)
+			}
+		})
+	}
+}
+
 func TestAuthLogin_WarnsWhenEnvTokenShadowsSavedToken(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 	t.Setenv(config.EnvToken, "ci-env-token") // shadows whatever login saves
@@ -235,3 +257,130 @@ func TestAuthStatus_OAuthManaged_ShowsSourceAndExpiryNoToken(t *testing.T) {
 		t.Fatalf("token material must never be printed, got:\n%s", s)
 	}
 }
+
+func TestAuthLogin_ProfileKeepsOtherAccounts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(config.EnvToken, "")
+	if err := auth.Save(auth.Auth{Token: "bitpat_default"}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	c := newAuthLoginCmd()
+	stderr := &bytes.Buffer{}
+	c.SetOut(io.Discard)
+	c.SetErr(stderr)
+	c.SetIn(strings.NewReader("bitpat_staging\n"))
+	c.SetArgs([]string{"--with-token"})
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{Output: "human", Profile: "staging"}))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(stderr.String(), `for profile "staging"`) {
+		t.Errorf("confirmation should name the profile: %q", stderr.String())
+	}
+	if a, _ := auth.Load(); a.Token != "bitpat_default" {
+		t.Errorf("default token = %q, want it untouched", a.Token)
+	}
+	if a, _ := auth.LoadProfile("staging"); a.Token != "bitpat_staging" {
+		t.Errorf("staging token = %q", a.Token)
+	}
+}
+
+func TestAuthStatus_ListsProfiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(config.EnvToken, "")
+	if err := auth.Save(auth.Auth{Token: "bitpat_default"}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := auth.SaveProfile("staging", auth.Auth{Token: "bitpat_staging"}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := config.Save(config.Config{Profiles: map[string]config.Config{"client": {AppID: "a"}}}); err != nil {
+		t.Fatalf("seed config: %v", err)
+	}
+
+	c := newAuthStatusCmd()
+	out := &bytes.Buffer{}
+	c.SetOut(out)
+	c.SetErr(io.Discard)
+	c.SetArgs(nil)
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{Token: "bitpat_staging", Profile: "staging", Output: "json"}))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var got struct {
+		Profile  string `json:"profile"`
+		Profiles []struct {
+			Name     string `json:"name"`
+			Active   bool   `json:"active"`
+			HasToken bool   `json:"has_token"`
+		} `json:"profiles"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if got.Profile != "staging" || len(got.Profiles) != 3 {
+		t.Fatalf("got %+v, want staging active among 3 profiles", got)
+	}
+	want := []struct {
+		name             string
+		active, hasToken bool
+	}{{"default", false, true}, {"client", false, false}, {"staging", true, true}}
+	for i, w := range want {
+		p := got.Profiles[i]
+		if p.Name != w.name || p.Active != w.active || p.HasToken != w.hasToken {
+			t.Errorf("profiles[%d] = %+v, want %+v", i, p, w)
+		}
+	}
+	if strings.Contains(out.String(), "bitpat_") {
+		t.Fatalf("token material must never be printed:\n%s", out.String())
+	}
+}
+
+func TestAuthMigrateStore_FileToEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(config.EnvCredentialStore, "")
+	t.Setenv(config.EnvCredentialPassphrase, "correct horse")
+	prev := auth.ActiveStore()
+	t.Cleanup(func() { auth.UseStore(prev) })
+	if err := auth.SaveProfile("staging", auth.Auth{Token: "bitpat_staging"}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	c := newAuthMigrateStoreCmd()
+	stderr := &bytes.Buffer{}
+	c.SetOut(io.Discard)
+	c.SetErr(stderr)
+	c.SetArgs([]string{"--to", "encrypted-file"})
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{Output: "human"}))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Moved credentials for staging") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bitrise", "auth.yaml")); !os.IsNotExist(err) {
+		t.Errorf("plaintext auth.yaml should be gone, stat err = %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil || cfg.CredentialStore != "encrypted-file" {
+		t.Errorf("credential_store = %q, %v", cfg.CredentialStore, err)
+	}
+	if a, err := auth.LoadProfile("staging"); err != nil || a.Token != "bitpat_staging" {
+		t.Errorf("token after migrate = %+v, %v", a, err)
+	}
+
+	// Migrating to the store already in use is refused.
+	c = newAuthMigrateStoreCmd()
+	c.SetOut(io.Discard)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"--to", "encrypted-file"})
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{Output: "human"}))
+	if err := c.Execute(); err == nil || !strings.Contains(err.Error(), "already") {
+		t.Errorf("err = %v, want already in the store", err)
+	}
+}
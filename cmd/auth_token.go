@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internaltoken "github.com/bitrise-io/bitrise-cli/internal/token"
+)
+
+// newAuthTokenCmd returns `bitrise-cli auth token`, which manages the
+// signed-in user's Personal Access Tokens on the server. Unlike login, it
+// never touches the local credential store.
+func newAuthTokenCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "token",
+		Short: "Create, list, and revoke Personal Access Tokens",
+		Long: `Create, list, and revoke the signed-in user's Personal Access Tokens.
+
+These are additional tokens for CI and other machines; they are printed once
+and never written to the credential store. The token in use is unaffected
+unless you revoke it.`,
+		Example: `  bitrise-cli auth token create --description "GitHub Actions" --expires-in 90d
+  bitrise-cli auth token list
+  bitrise-cli auth token revoke TOKEN_ID`,
+	}
+	c.AddCommand(
+		newAuthTokenCreateCmd(),
+		newAuthTokenListCmd(),
+		newAuthTokenRevokeCmd(),
+	)
+	return c
+}
+
+func newAuthTokenCreateCmd() *cobra.Command {
+	var (
+		description string
+		expiresIn   string
+	)
+	c := &cobra.Command{
+		Use:   "create",
+		Short: "Mint a new Personal Access Token",
+		Long: `Mint a new Personal Access Token for the signed-in user.
+
+The token is printed to stdout exactly once — the server never shows it again —
+so capture it straight into a secret store. Notes go to stderr, keeping stdout
+clean for scripts.
+
+--expires-in takes a duration (720h), a number of days (90d), or "never".
+Without it the server's default lifetime applies.`,
+		Example: `  bitrise-cli auth token create --description "GitHub Actions" --expires-in 90d
+  bitrise-cli auth token create --description ci | gh secret set BITRISE_TOKEN
+  bitrise-cli auth token create --description ci --output json | jq -r .token`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			lifetime, err := internaltoken.ParseExpiresIn(expiresIn)
+			if err != nil {
+				return err
+			}
+			client, err := cmdutil.NewAPIClient(cmd)
+			if err != nil {
+				return err
+			}
+			created, err := internaltoken.NewService(client).Create(cmd.Context(), internaltoken.CreateInput{
+				Description: description,
+				ExpiresIn:   lifetime,
+			})
+			if err != nil {
+				return err
+			}
+			render := func(w io.Writer, c internaltoken.Created) error {
+				return renderTokenCreated(w, cmd.ErrOrStderr(), cmdutil.IsQuiet(cmd), c)
+			}
+			return output.Render(cmd.OutOrStdout(), resolveFormat(cmd), created, render)
+		},
+	}
+	c.Flags().StringVar(&description, "description", "", "what the token is for (required), e.g. the CI system using it")
+	c.Flags().StringVar(&expiresIn, "expires-in", "", `token lifetime: a duration (720h), days (90d), or "never"`)
+	_ = c.MarkFlagRequired("description")
+	return c
+}
+
+// renderTokenCreated prints the secret alone on stdout and the details on
+// stderr, so `TOKEN=$(bitrise-cli auth token create ...)` captures only the
+// token.
+func renderTokenCreated(stdout, stderr io.Writer, quiet bool, c internaltoken.Created) error {
+	if _, err := fmt.Fprintln(stdout, c.Secret); err != nil {
+		return err
+	}
+	if quiet {
+		return nil
+	}
+	s := style.New(stderr)
+	ew := cmdutil.NewErrWriter(stderr)
+	expiry := "never"
+	if c.ExpiresAt != nil {
+		expiry = c.ExpiresAt.Format(time.RFC3339)
+	}
+	ew.F("%s Created access token %s (%s), expires %s.\n", s.Success.Render("✓"), s.Slug.Render(c.ID), c.Description, expiry)
+	ew.Ln(s.Warn.Render("Copy it now — it won't be shown again."))
+	return ew.Err
+}
+
+func newAuthTokenListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List your Personal Access Tokens",
+		Long: `List the signed-in user's Personal Access Tokens with their expiry and last
+use. Token secrets are never shown.`,
+		Example: `  bitrise-cli auth token list
+  bitrise-cli auth token list --output json | jq -r '.[] | select(.expires_at == null) | .id'`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := cmdutil.NewAPIClient(cmd)
+			if err != nil {
+				return err
+			}
+			tokens, err := internaltoken.NewService(client).List(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return output.Render(cmd.OutOrStdout(), resolveFormat(cmd), tokens, renderTokenList)
+		},
+	}
+}
+
+func renderTokenList(w io.Writer, tokens []internaltoken.Token) error {
+	if len(tokens) == 0 {
+		_, err := fmt.Fprintln(w, "No access tokens found.")
+		return err
+	}
+	s := style.New(w)
+	now := time.Now()
+	headers := []string{"ID", "DESCRIPTION", "EXPIRES", "LAST USED", "SCOPES"}
+	rows := make([][]string, 0, len(tokens))
+	expired := make([]bool, 0, len(tokens))
+	for _, t := range tokens {
+		expires, lastUsed := "never", "-"
+		if t.ExpiresAt != nil {
+			expires = t.ExpiresAt.Format(time.DateOnly)
+		}
+		if t.LastUsedAt != nil {
+			lastUsed = t.LastUsedAt.Format(time.DateOnly)
+		}
+		expired = append(expired, t.ExpiresAt != nil && t.ExpiresAt.Before(now))
+		rows = append(rows, []string{t.ID, t.Description, expires, lastUsed, strings.Join(t.Scopes, ",")})
+	}
+	styler := func(row, col int, content string) string {
+		switch {
+		case expired[row]:
+			return s.Dim.Render(content)
+		case col == 0:
+			return s.Slug.Render(content)
+		}
+		return content
+	}
+	return style.Table(w, headers, rows, s.Header, styler)
+}
+
+func newAuthTokenRevokeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke TOKEN_ID",
+		Short: "Revoke a Personal Access Token",
+		Long: `Revoke one of the signed-in user's Personal Access Tokens by ID (see
+'bitrise-cli auth token list'). Anything still using it starts getting 401s
+immediately. Revoking the token this CLI is signed in with signs it out.`,
+		Example: `  bitrise-cli auth token revoke 8f1c2d3e`,
+		Args:    cmdutil.RequireArgs("TOKEN_ID"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := cmdutil.NewAPIClient(cmd)
+			if err != nil {
+				return err
+			}
+			if err := internaltoken.NewService(client).Revoke(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			if !cmdutil.IsQuiet(cmd) {
+				_, err := fmt.Fprintf(cmd.ErrOrStderr(), "Revoked access token %s\n", args[0])
+				return err
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+// tokenAPI fakes the access-token endpoints of the Bitrise API.
+func tokenAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/me/access-tokens":
+			var body struct {
+				Description string `json:"description"`
+				ExpiresIn   int64  `json:"expires_in"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body.ExpiresIn != 90*24*3600 {
+				t.Errorf("expires_in = %d, want 90 days", body.ExpiresIn)
+			}
+			_, _ = io.WriteString(w, `{"data":{"id":"tok-1","description":"`+body.Description+`","expires_at":"2027-01-01T00:00:00Z","token":"bitpat_fresh"}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/token-info":
+			_, _ = io.WriteString(w, `{"data":{"type":"personal","id":"tok-0","scopes":["apps:read"],"user":{"username":"alice","email":"alice@example.com"}}}`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAuthTokenCreate_SecretAloneOnStdout(t *testing.T) {
+	srv := tokenAPI(t)
+
+	c := newAuthTokenCreateCmd()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(stderr)
+	c.SetArgs([]string{"--description", "GitHub Actions", "--expires-in", "90d"})
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{Token: "bitpat_x", APIBaseURL: srv.URL, Output: output.Human}))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if stdout.String() != "bitpat_fresh\n" {
+		t.Errorf("stdout = %q, want only the token", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "tok-1") || !strings.Contains(stderr.String(), "won't be shown again") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestAuthTokenCreate_RequiresDescription(t *testing.T) {
+	c := newAuthTokenCreateCmd()
+	c.SetOut(io.Discard)
+	c.SetErr(io.Discard)
+	c.SetArgs(nil)
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{Token: "bitpat_x", Output: output.Human}))
+
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), "description") {
+		t.Fatalf("err = %v, want missing --description", err)
+	}
+}
+
+func TestAuthStatus_ShowsAPIAccount(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(config.EnvToken, "")
+	srv := tokenAPI(t)
+
+	c := newAuthStatusCmd()
+	out := &bytes.Buffer{}
+	c.SetOut(out)
+	c.SetErr(io.Discard)
+	c.SetArgs(nil)
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{Token: "opaque-token", APIBaseURL: srv.URL, Output: output.JSON}))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var got struct {
+		TokenType string `json:"token_type"`
+		Account   struct {
+			Username string   `json:"username"`
+			Scopes   []string `json:"scopes"`
+		} `json:"account"`
+		APIError string `json:"api_error"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if got.APIError != "" || got.Account.Username != "alice" || len(got.Account.Scopes) != 1 {
+		t.Fatalf("got %+v", got)
+	}
+	if got.TokenType != "PAT" {
+		t.Errorf("token_type = %q, want PAT from the API rather than the prefix guess", got.TokenType)
+	}
+}
@@ -121,7 +121,7 @@ Optional flags:
 				// --watch: stream logs until the build finishes.
 				// In JSON mode logs go to stderr so stdout carries only the final build JSON.
 				logWriter := io.Writer(cmd.OutOrStdout())
-				if format == output.JSON {
+				if format.Structured() {
 					logWriter = cmd.ErrOrStderr()
 				}
 				return runWatch(cmd, svc, b, interval, logWriter, format)
@@ -145,7 +145,7 @@ Optional flags:
 			if err != nil {
 				return err
 			}
-			if format == output.JSON {
+			if format.Structured() {
 				if err := output.Render(cmd.OutOrStdout(), format, finalBuild, renderBuildText); err != nil {
 					return err
 				}
@@ -100,8 +100,8 @@ func renderBuildText(w io.Writer, b internalbuild.Build) error {
 
 // runWatch is the shared implementation for `build watch` and `build trigger --watch`.
 // It prints a header/footer to stderr and streams log content to logWriter.
-// For output.JSON format it renders the final build record as JSON to
-// cmd.OutOrStdout() instead of the text footer.
+// For a structured format (JSON, YAML, …) it renders the final build record in
+// that format to cmd.OutOrStdout() instead of the text footer.
 //
 // In human format on an interactive terminal it switches to a TUI that
 // pins a spinner + status bar to the bottom and streams logs above it.
@@ -130,7 +130,7 @@ func runWatch(cmd *cobra.Command, svc *internalbuild.Service, b internalbuild.Bu
 		return err
 	}
 
-	if format == output.JSON {
+	if format.Structured() {
 		if err := output.Render(cmd.OutOrStdout(), format, finalBuild, renderBuildText); err != nil {
 			return err
 		}
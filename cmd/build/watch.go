@@ -8,7 +8,6 @@ import (
 
 	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
 	internalbuild "github.com/bitrise-io/bitrise-cli/internal/build"
-	"github.com/bitrise-io/bitrise-cli/internal/output"
 )
 
 func newWatchCmd() *cobra.Command {
@@ -57,7 +56,7 @@ Output:
 			// stdout carries only the final build record.
 			format := cmdutil.ResolveFormat(cmd)
 			logWriter := io.Writer(cmd.OutOrStdout())
-			if format == output.JSON {
+			if format.Structured() {
 				logWriter = cmd.ErrOrStderr()
 			}
 			return runWatch(cmd, svc, b, interval, logWriter, format)
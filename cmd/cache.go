@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/internal/httpcache"
+)
+
+func newCacheCmd() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the on-disk HTTP cache",
+		Long: `Manage the on-disk cache of slow-changing API responses.
+
+To keep name resolution and shell completion fast, GET responses for
+workspaces (1h), apps (10m), stacks and machine types (24h), and the step
+library (6h) are kept under $XDG_CONFIG_HOME/bitrise/cache/http (falls back
+to ~/.config/bitrise/cache/http). Within its TTL an entry is used without
+asking the API; after that it is revalidated (If-None-Match /
+If-Modified-Since) and reused if unchanged. Entries are kept per access
+token, and any change the CLI makes (create, update, delete) drops them.
+
+Pass --no-cache (or set BITRISE_NO_CACHE=1) to fetch everything fresh for one
+command; --debug shows each cache hit.`,
+		Example: `  bitrise-cli cache clear
+  bitrise-cli app list --no-cache`,
+	}
+	c.AddCommand(newCacheClearCmd())
+	return c
+}
+
+func newCacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "clear",
+		Short:   "Delete every cached API response",
+		Long:    `Delete every cached API response, for all profiles.`,
+		Example: `  bitrise-cli cache clear`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			n, err := httpcache.Clear(resolvedFromCmd(cmd).CacheDir)
+			if err != nil {
+				return err
+			}
+			if !quiet {
+				_, err := fmt.Fprintf(cmd.ErrOrStderr(), "Cleared %d cached response(s)\n", n)
+				return err
+			}
+			return nil
+		},
+	}
+}
@@ -6,20 +6,28 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 
 	"github.com/bitrise-io/bitrise-cli/bitriseapi"
 	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
+	"github.com/bitrise-io/bitrise-cli/bitriseapi/steplib"
 	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil/picker"
+	"github.com/bitrise-io/bitrise-cli/internal/auth"
 	"github.com/bitrise-io/bitrise-cli/internal/cache"
 	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/httpcache"
+	"github.com/bitrise-io/bitrise-cli/internal/httpdebug"
+	"github.com/bitrise-io/bitrise-cli/internal/httpretry"
 	"github.com/bitrise-io/bitrise-cli/internal/oauth"
 	"github.com/bitrise-io/bitrise-cli/internal/output"
 	"github.com/bitrise-io/bitrise-cli/internal/resolve"
+	"github.com/bitrise-io/bitrise-cli/internal/webclient"
 )
 
 const (
@@ -29,6 +37,14 @@ const (
 	FlagQuiet     = "quiet"
 	FlagWeb       = "web"
 	FlagTheme     = "theme"
+	FlagProfile   = "profile"
+	FlagDebug     = "debug"
+	FlagDebugFile = "debug-file"
+	FlagDebugHAR  = "debug-har"
+	FlagNoCache   = "no-cache"
+	FlagColumns   = "columns"
+	FlagTemplate  = "template"
+	FlagJQ        = "jq"
 )
 
 // IsQuiet reports whether the persistent --quiet flag was set.
@@ -215,17 +231,50 @@ var ErrNoToken = errors.New("no Bitrise access token configured (run 'bitrise-cl
 // config list, etc.).
 //
 // BITRISE_TOKEN, when set, is used verbatim and never refreshed — that's the
-// CI path. Otherwise the resolved token (from auth.yaml) is handed to the
-// OAuth refresh ladder, which is a no-op for a manually pasted/email token.
+// CI path, and the credential store is not read at all. Otherwise the active
+// profile's saved token is loaded from the store — the first point at which
+// a keychain is queried or a passphrase asked for — and handed to the OAuth
+// refresh ladder, which is a no-op for a manually pasted/email token.
 func liveToken(cmd *cobra.Command) (string, error) {
 	if t := os.Getenv(config.EnvToken); t != "" {
 		return t, nil
 	}
 	r := config.FromContext(cmd.Context())
-	if r.Token == "" {
+	tok := r.Token
+	if tok == "" {
+		a, err := auth.LoadProfile(r.Profile)
+		if err != nil {
+			return "", fmt.Errorf("read saved credentials: %w", err)
+		}
+		tok = a.Token
+	}
+	if tok == "" {
+		if !auth.IsDefaultProfile(r.Profile) {
+			return "", fmt.Errorf("no Bitrise access token configured for profile %q (run 'bitrise-cli auth login --profile %s' or set BITRISE_TOKEN)", r.Profile, r.Profile)
+		}
 		return "", ErrNoToken
 	}
-	return oauth.NewConfig(r.OAuthIssuer, r.OIDCTokenEndpoint, r.OAuthClientID).EnsureFreshPAT(cmd.Context(), r.Token)
+	return NewOAuthConfig(cmd).EnsureFreshPAT(cmd.Context(), tok)
+}
+
+// NewOAuthConfig builds the oauth.Config for the active profile from the
+// Resolved settings on cmd.Context(), traced under --debug.
+func NewOAuthConfig(cmd *cobra.Command) oauth.Config {
+	r := config.FromContext(cmd.Context())
+	oc := oauth.NewConfig(r.OAuthIssuer, r.OIDCTokenEndpoint, r.OAuthClientID)
+	oc.Profile = r.Profile
+	oc.Transport = httpdebug.FromContext(cmd.Context()).Wrap(nil)
+	return oc
+}
+
+// NewWebClient builds a webclient.Client for the resolved web base URL,
+// traced under --debug.
+func NewWebClient(cmd *cobra.Command) (*webclient.Client, error) {
+	wc, err := webclient.New(ResolveWebBaseURL(cmd))
+	if err != nil {
+		return nil, err
+	}
+	return wc.WithTransport(httpdebug.FromContext(cmd.Context()).Wrap(nil)), nil
 }
 
 // NewAPIClient builds a *bitriseapi.Client from the Resolved settings on
@@ -236,7 +285,7 @@ func NewAPIClient(cmd *cobra.Command) (*bitriseapi.Client, error) {
 		return nil, err
 	}
 	r := config.FromContext(cmd.Context())
-	return bitriseapi.New(r.APIBaseURL, tok), nil
+	return bitriseapi.New(r.APIBaseURL, tok, bitriseapi.WithTransport(apiTransport(cmd))), nil
 }
 
 // NewRDEClient builds an *rdeapi.Client for the Remote Dev Environments API
@@ -249,7 +298,38 @@ func NewRDEClient(cmd *cobra.Command) (*rdeapi.Client, error) {
 		return nil, err
 	}
 	r := config.FromContext(cmd.Context())
-	return rdeapi.New(r.RDEAPIBaseURL, tok), nil
+	return rdeapi.New(r.RDEAPIBaseURL, tok, rdeapi.WithTransport(apiTransport(cmd))), nil
+}
+
+// NewStepLibClient builds a *steplib.Client for reading the StepLib from
+// GitHub. It needs no Bitrise token; it shares the API clients' cache,
+// retries and --debug tracing.
+func NewStepLibClient(cmd *cobra.Command) *steplib.Client {
+	r := config.FromContext(cmd.Context())
+	return steplib.New(r.StepLibAPIBaseURL, steplib.WithTransport(apiTransport(cmd)))
+}
+
+// apiTransport is the HTTP transport shared by the API clients. From the
+// top: the on-disk cache answers slow-changing GETs (unless --no-cache);
+// below it, requests that reach the network are retried when rate-limited or
+// transiently failing, per the resolved http_retries / http_retry_max_wait;
+// the --debug tracer sits at the bottom, so every attempt is traced.
+func apiTransport(cmd *cobra.Command) http.RoundTripper {
+	r := config.FromContext(cmd.Context())
+	policy := httpretry.DefaultPolicy()
+	policy.MaxRetries, policy.MaxWait = r.HTTPRetries, r.HTTPRetryMaxWait
+	retry := httpretry.New(httpdebug.FromContext(cmd.Context()).Wrap(nil), policy, Debugf(cmd))
+	return httpcache.New(retry, r.CacheDir, r.NoCache, Debugf(cmd))
+}
+
+// Debugf returns a logger for --debug diagnostics (stderr, or --debug-file),
+// or nil when debug logging is off.
+func Debugf(cmd *cobra.Command) func(format string, args ...any) {
+	t := httpdebug.FromContext(cmd.Context())
+	if !t.Logging() {
+		return nil
+	}
+	return t.Logf
 }
 
 // ErrWriter wraps an io.Writer and captures the first write error so callers
@@ -346,6 +426,42 @@ func ReadSecretInput(in io.Reader, stderr io.Writer, prompt string, fromStdin bo
 	return strings.TrimSpace(s), nil
 }
 
+// CredentialPassphrase returns the passphrase source for the encrypted-file
+// credential store: BITRISE_CREDENTIAL_PASSPHRASE when set, otherwise a
+// no-echo prompt on the terminal (asked twice when the file is being
+// created). The answer is kept for the rest of the process.
+func CredentialPassphrase(cmd *cobra.Command) func(create bool) (string, error) {
+	var (
+		once sync.Once
+		pass string
+		err  error
+	)
+	return func(create bool) (string, error) {
+		once.Do(func() {
+			if p := os.Getenv(config.EnvCredentialPassphrase); p != "" {
+				pass = p
+				return
+			}
+			if !IsTerminal(cmd.InOrStdin()) {
+				err = fmt.Errorf("credentials are encrypted: set %s or run in a terminal to enter the passphrase", config.EnvCredentialPassphrase)
+				return
+			}
+			pass, err = ReadSecretInput(cmd.InOrStdin(), cmd.ErrOrStderr(), "Credential store passphrase: ", false)
+			if err != nil || !create {
+				return
+			}
+			again, rerr := ReadSecretInput(cmd.InOrStdin(), cmd.ErrOrStderr(), "Repeat passphrase: ", false)
+			switch {
+			case rerr != nil:
+				err = rerr
+			case again != pass:
+				err = errors.New("passphrases do not match")
+			}
+		})
+		return pass, err
+	}
+}
+
 // RequireArgs returns an Args validator that names exactly which positional
 // argument(s) are missing, instead of cobra's generic "accepts N arg(s), received M".
 func RequireArgs(names ...string) cobra.PositionalArgs {
@@ -3,6 +3,7 @@ package cmdutil
 import (
 	"bytes"
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/bitrise-io/bitrise-cli/internal/auth"
 	"github.com/bitrise-io/bitrise-cli/internal/config"
 	"github.com/bitrise-io/bitrise-cli/internal/output"
 )
@@ -120,3 +122,39 @@ func TestResolveWorkspaceID_AutoDetectMultipleErrors(t *testing.T) {
 		t.Fatalf("expected multiple-workspaces error, got %v", err)
 	}
 }
+
+// countingStore is an auth.Store that records loads and has no credentials
+// to give.
+type countingStore struct{ loads int }
+
+func (s *countingStore) Kind() string { return auth.StoreFile }
+func (s *countingStore) Load() (auth.File, error) {
+	s.loads++
+	return auth.File{}, errors.New("store unavailable")
+}
+func (s *countingStore) Save(auth.File) error { return nil }
+func (s *countingStore) Clear() error         { return nil }
+
+func TestLiveToken_EnvTokenSkipsStore(t *testing.T) {
+	store := &countingStore{}
+	prev := auth.ActiveStore()
+	auth.UseStore(store)
+	t.Cleanup(func() { auth.UseStore(prev) })
+	c := wsCmd(t, config.Resolved{Profile: auth.DefaultProfile})
+
+	t.Setenv(config.EnvToken, "from-env")
+	if tok, err := liveToken(c); err != nil || tok != "from-env" {
+		t.Fatalf("liveToken = %q, %v", tok, err)
+	}
+	if store.loads != 0 {
+		t.Errorf("store loaded %d times with BITRISE_TOKEN set", store.loads)
+	}
+
+	t.Setenv(config.EnvToken, "")
+	if _, err := liveToken(c); err == nil || !strings.Contains(err.Error(), "read saved credentials") {
+		t.Errorf("err = %v, want the store error", err)
+	}
+	if store.loads != 1 {
+		t.Errorf("store loaded %d times, want once", store.loads)
+	}
+}
@@ -0,0 +1,53 @@
+package cmdutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// windowsToast shows a toast through the WinRT notification API. Title and
+// body come in through the environment, never the script text, so they
+// can't inject PowerShell. The app ID is PowerShell's own: toasts from an
+// unregistered app ID are silently dropped.
+const windowsToast = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$t = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$n = $t.GetElementsByTagName('text')
+$n.Item(0).AppendChild($t.CreateTextNode($env:BITRISE_NOTIFY_TITLE)) > $null
+$n.Item(1).AppendChild($t.CreateTextNode($env:BITRISE_NOTIFY_BODY)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe').Show([Windows.UI.Notifications.ToastNotification]::new($t))`
+
+// DesktopNotify shows a desktop notification through the OS notifier:
+//
+//   - macOS:    osascript (display notification)
+//   - Windows:  PowerShell toast
+//   - other:    notify-send
+//
+// Title and body are passed as arguments or environment, never spliced into
+// a script, so backend-provided text can't run commands.
+func DesktopNotify(ctx context.Context, title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, body) // #nosec G204 -- fixed script, text passed as argv
+	case "windows":
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToast)
+		cmd.Env = append(os.Environ(), "BITRISE_NOTIFY_TITLE="+title, "BITRISE_NOTIFY_BODY="+body)
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=bitrise-cli", "--", title, body) // #nosec G204 -- fixed command, text passed as argv
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, string(out))
+		}
+		return err
+	}
+	return nil
+}
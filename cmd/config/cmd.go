@@ -1,6 +1,7 @@
 package config
 
 import (
+	"cmp"
 	"fmt"
 	"io"
 	"strings"
@@ -8,7 +9,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/auth"
 	internalconfig "github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/httpretry"
 	"github.com/bitrise-io/bitrise-cli/internal/output"
 	"github.com/bitrise-io/bitrise-cli/internal/output/style"
 )
@@ -27,22 +30,42 @@ Storage:
                Useful for per-project app_id pinning.
 
 Precedence at runtime:
-  flag > env > per-directory file > global file > built-in default
+  flag > env > per-directory file > active profile > global file > built-in default
+
+Profiles:
+  Named profiles keep a separate set of account settings (app_id,
+  default_workspace_id, base URLs) under "profiles" in the global file, next
+  to their own token in auth.yaml. The top-level settings belong to the
+  "default" profile. Select one per command with --profile or %s, per
+  project with "profile: NAME" in .bitrise-cli.yml, or for good with
+  'config use-profile'. 'get', 'set', 'unset', and 'list' act on the active
+  profile.
 
 Recognized keys:
   %s
 
 Environment overrides for the same values:
-  %s, %s, %s, %s, %s, %s, %s, %s
+  %s, %s, %s, %s, %s, %s, %s, %s, %s,
+  %s, %s
+
+API retries: rate-limited (429) and transiently failing (502/503/504,
+dropped connections) requests are retried up to http_retries times (default
+%d; 0 disables), backing off exponentially and honouring Retry-After, with no
+single wait longer than http_retry_max_wait (default %s). --debug shows each
+retry.
 
 Note: 'set'/'unset' modify only the global file. Per-directory files must be
-edited by hand.
+edited by hand. credential_store is changed with 'bitrise-cli auth
+migrate-store', which moves the saved tokens along.
 
 To manage your access token, use 'bitrise-cli auth login/logout/status'.`,
+			internalconfig.EnvProfile,
 			strings.Join(internalconfig.Keys, ", "),
 			internalconfig.EnvOutput, internalconfig.EnvAppSlug, internalconfig.EnvWorkspaceID,
 			internalconfig.EnvToken, internalconfig.EnvAPIBaseURL, internalconfig.EnvRDEAPIBaseURL,
-			internalconfig.EnvWebBaseURL, internalconfig.EnvTheme,
+			internalconfig.EnvWebBaseURL, internalconfig.EnvTheme, internalconfig.EnvCredentialStore,
+			internalconfig.EnvHTTPRetries, internalconfig.EnvHTTPRetryMaxWait,
+			httpretry.DefaultMaxRetries, httpretry.DefaultMaxWait,
 		),
 	}
 	c.AddCommand(
@@ -51,10 +74,34 @@ To manage your access token, use 'bitrise-cli auth login/logout/status'.`,
 		newGetCmd(),
 		newSetCmd(),
 		newUnsetCmd(),
+		newUseProfileCmd(),
 	)
 	return c
 }
 
+// activeProfile returns the profile whose settings get/set/unset/list act on.
+func activeProfile(cmd *cobra.Command) string {
+	return cmp.Or(internalconfig.FromContext(cmd.Context()).Profile, auth.DefaultProfile)
+}
+
+// keyProfile returns the profile section key lives in: the active profile,
+// or the top level for machine-wide keys.
+func keyProfile(cmd *cobra.Command, key string) string {
+	if !internalconfig.ProfileScoped(key) {
+		return auth.DefaultProfile
+	}
+	return activeProfile(cmd)
+}
+
+// errUseMigrateStore refuses editing credential_store by hand: switching it
+// without moving the saved tokens would silently sign the user out.
+func errUseMigrateStore(key string) error {
+	if key != internalconfig.KeyCredentialStore {
+		return nil
+	}
+	return fmt.Errorf("%s is changed with 'bitrise-cli auth migrate-store --to STORE', which also moves your saved tokens", key)
+}
+
 func newPathCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "path",
@@ -72,13 +119,19 @@ func newPathCmd() *cobra.Command {
 
 // configList is the JSON shape of `bitrise-cli config list`.
 type configList struct {
+	Profile    string `json:"profile"`
 	Output     string `json:"output,omitempty"`
 	AppSlug    string `json:"app_id,omitempty"`
 	OrgSlug    string `json:"default_workspace_id,omitempty"`
 	APIBaseURL string `json:"api_base_url,omitempty"`
 	WebBaseURL string `json:"web_base_url,omitempty"`
 	Theme      string `json:"theme,omitempty"`
-	Path       string `json:"path"`
+	// HTTPRetries and HTTPRetryMaxWait are kept as written, like the rest.
+	HTTPRetries      string `json:"http_retries,omitempty"`
+	HTTPRetryMaxWait string `json:"http_retry_max_wait,omitempty"`
+	// CredentialStore is machine-wide, shown whatever the active profile.
+	CredentialStore string `json:"credential_store,omitempty"`
+	Path            string `json:"path"`
 }
 
 func newListCmd() *cobra.Command {
@@ -86,7 +139,7 @@ func newListCmd() *cobra.Command {
 		Use:     "list",
 		Aliases: []string{"ls"},
 		Short:   "List the current config-file values",
-		Long: `List the values currently saved in the config file.
+		Long: `List the values currently saved in the config file for the active profile.
 
 Env-var overrides are NOT shown by this command — they only apply at runtime
 to other bitrise-cli commands.`,
@@ -99,14 +152,21 @@ to other bitrise-cli commands.`,
 			if err != nil {
 				return err
 			}
+			profile := activeProfile(cmd)
+			store := cfg.CredentialStore
+			cfg = cfg.ProfileConfig(profile)
 			v := configList{
-				Output:     cfg.Output,
-				AppSlug:    cfg.AppID,
-				OrgSlug:    cfg.DefaultWorkspaceID,
-				APIBaseURL: cfg.APIBaseURL,
-				WebBaseURL: cfg.WebBaseURL,
-				Theme:      cfg.Theme,
-				Path:       p,
+				CredentialStore:  store,
+				Profile:          profile,
+				Output:           cfg.Output,
+				AppSlug:          cfg.AppID,
+				OrgSlug:          cfg.DefaultWorkspaceID,
+				APIBaseURL:       cfg.APIBaseURL,
+				WebBaseURL:       cfg.WebBaseURL,
+				Theme:            cfg.Theme,
+				HTTPRetries:      cfg.HTTPRetries,
+				HTTPRetryMaxWait: cfg.HTTPRetryMaxWait,
+				Path:             p,
 			}
 			return output.Render(cmd.OutOrStdout(), cmdutil.ResolveFormat(cmd), v, renderListHuman)
 		},
@@ -125,13 +185,17 @@ func renderListHuman(w io.Writer, v configList) error {
 		}
 		return v
 	}
-	ew.F("%s%s\n\n", lbl("Path:"), s.Dim.Render(v.Path))
+	ew.F("%s%s\n", lbl("Path:"), s.Dim.Render(v.Path))
+	ew.F("%s%s\n\n", lbl("Profile:"), s.Slug.Render(v.Profile))
 	ew.F("%s%s\n", lbl(internalconfig.KeyOutput+":"), value(v.Output))
 	ew.F("%s%s\n", lbl(internalconfig.KeyAppID+":"), value(v.AppSlug))
 	ew.F("%s%s\n", lbl(internalconfig.KeyDefaultWorkspaceID+":"), value(v.OrgSlug))
 	ew.F("%s%s\n", lbl(internalconfig.KeyAPIBaseURL+":"), value(v.APIBaseURL))
 	ew.F("%s%s\n", lbl(internalconfig.KeyWebBaseURL+":"), value(v.WebBaseURL))
 	ew.F("%s%s\n", lbl(internalconfig.KeyTheme+":"), value(v.Theme))
+	ew.F("%s%s\n", lbl(internalconfig.KeyHTTPRetries+":"), value(v.HTTPRetries))
+	ew.F("%s%s\n", lbl(internalconfig.KeyHTTPRetryMaxWait+":"), value(v.HTTPRetryMaxWait))
+	ew.F("%s%s\n", lbl(internalconfig.KeyCredentialStore+":"), value(v.CredentialStore))
 	return ew.Err
 }
 
@@ -151,7 +215,8 @@ Valid keys: %s`,
 			if err != nil {
 				return err
 			}
-			v, err := cfg.Get(args[0])
+			pc := cfg.ProfileConfig(keyProfile(cmd, args[0]))
+			v, err := pc.Get(args[0])
 			if err != nil {
 				return err
 			}
@@ -170,14 +235,15 @@ func newSetCmd() *cobra.Command {
 
 Valid keys: %s
 
-The value is validated before being saved (e.g. "output" must be human or json,
-"api_base_url" and "web_base_url" must be valid URLs). The file is written with
-0600 permissions.
+The value is validated before being saved (e.g. "output" must be human, json,
+yaml, csv, or tsv; "api_base_url" and "web_base_url" must be valid URLs). The
+file is written with 0600 permissions.
 
 If VALUE is "-", the value is read from stdin (trailing newline trimmed).`,
 			strings.Join(internalconfig.Keys, ", "),
 		),
 		Example: `  bitrise-cli config set output json
+  bitrise-cli config set app_id 5db8b1d8-cae8-4cea-b943-ddc8f48e5e7c --profile staging
   bitrise-cli config set app_id 5db8b1d8-cae8-4cea-b943-ddc8f48e5e7c`,
 		Args: cmdutil.RequireArgs("KEY", "VALUE"),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -194,9 +260,15 @@ If VALUE is "-", the value is read from stdin (trailing newline trimmed).`,
 			if err != nil {
 				return err
 			}
-			if err := cfg.Set(key, value); err != nil {
+			if err := errUseMigrateStore(key); err != nil {
 				return err
 			}
+			profile := keyProfile(cmd, key)
+			pc := cfg.ProfileConfig(profile)
+			if err := pc.Set(key, value); err != nil {
+				return err
+			}
+			cfg.SetProfileConfig(profile, pc)
 			if err := internalconfig.Save(cfg); err != nil {
 				return err
 			}
@@ -235,9 +307,15 @@ Valid keys: %s`, strings.Join(internalconfig.Keys, ", ")),
 			if err != nil {
 				return err
 			}
-			if err := cfg.Unset(args[0]); err != nil {
+			if err := errUseMigrateStore(args[0]); err != nil {
 				return err
 			}
+			profile := keyProfile(cmd, args[0])
+			pc := cfg.ProfileConfig(profile)
+			if err := pc.Unset(args[0]); err != nil {
+				return err
+			}
+			cfg.SetProfileConfig(profile, pc)
 			if err := internalconfig.Save(cfg); err != nil {
 				return err
 			}
@@ -250,3 +328,48 @@ Valid keys: %s`, strings.Join(internalconfig.Keys, ", ")),
 		},
 	}
 }
+
+func newUseProfileCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use-profile NAME",
+		Short: "Make a profile the active one for future commands",
+		Long: `Make NAME the active profile: later commands use its token and account
+settings unless --profile, BITRISE_PROFILE, or a per-directory "profile:"
+says otherwise. "default" switches back to the top-level settings.
+
+The profile must already exist — sign in to it with
+'bitrise-cli auth login --profile NAME' or give it a setting with
+'bitrise-cli config set KEY VALUE --profile NAME'.`,
+		Example: `  bitrise-cli config use-profile staging
+  bitrise-cli config use-profile default`,
+		Args: cmdutil.RequireArgs("NAME"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			cfg, err := internalconfig.Load()
+			if err != nil {
+				return err
+			}
+			if auth.IsDefaultProfile(name) {
+				cfg.Profile = ""
+			} else {
+				authFile, err := auth.LoadFile()
+				if err != nil {
+					return err
+				}
+				if _, ok := cfg.Profiles[name]; !ok && authFile.Profile(name).Token == "" {
+					return fmt.Errorf("unknown profile %q (create it with 'bitrise-cli auth login --profile %s')", name, name)
+				}
+				cfg.Profile = name
+			}
+			if err := internalconfig.Save(cfg); err != nil {
+				return err
+			}
+			if !cmdutil.IsQuiet(cmd) {
+				if _, err := fmt.Fprintf(cmd.ErrOrStderr(), "Switched to profile %q\n", cmp.Or(cfg.Profile, auth.DefaultProfile)); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
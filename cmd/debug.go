@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/httpdebug"
+)
+
+// debugSession is the --debug state of one invocation: the tracer installed
+// on the command's context, and what Execute must flush when it is done.
+var debugSession struct {
+	tracer  *httpdebug.Tracer
+	logFile *os.File
+	harPath string
+}
+
+// startDebug sets up HTTP tracing from --debug / BITRISE_DEBUG, --debug-file
+// and --debug-har. It returns nil when none is set, leaving every client
+// untraced.
+func startDebug(cmd *cobra.Command, r config.Resolved) (*httpdebug.Tracer, error) {
+	logPath, _ := cmd.Flags().GetString(cmdutil.FlagDebugFile)
+	harPath, _ := cmd.Flags().GetString(cmdutil.FlagDebugHAR)
+	if !r.Debug && logPath == "" && harPath == "" {
+		return nil, nil
+	}
+	var log io.Writer
+	switch {
+	case logPath != "":
+		f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // path is the user's own --debug-file
+		if err != nil {
+			return nil, fmt.Errorf("open debug file: %w", err)
+		}
+		debugSession.logFile = f
+		log = f
+	case r.Debug:
+		log = cmd.ErrOrStderr()
+	}
+	debugSession.harPath = harPath
+	debugSession.tracer = httpdebug.New(httpdebug.Options{
+		Log:       log,
+		RecordHAR: harPath != "",
+		Creator:   "bitrise-cli",
+		Version:   version,
+	})
+	return debugSession.tracer, nil
+}
+
+// finishDebug writes the HAR file and closes the debug log, reporting
+// failures on stderr without changing the command's outcome.
+func finishDebug(stderr io.Writer) {
+	if debugSession.harPath != "" && debugSession.tracer != nil {
+		if err := writeHAR(debugSession.harPath, debugSession.tracer); err != nil {
+			_, _ = fmt.Fprintf(stderr, "Warning: could not write %s: %v\n", debugSession.harPath, err)
+		} else if !quiet {
+			_, _ = fmt.Fprintf(stderr, "Wrote HTTP trace to %s (credentials redacted)\n", debugSession.harPath)
+		}
+	}
+	if debugSession.logFile != nil {
+		_ = debugSession.logFile.Close()
+	}
+}
+
+func writeHAR(path string, t *httpdebug.Tracer) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) //nolint:gosec // path is the user's own --debug-har
+	if err != nil {
+		return err
+	}
+	if err := t.WriteHAR(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
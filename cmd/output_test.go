@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+// TestRoot_JQAndTemplateApplyToEveryCommand runs a plain Render-based
+// command through the root so persistentPreRun wires --jq / --template.
+func TestRoot_JQAndTemplateApplyToEveryCommand(t *testing.T) {
+	t.Cleanup(func() {
+		_ = rootCmd.PersistentFlags().Set(cmdutil.FlagJQ, "")
+		_ = rootCmd.PersistentFlags().Set(cmdutil.FlagTemplate, "")
+		_ = output.Configure(output.Options{})
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+	})
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs(args)
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	if got := run("version", "--jq", ".os"); got != runtime.GOOS+"\n" {
+		t.Errorf("--jq .os = %q, want %q", got, runtime.GOOS+"\n")
+	}
+	_ = rootCmd.PersistentFlags().Set(cmdutil.FlagJQ, "")
+	if got := run("version", "--template", "{{.arch}}"); got != runtime.GOARCH {
+		t.Errorf("--template = %q, want %q", got, runtime.GOARCH)
+	}
+}
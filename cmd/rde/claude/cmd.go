@@ -364,7 +364,7 @@ func runFresh(ctx context.Context, cmd *cobra.Command, opts freshOptions) error
 		claudeSessionID: claudeSessionID,
 		claudeCmd:       buildClaudeCommand(repoDir, claudeSessionID),
 		record:          rec,
-		describe:        newDescriber(repoSlugFromURL(originURL), branch),
+		describe:        newDescriber(internalrde.RepoSlugFromURL(originURL), branch),
 	})
 	if errors.Is(err, errReconnectInterrupted) {
 		leaveRunning = true // skip termination; deferred cleanup leaves the VM up
@@ -795,7 +795,7 @@ func gitHTTPSURL(raw string) string {
 	if !sshRewriteHosts[host] {
 		return raw
 	}
-	slug := repoSlugFromURL(raw)
+	slug := internalrde.RepoSlugFromURL(raw)
 	if slug == "" {
 		return raw
 	}
@@ -151,7 +151,7 @@ func downloadAction(svc *internalrde.Service, workspaceID, sessionID, localDir s
 			return nil, fmt.Errorf("remotePath is required")
 		}
 		dest := resolveDownloadDest(req.LocalDest, localDir, sessionID)
-		if err := svc.DownloadFile(ctx, workspaceID, sessionID, req.RemotePath, dest, req.OnlyContents); err != nil {
+		if err := svc.DownloadFile(ctx, workspaceID, sessionID, req.RemotePath, dest, req.OnlyContents, internalrde.TransferOptions{}); err != nil {
 			return nil, err
 		}
 		return map[string]any{"downloaded": true, "localPath": absOrSelf(dest)}, nil
@@ -180,7 +180,7 @@ func uploadAction(svc *internalrde.Service, workspaceID, sessionID, localDir str
 		}
 		source := resolveUploadSource(req.LocalPath, localDir)
 		dest := resolveRemoteUploadFolder(req.RemoteFolder)
-		if err := svc.UploadFile(ctx, workspaceID, sessionID, source, dest); err != nil {
+		if err := svc.UploadFile(ctx, workspaceID, sessionID, source, dest, internalrde.TransferOptions{}); err != nil {
 			return nil, err
 		}
 		return map[string]any{"uploaded": true, "localPath": absOrSelf(source), "remoteFolder": dest}, nil
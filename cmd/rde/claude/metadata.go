@@ -89,38 +89,3 @@ func repoRootPath(ctx context.Context) string {
 	}
 	return ""
 }
-
-// repoSlugFromURL extracts "owner/repo" from a clone URL (ssh, scp-like, or
-// https), trimming any ".git" suffix. Returns "" when it can't parse one.
-func repoSlugFromURL(raw string) string {
-	s := strings.TrimSpace(raw)
-	scheme := false
-	for _, p := range []string{"ssh://", "https://", "http://", "git://"} {
-		if rest, ok := strings.CutPrefix(s, p); ok {
-			s = rest
-			scheme = true
-			break
-		}
-	}
-	if _, after, ok := strings.Cut(s, "@"); ok {
-		s = after
-	}
-	var path string
-	if scheme {
-		// host[:port]/owner/repo
-		_, p, ok := strings.Cut(s, "/")
-		if !ok {
-			return ""
-		}
-		path = p
-	} else {
-		// scp-like: host:owner/repo
-		_, p, ok := strings.Cut(s, ":")
-		if !ok {
-			return ""
-		}
-		path = p
-	}
-	path = strings.TrimSuffix(path, ".git")
-	return strings.Trim(path, "/")
-}
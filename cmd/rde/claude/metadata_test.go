@@ -5,26 +5,6 @@ import (
 	"testing"
 )
 
-func TestRepoSlugFromURL(t *testing.T) {
-	for _, tc := range []struct {
-		in, want string
-	}{
-		{"git@github.com:org/repo.git", "org/repo"},
-		{"git@github.com:org/repo", "org/repo"},
-		{"https://github.com/org/repo.git", "org/repo"},
-		{"https://github.com/org/repo", "org/repo"},
-		{"ssh://git@github.com/org/repo.git", "org/repo"},
-		{"git@gitlab.com:grp/sub/repo.git", "grp/sub/repo"},
-		{"https://user@github.com/org/repo.git", "org/repo"},
-		{"not a url", ""},
-		{"", ""},
-	} {
-		if got := repoSlugFromURL(tc.in); got != tc.want {
-			t.Errorf("repoSlugFromURL(%q) = %q, want %q", tc.in, got, tc.want)
-		}
-	}
-}
-
 func TestBuildDescription(t *testing.T) {
 	for _, tc := range []struct {
 		slug, branch, prURL, want string
@@ -169,7 +169,7 @@ func resumeSession(ctx context.Context, cmd *cobra.Command, svc *internalrde.Ser
 		claudeSessionID: rec.ClaudeSessionID,
 		claudeCmd:       buildResumeCommand(rec.RemoteRepoDir, rec.ClaudeSessionID),
 		record:          rec,
-		describe:        newDescriber(repoSlugFromURL(rec.Repo), rec.Branch),
+		describe:        newDescriber(internalrde.RepoSlugFromURL(rec.Repo), rec.Branch),
 	})
 	if errors.Is(err, errReconnectInterrupted) {
 		leaveRunning = true // skip termination; deferred cleanup leaves the VM up
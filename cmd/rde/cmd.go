@@ -12,6 +12,7 @@ import (
 	rdesession "github.com/bitrise-io/bitrise-cli/cmd/rde/session"
 	rdestack "github.com/bitrise-io/bitrise-cli/cmd/rde/stack"
 	rdetemplate "github.com/bitrise-io/bitrise-cli/cmd/rde/template"
+	rdeusage "github.com/bitrise-io/bitrise-cli/cmd/rde/usage"
 )
 
 // NewCmd returns the `bitrise-cli rde` parent command.
@@ -38,10 +39,15 @@ Saved inputs are user-scoped — they do not require --workspace.`,
 	c.AddCommand(
 		rdeclaude.NewCmd(),
 		rdesession.NewCmd(),
+		rdesession.NewSSHProxyCmd(),
+		rdesession.NewUpCmd(),
+		rdesession.NewDownCmd(),
+		rdesession.NewStatusCmd(),
 		rdetemplate.NewCmd(),
 		rdesavedinput.NewCmd(),
 		rdestack.NewCmd(),
 		rdemachinetype.NewCmd(),
+		rdeusage.NewCmd(),
 	)
 	return c
 }
@@ -0,0 +1,222 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+// bulkFlags are the selector flags that switch terminate, update and delete
+// from one SESSION_ID to every matching session.
+type bulkFlags struct {
+	selectors   []string
+	statuses    []string
+	olderThan   time.Duration
+	dryRun      bool
+	assumeYes   bool
+	concurrency int
+}
+
+// register adds the selector flags. -l is --label-selector, as on list and
+// watch.
+func (b *bulkFlags) register(c *cobra.Command) {
+	c.Flags().StringArrayVarP(&b.selectors, "label-selector", "l", nil, "apply to every session whose labels match key=value exactly, instead of one SESSION_ID (repeatable; all must match)")
+	c.Flags().StringSliceVar(&b.statuses, "status", nil, "with selectors: only sessions in one of these statuses (e.g. running,terminated)")
+	c.Flags().DurationVar(&b.olderThan, "older-than", 0, "with selectors: only sessions created at least this long ago (Go duration syntax: 48h, 90m)")
+	c.Flags().BoolVar(&b.dryRun, "dry-run", false, "with selectors: list the matching sessions without changing them")
+	c.Flags().BoolVar(&b.assumeYes, "yes", false, "with selectors: skip the confirmation prompt")
+	c.Flags().IntVar(&b.concurrency, "concurrency", 4, "with selectors: how many sessions to change at once")
+}
+
+// active reports whether any selector flag was given, i.e. the command runs
+// in bulk mode.
+func (b *bulkFlags) active(cmd *cobra.Command) bool {
+	for _, name := range []string{"label-selector", "status", "older-than"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// args validates the positional arguments: one SESSION_ID normally, none in
+// bulk mode.
+func (b *bulkFlags) args(cmd *cobra.Command, args []string) error {
+	if !b.active(cmd) {
+		for _, name := range []string{"dry-run", "yes", "concurrency"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s needs --label-selector, --status or --older-than", name)
+			}
+		}
+		return cmdutil.RequireArgs("SESSION_ID")(cmd, args)
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("SESSION_ID can't be combined with --label-selector, --status or --older-than")
+	}
+	return nil
+}
+
+func (b *bulkFlags) filter() (internalrde.SessionFilter, error) {
+	if err := validateLabelSelectors(b.selectors); err != nil {
+		return internalrde.SessionFilter{}, err
+	}
+	if b.olderThan < 0 {
+		return internalrde.SessionFilter{}, fmt.Errorf("--older-than must not be negative")
+	}
+	if b.concurrency < 1 {
+		return internalrde.SessionFilter{}, fmt.Errorf("--concurrency must be at least 1")
+	}
+	statuses := make([]string, 0, len(b.statuses))
+	for _, st := range b.statuses {
+		if st = strings.ToLower(strings.TrimSpace(st)); st != "" {
+			statuses = append(statuses, st)
+		}
+	}
+	return internalrde.SessionFilter{LabelSelectors: b.selectors, Statuses: statuses, OlderThan: b.olderThan}, nil
+}
+
+// bulkResult is the --output json shape of a bulk operation.
+type bulkResult struct {
+	Action    string                       `json:"action"`
+	DryRun    bool                         `json:"dry_run"`
+	Matched   int                          `json:"matched"`
+	Succeeded int                          `json:"succeeded"`
+	Failed    int                          `json:"failed"`
+	Items     []internalrde.BulkItemResult `json:"items"`
+}
+
+// bulkVerbs are the human wording per action: the prompt verb and the
+// past tense for the summary.
+var bulkVerbs = map[string][2]string{
+	"terminate": {"Terminate", "Terminated"},
+	"update":    {"Update", "Updated"},
+	"delete":    {"Permanently delete", "Deleted"},
+}
+
+// runBulk selects the sessions matching b, previews them, asks for
+// confirmation when it can, applies op to each and renders per-item results.
+// It returns an error when any session failed, after rendering.
+func runBulk(cmd *cobra.Command, workspaceID string, b *bulkFlags, action string, op func(ctx context.Context, svc *internalrde.Service, workspaceID string, sess internalrde.Session) (internalrde.Session, error)) error {
+	filter, err := b.filter()
+	if err != nil {
+		return err
+	}
+	format := cmdutil.ResolveFormat(cmd)
+	client, err := cmdutil.NewRDEClient(cmd)
+	if err != nil {
+		return err
+	}
+	svc := internalrde.NewService(client)
+	sessions, err := svc.SelectSessions(cmd.Context(), workspaceID, filter)
+	if err != nil {
+		return err
+	}
+
+	res := bulkResult{Action: action, DryRun: b.dryRun, Matched: len(sessions), Items: []internalrde.BulkItemResult{}}
+	if b.dryRun || len(sessions) == 0 {
+		for _, sess := range sessions {
+			res.Items = append(res.Items, internalrde.BulkItemResult{ID: sess.ID, Name: sess.Name, Status: sess.Status, Result: internalrde.BulkResultDryRun})
+		}
+		return output.Render(cmd.OutOrStdout(), format, res, func(w io.Writer, r bulkResult) error {
+			if len(sessions) == 0 {
+				_, err := fmt.Fprintln(w, "No sessions match.")
+				return err
+			}
+			if err := renderSessionList(w, listResult{Items: sessions}); err != nil {
+				return err
+			}
+			_, err := fmt.Fprintf(w, "\nDry run: %d session(s) would be %s. Re-run without --dry-run to apply.\n", len(sessions), strings.ToLower(bulkVerbs[action][1]))
+			return err
+		})
+	}
+
+	if !b.assumeYes {
+		if !cmdutil.IsTerminal(cmd.InOrStdin()) || !cmdutil.WriterIsTTY(cmd.ErrOrStderr()) {
+			return fmt.Errorf("refusing to %s %d session(s) without confirmation: pass --yes, or --dry-run to preview", action, len(sessions))
+		}
+		stderr := cmd.ErrOrStderr()
+		if err := renderSessionList(stderr, listResult{Items: sessions}); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(stderr, "\n%s %d session(s)? [y/N]: ", bulkVerbs[action][0], len(sessions)); err != nil {
+			return err
+		}
+		answer, err := cmdutil.ReadSecretInput(cmd.InOrStdin(), stderr, "", true)
+		if err != nil {
+			return err
+		}
+		if answer != "y" && answer != "Y" && answer != "yes" {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	res.Items = internalrde.BulkApply(cmd.Context(), sessions, b.concurrency, func(ctx context.Context, sess internalrde.Session) (internalrde.Session, error) {
+		return op(ctx, svc, workspaceID, sess)
+	})
+	for _, item := range res.Items {
+		if item.Result == internalrde.BulkResultOK {
+			res.Succeeded++
+		} else {
+			res.Failed++
+		}
+	}
+	if err := output.Render(cmd.OutOrStdout(), format, res, renderBulkResult); err != nil {
+		return err
+	}
+	if res.Failed > 0 {
+		return fmt.Errorf("%d of %d session(s) failed to %s", res.Failed, res.Matched, action)
+	}
+	return nil
+}
+
+func renderBulkResult(w io.Writer, r bulkResult) error {
+	s := style.New(w)
+	headers := []string{"NAME", "STATUS", "RESULT", "ID"}
+	rows := make([][]string, 0, len(r.Items))
+	for _, item := range r.Items {
+		result := "✓"
+		if item.Result != internalrde.BulkResultOK {
+			result = "✗ " + item.Error
+		}
+		rows = append(rows, []string{item.Name, item.Status, result, item.ID})
+	}
+	const (
+		colStatus = 1
+		colResult = 2
+		colID     = 3
+	)
+	styler := func(row, col int, content string) string {
+		switch col {
+		case colStatus:
+			return statusStyle(s, r.Items[row].Status).Render(content)
+		case colResult:
+			if r.Items[row].Result == internalrde.BulkResultOK {
+				return s.Success.Render(content)
+			}
+			return s.Failure.Render(content)
+		case colID:
+			return s.Slug.Render(content)
+		}
+		return content
+	}
+	if err := style.Table(w, headers, rows, s.Header, styler); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%s %d of %d session(s)", bulkVerbs[r.Action][1], r.Succeeded, r.Matched)
+	if err == nil && r.Failed > 0 {
+		_, err = fmt.Fprintf(w, " (%d failed)", r.Failed)
+	}
+	if err == nil {
+		_, err = fmt.Fprintln(w)
+	}
+	return err
+}
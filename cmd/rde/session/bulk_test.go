@@ -0,0 +1,194 @@
+package session
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+// bulkServer lists three sessions labelled team=mobile — two running (one
+// created three days ago, one an hour ago) and one terminated — and records
+// every mutation it receives.
+type bulkServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	query     string
+	mutations []string
+	bodies    []map[string]any
+}
+
+func newBulkServer(t *testing.T, fail string) *bulkServer {
+	t.Helper()
+	old := time.Now().Add(-72 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	bs := &bulkServer{}
+	bs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/v1/workspaces/ws-1/sessions" {
+			bs.mu.Lock()
+			bs.query = r.URL.RawQuery
+			bs.mu.Unlock()
+			_, _ = io.WriteString(w, `{"sessions":[
+				{"id":"s-old","name":"old","status":"SESSION_STATUS_RUNNING","createdAt":"`+old+`"},
+				{"id":"s-new","name":"new","status":"SESSION_STATUS_RUNNING","createdAt":"`+recent+`"},
+				{"id":"s-off","name":"off","status":"SESSION_STATUS_TERMINATED","createdAt":"`+old+`"}
+			]}`)
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bs.mu.Lock()
+		bs.mutations = append(bs.mutations, r.Method+" "+r.URL.Path)
+		bs.bodies = append(bs.bodies, body)
+		bs.mu.Unlock()
+		if fail != "" && strings.Contains(r.URL.Path, fail) {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = io.WriteString(w, `{"message":"session is busy"}`)
+			return
+		}
+		id := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/workspaces/ws-1/sessions/"), "/")[0]
+		_, _ = io.WriteString(w, `{"session":{"id":"`+id+`","name":"x","status":"SESSION_STATUS_TERMINATING"}}`)
+	}))
+	t.Cleanup(bs.Close)
+	return bs
+}
+
+func TestTerminateCmd_BulkDryRunChangesNothing(t *testing.T) {
+	srv := newBulkServer(t, "")
+
+	stdout, _, err := run(t, newTerminateCmd(), srv.URL, "ws-1",
+		[]string{"-l", "team=mobile", "--status", "running", "--older-than", "48h", "--dry-run"}, output.Human)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if srv.query != "labelSelectors=team%3Dmobile" {
+		t.Errorf("list query = %q, want the label selector", srv.query)
+	}
+	if len(srv.mutations) != 0 {
+		t.Errorf("dry run must not mutate, got %v", srv.mutations)
+	}
+	if !strings.Contains(stdout, "s-old") || strings.Contains(stdout, "s-new") || strings.Contains(stdout, "s-off") {
+		t.Errorf("preview should list only the old running session:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "Dry run: 1 session(s) would be terminated") {
+		t.Errorf("stdout missing dry-run summary:\n%s", stdout)
+	}
+}
+
+func TestTerminateCmd_BulkNeedsConfirmationOffTTY(t *testing.T) {
+	srv := newBulkServer(t, "")
+
+	_, _, err := run(t, newTerminateCmd(), srv.URL, "ws-1", []string{"--status", "running"}, output.Human)
+	if err == nil || !strings.Contains(err.Error(), "pass --yes") {
+		t.Fatalf("err = %v, want a confirmation error", err)
+	}
+	if len(srv.mutations) != 0 {
+		t.Errorf("unconfirmed bulk run must not mutate, got %v", srv.mutations)
+	}
+}
+
+func TestTerminateCmd_BulkJSONReportsEachItem(t *testing.T) {
+	srv := newBulkServer(t, "s-new")
+	c := newTerminateCmd()
+	c.SilenceUsage = true // as under the root command
+
+	stdout, _, err := run(t, c, srv.URL, "ws-1",
+		[]string{"--status", "running", "--yes", "--concurrency", "2"}, output.JSON)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 session(s) failed to terminate") {
+		t.Fatalf("err = %v, want the partial failure reported", err)
+	}
+	var got struct {
+		Action    string `json:"action"`
+		Matched   int    `json:"matched"`
+		Succeeded int    `json:"succeeded"`
+		Failed    int    `json:"failed"`
+		Items     []struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+			Result string `json:"result"`
+			Error  string `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, stdout)
+	}
+	if got.Action != "terminate" || got.Matched != 2 || got.Succeeded != 1 || got.Failed != 1 || len(got.Items) != 2 {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+	if got.Items[0].ID != "s-old" || got.Items[0].Result != "ok" || got.Items[0].Status != "terminating" {
+		t.Errorf("item 0 = %+v, want s-old terminated ok", got.Items[0])
+	}
+	if got.Items[1].ID != "s-new" || got.Items[1].Result != "failed" || !strings.Contains(got.Items[1].Error, "busy") {
+		t.Errorf("item 1 = %+v, want s-new failed with the API error", got.Items[1])
+	}
+}
+
+func TestUpdateCmd_BulkSendsSameUpdateToEachMatch(t *testing.T) {
+	srv := newBulkServer(t, "")
+
+	stdout, _, err := run(t, newUpdateCmd(), srv.URL, "ws-1",
+		[]string{"-l", "team=mobile", "--status", "running", "--auto-terminate-minutes", "120", "--yes"}, output.Human)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(srv.mutations) != 2 {
+		t.Fatalf("mutations = %v, want one PATCH per running session", srv.mutations)
+	}
+	for i, m := range srv.mutations {
+		if !strings.HasPrefix(m, http.MethodPatch) {
+			t.Errorf("mutation %q should be a PATCH", m)
+		}
+		if srv.bodies[i]["autoTerminateMinutes"] != float64(120) {
+			t.Errorf("body = %v, want autoTerminateMinutes 120", srv.bodies[i])
+		}
+	}
+	if !strings.Contains(stdout, "Updated 2 of 2 session(s)") {
+		t.Errorf("stdout missing summary:\n%s", stdout)
+	}
+}
+
+func TestUpdateCmd_BulkRejectsName(t *testing.T) {
+	_, _, err := run(t, newUpdateCmd(), "http://unused", "ws-1",
+		[]string{"--label-selector", "team=mobile", "--name", "same"}, output.Human)
+	if err == nil || !strings.Contains(err.Error(), "--name") {
+		t.Fatalf("err = %v, want --name rejected in bulk mode", err)
+	}
+}
+
+func TestUpdateCmd_ShortLabelSelectsSessions(t *testing.T) {
+	_, _, err := run(t, newUpdateCmd(), "http://unused", "ws-1",
+		[]string{uuidSession, "-l", "branch=main"}, output.Human)
+	if err == nil || !strings.Contains(err.Error(), "set labels with --label") {
+		t.Fatalf("err = %v, want -l with SESSION_ID rejected with a --label hint", err)
+	}
+}
+
+func TestDeleteCmd_BulkDeletesMatches(t *testing.T) {
+	srv := newBulkServer(t, "")
+
+	_, _, err := run(t, newDeleteCmd(), srv.URL, "ws-1", []string{"--status", "terminated", "--yes"}, output.Human)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(srv.mutations) != 1 || srv.mutations[0] != "DELETE /v1/workspaces/ws-1/sessions/s-off" {
+		t.Errorf("mutations = %v, want only the terminated session deleted", srv.mutations)
+	}
+}
+
+func TestBulkFlags_ArgValidation(t *testing.T) {
+	for _, args := range [][]string{
+		{uuidSession, "-l", "team=mobile"},
+		{uuidSession, "--dry-run"},
+		{"-l", "team"},
+	} {
+		if _, _, err := run(t, newTerminateCmd(), "http://unused", "ws-1", args, output.Human); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
@@ -31,7 +31,12 @@ the command errors and lists the candidate IDs to pick from.`,
 		newDiffCmd(),
 		newLogsCmd(),
 		newNotificationsCmd(),
+		newWatchCmd(),
 		newExecCmd(),
+		newSSHCmd(),
+		newPortForwardCmd(),
+		newSSHConfigCmd(),
+		newSyncCmd(),
 		newUploadCmd(),
 		newDownloadCmd(),
 		newVNCCmd(),
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -33,10 +35,11 @@ func newCreateCmd() *cobra.Command {
 		mapSavedInputs       bool
 		wait                 bool
 		waitTimeout          time.Duration
+		fromRepo             bool
 	)
 
 	c := &cobra.Command{
-		Use:   "create NAME",
+		Use:   "create [NAME]",
 		Short: "Create a new RDE session",
 		Long: `Create a new RDE session, either from a template or from a bare
 stack + machine type (a template-less session, with no warmup/startup scripts
@@ -68,7 +71,36 @@ can be filtered by them with 'rde session list --label-selector key=value'.
 Example values:
   --input key=value
   --saved-input session-key=SAVED_INPUT_ID   # secret stored ahead of time
-  --secret-input api-key=VALUE               # inline; avoid for real secrets`,
+  --secret-input api-key=VALUE               # inline; avoid for real secrets
+
+--from-repo takes the session definition from the session section of
+.bitrise/rde.yml (found in the working directory or any ancestor), so a
+team shares one dev environment through git and creates it with no flags:
+
+  session:
+    name: my-app                  # default: the repo directory's name
+    template: ios-dev             # or stack + machine_type without one
+    machine_type: g2.mac.m2pro.6c-14g
+    inputs:
+      repo: my-app
+    saved_inputs:                 # key: saved-input ID, no inline secrets
+      gh-token: SAVED_INPUT_ID
+    map_saved_inputs: true
+    labels:
+      team: mobile
+    auto_terminate_minutes: 240
+    feature_flags: [some-flag]
+    ai_prompt: Run the unit tests and fix what fails.
+    post_create:
+      - cd ~/src/my-app && bundle install
+
+NAME is optional with --from-repo. Flags given alongside override the file:
+scalar flags replace its values, --input/--saved-input/--label replace the
+same keys, and --feature-flag adds to its list. post_create commands run in
+order once the session is running, like 'session exec --shell' (with the
+file's exec.env forwarded), so --from-repo with post_create implies --wait;
+the first failing command makes create exit non-zero, leaving the session
+up for a look.`,
 		Example: `  bitrise-cli rde session create dev --template TEMPLATE_ID
   bitrise-cli rde session create dev --template TEMPLATE_ID --input repo=my-app
   # Template-less: pick a stack and machine type directly.
@@ -76,22 +108,22 @@ Example values:
   # Keep secrets off the command line: store once, then reference by ID.
   echo -n "ghp_xxx" | bitrise-cli rde saved-input create --key gh-token --value-stdin --secret
   bitrise-cli rde session create dev --template TEMPLATE_ID --saved-input gh-token=SAVED_INPUT_ID
-  bitrise-cli rde session create dev --template TEMPLATE_ID --map-saved-inputs`,
-		Args: cmdutil.RequireArgs("NAME"),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			name := args[0]
-			if name == "" {
-				return fmt.Errorf("NAME must not be empty")
-			}
-			// A session needs either a template or, for a template-less
-			// session, an explicit stack + machine type. (stack/machine type
-			// may also accompany a template to override its defaults.)
-			if templateID == "" && (stack == "" || machineType == "") {
-				return fmt.Errorf("provide --template, or both --stack and --machine-type to create a session without a template")
+  bitrise-cli rde session create dev --template TEMPLATE_ID --map-saved-inputs
+  # From the session section of .bitrise/rde.yml.
+  bitrise-cli rde session create --from-repo`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if fromRepo {
+				return cobra.MaximumNArgs(1)(cmd, args)
 			}
-			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
-			if err != nil {
-				return err
+			return cmdutil.RequireArgs("NAME")(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+				if name == "" {
+					return fmt.Errorf("NAME must not be empty")
+				}
 			}
 			sessionInputs, err := parseSessionInputs(inputs, secretInputs, savedInputs)
 			if err != nil {
@@ -102,7 +134,6 @@ Example values:
 				return err
 			}
 			req := internalrde.CreateSessionRequest{
-				Name:                    name,
 				Description:             description,
 				TemplateID:              templateID,
 				StackID:                 stack,
@@ -118,6 +149,37 @@ Example values:
 				m := autoTerminateMinutes
 				req.AutoTerminateMinutes = &m
 			}
+			var (
+				postCreate []string
+				envVars    []internalrde.EnvVar
+			)
+			if fromRepo {
+				repoCfg, path, err := loadRepoSessionConfig()
+				if err != nil {
+					return err
+				}
+				if name == "" {
+					name = repoCfg.Session.SessionName(path)
+				}
+				req = mergeCreateRequest(repoCfg.Session.CreateRequest(name), req)
+				if postCreate = repoCfg.Session.PostCreate; len(postCreate) > 0 {
+					wait = true
+					if envVars, err = postCreateEnv(cmd, repoCfg, path); err != nil {
+						return err
+					}
+				}
+			}
+			req.Name = name
+			// A session needs either a template or, for a template-less
+			// session, an explicit stack + machine type. (stack/machine type
+			// may also accompany a template to override its defaults.)
+			if req.TemplateID == "" && (req.StackID == "" || req.MachineType == "") {
+				return fmt.Errorf("provide --template, or both --stack and --machine-type to create a session without a template")
+			}
+			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
+			if err != nil {
+				return err
+			}
 			format := cmdutil.ResolveFormat(cmd)
 			client, err := cmdutil.NewRDEClient(cmd)
 			if err != nil {
@@ -145,7 +207,7 @@ Example values:
 			if wait {
 				waitCtx, cancel := context.WithTimeout(cmd.Context(), waitTimeout)
 				defer cancel()
-				if !cmdutil.IsQuiet(cmd) && format != output.JSON {
+				if !cmdutil.IsQuiet(cmd) && !format.Structured() {
 					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Waiting for session %s to become ready (timeout %s)…\n", res.Session.ID, waitTimeout)
 				}
 				ready, waitErr := svc.WaitForReady(waitCtx, workspaceID, res.Session.ID, 0, nil)
@@ -161,6 +223,12 @@ Example values:
 					return fmt.Errorf("session ended provisioning with status %q (expected running)", ready.Status)
 				}
 			}
+			if err := runPostCreate(cmd, svc, workspaceID, res.Session.ID, postCreate, envVars); err != nil {
+				if renderErr := output.Render(cmd.OutOrStdout(), format, res, renderCreateResult); renderErr != nil {
+					return renderErr
+				}
+				return err
+			}
 
 			return output.Render(cmd.OutOrStdout(), format, res, renderCreateResult)
 		},
@@ -181,6 +249,7 @@ Example values:
 	c.Flags().BoolVar(&mapSavedInputs, "map-saved-inputs", false, "auto-fill template session inputs from the user's saved inputs (matched by key)")
 	c.Flags().BoolVar(&wait, "wait", false, "wait until the session leaves provisioning (running, failed, …) before returning; exits 1 if the final status isn't running")
 	c.Flags().DurationVar(&waitTimeout, "wait-timeout", 10*time.Minute, "max time to wait when --wait is set (uses Go duration syntax: 30s, 5m, 1h)")
+	c.Flags().BoolVar(&fromRepo, "from-repo", false, "create the session defined in the session section of .bitrise/rde.yml (flags override it; NAME is optional)")
 
 	c.PreRun = func(cmd *cobra.Command, _ []string) {
 		// Track whether --auto-terminate-minutes was explicitly set so we
@@ -190,6 +259,105 @@ Example values:
 	return c
 }
 
+// loadRepoSessionConfig finds .bitrise/rde.yml and checks its session
+// section. Returns the config and the file's path.
+func loadRepoSessionConfig() (internalrde.RepoConfig, string, error) {
+	repoCfg, path, err := internalrde.LoadRepoConfig()
+	if err != nil {
+		return internalrde.RepoConfig{}, "", err
+	}
+	if path == "" {
+		return internalrde.RepoConfig{}, "", fmt.Errorf("no .bitrise/rde.yml found in the working directory or its ancestors")
+	}
+	if err := repoCfg.Session.Validate(path); err != nil {
+		return internalrde.RepoConfig{}, "", err
+	}
+	return repoCfg, path, nil
+}
+
+// mergeCreateRequest lays flag values over a repo-defined request: set
+// scalars replace, inputs and labels replace per key, feature flags add.
+func mergeCreateRequest(base, flags internalrde.CreateSessionRequest) internalrde.CreateSessionRequest {
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&base.Description, flags.Description},
+		{&base.TemplateID, flags.TemplateID},
+		{&base.StackID, flags.StackID},
+		{&base.MachineType, flags.MachineType},
+		{&base.Cluster, flags.Cluster},
+		{&base.AIPrompt, flags.AIPrompt},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	if flags.AutoTerminateMinutes != nil {
+		base.AutoTerminateMinutes = flags.AutoTerminateMinutes
+	}
+	base.MapSavedToSessionInputs = base.MapSavedToSessionInputs || flags.MapSavedToSessionInputs
+	overridden := make(map[string]bool, len(flags.SessionInputs))
+	for _, in := range flags.SessionInputs {
+		overridden[in.Key] = true
+	}
+	inputs := flags.SessionInputs[:0:0]
+	for _, in := range base.SessionInputs {
+		if !overridden[in.Key] {
+			inputs = append(inputs, in)
+		}
+	}
+	base.SessionInputs = append(inputs, flags.SessionInputs...)
+	for k, v := range flags.Labels {
+		if base.Labels == nil {
+			base.Labels = map[string]string{}
+		}
+		base.Labels[k] = v
+	}
+	for _, f := range flags.EnabledFeatureFlagNames {
+		if !slices.Contains(base.EnabledFeatureFlagNames, f) {
+			base.EnabledFeatureFlagNames = append(base.EnabledFeatureFlagNames, f)
+		}
+	}
+	return base
+}
+
+// postCreateEnv resolves the dotfile's exec.env for the post-create
+// commands and warns about entries unset locally, like exec does.
+func postCreateEnv(cmd *cobra.Command, repoCfg internalrde.RepoConfig, path string) ([]internalrde.EnvVar, error) {
+	envVars, skipped, err := internalrde.ResolveExecEnv(repoCfg.Exec.Env, path, nil, os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) > 0 {
+		s := style.New(cmd.ErrOrStderr())
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s %s not set locally — skipped (listed in %s)\n", s.Warn.Render("Warning:"), strings.Join(skipped, ", "), path)
+	}
+	return envVars, nil
+}
+
+// runPostCreate runs a repo's post-create commands on a fresh session. Each
+// command's output goes to stderr — stdout carries the command's own
+// result — and a failing command's output is shown even with --quiet.
+func runPostCreate(cmd *cobra.Command, svc *internalrde.Service, workspaceID, sessionID string, commands []string, env []internalrde.EnvVar) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	quiet := cmdutil.IsQuiet(cmd)
+	ew := cmdutil.NewErrWriter(cmd.ErrOrStderr())
+	err := svc.RunPostCreate(cmd.Context(), workspaceID, sessionID, commands, env, func(command string, res internalrde.ExecResult) {
+		if quiet && res.ExitCode == 0 {
+			return
+		}
+		ew.F("Post-create: %s\n", command)
+		ew.F("%s%s", res.Stdout, res.Stderr)
+	})
+	if err != nil {
+		return err
+	}
+	return ew.Err
+}
+
 // parseSessionInputs converts the user-friendly --input/--secret-input/--saved-input
 // flags into SessionInputValue entries. Returns an error on the first malformed
 // entry; later iterations don't run.
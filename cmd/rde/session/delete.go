@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -10,15 +11,34 @@ import (
 )
 
 func newDeleteCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "delete SESSION_ID",
+	var bulk bulkFlags
+	c := &cobra.Command{
+		Use:   "delete [SESSION_ID]",
 		Short: "Permanently delete a session",
-		Args:  cmdutil.RequireArgs("SESSION_ID"),
+		Long: `Permanently delete a session. Only terminated or failed sessions can be
+deleted.
+
+Instead of one SESSION_ID, --label-selector, --status and --older-than
+delete every session matching all of them. The matching sessions are listed
+first and, on a terminal, you're asked to confirm; elsewhere pass --yes.
+--dry-run only lists them. Sessions that can't be deleted are reported as
+failures without stopping the rest.`,
+		Example: `  bitrise-cli rde session delete SESSION_ID
+  bitrise-cli rde session delete -l team=mobile --status terminated,failed --older-than 168h --dry-run`,
+		Args: bulk.args,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
 			if err != nil {
 				return err
 			}
+			if bulk.active(cmd) {
+				return runBulk(cmd, workspaceID, &bulk, "delete", func(ctx context.Context, svc *internalrde.Service, workspaceID string, sess internalrde.Session) (internalrde.Session, error) {
+					if err := svc.DeleteSession(ctx, workspaceID, sess.ID); err != nil {
+						return internalrde.Session{}, err
+					}
+					return internalrde.Session{Status: "deleted"}, nil
+				})
+			}
 			client, err := cmdutil.NewRDEClient(cmd)
 			if err != nil {
 				return err
@@ -38,4 +58,6 @@ func newDeleteCmd() *cobra.Command {
 			return nil
 		},
 	}
+	bulk.register(c)
+	return c
 }
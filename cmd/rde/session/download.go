@@ -21,7 +21,16 @@ then extracted into LOCAL_PATH locally.
 
 When REMOTE_PATH is a directory, the directory itself is recreated inside
 LOCAL_PATH by default. Pass --only-contents to drop just its contents into
-LOCAL_PATH instead.`,
+LOCAL_PATH instead.
+
+The archive is streamed to a file under the CLI's config directory
+(~/.config/bitrise/cache/transfer, or under $XDG_CONFIG_HOME), which needs
+free space for the archive on top of the extracted files — a dropped
+connection resumes where it stopped — and checked against the checksum storage reports before
+anything is extracted. Symlinks, permission bits, and modification times are
+restored; a symlink or path that would land outside LOCAL_PATH aborts the
+extraction. Progress (bytes, rate, time left) is shown on stderr; --quiet
+hides it.`,
 		Example: `  bitrise-cli rde session download SESSION_ID /Users/vagrant/project/build ./build
   bitrise-cli rde session download SESSION_ID /Users/vagrant/logs ./logs --only-contents`,
 		Args: cmdutil.RequireArgs("SESSION_ID", "REMOTE_PATH", "LOCAL_PATH"),
@@ -40,10 +49,12 @@ LOCAL_PATH instead.`,
 			if err != nil {
 				return err
 			}
+			var opts internalrde.TransferOptions
 			if !cmdutil.IsQuiet(cmd) {
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Downloading %s → %s from session %s…\n", sourcePath, localDest, sessionID)
+				opts.Progress = transferProgress(cmd.ErrOrStderr())
 			}
-			if err := svc.DownloadFile(cmd.Context(), workspaceID, sessionID, sourcePath, localDest, onlyContents); err != nil {
+			if err := svc.DownloadFile(cmd.Context(), workspaceID, sessionID, sourcePath, localDest, onlyContents, opts); err != nil {
 				return err
 			}
 			if !cmdutil.IsQuiet(cmd) {
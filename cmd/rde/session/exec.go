@@ -192,7 +192,7 @@ func buildExecCommand(cmdArgs []string, shell bool) string {
 }
 
 func renderExecResult(cmd *cobra.Command, format output.Format, res internalrde.ExecResult) error {
-	if format == output.JSON {
+	if format.Structured() {
 		return output.Render(cmd.OutOrStdout(), format, res, nil)
 	}
 	// Human mode: stream stdout and stderr to their natural sinks. We
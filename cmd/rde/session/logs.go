@@ -13,7 +13,6 @@ import (
 
 	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
 	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
-	"github.com/bitrise-io/bitrise-cli/internal/output"
 	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
 )
 
@@ -63,8 +62,8 @@ is rejected (the feed is plain text, not a single object).`,
 		Args: cmdutil.RequireArgs("SESSION_ID"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Reject the unsupported flag combo before any network round-trip.
-			if cmdutil.ResolveFormat(cmd) == output.JSON {
-				return fmt.Errorf("--output json is not supported for logs (the feed is plain text, not a single object)")
+			if format := cmdutil.ResolveFormat(cmd); format.Structured() {
+				return fmt.Errorf("--output %s is not supported for logs (the feed is plain text, not a single object)", format)
 			}
 			// Validate --stage up front so a typo errors before the stream
 			// header prints (mirrors notifications' --order validation).
@@ -5,6 +5,9 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -613,3 +616,74 @@ func TestDeleteTerminatedCmd_ProceedsOnYes(t *testing.T) {
 		t.Errorf("unexpected stdout: %q", stdout)
 	}
 }
+
+func TestCreateCmd_FromRepoUsesDotfileWithFlagOverrides(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "my-app")
+	if err := os.MkdirAll(filepath.Join(repo, ".bitrise"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	dotfile := `session:
+  template: ` + uuidTemplate + `
+  inputs:
+    repo: my-app
+    branch: main
+  saved_inputs:
+    gh-token: sv-1
+  labels:
+    team: mobile
+  auto_terminate_minutes: 240
+  feature_flags: [flag-a]
+`
+	if err := os.WriteFile(filepath.Join(repo, ".bitrise", "rde.yml"), []byte(dotfile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repo)
+
+	var gotBody struct {
+		Name          string `json:"name"`
+		TemplateID    string `json:"templateId"`
+		SessionInputs []struct {
+			Key          string `json:"key"`
+			Value        string `json:"value"`
+			SavedInputID string `json:"savedInputId"`
+		} `json:"sessionInputs"`
+		Labels               map[string]string `json:"labels"`
+		AutoTerminateMinutes int               `json:"autoTerminateMinutes"`
+		FeatureFlags         []string          `json:"enabledFeatureFlagNames"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = io.WriteString(w, `{"session":{"id":"s-new","name":"my-app"}}`)
+	}))
+	defer srv.Close()
+
+	_, _, err := run(t, newCreateCmd(), srv.URL, "ws-1",
+		[]string{"--from-repo", "--input", "branch=feature", "-l", "owner=me", "--feature-flag", "flag-b"}, output.Human)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if gotBody.Name != "my-app" || gotBody.TemplateID != uuidTemplate || gotBody.AutoTerminateMinutes != 240 {
+		t.Errorf("name/template/auto-terminate = %q/%q/%d", gotBody.Name, gotBody.TemplateID, gotBody.AutoTerminateMinutes)
+	}
+	inputs := map[string]string{}
+	for _, in := range gotBody.SessionInputs {
+		inputs[in.Key] = in.Value + in.SavedInputID
+	}
+	if want := map[string]string{"repo": "my-app", "branch": "feature", "gh-token": "sv-1"}; !reflect.DeepEqual(inputs, want) {
+		t.Errorf("inputs = %v, want %v (--input overrides the file per key)", inputs, want)
+	}
+	if want := map[string]string{"team": "mobile", "owner": "me"}; !reflect.DeepEqual(gotBody.Labels, want) {
+		t.Errorf("labels = %v, want %v", gotBody.Labels, want)
+	}
+	if want := []string{"flag-a", "flag-b"}; !reflect.DeepEqual(gotBody.FeatureFlags, want) {
+		t.Errorf("feature flags = %v, want %v", gotBody.FeatureFlags, want)
+	}
+}
+
+func TestCreateCmd_FromRepoWithoutSessionSection(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, _, err := run(t, newCreateCmd(), "http://unused", "ws-1", []string{"--from-repo"}, output.Human)
+	if err == nil || !strings.Contains(err.Error(), ".bitrise/rde.yml") {
+		t.Errorf("error = %v, want a missing-dotfile error", err)
+	}
+}
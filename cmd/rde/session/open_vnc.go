@@ -68,7 +68,7 @@ viewer manually.`,
 				return fmt.Errorf("open VNC URL: %w", err)
 			}
 			res := openVNCResult{Opened: true, Address: creds.Address, Username: creds.Username}
-			if format == output.JSON {
+			if format.Structured() {
 				return output.Render(cmd.OutOrStdout(), format, res, nil)
 			}
 			if !cmdutil.IsQuiet(cmd) {
@@ -0,0 +1,175 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+// portForwardEvent is one line of the `port-forward --output json` stream.
+// The shape is part of the CLI's stable contract — additive changes only.
+type portForwardEvent struct {
+	Event     string                     `json:"event"` // "ready" or "reconnecting"
+	SessionID string                     `json:"session_id"`
+	Forwards  []internalrde.BoundForward `json:"forwards,omitempty"`
+	Attempt   int                        `json:"attempt,omitempty"`
+	Error     string                     `json:"error,omitempty"`
+}
+
+func newPortForwardCmd() *cobra.Command {
+	var localSpecs, remoteSpecs, dynamicSpecs []string
+	c := &cobra.Command{
+		Use:   "port-forward SESSION_ID [[BIND:]PORT[:HOST:HOSTPORT]...]",
+		Short: "Forward ports between this machine and a session over SSH",
+		Long: `Forward ports between this machine and a session over SSH, then block until
+Ctrl-C. Use it to reach a dev server or a simulator's debug port inside the
+session from your laptop, or to expose a local service to the session.
+
+Each positional spec after SESSION_ID is a local forward (like ssh -L): a port
+on this machine whose connections are dialed from the session. The spec is
+[bind_address:]port:host:hostport, port:hostport, or just port (the same port
+on the session's localhost). -L adds more local forwards the same way.
+
+  -R [bind_address:]port:host:hostport   listen on the session, dial from here
+  -D [bind_address:]port                 SOCKS5 proxy whose connections leave
+                                         from the session (names resolve there)
+
+Listeners bind loopback unless a bind address is given, on both sides. Port 0
+picks a free port; the bound addresses are reported once the tunnel is up.
+
+The connection is watched with keepalives. When it drops, the tunnel
+reconnects on its own — local ports stay bound throughout, connections made
+while disconnected are refused, and remote listeners are re-opened. It stops
+with an error if the session stops running.
+
+With --output json, one JSON object per line is written to stdout: a "ready"
+event listing the bound addresses after every (re)connect, and a
+"reconnecting" event when the connection drops; --jq and --template run
+once per event. A script can wait for the first "ready" line before using
+the ports:
+
+  {"event":"ready","session_id":"…","forwards":[{"kind":"local","listen":"127.0.0.1:3000","target":"localhost:3000"}]}`,
+		Example: `  bitrise-cli rde session port-forward SESSION_ID 3000
+  bitrise-cli rde session port-forward SESSION_ID 3000 8080:localhost:80 -R 9000:localhost:9000
+  bitrise-cli rde session port-forward SESSION_ID -D 1080
+  bitrise-cli rde session port-forward SESSION_ID 0:localhost:9229 --output json`,
+		Args: cmdutil.RequireArgs("SESSION_ID"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := cmdutil.ResolveFormat(cmd)
+			if format.Structured() && format != output.JSON {
+				return fmt.Errorf("port-forward supports --output json only (it streams events, not a single document)")
+			}
+			forwards, err := parseForwardSpecs(slices.Concat(args[1:], localSpecs), remoteSpecs, dynamicSpecs)
+			if err != nil {
+				return err
+			}
+			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := cmdutil.NewRDEClient(cmd)
+			if err != nil {
+				return err
+			}
+			svc := internalrde.NewService(client)
+			sessionID, err := svc.ResolveSessionID(cmd.Context(), workspaceID, args[0])
+			if err != nil {
+				return err
+			}
+
+			// Ctrl-C stops the tunnel and returns cleanly.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+
+			ew := cmdutil.NewErrWriter(cmd.ErrOrStderr())
+			emit := func(ev portForwardEvent) {
+				if err := output.Emit(cmd.OutOrStdout(), ev); err != nil && ew.Err == nil {
+					ew.Err = err
+				}
+			}
+			streaming := output.Streaming(format)
+			quiet := cmdutil.IsQuiet(cmd)
+			readies := 0
+			err = svc.PortForward(ctx, workspaceID, sessionID, forwards, internalrde.PortForwardOptions{
+				OnReady: func(bound []internalrde.BoundForward) {
+					readies++
+					switch {
+					case streaming:
+						emit(portForwardEvent{Event: "ready", SessionID: sessionID, Forwards: bound})
+					case quiet:
+					case readies == 1:
+						ew.F("Forwarding (Ctrl-C to stop):\n")
+						for _, b := range bound {
+							ew.F("  %s\n", describeForward(b))
+						}
+					default:
+						ew.F("Reconnected.\n")
+					}
+				},
+				OnReconnect: func(attempt int, err error) {
+					if streaming {
+						emit(portForwardEvent{Event: "reconnecting", SessionID: sessionID, Attempt: attempt, Error: err.Error()})
+						return
+					}
+					if attempt == 1 || !quiet {
+						ew.F("Connection lost; reconnecting (attempt %d)…\n", attempt)
+					}
+				},
+			})
+			if err != nil {
+				return err
+			}
+			if !quiet && !streaming {
+				ew.F("Stopped forwarding.\n")
+			}
+			return ew.Err
+		},
+	}
+	c.Flags().StringArrayVarP(&localSpecs, "local", "L", nil, "local forward [bind_address:]port:host:hostport — listen here, dial from the session (repeatable)")
+	c.Flags().StringArrayVarP(&remoteSpecs, "remote", "R", nil, "remote forward [bind_address:]port:host:hostport — listen on the session, dial from here (repeatable)")
+	c.Flags().StringArrayVarP(&dynamicSpecs, "dynamic", "D", nil, "SOCKS5 proxy on [bind_address:]port whose connections leave from the session (repeatable)")
+	return c
+}
+
+// parseForwardSpecs parses every spec, in the order local, remote, dynamic.
+func parseForwardSpecs(local, remote, dynamic []string) ([]internalrde.Forward, error) {
+	var out []internalrde.Forward
+	for _, group := range []struct {
+		kind  string
+		specs []string
+	}{
+		{internalrde.ForwardLocal, local},
+		{internalrde.ForwardRemote, remote},
+		{internalrde.ForwardDynamic, dynamic},
+	} {
+		for _, spec := range group.specs {
+			f, err := internalrde.ParseForward(group.kind, spec)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, f)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("nothing to forward: pass a PORT spec, -L, -R, or -D")
+	}
+	return out, nil
+}
+
+// describeForward is the human line for a bound forward.
+func describeForward(b internalrde.BoundForward) string {
+	switch b.Kind {
+	case internalrde.ForwardRemote:
+		return fmt.Sprintf("session %s → %s", b.Listen, b.Target)
+	case internalrde.ForwardDynamic:
+		return fmt.Sprintf("%s → SOCKS5 proxy via the session", b.Listen)
+	}
+	return fmt.Sprintf("%s → session %s", b.Listen, b.Target)
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+func TestParseForwardSpecs(t *testing.T) {
+	got, err := parseForwardSpecs([]string{"3000", "8080:localhost:80"}, []string{"9000:localhost:9000"}, []string{"1080"})
+	if err != nil {
+		t.Fatalf("parseForwardSpecs: %v", err)
+	}
+	want := []internalrde.Forward{
+		{Kind: internalrde.ForwardLocal, Bind: "127.0.0.1:3000", Target: "localhost:3000"},
+		{Kind: internalrde.ForwardLocal, Bind: "127.0.0.1:8080", Target: "localhost:80"},
+		{Kind: internalrde.ForwardRemote, Bind: "127.0.0.1:9000", Target: "localhost:9000"},
+		{Kind: internalrde.ForwardDynamic, Bind: "127.0.0.1:1080"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d forwards, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("forward %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if _, err := parseForwardSpecs(nil, nil, nil); err == nil || !strings.Contains(err.Error(), "nothing to forward") {
+		t.Errorf("no specs: err = %v, want 'nothing to forward'", err)
+	}
+}
+
+func TestPortForwardCmd_RejectsBadSpecBeforeNetwork(t *testing.T) {
+	c := newPortForwardCmd()
+	c.SilenceUsage = true // production root sets this; detached test cmd must too
+	// No server: a bad spec must fail before any API call.
+	_, _, err := run(t, c, "http://127.0.0.1:0", "ws-1", []string{uuidSession, "http"}, output.Human)
+	if err == nil || !strings.Contains(err.Error(), `invalid port "http"`) {
+		t.Fatalf("err = %v, want invalid port", err)
+	}
+}
+
+func TestPortForwardCmd_RejectsNonJSONStructuredOutput(t *testing.T) {
+	c := newPortForwardCmd()
+	c.SilenceUsage = true // production root sets this; detached test cmd must too
+	_, _, err := run(t, c, "http://127.0.0.1:0", "ws-1", []string{uuidSession, "3000"}, output.YAML)
+	if err == nil || !strings.Contains(err.Error(), "--output json only") {
+		t.Fatalf("err = %v, want the json-only rejection", err)
+	}
+}
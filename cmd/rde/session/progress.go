@@ -0,0 +1,61 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+// transferPhaseVerbs are the past-tense labels of a transfer's final line.
+var transferPhaseVerbs = map[string]string{
+	internalrde.PhaseArchiving:   "Archived",
+	internalrde.PhaseUploading:   "Uploaded",
+	internalrde.PhaseDownloading: "Downloaded",
+	internalrde.PhaseExtracting:  "Extracted",
+}
+
+// transferProgress returns a TransferOptions.Progress callback that renders
+// to w. On a terminal each phase is one line redrawn in place (bytes,
+// percent, rate, ETA); elsewhere only each phase's closing line is written,
+// so logs stay readable.
+func transferProgress(w io.Writer) func(internalrde.TransferProgress) {
+	tty := cmdutil.WriterIsTTY(w)
+	return func(p internalrde.TransferProgress) {
+		switch {
+		case p.Final && tty:
+			_, _ = fmt.Fprintf(w, "\r\033[K%s\n", transferDoneLine(p))
+		case p.Final:
+			_, _ = fmt.Fprintf(w, "%s\n", transferDoneLine(p))
+		case tty:
+			_, _ = fmt.Fprintf(w, "\r\033[K%s", transferProgressLine(p))
+		}
+	}
+}
+
+// transferProgressLine is the live line, e.g.
+// "uploading 12.0 MiB / 48.0 MiB (25%), 4.0 MiB/s, 9s left".
+func transferProgressLine(p internalrde.TransferProgress) string {
+	var b strings.Builder
+	b.WriteString(p.Phase + " " + formatBytes(p.Done))
+	if p.Total > 0 {
+		fmt.Fprintf(&b, " / %s (%d%%)", formatBytes(p.Total), p.Done*100/p.Total)
+	}
+	fmt.Fprintf(&b, ", %s/s", formatBytes(int64(p.Rate())))
+	if eta := p.ETA(); eta > 0 {
+		fmt.Fprintf(&b, ", %s left", eta.Round(time.Second))
+	}
+	return b.String()
+}
+
+// transferDoneLine closes a phase, e.g. "Uploaded 48.0 MiB in 12s (4.0 MiB/s).".
+func transferDoneLine(p internalrde.TransferProgress) string {
+	verb, ok := transferPhaseVerbs[p.Phase]
+	if !ok {
+		verb = p.Phase
+	}
+	return fmt.Sprintf("%s %s in %s (%s/s).", verb, formatBytes(p.Done), p.Elapsed.Round(100*time.Millisecond), formatBytes(int64(p.Rate())))
+}
@@ -0,0 +1,29 @@
+package session
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+func TestTransferProgress_Lines(t *testing.T) {
+	p := internalrde.TransferProgress{Phase: internalrde.PhaseUploading, Done: 12 << 20, Total: 48 << 20, Elapsed: 3 * time.Second}
+	if got, want := transferProgressLine(p), "uploading 12.0 MiB / 48.0 MiB (25%), 4.0 MiB/s, 9s left"; got != want {
+		t.Errorf("progress line = %q, want %q", got, want)
+	}
+	p.Done, p.Elapsed, p.Final = p.Total, 12*time.Second, true
+	if got, want := transferDoneLine(p), "Uploaded 48.0 MiB in 12s (4.0 MiB/s)."; got != want {
+		t.Errorf("done line = %q, want %q", got, want)
+	}
+
+	// Off a terminal only the closing line of each phase is written.
+	var buf bytes.Buffer
+	report := transferProgress(&buf)
+	report(internalrde.TransferProgress{Phase: internalrde.PhaseUploading, Done: 1, Total: 2, Elapsed: time.Second})
+	report(p)
+	if got, want := buf.String(), "Uploaded 48.0 MiB in 12s (4.0 MiB/s).\n"; got != want {
+		t.Errorf("non-TTY output = %q, want %q", got, want)
+	}
+}
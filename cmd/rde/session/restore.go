@@ -56,7 +56,7 @@ and then immediately use the session without hand-rolling a poll loop.`,
 			case internalrde.DiskStatusUnavailable:
 				return fmt.Errorf("session %s cannot be restored: its persistent disk is no longer available", sessionID)
 			case internalrde.DiskStatusUnavailableSoon:
-				if !cmdutil.IsQuiet(cmd) && format != output.JSON {
+				if !cmdutil.IsQuiet(cmd) && !format.Structured() {
 					_, _ = fmt.Fprintf(cmd.ErrOrStderr(),
 						"Warning: this session's persistent disk will become unavailable soon (within ~1 week) — restore while you still can.\n")
 				}
@@ -70,7 +70,7 @@ and then immediately use the session without hand-rolling a poll loop.`,
 			if wait {
 				waitCtx, cancel := context.WithTimeout(cmd.Context(), waitTimeout)
 				defer cancel()
-				if !cmdutil.IsQuiet(cmd) && format != output.JSON {
+				if !cmdutil.IsQuiet(cmd) && !format.Structured() {
 					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Waiting for session %s to become ready (timeout %s)…\n", restored.ID, waitTimeout)
 				}
 				ready, waitErr := svc.WaitForReady(waitCtx, workspaceID, restored.ID, 0, nil)
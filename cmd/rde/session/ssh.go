@@ -0,0 +1,135 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+func newSSHCmd() *cobra.Command {
+	var (
+		forwardAgent bool
+		restore      bool
+		noTmux       bool
+		waitTimeout  time.Duration
+	)
+	c := &cobra.Command{
+		Use:   "ssh SESSION_ID [-- COMMAND [ARGS...]]",
+		Short: "Open an interactive shell (or TTY program) on a session",
+		Long: `Open an interactive login shell on a session, or run a TTY program such as
+top or vim, with your terminal attached.
+
+A PTY is allocated when stdin is a terminal; your $TERM is forwarded (its
+terminfo entry is installed on the session if the image lacks it), and window
+resizes follow. The tokens after '--' are a program plus literal arguments,
+quoted the same way as 'rde session exec'.
+
+The shell runs inside a tmux session on the VM when the image has tmux, so a
+network blip doesn't kill it: the connection is watched with keepalives, and
+when it drops the CLI reattaches on its own, retrying until the session is
+reachable again (Ctrl-C stops retrying). A command that finished while you were
+disconnected still reports its exit status. Pass --no-tmux to run the program
+directly instead — a dropped shell is then replaced by a fresh one, and a
+dropped command ends the run.
+
+Like OpenSSH, your local SSH agent is only forwarded with -A. Forward it when
+the session needs your keys (git over SSH); the session VM can then use them
+for as long as you're connected.
+
+A session that isn't running yet is waited on. A terminated one is an error
+unless --restore is given, which restores it from its persistent disk first.
+
+The CLI exits with an error when the shell or program exits non-zero.`,
+		Example: `  bitrise-cli rde session ssh SESSION_ID
+  bitrise-cli rde session ssh SESSION_ID -A
+  bitrise-cli rde session ssh SESSION_ID --restore
+  bitrise-cli rde session ssh SESSION_ID -- top
+  bitrise-cli rde session ssh SESSION_ID -- vim README.md`,
+		Args: cmdutil.RequireArgs("SESSION_ID"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
+			if err != nil {
+				return err
+			}
+			if format := cmdutil.ResolveFormat(cmd); format.Structured() {
+				return fmt.Errorf("ssh cannot be combined with --output %s (it attaches your terminal, not a single-object result)", format)
+			}
+			client, err := cmdutil.NewRDEClient(cmd)
+			if err != nil {
+				return err
+			}
+			svc := internalrde.NewService(client)
+			sessionID, err := svc.ResolveSessionID(cmd.Context(), workspaceID, args[0])
+			if err != nil {
+				return err
+			}
+
+			ew := cmdutil.NewErrWriter(cmd.ErrOrStderr())
+			quiet := cmdutil.IsQuiet(cmd)
+			waitCtx, cancel := context.WithTimeout(cmd.Context(), waitTimeout)
+			defer cancel()
+			_, err = svc.EnsureSSHReady(waitCtx, workspaceID, sessionID, restore, 0, func(phase string) {
+				if !quiet {
+					ew.F("%s\n", sshPhaseMessage(phase))
+				}
+			})
+			if errors.Is(err, internalrde.ErrSessionStopped) {
+				return fmt.Errorf("%w; pass --restore to restore it first", err)
+			}
+			if err != nil {
+				return err
+			}
+
+			// While attached the terminal is raw and Ctrl-C goes to the remote
+			// program; between reconnect attempts it stops retrying.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			code, err := svc.Shell(ctx, workspaceID, sessionID, internalrde.ShellOptions{
+				Command:      cmdutil.JoinShellArgs(args[1:]),
+				ForwardAgent: forwardAgent,
+				NoTmux:       noTmux,
+				OnReconnect: func(attempt int, _ error) {
+					if attempt == 1 {
+						ew.F("\nConnection lost; reconnecting…\n")
+					} else if !quiet {
+						ew.F("Still unreachable; retrying (attempt %d)…\n", attempt)
+					}
+				},
+			}, os.Stdin, os.Stdout, os.Stderr)
+			if err != nil {
+				return err
+			}
+			if code != 0 {
+				cmdutil.SilenceRootErrors(cmd)
+				return fmt.Errorf("remote shell exited with status %d", code)
+			}
+			return ew.Err
+		},
+	}
+	c.Flags().BoolVarP(&forwardAgent, "forward-agent", "A", false, "forward your local SSH agent into the session (like ssh -A)")
+	c.Flags().BoolVar(&restore, "restore", false, "restore the session first if it is terminated")
+	c.Flags().BoolVar(&noTmux, "no-tmux", false, "run without the tmux wrapper (the shell does not survive a dropped connection)")
+	c.Flags().DurationVar(&waitTimeout, "wait-timeout", 10*time.Minute, "max time to wait for the session (and a restore) to become reachable over SSH")
+	return c
+}
+
+// sshPhaseMessage is the stderr progress line for an EnsureSSHReady phase.
+func sshPhaseMessage(phase string) string {
+	switch phase {
+	case internalrde.PhaseRestoring:
+		return "Restoring session…"
+	case internalrde.PhaseBooting:
+		return "Waiting for the session to boot…"
+	case internalrde.PhaseSSHWaiting:
+		return "Waiting for SSH access…"
+	}
+	return phase
+}
@@ -0,0 +1,37 @@
+package session
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+func TestSSHCmd_TerminatedWithoutRestoreHintsFlag(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s %s: a terminated session must not be restored without --restore", r.Method, r.URL.Path)
+		}
+		_, _ = io.WriteString(w, `{"session":{"id":"s-1","name":"dev","status":"SESSION_STATUS_TERMINATED"}}`)
+	}))
+	defer srv.Close()
+
+	c := newSSHCmd()
+	c.SilenceUsage = true // production root sets this; detached test cmd must too
+	_, _, err := run(t, c, srv.URL, "ws-1", []string{uuidSession}, output.Human)
+	if err == nil || !strings.Contains(err.Error(), "--restore") {
+		t.Fatalf("err = %v, want a hint to pass --restore", err)
+	}
+}
+
+func TestSSHCmd_RejectsStructuredOutput(t *testing.T) {
+	c := newSSHCmd()
+	c.SilenceUsage = true // production root sets this; detached test cmd must too
+	_, _, err := run(t, c, "http://127.0.0.1:0", "ws-1", []string{uuidSession}, output.JSON)
+	if err == nil || !strings.Contains(err.Error(), "--output json") {
+		t.Fatalf("err = %v, want the --output rejection", err)
+	}
+}
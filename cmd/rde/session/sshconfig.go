@@ -0,0 +1,151 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+// cliName is the binary name a ProxyCommand falls back to when the running
+// executable can't be located.
+const cliName = "bitrise-cli"
+
+func newSSHConfigCmd() *cobra.Command {
+	var all bool
+	c := &cobra.Command{
+		Use:   "ssh-config [SESSION_ID...|--all]",
+		Short: "Print OpenSSH config Host blocks for sessions",
+		Long: `Print an OpenSSH config "Host" block for each session, so plain ssh, scp,
+rsync, VS Code Remote-SSH, and JetBrains Gateway can reach it by name:
+
+  ssh rde-my-session
+  rsync -a ./build/ rde-my-session:build/
+
+Each block routes the connection through 'bitrise-cli rde ssh-proxy' as its
+ProxyCommand. On every connect the proxy fetches the session's current address
+and ephemeral password, restores the session if it was terminated, and tunnels
+to the session's sshd over the CLI's own SSH connection — no credentials are
+written into the config, and it keeps working across restores.
+
+The client authenticates with a key pair the CLI generates on first use and
+keeps in its config directory; the proxy installs the public key on the
+session. Host-key checking is off for these hosts only: every session VM has a
+fresh host key, and the hop to it is already authenticated by the CLI.
+
+The Host alias is "rde-" plus the session name (its ID when unnamed). The
+ProxyCommand pins the workspace and profile this command ran with. Save the
+output to a file and include it from ~/.ssh/config:
+
+  bitrise-cli rde session ssh-config --all > ~/.ssh/bitrise-rde.conf
+  echo 'Include ~/.ssh/bitrise-rde.conf' >> ~/.ssh/config
+
+--all covers every session that is running or can be restored. User is only
+known for a session that has run; re-run the command after a new session starts.`,
+		Example: `  bitrise-cli rde session ssh-config SESSION_ID
+  bitrise-cli rde session ssh-config --all > ~/.ssh/bitrise-rde.conf
+  bitrise-cli rde session ssh-config --all --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return fmt.Errorf("pass SESSION_ID arguments or --all (not both)")
+			}
+			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
+			if err != nil {
+				return err
+			}
+			format := cmdutil.ResolveFormat(cmd)
+			client, err := cmdutil.NewRDEClient(cmd)
+			if err != nil {
+				return err
+			}
+			svc := internalrde.NewService(client)
+
+			var sessions []internalrde.Session
+			if all {
+				listed, err := svc.ListSessions(cmd.Context(), workspaceID, nil)
+				if err != nil {
+					return err
+				}
+				for _, sess := range listed {
+					if sess.Resumable() {
+						sessions = append(sessions, sess)
+					}
+				}
+			} else {
+				for _, arg := range args {
+					id, err := svc.ResolveSessionID(cmd.Context(), workspaceID, arg)
+					if err != nil {
+						return err
+					}
+					sess, err := svc.GetSession(cmd.Context(), workspaceID, id)
+					if err != nil {
+						return err
+					}
+					sessions = append(sessions, sess)
+				}
+			}
+
+			key, err := internalrde.EnsureProxyKey()
+			if err != nil {
+				return err
+			}
+			exe := selfExecutable()
+			profile := config.FromContext(cmd.Context()).Profile
+			hosts := internalrde.SSHConfigHosts(sessions, key.Path, func(sessionID string) string {
+				proxyArgs := []string{"rde", "ssh-proxy", "--" + cmdutil.FlagWorkspace, workspaceID}
+				if profile != "" {
+					proxyArgs = append(proxyArgs, "--"+cmdutil.FlagProfile, profile)
+				}
+				return internalrde.SSHProxyCommand(exe, append(proxyArgs, sessionID)...)
+			})
+			if len(hosts) == 0 && !format.Structured() {
+				ew := cmdutil.NewErrWriter(cmd.ErrOrStderr())
+				ew.F("No running or restorable sessions.\n")
+				return ew.Err
+			}
+			return output.Render(cmd.OutOrStdout(), format, hosts, func(w io.Writer, hosts []internalrde.SSHConfigHost) error {
+				return internalrde.WriteSSHConfig(w, hosts)
+			})
+		},
+	}
+	c.Flags().BoolVar(&all, "all", false, "emit a block for every running or restorable session in the workspace")
+	return c
+}
+
+// selfExecutable is the path a ProxyCommand should run to reach this CLI. The
+// PATH entry is preferred when it is this same binary: package managers
+// (Homebrew) run it from a versioned directory that an upgrade removes, while
+// the PATH symlink stays. IDEs launched outside a shell may lack the user's
+// PATH, so the result is always absolute.
+func selfExecutable() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return cliName
+	}
+	if onPath, err := exec.LookPath(cliName); err == nil {
+		if abs, err := filepath.Abs(onPath); err == nil && sameFile(abs, exe) {
+			return abs
+		}
+	}
+	return exe
+}
+
+func sameFile(a, b string) bool {
+	fa, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	fb, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(fa, fb)
+}
@@ -0,0 +1,55 @@
+package session
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+func TestSSHConfigCmd_AllSkipsUnrestorableSessions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/workspaces/ws-1/sessions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		_, _ = io.WriteString(w, `{"sessions":[
+			{"id":"s-1","name":"dev box","status":"SESSION_STATUS_RUNNING","sshAddress":"ssh -p 22 ubuntu@10.0.0.1"},
+			{"id":"s-2","name":"old","status":"SESSION_STATUS_TERMINATED","persistentDiskStatus":"PERSISTENT_DISK_STATUS_UNAVAILABLE"}
+		]}`)
+	}))
+	defer srv.Close()
+
+	stdout, _, err := run(t, newSSHConfigCmd(), srv.URL, "ws-1", []string{"--all"}, output.Human)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	for _, want := range []string{"Host rde-dev-box\n", "  User ubuntu\n", " rde ssh-proxy --workspace ws-1 s-1\n", "  IdentityFile "} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout missing %q:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "rde-old") {
+		t.Errorf("a session whose disk is gone can't be restored and should be skipped:\n%s", stdout)
+	}
+}
+
+func TestSSHConfigCmd_RequiresSessionOrAll(t *testing.T) {
+	c := newSSHConfigCmd()
+	c.SilenceUsage = true // production root sets this; detached test cmd must too
+	_, _, err := run(t, c, "http://127.0.0.1:0", "ws-1", nil, output.Human)
+	if err == nil || !strings.Contains(err.Error(), "--all") {
+		t.Fatalf("err = %v, want a SESSION_ID-or---all error", err)
+	}
+}
+
+func TestSSHProxyCmd_RejectsStructuredOutput(t *testing.T) {
+	c := NewSSHProxyCmd()
+	c.SilenceUsage = true // production root sets this; detached test cmd must too
+	_, _, err := run(t, c, "http://127.0.0.1:0", "ws-1", []string{uuidSession}, output.JSON)
+	if err == nil || !strings.Contains(err.Error(), "stdout carries the SSH stream") {
+		t.Fatalf("err = %v, want the --output rejection", err)
+	}
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+// NewSSHProxyCmd returns `bitrise-cli rde ssh-proxy`, the OpenSSH
+// ProxyCommand behind the Host blocks `rde session ssh-config` prints. It
+// lives with the session commands it shares helpers with but is mounted
+// directly under `rde`, keeping the ProxyCommand line short.
+func NewSSHProxyCmd() *cobra.Command {
+	var waitTimeout time.Duration
+	c := &cobra.Command{
+		Use:   "ssh-proxy SESSION_ID",
+		Short: "Tunnel stdin/stdout to a session's sshd (for ssh's ProxyCommand)",
+		Long: `Connect stdin and stdout to a session's sshd, for use as an OpenSSH
+ProxyCommand. You rarely run it by hand: 'rde session ssh-config' prints Host
+blocks that use it.
+
+On each connect it looks the session up (by ID or name), restores it if it was
+terminated, waits until it accepts SSH, and fetches its current address and
+ephemeral password. It then dials the session itself, installs the CLI-managed
+public key (see 'rde session ssh-config'), and relays bytes between ssh and the
+session's sshd until either side closes.
+
+stdout carries the SSH protocol, so progress and errors go to stderr only.`,
+		Example: `  bitrise-cli rde session ssh-config SESSION_ID >> ~/.ssh/config
+  ssh -o ProxyCommand='bitrise-cli rde ssh-proxy SESSION_ID' ubuntu@SESSION_ID`,
+		Args: cmdutil.RequireArgs("SESSION_ID"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format := cmdutil.ResolveFormat(cmd); format.Structured() {
+				return fmt.Errorf("ssh-proxy cannot be combined with --output %s (stdout carries the SSH stream)", format)
+			}
+			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := cmdutil.NewRDEClient(cmd)
+			if err != nil {
+				return err
+			}
+			key, err := internalrde.EnsureProxyKey()
+			if err != nil {
+				return err
+			}
+			svc := internalrde.NewService(client)
+			sessionID, err := svc.ResolveSessionID(cmd.Context(), workspaceID, args[0])
+			if err != nil {
+				return err
+			}
+
+			ew := cmdutil.NewErrWriter(cmd.ErrOrStderr())
+			quiet := cmdutil.IsQuiet(cmd)
+			waitCtx, cancel := context.WithTimeout(cmd.Context(), waitTimeout)
+			defer cancel()
+			if _, err := svc.EnsureSSHReady(waitCtx, workspaceID, sessionID, true, 0, func(phase string) {
+				if !quiet {
+					ew.F("%s\n", sshPhaseMessage(phase))
+				}
+			}); err != nil {
+				return err
+			}
+
+			// ssh closes the pipe when it's done, or interrupts the proxy;
+			// either ends the relay cleanly.
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			if err := svc.ProxySSH(ctx, workspaceID, sessionID, key.AuthorizedKey, cmd.InOrStdin(), cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			return ew.Err
+		},
+	}
+	c.Flags().DurationVar(&waitTimeout, "wait-timeout", 10*time.Minute, "max time to wait for the session (and a restore) to become reachable over SSH")
+	return c
+}
@@ -0,0 +1,226 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+// syncEvent is one line of the `sync --watch --output json` stream. The shape
+// is part of the CLI's stable contract — additive changes only.
+type syncEvent struct {
+	Event  string                  `json:"event"` // "synced" or "error"
+	Result *internalrde.SyncResult `json:"result,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+func newSyncCmd() *cobra.Command {
+	var (
+		opts     internalrde.SyncOptions
+		watch    bool
+		interval time.Duration
+	)
+	c := &cobra.Command{
+		Use:   "sync SESSION_ID LOCAL_DIR REMOTE_DIR",
+		Short: "Sync a local directory with a directory on a session over SSH",
+		Long: `Sync a local directory with a directory on a session, transferring only the
+files whose content differs. Both sides are listed with a SHA-256 per file,
+and the changed files travel over SSH as one compressed stream — no cloud
+storage round trip, and an unchanged tree costs a single listing. Files of
+1 MiB or more that the other side already has go as a block delta: pushing,
+like rsync, only the bytes that match none of the session copy's blocks
+travel, wherever in the file they moved; pulling, only the blocks that
+differ at the same offset. A delta needs GNU split on the session; without
+it, or if the rebuilt file doesn't check out, the file is sent whole.
+
+Paths excluded by .gitignore files (the root one and any nested ones) are
+skipped on both sides, and so is .git. Add patterns with --exclude (same
+syntax), or ignore .gitignore with --no-gitignore. Only regular files are
+synced; symlinks and empty directories are skipped. A relative REMOTE_DIR is
+taken from the session user's home, so src/my-app is ~/src/my-app on the
+session. Quote a leading ~ ('~/src/my-app'): unquoted, your shell expands it
+to your local home directory before the CLI sees it.
+
+--direction picks which way changes flow:
+
+  push   (default) make REMOTE_DIR match LOCAL_DIR
+  pull   make LOCAL_DIR match REMOTE_DIR
+  both   carry edits made on either side since the last sync to the other
+
+push and pull never delete unless --delete is given, like rsync. both
+remembers the state of the previous sync (in the CLI's config directory), so a
+file deleted on one side is deleted on the other; a file changed on both
+sides is left alone on both and reported as a conflict, and the command exits
+with an error. Fix it on one side and sync again.
+
+--watch keeps pushing: after the first sync it watches LOCAL_DIR for file
+events (inotify, FSEvents/kqueue, ReadDirectoryChangesW) and pushes each
+change as soon as it lands, until Ctrl-C. Where file events are unavailable
+(say, the inotify watch limit is reached) it falls back to rescanning every
+--interval. Only stat information is read on a rescan; files are hashed
+again only when their size or modification time moved. Edits made on the
+session meanwhile are not pulled back. A failed pass (say, the connection
+dropped) is reported and retried after --interval.
+
+--dry-run prints the plan without changing anything. With --output json a
+single result object is printed; with --watch, one JSON event per pass, and
+--jq and --template run once per event.`,
+		Example: `  bitrise-cli rde session sync SESSION_ID . src/my-app
+  bitrise-cli rde session sync SESSION_ID . src/my-app --watch
+  bitrise-cli rde session sync SESSION_ID . src/my-app --delete --dry-run
+  bitrise-cli rde session sync SESSION_ID ./build src/my-app/build --direction pull
+  bitrise-cli rde session sync SESSION_ID . src/my-app --direction both`,
+		Args: cmdutil.RequireArgs("SESSION_ID", "LOCAL_DIR", "REMOTE_DIR"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.LocalDir, opts.RemoteDir = args[1], args[2]
+			format := cmdutil.ResolveFormat(cmd)
+			if watch && format.Structured() && format != output.JSON {
+				return fmt.Errorf("sync --watch supports --output json only (it streams events, not a single document)")
+			}
+			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := cmdutil.NewRDEClient(cmd)
+			if err != nil {
+				return err
+			}
+			svc := internalrde.NewService(client)
+			sessionID, err := svc.ResolveSessionID(cmd.Context(), workspaceID, args[0])
+			if err != nil {
+				return err
+			}
+			ew := cmdutil.NewErrWriter(cmd.ErrOrStderr())
+			quiet := cmdutil.IsQuiet(cmd)
+
+			if watch {
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+				defer stop()
+				emit := func(ev syncEvent) {
+					if err := output.Emit(cmd.OutOrStdout(), ev); err != nil && ew.Err == nil {
+						ew.Err = err
+					}
+				}
+				streaming := output.Streaming(format)
+				first := true
+				err := svc.SyncWatch(ctx, workspaceID, sessionID, opts, internalrde.SyncWatchOptions{
+					Interval: interval,
+					OnSync: func(res internalrde.SyncResult) {
+						if streaming {
+							emit(syncEvent{Event: "synced", Result: &res})
+							return
+						}
+						_ = writeSyncResult(cmd.OutOrStdout(), res)
+						if !quiet {
+							ew.F("%s\n", syncSummary(res))
+							if first {
+								ew.F("Watching %s for changes (Ctrl-C to stop)…\n", opts.LocalDir)
+							}
+						}
+						first = false
+					},
+					OnPolling: func(err error) {
+						ew.F("File events unavailable (%v); rescanning every %s.\n", err, interval)
+					},
+					OnError: func(err error) {
+						if streaming {
+							emit(syncEvent{Event: "error", Error: err.Error()})
+							return
+						}
+						ew.F("Sync failed: %v; retrying in %s.\n", err, interval)
+					},
+				})
+				if err != nil {
+					return err
+				}
+				return ew.Err
+			}
+
+			res, err := svc.Sync(cmd.Context(), workspaceID, sessionID, opts)
+			if err != nil {
+				return err
+			}
+			if err := output.Render(cmd.OutOrStdout(), format, res, writeSyncResult); err != nil {
+				return err
+			}
+			if !quiet && !format.Structured() {
+				ew.F("%s\n", syncSummary(res))
+			}
+			if len(res.Conflicts) > 0 {
+				cmdutil.SilenceRootErrors(cmd)
+				return fmt.Errorf("%d conflicting file(s) left unsynced", len(res.Conflicts))
+			}
+			return ew.Err
+		},
+	}
+	c.Flags().StringVar(&opts.Direction, "direction", internalrde.SyncPush, "push (local → session), pull (session → local), or both")
+	c.Flags().BoolVar(&opts.Delete, "delete", false, "with push or pull, delete files missing from the source")
+	c.Flags().BoolVar(&opts.DryRun, "dry-run", false, "print what would change without changing anything")
+	c.Flags().StringArrayVar(&opts.Excludes, "exclude", nil, "extra exclude pattern in .gitignore syntax (repeatable)")
+	c.Flags().BoolVar(&opts.NoGitignore, "no-gitignore", false, "don't read .gitignore files (.git and --exclude still apply)")
+	c.Flags().BoolVar(&watch, "watch", false, "keep pushing local changes until Ctrl-C")
+	c.Flags().DurationVar(&interval, "interval", internalrde.DefaultSyncWatchInterval, "with --watch, how soon to retry a failed pass, and how often to rescan where file events are unavailable")
+	return c
+}
+
+// writeSyncResult is the human rendering: one line per file, rsync-style.
+func writeSyncResult(w io.Writer, res internalrde.SyncResult) error {
+	ew := cmdutil.NewErrWriter(w)
+	for _, group := range []struct {
+		mark  string
+		paths []string
+	}{
+		{"↑", res.Uploaded},
+		{"↓", res.Downloaded},
+		{"- session:", res.DeletedRemote},
+		{"- local:", res.DeletedLocal},
+	} {
+		for _, p := range group.paths {
+			ew.F("%s %s\n", group.mark, p)
+		}
+	}
+	for _, c := range res.Conflicts {
+		ew.F("! %s: %s\n", c.Path, c.Reason)
+	}
+	return ew.Err
+}
+
+// syncSummary is the stderr line closing a sync pass.
+func syncSummary(res internalrde.SyncResult) string {
+	verb := "Synced"
+	if res.DryRun {
+		verb = "Dry run"
+	}
+	if !res.Changed() {
+		return verb + ": already up to date."
+	}
+	s := fmt.Sprintf("%s: %d uploaded (%s), %d downloaded (%s), %d deleted",
+		verb, len(res.Uploaded), formatBytes(res.BytesUploaded), len(res.Downloaded), formatBytes(res.BytesDownloaded),
+		len(res.DeletedRemote)+len(res.DeletedLocal))
+	if n := len(res.Conflicts); n > 0 {
+		s += fmt.Sprintf(", %d conflict(s)", n)
+	}
+	return s + "."
+}
+
+// formatBytes renders n with a binary unit, e.g. 1.5 MiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
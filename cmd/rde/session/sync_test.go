@@ -0,0 +1,34 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+func TestSyncCmd_RejectsUnknownDirection(t *testing.T) {
+	c := newSyncCmd()
+	c.SilenceUsage = true // production root sets this; detached test cmd must too
+	_, _, err := run(t, c, "http://127.0.0.1:0", "ws-1", []string{uuidSession, t.TempDir(), "~/src", "--direction", "sideways"}, output.Human)
+	if err == nil || !strings.Contains(err.Error(), `unknown sync direction "sideways"`) {
+		t.Fatalf("err = %v, want the direction error before any connection", err)
+	}
+}
+
+func TestSyncCmd_WatchStreamsJSONOnly(t *testing.T) {
+	c := newSyncCmd()
+	c.SilenceUsage = true // production root sets this; detached test cmd must too
+	_, _, err := run(t, c, "http://127.0.0.1:0", "ws-1", []string{uuidSession, t.TempDir(), "~/src", "--watch"}, output.YAML)
+	if err == nil || !strings.Contains(err.Error(), "--output json only") {
+		t.Fatalf("err = %v, want the --output rejection", err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KiB", 5 << 30: "5.0 GiB"} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -16,9 +16,10 @@ func newTerminateCmd() *cobra.Command {
 	var (
 		wait        bool
 		waitTimeout time.Duration
+		bulk        bulkFlags
 	)
 	c := &cobra.Command{
-		Use:   "terminate SESSION_ID",
+		Use:   "terminate [SESSION_ID]",
 		Short: "Terminate a running session (preserves it for later restart)",
 		Long: `Terminate a running session (preserves it for later restart).
 
@@ -26,13 +27,33 @@ Terminate is asynchronous: by default the command returns while the session
 is still "terminating". Pass --wait to block until the session settles into a
 terminal state ("terminated" or "failed"). This is what makes a
 'terminate --wait && delete' pipeline reliable — delete rejects any session
-that isn't yet terminated or failed.`,
-		Args: cmdutil.RequireArgs("SESSION_ID"),
+that isn't yet terminated or failed.
+
+Instead of one SESSION_ID, --label-selector, --status and --older-than
+terminate every session matching all of them. The matching sessions are
+listed first and, on a terminal, you're asked to confirm; elsewhere pass
+--yes. --dry-run only lists them. --concurrency sessions are terminated at
+once, and a failing one doesn't stop the rest.`,
+		Example: `  bitrise-cli rde session terminate SESSION_ID --wait
+  bitrise-cli rde session terminate -l team=mobile --older-than 48h --status running --dry-run
+  bitrise-cli rde session terminate -l team=mobile --older-than 48h --status running --yes --output json`,
+		Args: bulk.args,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
 			if err != nil {
 				return err
 			}
+			if bulk.active(cmd) {
+				return runBulk(cmd, workspaceID, &bulk, "terminate", func(ctx context.Context, svc *internalrde.Service, workspaceID string, sess internalrde.Session) (internalrde.Session, error) {
+					terminated, err := svc.TerminateSession(ctx, workspaceID, sess.ID)
+					if err != nil || !wait {
+						return terminated, err
+					}
+					waitCtx, cancel := context.WithTimeout(ctx, waitTimeout)
+					defer cancel()
+					return svc.WaitForTerminated(waitCtx, workspaceID, sess.ID, 0)
+				})
+			}
 			format := cmdutil.ResolveFormat(cmd)
 			client, err := cmdutil.NewRDEClient(cmd)
 			if err != nil {
@@ -51,7 +72,7 @@ that isn't yet terminated or failed.`,
 			if wait {
 				waitCtx, cancel := context.WithTimeout(cmd.Context(), waitTimeout)
 				defer cancel()
-				if !cmdutil.IsQuiet(cmd) && format != output.JSON {
+				if !cmdutil.IsQuiet(cmd) && !format.Structured() {
 					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Waiting for session %s to terminate (timeout %s)…\n", sess.ID, waitTimeout)
 				}
 				settled, waitErr := svc.WaitForTerminated(waitCtx, workspaceID, sess.ID, 0)
@@ -66,5 +87,6 @@ that isn't yet terminated or failed.`,
 	}
 	c.Flags().BoolVar(&wait, "wait", false, "block until the session settles into a terminal state (terminated/failed) before returning; makes 'terminate --wait && delete' reliable")
 	c.Flags().DurationVar(&waitTimeout, "wait-timeout", 10*time.Minute, "max time to wait when --wait is set (Go duration syntax: 30s, 5m, 1h)")
+	bulk.register(c)
 	return c
 }
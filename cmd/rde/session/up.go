@@ -0,0 +1,354 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	internalapp "github.com/bitrise-io/bitrise-cli/internal/app"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+// upTarget is the repo and branch `rde up`, `down` and `status` act on.
+type upTarget struct {
+	repoSlug string
+	branch   string
+	root     string // local repo root
+}
+
+// resolveUpTarget reads the repo (origin remote) and branch of the working
+// directory; branch, when non-empty, overrides the checked-out one.
+func resolveUpTarget(ctx context.Context, branch string) (upTarget, error) {
+	detector := internalapp.ExecGitDetector{}
+	originURL, err := detector.RemoteURL(ctx)
+	if err != nil {
+		return upTarget{}, fmt.Errorf("detect git remote: %w", err)
+	}
+	slug := internalrde.RepoSlugFromURL(originURL)
+	if slug == "" {
+		return upTarget{}, fmt.Errorf("current directory is not a git repository with an 'origin' remote; the session is looked up by repo and branch")
+	}
+	if branch == "" {
+		if branch, err = detector.CurrentBranch(ctx); err != nil {
+			return upTarget{}, fmt.Errorf("detect git branch: %w", err)
+		}
+		if branch == "" {
+			return upTarget{}, fmt.Errorf("could not determine the current git branch (detached HEAD?); check out a branch or pass --branch")
+		}
+	}
+	root := ""
+	if out, err := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel").Output(); err == nil {
+		root = strings.TrimSpace(string(out))
+	}
+	return upTarget{repoSlug: slug, branch: branch, root: root}, nil
+}
+
+// NewUpCmd returns `rde up`. Like `rde ssh-proxy` it lives here to share the
+// session commands' helpers, but hangs off the rde root.
+func NewUpCmd() *cobra.Command {
+	var (
+		branch       string
+		syncDir      string
+		attach       bool
+		forwardAgent bool
+		waitTimeout  time.Duration
+	)
+	c := &cobra.Command{
+		Use:   "up",
+		Short: "Bring up the dev session for the current repo and branch",
+		Long: `Make sure the dev session for the current repo and branch exists and is
+running, creating or restoring it as needed — one command to get back to
+work.
+
+The session is found by its labels: repo (the origin remote's owner/repo)
+and branch. up then:
+
+  - waits for it, if it's running or still starting
+  - restores it from its persistent disk, if it was terminated
+  - creates it from the session section of .bitrise/rde.yml (see 'rde
+    session create --from-repo'), if there is none yet, labelled with the
+    repo and branch and named after them; the file's post_create commands
+    then run on it
+
+Once the session accepts SSH, --sync REMOTE_DIR pushes the repo's working
+tree to REMOTE_DIR on the session (a one-off 'rde session sync'; .gitignore
+applies), and --attach opens an interactive shell on it like 'rde session
+ssh'. A relative REMOTE_DIR is taken from the session user's home. Quote a
+leading ~ ('~/src/my-app'): unquoted, your shell expands it to your local
+home, which doesn't exist on the session.
+
+Use --branch to bring up another branch's session. 'rde status' shows the
+session and 'rde down' terminates it.`,
+		Example: `  bitrise-cli rde up
+  bitrise-cli rde up --sync src/my-app --attach
+  bitrise-cli rde up --branch main --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format := cmdutil.ResolveFormat(cmd)
+			if attach && format.Structured() {
+				return fmt.Errorf("--attach cannot be combined with --output %s (it attaches your terminal)", format)
+			}
+			target, err := resolveUpTarget(cmd.Context(), branch)
+			if err != nil {
+				return err
+			}
+			if syncDir != "" && target.root == "" {
+				return fmt.Errorf("--sync: could not find the repo root to sync")
+			}
+			repoCfg, path, err := internalrde.LoadRepoConfig()
+			if err != nil {
+				return err
+			}
+			opts := internalrde.UpOptions{RepoSlug: target.repoSlug, Branch: target.branch}
+			var envVars []internalrde.EnvVar
+			if repoCfg.Session.Defined() {
+				if err := repoCfg.Session.Validate(path); err != nil {
+					return err
+				}
+				name := repoCfg.Session.SessionName(path) + "-" + strings.ReplaceAll(target.branch, "/", "-")
+				req := repoCfg.Session.CreateRequest(name)
+				opts.Create = &req
+				if len(repoCfg.Session.PostCreate) > 0 {
+					if envVars, err = postCreateEnv(cmd, repoCfg, path); err != nil {
+						return err
+					}
+				}
+			}
+			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := cmdutil.NewRDEClient(cmd)
+			if err != nil {
+				return err
+			}
+			svc := internalrde.NewService(client)
+
+			ew := cmdutil.NewErrWriter(cmd.ErrOrStderr())
+			quiet := cmdutil.IsQuiet(cmd)
+			opts.OnPhase = func(phase string) {
+				if quiet {
+					return
+				}
+				if phase == internalrde.PhaseCreating {
+					ew.F("Creating a session for %s @ %s…\n", target.repoSlug, target.branch)
+					return
+				}
+				ew.F("%s\n", sshPhaseMessage(phase))
+			}
+			waitCtx, cancel := context.WithTimeout(cmd.Context(), waitTimeout)
+			defer cancel()
+			res, err := svc.Up(waitCtx, workspaceID, opts)
+			if errors.Is(err, internalrde.ErrNoUpSession) {
+				return fmt.Errorf("%w; add a session section to .bitrise/rde.yml so up can create one (see 'rde session create --help')", err)
+			}
+			if err != nil {
+				return err
+			}
+			if res.Action == internalrde.UpActionCreated && opts.Create != nil {
+				if err := runPostCreate(cmd, svc, workspaceID, res.Session.ID, repoCfg.Session.PostCreate, envVars); err != nil {
+					return err
+				}
+			}
+			if err := output.Render(cmd.OutOrStdout(), format, res, renderUpResult); err != nil {
+				return err
+			}
+
+			if syncDir != "" {
+				sres, err := svc.Sync(cmd.Context(), workspaceID, res.Session.ID, internalrde.SyncOptions{LocalDir: target.root, RemoteDir: syncDir})
+				if err != nil {
+					return fmt.Errorf("sync: %w", err)
+				}
+				if !quiet {
+					ew.F("%s\n", syncSummary(sres))
+				}
+			}
+			if attach {
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+				defer stop()
+				code, err := svc.Shell(ctx, workspaceID, res.Session.ID, internalrde.ShellOptions{
+					ForwardAgent: forwardAgent,
+					OnReconnect: func(attempt int, _ error) {
+						if attempt == 1 {
+							ew.F("\nConnection lost; reconnecting…\n")
+						}
+					},
+				}, os.Stdin, os.Stdout, os.Stderr)
+				if err != nil {
+					return err
+				}
+				if code != 0 {
+					cmdutil.SilenceRootErrors(cmd)
+					return fmt.Errorf("remote shell exited with status %d", code)
+				}
+			}
+			return ew.Err
+		},
+	}
+	c.Flags().StringVar(&branch, "branch", "", "branch whose session to bring up (default: the checked-out branch)")
+	c.Flags().StringVar(&syncDir, "sync", "", "push the repo's working tree to this directory on the session once it's up (relative to the session user's home)")
+	c.Flags().BoolVar(&attach, "attach", false, "open an interactive shell on the session once it's up")
+	c.Flags().BoolVarP(&forwardAgent, "forward-agent", "A", false, "with --attach, forward your local SSH agent into the session (like ssh -A)")
+	c.Flags().DurationVar(&waitTimeout, "wait-timeout", 15*time.Minute, "max time to wait for the session to be created or restored and reachable over SSH")
+	return c
+}
+
+// upActionLines are the headline of `rde up`'s human output.
+var upActionLines = map[string]string{
+	internalrde.UpActionCreated:  "Session created",
+	internalrde.UpActionRestored: "Session restored",
+	internalrde.UpActionRunning:  "Session is up",
+}
+
+func renderUpResult(w io.Writer, res internalrde.UpResult) error {
+	s := style.New(w)
+	ew := cmdutil.NewErrWriter(w)
+	ew.F("%s %s\n", s.BuildStatus("success").Render("✓"), upActionLines[res.Action])
+	if err := renderSessionDetail(w, res.Session); err != nil {
+		return err
+	}
+	return ew.Err
+}
+
+// upStatus is the `rde status` / `rde down` result. Session is nil when the
+// repo and branch have no session.
+type upStatus struct {
+	Repo    string               `json:"repo"`
+	Branch  string               `json:"branch"`
+	Session *internalrde.Session `json:"session"`
+	// Action is what `rde down` did: "terminated", "deleted", or "" when
+	// there was nothing to do.
+	Action string `json:"action,omitempty"`
+}
+
+func renderUpStatus(w io.Writer, st upStatus) error {
+	if st.Session == nil {
+		_, err := fmt.Fprintf(w, "No session for %s @ %s.\n", st.Repo, st.Branch)
+		return err
+	}
+	return renderSessionDetail(w, *st.Session)
+}
+
+// NewStatusCmd returns `rde status`.
+func NewStatusCmd() *cobra.Command {
+	var branch string
+	c := &cobra.Command{
+		Use:   "status",
+		Short: "Show the dev session for the current repo and branch",
+		Long: `Show the dev session 'rde up' brings up for the current repo and branch,
+found by its repo and branch labels. Prints "No session" (and, with --output
+json, a null session) when there is none; that is not an error.`,
+		Example: `  bitrise-cli rde status
+  bitrise-cli rde status --branch main --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			st, _, _, err := findUpStatus(cmd, branch)
+			if err != nil {
+				return err
+			}
+			return output.Render(cmd.OutOrStdout(), cmdutil.ResolveFormat(cmd), st, renderUpStatus)
+		},
+	}
+	c.Flags().StringVar(&branch, "branch", "", "branch whose session to show (default: the checked-out branch)")
+	return c
+}
+
+// NewDownCmd returns `rde down`.
+func NewDownCmd() *cobra.Command {
+	var (
+		branch string
+		del    bool
+	)
+	c := &cobra.Command{
+		Use:   "down",
+		Short: "Terminate the dev session for the current repo and branch",
+		Long: `Terminate the dev session 'rde up' brings up for the current repo and
+branch. Its persistent disk is kept, so the next 'rde up' restores it where
+you left off. Pass --delete to remove the session for good instead.
+
+Having no session is not an error.`,
+		Example: `  bitrise-cli rde down
+  bitrise-cli rde down --delete`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			st, svc, workspaceID, err := findUpStatus(cmd, branch)
+			if err != nil {
+				return err
+			}
+			if st.Session != nil {
+				switch {
+				case del:
+					if err := svc.DeleteSession(cmd.Context(), workspaceID, st.Session.ID); err != nil {
+						return err
+					}
+					st.Action = "deleted"
+				case st.Session.Status == "terminated" || st.Session.Status == "terminating" || st.Session.Status == "stopped":
+					// Already down.
+				default:
+					sess, err := svc.TerminateSession(cmd.Context(), workspaceID, st.Session.ID)
+					if err != nil {
+						return err
+					}
+					st.Session, st.Action = &sess, "terminated"
+				}
+			}
+			return output.Render(cmd.OutOrStdout(), cmdutil.ResolveFormat(cmd), st, renderDownResult)
+		},
+	}
+	c.Flags().StringVar(&branch, "branch", "", "branch whose session to take down (default: the checked-out branch)")
+	c.Flags().BoolVar(&del, "delete", false, "permanently delete the session instead of terminating it")
+	return c
+}
+
+func renderDownResult(w io.Writer, st upStatus) error {
+	switch {
+	case st.Session == nil:
+		return renderUpStatus(w, st)
+	case st.Action == "deleted":
+		_, err := fmt.Fprintf(w, "Deleted session %s (%s @ %s).\n", st.Session.ID, st.Repo, st.Branch)
+		return err
+	case st.Action == "":
+		_, err := fmt.Fprintf(w, "Session %s is already %s.\n", st.Session.ID, st.Session.Status)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Terminating session %s (%s @ %s); 'rde up' restores it.\n", st.Session.ID, st.Repo, st.Branch)
+	return err
+}
+
+// findUpStatus looks up the session for the working directory's repo (and
+// branch, or the given one). Returns the Service and workspace used.
+func findUpStatus(cmd *cobra.Command, branch string) (upStatus, *internalrde.Service, string, error) {
+	target, err := resolveUpTarget(cmd.Context(), branch)
+	if err != nil {
+		return upStatus{}, nil, "", err
+	}
+	workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
+	if err != nil {
+		return upStatus{}, nil, "", err
+	}
+	client, err := cmdutil.NewRDEClient(cmd)
+	if err != nil {
+		return upStatus{}, nil, "", err
+	}
+	svc := internalrde.NewService(client)
+	st := upStatus{Repo: target.repoSlug, Branch: target.branch}
+	sess, found, err := svc.FindUpSession(cmd.Context(), workspaceID, target.repoSlug, target.branch)
+	if err != nil {
+		return upStatus{}, nil, "", err
+	}
+	if found {
+		st.Session = &sess
+	}
+	return st, svc, workspaceID, nil
+}
@@ -0,0 +1,135 @@
+package session
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+// gitRepo makes a repo with an origin remote on branch, with dotfile (if
+// any) as .bitrise/rde.yml, and changes into it.
+func gitRepo(t *testing.T, branch, dotfile string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+	dir := filepath.Join(t.TempDir(), "my-app")
+	for _, args := range [][]string{
+		{"init", "-q", dir},
+		{"-C", dir, "remote", "add", "origin", "git@github.com:org/my-app.git"},
+		{"-C", dir, "symbolic-ref", "HEAD", "refs/heads/" + branch},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if dotfile != "" {
+		if err := os.MkdirAll(filepath.Join(dir, ".bitrise"), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".bitrise", "rde.yml"), []byte(dotfile), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+}
+
+func TestUpCmd_CreatesFromRepoConfig(t *testing.T) {
+	gitRepo(t, "feature/login", "session:\n  stack: st\n  machine_type: mt\n")
+	var (
+		mu      sync.Mutex
+		created map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/sessions"):
+			if got := r.URL.Query()["labelSelectors"]; strings.Join(got, ",") != "branch=feature/login,repo=org/my-app" {
+				t.Errorf("label selectors = %v", got)
+			}
+			_, _ = io.WriteString(w, `{"sessions":[]}`)
+		case r.Method == http.MethodPost:
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			_, _ = io.WriteString(w, `{"session":{"id":"s-new","status":"SESSION_STATUS_PENDING"}}`)
+		default:
+			_, _ = io.WriteString(w, `{"session":{"id":"s-new","name":"my-app-feature-login","status":"SESSION_STATUS_RUNNING","sshConnectionOpen":true,"sshAddress":"ssh ubuntu@h -p 22","sshPassword":"pw"}}`)
+		}
+	}))
+	defer srv.Close()
+
+	stdout, stderr, err := run(t, NewUpCmd(), srv.URL, "ws-1", nil, output.Human)
+	if err != nil {
+		t.Fatalf("Execute: %v\n%s", err, stderr)
+	}
+	if created["name"] != "my-app-feature-login" || created["stackId"] != "st" {
+		t.Errorf("create body = %v", created)
+	}
+	if !strings.Contains(stderr, "Creating a session for org/my-app @ feature/login") || !strings.Contains(stdout, "Session created") {
+		t.Errorf("stdout:\n%s\nstderr:\n%s", stdout, stderr)
+	}
+}
+
+func TestUpCmd_NoSessionAndNoConfig(t *testing.T) {
+	gitRepo(t, "main", "")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"sessions":[]}`)
+	}))
+	defer srv.Close()
+	_, _, err := run(t, NewUpCmd(), srv.URL, "ws-1", nil, output.Human)
+	if err == nil || !strings.Contains(err.Error(), "add a session section to .bitrise/rde.yml") {
+		t.Errorf("error = %v, want a hint to add a session section", err)
+	}
+}
+
+func TestStatusCmd_JSONWithoutSession(t *testing.T) {
+	gitRepo(t, "main", "")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"sessions":[]}`)
+	}))
+	defer srv.Close()
+	stdout, _, err := run(t, NewStatusCmd(), srv.URL, "ws-1", nil, output.JSON)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, stdout)
+	}
+	if got["repo"] != "org/my-app" || got["branch"] != "main" || got["session"] != nil {
+		t.Errorf("status = %v", got)
+	}
+}
+
+func TestDownCmd_TerminatesTheSession(t *testing.T) {
+	gitRepo(t, "main", "")
+	var terminated string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			terminated = r.URL.Path
+			_, _ = io.WriteString(w, `{"session":{"id":"s-1","status":"SESSION_STATUS_TERMINATING"}}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"sessions":[{"id":"s-1","status":"SESSION_STATUS_RUNNING"}]}`)
+	}))
+	defer srv.Close()
+	stdout, _, err := run(t, NewDownCmd(), srv.URL, "ws-1", nil, output.Human)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.HasSuffix(terminated, "/sessions/s-1/terminate") {
+		t.Errorf("terminate path = %q", terminated)
+	}
+	if !strings.Contains(stdout, "Terminating session s-1 (org/my-app @ main)") {
+		t.Errorf("stdout = %q", stdout)
+	}
+}
@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -17,20 +18,36 @@ func newUpdateCmd() *cobra.Command {
 		autoTerminateMinutes int
 		labels               []string
 		unsetLabels          []string
+		bulk                 bulkFlags
 	)
 	c := &cobra.Command{
-		Use:   "update SESSION_ID",
+		Use:   "update [SESSION_ID]",
 		Short: "Update a session's name, description, auto-terminate duration, or labels",
 		Long: `Update a session's name, description, auto-terminate duration, or labels.
 
 Labels change incrementally: --label key=value upserts one label (an existing
 key is overwritten, other keys are left untouched) and --unset-label key
 removes one; both are repeatable. Removing a key the session doesn't have is
-a no-op.`,
-		Args: cmdutil.RequireArgs("SESSION_ID"),
+a no-op.
+
+Instead of one SESSION_ID, --label-selector (-l), --status and --older-than
+update every session matching all of them. As on list, terminate and
+delete, -l selects sessions; --label has no shorthand. The matching
+sessions are listed first and, on a terminal, you're asked to confirm;
+elsewhere pass --yes. --dry-run only lists them. --name can't be applied to
+more than one session.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			// -l used to set a label here; say so rather than a bare
+			// SESSION_ID conflict.
+			if len(args) > 0 && cmd.Flags().Changed("label-selector") {
+				return fmt.Errorf("SESSION_ID can't be combined with --label-selector (-l); set labels with --label")
+			}
+			return bulk.args(cmd, args)
+		},
 		Example: `  bitrise-cli rde session update SESSION_ID --name new-name
   bitrise-cli rde session update SESSION_ID --auto-terminate-minutes 0
-  bitrise-cli rde session update SESSION_ID --label branch=main --unset-label wip`,
+  bitrise-cli rde session update SESSION_ID --label branch=main --unset-label wip
+  bitrise-cli rde session update -l branch=main --auto-terminate-minutes 120 --yes`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
 			if err != nil {
@@ -69,6 +86,14 @@ a no-op.`,
 			if req.Name == nil && req.Description == nil && req.AutoTerminateMinutes == nil && len(req.Labels) == 0 && len(req.RemoveLabels) == 0 {
 				return fmt.Errorf("at least one of --name, --description, --auto-terminate-minutes, --label, --unset-label is required")
 			}
+			if bulk.active(cmd) {
+				if req.Name != nil {
+					return fmt.Errorf("--name can't be combined with --label-selector, --status or --older-than")
+				}
+				return runBulk(cmd, workspaceID, &bulk, "update", func(ctx context.Context, svc *internalrde.Service, workspaceID string, sess internalrde.Session) (internalrde.Session, error) {
+					return svc.UpdateSession(ctx, workspaceID, sess.ID, req)
+				})
+			}
 			format := cmdutil.ResolveFormat(cmd)
 			client, err := cmdutil.NewRDEClient(cmd)
 			if err != nil {
@@ -89,7 +114,8 @@ a no-op.`,
 	c.Flags().StringVar(&name, "name", "", "new session name")
 	c.Flags().StringVar(&description, "description", "", "new session description")
 	c.Flags().IntVar(&autoTerminateMinutes, "auto-terminate-minutes", 0, "auto-terminate duration in minutes; 0 disables. Resets the deadline to now + minutes.")
-	c.Flags().StringArrayVarP(&labels, "label", "l", nil, "label to set on the session as key=value (repeatable; merged into the existing labels)")
+	c.Flags().StringArrayVar(&labels, "label", nil, "label to set on the session as key=value (repeatable; merged into the existing labels)")
 	c.Flags().StringArrayVar(&unsetLabels, "unset-label", nil, "label key to remove from the session (repeatable; unknown keys are ignored)")
+	bulk.register(c)
 	return c
 }
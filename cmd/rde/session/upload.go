@@ -19,7 +19,17 @@ The local path is tarred + gzipped, uploaded to cloud storage via a signed
 URL, then extracted on the session VM at REMOTE_FOLDER.
 
 For directories: the directory's contents are extracted into REMOTE_FOLDER
-(not the directory itself).`,
+(not the directory itself). Symlinks are kept as links, and permission bits
+and modification times are preserved.
+
+The archive is streamed to a file under the CLI's config directory
+(~/.config/bitrise/cache/transfer, or under $XDG_CONFIG_HOME) and from there
+to storage, so memory use stays flat however large the upload is. That disk
+needs free space for the archive — up to LOCAL_PATH's size — which is
+checked before archiving starts. A dropped connection is
+retried — resumed from the last stored byte when storage offers a resumable
+session — and the stored object's checksum is verified when storage reports
+one. Progress (bytes, rate, time left) is shown on stderr; --quiet hides it.`,
 		Example: `  bitrise-cli rde session upload SESSION_ID ./project /Users/vagrant/project
   bitrise-cli rde session upload SESSION_ID ./build.tar.gz /Users/vagrant/artifacts`,
 		Args: cmdutil.RequireArgs("SESSION_ID", "LOCAL_PATH", "REMOTE_FOLDER"),
@@ -38,10 +48,12 @@ For directories: the directory's contents are extracted into REMOTE_FOLDER
 			if err != nil {
 				return err
 			}
+			var opts internalrde.TransferOptions
 			if !cmdutil.IsQuiet(cmd) {
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Uploading %s → %s on session %s…\n", sourcePath, destFolder, sessionID)
+				opts.Progress = transferProgress(cmd.ErrOrStderr())
 			}
-			if err := svc.UploadFile(cmd.Context(), workspaceID, sessionID, sourcePath, destFolder); err != nil {
+			if err := svc.UploadFile(cmd.Context(), workspaceID, sessionID, sourcePath, destFolder, opts); err != nil {
 				return err
 			}
 			if !cmdutil.IsQuiet(cmd) {
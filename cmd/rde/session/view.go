@@ -42,8 +42,8 @@ stream); use 'session create --wait' or a polling jq loop instead.`,
 			}
 			// Reject the unsupported flag combo before resolving the name —
 			// no point in a lookup round-trip for a request we won't serve.
-			if watch && format == output.JSON {
-				return fmt.Errorf("--watch cannot be combined with --output json (JSON is a single-object contract)")
+			if watch && format.Structured() {
+				return fmt.Errorf("--watch cannot be combined with --output %s (structured output is a single-object contract)", format)
 			}
 
 			svc := internalrde.NewService(client)
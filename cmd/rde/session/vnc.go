@@ -66,8 +66,8 @@ when you just want to launch your viewer against a directly-reachable endpoint.`
 			// (mirrors `view --watch`). --forward runs a long-lived tunnel, not
 			// a single-object result, so it can't satisfy the JSON contract.
 			forwarding := cmd.Flags().Changed("forward")
-			if forwarding && format == output.JSON {
-				return fmt.Errorf("--forward cannot be combined with --output json (it runs a long-lived tunnel, not a single-object result)")
+			if forwarding && format.Structured() {
+				return fmt.Errorf("--forward cannot be combined with --output %s (it runs a long-lived tunnel, not a single-object result)", format)
 			}
 
 			svc := internalrde.NewService(client)
@@ -0,0 +1,312 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+func newWatchCmd() *cobra.Command {
+	var (
+		selectors []string
+		since     string
+		interval  time.Duration
+		notify    bool
+	)
+	c := &cobra.Command{
+		Use:   "watch [SESSION_ID...]",
+		Short: "Follow sessions' status, agent status, SSH readiness and notifications live",
+		Long: `Follow sessions live: status, agent status, SSH readiness and new
+notifications as they arrive. Without a SESSION_ID every session in the
+workspace is watched (narrow it with --label-selector), including ones
+created while watching. Watching named sessions ends once all of them are
+deleted.
+
+Sessions are polled every --interval; notifications are fetched
+incrementally, only those created after the newest one each session already
+had when the watch started. Pass --since to replay notifications from an
+earlier point; replayed ones carry "replayed": true in JSON.
+
+On a terminal the sessions are shown as a live table with the latest events
+below it; an agent status that reads as waiting on you (a permission prompt,
+a question) is highlighted. Elsewhere one line is printed per event. With
+--output json one JSON event is printed per line (NDJSON): "session" when a
+session is first seen, then "status", "agent_status", "ssh",
+"notification", "gone" and "error". --jq and --template run once per event.
+
+--notify also raises a desktop notification for every change and new
+notification, but not for ones replayed by --since (osascript on macOS, a
+toast on Windows, notify-send elsewhere). A notifier that hangs is given up
+on after a few seconds without holding up the watch.
+
+Stop with Ctrl-C.`,
+		Example: `  bitrise-cli rde session watch SESSION_ID
+  bitrise-cli rde session watch -l team=mobile --notify
+  bitrise-cli rde session watch SESSION_ID --output json | jq -c 'select(.type == "agent_status")'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := cmdutil.ResolveFormat(cmd)
+			if format.Structured() && format != output.JSON {
+				return fmt.Errorf("session watch supports --output json only (it streams events, not a single document)")
+			}
+			if len(args) > 0 && len(selectors) > 0 {
+				return fmt.Errorf("SESSION_ID can't be combined with --label-selector")
+			}
+			if err := validateLabelSelectors(selectors); err != nil {
+				return err
+			}
+			opts := internalrde.WatchOptions{LabelSelectors: selectors, Interval: interval}
+			if since != "" {
+				t, err := time.Parse(time.RFC3339, since)
+				if err != nil {
+					return fmt.Errorf("--since: %w", err)
+				}
+				opts.Since = t
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := cmdutil.NewRDEClient(cmd)
+			if err != nil {
+				return err
+			}
+			svc := internalrde.NewService(client)
+			for _, arg := range args {
+				id, err := svc.ResolveSessionID(cmd.Context(), workspaceID, arg)
+				if err != nil {
+					return err
+				}
+				opts.SessionIDs = append(opts.SessionIDs, id)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			notifier := &desktopNotifier{enabled: notify, notify: cmdutil.DesktopNotify}
+
+			if format == output.Human && !output.Filtering() && cmdutil.WriterIsTTY(cmd.OutOrStdout()) {
+				return runSessionWatchTUI(ctx, cmd, svc, workspaceID, opts, notifier)
+			}
+
+			ew := cmdutil.NewErrWriter(cmd.ErrOrStderr())
+			notifier.warn = func(msg string) { ew.F("%s\n", msg) }
+			var emit func(internalrde.WatchEvent)
+			if output.Streaming(format) {
+				emit = func(ev internalrde.WatchEvent) {
+					if err := output.Emit(cmd.OutOrStdout(), ev); err != nil && ew.Err == nil {
+						ew.Err = err
+					}
+				}
+			} else {
+				emit = lineWatchEmitter(cmd.OutOrStdout(), ew)
+				if !cmdutil.IsQuiet(cmd) {
+					ew.F("Watching sessions every %s (Ctrl-C to stop)…\n", interval)
+				}
+			}
+			err = svc.Watch(ctx, workspaceID, opts, notifier.wrap(ctx, emit))
+			notifier.wait()
+			if err != nil {
+				return err
+			}
+			return ew.Err
+		},
+	}
+	c.Flags().StringArrayVarP(&selectors, "label-selector", "l", nil, "without SESSION_ID: only sessions whose labels match key=value exactly (repeatable; all must match)")
+	c.Flags().StringVar(&since, "since", "", "also show notifications created after this RFC3339 timestamp (default: only new ones)")
+	c.Flags().DurationVar(&interval, "interval", 5*time.Second, "how often to poll (Go duration syntax: 5s, 1m)")
+	c.Flags().BoolVar(&notify, "notify", false, "raise a desktop notification for each event")
+	return c
+}
+
+// lineWatchEmitter prints one human line per event: events to out, failed
+// polls to ew.
+func lineWatchEmitter(out io.Writer, ew *cmdutil.ErrWriter) func(internalrde.WatchEvent) {
+	s := style.New(out)
+	return func(ev internalrde.WatchEvent) {
+		line := describeWatchEvent(ev)
+		if ev.Type == internalrde.WatchEventError {
+			ew.F("%s %s\n", ev.Time.Local().Format(time.TimeOnly), line)
+			return
+		}
+		if watchEventNeedsUser(ev) {
+			line = s.Warn.Render(line)
+		}
+		_, _ = fmt.Fprintf(out, "%s  %s  %s\n", s.Dim.Render(ev.Time.Local().Format(time.TimeOnly)), watchEventSubject(ev), line)
+	}
+}
+
+// watchEventSubject names the session an event is about.
+func watchEventSubject(ev internalrde.WatchEvent) string {
+	if ev.SessionName != "" {
+		return ev.SessionName
+	}
+	return ev.SessionID
+}
+
+// describeWatchEvent is the one-line human description of an event, without
+// the session name or time.
+func describeWatchEvent(ev internalrde.WatchEvent) string {
+	switch ev.Type {
+	case internalrde.WatchEventSession:
+		parts := []string{"status " + orDash(ev.Session.Status)}
+		if ev.Session.AgentSessionStatus != "" {
+			parts = append(parts, "agent "+ev.Session.AgentSessionStatus)
+		}
+		if internalrde.SessionSSHReady(*ev.Session) {
+			parts = append(parts, "ssh ready")
+		}
+		return "watching (" + strings.Join(parts, ", ") + ")"
+	case internalrde.WatchEventStatus:
+		return fmt.Sprintf("status %s → %s", orDash(ev.From), orDash(ev.To))
+	case internalrde.WatchEventAgentStatus:
+		line := fmt.Sprintf("agent %s → %s", orDash(ev.From), orDash(ev.To))
+		if internalrde.AgentAwaitingUser(ev.To) {
+			line += " — waiting on you"
+		}
+		return line
+	case internalrde.WatchEventSSH:
+		return "ssh " + ev.To
+	case internalrde.WatchEventNotification:
+		return "notification: " + notificationText(*ev.Notification)
+	case internalrde.WatchEventGone:
+		return "session no longer exists"
+	case internalrde.WatchEventError:
+		return "poll failed: " + ev.Error + " (retrying)"
+	}
+	return ev.Type
+}
+
+// watchEventNeedsUser reports whether an event means someone should look
+// at the session now.
+func watchEventNeedsUser(ev internalrde.WatchEvent) bool {
+	switch ev.Type {
+	case internalrde.WatchEventAgentStatus:
+		return internalrde.AgentAwaitingUser(ev.To)
+	case internalrde.WatchEventNotification:
+		return true
+	case internalrde.WatchEventStatus:
+		return ev.To == "failed"
+	}
+	return false
+}
+
+func notificationText(n internalrde.SessionNotification) string {
+	parts := make([]string, 0, 3)
+	if n.Type != "" {
+		parts = append(parts, "["+n.Type+"]")
+	}
+	if n.Title != "" {
+		parts = append(parts, n.Title)
+	}
+	if n.Body != "" {
+		parts = append(parts, n.Body)
+	}
+	return strings.Join(parts, " ")
+}
+
+func orDash(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
+
+// desktopNotifyTimeout bounds one OS notification; a stuck notify-send or
+// osascript is killed rather than left to pile up.
+const desktopNotifyTimeout = 5 * time.Second
+
+// desktopNotifier raises an OS notification for watch events. "session"
+// events only describe a session's starting state and are skipped, as are
+// notifications replayed by --since. Notifications are sent in the
+// background so a slow notifier never delays the watch. When the OS
+// notifier fails it warns once, on the next event, and stays quiet
+// afterwards.
+type desktopNotifier struct {
+	enabled bool
+	notify  func(ctx context.Context, title, body string) error
+	warn    func(msg string)
+	failed  bool
+	// errs carries the first failure back from the sending goroutines.
+	errs chan error
+	wg   sync.WaitGroup
+}
+
+// wrap returns emit preceded by a desktop notification when enabled.
+func (n *desktopNotifier) wrap(ctx context.Context, emit func(internalrde.WatchEvent)) func(internalrde.WatchEvent) {
+	if !n.enabled {
+		return emit
+	}
+	return func(ev internalrde.WatchEvent) {
+		n.event(ctx, ev)
+		emit(ev)
+	}
+}
+
+func (n *desktopNotifier) event(ctx context.Context, ev internalrde.WatchEvent) {
+	if !n.enabled || n.disabled() {
+		return
+	}
+	switch ev.Type {
+	case internalrde.WatchEventSession, internalrde.WatchEventError:
+		return
+	}
+	if ev.Replayed {
+		return
+	}
+	title := "RDE session " + watchEventSubject(ev)
+	if watchEventNeedsUser(ev) {
+		title += " needs you"
+	}
+	body := describeWatchEvent(ev)
+	if n.errs == nil {
+		n.errs = make(chan error, 1)
+	}
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		nctx, cancel := context.WithTimeout(ctx, desktopNotifyTimeout)
+		defer cancel()
+		if err := n.notify(nctx, title, body); err != nil && ctx.Err() == nil {
+			select {
+			case n.errs <- err:
+			default: // a failure is already pending
+			}
+		}
+	}()
+}
+
+// disabled reports whether a notification has failed, warning the first
+// time it learns so. It runs on the emitting goroutine, so warn never races
+// the watch's own output.
+func (n *desktopNotifier) disabled() bool {
+	if n.failed {
+		return true
+	}
+	select {
+	case err := <-n.errs:
+		n.failed = true
+		if n.warn != nil {
+			n.warn(fmt.Sprintf("Desktop notifications disabled: %v", err))
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// wait blocks until the notifications already started are done.
+func (n *desktopNotifier) wait() { n.wg.Wait() }
@@ -0,0 +1,223 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+// newWatchServer serves uuidSession running with its agent idle, then
+// waiting on a permission prompt with a notification, then deleted — so a
+// watch of it ends on its own.
+func newWatchServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var (
+		mu   sync.Mutex
+		gets int
+	)
+	created := time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/workspaces/ws-1/sessions/" + uuidSession:
+			gets++
+			switch gets {
+			case 1:
+				_, _ = io.WriteString(w, `{"session":{"id":"`+uuidSession+`","name":"dev","status":"SESSION_STATUS_RUNNING","agentSessionStatus":"AGENT_SESSION_STATUS_IDLE"}}`)
+			case 2:
+				_, _ = io.WriteString(w, `{"session":{"id":"`+uuidSession+`","name":"dev","status":"SESSION_STATUS_RUNNING","agentSessionStatus":"AGENT_SESSION_STATUS_WAITING_FOR_PERMISSION"}}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, `{"message":"not found"}`)
+			}
+		case "/v1/workspaces/ws-1/sessions/" + uuidSession + "/notifications":
+			if gets < 2 {
+				_, _ = io.WriteString(w, `{"notifications":[]}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"notifications":[{"id":"n1","title":"Allow Bash?","createdAt":"`+created+`"}]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWatchCmd_NDJSONStream(t *testing.T) {
+	srv := newWatchServer(t)
+
+	stdout, _, err := run(t, newWatchCmd(), srv.URL, "ws-1", []string{uuidSession, "--interval", "10ms"}, output.JSON)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		var ev struct {
+			Type string `json:"type"`
+			To   string `json:"to"`
+		}
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		types = append(types, ev.Type+">"+ev.To)
+	}
+	want := "session> agent_status>waiting_for_permission notification> gone>"
+	if strings.Join(types, " ") != want {
+		t.Errorf("events = %v, want %s", types, want)
+	}
+}
+
+func TestWatchCmd_JQRunsPerEvent(t *testing.T) {
+	srv := newWatchServer(t)
+	if err := output.Configure(output.Options{JQ: `select(.type == "agent_status") | .to`}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = output.Configure(output.Options{}) })
+
+	stdout, _, err := run(t, newWatchCmd(), srv.URL, "ws-1", []string{uuidSession, "--interval", "10ms"}, output.Human)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if stdout != "waiting_for_permission\n" {
+		t.Errorf("stdout = %q, want only the filtered agent status", stdout)
+	}
+}
+
+func TestWatchCmd_HumanLines(t *testing.T) {
+	srv := newWatchServer(t)
+
+	stdout, stderr, err := run(t, newWatchCmd(), srv.URL, "ws-1", []string{uuidSession, "--interval", "10ms"}, output.Human)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	for _, want := range []string{
+		"dev  watching (status running, agent idle)",
+		"dev  agent idle → waiting_for_permission — waiting on you",
+		"dev  notification: Allow Bash?",
+		"dev  session no longer exists",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout missing %q:\n%s", want, stdout)
+		}
+	}
+	if !strings.Contains(stderr, "Watching sessions every 10ms") {
+		t.Errorf("stderr missing the watch banner:\n%s", stderr)
+	}
+}
+
+func TestWatchCmd_RejectsYAMLAndMixedTargets(t *testing.T) {
+	if _, _, err := run(t, newWatchCmd(), "http://unused", "ws-1", nil, output.YAML); err == nil {
+		t.Error("expected --output yaml to be rejected")
+	}
+	if _, _, err := run(t, newWatchCmd(), "http://unused", "ws-1", []string{uuidSession, "-l", "team=mobile"}, output.Human); err == nil {
+		t.Error("expected SESSION_ID with --label-selector to be rejected")
+	}
+}
+
+func TestDesktopNotifier_SkipsStartingStateAndDisablesOnFailure(t *testing.T) {
+	var (
+		titles []string
+		warns  []string
+	)
+	fail := false
+	n := &desktopNotifier{
+		enabled: true,
+		notify: func(ctx context.Context, title, _ string) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("notify should run with a timeout")
+			}
+			titles = append(titles, title)
+			if fail {
+				return errors.New("notify-send: not found")
+			}
+			return nil
+		},
+		warn: func(msg string) { warns = append(warns, msg) },
+	}
+	ctx := context.Background()
+	sess := internalrde.Session{ID: "s1", Name: "dev"}
+	n.event(ctx, internalrde.WatchEvent{Type: internalrde.WatchEventSession, SessionName: "dev", Session: &sess})
+	n.event(ctx, internalrde.WatchEvent{Type: internalrde.WatchEventNotification, SessionName: "dev", Replayed: true, Notification: &internalrde.SessionNotification{Title: "old"}})
+	n.event(ctx, internalrde.WatchEvent{Type: internalrde.WatchEventAgentStatus, SessionName: "dev", From: "working", To: "waiting_for_permission"})
+	n.wait()
+	if len(titles) != 1 || titles[0] != "RDE session dev needs you" {
+		t.Fatalf("titles = %v, want one attention notification", titles)
+	}
+
+	fail = true
+	n.event(ctx, internalrde.WatchEvent{Type: internalrde.WatchEventStatus, SessionName: "dev", From: "running", To: "terminating"})
+	n.wait()
+	n.event(ctx, internalrde.WatchEvent{Type: internalrde.WatchEventStatus, SessionName: "dev", From: "terminating", To: "terminated"})
+	n.wait()
+	if len(titles) != 2 {
+		t.Errorf("notifier should stop after a failure, got %d calls", len(titles))
+	}
+	if len(warns) != 1 || !strings.Contains(warns[0], "not found") {
+		t.Errorf("warns = %v, want one warning", warns)
+	}
+}
+
+func TestDesktopNotifier_DoesNotBlockTheWatch(t *testing.T) {
+	release := make(chan struct{})
+	n := &desktopNotifier{
+		enabled: true,
+		notify: func(context.Context, string, string) error {
+			<-release
+			return nil
+		},
+	}
+	done := make(chan struct{})
+	go func() {
+		n.event(context.Background(), internalrde.WatchEvent{Type: internalrde.WatchEventStatus, SessionName: "dev", From: "running", To: "failed"})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event blocked on a hanging notifier")
+	}
+	close(release)
+	n.wait()
+}
+
+func TestSessionWatchModel_TableTracksEvents(t *testing.T) {
+	m := newSessionWatchModel(style.New(io.Discard))
+	now := time.Now()
+	sess := internalrde.Session{ID: "s1", Name: "dev", Status: "starting"}
+	for _, ev := range []internalrde.WatchEvent{
+		{Type: internalrde.WatchEventSession, Time: now, SessionID: "s1", SessionName: "dev", Session: &sess},
+		{Type: internalrde.WatchEventStatus, Time: now, SessionID: "s1", SessionName: "dev", From: "starting", To: "running"},
+		{Type: internalrde.WatchEventSSH, Time: now, SessionID: "s1", SessionName: "dev", From: "unavailable", To: "ready"},
+		{Type: internalrde.WatchEventAgentStatus, Time: now, SessionID: "s1", SessionName: "dev", To: "waiting_for_permission"},
+		{Type: internalrde.WatchEventNotification, Time: now, SessionID: "s1", SessionName: "dev", Notification: &internalrde.SessionNotification{Title: "Allow Bash?"}},
+	} {
+		next, _ := m.Update(watchEventMsg(ev))
+		m = next.(sessionWatchModel)
+	}
+
+	view := m.View()
+	for _, want := range []string{"running", "waiting_for_permission", "ready", "Allow Bash?", "status starting → running"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "watching (") {
+		t.Errorf("the starting state belongs in the table, not the event list:\n%s", view)
+	}
+	if len(m.recent) != 4 {
+		t.Errorf("recent = %d events, want 4", len(m.recent))
+	}
+}
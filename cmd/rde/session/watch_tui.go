@@ -0,0 +1,211 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+// watchRecentEvents is how many of the latest events the TUI keeps below
+// the table.
+const watchRecentEvents = 8
+
+// runSessionWatchTUI is the interactive variant of `session watch`: a live
+// table of the watched sessions with the latest events below it. q or
+// Ctrl-C quits.
+func runSessionWatchTUI(ctx context.Context, cmd *cobra.Command, svc *internalrde.Service, workspaceID string, opts internalrde.WatchOptions, notifier *desktopNotifier) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p := tea.NewProgram(newSessionWatchModel(style.New(cmd.OutOrStdout())), tea.WithContext(ctx), tea.WithOutput(cmd.OutOrStdout()))
+	notifier.warn = func(msg string) { p.Send(watchWarnMsg(msg)) }
+
+	errCh := make(chan error, 1)
+	go func() {
+		err := svc.Watch(ctx, workspaceID, opts, notifier.wrap(ctx, func(ev internalrde.WatchEvent) { p.Send(watchEventMsg(ev)) }))
+		p.Send(watchEndMsg{err: err})
+		errCh <- err
+	}()
+
+	_, runErr := p.Run()
+	cancel()
+	err := <-errCh
+	notifier.wait()
+	if err != nil {
+		return err
+	}
+	if runErr != nil && ctx.Err() == nil {
+		return fmt.Errorf("render watch UI: %w", runErr)
+	}
+	return nil
+}
+
+type watchEventMsg internalrde.WatchEvent
+
+type watchWarnMsg string
+
+type watchEndMsg struct{ err error }
+
+// watchRow is the latest known state of one session in the TUI table.
+type watchRow struct {
+	id, name, status, agent string
+	sshReady                bool
+	gone                    bool
+	lastNote                string
+}
+
+type sessionWatchModel struct {
+	s      style.Styles
+	rows   map[string]*watchRow
+	recent []string
+	width  int
+}
+
+func newSessionWatchModel(s style.Styles) sessionWatchModel {
+	return sessionWatchModel{s: s, rows: make(map[string]*watchRow), width: 80}
+}
+
+func (m sessionWatchModel) Init() tea.Cmd { return nil }
+
+func (m sessionWatchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC || msg.String() == "q" {
+			return m, tea.Quit
+		}
+	case watchEventMsg:
+		m.apply(internalrde.WatchEvent(msg))
+	case watchWarnMsg:
+		m.remember(m.s.Warn.Render(string(msg)))
+	case watchEndMsg:
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// apply folds an event into the table and the recent-events list.
+func (m *sessionWatchModel) apply(ev internalrde.WatchEvent) {
+	row, ok := m.rows[ev.SessionID]
+	if !ok && ev.SessionID != "" {
+		row = &watchRow{id: ev.SessionID, name: ev.SessionName}
+		m.rows[ev.SessionID] = row
+	}
+	switch ev.Type {
+	case internalrde.WatchEventSession:
+		row.status = ev.Session.Status
+		row.agent = ev.Session.AgentSessionStatus
+		row.sshReady = internalrde.SessionSSHReady(*ev.Session)
+		// The starting state is in the table already; only changes go to
+		// the event list.
+		return
+	case internalrde.WatchEventStatus:
+		row.status = ev.To
+	case internalrde.WatchEventAgentStatus:
+		row.agent = ev.To
+	case internalrde.WatchEventSSH:
+		row.sshReady = ev.To == internalrde.SSHReady
+	case internalrde.WatchEventNotification:
+		row.lastNote = notificationText(*ev.Notification)
+	case internalrde.WatchEventGone:
+		row.gone = true
+	}
+	line := describeWatchEvent(ev)
+	if watchEventNeedsUser(ev) {
+		line = m.s.Warn.Render(line)
+	}
+	subject := ""
+	if ev.SessionID != "" {
+		subject = watchEventSubject(ev) + "  "
+	}
+	m.remember(m.s.Dim.Render(ev.Time.Local().Format(time.TimeOnly)) + "  " + subject + line)
+}
+
+func (m *sessionWatchModel) remember(line string) {
+	m.recent = append(m.recent, line)
+	if len(m.recent) > watchRecentEvents {
+		m.recent = m.recent[len(m.recent)-watchRecentEvents:]
+	}
+}
+
+func (m sessionWatchModel) View() string {
+	var b bytes.Buffer
+	if len(m.rows) == 0 {
+		b.WriteString(m.s.Dim.Render("Waiting for sessions…") + "\n")
+	} else {
+		rows := make([]*watchRow, 0, len(m.rows))
+		for _, r := range m.rows {
+			rows = append(rows, r)
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if rows[i].name != rows[j].name {
+				return rows[i].name < rows[j].name
+			}
+			return rows[i].id < rows[j].id
+		})
+		headers := []string{"NAME", "STATUS", "AGENT", "SSH", "LAST NOTIFICATION", "ID"}
+		cells := make([][]string, 0, len(rows))
+		for _, r := range rows {
+			status, ssh := r.status, "-"
+			if r.gone {
+				status = "gone"
+			}
+			if r.sshReady {
+				ssh = "ready"
+			}
+			cells = append(cells, []string{r.name, orDash(status), orDash(r.agent), ssh, truncateCell(r.lastNote, m.width/3), r.id})
+		}
+		const (
+			colStatus = 1
+			colAgent  = 2
+			colSSH    = 3
+			colID     = 5
+		)
+		styler := func(row, col int, content string) string {
+			switch col {
+			case colStatus:
+				return statusStyle(m.s, rows[row].status).Render(content)
+			case colAgent:
+				if internalrde.AgentAwaitingUser(rows[row].agent) {
+					return m.s.Warn.Render(content)
+				}
+			case colSSH:
+				if rows[row].sshReady {
+					return m.s.Success.Render(content)
+				}
+				return m.s.Dim.Render(content)
+			case colID:
+				return m.s.Slug.Render(content)
+			}
+			return content
+		}
+		_ = style.Table(&b, headers, cells, m.s.Header, styler)
+	}
+	if len(m.recent) > 0 {
+		b.WriteString("\n" + strings.Join(m.recent, "\n") + "\n")
+	}
+	b.WriteString("\n" + m.s.Dim.Render("q to quit"))
+	return b.String()
+}
+
+// truncateCell shortens v to at most n runes, marking the cut with "…".
+func truncateCell(v string, n int) string {
+	if n < 8 {
+		n = 8
+	}
+	r := []rune(v)
+	if len(r) <= n {
+		return v
+	}
+	return string(r[:n-1]) + "…"
+}
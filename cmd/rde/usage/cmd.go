@@ -0,0 +1,242 @@
+// Package usage wires `bitrise-cli rde usage`.
+package usage
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalrde "github.com/bitrise-io/bitrise-cli/internal/rde"
+)
+
+// NewCmd returns the `rde usage` command.
+func NewCmd() *cobra.Command {
+	var (
+		since     string
+		until     string
+		userLabel string
+		rateFlags []string
+		idleAfter time.Duration
+	)
+	c := &cobra.Command{
+		Use:   "usage",
+		Short: "Report estimated session runtime and credits per user, template, stack and machine type",
+		Long: `Report estimated session runtime in the workspace, aggregated per user,
+template, stack and machine type, and flag running sessions that look idle.
+
+The API keeps no runtime history, so runtimes are estimated: a session runs
+from its creation until now, or until its last update once terminated,
+stopped or failed. The time between a terminate and a restore is left out
+when this CLI saw both: rde session terminate, restore and watch log status
+changes to a file on this machine, so terminates and restores done elsewhere
+(another machine, the web UI, a teammate's CLI) aren't seen, and the session
+counts as running in between. Runtimes are clipped to the --since/--until
+window.
+
+Only sessions that still exist are reported: the API doesn't list deleted
+sessions, so their runtime drops out of the report, even inside the window.
+Treat the numbers as an estimate rather than an invoice.
+
+Sessions have no owner field, so per-user grouping reads the label named by
+--user-label (default "user"); sessions without it group as "(unknown)".
+
+The machine catalog carries no pricing. Pass each machine type's rate with
+--rate NAME=CREDITS_PER_MINUTE to get credit estimates; machine types
+without a rate are listed so their runtime isn't silently dropped.
+
+A running session is flagged idle when its creation, its last agent status
+change and its newest notification are all older than --idle-after.`,
+		Example: `  bitrise-cli rde usage --since 30d
+  bitrise-cli rde usage --since 2026-09-01 --until 2026-10-01 --rate g2.mac.large=0.4 --rate g2.mac.medium=0.2
+  bitrise-cli rde usage --user-label owner --output json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			now := time.Now()
+			opts := internalrde.UsageOptions{Now: now, UserLabel: userLabel, IdleAfter: idleAfter}
+			var err error
+			if opts.Since, err = parseWindowTime(since, now); err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			if until != "" {
+				if opts.Until, err = parseWindowTime(until, now); err != nil {
+					return fmt.Errorf("--until: %w", err)
+				}
+				if !opts.Until.After(opts.Since) {
+					return fmt.Errorf("--until must be after --since")
+				}
+			}
+			if opts.Rates, err = parseRates(rateFlags); err != nil {
+				return err
+			}
+
+			workspaceID, err := cmdutil.ResolveWorkspaceID(cmd)
+			if err != nil {
+				return err
+			}
+			format := cmdutil.ResolveFormat(cmd)
+			client, err := cmdutil.NewRDEClient(cmd)
+			if err != nil {
+				return err
+			}
+			rep, err := internalrde.NewService(client).Usage(cmd.Context(), workspaceID, opts)
+			if err != nil {
+				return err
+			}
+			if len(rep.UnpricedMachineTypes) > 0 && !cmdutil.IsQuiet(cmd) && !format.Structured() {
+				s := style.New(cmd.ErrOrStderr())
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), s.Warn.Render("!")+" no --rate for "+strings.Join(rep.UnpricedMachineTypes, ", ")+"; their runtime is not included in the credit estimate")
+			}
+			return output.Render(cmd.OutOrStdout(), format, rep, renderReport)
+		},
+	}
+	c.Flags().StringVar(&since, "since", "30d", "start of the reporting window: a date (2026-09-01), an RFC3339 timestamp, or an age such as 30d or 12h")
+	c.Flags().StringVar(&until, "until", "", "end of the reporting window, in the same formats as --since (default now)")
+	c.Flags().StringVar(&userLabel, "user-label", "user", "session label naming who a session belongs to")
+	c.Flags().StringArrayVar(&rateFlags, "rate", nil, "credits per minute for a machine type, NAME=CREDITS (repeatable)")
+	c.Flags().DurationVar(&idleAfter, "idle-after", 2*time.Hour, "flag running sessions without agent activity or notifications for this long (0 disables)")
+	return c
+}
+
+// parseWindowTime accepts a date, an RFC3339 timestamp, a Go duration or a
+// number of days ("30d"); durations count back from now.
+func parseWindowTime(v string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: want a date (2006-01-02), an RFC3339 timestamp, or an age such as 30d or 12h", v)
+}
+
+func parseRates(flags []string) (map[string]float64, error) {
+	rates := make(map[string]float64, len(flags))
+	for _, f := range flags {
+		name, raw, ok := strings.Cut(f, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("--rate %q: want NAME=CREDITS_PER_MINUTE", f)
+		}
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("--rate %q: credits must be a non-negative number", f)
+		}
+		rates[name] = rate
+	}
+	return rates, nil
+}
+
+func renderReport(w io.Writer, rep internalrde.UsageReport) error {
+	s := style.New(w)
+	ew := cmdutil.NewErrWriter(w)
+	ew.F("Session usage %s → %s\n", formatTime(rep.Since), formatTime(rep.Until))
+	ew.F("%s\n", s.Dim.Render("Estimated: time terminated counts as running unless this machine saw the terminate and the restore."))
+	total := fmt.Sprintf("%d sessions · %s h", rep.Sessions, hours(rep.Minutes))
+	if rep.Credits != nil {
+		total += " · " + credits(*rep.Credits) + " credits"
+	}
+	ew.F("%s %s\n", s.Label.Render("Total:"), total)
+	if ew.Err != nil {
+		return ew.Err
+	}
+
+	priced := rep.Credits != nil
+	for _, sec := range []struct {
+		title, column string
+		groups        []internalrde.UsageGroup
+	}{
+		{"By user", "USER", rep.ByUser},
+		{"By template", "TEMPLATE", rep.ByTemplate},
+		{"By stack", "STACK", rep.ByStack},
+		{"By machine type", "MACHINE TYPE", rep.ByMachineType},
+	} {
+		if len(sec.groups) == 0 {
+			continue
+		}
+		ew.F("\n%s\n", s.Dim.Render(sec.title))
+		if ew.Err != nil {
+			return ew.Err
+		}
+		if err := renderGroups(w, s, sec.column, sec.groups, priced); err != nil {
+			return err
+		}
+	}
+
+	if len(rep.Idle) > 0 {
+		ew.F("\n%s\n", s.Warn.Render(fmt.Sprintf("Idle running sessions (%d)", len(rep.Idle))))
+		if ew.Err != nil {
+			return ew.Err
+		}
+		headers := []string{"ID", "NAME", "USER", "MACHINE TYPE", "AGENT", "IDLE FOR", "AUTO-TERMINATE"}
+		rows := make([][]string, 0, len(rep.Idle))
+		for _, idle := range rep.Idle {
+			autoTerminate := "never"
+			if idle.AutoTerminateAt != nil {
+				autoTerminate = formatTime(*idle.AutoTerminateAt)
+			}
+			rows = append(rows, []string{
+				idle.ID, idle.Name, idle.User, idle.MachineType, idle.AgentStatus,
+				(time.Duration(idle.IdleMinutes) * time.Minute).String(), autoTerminate,
+			})
+		}
+		styler := func(_, col int, content string) string {
+			if col == 0 {
+				return s.Slug.Render(content)
+			}
+			return content
+		}
+		return style.SectionTable(w, headers, rows, s.Header, styler)
+	}
+	return nil
+}
+
+func renderGroups(w io.Writer, s style.Styles, column string, groups []internalrde.UsageGroup, priced bool) error {
+	headers := []string{column, "SESSIONS", "HOURS"}
+	if priced {
+		headers = append(headers, "CREDITS")
+	}
+	rows := make([][]string, 0, len(groups))
+	for _, g := range groups {
+		key := g.Key
+		if g.Title != "" {
+			key += " (" + g.Title + ")"
+		}
+		row := []string{key, strconv.Itoa(g.Sessions), hours(g.Minutes)}
+		if priced {
+			row = append(row, credits(*g.Credits))
+		}
+		rows = append(rows, row)
+	}
+	styler := func(_, _ int, content string) string {
+		if content == internalrde.UsageUnknown {
+			return s.Dim.Render(content)
+		}
+		return content
+	}
+	return style.SectionTable(w, headers, rows, s.Header, styler)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 UTC")
+}
+
+func hours(minutes float64) string {
+	return strconv.FormatFloat(minutes/60, 'f', 1, 64)
+}
+
+func credits(c float64) string {
+	return strconv.FormatFloat(c, 'f', 1, 64)
+}
@@ -0,0 +1,135 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+func run(t *testing.T, c *cobra.Command, srvURL, workspaceID string, args []string, format output.Format) (string, string, error) {
+	t.Helper()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(stderr)
+	c.SetArgs(args)
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{
+		RDEAPIBaseURL: srvURL,
+		Token:         "tok",
+		Output:        format,
+		WorkspaceID:   workspaceID,
+	}))
+	err := c.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func newUsageServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	created := time.Now().Add(-5 * time.Hour).UTC().Format(time.RFC3339)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/workspaces/ws-1/sessions":
+			_, _ = io.WriteString(w, `{"sessions":[
+				{"id":"s1","name":"ios-dev","status":"SESSION_STATUS_RUNNING","templateName":"ios","createdAt":"`+created+`",
+				 "templateSnapshot":{"stackId":"xcode-16","machineType":"m2.large"},"labels":{"user":"ana"}},
+				{"id":"s2","name":"android-dev","status":"SESSION_STATUS_RUNNING","templateName":"android","createdAt":"`+created+`",
+				 "templateSnapshot":{"stackId":"ubuntu-24","machineType":"linux.medium"}}
+			]}`)
+		case r.URL.Path == "/v1/workspaces/ws-1/machine-types":
+			_, _ = io.WriteString(w, `{"machineTypes":[{"id":"mt1","name":"m2.large","title":"M2 Pro Large"}]}`)
+		case strings.HasSuffix(r.URL.Path, "/notifications"):
+			_, _ = io.WriteString(w, `{"notifications":[]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUsageCmd_HumanOutput(t *testing.T) {
+	srv := newUsageServer(t)
+
+	stdout, stderr, err := run(t, NewCmd(), srv.URL, "ws-1", []string{"--rate", "m2.large=0.5"}, output.Human)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	for _, want := range []string{
+		"Estimated: time terminated counts as running",
+		"2 sessions · 10.0 h · 150.0 credits",
+		"By user", "ana", "(unknown)",
+		"By template", "ios", "android",
+		"By stack", "xcode-16",
+		"By machine type", "m2.large (M2 Pro Large)",
+		"Idle running sessions (2)", "ios-dev", "5h0m0s",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout missing %q:\n%s", want, stdout)
+		}
+	}
+	if !strings.Contains(stderr, "no --rate for linux.medium") {
+		t.Errorf("stderr should warn about unpriced machine types, got:\n%s", stderr)
+	}
+}
+
+func TestUsageCmd_JSONOutput(t *testing.T) {
+	srv := newUsageServer(t)
+
+	stdout, stderr, err := run(t, NewCmd(), srv.URL, "ws-1", []string{"--idle-after", "0"}, output.JSON)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if stderr != "" {
+		t.Errorf("stderr should be empty in JSON mode, got:\n%s", stderr)
+	}
+	var got struct {
+		Sessions int                    `json:"sessions"`
+		Credits  *float64               `json:"credits"`
+		ByUser   []struct{ Key string } `json:"by_user"`
+		Idle     []json.RawMessage      `json:"idle"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, stdout)
+	}
+	if got.Sessions != 2 || got.Credits != nil || len(got.ByUser) != 2 || len(got.Idle) != 0 {
+		t.Errorf("unexpected report: %+v", got)
+	}
+}
+
+func TestUsageCmd_RejectsBadFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"--since", "last month"},
+		{"--rate", "m2.large"},
+		{"--rate", "m2.large=-1"},
+		{"--since", "2026-10-01", "--until", "2026-09-01"},
+	} {
+		if _, _, err := run(t, NewCmd(), "http://unused", "ws-1", args, output.Human); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestParseWindowTime(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"30d":                  time.Date(2026, 9, 18, 12, 0, 0, 0, time.UTC),
+		"12h":                  time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		"2026-09-01":           time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		"2026-09-01T08:00:00Z": time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC),
+	} {
+		got, err := parseWindowTime(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseWindowTime(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+}
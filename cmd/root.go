@@ -22,6 +22,7 @@ import (
 	cmdyml "github.com/bitrise-io/bitrise-cli/cmd/yml"
 	"github.com/bitrise-io/bitrise-cli/internal/auth"
 	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/httpdebug"
 	"github.com/bitrise-io/bitrise-cli/internal/output"
 	"github.com/bitrise-io/bitrise-cli/internal/output/style"
 )
@@ -52,9 +53,22 @@ For scripts and agents:
   "bitrise-cli config set output json" once). Data goes to stdout and
   diagnostics to stderr — even in json mode — so output stays pipeable.
   Most build and yml commands act on one app: pass --app ID or set BITRISE_APP_ID.
+  --output yaml, csv, or tsv reshape the same document; --columns picks
+  table columns; --jq EXPR and --template TEXT filter or format the JSON
+  without piping into another tool.
 
-Configuration precedence: flag > env > per-dir (.bitrise-cli.yml) > global
-config.yaml > built-in default. Run "bitrise-cli config" for all keys and env vars.`,
+Configuration precedence: flag > env > per-dir (.bitrise-cli.yml) > active
+profile > global config.yaml > built-in default. Run "bitrise-cli config" for
+all keys and env vars.
+
+Troubleshooting: --debug logs every HTTP request and response (credentials
+redacted); --debug-har FILE records them for a support ticket. Workspace,
+app, and stack lookups are cached on disk; --no-cache fetches them fresh and
+"bitrise-cli cache clear" empties the cache.
+
+Profiles: keep several accounts side by side with "auth login --profile NAME",
+then pick one per command with --profile NAME (or BITRISE_PROFILE), or for
+good with "bitrise-cli config use-profile NAME".`,
 	SilenceUsage:      true,
 	SilenceErrors:     false,
 	PersistentPreRunE: persistentPreRun,
@@ -68,6 +82,7 @@ func Execute() {
 	// otherwise. It prints on both success and failure paths so a user behind
 	// on versions still hears about it, and never changes the exit status.
 	notifyUpdateAvailable(os.Stderr)
+	finishDebug(os.Stderr)
 	if err != nil {
 		os.Exit(1)
 	}
@@ -85,10 +100,18 @@ func init() {
 	// defaults to "dev" for plain `go build`.
 	rdeapi.UserAgent = "bitrise-cli/" + version
 
-	rootCmd.PersistentFlags().StringP(cmdutil.FlagOutput, "o", "", `output format: human|json (default "human")`)
+	rootCmd.PersistentFlags().StringP(cmdutil.FlagOutput, "o", "", `output format: human|json|yaml|csv|tsv (default "human")`)
 	rootCmd.PersistentFlags().BoolVarP(&quiet, cmdutil.FlagQuiet, "q", false, "suppress non-error diagnostic messages")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable ANSI colors (NO_COLOR env is also honored)")
 	rootCmd.PersistentFlags().StringVar(&theme, cmdutil.FlagTheme, "", `color theme: auto|dark|light|none (default "auto"; overrides terminal background detection)`)
+	rootCmd.PersistentFlags().String(cmdutil.FlagProfile, "", `account profile to use (default: BITRISE_PROFILE, then "config use-profile")`)
+	rootCmd.PersistentFlags().Bool(cmdutil.FlagDebug, false, "log every HTTP request and response, redacted, to stderr (also BITRISE_DEBUG=api)")
+	rootCmd.PersistentFlags().String(cmdutil.FlagDebugFile, "", "write the --debug log to this file instead of stderr (implies --debug)")
+	rootCmd.PersistentFlags().String(cmdutil.FlagDebugHAR, "", "record the HTTP traffic, redacted, as a HAR file for a support ticket")
+	rootCmd.PersistentFlags().StringSlice(cmdutil.FlagColumns, nil, "show only these table columns, in this order (human, csv, and tsv output)")
+	rootCmd.PersistentFlags().String(cmdutil.FlagTemplate, "", "format the JSON output with a Go text/template")
+	rootCmd.PersistentFlags().String(cmdutil.FlagJQ, "", "filter the JSON output with a jq expression")
+	rootCmd.PersistentFlags().Bool(cmdutil.FlagNoCache, false, "fetch fresh API responses instead of using the on-disk cache (also BITRISE_NO_CACHE=1)")
 	rootCmd.SetFlagErrorFunc(cmdutil.FlagErrorFunc)
 	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
 	rootCmd.AddCommand(cmdbuild.NewCmd())
@@ -98,6 +121,7 @@ func init() {
 	rootCmd.AddCommand(cmduser.NewCmd())
 	rootCmd.AddCommand(newAuthCmd())
 	rootCmd.AddCommand(newVersionCmd())
+	rootCmd.AddCommand(newCacheCmd())
 	rootCmd.AddCommand(newStackCmd())
 	rootCmd.AddCommand(newPurrCmd())
 	rootCmd.AddCommand(cmdstep.NewCmd())
@@ -116,6 +140,9 @@ func init() {
 	if err := rootCmd.RegisterFlagCompletionFunc(cmdutil.FlagTheme, completeThemeFlag); err != nil {
 		panic(err)
 	}
+	if err := rootCmd.RegisterFlagCompletionFunc(cmdutil.FlagProfile, completeProfileFlag); err != nil {
+		panic(err)
+	}
 }
 
 const completionLong = `Generate a shell completion script for your shell.
@@ -133,7 +160,13 @@ PowerShell — load now:              bitrise-cli completion powershell | Out-St
 PowerShell — make permanent:        add the above line to your $PROFILE`
 
 func completeOutputFlag(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
-	return []string{"human\thuman-readable tables and key/value lines", "json\tmachine-readable JSON"}, cobra.ShellCompDirectiveNoFileComp
+	return []string{
+		"human\thuman-readable tables and key/value lines",
+		"json\tmachine-readable JSON",
+		"yaml\tthe JSON document as YAML",
+		"csv\tcomma-separated rows with a header line",
+		"tsv\ttab-separated rows with a header line",
+	}, cobra.ShellCompDirectiveNoFileComp
 }
 
 func completeThemeFlag(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
@@ -145,9 +178,20 @@ func completeThemeFlag(_ *cobra.Command, _ []string, _ string) ([]string, cobra.
 	}, cobra.ShellCompDirectiveNoFileComp
 }
 
-// persistentPreRun loads global config, per-directory config, and auth.yaml,
-// merges them with env + flags, and stores the Resolved settings on
-// cmd.Context() so subcommand handlers can read them.
+// completeProfileFlag offers every profile known to config.yaml or auth.yaml.
+func completeProfileFlag(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	globalCfg, _ := config.Load()
+	authFile, _ := auth.LoadFile()
+	return profileNames(globalCfg, authFile), cobra.ShellCompDirectiveNoFileComp
+}
+
+// persistentPreRun loads global config and per-directory config, merges
+// them with env + flags, and stores the Resolved settings on cmd.Context()
+// so subcommand handlers can read them. The credential store is only
+// registered here: it is opened and read when a command first needs a saved
+// token (see cmdutil.NewAPIClient), so `version`, `config`, completion, and
+// runs with BITRISE_TOKEN set never prompt for a passphrase or reach the
+// keychain.
 func persistentPreRun(cmd *cobra.Command, _ []string) error {
 	globalCfg, err := config.Load()
 	if err != nil {
@@ -157,27 +201,61 @@ func persistentPreRun(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
-	authData, err := auth.Load()
+	flagOut, _ := cmd.Flags().GetString(cmdutil.FlagOutput)
+	flagTheme, _ := cmd.Flags().GetString(cmdutil.FlagTheme)
+	flagProfile, _ := cmd.Flags().GetString(cmdutil.FlagProfile)
+	r, err := config.Resolve(globalCfg, dirCfg, flagOut, flagTheme, flagProfile)
 	if err != nil {
 		return err
 	}
-	flagOut, _ := cmd.Flags().GetString(cmdutil.FlagOutput)
-	flagTheme, _ := cmd.Flags().GetString(cmdutil.FlagTheme)
-	r, err := config.Resolve(globalCfg, dirCfg, authData, flagOut, flagTheme)
+	store, err := auth.OpenStoreLazy(r.CredentialStore, auth.StoreOptions{Passphrase: cmdutil.CredentialPassphrase(cmd)})
 	if err != nil {
 		return err
 	}
+	auth.UseStore(store)
+	if debug, _ := cmd.Flags().GetBool(cmdutil.FlagDebug); debug {
+		r.Debug = true
+	}
+	if noCache, _ := cmd.Flags().GetBool(cmdutil.FlagNoCache); noCache {
+		r.NoCache = true
+	}
+	if err := configureOutput(cmd, &r); err != nil {
+		return err
+	}
 	// Configure must run after Resolve so the resolved theme (which folds
 	// in the --theme flag, BITRISE_CLI_THEME, and the config files) is what
 	// actually drives Style construction in subcommand RunE bodies.
 	style.Configure(noColor, r.Theme)
-	cmd.SetContext(config.WithResolved(cmd.Context(), r))
+	tracer, err := startDebug(cmd, r)
+	if err != nil {
+		return err
+	}
+	cmd.SetContext(httpdebug.WithTracer(config.WithResolved(cmd.Context(), r), tracer))
 	// Decide now — while we have the resolved settings and the running command —
 	// whether Execute should look for a newer release once the command is done.
 	armUpdateCheck(cmd, r)
 	return nil
 }
 
+// configureOutput applies --columns, --template, and --jq. The flags are
+// read from the root so a subcommand's own --template (rde session create)
+// shadows rather than feeds the output template. --template and --jq work
+// on the JSON document, so they switch the command to JSON output.
+func configureOutput(cmd *cobra.Command, r *config.Resolved) error {
+	flags := cmd.Root().PersistentFlags()
+	var opts output.Options
+	opts.Columns, _ = flags.GetStringSlice(cmdutil.FlagColumns)
+	opts.Template, _ = flags.GetString(cmdutil.FlagTemplate)
+	opts.JQ, _ = flags.GetString(cmdutil.FlagJQ)
+	if err := output.Configure(opts); err != nil {
+		return err
+	}
+	if opts.Template != "" || opts.JQ != "" {
+		r.Output = output.JSON
+	}
+	return nil
+}
+
 // resolvedFromCmd returns the Resolved installed by persistentPreRun.
 // Used by auth subcommands which live in this package.
 func resolvedFromCmd(cmd *cobra.Command) config.Resolved {
@@ -0,0 +1,88 @@
+package step
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalstep "github.com/bitrise-io/bitrise-cli/internal/step"
+)
+
+func newChangelogCmd() *cobra.Command {
+	var from, to string
+
+	c := &cobra.Command{
+		Use:   "changelog STEP_ID",
+		Short: "Show release notes between two step versions",
+		Long: `Show the release notes of a step's versions, newest first.
+
+Use it before bumping a step in bitrise.yml: --from is the version you use
+now (excluded), --to the one you are moving to (included).
+
+Release notes are the GitHub releases of the step's source repository,
+matched to StepLib versions by tag (with or without a leading "v"). Steps
+hosted elsewhere have none.
+
+Arguments:
+  STEP_ID   step ID, e.g. git-clone
+
+Flags:
+  --from VERSION   start after this version (default: the first version)
+  --to VERSION     end at this version (default: the latest version)`,
+		Example: `  bitrise-cli step changelog git-clone --from 8.2.0
+  bitrise-cli step changelog git-clone --from 7.0.0 --to 8.0.0
+  bitrise-cli step changelog xcode-archive --output json`,
+		Args: cmdutil.RequireArgs("STEP_ID"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := cmdutil.ResolveFormat(cmd)
+
+			svc := internalstep.NewService(nil, cmdutil.NewStepLibClient(cmd))
+			result, err := svc.Changelog(cmd.Context(), args[0], from, to)
+			if err != nil {
+				return err
+			}
+			return output.Render(cmd.OutOrStdout(), format, result, renderChangelogText)
+		},
+	}
+
+	c.Flags().StringVar(&from, "from", "", "start after this version (exclusive)")
+	c.Flags().StringVar(&to, "to", "", "end at this version (inclusive; default latest)")
+	return c
+}
+
+func renderChangelogText(w io.Writer, r internalstep.ChangelogResult) error {
+	if len(r.Items) == 0 {
+		_, err := fmt.Fprintln(w, "No versions in range.")
+		return err
+	}
+
+	s := style.New(w)
+	ew := cmdutil.NewErrWriter(w)
+	for i, v := range r.Items {
+		if i > 0 {
+			ew.Ln("")
+		}
+		heading := s.Bold.Render(r.StepID + "@" + v.Version)
+		if !v.PublishedAt.IsZero() {
+			heading += "  " + s.Dim.Render(v.PublishedAt.Format("2006-01-02"))
+		}
+		if v.IsDeprecated {
+			heading += "  " + s.Warn.Render("deprecated")
+		}
+		ew.Ln(heading)
+		notes := strings.TrimSpace(v.ReleaseNotes)
+		if notes == "" {
+			ew.Ln(s.Dim.Render("  No release notes."))
+			continue
+		}
+		for line := range strings.SplitSeq(notes, "\n") {
+			ew.Ln("  " + line)
+		}
+	}
+	return ew.Err
+}
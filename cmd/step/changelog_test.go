@@ -0,0 +1,65 @@
+package step
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+func TestChangelogCmd_Range(t *testing.T) {
+	srv := stepLibServer(t)
+
+	c := newChangelogCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"git-clone", "--from", "8.0.0"})
+	c.SetContext(stepCtx(srv, output.JSON))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var got struct {
+		To    string `json:"to"`
+		Items []struct {
+			Version      string `json:"version"`
+			ReleaseNotes string `json:"release_notes"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if got.To != "8.10.0" || len(got.Items) != 2 || got.Items[0].Version != "8.10.0" || got.Items[1].Version != "8.1.0" {
+		t.Errorf("got %+v, want 8.10.0 and 8.1.0", got)
+	}
+}
+
+func TestChangelogCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown version", []string{"git-clone", "--from", "9.9.9"}, `no version "9.9.9"`},
+		{"inverted range", []string{"git-clone", "--from", "8.10.0", "--to", "8.1.0"}, "not older than"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := stepLibServer(t)
+			c := newChangelogCmd()
+			c.SetOut(io.Discard)
+			c.SetErr(io.Discard)
+			c.SetArgs(tt.args)
+			c.SetContext(stepCtx(srv, output.Human))
+
+			err := c.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
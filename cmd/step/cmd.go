@@ -8,14 +8,23 @@ func NewCmd() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "step",
 		Short: "Search steps and inspect their inputs",
-		Long:  `Search the step library and inspect a step version's inputs.`,
+		Long: `Search the step library and inspect a step version's inputs.
+
+'step view' shows a step's full metadata, 'step versions' lists its
+releases, and 'step changelog' prints release notes between two versions.
+These three read the StepLib from GitHub and need no Bitrise token.`,
 		Example: `  bitrise-cli step search clone
   bitrise-cli step search fastlane --output json
-  bitrise-cli step inputs git-clone@8.3.1`,
+  bitrise-cli step inputs git-clone@8.3.1
+  bitrise-cli step view git-clone
+  bitrise-cli step changelog git-clone --from 8.2.0`,
 	}
 	c.AddCommand(
 		newSearchCmd(),
 		newInputsCmd(),
+		newViewCmd(),
+		newVersionsCmd(),
+		newChangelogCmd(),
 	)
 	return c
 }
@@ -34,7 +34,7 @@ Arguments:
 				return err
 			}
 
-			svc := internalstep.NewService(client)
+			svc := internalstep.NewService(client, nil)
 			result, err := svc.Inputs(cmd.Context(), args[0])
 			if err != nil {
 				return err
@@ -49,12 +49,16 @@ func renderInputsText(w io.Writer, r internalstep.InputsResult) error {
 		_, err := fmt.Fprintln(w, "No inputs found.")
 		return err
 	}
+	return renderInputsTable(w, r.Items, style.Table)
+}
 
+// renderInputsTable prints inputs as a table drawn by table.
+func renderInputsTable(w io.Writer, items []internalstep.StepInput, table style.TableFunc) error {
 	s := style.New(w)
 	headers := []string{"NAME", "TITLE", "DEFAULT", "REQUIRED", "SENSITIVE", "OPTIONS"}
-	rows := make([][]string, 0, len(r.Items))
-	required := make([]bool, 0, len(r.Items))
-	for _, inp := range r.Items {
+	rows := make([][]string, 0, len(items))
+	required := make([]bool, 0, len(items))
+	for _, inp := range items {
 		req := ""
 		if inp.IsRequired {
 			req = "yes"
@@ -79,5 +83,5 @@ func renderInputsText(w io.Writer, r internalstep.InputsResult) error {
 		}
 		return content
 	}
-	return style.Table(w, headers, rows, s.Header, styler)
+	return table(w, headers, rows, s.Header, styler)
 }
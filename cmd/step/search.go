@@ -52,7 +52,7 @@ Valid maintainers:
 				return err
 			}
 
-			svc := internalstep.NewService(client)
+			svc := internalstep.NewService(client, nil)
 			result, err := svc.Search(cmd.Context(), internalstep.SearchOptions{
 				Query:       args[0],
 				Categories:  categories,
@@ -0,0 +1,83 @@
+package step
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalstep "github.com/bitrise-io/bitrise-cli/internal/step"
+)
+
+func newVersionsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "versions STEP_ID",
+		Short: "List published versions of a step",
+		Long: `List every published version of a step, newest first, with release dates.
+
+Versions are the StepLib's (github.com/bitrise-io/bitrise-steplib); release
+dates are those of the matching GitHub releases of the step's source
+repository, so versions without a release have none. The StepLib deprecates
+whole steps: every version of a deprecated step is marked.
+
+Arguments:
+  STEP_ID   step ID, e.g. git-clone`,
+		Example: `  bitrise-cli step versions git-clone
+  bitrise-cli step versions git-clone --output json`,
+		Args: cmdutil.RequireArgs("STEP_ID"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := cmdutil.ResolveFormat(cmd)
+
+			svc := internalstep.NewService(nil, cmdutil.NewStepLibClient(cmd))
+			result, err := svc.Versions(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return output.Render(cmd.OutOrStdout(), format, result, renderVersionsText)
+		},
+	}
+}
+
+func renderVersionsText(w io.Writer, r internalstep.VersionsResult) error {
+	if len(r.Items) == 0 {
+		_, err := fmt.Fprintln(w, "No versions found.")
+		return err
+	}
+	return renderVersionsTable(w, style.New(w), r.Items, "", style.Table)
+}
+
+// renderVersionsTable prints versions as a table drawn by table,
+// highlighting current.
+func renderVersionsTable(w io.Writer, s style.Styles, versions []internalstep.Version, current string, table style.TableFunc) error {
+	headers := []string{"VERSION", "PUBLISHED", "STATUS"}
+	rows := make([][]string, 0, len(versions))
+	for i, v := range versions {
+		published := ""
+		if !v.PublishedAt.IsZero() {
+			published = v.PublishedAt.Format("2006-01-02")
+		}
+		status := ""
+		switch {
+		case v.IsDeprecated:
+			status = "deprecated"
+		case i == 0:
+			status = "latest"
+		}
+		rows = append(rows, []string{v.Version, published, status})
+	}
+	styler := func(row, col int, content string) string {
+		switch {
+		case versions[row].IsDeprecated:
+			return s.Dim.Render(content)
+		case col == 0 && versions[row].Version == current:
+			return s.Bold.Render(content)
+		case col == 0:
+			return s.Slug.Render(content)
+		}
+		return content
+	}
+	return table(w, headers, rows, s.Header, styler)
+}
@@ -0,0 +1,29 @@
+package step
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+func TestVersionsCmd_HumanOutput(t *testing.T) {
+	srv := stepLibServer(t)
+
+	c := newVersionsCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"git-clone"})
+	c.SetContext(stepCtx(srv, output.Human))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	out := stdout.String()
+	if strings.Index(out, "8.10.0") > strings.Index(out, "8.1.0 ") || !strings.Contains(out, "2024-06-01") {
+		t.Errorf("versions not listed newest first with dates:\n%s", out)
+	}
+}
@@ -0,0 +1,133 @@
+package step
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalstep "github.com/bitrise-io/bitrise-cli/internal/step"
+)
+
+// viewVersionsShown caps the versions table in human output; JSON output
+// always has all of them.
+const viewVersionsShown = 10
+
+func newViewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "view STEP_ID[@VERSION]",
+		Short: "Show a step's details, inputs, outputs, and versions",
+		Long: `Show everything the step library knows about a step version:
+description, maintainer, source repository, supported platforms and project
+types, all inputs and outputs, recent versions, and deprecation notes.
+
+Without @VERSION the latest version is shown.
+
+The details come from the StepLib (github.com/bitrise-io/bitrise-steplib),
+read through the GitHub API; release dates come from the GitHub releases of
+the step's source repository. No Bitrise token is needed.
+
+Arguments:
+  STEP_ID[@VERSION]   step ID, optionally with an exact version`,
+		Example: `  bitrise-cli step view git-clone
+  bitrise-cli step view git-clone@8.3.1
+  bitrise-cli step view xcode-archive --output json`,
+		Args: cmdutil.RequireArgs("STEP_ID[@VERSION]"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format := cmdutil.ResolveFormat(cmd)
+
+			svc := internalstep.NewService(nil, cmdutil.NewStepLibClient(cmd))
+			result, err := svc.View(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return output.Render(cmd.OutOrStdout(), format, result, renderViewText)
+		},
+	}
+}
+
+func renderViewText(w io.Writer, d internalstep.Detail) error {
+	s := style.New(w)
+	ew := cmdutil.NewErrWriter(w)
+	lbl := func(label string) string {
+		return s.Label.Render(fmt.Sprintf("%-16s", label))
+	}
+
+	ew.F("%s  %s\n", s.Slug.Render(d.ID+"@"+d.Version), s.Bold.Render(d.Title))
+	if d.Summary != "" {
+		ew.Ln(d.Summary)
+	}
+	ew.Ln("")
+	if d.IsDeprecated {
+		note := "this step is deprecated"
+		if d.DeprecationNotes != "" {
+			note += ": " + d.DeprecationNotes
+		}
+		ew.F("%s%s\n", lbl("Deprecated:"), s.Warn.Render(note))
+	}
+	if d.LatestVersion != "" && d.LatestVersion != d.Version {
+		ew.F("%s%s\n", lbl("Latest:"), d.LatestVersion)
+	}
+	if d.Maintainer != "" {
+		ew.F("%s%s\n", lbl("Maintainer:"), d.Maintainer)
+	}
+	if d.SourceCodeURL != "" {
+		ew.F("%s%s\n", lbl("Source:"), s.URL.Render(d.SourceCodeURL))
+	}
+	if d.Website != "" && d.Website != d.SourceCodeURL {
+		ew.F("%s%s\n", lbl("Website:"), s.URL.Render(d.Website))
+	}
+	if d.SupportURL != "" {
+		ew.F("%s%s\n", lbl("Support:"), s.URL.Render(d.SupportURL))
+	}
+	if len(d.Platforms) > 0 {
+		ew.F("%s%s\n", lbl("Platforms:"), strings.Join(d.Platforms, ", "))
+	}
+	if len(d.ProjectTypes) > 0 {
+		ew.F("%s%s\n", lbl("Project types:"), strings.Join(d.ProjectTypes, ", "))
+	}
+	if len(d.Categories) > 0 {
+		ew.F("%s%s\n", lbl("Categories:"), strings.Join(d.Categories, ", "))
+	}
+	if !d.PublishedAt.IsZero() {
+		ew.F("%s%s\n", lbl("Published:"), d.PublishedAt.Format("2006-01-02"))
+	}
+	if d.Description != "" {
+		ew.F("\n%s\n", strings.TrimSpace(d.Description))
+	}
+	if ew.Err != nil {
+		return ew.Err
+	}
+
+	if len(d.Inputs) > 0 {
+		ew.F("\n%s\n", s.Header.Render("Inputs"))
+		if err := renderInputsTable(w, d.Inputs, style.SectionTable); err != nil {
+			return err
+		}
+	}
+	if len(d.Outputs) > 0 {
+		ew.F("\n%s\n", s.Header.Render("Outputs"))
+		rows := make([][]string, 0, len(d.Outputs))
+		for _, out := range d.Outputs {
+			rows = append(rows, []string{out.Name, out.Title})
+		}
+		if err := style.SectionTable(w, []string{"NAME", "TITLE"}, rows, s.Header, nil); err != nil {
+			return err
+		}
+	}
+	if len(d.Versions) > 0 {
+		ew.F("\n%s\n", s.Header.Render("Versions"))
+		shown := d.Versions[:min(len(d.Versions), viewVersionsShown)]
+		if err := renderVersionsTable(w, s, shown, d.Version, style.SectionTable); err != nil {
+			return err
+		}
+		if more := len(d.Versions) - len(shown); more > 0 {
+			ew.F("%s\n", s.Dim.Render(fmt.Sprintf("… %d older versions (see 'bitrise-cli step versions %s')", more, d.ID)))
+		}
+	}
+	return ew.Err
+}
@@ -0,0 +1,176 @@
+package step
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+// stepLibServer serves the StepLib's git-clone through the GitHub API:
+// versions 8.0.0, 8.1.0, 8.10.0 (latest) and 7.0.0, listed out of order, and
+// releases for all but 7.0.0. old-step is deprecated.
+func stepLibServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	const lib = "/repos/bitrise-io/bitrise-steplib/contents/steps/"
+	stepYML := func(version string) string {
+		return `title: Git Clone Repository
+summary: Checks out the repository.
+source_code_url: https://github.com/bitrise-steplib/steps-git-clone
+published_at: 2024-02-01T00:00:00Z
+host_os_tags: [osx-10.10, ubuntu-16.04]
+project_type_tags: [ios, android]
+inputs:
+- repository_url: $GIT_REPOSITORY_URL
+  opts:
+    is_required: true
+- clone_depth: 1
+  opts:
+    value_options: [1, 50]
+outputs:
+- GIT_CLONE_COMMIT_HASH:
+  opts:
+    title: Cloned commit hash ` + version + "\n"
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".yml") && r.Header.Get("Accept") != "application/vnd.github.raw" {
+			t.Errorf("%s: Accept = %q, want the raw media type", r.URL.Path, r.Header.Get("Accept"))
+		}
+		switch r.URL.Path {
+		case lib + "git-clone":
+			_, _ = io.WriteString(w, `[
+				{"name":"8.1.0","type":"dir"},{"name":"7.0.0","type":"dir"},{"name":"assets","type":"dir"},
+				{"name":"8.10.0","type":"dir"},{"name":"8.0.0","type":"dir"},{"name":"step-info.yml","type":"file"}
+			]`)
+		case lib + "git-clone/step-info.yml":
+			_, _ = io.WriteString(w, "maintainer: bitrise\n")
+		case lib + "git-clone/8.1.0/step.yml":
+			_, _ = io.WriteString(w, stepYML("8.1.0"))
+		case lib + "git-clone/8.10.0/step.yml":
+			_, _ = io.WriteString(w, stepYML("8.10.0"))
+		case "/repos/bitrise-steplib/steps-git-clone/releases":
+			_, _ = io.WriteString(w, `[
+				{"tag_name":"9.0.0-beta","draft":true,"body":"Not out yet."},
+				{"tag_name":"8.10.0","published_at":"2024-06-01T00:00:00Z","body":"Faster fetch."},
+				{"tag_name":"8.1.0","published_at":"2024-02-01T00:00:00Z","body":"Adds sparse checkout."},
+				{"tag_name":"v8.0.0","published_at":"2024-01-01T00:00:00Z","body":"New major."}
+			]`)
+		case lib + "old-step":
+			_, _ = io.WriteString(w, `[{"name":"1.0.0","type":"dir"}]`)
+		case lib + "old-step/step-info.yml":
+			_, _ = io.WriteString(w, "maintainer: community\ndeprecate_notes: use new-step\n")
+		case lib + "old-step/1.0.0/step.yml":
+			_, _ = io.WriteString(w, "title: Old step\nsource_code_url: https://gitlab.com/org/old-step\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"message":"Not Found"}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func stepCtx(srv *httptest.Server, format output.Format) context.Context {
+	return config.WithResolved(context.Background(), config.Resolved{
+		StepLibAPIBaseURL: srv.URL,
+		Output:            format,
+	})
+}
+
+func TestViewCmd_HumanOutput(t *testing.T) {
+	srv := stepLibServer(t)
+
+	c := newViewCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"git-clone@8.1.0"})
+	c.SetContext(stepCtx(srv, output.Human))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	out := stdout.String()
+	for _, want := range []string{
+		"git-clone@8.1.0", "Git Clone Repository", "8.10.0", "steps-git-clone",
+		"ios, android", "repository_url", "GIT_CLONE_COMMIT_HASH", "bitrise",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stdout missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestViewCmd_JSONOutput(t *testing.T) {
+	srv := stepLibServer(t)
+
+	c := newViewCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"git-clone@8.1.0"})
+	c.SetContext(stepCtx(srv, output.JSON))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var got struct {
+		Version       string `json:"version"`
+		LatestVersion string `json:"latest_version"`
+		Outputs       []struct {
+			Name string `json:"name"`
+		} `json:"outputs"`
+		Versions []struct {
+			Version string `json:"version"`
+		} `json:"versions"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if got.Version != "8.1.0" || got.LatestVersion != "8.10.0" || len(got.Outputs) != 1 {
+		t.Errorf("got %+v", got)
+	}
+	if len(got.Versions) != 4 || got.Versions[0].Version != "8.10.0" || got.Versions[3].Version != "7.0.0" {
+		t.Errorf("versions not sorted newest first: %+v", got.Versions)
+	}
+}
+
+func TestViewCmd_NotFound(t *testing.T) {
+	srv := stepLibServer(t)
+
+	c := newViewCmd()
+	c.SetOut(io.Discard)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"git-clone@1.0.0"})
+	c.SetContext(stepCtx(srv, output.Human))
+
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), `step "git-clone@1.0.0" not found`) {
+		t.Errorf("err = %v, want not found", err)
+	}
+}
+
+func TestViewCmd_DeprecatedStep(t *testing.T) {
+	srv := stepLibServer(t)
+
+	c := newViewCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"old-step"})
+	c.SetContext(stepCtx(srv, output.Human))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if out := stdout.String(); !strings.Contains(out, "this step is deprecated: use new-step") {
+		t.Errorf("stdout missing the deprecation note:\n%s", out)
+	}
+}
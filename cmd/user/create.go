@@ -10,7 +10,6 @@ import (
 	"github.com/bitrise-io/bitrise-cli/internal/output"
 	"github.com/bitrise-io/bitrise-cli/internal/output/style"
 	internaluser "github.com/bitrise-io/bitrise-cli/internal/user"
-	"github.com/bitrise-io/bitrise-cli/internal/webclient"
 )
 
 func newCreateCmd() *cobra.Command {
@@ -72,7 +71,7 @@ Email verification:
 				return fmt.Errorf("password is empty")
 			}
 
-			webClient, err := webclient.New(cmdutil.ResolveWebBaseURL(cmd))
+			webClient, err := cmdutil.NewWebClient(cmd)
 			if err != nil {
 				return err
 			}
@@ -18,10 +18,21 @@ Running 'bitrise-cli yml' without a subcommand defaults to 'yml get'.
 
 Subcommands operate on the YAML stored server-side. If your project stores
 bitrise.yml in the repository (version-controlled mode), get and update
-commands still work, but uploaded changes will not affect builds.`,
+commands still work, but uploaded changes will not affect builds.
+
+A modular bitrise.yml split across files with include can be resolved
+locally with 'yml merge'; 'yml validate' merges before validating.
+'yml fmt' rewrites local files into a canonical layout, and 'yml init'
+scaffolds a new bitrise.yml from the project in the current directory.
+
+'yml history' lists the bitrise.yml revisions recent builds ran with;
+'yml show' prints one and 'yml rollback' restores it.`,
 		Example: `  bitrise-cli yml get --app APP_ID
   bitrise-cli yml validate --file bitrise.yml
-  bitrise-cli yml update --app APP_ID --file bitrise.yml`,
+  bitrise-cli yml merge --file bitrise.yml
+  bitrise-cli yml fmt --check bitrise.yml
+  bitrise-cli yml update --app APP_ID --file bitrise.yml
+  bitrise-cli yml history --app APP_ID`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			for _, sub := range cmd.Commands() {
 				if sub.Name() == "get" {
@@ -37,6 +48,12 @@ commands still work, but uploaded changes will not affect builds.`,
 		newGetCmd(),
 		newUpdateCmd(),
 		newValidateCmd(),
+		newFmtCmd(),
+		newInitCmd(),
+		newMergeCmd(),
+		newHistoryCmd(),
+		newShowCmd(),
+		newRollbackCmd(),
 	)
 	return c
 }
@@ -0,0 +1,164 @@
+package yml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalyml "github.com/bitrise-io/bitrise-cli/internal/yml"
+)
+
+type fmtFileResult struct {
+	Path    string `json:"path"`
+	Changed bool   `json:"changed"`
+	Written bool   `json:"written"`
+	Content string `json:"content,omitempty"`
+}
+
+type fmtResult struct {
+	Files []fmtFileResult `json:"files"`
+	check bool
+	write bool
+}
+
+func newFmtCmd() *cobra.Command {
+	var check, write bool
+
+	c := &cobra.Command{
+		Use:   "fmt [FILE...]",
+		Short: "Format bitrise.yml files into a canonical layout",
+		Long: `Rewrite bitrise.yml files into a canonical layout.
+
+Formatting:
+  - top-level keys in a stable order (format_version first, then app,
+    triggers and pipelines, with workflows and step_bundles last)
+  - two-space indentation
+  - step references with a version or source, such as git-clone@8 or
+    path::./step, double-quoted; plain step ids unquoted
+  - env lists sorted by name, only where the order can't matter: each item
+    sets one variable and no value references another variable of the list
+
+Comments are kept. Formatting an already formatted file changes nothing.
+
+Without flags, the formatted YAML is printed to stdout. With no FILE, reads
+from stdin. Printing several files would run them together, so more than one
+FILE needs --write, --check or a structured --output.
+
+Flags:
+  --check   report files that are not formatted; change nothing
+  --write   rewrite files in place
+
+No API call is made and no token is needed.
+
+Exit codes:
+  0   success (with --check: every file is formatted)
+  1   error (with --check: at least one file needs formatting)`,
+		Example: `  bitrise-cli yml fmt bitrise.yml
+  bitrise-cli yml fmt --write bitrise.yml ci/*.yml
+  bitrise-cli yml fmt --check bitrise.yml
+  cat bitrise.yml | bitrise-cli yml fmt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if write && len(args) == 0 {
+				return fmt.Errorf("--write needs at least one FILE")
+			}
+			format := cmdutil.ResolveFormat(cmd)
+			if len(args) > 1 && !check && !write && !format.Structured() && !output.Filtering() {
+				return fmt.Errorf("more than one FILE needs --write or --check (printed one after another they couldn't be told apart)")
+			}
+			paths := args
+			if len(paths) == 0 {
+				paths = []string{"-"}
+			}
+
+			result := fmtResult{Files: []fmtFileResult{}, check: check, write: write}
+			unformatted := 0
+			for _, p := range paths {
+				f, err := formatFile(cmd.InOrStdin(), p, write)
+				if err != nil {
+					return err
+				}
+				if check || write {
+					f.Content = ""
+				}
+				if f.Changed {
+					unformatted++
+				}
+				result.Files = append(result.Files, f)
+			}
+
+			if err := output.Render(cmd.OutOrStdout(), format, result, renderFmtText); err != nil {
+				return err
+			}
+			if check && unformatted > 0 {
+				cmdutil.SilenceRootErrors(cmd)
+				return fmt.Errorf("%d file(s) not formatted", unformatted)
+			}
+			return nil
+		},
+	}
+
+	c.Flags().BoolVar(&check, "check", false, "report unformatted files and exit non-zero if any; change nothing")
+	c.Flags().BoolVar(&write, "write", false, "rewrite files in place")
+	c.MarkFlagsMutuallyExclusive("check", "write")
+	return c
+}
+
+// formatFile formats the bitrise.yml at path ("-" for stdin) and, if write is
+// set and the layout changed, rewrites it with its existing permissions.
+func formatFile(stdin io.Reader, path string, write bool) (fmtFileResult, error) {
+	name, _ := inputSource(path)
+	data, err := readInput(stdin, path)
+	if err != nil {
+		return fmtFileResult{}, fmt.Errorf("read %s: %w", name, err)
+	}
+	formatted, err := internalyml.Format(data)
+	if err != nil {
+		return fmtFileResult{}, fmt.Errorf("format %s: %w", name, err)
+	}
+	r := fmtFileResult{Path: name, Changed: !bytes.Equal(data, formatted), Content: string(formatted)}
+	if write && r.Changed {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmtFileResult{}, err
+		}
+		if err := os.WriteFile(path, formatted, info.Mode().Perm()); err != nil {
+			return fmtFileResult{}, fmt.Errorf("write %s: %w", name, err)
+		}
+		r.Written = true
+	}
+	return r, nil
+}
+
+func renderFmtText(w io.Writer, r fmtResult) error {
+	ew := cmdutil.NewErrWriter(w)
+	if !r.check && !r.write {
+		for _, f := range r.Files {
+			ew.F("%s", f.Content)
+		}
+		return ew.Err
+	}
+
+	s := style.New(w)
+	changed := 0
+	for _, f := range r.Files {
+		switch {
+		case f.Written:
+			ew.Ln(s.Success.Render("✓") + " formatted " + f.Path)
+		case f.Changed:
+			ew.Ln(s.Failure.Render("✗") + " " + f.Path + " is not formatted")
+		}
+		if f.Changed {
+			changed++
+		}
+	}
+	if changed == 0 {
+		ew.Ln(s.Success.Render("✓") + fmt.Sprintf(" %d file(s) already formatted", len(r.Files)))
+	}
+	return ew.Err
+}
@@ -0,0 +1,187 @@
+package yml
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+const (
+	unformattedYML = "workflows:\n  test: {}\nformat_version: \"13\"\n"
+	formattedYML   = "format_version: \"13\"\nworkflows:\n  test: {}\n"
+)
+
+func runFmt(t *testing.T, format output.Format, args ...string) (string, error) {
+	t.Helper()
+	c := newFmtCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs(args)
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{Output: format}))
+	err := c.Execute()
+	return stdout.String(), err
+}
+
+func writeYML(t *testing.T, body string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "bitrise.yml")
+	if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestFmtCmd_PrintsFormatted(t *testing.T) {
+	p := writeYML(t, unformattedYML)
+
+	out, err := runFmt(t, output.Human, p)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if out != formattedYML {
+		t.Errorf("stdout = %q, want %q", out, formattedYML)
+	}
+	if data, _ := os.ReadFile(p); string(data) != unformattedYML { //nolint:gosec // test-only tempdir path
+		t.Errorf("file changed without --write")
+	}
+}
+
+func TestFmtCmd_CheckFailsOnUnformatted(t *testing.T) {
+	bad := writeYML(t, unformattedYML)
+	good := writeYML(t, formattedYML)
+
+	out, err := runFmt(t, output.Human, "--check", bad, good)
+	if err == nil || !strings.Contains(err.Error(), "1 file(s) not formatted") {
+		t.Errorf("err = %v, want not formatted error", err)
+	}
+	if !strings.Contains(out, bad) || strings.Contains(out, good) {
+		t.Errorf("stdout should list only the unformatted file:\n%s", out)
+	}
+
+	if _, err := runFmt(t, output.Human, "--check", good); err != nil {
+		t.Errorf("--check on formatted file: %v", err)
+	}
+}
+
+func TestFmtCmd_WriteRewritesInPlace(t *testing.T) {
+	p := writeYML(t, unformattedYML)
+
+	out, err := runFmt(t, output.JSON, "--write", p)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var got struct {
+		Files []struct {
+			Path    string `json:"path"`
+			Changed bool   `json:"changed"`
+			Written bool   `json:"written"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(got.Files) != 1 || got.Files[0].Path != p || !got.Files[0].Changed || !got.Files[0].Written {
+		t.Errorf("files = %+v", got.Files)
+	}
+	if data, _ := os.ReadFile(p); string(data) != formattedYML { //nolint:gosec // test-only tempdir path
+		t.Errorf("file = %q, want %q", data, formattedYML)
+	}
+}
+
+func TestFmtCmd_WriteNeedsFiles(t *testing.T) {
+	if _, err := runFmt(t, output.Human, "--write"); err == nil {
+		t.Error("expected error for --write without files")
+	}
+}
+
+func TestFmtCmd_SeveralFilesToStdoutRejected(t *testing.T) {
+	a, b := writeYML(t, unformattedYML), writeYML(t, formattedYML)
+	if _, err := runFmt(t, output.Human, a, b); err == nil || !strings.Contains(err.Error(), "--write or --check") {
+		t.Errorf("err = %v, want several files without --write rejected", err)
+	}
+	out, err := runFmt(t, output.JSON, a, b)
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	var got fmtResult
+	if err := json.Unmarshal([]byte(out), &got); err != nil || len(got.Files) != 2 || got.Files[0].Content != formattedYML {
+		t.Errorf("JSON = %s (%v), want each file's content separately", out, err)
+	}
+}
+
+// TestFmtCmd_StepRefQuotingRoundTripsThroughValidate formats refs written
+// both quoted and unquoted, then validates the result: the API must receive
+// the same step refs, as strings, however the input quoted them.
+func TestFmtCmd_StepRefQuotingRoundTripsThroughValidate(t *testing.T) {
+	p := writeYML(t, `format_version: "13"
+workflows:
+  primary:
+    steps:
+    - git-clone@8: {}
+    - 'script@1.2': {}
+    - "path::./steps/local": {}
+    - git::https://github.com/org/step.git@main: {}
+    - "cache-pull": {}
+`)
+	if _, err := runFmt(t, output.Human, "--write", p); err != nil {
+		t.Fatalf("fmt --write: %v", err)
+	}
+	formatted, err := os.ReadFile(p) //nolint:gosec // test-only tempdir path
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`- "git-clone@8": {}`, `- "script@1.2": {}`, `- "path::./steps/local": {}`, `- "git::https://github.com/org/step.git@main": {}`, `- cache-pull: {}`} {
+		if !strings.Contains(string(formatted), want) {
+			t.Errorf("formatted output missing %s:\n%s", want, formatted)
+		}
+	}
+
+	var refs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			BitriseYML string `json:"bitrise_yml"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		var doc struct {
+			Workflows map[string]struct {
+				Steps []map[string]any `yaml:"steps"`
+			} `yaml:"workflows"`
+		}
+		if err := yaml.Unmarshal([]byte(body.BitriseYML), &doc); err != nil {
+			t.Errorf("validate received unparseable YAML: %v", err)
+		}
+		for _, step := range doc.Workflows["primary"].Steps {
+			for ref := range step {
+				refs = append(refs, ref)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"errors": []string{}, "warnings": []string{}})
+	}))
+	defer srv.Close()
+
+	c := newValidateCmd()
+	c.SetOut(io.Discard)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"--file", p})
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{APIBaseURL: srv.URL, Token: "tok", Output: output.Human}))
+	if err := c.Execute(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	want := "git-clone@8 script@1.2 path::./steps/local git::https://github.com/org/step.git@main cache-pull"
+	if got := strings.Join(refs, " "); got != want {
+		t.Errorf("validated step refs = %q, want %q", got, want)
+	}
+}
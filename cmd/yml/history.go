@@ -0,0 +1,102 @@
+package yml
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalyml "github.com/bitrise-io/bitrise-cli/internal/yml"
+)
+
+// flagHistoryLimit is the --limit flag shared by history, show, and rollback:
+// how many recent builds to scan for bitrise.yml revisions.
+const flagHistoryLimit = "limit"
+
+func newHistoryCmd() *cobra.Command {
+	var limit int
+
+	c := &cobra.Command{
+		Use:   "history",
+		Short: "List the bitrise.yml revisions recent builds ran with",
+		Long: `List the distinct bitrise.yml revisions used by an app's recent builds.
+
+Scans the most recent builds (newest first), fetches the bitrise.yml each one
+ran with, and groups builds that used identical YAML into one revision. Each
+revision shows the range of builds that used it and who introduced it (the
+trigger of its first build). That column is empty when the first build found
+is the oldest one scanned, as the revision may be older still.
+
+Pass a revision ID to 'yml show' to print it, or to 'yml rollback' to restore
+it as the app's stored bitrise.yml. Each scanned build costs one API call;
+raise --limit to look further back.
+
+Required:
+  --app ID      app ID (or BITRISE_APP_ID env var)`,
+		Example: `  bitrise-cli yml history --app my-app-id
+  bitrise-cli yml history --app my-app-id --limit 200
+  bitrise-cli yml history --app my-app-id --output json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := cmdutil.NewAPIClient(cmd)
+			if err != nil {
+				return err
+			}
+			appSlug, err := cmdutil.ResolveAndLookupAppSlug(cmd, client)
+			if err != nil {
+				return err
+			}
+			format := cmdutil.ResolveFormat(cmd)
+			result, err := internalyml.NewService(client).History(cmd.Context(), appSlug, limit)
+			if err != nil {
+				return err
+			}
+			return output.Render(cmd.OutOrStdout(), format, result, renderHistoryText)
+		},
+	}
+
+	addHistoryLimitFlag(c, &limit)
+	return c
+}
+
+func addHistoryLimitFlag(c *cobra.Command, limit *int) {
+	c.Flags().IntVar(limit, flagHistoryLimit, internalyml.DefaultHistoryBuilds, "number of recent builds to scan")
+}
+
+func renderHistoryText(w io.Writer, r internalyml.HistoryResult) error {
+	s := style.New(w)
+	if len(r.Items) == 0 {
+		_, err := fmt.Fprintf(w, "No bitrise.yml revisions found in the last %d builds.\n", r.BuildsScanned)
+		return err
+	}
+	headers := []string{"REVISION", "BUILDS", "FIRST", "LAST", "INTRODUCED BY", "LAST USED"}
+	rows := make([][]string, 0, len(r.Items))
+	for _, rev := range r.Items {
+		lastUsed := ""
+		if !rev.LastSeenAt.IsZero() {
+			lastUsed = rev.LastSeenAt.Format("2006-01-02 15:04")
+		}
+		rows = append(rows, []string{
+			rev.ID,
+			strconv.Itoa(rev.BuildCount),
+			"#" + strconv.Itoa(rev.FirstBuildNumber),
+			"#" + strconv.Itoa(rev.LastBuildNumber),
+			rev.IntroducedBy,
+			lastUsed,
+		})
+	}
+	styler := func(_, col int, content string) string {
+		if col == 0 {
+			return s.Slug.Render(content)
+		}
+		return content
+	}
+	if err := style.Table(w, headers, rows, s.Header, styler); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", s.Dim.Render(fmt.Sprintf("%d revision(s) across the last %d builds", len(r.Items), r.BuildsScanned)))
+	return err
+}
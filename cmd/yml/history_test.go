@@ -0,0 +1,182 @@
+package yml
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdtest"
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+const oldYML = "format_version: \"13\"\nworkflows:\n  primary: {}\n"
+
+// historyServer serves two builds — #2 ran sampleYML, #1 ran oldYML — with
+// sampleYML as the stored config. uploads counts POSTs to the stored yml.
+func historyServer(t *testing.T, uploads *int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(cmdtest.AppPassthrough(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apps/my-app/builds":
+			_, _ = io.WriteString(w, `{"data":[
+				{"slug":"b-2","build_number":2,"triggered_by":"bob"},
+				{"slug":"b-1","build_number":1,"triggered_by":"alice"}
+			],"paging":{}}`)
+		case "/apps/my-app/builds/b-2/bitrise.yml":
+			_, _ = io.WriteString(w, sampleYML)
+		case "/apps/my-app/builds/b-1/bitrise.yml":
+			_, _ = io.WriteString(w, oldYML)
+		case "/apps/my-app/bitrise.yml":
+			if r.Method == http.MethodPost {
+				*uploads++
+				_, _ = io.WriteString(w, `{}`)
+				return
+			}
+			_, _ = io.WriteString(w, sampleYML)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func ymlCtx(srv *httptest.Server, format output.Format) context.Context {
+	return config.WithResolved(context.Background(), config.Resolved{
+		APIBaseURL: srv.URL,
+		Token:      "tok",
+		Output:     format,
+		AppSlug:    "my-app",
+	})
+}
+
+func TestHistoryCmd_JSON(t *testing.T) {
+	srv := historyServer(t, new(int))
+
+	c := newHistoryCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetContext(ymlCtx(srv, output.JSON))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var got struct {
+		BuildsScanned int `json:"builds_scanned"`
+		Items         []struct {
+			ID               string `json:"id"`
+			FirstBuildNumber int    `json:"first_build_number"`
+			IntroducedBy     string `json:"introduced_by"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if got.BuildsScanned != 2 || len(got.Items) != 2 {
+		t.Fatalf("unexpected history: %s", stdout.String())
+	}
+	if got.Items[1].FirstBuildNumber != 1 || got.Items[1].IntroducedBy != "alice" {
+		t.Errorf("oldest revision = %+v", got.Items[1])
+	}
+}
+
+// oldRevisionID runs history and returns the ID of the revision build #1 used.
+func oldRevisionID(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	c := newHistoryCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetContext(ymlCtx(srv, output.JSON))
+	if err := c.Execute(); err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	var got struct {
+		Items []struct {
+			ID string `json:"id"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil || len(got.Items) != 2 {
+		t.Fatalf("history output: %v\n%s", err, stdout.String())
+	}
+	return got.Items[1].ID
+}
+
+func TestRollbackCmd_YesUploadsAndShowsDiff(t *testing.T) {
+	uploads := 0
+	srv := historyServer(t, &uploads)
+	rev := oldRevisionID(t, srv)
+
+	c := newRollbackCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{rev, "--yes"})
+	c.SetContext(ymlCtx(srv, output.Human))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if uploads != 1 {
+		t.Errorf("uploads = %d, want 1", uploads)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "--- current") || !strings.Contains(out, "Rolled back") {
+		t.Errorf("stdout missing diff or confirmation:\n%s", out)
+	}
+}
+
+func TestRollbackCmd_NonInteractiveRequiresYes(t *testing.T) {
+	uploads := 0
+	srv := historyServer(t, &uploads)
+	rev := oldRevisionID(t, srv)
+
+	c := newRollbackCmd()
+	stderr := &bytes.Buffer{}
+	c.SetOut(io.Discard)
+	c.SetErr(stderr)
+	c.SetIn(strings.NewReader("y\n"))
+	c.SetArgs([]string{rev})
+	c.SetContext(ymlCtx(srv, output.Human))
+
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("expected --yes error, got %v", err)
+	}
+	if uploads != 0 {
+		t.Errorf("uploads = %d, want 0", uploads)
+	}
+	if !strings.Contains(stderr.String(), "--- current") {
+		t.Errorf("preview diff missing from stderr:\n%s", stderr.String())
+	}
+}
+
+func TestRollbackCmd_DryRun(t *testing.T) {
+	uploads := 0
+	srv := historyServer(t, &uploads)
+	rev := oldRevisionID(t, srv)
+
+	c := newRollbackCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{rev, "--dry-run"})
+	c.SetContext(ymlCtx(srv, output.Human))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if uploads != 0 {
+		t.Errorf("uploads = %d, want 0", uploads)
+	}
+	if !strings.Contains(stdout.String(), "Dry run") {
+		t.Errorf("stdout missing dry-run note:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,151 @@
+package yml
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalyml "github.com/bitrise-io/bitrise-cli/internal/yml"
+)
+
+type initResult struct {
+	internalyml.ScaffoldResult
+	Path    string `json:"path"`
+	Written bool   `json:"written"`
+}
+
+func newInitCmd() *cobra.Command {
+	var (
+		projectType string
+		filePath    string
+		force       bool
+		offline     bool
+	)
+
+	c := &cobra.Command{
+		Use:   "init [DIR]",
+		Short: "Scaffold a bitrise.yml from the local project",
+		Long: `Inspect a local checkout and write a proposed bitrise.yml for review.
+
+DIR defaults to the current directory. Up to 3 directory levels are scanned
+for (in priority order):
+  flutter        pubspec.yaml with a flutter section
+  react-native   package.json depending on react-native
+  ios            *.xcworkspace, *.xcodeproj, or Package.swift
+  android        gradlew
+  fastlane       fastlane/Fastfile
+  node           package.json
+Anything else gets a minimal script-based config ("other"). When several
+types are found, the first is used; pass --project-type to pick another.
+
+The proposal has a primary workflow that runs the project's tests and, for
+mobile projects, a deploy workflow that builds a release artifact. Steps are
+pinned to the major version of their latest release in the step library;
+with --offline no lookup is made and steps are left unversioned.
+
+The result is written to DIR/bitrise.yml unless --file says otherwise
+("-" prints to stdout). An existing file is never overwritten without --force.
+Nothing is uploaded: review the file, then use 'yml validate' and
+'app create', which uploads ./bitrise.yml when present.`,
+		Example: `  bitrise-cli yml init
+  bitrise-cli yml init ./mobile --project-type android
+  bitrise-cli yml init --file - --offline
+  bitrise-cli yml init --force --output json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			if filePath == "" {
+				filePath = filepath.Join(dir, "bitrise.yml")
+			}
+			if filePath != "-" && !force {
+				if _, err := os.Stat(filePath); err == nil {
+					return fmt.Errorf("%s already exists (use --force to overwrite)", filePath)
+				} else if !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+			}
+
+			project, err := internalyml.Detect(dir)
+			if err != nil {
+				return fmt.Errorf("inspect %s: %w", dir, err)
+			}
+			if projectType != "" {
+				project = project.WithType(projectType)
+			}
+
+			svc := internalyml.NewService(nil)
+			if !offline {
+				client, err := cmdutil.NewAPIClient(cmd)
+				if err != nil {
+					return err
+				}
+				svc = internalyml.NewService(client)
+			}
+			scaffold, err := svc.Scaffold(cmd.Context(), project, offline)
+			if err != nil {
+				return err
+			}
+
+			result := initResult{ScaffoldResult: scaffold}
+			if filePath != "-" {
+				if err := os.WriteFile(filePath, []byte(scaffold.Content), 0o644); err != nil { //nolint:gosec // bitrise.yml is committed to the repo, not a secret
+					return fmt.Errorf("write %s: %w", filePath, err)
+				}
+				result.Path, result.Written = filePath, true
+			} else {
+				for _, w := range scaffold.Warnings {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", w)
+				}
+			}
+
+			format := cmdutil.ResolveFormat(cmd)
+			return output.Render(cmd.OutOrStdout(), format, result, renderInitText)
+		},
+	}
+
+	c.Flags().StringVar(&projectType, "project-type", "", "scaffold for this project type instead of the detected one")
+	c.Flags().StringVarP(&filePath, "file", "f", "", `where to write the bitrise.yml (default DIR/bitrise.yml; "-" for stdout)`)
+	c.Flags().BoolVar(&force, "force", false, "overwrite an existing file")
+	c.Flags().BoolVar(&offline, "offline", false, "don't look up step versions; leave steps unversioned")
+
+	_ = c.RegisterFlagCompletionFunc("project-type", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return internalyml.ScaffoldProjectTypes, cobra.ShellCompDirectiveNoFileComp
+	})
+	return c
+}
+
+func renderInitText(w io.Writer, r initResult) error {
+	ew := cmdutil.NewErrWriter(w)
+	if !r.Written {
+		ew.F("%s", r.Content)
+		return ew.Err
+	}
+
+	s := style.New(w)
+	detected := r.Project.Type
+	if r.Project.Marker != "" {
+		detected += " (" + r.Project.Marker + ")"
+	}
+	ew.F("%s Project type: %s\n", s.Success.Render("✓"), detected)
+	if len(r.Project.AlsoDetected) > 0 {
+		ew.F("  %s\n", s.Dim.Render("also found: "+strings.Join(r.Project.AlsoDetected, ", ")+" (use --project-type to switch)"))
+	}
+	for _, warning := range r.Warnings {
+		ew.F("  %s %s\n", s.Warn.Render("Warning:"), warning)
+	}
+	ew.F("%s Wrote %s\n", s.Success.Render("✓"), r.Path)
+	ew.F("  %s\n", s.Dim.Render("Review it, then run: bitrise-cli yml validate --file "+r.Path))
+	return ew.Err
+}
@@ -0,0 +1,101 @@
+package yml
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+func TestInitCmd_WritesScaffold(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gradlew"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{{"id": r.URL.Query().Get("query"), "version": "2.1.0"}})
+	}))
+	defer srv.Close()
+
+	c := newInitCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{dir})
+	c.SetContext(ymlCtx(srv, output.JSON))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var got struct {
+		Project struct {
+			Type string `json:"type"`
+		} `json:"project"`
+		Path    string `json:"path"`
+		Written bool   `json:"written"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	want := filepath.Join(dir, "bitrise.yml")
+	if got.Project.Type != "android" || got.Path != want || !got.Written {
+		t.Errorf("result = %+v", got)
+	}
+	data, err := os.ReadFile(want) //nolint:gosec // test-only tempdir path
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "android-build@2") {
+		t.Errorf("written bitrise.yml missing pinned step:\n%s", data)
+	}
+}
+
+func TestInitCmd_RefusesToOverwrite(t *testing.T) {
+	p := writeYML(t, formattedYML)
+
+	c := newInitCmd()
+	c.SetOut(io.Discard)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{filepath.Dir(p), "--offline"})
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	c.SetContext(ymlCtx(srv, output.Human))
+
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("err = %v, want overwrite refusal", err)
+	}
+	if data, _ := os.ReadFile(p); string(data) != formattedYML { //nolint:gosec // test-only tempdir path
+		t.Errorf("existing file was modified")
+	}
+}
+
+func TestInitCmd_OfflineToStdout(t *testing.T) {
+	dir := t.TempDir()
+
+	c := newInitCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{dir, "--offline", "--file", "-"})
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	c.SetContext(ymlCtx(srv, output.Human))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "project_type: other") || !strings.Contains(stdout.String(), "- script:") {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bitrise.yml")); err == nil {
+		t.Error("--file - must not write a file")
+	}
+}
@@ -0,0 +1,157 @@
+package yml
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+)
+
+// writeModularYML lays out a bitrise.yml that includes one module and returns
+// the root path and the module path.
+func writeModularYML(t *testing.T) (root, mod string) {
+	t.Helper()
+	dir := t.TempDir()
+	mod = filepath.Join(dir, "workflows.yml")
+	if err := os.WriteFile(mod, []byte("workflows:\n  test:\n    title: Test\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	root = filepath.Join(dir, "bitrise.yml")
+	if err := os.WriteFile(root, []byte("format_version: \"13\"\ninclude:\n- path: workflows.yml\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return root, mod
+}
+
+func TestMergeCmd_PrintsMergedDocument(t *testing.T) {
+	root, _ := writeModularYML(t)
+
+	c := newMergeCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"--file", root})
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{Output: output.Human}))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "title: Test") || !strings.Contains(out, "format_version") {
+		t.Errorf("merged output missing content:\n%s", out)
+	}
+	if strings.Contains(out, "include:") {
+		t.Errorf("merged output still has include key:\n%s", out)
+	}
+}
+
+func TestMergeCmd_JSONListsFiles(t *testing.T) {
+	root, mod := writeModularYML(t)
+
+	c := newMergeCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"--file", root})
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{Output: output.JSON}))
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var got struct {
+		Content string   `json:"content"`
+		Files   []string `json:"files"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	if len(got.Files) != 2 || got.Files[0] != mod || got.Files[1] != root {
+		t.Errorf("files = %v, want [%s %s]", got.Files, mod, root)
+	}
+}
+
+func TestValidateCmd_MergesIncludesAndRemapsLines(t *testing.T) {
+	root, mod := writeModularYML(t)
+
+	var sent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			BitriseYML string `json:"bitrise_yml"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		sent = body.BitriseYML
+		line := 0
+		for i, l := range strings.Split(sent, "\n") {
+			if strings.Contains(l, "title: Test") {
+				line = i + 1
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"errors":   []string{"bad title on line " + strconv.Itoa(line)},
+			"warnings": []string{},
+		})
+	}))
+	defer srv.Close()
+
+	c := newValidateCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"--file", root})
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{
+		APIBaseURL: srv.URL,
+		Token:      "tok",
+		Output:     output.Human,
+	}))
+
+	if err := c.Execute(); err == nil {
+		t.Fatal("expected invalid error")
+	}
+	if strings.Contains(sent, "include:") || !strings.Contains(sent, "title: Test") {
+		t.Errorf("API did not receive the merged document:\n%s", sent)
+	}
+	if want := "line 3 of " + mod; !strings.Contains(stdout.String(), want) {
+		t.Errorf("stdout missing remapped location %q:\n%s", want, stdout.String())
+	}
+}
+
+func TestValidateCmd_MissingIncludeIsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "bitrise.yml")
+	if err := os.WriteFile(root, []byte("include:\n- path: gone.yml\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("API must not be called when includes can't be resolved")
+	}))
+	defer srv.Close()
+
+	c := newValidateCmd()
+	stdout := &bytes.Buffer{}
+	c.SetOut(stdout)
+	c.SetErr(io.Discard)
+	c.SetArgs([]string{"--file", root})
+	c.SetContext(config.WithResolved(context.Background(), config.Resolved{
+		APIBaseURL: srv.URL,
+		Token:      "tok",
+		Output:     output.Human,
+	}))
+
+	err := c.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid") {
+		t.Errorf("expected invalid error, got %v", err)
+	}
+	if !strings.Contains(stdout.String(), "gone.yml") {
+		t.Errorf("stdout missing include error: %q", stdout.String())
+	}
+}
@@ -0,0 +1,148 @@
+package yml
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+	internalyml "github.com/bitrise-io/bitrise-cli/internal/yml"
+)
+
+func newRollbackCmd() *cobra.Command {
+	var (
+		limit     int
+		dryRun    bool
+		assumeYes bool
+	)
+
+	c := &cobra.Command{
+		Use:   "rollback REV",
+		Short: "Restore a past bitrise.yml revision",
+		Long: `Upload a bitrise.yml revision listed by 'yml history' as the app's stored
+bitrise.yml.
+
+Prints a diff from the current stored bitrise.yml to the revision, then asks
+for confirmation before uploading. Pass --yes to skip the prompt (required
+when stdin is not a terminal), or --dry-run to only print the diff.
+
+REV is a revision ID, or any unique prefix of at least 4 characters.
+
+Note: if the app is configured to read its bitrise.yml from the repository,
+the upload succeeds but will not affect builds — revert the commit instead.
+
+Required:
+  --app ID      app ID (or BITRISE_APP_ID env var)
+
+Arguments:
+  REV           revision ID from 'yml history'`,
+		Example: `  bitrise-cli yml rollback 3f9a1c2b7d4e --app my-app-id
+  bitrise-cli yml rollback 3f9a --app my-app-id --dry-run
+  bitrise-cli yml rollback 3f9a --app my-app-id --yes --output json`,
+		Args: cmdutil.RequireArgs("REV"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := cmdutil.NewAPIClient(cmd)
+			if err != nil {
+				return err
+			}
+			appSlug, err := cmdutil.ResolveAndLookupAppSlug(cmd, client)
+			if err != nil {
+				return err
+			}
+			format := cmdutil.ResolveFormat(cmd)
+			svc := internalyml.NewService(client)
+			plan, err := svc.PlanRollback(cmd.Context(), appSlug, args[0], limit)
+			if err != nil {
+				return err
+			}
+
+			if dryRun || plan.Diff == "" {
+				return output.Render(cmd.OutOrStdout(), format, plan, renderRollbackText)
+			}
+			if !assumeYes {
+				// Show the preview before asking; in JSON mode the diff is part
+				// of the result document, so the preview goes to stderr.
+				if err := renderDiffText(cmd.ErrOrStderr(), plan.Diff); err != nil {
+					return err
+				}
+				if !cmdutil.IsTerminal(cmd.InOrStdin()) {
+					return fmt.Errorf("refusing to roll back without confirmation: pass --yes")
+				}
+				if _, err := fmt.Fprintf(cmd.ErrOrStderr(), "Upload revision %s as the stored bitrise.yml? [y/N]: ", plan.Revision.ID); err != nil {
+					return err
+				}
+				answer, err := cmdutil.ReadSecretInput(cmd.InOrStdin(), cmd.ErrOrStderr(), "", true)
+				if err != nil {
+					return err
+				}
+				if answer != "y" && answer != "Y" && answer != "yes" {
+					return fmt.Errorf("aborted")
+				}
+			}
+
+			plan, err = svc.Rollback(cmd.Context(), plan)
+			if err != nil {
+				return err
+			}
+			if format == output.Human && !assumeYes {
+				// The diff was already shown as the preview.
+				return renderRollbackDone(cmd.OutOrStdout(), plan)
+			}
+			return output.Render(cmd.OutOrStdout(), format, plan, renderRollbackText)
+		},
+	}
+
+	addHistoryLimitFlag(c, &limit)
+	c.Flags().BoolVar(&dryRun, "dry-run", false, "print the diff without uploading")
+	c.Flags().BoolVar(&assumeYes, "yes", false, "skip the confirmation prompt")
+	c.MarkFlagsMutuallyExclusive("dry-run", "yes")
+	return c
+}
+
+func renderRollbackText(w io.Writer, p internalyml.RollbackPlan) error {
+	s := style.New(w)
+	if p.Diff == "" {
+		_, err := fmt.Fprintf(w, "The stored bitrise.yml already matches revision %s.\n", s.Slug.Render(p.Revision.ID))
+		return err
+	}
+	if err := renderDiffText(w, p.Diff); err != nil {
+		return err
+	}
+	if !p.Applied {
+		_, err := fmt.Fprintln(w, s.Dim.Render("\nDry run: nothing was uploaded."))
+		return err
+	}
+	return renderRollbackDone(w, p)
+}
+
+func renderRollbackDone(w io.Writer, p internalyml.RollbackPlan) error {
+	s := style.New(w)
+	_, err := fmt.Fprintf(w, "%s Rolled back bitrise.yml to revision %s (first used in build #%d)\n",
+		s.Success.Render("✓"), s.Slug.Render(p.Revision.ID), p.Revision.FirstBuildNumber)
+	return err
+}
+
+// renderDiffText writes a unified diff, coloring added and removed lines.
+func renderDiffText(w io.Writer, diff string) error {
+	s := style.New(w)
+	ew := cmdutil.NewErrWriter(w)
+	for line := range strings.SplitSeq(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			ew.Ln(s.Bold.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			ew.Ln(s.Dim.Render(line))
+		case strings.HasPrefix(line, "+"):
+			ew.Ln(s.Success.Render(line))
+		case strings.HasPrefix(line, "-"):
+			ew.Ln(s.Failure.Render(line))
+		default:
+			ew.Ln(line)
+		}
+	}
+	return ew.Err
+}
@@ -0,0 +1,58 @@
+package yml
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bitrise-io/bitrise-cli/cmd/cmdutil"
+	"github.com/bitrise-io/bitrise-cli/internal/output"
+	internalyml "github.com/bitrise-io/bitrise-cli/internal/yml"
+)
+
+func newShowCmd() *cobra.Command {
+	var limit int
+
+	c := &cobra.Command{
+		Use:   "show REV",
+		Short: "Print a past bitrise.yml revision",
+		Long: `Print a bitrise.yml revision listed by 'yml history'.
+
+REV is a revision ID, or any unique prefix of at least 4 characters. The
+revision is looked up among the same recent builds 'yml history' scans.
+
+Required:
+  --app ID      app ID (or BITRISE_APP_ID env var)
+
+Arguments:
+  REV           revision ID from 'yml history'`,
+		Example: `  bitrise-cli yml show 3f9a1c2b7d4e --app my-app-id
+  bitrise-cli yml show 3f9a --app my-app-id > old-bitrise.yml`,
+		Args: cmdutil.RequireArgs("REV"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := cmdutil.NewAPIClient(cmd)
+			if err != nil {
+				return err
+			}
+			appSlug, err := cmdutil.ResolveAndLookupAppSlug(cmd, client)
+			if err != nil {
+				return err
+			}
+			format := cmdutil.ResolveFormat(cmd)
+			result, err := internalyml.NewService(client).Show(cmd.Context(), appSlug, args[0], limit)
+			if err != nil {
+				return err
+			}
+			return output.Render(cmd.OutOrStdout(), format, result, renderShowText)
+		},
+	}
+
+	addHistoryLimitFlag(c, &limit)
+	return c
+}
+
+func renderShowText(w io.Writer, r internalyml.ShowResult) error {
+	_, err := fmt.Fprint(w, r.Content)
+	return err
+}
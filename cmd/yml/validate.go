@@ -3,6 +3,7 @@ package yml
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -27,6 +28,10 @@ When --app is provided (or BITRISE_APP_ID is set), validation uses
 app-specific settings (available stacks, machine types, license pools).
 Without an app ID, only the schema is validated.
 
+A modular bitrise.yml is merged with its local includes first (see
+'bitrise-cli yml merge'), and the merged result is validated. Line numbers in
+the reported errors point into the file each line came from.
+
 Exit codes:
   0   valid (no errors; warnings do not affect the exit code)
   1   invalid (at least one error)`,
@@ -57,7 +62,8 @@ Exit codes:
 			}
 
 			svc := internalyml.NewService(client)
-			result, err := svc.Validate(cmd.Context(), string(rawYAML), appSlug)
+			name, dir := inputSource(filePath)
+			result, err := svc.ValidateModular(cmd.Context(), name, rawYAML, dir, appSlug)
 			if err != nil {
 				return err
 			}
@@ -88,6 +94,9 @@ func renderValidateText(w io.Writer, r internalyml.ValidateResult) error {
 	} else {
 		ew.Ln(s.Failure.Render("✗") + " bitrise.yml is invalid")
 	}
+	if len(r.Files) > 0 {
+		ew.F("  %s\n", s.Dim.Render(fmt.Sprintf("merged from %d files: %s", len(r.Files), strings.Join(r.Files, ", "))))
+	}
 
 	for _, e := range r.Errors {
 		ew.F("  %s %s\n", s.Failure.Render("Error:"), e)
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// FileRequest is one request of an `api --from-file` request file, with every
+// {{variable}} already substituted.
+type FileRequest struct {
+	Name    string // text after the "###" separator, if any
+	Line    int    // line of the request line, for diagnostics
+	Method  string
+	Path    string // endpoint path or absolute URL, as in Request.Path
+	Headers http.Header
+	Body    []byte
+}
+
+// Label names r in progress output and errors: its name, else its request
+// line.
+func (r FileRequest) Label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Method + " " + r.Path
+}
+
+var (
+	fileVarRe  = regexp.MustCompile(`^@([A-Za-z_][A-Za-z0-9_.-]*)\s*=\s*(.*)$`)
+	varRefRe   = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*}}`)
+	httpMethod = regexp.MustCompile(`^(GET|HEAD|POST|PUT|PATCH|DELETE|OPTIONS)$`)
+)
+
+// ParseHTTPFile reads a request file in the format of the common editor REST
+// clients (.http / .rest):
+//
+//	@app = 1a2b3c
+//
+//	### Latest builds
+//	GET /apps/{{app}}/builds?limit=5
+//
+//	### Abort one
+//	POST /apps/{{app}}/builds/{{build}}/abort
+//	Content-Type: application/json
+//
+//	{"abort_reason": "replayed"}
+//
+// Requests are separated by lines starting with "###". Each has a request
+// line (METHOD PATH, optionally followed by an HTTP version; a bare PATH
+// means GET), then headers up to the first blank line, then the body. Lines
+// starting with "#" or "//" outside a body are comments. "@name = value"
+// lines define variables for the requests after them; vars (from --var)
+// override those. A reference to an undefined variable is an error.
+func ParseHTTPFile(r io.Reader, vars map[string]string) ([]FileRequest, error) {
+	fileVars := map[string]string{}
+	lookup := func(name string) (string, bool) {
+		if v, ok := vars[name]; ok {
+			return v, true
+		}
+		v, ok := fileVars[name]
+		return v, ok
+	}
+
+	var (
+		out     []FileRequest
+		cur     *FileRequest
+		name    string
+		inBody  bool
+		body    []string
+		lineNum int
+	)
+	finish := func() error {
+		if cur == nil {
+			return nil
+		}
+		text := strings.TrimRight(strings.Join(body, "\n"), "\n\t ")
+		text, err := substitute(text, lookup, cur.Line)
+		if err != nil {
+			return err
+		}
+		if text != "" {
+			cur.Body = []byte(text)
+		}
+		out = append(out, *cur)
+		cur, inBody, body = nil, false, nil
+		return nil
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		lineNum++
+		line := strings.TrimRight(sc.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "###") {
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			name = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			continue
+		}
+		if inBody {
+			body = append(body, line)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		switch {
+		case cur == nil && trimmed == "":
+		case cur == nil && fileVarRe.MatchString(trimmed):
+			m := fileVarRe.FindStringSubmatch(trimmed)
+			v, err := substitute(strings.TrimSpace(m[2]), lookup, lineNum)
+			if err != nil {
+				return nil, err
+			}
+			fileVars[m[1]] = v
+		case cur == nil:
+			req, err := parseRequestLine(trimmed, lookup, lineNum)
+			if err != nil {
+				return nil, err
+			}
+			req.Name = name
+			cur, name = &req, ""
+		case trimmed == "":
+			inBody = true
+		default:
+			k, v, ok := strings.Cut(trimmed, ":")
+			if !ok || strings.TrimSpace(k) == "" {
+				return nil, fmt.Errorf("line %d: invalid header %q: expected 'Name: value'", lineNum, trimmed)
+			}
+			v, err := substitute(strings.TrimSpace(v), lookup, lineNum)
+			if err != nil {
+				return nil, err
+			}
+			cur.Headers.Add(strings.TrimSpace(k), v)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read request file: %w", err)
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no requests found")
+	}
+	return out, nil
+}
+
+func parseRequestLine(line string, lookup func(string) (string, bool), lineNum int) (FileRequest, error) {
+	parts := strings.Fields(line)
+	method := http.MethodGet
+	if httpMethod.MatchString(strings.ToUpper(parts[0])) {
+		method, parts = strings.ToUpper(parts[0]), parts[1:]
+	}
+	if n := len(parts); n > 0 && strings.HasPrefix(parts[n-1], "HTTP/") {
+		parts = parts[:n-1]
+	}
+	if len(parts) != 1 {
+		return FileRequest{}, fmt.Errorf("line %d: invalid request line %q: expected 'METHOD PATH'", lineNum, line)
+	}
+	path, err := substitute(parts[0], lookup, lineNum)
+	if err != nil {
+		return FileRequest{}, err
+	}
+	return FileRequest{Line: lineNum, Method: method, Path: path, Headers: http.Header{}}, nil
+}
+
+// substitute replaces every {{name}} in s.
+func substitute(s string, lookup func(string) (string, bool), lineNum int) (string, error) {
+	var missing []string
+	out := varRefRe.ReplaceAllStringFunc(s, func(ref string) string {
+		name := varRefRe.FindStringSubmatch(ref)[1]
+		v, ok := lookup(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("line %d: undefined variable %q (define it with @%s = … or --var %s=…)", lineNum, missing[0], missing[0], missing[0])
+	}
+	return out, nil
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseHTTPFile(t *testing.T) {
+	const file = `# Replays a build's lifecycle.
+@app = file-app
+@base = /apps/{{app}}
+
+### Latest builds
+GET {{base}}/builds?limit=5 HTTP/1.1
+Accept: application/json
+
+###
+// a bare path is a GET
+/me
+
+### Abort
+post {{base}}/builds/{{build}}/abort
+Content-Type: application/json
+
+{
+  "abort_reason": "{{reason}}"
+}
+
+`
+	reqs, err := ParseHTTPFile(strings.NewReader(file), map[string]string{"app": "flag-app", "build": "b1", "reason": "replayed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 3 {
+		t.Fatalf("parsed %d requests, want 3: %+v", len(reqs), reqs)
+	}
+
+	if r := reqs[0]; r.Name != "Latest builds" || r.Method != http.MethodGet || r.Path != "/apps/flag-app/builds?limit=5" ||
+		r.Headers.Get("Accept") != "application/json" || r.Body != nil || r.Line != 6 {
+		t.Errorf("request 1 = %+v", r)
+	}
+	if r := reqs[1]; r.Label() != "GET /me" {
+		t.Errorf("request 2 label = %q, want GET /me", r.Label())
+	}
+	if r := reqs[2]; r.Method != http.MethodPost || r.Path != "/apps/flag-app/builds/b1/abort" ||
+		string(r.Body) != "{\n  \"abort_reason\": \"replayed\"\n}" {
+		t.Errorf("request 3 = %+v (body %q)", r, r.Body)
+	}
+}
+
+func TestParseHTTPFile_Errors(t *testing.T) {
+	for name, tc := range map[string]struct{ file, want string }{
+		"undefined variable": {"GET /apps/{{app}}", `line 1: undefined variable "app"`},
+		"bad request line":   {"GET /a /b", "line 1: invalid request line"},
+		"bad header":         {"GET /a\nnot a header", "line 2: invalid header"},
+		"empty":              {"# nothing\n", "no requests found"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseHTTPFile(strings.NewReader(tc.file), nil)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 // Package api holds the business-logic layer for the raw API passthrough
 // command. It assembles a user-directed request (method, path, fields,
-// headers, body) and, optionally, follows cursor pagination — leaving all HTTP
-// transport to the bitriseapi client and all formatting to the cmd layer.
+// headers, body) against either the v0.1 API or the RDE API and, optionally,
+// follows cursor pagination — leaving all HTTP transport to the bitriseapi
+// clients and all formatting to the cmd layer. httpfile.go parses the
+// REST-client style request files `api --from-file` replays.
 package api
 
 import (
@@ -14,6 +16,7 @@ import (
 	"net/url"
 
 	"github.com/bitrise-io/bitrise-cli/bitriseapi"
+	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
 )
 
 // KeyValue is a single -f/--field pair.
@@ -30,24 +33,81 @@ type Request struct {
 	Headers  http.Header // -H/--header
 	Body     io.Reader   // --input body; mutually exclusive with Fields
 	Paginate bool        // --all
+	// PageLimit caps the pages Paginate fetches (--paginate-limit); 0 means
+	// no limit.
+	PageLimit int
 }
 
 // Response is the result of a raw API call. When Paginate is set, Body is the
-// merged "data" arrays of every page.
+// merged list of every page, {"data":[…]} (v0.1 API only).
 type Response struct {
 	StatusCode int
 	Header     http.Header
 	Body       []byte
+	// Truncated reports that PageLimit stopped pagination before the last
+	// page.
+	Truncated bool
+	// Err is the API's error for a non-2xx RDE response, parsed the way the
+	// typed RDE commands report it. Nil for the v0.1 API, whose errors the
+	// caller reports from StatusCode.
+	Err error
 }
 
+// rawClient is the slice of bitriseapi.Client / rdeapi.Client the Service
+// uses, with the client-specific response type flattened into Response.
+type rawClient interface {
+	rawRequest(ctx context.Context, method, path string, query url.Values, header http.Header, body io.Reader) (Response, error)
+	// paginator returns the pagination scheme of the client's API, nil
+	// when it has none the CLI knows.
+	paginator() paginator
+}
+
+type v01Client struct{ c *bitriseapi.Client }
+
+func (v v01Client) rawRequest(ctx context.Context, method, path string, query url.Values, header http.Header, body io.Reader) (Response, error) {
+	resp, err := v.c.RawRequest(ctx, method, path, query, header, body)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: resp.Body}, nil
+}
+
+func (v01Client) paginator() paginator { return v01Pages{} }
+
+type rdeClient struct{ c *rdeapi.Client }
+
+func (r rdeClient) rawRequest(ctx context.Context, method, path string, query url.Values, header http.Header, body io.Reader) (Response, error) {
+	resp, err := r.c.RawRequest(ctx, method, path, query, header, body)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: resp.Body, Err: resp.Err()}, nil
+}
+
+// The RDE API's list responses carry no page cursor (see
+// bitriseapi/rde), so there is nothing for --all to follow.
+func (rdeClient) paginator() paginator { return nil }
+
 // Service performs raw, user-directed Bitrise API requests.
 type Service struct {
-	client *bitriseapi.Client
+	client rawClient
 }
 
-// NewService returns a Service backed by client.
+// NewService returns a Service for the v0.1 API backed by client.
 func NewService(client *bitriseapi.Client) *Service {
-	return &Service{client: client}
+	if client == nil {
+		return &Service{}
+	}
+	return &Service{client: v01Client{client}}
+}
+
+// NewRDEService returns a Service for the Remote Dev Environments API backed
+// by client (`api --service rde`).
+func NewRDEService(client *rdeapi.Client) *Service {
+	if client == nil {
+		return &Service{}
+	}
+	return &Service{client: rdeClient{client}}
 }
 
 // Do performs the request described by req. Fields become query parameters for
@@ -93,74 +153,100 @@ func (s *Service) Do(ctx context.Context, req Request) (Response, error) {
 		if !isGet {
 			return Response{}, fmt.Errorf("--all is only supported for GET requests")
 		}
-		return s.paginate(ctx, req.Path, query, header)
+		if s.client.paginator() == nil {
+			return Response{}, fmt.Errorf("--all isn't supported with --service rde: the RDE API's lists have no page cursor")
+		}
+		return s.paginate(ctx, req.Path, query, header, req.PageLimit)
 	}
 
-	resp, err := s.client.RawRequest(ctx, req.Method, req.Path, query, header, body)
-	if err != nil {
-		return Response{}, err
-	}
-	return Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: resp.Body}, nil
+	return s.client.rawRequest(ctx, req.Method, req.Path, query, header, body)
+}
+
+// paginator is one API's cursor pagination scheme.
+type paginator interface {
+	// param is the query parameter carrying the cursor of the next page.
+	param() string
+	// page splits a response body into its list field, the field's items,
+	// and the next page's cursor ("" on the last page). ok is false when the
+	// body is not a page of a list.
+	page(body []byte) (field string, items []json.RawMessage, next string, ok bool)
 }
 
-// pagedBody is the minimal envelope shape needed to follow cursor pagination.
-type pagedBody struct {
-	Data   json.RawMessage `json:"data"`
-	Paging struct {
-		Next string `json:"next"`
-	} `json:"paging"`
+// v01Pages is the v0.1 API's {"data":[…],"paging":{"next":"…"}} envelope,
+// continued with ?next=.
+type v01Pages struct{}
+
+func (v01Pages) param() string { return "next" }
+
+func (v01Pages) page(body []byte) (string, []json.RawMessage, string, bool) {
+	var pb struct {
+		Data   json.RawMessage `json:"data"`
+		Paging struct {
+			Next string `json:"next"`
+		} `json:"paging"`
+	}
+	var items []json.RawMessage
+	if json.Unmarshal(body, &pb) != nil || len(pb.Data) == 0 || json.Unmarshal(pb.Data, &items) != nil {
+		return "", nil, "", false
+	}
+	return "data", items, pb.Paging.Next, true
 }
 
-// paginate walks every page of a list endpoint, following paging.next, and
-// returns a single {"data":[…]} merging each page's items. If the first
-// response is non-2xx or isn't a paged envelope with an array "data" field, it
-// is returned unchanged — so --all is a safe no-op on non-list endpoints.
-func (s *Service) paginate(ctx context.Context, path string, query url.Values, header http.Header) (Response, error) {
+// paginate walks the pages of a list endpoint, following the API's cursor,
+// and returns a single object holding every page's items under the list
+// field — {"data":[…]} for the v0.1 API. If the first response is non-2xx
+// or isn't a page of a list, it is returned unchanged — so --all is a safe
+// no-op on non-list endpoints. A positive limit stops after that many
+// pages and marks the response Truncated if more were left.
+func (s *Service) paginate(ctx context.Context, path string, query url.Values, header http.Header, limit int) (Response, error) {
+	pg := s.client.paginator()
 	items := []json.RawMessage{}
-	var last *bitriseapi.RawResponse
-	cursor := ""
-	for {
+	var (
+		last      Response
+		field     string
+		cursor    string
+		truncated bool
+	)
+	for pages := 0; ; pages++ {
+		if limit > 0 && pages == limit {
+			truncated = true
+			break
+		}
 		q := cloneValues(query)
 		if cursor != "" {
-			q.Set("next", cursor)
+			q.Set(pg.param(), cursor)
 		}
-		resp, err := s.client.RawRequest(ctx, http.MethodGet, path, q, header, nil)
+		resp, err := s.client.rawRequest(ctx, http.MethodGet, path, q, header, nil)
 		if err != nil {
 			return Response{}, err
 		}
 		last = resp
 
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-			return asResponse(resp), nil
+			return resp, nil
 		}
-		var pb pagedBody
-		var pageItems []json.RawMessage
-		if json.Unmarshal(resp.Body, &pb) != nil || len(pb.Data) == 0 || json.Unmarshal(pb.Data, &pageItems) != nil {
-			// Not a paged list envelope. On the first page, pass it through
+		f, pageItems, next, ok := pg.page(resp.Body)
+		if !ok {
+			// Not a page of a list. On the first page, pass it through
 			// untouched; mid-pagination this shouldn't happen, so stop.
 			if cursor == "" {
-				return asResponse(resp), nil
+				return resp, nil
 			}
 			break
 		}
+		field = f
 		items = append(items, pageItems...)
-		if pb.Paging.Next == "" {
+		if next == "" {
 			break
 		}
-		cursor = pb.Paging.Next
+		cursor = next
 	}
 
-	merged, err := json.Marshal(struct {
-		Data []json.RawMessage `json:"data"`
-	}{Data: items})
+	merged, err := json.Marshal(map[string][]json.RawMessage{field: items})
 	if err != nil {
 		return Response{}, fmt.Errorf("merge pages: %w", err)
 	}
-	return Response{StatusCode: last.StatusCode, Header: last.Header, Body: merged}, nil
-}
-
-func asResponse(r *bitriseapi.RawResponse) Response {
-	return Response{StatusCode: r.StatusCode, Header: r.Header, Body: r.Body}
+	return Response{StatusCode: last.StatusCode, Header: last.Header, Body: merged, Truncated: truncated}, nil
 }
 
 // cloneHeader returns a non-nil copy of h.
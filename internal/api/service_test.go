@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/bitrise-io/bitrise-cli/bitriseapi"
+	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
 )
 
 func TestDo_GETFieldsBecomeQuery(t *testing.T) {
@@ -220,3 +221,28 @@ func TestDo_PaginateStopsOnErrorPage(t *testing.T) {
 		t.Errorf("StatusCode = %d, want 403", resp.StatusCode)
 	}
 }
+
+func TestDo_RDEPaginateRejected(t *testing.T) {
+	svc := NewRDEService(rdeapi.New("http://unused", "tok"))
+	_, err := svc.Do(context.Background(), Request{Method: http.MethodGet, Path: "/v1/workspaces/w/sessions", Paginate: true})
+	if err == nil || !strings.Contains(err.Error(), "--service rde") {
+		t.Errorf("err = %v, want --all rejected for the RDE API", err)
+	}
+}
+
+func TestDo_RDEErrorIsParsed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":5,"message":"session not found"}`))
+	}))
+	t.Cleanup(srv.Close)
+	svc := NewRDEService(rdeapi.New(srv.URL, "tok"))
+
+	resp, err := svc.Do(context.Background(), Request{Method: http.MethodGet, Path: "/v1/sessions/x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound || resp.Err == nil || !strings.Contains(resp.Err.Error(), "session not found") {
+		t.Errorf("status %d, Err %v; want 404 with the API message", resp.StatusCode, resp.Err)
+	}
+}
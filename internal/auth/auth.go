@@ -1,9 +1,14 @@
 // Package auth persists and reads the Bitrise access token.
 //
-// Storage: YAML at $XDG_CONFIG_HOME/bitrise/auth.yaml, falling back to
-// ~/.config/bitrise/auth.yaml. Per the patterns guide, credentials live
-// in their own file (separate from preferences in config.yaml) and at
-// 0600 permissions. OS-keychain integration is intentionally deferred.
+// Storage: by default YAML at $XDG_CONFIG_HOME/bitrise/auth.yaml, falling
+// back to ~/.config/bitrise/auth.yaml. Per the patterns guide, credentials
+// live in their own file (separate from preferences in config.yaml) and at
+// 0600 permissions. The credential_store setting swaps that plaintext file
+// for the OS keychain or a passphrase-encrypted file; see Store.
+//
+// The credentials hold one set per profile: the default profile at the top
+// level and named profiles (e.g. a client's workspace, or staging) under
+// profiles.
 //
 // The Bitrise API accepts both Personal Access Tokens (user-scoped) and
 // Workspace API Tokens (workspace-scoped); they have identical wire format
@@ -13,15 +18,14 @@
 package auth
 
 import (
-	"errors"
 	"fmt"
-	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
-
-	"gopkg.in/yaml.v3"
 )
 
 // Auth is the on-disk shape of auth.yaml.
@@ -77,62 +81,141 @@ func Path() (string, error) {
 	return filepath.Join(base, "bitrise", "auth.yaml"), nil
 }
 
-// Load reads the auth file. A missing file returns the zero Auth so
-// first-time users don't see failures.
-func Load() (Auth, error) {
-	p, err := Path()
-	if err != nil {
-		return Auth{}, err
+// DefaultProfile names the profile stored at the top level of auth.yaml
+// (and config.yaml): the single account older versions knew about.
+const DefaultProfile = "default"
+
+// profileNameRe restricts profile names to something safe to type, show in
+// tables, and use as a YAML key.
+var profileNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateProfileName reports whether name can be used as a profile name.
+func ValidateProfileName(name string) error {
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '.', '_' and '-'", name)
 	}
-	data, err := os.ReadFile(p) //nolint:gosec // p is derived from XDG_CONFIG_HOME / user home, not user input
-	if errors.Is(err, fs.ErrNotExist) {
-		return Auth{}, nil
+	return nil
+}
+
+// IsDefaultProfile reports whether name refers to the default profile; the
+// empty name does too.
+func IsDefaultProfile(name string) bool {
+	return name == "" || name == DefaultProfile
+}
+
+// File is the on-disk shape of auth.yaml: the default profile's credentials
+// at the top level, so single-account files from older versions load
+// unchanged, and named profiles under profiles.
+type File struct {
+	Auth     `yaml:",inline"`
+	Profiles map[string]Auth `yaml:"profiles,omitempty"`
+}
+
+// Profile returns the credentials stored for the named profile (zero if none).
+func (f File) Profile(name string) Auth {
+	if IsDefaultProfile(name) {
+		return f.Auth
 	}
-	if err != nil {
-		return Auth{}, fmt.Errorf("read %s: %w", p, err)
+	return f.Profiles[name]
+}
+
+// Names lists the profiles that hold a token, the default profile first and
+// the rest sorted.
+func (f File) Names() []string {
+	var names []string
+	if f.Token != "" {
+		names = append(names, DefaultProfile)
 	}
-	var a Auth
-	if err := yaml.Unmarshal(data, &a); err != nil {
-		return Auth{}, fmt.Errorf("parse %s: %w", p, err)
+	named := slices.Sorted(maps.Keys(f.Profiles))
+	for _, n := range named {
+		if f.Profiles[n].Token != "" {
+			names = append(names, n)
+		}
 	}
-	return a, nil
+	return names
 }
 
-// Save atomically writes a to disk with 0600 permissions, creating the
-// parent directory (0700) if needed.
-func Save(a Auth) error {
-	if a.Token == "" {
-		return fmt.Errorf("refusing to save auth with empty token")
+func (f File) isZero() bool {
+	return f.Auth == (Auth{}) && len(f.Profiles) == 0
+}
+
+func (f *File) set(name string, a Auth) {
+	if IsDefaultProfile(name) {
+		f.Auth = a
+		return
 	}
-	p, err := Path()
-	if err != nil {
-		return err
+	if a == (Auth{}) {
+		delete(f.Profiles, name)
+		return
 	}
-	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
-		return fmt.Errorf("create config dir: %w", err)
+	if f.Profiles == nil {
+		f.Profiles = map[string]Auth{}
 	}
-	data, err := yaml.Marshal(&a) //nolint:gosec // G117: auth.yaml intentionally persists OAuth material (PAT/JWT/refresh token) — that's the file's purpose; it's written 0600
+	f.Profiles[name] = a
+}
+
+// LoadFile reads every profile's credentials from the active Store (see
+// UseStore). Nothing stored yet returns the zero File so first-time users
+// don't see failures.
+func LoadFile() (File, error) {
+	return current().Load()
+}
+
+// Load reads the default profile's credentials. A missing file returns the
+// zero Auth so first-time users don't see failures.
+func Load() (Auth, error) {
+	return LoadProfile(DefaultProfile)
+}
+
+// LoadProfile reads the named profile's credentials (zero if it has none).
+func LoadProfile(name string) (Auth, error) {
+	f, err := LoadFile()
 	if err != nil {
-		return fmt.Errorf("marshal auth: %w", err)
+		return Auth{}, err
+	}
+	return f.Profile(name), nil
+}
+
+// Save stores a as the default profile's credentials. See SaveProfile.
+func Save(a Auth) error {
+	return SaveProfile(DefaultProfile, a)
+}
+
+// SaveProfile stores a as the named profile's credentials, leaving other
+// profiles untouched.
+func SaveProfile(name string, a Auth) error {
+	if a.Token == "" {
+		return fmt.Errorf("refusing to save auth with empty token")
 	}
-	tmp := p + ".tmp"
-	if err := os.WriteFile(tmp, data, 0o600); err != nil {
-		return fmt.Errorf("write %s: %w", tmp, err)
+	if !IsDefaultProfile(name) {
+		if err := ValidateProfileName(name); err != nil {
+			return err
+		}
 	}
-	if err := os.Rename(tmp, p); err != nil {
-		return fmt.Errorf("install %s: %w", p, err)
+	f, err := LoadFile()
+	if err != nil {
+		return err
 	}
-	return nil
+	f.set(name, a)
+	return current().Save(f)
 }
 
-// Clear removes the auth file. A non-existent file is not an error.
+// Clear removes the default profile's credentials. See ClearProfile.
 func Clear() error {
-	p, err := Path()
+	return ClearProfile(DefaultProfile)
+}
+
+// ClearProfile removes the named profile's credentials. The stored
+// credentials are removed entirely once no profile holds any. Nothing stored
+// for the profile is not an error.
+func ClearProfile(name string) error {
+	f, err := LoadFile()
 	if err != nil {
 		return err
 	}
-	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
-		return fmt.Errorf("remove %s: %w", p, err)
+	f.set(name, Auth{})
+	if f.isZero() {
+		return current().Clear()
 	}
-	return nil
+	return current().Save(f)
 }
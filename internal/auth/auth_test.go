@@ -196,3 +196,48 @@ func TestSave_OverwritesExisting(t *testing.T) {
 		t.Fatalf("expected overwrite, got %q", got.Token)
 	}
 }
+
+func TestSaveLoadClearProfile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	def := Auth{Token: "default-pat"}
+	staging := Auth{Token: "staging-pat", RefreshToken: "r"}
+	if err := Save(def); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := SaveProfile("staging", staging); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	if got, _ := Load(); got != def {
+		t.Fatalf("default profile = %+v, want %+v", got, def)
+	}
+	if got, _ := LoadProfile("staging"); got != staging {
+		t.Fatalf("staging profile = %+v, want %+v", got, staging)
+	}
+	f, err := LoadFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := strings.Join(f.Names(), ","); names != "default,staging" {
+		t.Fatalf("Names = %q", names)
+	}
+
+	// Clearing the default profile keeps the others (and the file).
+	if err := Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := LoadProfile("staging"); got != staging {
+		t.Fatalf("staging lost after Clear: %+v", got)
+	}
+	if err := ClearProfile("staging"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bitrise", "auth.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("auth.yaml should be removed once empty, stat err = %v", err)
+	}
+
+	if err := SaveProfile("bad name", staging); err == nil {
+		t.Fatal("expected an invalid profile name to be rejected")
+	}
+}
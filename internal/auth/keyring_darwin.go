@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// securityNotFound is the exit status of security(1) when no item matches.
+const securityNotFound = 44
+
+// macKeyring stores items in the login keychain through security(1), as
+// generic passwords under keychainService.
+type macKeyring struct{}
+
+func newOSKeyring() keyring { return macKeyring{} }
+
+func (macKeyring) get(account string) (string, error) {
+	out, err := runSecurity(nil, "find-generic-password", "-s", keychainService, "-a", account, "-w")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+// set feeds the command through `security -i` on stdin so the secret never
+// shows up in the process list.
+func (macKeyring) set(account, secret string) error {
+	cmd := fmt.Sprintf("add-generic-password -U -s %q -a %q -l %q -w %q\n",
+		keychainService, account, "Bitrise CLI ("+account+")", secret)
+	_, err := runSecurity(strings.NewReader(cmd), "-i")
+	return err
+}
+
+func (macKeyring) delete(account string) error {
+	_, err := runSecurity(nil, "delete-generic-password", "-s", keychainService, "-a", account)
+	return err
+}
+
+func runSecurity(stdin *strings.Reader, args ...string) (string, error) {
+	cmd := exec.Command("/usr/bin/security", args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := errors.AsType[*exec.ExitError](err); ok && exitErr.ExitCode() == securityNotFound {
+			return "", errKeyringNotFound
+		}
+		return "", fmt.Errorf("macOS keychain: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.String(), nil
+}
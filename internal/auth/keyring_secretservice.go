@@ -0,0 +1,71 @@
+//go:build !darwin && !windows
+
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// secretServiceKeyring stores items through the freedesktop Secret Service
+// D-Bus API (GNOME Keyring, KWallet, KeePassXC) using secret-tool(1) from
+// libsecret, with the attributes service=keychainService and account.
+type secretServiceKeyring struct{}
+
+func newOSKeyring() keyring { return secretServiceKeyring{} }
+
+func (secretServiceKeyring) get(account string) (string, error) {
+	out, stderr, err := runSecretTool(nil, "lookup", "service", keychainService, "account", account)
+	if err != nil {
+		// lookup exits 1 without a message when nothing matches.
+		if exitErr, ok := errors.AsType[*exec.ExitError](err); ok && exitErr.ExitCode() == 1 && stderr == "" {
+			return "", errKeyringNotFound
+		}
+		return "", secretToolErr(stderr, err)
+	}
+	return out, nil
+}
+
+// set passes the secret on stdin so it never shows up in the process list.
+func (secretServiceKeyring) set(account, secret string) error {
+	_, stderr, err := runSecretTool(strings.NewReader(secret),
+		"store", "--label", "Bitrise CLI ("+account+")", "service", keychainService, "account", account)
+	if err != nil {
+		return secretToolErr(stderr, err)
+	}
+	return nil
+}
+
+// delete is idempotent: clear succeeds when nothing matches.
+func (secretServiceKeyring) delete(account string) error {
+	_, stderr, err := runSecretTool(nil, "clear", "service", keychainService, "account", account)
+	if err != nil {
+		return secretToolErr(stderr, err)
+	}
+	return nil
+}
+
+func runSecretTool(stdin *strings.Reader, args ...string) (string, string, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return "", "", errors.New("secret-tool not found: install libsecret-tools (Debian/Ubuntu) or libsecret (Fedora/Arch), or use credential_store encrypted-file on hosts without a desktop keyring")
+	}
+	cmd := exec.Command(path, args...) //nolint:gosec // fixed binary found on PATH; args are constants and profile names
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err = cmd.Run()
+	return stdout.String(), strings.TrimSpace(stderr.String()), err
+}
+
+func secretToolErr(stderr string, err error) error {
+	if stderr != "" {
+		return fmt.Errorf("secret service: %s: %w", stderr, err)
+	}
+	return fmt.Errorf("secret service: %w", err)
+}
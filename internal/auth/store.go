@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Credential store kinds, the values of the credential_store config key.
+const (
+	// StoreFile is the plaintext auth.yaml, the default.
+	StoreFile = "file"
+	// StoreKeychain is the OS credential manager: the macOS Keychain, the
+	// Windows Credential Manager, or the freedesktop Secret Service (GNOME
+	// Keyring, KWallet) elsewhere.
+	StoreKeychain = "keychain"
+	// StoreEncryptedFile is auth.enc, encrypted with a passphrase — for hosts
+	// without a usable keychain, such as headless Linux boxes.
+	StoreEncryptedFile = "encrypted-file"
+)
+
+// StoreKinds lists the valid credential_store values.
+var StoreKinds = []string{StoreFile, StoreKeychain, StoreEncryptedFile}
+
+// ParseStoreKind validates a credential_store value; empty means StoreFile.
+func ParseStoreKind(s string) (string, error) {
+	switch s {
+	case "":
+		return StoreFile, nil
+	case StoreFile, StoreKeychain, StoreEncryptedFile:
+		return s, nil
+	default:
+		return "", fmt.Errorf("invalid credential store %q (valid: %s)", s, strings.Join(StoreKinds, ", "))
+	}
+}
+
+// Store persists the credentials of every profile. Load returns the zero
+// File when nothing is stored; Clear removes everything and is idempotent.
+type Store interface {
+	Kind() string
+	Load() (File, error)
+	Save(f File) error
+	Clear() error
+}
+
+// StoreOptions carries what a store may need from the user at runtime.
+type StoreOptions struct {
+	// Passphrase returns the encrypted-file passphrase; create is true when
+	// the file doesn't exist yet, so a prompt can ask for confirmation. It is
+	// called lazily, at most once per process.
+	Passphrase func(create bool) (string, error)
+}
+
+// OpenStore returns the Store for kind (see ParseStoreKind).
+func OpenStore(kind string, opts StoreOptions) (Store, error) {
+	kind, err := ParseStoreKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case StoreKeychain:
+		return &keychainStore{kr: newOSKeyring()}, nil
+	case StoreEncryptedFile:
+		if opts.Passphrase == nil {
+			return nil, errors.New("encrypted credential store needs a passphrase source")
+		}
+		return &encryptedFileStore{passphrase: opts.Passphrase}, nil
+	default:
+		return fileStore{}, nil
+	}
+}
+
+// OpenStoreLazy is OpenStore deferred until the store is first loaded,
+// saved or cleared, so a process that never touches credentials never
+// reaches the keychain or asks for a passphrase. An unknown kind still fails
+// at once.
+func OpenStoreLazy(kind string, opts StoreOptions) (Store, error) {
+	kind, err := ParseStoreKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	return &lazyStore{kind: kind, open: func() (Store, error) { return OpenStore(kind, opts) }}, nil
+}
+
+// lazyStore opens its Store on first use.
+type lazyStore struct {
+	kind string
+	open func() (Store, error)
+
+	once  sync.Once
+	store Store
+	err   error
+}
+
+func (l *lazyStore) Kind() string { return l.kind }
+
+func (l *lazyStore) get() (Store, error) {
+	l.once.Do(func() { l.store, l.err = l.open() })
+	return l.store, l.err
+}
+
+func (l *lazyStore) Load() (File, error) {
+	s, err := l.get()
+	if err != nil {
+		return File{}, err
+	}
+	return s.Load()
+}
+
+func (l *lazyStore) Save(f File) error {
+	s, err := l.get()
+	if err != nil {
+		return err
+	}
+	return s.Save(f)
+}
+
+func (l *lazyStore) Clear() error {
+	s, err := l.get()
+	if err != nil {
+		return err
+	}
+	return s.Clear()
+}
+
+var (
+	storeMu     sync.Mutex
+	activeStore Store = fileStore{}
+)
+
+// UseStore makes s the store every Load/Save/Clear function goes through.
+// The cmd layer calls it once per invocation from the resolved
+// credential_store; until then the plaintext file store is used.
+func UseStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	activeStore = s
+}
+
+// ActiveStore returns the store set by UseStore.
+func ActiveStore() Store {
+	return current()
+}
+
+func current() Store {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return activeStore
+}
+
+// Migrate copies every profile's credentials from one store to another,
+// checks the copy reads back identically, and only then clears the source.
+// It returns the names of the profiles moved; an empty source moves nothing.
+func Migrate(from, to Store) ([]string, error) {
+	f, err := from.Load()
+	if err != nil {
+		return nil, fmt.Errorf("read %s store: %w", from.Kind(), err)
+	}
+	if f.isZero() {
+		return nil, nil
+	}
+	if err := to.Save(f); err != nil {
+		return nil, fmt.Errorf("write %s store: %w", to.Kind(), err)
+	}
+	got, err := to.Load()
+	if err != nil {
+		return nil, fmt.Errorf("verify %s store: %w", to.Kind(), err)
+	}
+	if !sameFile(f, got) {
+		return nil, fmt.Errorf("verify %s store: credentials read back differ from those written; %s store left untouched", to.Kind(), from.Kind())
+	}
+	if err := from.Clear(); err != nil {
+		return nil, fmt.Errorf("clear %s store: %w", from.Kind(), err)
+	}
+	return f.Names(), nil
+}
+
+// sameFile compares two Files by value; times are compared with Equal since
+// a round trip through YAML may change their location.
+func sameFile(a, b File) bool {
+	if !sameAuth(a.Auth, b.Auth) || len(a.Profiles) != len(b.Profiles) {
+		return false
+	}
+	for name, pa := range a.Profiles {
+		pb, ok := b.Profiles[name]
+		if !ok || !sameAuth(pa, pb) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameAuth(a, b Auth) bool {
+	return a.Token == b.Token && a.JWT == b.JWT && a.RefreshToken == b.RefreshToken &&
+		a.TokenExpiry.Equal(b.TokenExpiry) && a.JWTExpiry.Equal(b.JWTExpiry) &&
+		a.RefreshTokenExpiry.Equal(b.RefreshTokenExpiry)
+}
+
+// fileStore is the plaintext auth.yaml.
+type fileStore struct{}
+
+func (fileStore) Kind() string { return StoreFile }
+
+func (fileStore) Load() (File, error) {
+	p, err := Path()
+	if err != nil {
+		return File{}, err
+	}
+	data, err := os.ReadFile(p) //nolint:gosec // p is derived from XDG_CONFIG_HOME / user home, not user input
+	if errors.Is(err, fs.ErrNotExist) {
+		return File{}, nil
+	}
+	if err != nil {
+		return File{}, fmt.Errorf("read %s: %w", p, err)
+	}
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return File{}, fmt.Errorf("parse %s: %w", p, err)
+	}
+	return f, nil
+}
+
+// Save writes the file atomically with 0600 permissions, creating the
+// parent directory (0700) if needed.
+func (fileStore) Save(f File) error {
+	p, err := Path()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(&f) //nolint:gosec // G117: auth.yaml intentionally persists OAuth material (PAT/JWT/refresh token) — that's the file's purpose; it's written 0600
+	if err != nil {
+		return fmt.Errorf("marshal auth: %w", err)
+	}
+	return writePrivateFile(p, data)
+}
+
+func (fileStore) Clear() error {
+	p, err := Path()
+	if err != nil {
+		return err
+	}
+	return removeIfExists(p)
+}
+
+// writePrivateFile atomically writes data to p with 0600 permissions.
+func writePrivateFile(p string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("install %s: %w", p, err)
+	}
+	return nil
+}
+
+func removeIfExists(p string) error {
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove %s: %w", p, err)
+	}
+	return nil
+}
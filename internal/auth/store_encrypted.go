@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// encryptedMagic prefixes auth.enc and versions its layout:
+// magic | salt | nonce | AES-256-GCM(auth.yaml contents).
+var encryptedMagic = []byte("BRAUTH1\n")
+
+const (
+	encryptedSaltSize = 16
+	// scrypt cost parameters: ~100ms per key derivation on a laptop, paid
+	// once per process.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// EncryptedPath returns the absolute path to the encrypted credentials file
+// (whether or not it exists).
+func EncryptedPath() (string, error) {
+	p, err := Path()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(p), "auth.enc"), nil
+}
+
+// encryptedFileStore keeps the auth.yaml contents in auth.enc, sealed with
+// AES-256-GCM under a key derived from the user's passphrase with scrypt.
+type encryptedFileStore struct {
+	passphrase func(create bool) (string, error)
+
+	// salt and key are cached after the first derivation so a process asks
+	// for the passphrase at most once.
+	salt []byte
+	key  []byte
+}
+
+func (*encryptedFileStore) Kind() string { return StoreEncryptedFile }
+
+func (s *encryptedFileStore) Load() (File, error) {
+	p, err := EncryptedPath()
+	if err != nil {
+		return File{}, err
+	}
+	data, err := os.ReadFile(p) //nolint:gosec // p is derived from XDG_CONFIG_HOME / user home, not user input
+	if errors.Is(err, fs.ErrNotExist) {
+		return File{}, nil
+	}
+	if err != nil {
+		return File{}, fmt.Errorf("read %s: %w", p, err)
+	}
+	header := len(encryptedMagic) + encryptedSaltSize
+	if len(data) < header || !bytes.Equal(data[:len(encryptedMagic)], encryptedMagic) {
+		return File{}, fmt.Errorf("read %s: not an encrypted bitrise-cli credentials file", p)
+	}
+	if err := s.deriveKey(data[len(encryptedMagic):header], false); err != nil {
+		return File{}, err
+	}
+	gcm, err := newGCM(s.key)
+	if err != nil {
+		return File{}, err
+	}
+	rest := data[header:]
+	if len(rest) < gcm.NonceSize() {
+		return File{}, fmt.Errorf("read %s: file is truncated", p)
+	}
+	plain, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], encryptedMagic)
+	if err != nil {
+		return File{}, fmt.Errorf("decrypt %s: wrong passphrase or corrupted file", p)
+	}
+	var f File
+	if err := yaml.Unmarshal(plain, &f); err != nil {
+		return File{}, fmt.Errorf("parse %s: %w", p, err)
+	}
+	return f, nil
+}
+
+func (s *encryptedFileStore) Save(f File) error {
+	p, err := EncryptedPath()
+	if err != nil {
+		return err
+	}
+	if s.key == nil {
+		// Reuse the salt of an existing file so the passphrase is checked
+		// against it, rather than silently re-keying with a typo.
+		if _, err := s.Load(); err != nil {
+			return err
+		}
+		if s.key == nil {
+			salt := make([]byte, encryptedSaltSize)
+			if _, err := rand.Read(salt); err != nil {
+				return err
+			}
+			if err := s.deriveKey(salt, true); err != nil {
+				return err
+			}
+		}
+	}
+	plain, err := yaml.Marshal(&f) //nolint:gosec // G117: serialized only to be encrypted below
+	if err != nil {
+		return fmt.Errorf("marshal auth: %w", err)
+	}
+	gcm, err := newGCM(s.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	out := bytes.Join([][]byte{encryptedMagic, s.salt, nonce, gcm.Seal(nil, nonce, plain, encryptedMagic)}, nil)
+	return writePrivateFile(p, out)
+}
+
+func (*encryptedFileStore) Clear() error {
+	p, err := EncryptedPath()
+	if err != nil {
+		return err
+	}
+	return removeIfExists(p)
+}
+
+func (s *encryptedFileStore) deriveKey(salt []byte, create bool) error {
+	if s.key != nil && bytes.Equal(s.salt, salt) {
+		return nil
+	}
+	pass, err := s.passphrase(create)
+	if err != nil {
+		return err
+	}
+	if pass == "" {
+		return errors.New("passphrase is empty")
+	}
+	key, err := scrypt.Key([]byte(pass), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return fmt.Errorf("derive key: %w", err)
+	}
+	s.salt, s.key = bytes.Clone(salt), key
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
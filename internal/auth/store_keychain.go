@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keychainService is the service name every keychain item is filed under.
+const keychainService = "bitrise-cli"
+
+// keychainIndex is the item listing which profiles have an item of their own.
+const keychainIndex = "profiles"
+
+// errKeyringNotFound is returned by keyring.get when no item exists.
+var errKeyringNotFound = errors.New("keyring item not found")
+
+// keyring is the minimal secret storage an OS credential manager offers:
+// one opaque string per account under keychainService.
+type keyring interface {
+	get(account string) (string, error)
+	set(account, secret string) error
+	delete(account string) error
+}
+
+// keychainStore keeps each profile's credentials in its own keychain item,
+// plus an index item naming them. Items are split per profile because the
+// Windows Credential Manager caps a secret at 2560 bytes — enough for one
+// OAuth profile, not for several.
+type keychainStore struct {
+	kr keyring
+}
+
+func (*keychainStore) Kind() string { return StoreKeychain }
+
+func profileAccount(name string) string { return "profile:" + name }
+
+func (s *keychainStore) names() ([]string, error) {
+	idx, err := s.kr.get(keychainIndex)
+	if errors.Is(err, errKeyringNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(idx), nil
+}
+
+func (s *keychainStore) Load() (File, error) {
+	names, err := s.names()
+	if err != nil {
+		return File{}, err
+	}
+	var f File
+	for _, name := range names {
+		secret, err := s.kr.get(profileAccount(name))
+		if errors.Is(err, errKeyringNotFound) {
+			continue
+		}
+		if err != nil {
+			return File{}, err
+		}
+		a, err := decodeKeychainAuth(secret)
+		if err != nil {
+			return File{}, fmt.Errorf("keychain item %q: %w", profileAccount(name), err)
+		}
+		f.set(name, a)
+	}
+	return f, nil
+}
+
+func (s *keychainStore) Save(f File) error {
+	old, err := s.names()
+	if err != nil {
+		return err
+	}
+	var names []string
+	if f.Auth != (Auth{}) {
+		names = append(names, DefaultProfile)
+	}
+	for name, a := range f.Profiles {
+		if a != (Auth{}) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		secret, err := encodeKeychainAuth(f.Profile(name))
+		if err != nil {
+			return err
+		}
+		if err := s.kr.set(profileAccount(name), secret); err != nil {
+			return err
+		}
+	}
+	// Write the index after the items so a failure midway never lists a
+	// profile whose item is missing.
+	if err := s.kr.set(keychainIndex, strings.Join(names, " ")); err != nil {
+		return err
+	}
+	for _, name := range old {
+		if !slices.Contains(names, name) {
+			if err := s.kr.delete(profileAccount(name)); err != nil && !errors.Is(err, errKeyringNotFound) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *keychainStore) Clear() error {
+	names, err := s.names()
+	if err != nil {
+		return err
+	}
+	// Items first, index last, mirroring Save.
+	for _, account := range append(profileAccounts(names), keychainIndex) {
+		if err := s.kr.delete(account); err != nil && !errors.Is(err, errKeyringNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func profileAccounts(names []string) []string {
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = profileAccount(n)
+	}
+	return out
+}
+
+// encodeKeychainAuth serializes a as base64 YAML: base64 keeps the secret
+// a single shell-safe token for the command-line keychain tools.
+func encodeKeychainAuth(a Auth) (string, error) {
+	data, err := yaml.Marshal(&a) //nolint:gosec // G117: serialized only to be handed to the OS keychain
+	if err != nil {
+		return "", fmt.Errorf("marshal auth: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func decodeKeychainAuth(secret string) (Auth, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(secret))
+	if err != nil {
+		return Auth{}, fmt.Errorf("decode: %w", err)
+	}
+	var a Auth
+	if err := yaml.Unmarshal(data, &a); err != nil {
+		return Auth{}, fmt.Errorf("parse: %w", err)
+	}
+	return a, nil
+}
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memKeyring is an in-memory keyring standing in for the OS one.
+type memKeyring map[string]string
+
+func (m memKeyring) get(account string) (string, error) {
+	v, ok := m[account]
+	if !ok {
+		return "", errKeyringNotFound
+	}
+	return v, nil
+}
+
+func (m memKeyring) set(account, secret string) error {
+	m[account] = secret
+	return nil
+}
+
+func (m memKeyring) delete(account string) error {
+	if _, ok := m[account]; !ok {
+		return errKeyringNotFound
+	}
+	delete(m, account)
+	return nil
+}
+
+// useStore swaps the active store for the duration of a test.
+func useStore(t *testing.T, s Store) {
+	t.Helper()
+	prev := ActiveStore()
+	UseStore(s)
+	t.Cleanup(func() { UseStore(prev) })
+}
+
+func fixedPassphrase(pass string) func(bool) (string, error) {
+	return func(bool) (string, error) { return pass, nil }
+}
+
+func TestParseStoreKind(t *testing.T) {
+	for in, want := range map[string]string{"": StoreFile, "file": StoreFile, "keychain": StoreKeychain, "encrypted-file": StoreEncryptedFile} {
+		if got, err := ParseStoreKind(in); err != nil || got != want {
+			t.Errorf("ParseStoreKind(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseStoreKind("vault"); err == nil {
+		t.Error("expected an unknown store to be rejected")
+	}
+}
+
+func TestKeychainStore_ProfilesRoundTrip(t *testing.T) {
+	kr := memKeyring{}
+	useStore(t, &keychainStore{kr: kr})
+
+	expiry := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	staging := Auth{Token: "stg", RefreshToken: "r", TokenExpiry: expiry}
+	if err := Save(Auth{Token: "def"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := SaveProfile("staging", staging); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	if kr[keychainIndex] != "default staging" {
+		t.Fatalf("index = %q", kr[keychainIndex])
+	}
+	got, err := LoadProfile("staging")
+	if err != nil || !sameAuth(got, staging) {
+		t.Fatalf("LoadProfile = %+v, %v; want %+v", got, err, staging)
+	}
+
+	if err := Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := kr[profileAccount(DefaultProfile)]; ok {
+		t.Fatal("default item left behind after Clear")
+	}
+	if err := ClearProfile("staging"); err != nil {
+		t.Fatal(err)
+	}
+	if len(kr) != 0 {
+		t.Fatalf("keyring not emptied: %v", kr)
+	}
+}
+
+func TestEncryptedFileStore_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	useStore(t, &encryptedFileStore{passphrase: fixedPassphrase("correct horse")})
+
+	if err := SaveProfile("staging", Auth{Token: "bitpat_secret"}); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "bitrise", "auth.enc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("bitpat_secret")) {
+		t.Fatal("auth.enc holds the token in the clear")
+	}
+
+	// A fresh store (a new process) decrypts with the same passphrase...
+	useStore(t, &encryptedFileStore{passphrase: fixedPassphrase("correct horse")})
+	if a, err := LoadProfile("staging"); err != nil || a.Token != "bitpat_secret" {
+		t.Fatalf("LoadProfile = %+v, %v", a, err)
+	}
+	// ...and refuses a wrong one.
+	useStore(t, &encryptedFileStore{passphrase: fixedPassphrase("wrong")})
+	if _, err := LoadProfile("staging"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Fatalf("err = %v, want wrong passphrase", err)
+	}
+	if err := SaveProfile("other", Auth{Token: "x"}); err == nil {
+		t.Fatal("Save with a wrong passphrase must not re-key the file")
+	}
+}
+
+func TestOpenStoreLazy_OpensOnFirstUse(t *testing.T) {
+	if _, err := OpenStoreLazy("vault", StoreOptions{}); err == nil {
+		t.Error("expected an unknown store to be rejected up front")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	asked := 0
+	s, err := OpenStoreLazy(StoreEncryptedFile, StoreOptions{Passphrase: func(bool) (string, error) {
+		asked++
+		return "correct horse", nil
+	}})
+	if err != nil {
+		t.Fatalf("OpenStoreLazy: %v", err)
+	}
+	useStore(t, s)
+	if s.Kind() != StoreEncryptedFile || asked != 0 {
+		t.Fatalf("kind %q, asked %d times before any use", s.Kind(), asked)
+	}
+	if err := SaveProfile("default", Auth{Token: "bitpat_secret"}); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	if a, err := LoadProfile("default"); err != nil || a.Token != "bitpat_secret" {
+		t.Fatalf("LoadProfile = %+v, %v", a, err)
+	}
+	if asked != 1 {
+		t.Errorf("passphrase asked %d times, want once", asked)
+	}
+}
+
+func TestMigrate_FileToKeychain(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := Save(Auth{Token: "def"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveProfile("staging", Auth{Token: "stg", TokenExpiry: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	kc := &keychainStore{kr: memKeyring{}}
+	moved, err := Migrate(fileStore{}, kc)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if strings.Join(moved, ",") != "default,staging" {
+		t.Fatalf("moved = %v", moved)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bitrise", "auth.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("plaintext auth.yaml should be removed after migrating, stat err = %v", err)
+	}
+	f, err := kc.Load()
+	if err != nil || f.Profile("staging").Token != "stg" || f.Token != "def" {
+		t.Fatalf("keychain after migrate = %+v, %v", f, err)
+	}
+
+	// Nothing left to move is not an error.
+	if moved, err := Migrate(fileStore{}, kc); err != nil || len(moved) != 0 {
+		t.Fatalf("second Migrate = %v, %v", moved, err)
+	}
+}
@@ -15,10 +15,13 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/bitrise-io/bitrise-cli/internal/auth"
 	"github.com/bitrise-io/bitrise-cli/internal/output"
 	"github.com/bitrise-io/bitrise-cli/internal/output/style"
 )
@@ -33,21 +36,79 @@ const (
 	KeyRDEAPIBaseURL      = "rde_api_base_url"
 	KeyWebBaseURL         = "web_base_url"
 	KeyTheme              = "theme"
+	KeyCredentialStore    = "credential_store"
+	KeyHTTPRetries        = "http_retries"
+	KeyHTTPRetryMaxWait   = "http_retry_max_wait"
 )
 
 // Keys is the registered list of config keys, used for validation and help.
-var Keys = []string{KeyOutput, KeyAppID, KeyDefaultWorkspaceID, KeyAPIBaseURL, KeyRDEAPIBaseURL, KeyWebBaseURL, KeyTheme}
+var Keys = []string{KeyOutput, KeyAppID, KeyDefaultWorkspaceID, KeyAPIBaseURL, KeyRDEAPIBaseURL, KeyWebBaseURL, KeyTheme, KeyCredentialStore, KeyHTTPRetries, KeyHTTPRetryMaxWait}
+
+// ProfileScoped reports whether key can differ per profile. The credential
+// store is machine-wide: it holds every profile's token.
+func ProfileScoped(key string) bool {
+	return key != KeyCredentialStore
+}
 
 // Config is the on-disk shape. Fields use omitempty so unset values
 // don't appear in the saved YAML.
+//
+// The top-level account settings (app, workspace, base URLs) belong to the
+// default profile; named profiles keep their own under profiles. Profile
+// selects the active one: `config use-profile` sets it in the global file,
+// and a per-directory file may pin one for a project.
 type Config struct {
-	Output             string `yaml:"output,omitempty"`
-	AppID              string `yaml:"app_id,omitempty"`
-	DefaultWorkspaceID string `yaml:"default_workspace_id,omitempty"`
-	APIBaseURL         string `yaml:"api_base_url,omitempty"`
-	RDEAPIBaseURL      string `yaml:"rde_api_base_url,omitempty"`
-	WebBaseURL         string `yaml:"web_base_url,omitempty"`
-	Theme              string `yaml:"theme,omitempty"`
+	Output             string            `yaml:"output,omitempty"`
+	AppID              string            `yaml:"app_id,omitempty"`
+	DefaultWorkspaceID string            `yaml:"default_workspace_id,omitempty"`
+	APIBaseURL         string            `yaml:"api_base_url,omitempty"`
+	RDEAPIBaseURL      string            `yaml:"rde_api_base_url,omitempty"`
+	WebBaseURL         string            `yaml:"web_base_url,omitempty"`
+	Theme              string            `yaml:"theme,omitempty"`
+	CredentialStore    string            `yaml:"credential_store,omitempty"`
+	HTTPRetries        string            `yaml:"http_retries,omitempty"`
+	HTTPRetryMaxWait   string            `yaml:"http_retry_max_wait,omitempty"`
+	Profile            string            `yaml:"profile,omitempty"`
+	Profiles           map[string]Config `yaml:"profiles,omitempty"`
+}
+
+// ProfileConfig returns the settings stored for the named profile: the top
+// level for the default profile (without the profiles themselves), the
+// profile's section otherwise.
+func (c Config) ProfileConfig(name string) Config {
+	if auth.IsDefaultProfile(name) {
+		c.Profile = ""
+		c.Profiles = nil
+		return c
+	}
+	return c.Profiles[name]
+}
+
+// SetProfileConfig replaces the named profile's settings with pc, the
+// inverse of ProfileConfig. An empty named profile is dropped.
+func (c *Config) SetProfileConfig(name string, pc Config) {
+	if auth.IsDefaultProfile(name) {
+		pc.Profile, pc.Profiles = c.Profile, c.Profiles
+		*c = pc
+		return
+	}
+	if pc.isZero() {
+		delete(c.Profiles, name)
+		if len(c.Profiles) == 0 {
+			c.Profiles = nil
+		}
+		return
+	}
+	if c.Profiles == nil {
+		c.Profiles = map[string]Config{}
+	}
+	c.Profiles[name] = pc
+}
+
+func (c Config) isZero() bool {
+	return c.Output == "" && c.AppID == "" && c.DefaultWorkspaceID == "" && c.APIBaseURL == "" &&
+		c.RDEAPIBaseURL == "" && c.WebBaseURL == "" && c.Theme == "" && c.CredentialStore == "" && c.HTTPRetries == "" && c.HTTPRetryMaxWait == "" &&
+		c.Profile == "" && len(c.Profiles) == 0
 }
 
 // UnmarshalYAML reads a Config, accepting the legacy key names `app_slug` and
@@ -59,15 +120,20 @@ type Config struct {
 func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 	// NOTE: new Config fields must be added here too (two places: the struct and the copy block below).
 	var raw struct {
-		Output               string `yaml:"output"`
-		AppID                string `yaml:"app_id"`
-		AppSlugLegacy        string `yaml:"app_slug"`
-		DefaultWorkspaceID   string `yaml:"default_workspace_id"`
-		DefaultWorkspaceSlug string `yaml:"default_workspace_slug"`
-		APIBaseURL           string `yaml:"api_base_url"`
-		RDEAPIBaseURL        string `yaml:"rde_api_base_url"`
-		WebBaseURL           string `yaml:"web_base_url"`
-		Theme                string `yaml:"theme"`
+		Output               string            `yaml:"output"`
+		AppID                string            `yaml:"app_id"`
+		AppSlugLegacy        string            `yaml:"app_slug"`
+		DefaultWorkspaceID   string            `yaml:"default_workspace_id"`
+		DefaultWorkspaceSlug string            `yaml:"default_workspace_slug"`
+		APIBaseURL           string            `yaml:"api_base_url"`
+		RDEAPIBaseURL        string            `yaml:"rde_api_base_url"`
+		WebBaseURL           string            `yaml:"web_base_url"`
+		Theme                string            `yaml:"theme"`
+		CredentialStore      string            `yaml:"credential_store"`
+		HTTPRetries          string            `yaml:"http_retries"`
+		HTTPRetryMaxWait     string            `yaml:"http_retry_max_wait"`
+		Profile              string            `yaml:"profile"`
+		Profiles             map[string]Config `yaml:"profiles"`
 	}
 	if err := value.Decode(&raw); err != nil {
 		return err
@@ -80,6 +146,11 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 	c.RDEAPIBaseURL = raw.RDEAPIBaseURL
 	c.WebBaseURL = raw.WebBaseURL
 	c.Theme = raw.Theme
+	c.CredentialStore = raw.CredentialStore
+	c.HTTPRetries = raw.HTTPRetries
+	c.HTTPRetryMaxWait = raw.HTTPRetryMaxWait
+	c.Profile = raw.Profile
+	c.Profiles = raw.Profiles
 	return nil
 }
 
@@ -115,6 +186,15 @@ func Path() (string, error) {
 	return filepath.Join(dir, "config.yaml"), nil
 }
 
+// CacheDir returns the directory of the on-disk HTTP cache, under Dir.
+func CacheDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache", "http"), nil
+}
+
 // Load reads and validates the config file. A missing file is not an error —
 // it returns the zero Config so first-time users don't see failures.
 func Load() (Config, error) {
@@ -163,6 +243,12 @@ func loadDirFrom(start string) (Config, string, error) {
 			if err := c.Validate(); err != nil {
 				return Config{}, "", fmt.Errorf("invalid %s: %w", p, err)
 			}
+			if len(c.Profiles) > 0 {
+				return Config{}, "", fmt.Errorf("invalid %s: profiles are defined in the global config; pin one with %q", p, "profile: NAME")
+			}
+			if c.CredentialStore != "" {
+				return Config{}, "", fmt.Errorf("invalid %s: %q is a machine-wide setting; set it in the global config", p, KeyCredentialStore)
+			}
 			return c, p, nil
 		}
 		if !errors.Is(err, fs.ErrNotExist) {
@@ -233,6 +319,43 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("field %q: %w", KeyTheme, err)
 		}
 	}
+	if c.CredentialStore != "" {
+		if _, err := auth.ParseStoreKind(c.CredentialStore); err != nil {
+			return fmt.Errorf("field %q: %w", KeyCredentialStore, err)
+		}
+	}
+	if c.HTTPRetries != "" {
+		if _, err := ParseHTTPRetries(c.HTTPRetries); err != nil {
+			return fmt.Errorf("field %q: %w", KeyHTTPRetries, err)
+		}
+	}
+	if c.HTTPRetryMaxWait != "" {
+		if _, err := ParseHTTPRetryMaxWait(c.HTTPRetryMaxWait); err != nil {
+			return fmt.Errorf("field %q: %w", KeyHTTPRetryMaxWait, err)
+		}
+	}
+	if c.Profile != "" && !auth.IsDefaultProfile(c.Profile) {
+		if err := auth.ValidateProfileName(c.Profile); err != nil {
+			return fmt.Errorf("field %q: %w", "profile", err)
+		}
+	}
+	for name, pc := range c.Profiles {
+		if auth.IsDefaultProfile(name) {
+			return fmt.Errorf("profile %q: the default profile's settings live at the top level", name)
+		}
+		if err := auth.ValidateProfileName(name); err != nil {
+			return err
+		}
+		if pc.Profile != "" || len(pc.Profiles) > 0 {
+			return fmt.Errorf("profile %q: profiles cannot be nested", name)
+		}
+		if pc.CredentialStore != "" {
+			return fmt.Errorf("profile %q: %q applies to all profiles; set it at the top level", name, KeyCredentialStore)
+		}
+		if err := pc.Validate(); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
 	return nil
 }
 
@@ -253,6 +376,12 @@ func (c *Config) Get(key string) (string, error) {
 		return c.WebBaseURL, nil
 	case KeyTheme:
 		return c.Theme, nil
+	case KeyCredentialStore:
+		return c.CredentialStore, nil
+	case KeyHTTPRetries:
+		return c.HTTPRetries, nil
+	case KeyHTTPRetryMaxWait:
+		return c.HTTPRetryMaxWait, nil
 	default:
 		return "", unknownKeyErr(key)
 	}
@@ -277,6 +406,12 @@ func (c *Config) Set(key, value string) error {
 		next.WebBaseURL = value
 	case KeyTheme:
 		next.Theme = value
+	case KeyCredentialStore:
+		next.CredentialStore = value
+	case KeyHTTPRetries:
+		next.HTTPRetries = value
+	case KeyHTTPRetryMaxWait:
+		next.HTTPRetryMaxWait = value
 	default:
 		return unknownKeyErr(key)
 	}
@@ -292,6 +427,30 @@ func (c *Config) Unset(key string) error {
 	return c.Set(key, "")
 }
 
+// maxHTTPRetries keeps a typo like "100" from turning one failing request
+// into minutes of waiting.
+const maxHTTPRetries = 10
+
+// ParseHTTPRetries parses an http_retries value: how many times a failed
+// API request is retried, 0 to disable.
+func ParseHTTPRetries(v string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || n < 0 || n > maxHTTPRetries {
+		return 0, fmt.Errorf("invalid retry count %q: want a whole number from 0 to %d", v, maxHTTPRetries)
+	}
+	return n, nil
+}
+
+// ParseHTTPRetryMaxWait parses an http_retry_max_wait value: the longest
+// single wait between retries, as a Go duration such as "30s".
+func ParseHTTPRetryMaxWait(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(v))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid wait %q: want a positive duration such as 30s", v)
+	}
+	return d, nil
+}
+
 func unknownKeyErr(key string) error {
 	return fmt.Errorf("unknown config key %q (valid keys: %s)", key, strings.Join(Keys, ", "))
 }
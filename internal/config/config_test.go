@@ -3,9 +3,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/internal/auth"
 )
 
 func TestPath_HonorsXDG(t *testing.T) {
@@ -15,7 +18,7 @@ func TestPath_HonorsXDG(t *testing.T) {
 		t.Fatalf("Path: %v", err)
 	}
 	want := filepath.Join("/custom/xdg", "bitrise", "config.yaml")
-	if got != want {
+	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("Path = %q, want %q", got, want)
 	}
 }
@@ -41,7 +44,7 @@ func TestValidate(t *testing.T) {
 		{"valid output", Config{Output: "json"}, false},
 		{"valid url", Config{APIBaseURL: "https://api.example.com"}, false},
 		{"all set", Config{Output: "human", APIBaseURL: "https://x", AppID: "s", Theme: "dark"}, false},
-		{"bad output", Config{Output: "yaml"}, true},
+		{"bad output", Config{Output: "xml"}, true},
 		{"bad url no scheme", Config{APIBaseURL: "api.example.com"}, true},
 		{"bad url empty host", Config{APIBaseURL: "https://"}, true},
 		{"valid theme auto", Config{Theme: "auto"}, false},
@@ -49,6 +52,14 @@ func TestValidate(t *testing.T) {
 		{"valid theme light", Config{Theme: "light"}, false},
 		{"valid theme none", Config{Theme: "none"}, false},
 		{"bad theme", Config{Theme: "neon"}, true},
+		{"valid profile", Config{Profile: "staging", Profiles: map[string]Config{"staging": {AppID: "s"}}}, false},
+		{"bad profile name", Config{Profile: "has space"}, true},
+		{"default in profiles", Config{Profiles: map[string]Config{"default": {AppID: "s"}}}, true},
+		{"nested profiles", Config{Profiles: map[string]Config{"a": {Profile: "b"}}}, true},
+		{"bad value in profile", Config{Profiles: map[string]Config{"a": {Output: "xml"}}}, true},
+		{"valid credential store", Config{CredentialStore: "keychain"}, false},
+		{"bad credential store", Config{CredentialStore: "vault"}, true},
+		{"credential store in profile", Config{Profiles: map[string]Config{"a": {CredentialStore: "keychain"}}}, true},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -83,6 +94,8 @@ func TestGetSetUnset(t *testing.T) {
 		KeyRDEAPIBaseURL:      "https://api.example.com/rde",
 		KeyWebBaseURL:         "https://app.example.com",
 		KeyTheme:              "light",
+		KeyHTTPRetries:        "5",
+		KeyHTTPRetryMaxWait:   "45s",
 	}
 	for k, v := range values {
 		if err := c.Set(k, v); err != nil {
@@ -99,13 +112,19 @@ func TestGetSetUnset(t *testing.T) {
 
 	// Set with invalid value rolls back: Set is all-or-nothing.
 	prevOutput := c.Output
-	if err := c.Set(KeyOutput, "yaml"); err == nil {
-		t.Fatal("Set(output, yaml) should fail validation")
+	if err := c.Set(KeyOutput, "xml"); err == nil {
+		t.Fatal("Set(output, xml) should fail validation")
 	}
 	if c.Output != prevOutput {
 		t.Fatalf("after failed Set, Output = %q, want %q (rollback)", c.Output, prevOutput)
 	}
 
+	for k, v := range map[string]string{KeyHTTPRetries: "-1", KeyHTTPRetryMaxWait: "0s"} {
+		if err := c.Set(k, v); err == nil {
+			t.Errorf("Set(%q, %q) should fail validation", k, v)
+		}
+	}
+
 	// Unset clears the field.
 	if err := c.Unset(KeyAppID); err != nil {
 		t.Fatalf("Unset: %v", err)
@@ -132,7 +151,7 @@ func TestSaveLoad_RoundTrip(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
-	if got != want {
+	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("round-trip mismatch:\n got %+v\nwant %+v", got, want)
 	}
 
@@ -163,6 +182,9 @@ func TestUnmarshalYAML_AllFieldsPreserved(t *testing.T) {
 		RDEAPIBaseURL:      "https://rde.example.com",
 		WebBaseURL:         "https://web.example.com",
 		Theme:              "dark",
+		CredentialStore:    "encrypted-file",
+		Profile:            "staging",
+		Profiles:           map[string]Config{"staging": {AppID: "app-789", APIBaseURL: "https://api.staging.example.com"}},
 	}
 	if err := Save(want); err != nil {
 		t.Fatalf("Save: %v", err)
@@ -171,7 +193,7 @@ func TestUnmarshalYAML_AllFieldsPreserved(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
-	if got != want {
+	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("not all fields survived round-trip:\n got  %+v\nwant %+v", got, want)
 	}
 }
@@ -182,7 +204,7 @@ func TestLoad_MissingFileIsZeroValue(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Load: %v", err)
 	}
-	if got != (Config{}) {
+	if !reflect.DeepEqual(got, Config{}) {
 		t.Fatalf("expected zero Config, got %+v", got)
 	}
 }
@@ -209,7 +231,7 @@ func TestLoad_RejectsInvalidContent(t *testing.T) {
 		t.Fatal(err)
 	}
 	p := filepath.Join(dir, "bitrise", "config.yaml")
-	if err := os.WriteFile(p, []byte("output: yaml\n"), 0o600); err != nil {
+	if err := os.WriteFile(p, []byte("output: xml\n"), 0o600); err != nil {
 		t.Fatal(err)
 	}
 	_, err := Load()
@@ -288,7 +310,46 @@ func TestLoadDir_NoFileReturnsZero(t *testing.T) {
 	if found != "" {
 		t.Fatalf("found = %q, want empty", found)
 	}
-	if got != (Config{}) {
+	if !reflect.DeepEqual(got, Config{}) {
 		t.Fatalf("got %+v, want zero", got)
 	}
 }
+
+func TestProfileConfig_SetRoundTrip(t *testing.T) {
+	c := Config{AppID: "top", Profile: "staging"}
+
+	staging := c.ProfileConfig("staging")
+	if err := staging.Set(KeyAppID, "stg"); err != nil {
+		t.Fatal(err)
+	}
+	c.SetProfileConfig("staging", staging)
+	if c.AppID != "top" || c.Profiles["staging"].AppID != "stg" {
+		t.Fatalf("named profile leaked into top level: %+v", c)
+	}
+
+	def := c.ProfileConfig(auth.DefaultProfile)
+	if def.Profile != "" || def.Profiles != nil || def.AppID != "top" {
+		t.Fatalf("default ProfileConfig = %+v, want top-level settings only", def)
+	}
+	def.AppID = "top2"
+	c.SetProfileConfig(auth.DefaultProfile, def)
+	if c.AppID != "top2" || c.Profile != "staging" || len(c.Profiles) != 1 {
+		t.Fatalf("default SetProfileConfig dropped profiles: %+v", c)
+	}
+
+	c.SetProfileConfig("staging", Config{})
+	if c.Profiles != nil {
+		t.Fatalf("emptied profile not dropped: %+v", c.Profiles)
+	}
+}
+
+func TestLoadDir_RejectsProfilesSection(t *testing.T) {
+	root := t.TempDir()
+	body := "profile: staging\nprofiles:\n  staging:\n    app_id: x\n"
+	if err := os.WriteFile(filepath.Join(root, DirFileName), []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := loadDirFrom(root); err == nil || !strings.Contains(err.Error(), "global config") {
+		t.Fatalf("err = %v, want profiles rejected in per-dir file", err)
+	}
+}
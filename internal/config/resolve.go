@@ -3,8 +3,10 @@ package config
 import (
 	"context"
 	"os"
+	"time"
 
 	"github.com/bitrise-io/bitrise-cli/internal/auth"
+	"github.com/bitrise-io/bitrise-cli/internal/httpretry"
 	"github.com/bitrise-io/bitrise-cli/internal/output"
 	"github.com/bitrise-io/bitrise-cli/internal/output/style"
 )
@@ -20,6 +22,11 @@ const (
 	EnvRDEAPIBaseURL = "BITRISE_RDE_API_BASE_URL"
 	EnvWebBaseURL    = "BITRISE_WEB_BASE_URL"
 	EnvTheme         = "BITRISE_CLI_THEME"
+	EnvProfile       = "BITRISE_PROFILE"
+	// EnvCredentialStore overrides credential_store; EnvCredentialPassphrase
+	// supplies the encrypted-file passphrase non-interactively (CI, scripts).
+	EnvCredentialStore      = "BITRISE_CREDENTIAL_STORE"
+	EnvCredentialPassphrase = "BITRISE_CREDENTIAL_PASSPHRASE" //nolint:gosec // G101: env var name, not a credential
 	// EnvOAuthIssuer overrides the WorkOS AuthKit issuer (full URL) the OAuth
 	// login flow authorizes against; EnvOIDCTokenEndpoint overrides the
 	// monolith JWT→PAT exchange endpoint (full URL); EnvOAuthClientID overrides
@@ -29,6 +36,16 @@ const (
 	EnvOAuthIssuer       = "BITRISE_OAUTH_ISSUER"
 	EnvOIDCTokenEndpoint = "BITRISE_OIDC_TOKEN_ENDPOINT" //nolint:gosec // G101: env var name, not a credential
 	EnvOAuthClientID     = "BITRISE_OAUTH_CLIENT_ID"
+	// EnvHTTPRetries and EnvHTTPRetryMaxWait override the API retry policy;
+	// EnvDebug=api turns on --debug (any value but "0" or "false" does);
+	// EnvNoCache=1 turns on --no-cache the same way.
+	EnvHTTPRetries      = "BITRISE_HTTP_RETRIES"
+	EnvHTTPRetryMaxWait = "BITRISE_HTTP_RETRY_MAX_WAIT"
+	EnvDebug            = "BITRISE_DEBUG"
+	EnvNoCache          = "BITRISE_NO_CACHE"
+	// EnvStepLibAPIBaseURL overrides the GitHub API root the StepLib is read
+	// through (GitHub Enterprise mirrors, tests).
+	EnvStepLibAPIBaseURL = "BITRISE_STEPLIB_API_BASE_URL"
 )
 
 // DefaultAPIBaseURL is the production Bitrise API base URL.
@@ -39,6 +56,10 @@ const DefaultAPIBaseURL = "https://api.bitrise.io/v0.1"
 // https://api.bitrise.io/rde/api-docs/swagger.json.
 const DefaultRDEAPIBaseURL = "https://api.bitrise.io/rde"
 
+// DefaultStepLibAPIBaseURL is the GitHub REST API root the StepLib and step
+// releases are read from.
+const DefaultStepLibAPIBaseURL = "https://api.github.com"
+
 // DefaultWebBaseURL is the production Bitrise web app base URL.
 // Used by `user create` and `auth login --email` to drive the website's
 // signup and sign-in JSON endpoints.
@@ -72,12 +93,21 @@ const DefaultOAuthClientID = "https://app.bitrise.io/.well-known/oauth-client/cl
 //     per-command flags like --app are layered in the command handlers)
 //  2. Environment variables
 //  3. Per-directory config (.bitrise-cli.yml in CWD or ancestors)
-//  4. Global config file (~/.config/bitrise/config.yaml) — for non-secret keys
-//  5. auth.yaml (~/.config/bitrise/auth.yaml) — for the token only
+//  4. Active profile's section of the global config file
+//  5. Global config file (~/.config/bitrise/config.yaml) — for non-secret keys
 //  6. Built-in defaults
 //
-// Token resolution: env > auth.yaml.
+// Account settings (app, workspace, base URLs) skip layer 5 when a named
+// profile is active: the top level of the global file is the default
+// profile's account, and leaking it into another profile would point that
+// profile's token at the wrong app or host.
+//
+// Token holds BITRISE_TOKEN only. The saved token (the active profile's
+// entry in the credential store) is read by the cmd layer when a command
+// first needs one, so commands that don't never touch the store.
 type Resolved struct {
+	// Profile is the active profile name; DefaultProfile when none is selected.
+	Profile       string
 	Output        output.Format
 	AppSlug       string
 	OrgSlug       string
@@ -93,19 +123,61 @@ type Resolved struct {
 	OAuthIssuer       string
 	OIDCTokenEndpoint string
 	OAuthClientID     string
+	// StepLibAPIBaseURL is the GitHub API root `step view`, `step versions`
+	// and `step changelog` read the StepLib through.
+	StepLibAPIBaseURL string
 	Theme             style.Theme
+	// CredentialStore is the auth.Store kind holding the tokens.
+	CredentialStore string
+	// HTTPRetries and HTTPRetryMaxWait bound the API clients' retries of
+	// rate-limited and transiently failing requests.
+	HTTPRetries      int
+	HTTPRetryMaxWait time.Duration
+	// Debug turns on HTTP tracing and retry logging (--debug,
+	// BITRISE_DEBUG=api).
+	Debug bool
+	// CacheDir holds the on-disk HTTP cache; empty disables it. NoCache
+	// (--no-cache, BITRISE_NO_CACHE) skips cached answers for this run.
+	CacheDir string
+	NoCache  bool
+}
+
+// CredentialStoreKind picks the credential store: BITRISE_CREDENTIAL_STORE,
+// then credential_store in the global file, then auth.StoreFile. Per-dir
+// files are ignored — the store is a property of the machine, not the
+// project. It is resolved before Resolve because loading the token needs it.
+func CredentialStoreKind(globalCfg Config) (string, error) {
+	return auth.ParseStoreKind(firstNonEmpty(os.Getenv(EnvCredentialStore), globalCfg.CredentialStore))
+}
+
+// ActiveProfile picks the profile to use: the --profile flag, then
+// BITRISE_PROFILE, then a profile pinned by the per-directory config, then
+// the one selected with `config use-profile`, then DefaultProfile.
+func ActiveProfile(globalCfg, dirCfg Config, flagProfile string) string {
+	return firstNonEmpty(flagProfile, os.Getenv(EnvProfile), dirCfg.Profile, globalCfg.Profile, auth.DefaultProfile)
 }
 
-// Resolve merges global config, per-directory config, the auth file, and
-// environment variables with the persistent --output / --theme flag values.
-// flagOutput / flagTheme may be empty when unset. dirCfg / authData are zero
-// values when their respective files were not found.
-func Resolve(globalCfg, dirCfg Config, authData auth.Auth, flagOutput, flagTheme string) (Resolved, error) {
+// Resolve merges global config, per-directory config, and environment
+// variables with the persistent --output / --theme / --profile flag values.
+// The flag values may be empty when unset. dirCfg is the zero value when no
+// per-directory file was found. A profile that has no settings yet is not an
+// error, so `auth login --profile NEW` can create it.
+func Resolve(globalCfg, dirCfg Config, flagOutput, flagTheme, flagProfile string) (Resolved, error) {
 	var r Resolved
 
+	r.Profile = ActiveProfile(globalCfg, dirCfg, flagProfile)
+	if !auth.IsDefaultProfile(r.Profile) {
+		if err := auth.ValidateProfileName(r.Profile); err != nil {
+			return Resolved{}, err
+		}
+	}
+	// profileCfg carries the account settings; for the default profile it is
+	// the top level itself.
+	profileCfg := globalCfg.ProfileConfig(r.Profile)
+
 	rawOutput := flagOutput
 	if rawOutput == "" {
-		rawOutput = firstNonEmpty(os.Getenv(EnvOutput), dirCfg.Output, globalCfg.Output)
+		rawOutput = firstNonEmpty(os.Getenv(EnvOutput), dirCfg.Output, profileCfg.Output, globalCfg.Output)
 	}
 	f, err := output.ParseFormat(rawOutput)
 	if err != nil {
@@ -115,7 +187,7 @@ func Resolve(globalCfg, dirCfg Config, authData auth.Auth, flagOutput, flagTheme
 
 	rawTheme := flagTheme
 	if rawTheme == "" {
-		rawTheme = firstNonEmpty(os.Getenv(EnvTheme), dirCfg.Theme, globalCfg.Theme)
+		rawTheme = firstNonEmpty(os.Getenv(EnvTheme), dirCfg.Theme, profileCfg.Theme, globalCfg.Theme)
 	}
 	t, err := style.ParseTheme(rawTheme)
 	if err != nil {
@@ -123,23 +195,55 @@ func Resolve(globalCfg, dirCfg Config, authData auth.Auth, flagOutput, flagTheme
 	}
 	r.Theme = t
 
-	r.AppSlug = firstNonEmpty(os.Getenv(EnvAppSlug), os.Getenv(EnvAppSlugLegacy), dirCfg.AppID, globalCfg.AppID)
-	r.OrgSlug = firstNonEmpty(dirCfg.DefaultWorkspaceID, globalCfg.DefaultWorkspaceID)
+	r.AppSlug = firstNonEmpty(os.Getenv(EnvAppSlug), os.Getenv(EnvAppSlugLegacy), dirCfg.AppID, profileCfg.AppID)
+	r.OrgSlug = firstNonEmpty(dirCfg.DefaultWorkspaceID, profileCfg.DefaultWorkspaceID)
 	// WorkspaceID resolution: BITRISE_WORKSPACE_ID env, then fall back to the
 	// existing default_workspace_id — the RDE workspaceId is the same
 	// workspace identifier we already store (a slug on the wire).
 	r.WorkspaceID = firstNonEmpty(os.Getenv(EnvWorkspaceID), r.OrgSlug)
-	r.APIBaseURL = firstNonEmpty(os.Getenv(EnvAPIBaseURL), dirCfg.APIBaseURL, globalCfg.APIBaseURL, DefaultAPIBaseURL)
-	r.RDEAPIBaseURL = firstNonEmpty(os.Getenv(EnvRDEAPIBaseURL), dirCfg.RDEAPIBaseURL, globalCfg.RDEAPIBaseURL, DefaultRDEAPIBaseURL)
-	r.WebBaseURL = firstNonEmpty(os.Getenv(EnvWebBaseURL), dirCfg.WebBaseURL, globalCfg.WebBaseURL, DefaultWebBaseURL)
+	r.APIBaseURL = firstNonEmpty(os.Getenv(EnvAPIBaseURL), dirCfg.APIBaseURL, profileCfg.APIBaseURL, DefaultAPIBaseURL)
+	r.RDEAPIBaseURL = firstNonEmpty(os.Getenv(EnvRDEAPIBaseURL), dirCfg.RDEAPIBaseURL, profileCfg.RDEAPIBaseURL, DefaultRDEAPIBaseURL)
+	r.WebBaseURL = firstNonEmpty(os.Getenv(EnvWebBaseURL), dirCfg.WebBaseURL, profileCfg.WebBaseURL, DefaultWebBaseURL)
 	r.OAuthIssuer = firstNonEmpty(os.Getenv(EnvOAuthIssuer), DefaultOAuthIssuer)
 	r.OIDCTokenEndpoint = firstNonEmpty(os.Getenv(EnvOIDCTokenEndpoint), DefaultOIDCTokenEndpoint)
 	r.OAuthClientID = firstNonEmpty(os.Getenv(EnvOAuthClientID), DefaultOAuthClientID)
-	r.Token = firstNonEmpty(os.Getenv(EnvToken), authData.Token)
+	r.StepLibAPIBaseURL = firstNonEmpty(os.Getenv(EnvStepLibAPIBaseURL), DefaultStepLibAPIBaseURL)
+	r.Token = os.Getenv(EnvToken)
+	if r.CredentialStore, err = CredentialStoreKind(globalCfg); err != nil {
+		return Resolved{}, err
+	}
+
+	r.HTTPRetries = httpretry.DefaultMaxRetries
+	if v := firstNonEmpty(os.Getenv(EnvHTTPRetries), dirCfg.HTTPRetries, profileCfg.HTTPRetries, globalCfg.HTTPRetries); v != "" {
+		if r.HTTPRetries, err = ParseHTTPRetries(v); err != nil {
+			return Resolved{}, err
+		}
+	}
+	r.HTTPRetryMaxWait = httpretry.DefaultMaxWait
+	if v := firstNonEmpty(os.Getenv(EnvHTTPRetryMaxWait), dirCfg.HTTPRetryMaxWait, profileCfg.HTTPRetryMaxWait, globalCfg.HTTPRetryMaxWait); v != "" {
+		if r.HTTPRetryMaxWait, err = ParseHTTPRetryMaxWait(v); err != nil {
+			return Resolved{}, err
+		}
+	}
+	r.Debug = envFlag(EnvDebug)
+	r.NoCache = envFlag(EnvNoCache)
+	if r.CacheDir, err = CacheDir(); err != nil {
+		return Resolved{}, err
+	}
 
 	return r, nil
 }
 
+// envFlag reports whether the boolean env var name is on: any value but
+// empty, "0", or "false".
+func envFlag(name string) bool {
+	switch os.Getenv(name) {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, v := range values {
 		if v != "" {
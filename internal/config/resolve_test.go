@@ -1,9 +1,12 @@
 package config
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/bitrise-io/bitrise-cli/internal/auth"
+	"github.com/bitrise-io/bitrise-cli/internal/httpretry"
 	"github.com/bitrise-io/bitrise-cli/internal/output"
 	"github.com/bitrise-io/bitrise-cli/internal/output/style"
 )
@@ -21,14 +24,20 @@ func clearEnv(t *testing.T) {
 	t.Setenv(EnvRDEAPIBaseURL, "")
 	t.Setenv(EnvWebBaseURL, "")
 	t.Setenv(EnvTheme, "")
+	t.Setenv(EnvProfile, "")
+	t.Setenv(EnvCredentialStore, "")
 	t.Setenv(EnvOAuthIssuer, "")
 	t.Setenv(EnvOIDCTokenEndpoint, "")
 	t.Setenv(EnvOAuthClientID, "")
+	t.Setenv(EnvHTTPRetries, "")
+	t.Setenv(EnvHTTPRetryMaxWait, "")
+	t.Setenv(EnvDebug, "")
+	t.Setenv(EnvNoCache, "")
 }
 
 func TestResolve_DefaultsWhenNothingSet(t *testing.T) {
 	clearEnv(t)
-	r, err := Resolve(Config{}, Config{}, auth.Auth{}, "", "")
+	r, err := Resolve(Config{}, Config{}, "", "", "")
 	if err != nil {
 		t.Fatalf("Resolve: %v", err)
 	}
@@ -50,7 +59,7 @@ func TestResolve_OAuthDefaultsAndClientIDOverride(t *testing.T) {
 	clearEnv(t)
 
 	// With nothing set, the production OAuth values compile in.
-	r, err := Resolve(Config{}, Config{}, auth.Auth{}, "", "")
+	r, err := Resolve(Config{}, Config{}, "", "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -68,7 +77,7 @@ func TestResolve_OAuthDefaultsAndClientIDOverride(t *testing.T) {
 	// whose CIMD doc is served from its own host).
 	const staging = "https://app-staging.example/.well-known/oauth-client/cli"
 	t.Setenv(EnvOAuthClientID, staging)
-	r, err = Resolve(Config{}, Config{}, auth.Auth{}, "", "")
+	r, err = Resolve(Config{}, Config{}, "", "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -81,7 +90,7 @@ func TestResolve_WorkspaceIDFallsBackToOrgSlug(t *testing.T) {
 	clearEnv(t)
 
 	// With no env var, WorkspaceID falls back to default_workspace_id.
-	r, err := Resolve(Config{DefaultWorkspaceID: "acme"}, Config{}, auth.Auth{}, "", "")
+	r, err := Resolve(Config{DefaultWorkspaceID: "acme"}, Config{}, "", "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -91,7 +100,7 @@ func TestResolve_WorkspaceIDFallsBackToOrgSlug(t *testing.T) {
 
 	// BITRISE_WORKSPACE_ID wins over the org slug.
 	t.Setenv(EnvWorkspaceID, "ws-env")
-	r, err = Resolve(Config{DefaultWorkspaceID: "acme"}, Config{}, auth.Auth{}, "", "")
+	r, err = Resolve(Config{DefaultWorkspaceID: "acme"}, Config{}, "", "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -103,7 +112,7 @@ func TestResolve_WorkspaceIDFallsBackToOrgSlug(t *testing.T) {
 func TestResolve_RDEAPIBaseURLPrecedence(t *testing.T) {
 	clearEnv(t)
 
-	r, err := Resolve(Config{RDEAPIBaseURL: "https://global.rde"}, Config{RDEAPIBaseURL: "https://dir.rde"}, auth.Auth{}, "", "")
+	r, err := Resolve(Config{RDEAPIBaseURL: "https://global.rde"}, Config{RDEAPIBaseURL: "https://dir.rde"}, "", "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -112,7 +121,7 @@ func TestResolve_RDEAPIBaseURLPrecedence(t *testing.T) {
 	}
 
 	t.Setenv(EnvRDEAPIBaseURL, "https://env.rde")
-	r, err = Resolve(Config{RDEAPIBaseURL: "https://global.rde"}, Config{RDEAPIBaseURL: "https://dir.rde"}, auth.Auth{}, "", "")
+	r, err = Resolve(Config{RDEAPIBaseURL: "https://global.rde"}, Config{RDEAPIBaseURL: "https://dir.rde"}, "", "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -128,7 +137,7 @@ func TestResolve_OutputPrecedence(t *testing.T) {
 	t.Setenv(EnvOutput, "human")
 
 	// Flag wins over env, dir, and global.
-	r, err := Resolve(global, dir, auth.Auth{}, "json", "")
+	r, err := Resolve(global, dir, "json", "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -137,7 +146,7 @@ func TestResolve_OutputPrecedence(t *testing.T) {
 	}
 
 	// No flag → env beats dir + global.
-	r, err = Resolve(global, dir, auth.Auth{}, "", "")
+	r, err = Resolve(global, dir, "", "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -147,7 +156,7 @@ func TestResolve_OutputPrecedence(t *testing.T) {
 
 	// Clear env → dir beats global.
 	t.Setenv(EnvOutput, "")
-	r, err = Resolve(global, dir, auth.Auth{}, "", "")
+	r, err = Resolve(global, dir, "", "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -156,7 +165,7 @@ func TestResolve_OutputPrecedence(t *testing.T) {
 	}
 
 	// Clear dir → global wins.
-	r, err = Resolve(global, Config{}, auth.Auth{}, "", "")
+	r, err = Resolve(global, Config{}, "", "", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -169,20 +178,20 @@ func TestResolve_AppSlugPrecedence(t *testing.T) {
 	clearEnv(t)
 
 	// global only
-	r, _ := Resolve(Config{AppID: "global"}, Config{}, auth.Auth{}, "", "")
+	r, _ := Resolve(Config{AppID: "global"}, Config{}, "", "", "")
 	if r.AppSlug != "global" {
 		t.Errorf("global-only: %q", r.AppSlug)
 	}
 
 	// dir overrides global
-	r, _ = Resolve(Config{AppID: "global"}, Config{AppID: "dir"}, auth.Auth{}, "", "")
+	r, _ = Resolve(Config{AppID: "global"}, Config{AppID: "dir"}, "", "", "")
 	if r.AppSlug != "dir" {
 		t.Errorf("dir-over-global: %q", r.AppSlug)
 	}
 
 	// env overrides everything
 	t.Setenv(EnvAppSlug, "env")
-	r, _ = Resolve(Config{AppID: "global"}, Config{AppID: "dir"}, auth.Auth{}, "", "")
+	r, _ = Resolve(Config{AppID: "global"}, Config{AppID: "dir"}, "", "", "")
 	if r.AppSlug != "env" {
 		t.Errorf("env-wins: %q", r.AppSlug)
 	}
@@ -195,39 +204,31 @@ func TestResolve_LegacyAppSlugEnvFallback(t *testing.T) {
 
 	// Legacy env var beats config files (env > config).
 	t.Setenv(EnvAppSlugLegacy, "legacy-env")
-	r, _ := Resolve(Config{AppID: "global"}, Config{}, auth.Auth{}, "", "")
+	r, _ := Resolve(Config{AppID: "global"}, Config{}, "", "", "")
 	if r.AppSlug != "legacy-env" {
 		t.Errorf("legacy-env fallback: AppSlug = %q, want legacy-env", r.AppSlug)
 	}
 
 	// Current env var wins when both are set.
 	t.Setenv(EnvAppSlug, "new-env")
-	r, _ = Resolve(Config{AppID: "global"}, Config{}, auth.Auth{}, "", "")
+	r, _ = Resolve(Config{AppID: "global"}, Config{}, "", "", "")
 	if r.AppSlug != "new-env" {
 		t.Errorf("new env wins over legacy: AppSlug = %q, want new-env", r.AppSlug)
 	}
 }
 
-func TestResolve_TokenPrecedence(t *testing.T) {
+func TestResolve_TokenIsEnvOnly(t *testing.T) {
 	clearEnv(t)
 
-	// auth.yaml is the file source for tokens; config.yaml token field
-	// was removed when the dedicated auth surface landed.
-	r, _ := Resolve(Config{}, Config{}, auth.Auth{Token: "from-auth"}, "", "")
-	if r.Token != "from-auth" {
-		t.Errorf("auth-only: %q", r.Token)
-	}
-
-	// env wins over auth.yaml.
+	// The saved token is read lazily by the cmd layer, not here.
 	t.Setenv(EnvToken, "from-env")
-	r, _ = Resolve(Config{}, Config{}, auth.Auth{Token: "from-auth"}, "", "")
+	r, _ := Resolve(Config{}, Config{}, "", "", "")
 	if r.Token != "from-env" {
-		t.Errorf("env-wins: %q", r.Token)
+		t.Errorf("env: %q", r.Token)
 	}
 
-	// Nothing set anywhere → empty token.
 	t.Setenv(EnvToken, "")
-	r, _ = Resolve(Config{}, Config{}, auth.Auth{}, "", "")
+	r, _ = Resolve(Config{}, Config{}, "", "", "")
 	if r.Token != "" {
 		t.Errorf("none: %q", r.Token)
 	}
@@ -237,20 +238,20 @@ func TestResolve_APIBaseURLPrecedence(t *testing.T) {
 	clearEnv(t)
 
 	// Default when nothing set.
-	r, _ := Resolve(Config{}, Config{}, auth.Auth{}, "", "")
+	r, _ := Resolve(Config{}, Config{}, "", "", "")
 	if r.APIBaseURL != DefaultAPIBaseURL {
 		t.Errorf("default: %q", r.APIBaseURL)
 	}
 
 	// Global beats default.
-	r, _ = Resolve(Config{APIBaseURL: "https://global.test"}, Config{}, auth.Auth{}, "", "")
+	r, _ = Resolve(Config{APIBaseURL: "https://global.test"}, Config{}, "", "", "")
 	if r.APIBaseURL != "https://global.test" {
 		t.Errorf("global: %q", r.APIBaseURL)
 	}
 
 	// Env beats global.
 	t.Setenv(EnvAPIBaseURL, "https://env.test")
-	r, _ = Resolve(Config{APIBaseURL: "https://global.test"}, Config{}, auth.Auth{}, "", "")
+	r, _ = Resolve(Config{APIBaseURL: "https://global.test"}, Config{}, "", "", "")
 	if r.APIBaseURL != "https://env.test" {
 		t.Errorf("env: %q", r.APIBaseURL)
 	}
@@ -258,7 +259,7 @@ func TestResolve_APIBaseURLPrecedence(t *testing.T) {
 
 func TestResolve_RejectsInvalidOutputFlag(t *testing.T) {
 	clearEnv(t)
-	_, err := Resolve(Config{}, Config{}, auth.Auth{}, "yaml", "")
+	_, err := Resolve(Config{}, Config{}, "xml", "", "")
 	if err == nil {
 		t.Fatal("expected error for invalid --output value")
 	}
@@ -271,7 +272,7 @@ func TestResolve_ThemePrecedence(t *testing.T) {
 	t.Setenv(EnvTheme, "light")
 
 	// Flag wins over env, dir, and global.
-	r, err := Resolve(global, dir, auth.Auth{}, "", "none")
+	r, err := Resolve(global, dir, "", "none", "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -280,26 +281,26 @@ func TestResolve_ThemePrecedence(t *testing.T) {
 	}
 
 	// No flag → env beats dir + global.
-	r, _ = Resolve(global, dir, auth.Auth{}, "", "")
+	r, _ = Resolve(global, dir, "", "", "")
 	if r.Theme != style.ThemeLight {
 		t.Errorf("env-wins: Theme = %q, want light", r.Theme)
 	}
 
 	// Clear env → dir beats global.
 	t.Setenv(EnvTheme, "")
-	r, _ = Resolve(global, dir, auth.Auth{}, "", "")
+	r, _ = Resolve(global, dir, "", "", "")
 	if r.Theme != style.ThemeDark {
 		t.Errorf("dir-wins: Theme = %q, want dark", r.Theme)
 	}
 
 	// Clear dir → global wins.
-	r, _ = Resolve(global, Config{}, auth.Auth{}, "", "")
+	r, _ = Resolve(global, Config{}, "", "", "")
 	if r.Theme != style.ThemeAuto {
 		t.Errorf("global-wins: Theme = %q, want auto", r.Theme)
 	}
 
 	// Nothing set anywhere → auto.
-	r, _ = Resolve(Config{}, Config{}, auth.Auth{}, "", "")
+	r, _ = Resolve(Config{}, Config{}, "", "", "")
 	if r.Theme != style.ThemeAuto {
 		t.Errorf("default: Theme = %q, want auto", r.Theme)
 	}
@@ -307,12 +308,153 @@ func TestResolve_ThemePrecedence(t *testing.T) {
 
 func TestResolve_RejectsInvalidThemeFlag(t *testing.T) {
 	clearEnv(t)
-	_, err := Resolve(Config{}, Config{}, auth.Auth{}, "", "neon")
+	_, err := Resolve(Config{}, Config{}, "", "neon", "")
 	if err == nil {
 		t.Fatal("expected error for invalid --theme value")
 	}
 }
 
+func TestActiveProfile_Precedence(t *testing.T) {
+	clearEnv(t)
+
+	global := Config{Profile: "global"}
+	if got := ActiveProfile(Config{}, Config{}, ""); got != auth.DefaultProfile {
+		t.Errorf("nothing set: %q, want default", got)
+	}
+	if got := ActiveProfile(global, Config{}, ""); got != "global" {
+		t.Errorf("use-profile: %q", got)
+	}
+	if got := ActiveProfile(global, Config{Profile: "dir"}, ""); got != "dir" {
+		t.Errorf("dir pin over use-profile: %q", got)
+	}
+	t.Setenv(EnvProfile, "env")
+	if got := ActiveProfile(global, Config{Profile: "dir"}, ""); got != "env" {
+		t.Errorf("env over dir: %q", got)
+	}
+	if got := ActiveProfile(global, Config{Profile: "dir"}, "flag"); got != "flag" {
+		t.Errorf("flag wins: %q", got)
+	}
+}
+
+func TestResolve_ProfileLayering(t *testing.T) {
+	clearEnv(t)
+
+	global := Config{
+		Output:     "json",
+		AppID:      "default-app",
+		APIBaseURL: "https://default.test",
+		Profiles: map[string]Config{
+			"staging": {AppID: "staging-app", APIBaseURL: "https://staging.test", DefaultWorkspaceID: "stg-ws"},
+			"bare":    {},
+		},
+	}
+
+	r, err := Resolve(global, Config{}, "", "", "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Profile != "staging" || r.AppSlug != "staging-app" || r.APIBaseURL != "https://staging.test" || r.WorkspaceID != "stg-ws" {
+		t.Errorf("profile settings not applied: %+v", r)
+	}
+	if r.Output != output.JSON {
+		t.Errorf("Output = %q, want top-level preference to carry over", r.Output)
+	}
+
+	// Per-dir values still beat the profile.
+	r, _ = Resolve(global, Config{AppID: "dir-app"}, "", "", "staging")
+	if r.AppSlug != "dir-app" {
+		t.Errorf("dir over profile: %q", r.AppSlug)
+	}
+
+	// A named profile never inherits the default profile's account settings.
+	r, _ = Resolve(global, Config{}, "", "", "bare")
+	if r.AppSlug != "" || r.APIBaseURL != DefaultAPIBaseURL {
+		t.Errorf("default account leaked into profile: app=%q api=%q", r.AppSlug, r.APIBaseURL)
+	}
+
+	// The default profile is the top level.
+	r, _ = Resolve(global, Config{}, "", "", "")
+	if r.Profile != auth.DefaultProfile || r.AppSlug != "default-app" {
+		t.Errorf("default profile: %+v", r)
+	}
+
+	if _, err := Resolve(global, Config{}, "", "", "bad name"); err == nil {
+		t.Error("expected an invalid profile name to be rejected")
+	}
+}
+
+func TestCredentialStoreKind_Precedence(t *testing.T) {
+	clearEnv(t)
+
+	if got, _ := CredentialStoreKind(Config{}); got != auth.StoreFile {
+		t.Errorf("default: %q", got)
+	}
+	if got, _ := CredentialStoreKind(Config{CredentialStore: auth.StoreKeychain}); got != auth.StoreKeychain {
+		t.Errorf("global: %q", got)
+	}
+	t.Setenv(EnvCredentialStore, auth.StoreEncryptedFile)
+	if got, _ := CredentialStoreKind(Config{CredentialStore: auth.StoreKeychain}); got != auth.StoreEncryptedFile {
+		t.Errorf("env wins: %q", got)
+	}
+	t.Setenv(EnvCredentialStore, "vault")
+	if _, err := Resolve(Config{}, Config{}, "", "", ""); err == nil {
+		t.Error("expected an invalid BITRISE_CREDENTIAL_STORE to be rejected")
+	}
+}
+
+func TestResolve_HTTPRetryPrecedence(t *testing.T) {
+	clearEnv(t)
+
+	r, err := Resolve(Config{}, Config{}, "", "", "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if r.HTTPRetries != httpretry.DefaultMaxRetries || r.HTTPRetryMaxWait != httpretry.DefaultMaxWait || r.Debug {
+		t.Errorf("defaults: retries=%d wait=%v debug=%v", r.HTTPRetries, r.HTTPRetryMaxWait, r.Debug)
+	}
+
+	global := Config{HTTPRetries: "5", HTTPRetryMaxWait: "1m"}
+	r, _ = Resolve(global, Config{HTTPRetries: "0"}, "", "", "")
+	if r.HTTPRetries != 0 || r.HTTPRetryMaxWait != time.Minute {
+		t.Errorf("dir over global: retries=%d wait=%v", r.HTTPRetries, r.HTTPRetryMaxWait)
+	}
+
+	t.Setenv(EnvHTTPRetries, "7")
+	t.Setenv(EnvDebug, "1")
+	r, _ = Resolve(global, Config{HTTPRetries: "0"}, "", "", "")
+	if r.HTTPRetries != 7 || !r.Debug {
+		t.Errorf("env wins: retries=%d debug=%v", r.HTTPRetries, r.Debug)
+	}
+
+	t.Setenv(EnvDebug, "false")
+	if r, _ = Resolve(Config{}, Config{}, "", "", ""); r.Debug {
+		t.Error(`BITRISE_DEBUG=false should leave debugging off`)
+	}
+
+	t.Setenv(EnvHTTPRetryMaxWait, "soon")
+	if _, err := Resolve(Config{}, Config{}, "", "", ""); err == nil {
+		t.Error("expected an invalid BITRISE_HTTP_RETRY_MAX_WAIT to be rejected")
+	}
+}
+
+func TestResolve_HTTPCache(t *testing.T) {
+	clearEnv(t)
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	r, err := Resolve(Config{}, Config{}, "", "", "")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if want := filepath.Join(xdg, "bitrise", "cache", "http"); r.CacheDir != want || r.NoCache {
+		t.Errorf("CacheDir=%q NoCache=%v, want %q false", r.CacheDir, r.NoCache, want)
+	}
+
+	t.Setenv(EnvNoCache, "1")
+	if r, _ = Resolve(Config{}, Config{}, "", "", ""); !r.NoCache {
+		t.Error("BITRISE_NO_CACHE=1 should bypass the cache")
+	}
+}
+
 func TestContext_RoundTrip(t *testing.T) {
 	r := Resolved{Output: output.JSON, AppSlug: "abc"}
 	ctx := WithResolved(t.Context(), r)
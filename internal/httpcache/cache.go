@@ -0,0 +1,346 @@
+// Package httpcache is the CLI's persistent HTTP cache: an http.RoundTripper
+// that keeps the responses to GET requests for slow-changing resources
+// (workspaces, apps, stack and step catalogs) on disk, so name resolution and
+// shell completion don't re-fetch them on every invocation.
+//
+// Each Rule gives a class of resources a TTL. Within it a cached response is
+// served without touching the network; after it the entry is revalidated
+// with If-None-Match / If-Modified-Since, and a 304 extends it for another
+// TTL. Requests no rule matches are never cached.
+//
+// Entries are scoped by a hash of the request's Authorization header, so
+// accounts and profiles never see each other's data, and any successful
+// non-GET request drops its scope so a change the CLI just made is visible
+// at once. Scopes left behind by rotated tokens are pruned once they
+// outlive the longest TTL. Caching is best-effort: a broken or unwritable
+// cache directory only costs a network round trip.
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxEntrySize bounds a cached response body; larger responses pass through
+// uncached.
+const maxEntrySize = 8 << 20
+
+// Rule caches the GET responses whose URL path matches Pattern for TTL.
+type Rule struct {
+	// Class names the resource class in --debug output.
+	Class   string
+	Pattern *regexp.Regexp
+	TTL     time.Duration
+}
+
+// DefaultRules are the resource classes the CLI caches. Patterns are anchored
+// at the end of the path so they hold under any API base path.
+var DefaultRules = []Rule{
+	{Class: "workspaces", Pattern: regexp.MustCompile(`/organizations$`), TTL: time.Hour},
+	{Class: "apps", Pattern: regexp.MustCompile(`/apps(/[^/]+)?$`), TTL: 10 * time.Minute},
+	{Class: "stacks", Pattern: regexp.MustCompile(`/(available-stacks|v1/workspaces/[^/]+/(stacks|machine-types))$`), TTL: 24 * time.Hour},
+	{Class: "steps", Pattern: regexp.MustCompile(`/(search-steps|step-inputs)$`), TTL: 6 * time.Hour},
+	// The StepLib and step releases, read from GitHub (bitriseapi/steplib).
+	{Class: "steplib", Pattern: regexp.MustCompile(`/repos/bitrise-io/bitrise-steplib/contents/steps/.+$|/repos/[^/]+/[^/]+/releases$|/repositories/[0-9]+/releases$`), TTL: 6 * time.Hour},
+}
+
+type ttlKey struct{}
+
+// WithTTL returns a ctx under which every GET request is cacheable for ttl,
+// whether or not a Rule matches it (`api --cache`).
+func WithTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, ttlKey{}, ttl)
+}
+
+// Transport serves cacheable GET requests from Dir and performs everything
+// else with Base. The zero value caches nothing; use New.
+type Transport struct {
+	// Base performs the requests; http.DefaultTransport when nil.
+	Base http.RoundTripper
+	// Dir holds the entries; empty disables the cache.
+	Dir   string
+	Rules []Rule
+	// Refresh skips cached answers (--no-cache) but still stores the fresh
+	// responses for the next run.
+	Refresh bool
+	// Logf, when set, is told about every hit and revalidation.
+	Logf func(format string, args ...any)
+}
+
+// New returns a Transport caching DefaultRules under dir in front of base.
+// A nil base means http.DefaultTransport.
+func New(base http.RoundTripper, dir string, refresh bool, logf func(format string, args ...any)) *Transport {
+	return &Transport{Base: base, Dir: dir, Rules: DefaultRules, Refresh: refresh, Logf: logf}
+}
+
+// now is the clock entries are aged against. Tests replace it.
+var now = time.Now
+
+// entry is one cached response, stored as JSON.
+type entry struct {
+	URL      string      `json:"url"`
+	StoredAt time.Time   `json:"stored_at"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Method != http.MethodGet {
+		resp, err := base.RoundTrip(req)
+		if err == nil && !safeMethod(req.Method) && resp.StatusCode < http.StatusBadRequest {
+			t.invalidate(req)
+		}
+		return resp, err
+	}
+	rule, ok := t.rule(req)
+	if !ok {
+		return base.RoundTrip(req)
+	}
+
+	path := t.entryPath(req)
+	var cached *entry
+	if !t.Refresh {
+		cached = load(path)
+	}
+	if cached != nil && now().Sub(cached.StoredAt) < rule.TTL {
+		t.logf("cache hit (%s, %s old) GET %s", rule.Class, now().Sub(cached.StoredAt).Round(time.Second), req.URL.Redacted())
+		return cached.response(req), nil
+	}
+
+	out := req
+	if cached != nil {
+		etag, modified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+		if etag != "" || modified != "" {
+			out = req.Clone(req.Context())
+			if etag != "" {
+				out.Header.Set("If-None-Match", etag)
+			}
+			if modified != "" {
+				out.Header.Set("If-Modified-Since", modified)
+			}
+		}
+	}
+	resp, err := base.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil && out != req:
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+		t.logf("cache revalidated (%s) GET %s", rule.Class, req.URL.Redacted())
+		cached.StoredAt = now()
+		t.save(path, cached)
+		return cached.response(req), nil
+	case resp.StatusCode == http.StatusOK && storable(resp):
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxEntrySize+1))
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+		if len(body) <= maxEntrySize {
+			t.save(path, &entry{
+				URL:      req.URL.Redacted(),
+				StoredAt: now(),
+				Status:   resp.StatusCode,
+				Header:   resp.Header.Clone(),
+				Body:     body,
+			})
+		}
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	}
+	return resp, nil
+}
+
+// Clear removes every entry under dir and reports how many there were. A
+// missing dir is an empty cache.
+func Clear(dir string) (int, error) {
+	if dir == "" {
+		return 0, nil
+	}
+	n := 0
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".json") {
+			n++
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read cache dir: %w", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, fmt.Errorf("remove cache dir: %w", err)
+	}
+	return n, nil
+}
+
+func (t *Transport) rule(req *http.Request) (Rule, bool) {
+	if t.Dir == "" || req.Header.Get("Range") != "" ||
+		req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" ||
+		strings.Contains(req.Header.Get("Cache-Control"), "no-cache") {
+		return Rule{}, false
+	}
+	if ttl, ok := req.Context().Value(ttlKey{}).(time.Duration); ok && ttl > 0 {
+		return Rule{Class: "ttl " + ttl.String(), TTL: ttl}, true
+	}
+	for _, r := range t.Rules {
+		if r.Pattern.MatchString(req.URL.Path) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// scopeDir is the directory holding the entries made with req's
+// credentials.
+func (t *Transport) scopeDir(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return filepath.Join(t.Dir, hex.EncodeToString(sum[:8]))
+}
+
+func (t *Transport) entryPath(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.URL.String() + "\n" + req.Header.Get("Accept")))
+	return filepath.Join(t.scopeDir(req), hex.EncodeToString(sum[:])+".json")
+}
+
+// invalidate drops every entry made with req's credentials.
+func (t *Transport) invalidate(req *http.Request) {
+	if t.Dir == "" {
+		return
+	}
+	if err := os.RemoveAll(t.scopeDir(req)); err != nil {
+		t.logf("cache: invalidate: %v", err)
+	}
+}
+
+func load(path string) *entry {
+	data, err := os.ReadFile(path) //nolint:gosec // path is a hash under the CLI's cache dir
+	if err != nil {
+		return nil
+	}
+	var e entry
+	if json.Unmarshal(data, &e) != nil {
+		return nil
+	}
+	return &e
+}
+
+// save writes e to path via a temp file + rename, so a concurrent reader
+// never sees half an entry. Failures are only logged.
+func (t *Transport) save(path string, e *entry) {
+	if err := t.writeFileAtomic(path, e); err != nil {
+		t.logf("cache: store %s: %v", e.URL, err)
+	}
+}
+
+func (t *Transport) writeFileAtomic(path string, e *entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		t.prune(filepath.Dir(dir))
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // best-effort cleanup if rename already moved it
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck,gosec // returning the write error; close failure is secondary
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// prune removes the scope directories under root that haven't been written
+// to for longer than the longest TTL — typically ones keyed by an expired
+// token.
+func (t *Transport) prune(root string) {
+	var maxTTL time.Duration
+	for _, r := range t.Rules {
+		maxTTL = max(maxTTL, r.TTL)
+	}
+	scopes, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, s := range scopes {
+		info, err := s.Info()
+		if err != nil || !s.IsDir() || now().Sub(info.ModTime()) < maxTTL {
+			continue
+		}
+		_ = os.RemoveAll(filepath.Join(root, s.Name()))
+	}
+}
+
+func (t *Transport) logf(format string, args ...any) {
+	if t.Logf != nil {
+		t.Logf(format, args...)
+	}
+}
+
+// response rebuilds the cached answer to req.
+func (e *entry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// storable reports whether the server allows resp to be kept.
+func storable(resp *http.Response) bool {
+	return !strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store")
+}
+
+func safeMethod(m string) bool {
+	return m == http.MethodGet || m == http.MethodHead || m == http.MethodOptions
+}
+
+// readCloser reads from r and closes c.
+type readCloser struct {
+	io.Reader
+	c io.Closer
+}
+
+func (rc readCloser) Close() error { return rc.c.Close() }
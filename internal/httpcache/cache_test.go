@@ -0,0 +1,190 @@
+package httpcache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// clock replaces now for a test and returns a function that advances it.
+func clock(t *testing.T) func(time.Duration) {
+	t.Helper()
+	cur := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	prev := now
+	now = func() time.Time { return cur }
+	t.Cleanup(func() { now = prev })
+	return func(d time.Duration) { cur = cur.Add(d) }
+}
+
+// api serves /organizations with an ETag, answering conditional requests
+// with 304 while the ETag still matches, and counts every call.
+func api(t *testing.T, etag *string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.Header.Get("If-None-Match") == *etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", *etag)
+		_, _ = io.WriteString(w, `{"data":"`+*etag+`"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func get(t *testing.T, c *http.Client, url, token string) string {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", token)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestTransport_ServesFreshEntriesAndRevalidatesStaleOnes(t *testing.T) {
+	advance := clock(t)
+	etag := `"v1"`
+	srv, calls := api(t, &etag)
+	c := &http.Client{Transport: New(nil, t.TempDir(), false, nil)}
+
+	first := get(t, c, srv.URL+"/organizations", "token a")
+	if got := get(t, c, srv.URL+"/organizations", "token a"); got != first || calls.Load() != 1 {
+		t.Fatalf("fresh entry: body %q after %d calls, want %q after 1", got, calls.Load(), first)
+	}
+
+	advance(2 * time.Hour)
+	if got := get(t, c, srv.URL+"/organizations", "token a"); got != first || calls.Load() != 2 {
+		t.Fatalf("304 revalidation: body %q after %d calls, want %q after 2", got, calls.Load(), first)
+	}
+	if get(t, c, srv.URL+"/organizations", "token a"); calls.Load() != 2 {
+		t.Errorf("revalidated entry should be fresh again, got %d calls", calls.Load())
+	}
+
+	advance(2 * time.Hour)
+	etag = `"v2"`
+	if got := get(t, c, srv.URL+"/organizations", "token a"); !strings.Contains(got, "v2") {
+		t.Errorf("changed resource: body %q, want the new version", got)
+	}
+}
+
+func TestTransport_ScopesByCredentialAndSkipsUnmatchedPaths(t *testing.T) {
+	clock(t)
+	etag := `"v1"`
+	srv, calls := api(t, &etag)
+	c := &http.Client{Transport: New(nil, t.TempDir(), false, nil)}
+
+	get(t, c, srv.URL+"/organizations", "token a")
+	get(t, c, srv.URL+"/organizations", "token b")
+	if calls.Load() != 2 {
+		t.Errorf("another credential must not see the entry: %d calls, want 2", calls.Load())
+	}
+	get(t, c, srv.URL+"/apps/x/builds", "token a")
+	get(t, c, srv.URL+"/apps/x/builds", "token a")
+	if calls.Load() != 4 {
+		t.Errorf("uncached class: %d calls, want 4", calls.Load())
+	}
+}
+
+func TestTransport_WithTTLCachesAnyGET(t *testing.T) {
+	advance := clock(t)
+	etag := `"v1"`
+	srv, calls := api(t, &etag)
+	c := &http.Client{Transport: New(nil, t.TempDir(), false, nil)}
+
+	req, _ := http.NewRequestWithContext(WithTTL(context.Background(), time.Minute), http.MethodGet, srv.URL+"/apps/x/builds", nil)
+	for range 2 {
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	if calls.Load() != 1 {
+		t.Errorf("within the TTL: %d calls, want 1", calls.Load())
+	}
+	advance(2 * time.Minute)
+	etag = `"v2"`
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if calls.Load() != 2 || !strings.Contains(string(body), "v2") {
+		t.Errorf("after the TTL: body %q after %d calls, want v2 after 2", body, calls.Load())
+	}
+}
+
+func TestTransport_MutationInvalidatesScope(t *testing.T) {
+	clock(t)
+	etag := `"v1"`
+	srv, calls := api(t, &etag)
+	c := &http.Client{Transport: New(nil, t.TempDir(), false, nil)}
+
+	get(t, c, srv.URL+"/apps", "token a")
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/apps/register", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "token a")
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	get(t, c, srv.URL+"/apps", "token a")
+	if calls.Load() != 3 {
+		t.Errorf("GET after POST should refetch: %d calls, want 3", calls.Load())
+	}
+}
+
+func TestTransport_RefreshSkipsReadsButStores(t *testing.T) {
+	clock(t)
+	etag := `"v1"`
+	srv, calls := api(t, &etag)
+	dir := t.TempDir()
+
+	refresh := &http.Client{Transport: New(nil, dir, true, nil)}
+	get(t, refresh, srv.URL+"/organizations", "token a")
+	get(t, refresh, srv.URL+"/organizations", "token a")
+	if calls.Load() != 2 {
+		t.Fatalf("--no-cache should always fetch: %d calls, want 2", calls.Load())
+	}
+	get(t, &http.Client{Transport: New(nil, dir, false, nil)}, srv.URL+"/organizations", "token a")
+	if calls.Load() != 2 {
+		t.Errorf("the refreshed entry should serve the next run: %d calls, want 2", calls.Load())
+	}
+}
+
+func TestClear(t *testing.T) {
+	clock(t)
+	etag := `"v1"`
+	srv, _ := api(t, &etag)
+	dir := t.TempDir() + "/http"
+	c := &http.Client{Transport: New(nil, dir, false, nil)}
+	get(t, c, srv.URL+"/organizations", "token a")
+	get(t, c, srv.URL+"/apps", "token b")
+
+	n, err := Clear(dir)
+	if err != nil || n != 2 {
+		t.Fatalf("Clear = %d, %v; want 2, nil", n, err)
+	}
+	if n, err := Clear(dir); err != nil || n != 0 {
+		t.Errorf("Clear on a missing dir = %d, %v; want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,173 @@
+package httpdebug
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// The HAR 1.2 subset the tracer writes
+// (http://www.softwareishard.com/blog/har-12-spec/). Sizes the tracer does
+// not know are -1, as the spec asks.
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string      `json:"version"`
+	Creator harCreator  `json:"creator"`
+	Entries []*harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	// Error is a non-standard field (HAR allows "_"-prefixed custom
+	// fields) set when no response arrived.
+	Error string `json:"_error,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// newHAREntry records one exchange; resp is nil when the request failed.
+// The response body is filled in later by setContent.
+func newHAREntry(req *http.Request, target string, reqBody []byte, start time.Time, elapsed time.Duration, resp *http.Response) *harEntry {
+	e := &harEntry{
+		StartedDateTime: start.UTC().Format(time.RFC3339Nano),
+		Time:            millis(elapsed),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         target,
+			HTTPVersion: "HTTP/1.1",
+			Headers:     nameValues(redactHeaders(req.Header)),
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Timings: harTimings{Wait: millis(elapsed)},
+	}
+	e.Request.QueryString = []harNameValue{}
+	if u, err := url.Parse(target); err == nil {
+		for k, vs := range u.Query() {
+			for _, v := range vs {
+				e.Request.QueryString = append(e.Request.QueryString, harNameValue{Name: k, Value: v})
+			}
+		}
+	}
+	if len(reqBody) > 0 {
+		ct := req.Header.Get("Content-Type")
+		e.Request.PostData = &harPostData{MimeType: ct, Text: redactBody(ct, reqBody)}
+	}
+	if resp == nil {
+		e.Response = harResponse{HTTPVersion: "HTTP/1.1", Headers: []harNameValue{}, Cookies: []harNameValue{}, HeadersSize: -1, BodySize: -1, Error: "no response"}
+		return e
+	}
+	e.Request.HTTPVersion = resp.Proto
+	e.Response = harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Headers:     nameValues(redactHeaders(resp.Header)),
+		Cookies:     []harNameValue{},
+		Content:     harContent{Size: -1, MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	return e
+}
+
+// setContent fills in the response body once it has been read; total is
+// the time from sending the request to closing the body.
+func (e *harEntry) setContent(text string, size int64, total time.Duration) {
+	e.Response.Content.Text = text
+	e.Response.Content.Size = size
+	e.Response.BodySize = size
+	e.Timings.Receive = max(millis(total)-e.Timings.Wait, 0)
+	e.Time = millis(total)
+}
+
+func nameValues(h http.Header) []harNameValue {
+	out := []harNameValue{}
+	for _, name := range slices.Sorted(maps.Keys(h)) {
+		for _, v := range h[name] {
+			out = append(out, harNameValue{Name: name, Value: v})
+		}
+	}
+	return out
+}
+
+// WriteHAR writes every recorded exchange as a HAR 1.2 document.
+func (t *Tracer) WriteHAR(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f := harFile{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: t.opts.Creator, Version: t.opts.Version},
+		Entries: append([]*harEntry{}, t.entries...),
+	}}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(f)
+}
@@ -0,0 +1,155 @@
+package httpdebug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces every secret the tracer recognises.
+const redacted = "[REDACTED]"
+
+// secretHeaders carry credentials or session state and are never shown.
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Csrf-Token":        true,
+}
+
+// secretTokenRegexp matches Bitrise tokens and JWTs wherever they appear in
+// free text, as a last line of defence behind the field-based redaction.
+var secretTokenRegexp = regexp.MustCompile(`\bbit(?:pat|wat)_[A-Za-z0-9_-]+|\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+// signedURLParamRegexp matches the signature of a signed storage URL (GCS
+// V4, S3 SigV4, Azure SAS) inside any string: the URL alone grants access to
+// the object until it expires, like a bearer token.
+var signedURLParamRegexp = regexp.MustCompile(`(?i)([?&](?:x-goog-signature|x-amz-signature|x-amz-security-token|signature|sig)=)[^&#\s"'<>]+`)
+
+// redactText masks tokens and URL signatures in free text.
+func redactText(s string) string {
+	s = secretTokenRegexp.ReplaceAllString(s, redacted)
+	return signedURLParamRegexp.ReplaceAllString(s, "${1}"+redacted)
+}
+
+// secretKey reports whether a JSON field or form/query parameter holds a
+// secret, judged by its name.
+func secretKey(key string) bool {
+	k := strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	switch k {
+	case "code", "code_verifier", "device_code", "token", "otp", "sig":
+		return true
+	}
+	for _, s := range []string{"password", "secret", "passphrase", "private_key", "cookie", "authorization", "signature"} {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return strings.HasSuffix(k, "token")
+}
+
+// redactHeaders copies h with credential headers masked. The auth scheme of
+// Authorization ("token", "Bearer") is kept: it tells which client sent it.
+func redactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for name, values := range out {
+		if !secretHeaders[http.CanonicalHeaderKey(name)] {
+			continue
+		}
+		for i, v := range values {
+			scheme, _, ok := strings.Cut(v, " ")
+			if ok && http.CanonicalHeaderKey(name) != "Cookie" && http.CanonicalHeaderKey(name) != "Set-Cookie" {
+				values[i] = scheme + " " + redacted
+			} else {
+				values[i] = redacted
+			}
+		}
+	}
+	return out
+}
+
+// redactURL masks secret query parameters.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	if len(q) == 0 {
+		return u.Redacted()
+	}
+	changed := false
+	for k := range q {
+		if secretKey(k) {
+			q.Set(k, redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return u.Redacted()
+	}
+	c := *u
+	c.RawQuery = q.Encode()
+	return c.Redacted()
+}
+
+// redactBody masks secrets in a request or response body: fields of a JSON
+// document, parameters of a form, and token-looking strings anywhere else.
+func redactBody(contentType string, body []byte) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(mediaType) {
+	case "application/x-www-form-urlencoded":
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			for k := range form {
+				if secretKey(k) {
+					form.Set(k, redacted)
+				}
+			}
+			return form.Encode()
+		}
+	default:
+		var doc any
+		if json.Unmarshal(body, &doc) == nil {
+			if out, err := json.Marshal(redactJSON(doc)); err == nil {
+				return string(out)
+			}
+		}
+	}
+	return redactText(string(body))
+}
+
+// redactJSON walks a decoded JSON value and masks secret fields. A "value"
+// field is masked too when its object flags itself as secret, which is how
+// saved inputs and environment variables carry secret values.
+func redactJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		secretValue := false
+		for k, x := range t {
+			if b, ok := x.(bool); ok && b && strings.Contains(strings.ToLower(k), "secret") {
+				secretValue = true
+			}
+		}
+		for k, x := range t {
+			switch {
+			case secretKey(k):
+				if _, isBool := x.(bool); !isBool && x != nil {
+					t[k] = redacted
+				}
+			case secretValue && k == "value":
+				t[k] = redacted
+			default:
+				t[k] = redactJSON(x)
+			}
+		}
+		return t
+	case []any:
+		for i, x := range t {
+			t[i] = redactJSON(x)
+		}
+		return t
+	case string:
+		// Catches signed URLs such as the RDE transfer signedUrl.
+		return redactText(t)
+	}
+	return v
+}
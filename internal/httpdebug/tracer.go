@@ -0,0 +1,232 @@
+// Package httpdebug traces the CLI's HTTP traffic for --debug: a one-line
+// summary of every request and response (method, URL, status, timing,
+// request ID) plus redacted headers and bodies, written to a log, and
+// optionally recorded as a HAR file for attaching to a support ticket.
+//
+// Credentials never reach either output. Authorization, cookie and CSRF
+// headers are masked, as are secret-looking JSON fields, form and query
+// parameters, and anything shaped like a Bitrise token or a JWT.
+package httpdebug
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// logBodyLimit caps the body excerpt in the log.
+	logBodyLimit = 4 << 10
+	// harBodyLimit caps a body recorded in the HAR file.
+	harBodyLimit = 1 << 20
+)
+
+// requestIDHeaders are the response headers that identify a request on the
+// server side — what Bitrise support asks for.
+var requestIDHeaders = []string{"X-Request-Id", "X-Bitrise-Request-Id", "X-Amzn-Trace-Id", "X-Cloud-Trace-Context", "Traceparent"}
+
+// Options configures a Tracer.
+type Options struct {
+	// Log receives the trace; nil records without logging (HAR only).
+	Log io.Writer
+	// RecordHAR keeps every exchange for WriteHAR.
+	RecordHAR bool
+	// Creator names the program in the HAR file, e.g. "bitrise-cli".
+	Creator string
+	// Version is the program version in the HAR file.
+	Version string
+}
+
+// Tracer logs and records HTTP exchanges. A nil *Tracer is valid and traces
+// nothing, so callers can pass FromContext's result around unconditionally.
+type Tracer struct {
+	opts    Options
+	mu      sync.Mutex
+	seq     int
+	entries []*harEntry
+}
+
+// New returns a Tracer.
+func New(opts Options) *Tracer {
+	return &Tracer{opts: opts}
+}
+
+// Logf writes a debug line to the log, if there is one.
+func (t *Tracer) Logf(format string, args ...any) {
+	if t == nil || t.opts.Log == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, _ = fmt.Fprintf(t.opts.Log, "debug: "+format+"\n", args...)
+}
+
+// Logging reports whether Logf writes anywhere.
+func (t *Tracer) Logging() bool {
+	return t != nil && t.opts.Log != nil
+}
+
+// Wrap returns base with tracing added; base itself when t is nil. A nil
+// base means http.DefaultTransport.
+func (t *Tracer) Wrap(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t == nil {
+		return base
+	}
+	return &transport{tracer: t, base: base}
+}
+
+type transport struct {
+	tracer *Tracer
+	base   http.RoundTripper
+}
+
+func (rt *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t := rt.tracer
+	t.mu.Lock()
+	t.seq++
+	id := t.seq
+	t.mu.Unlock()
+
+	reqBody := peekRequestBody(req)
+	target := redactURL(req.URL)
+	t.Logf("→ #%d %s %s", id, req.Method, target)
+	t.logHeaders(redactHeaders(req.Header))
+	if len(reqBody) > 0 {
+		t.Logf("  body: %s", excerpt(redactBody(req.Header.Get("Content-Type"), reqBody), logBodyLimit))
+	}
+
+	start := time.Now()
+	resp, err := rt.base.RoundTrip(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Logf("← #%d %s %s failed after %s: %v", id, req.Method, target, elapsed.Round(time.Millisecond), err)
+		t.record(newHAREntry(req, target, reqBody, start, elapsed, nil))
+		return nil, err
+	}
+
+	t.Logf("← #%d %s %s %s (%s)%s", id, resp.Status, req.Method, target, elapsed.Round(time.Millisecond), requestIDs(resp.Header))
+	entry := newHAREntry(req, target, reqBody, start, elapsed, resp)
+	t.record(entry)
+	resp.Body = &tracedBody{ReadCloser: resp.Body, tracer: t, id: id, resp: resp, entry: entry, start: start}
+	return resp, nil
+}
+
+func (t *Tracer) logHeaders(h http.Header) {
+	if !t.Logging() {
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(h)) {
+		t.Logf("  %s: %s", name, strings.Join(h[name], ", "))
+	}
+}
+
+func (t *Tracer) record(e *harEntry) {
+	if !t.opts.RecordHAR {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, e)
+}
+
+// tracedBody captures the start of a response body as it is read and logs
+// it when the caller closes the body, so streaming responses keep streaming.
+type tracedBody struct {
+	io.ReadCloser
+	tracer *Tracer
+	id     int
+	resp   *http.Response
+	entry  *harEntry
+	start  time.Time
+	buf    bytes.Buffer
+	size   int64
+	once   sync.Once
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if room := harBodyLimit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		body := redactBody(b.resp.Header.Get("Content-Type"), b.buf.Bytes())
+		if b.buf.Len() > 0 {
+			b.tracer.Logf("  #%d response body (%d bytes): %s", b.id, b.size, excerpt(body, logBodyLimit))
+		}
+		b.tracer.mu.Lock()
+		b.entry.setContent(body, b.size, time.Since(b.start))
+		b.tracer.mu.Unlock()
+	})
+	return err
+}
+
+// peekRequestBody returns a copy of the request body without consuming it.
+func peekRequestBody(req *http.Request) []byte {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		defer func() { _ = rc.Close() }()
+		data, _ := io.ReadAll(io.LimitReader(rc, harBodyLimit))
+		return data
+	}
+	// A one-shot body: read it and hand the request a replacement.
+	data, _ := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data[:min(len(data), harBodyLimit)]
+}
+
+func requestIDs(h http.Header) string {
+	var parts []string
+	for _, name := range requestIDHeaders {
+		if v := h.Get(name); v != "" {
+			parts = append(parts, strings.ToLower(name)+"="+v)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(parts, " ")
+}
+
+func excerpt(s string, limit int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= limit {
+		return s
+	}
+	return s[:limit] + fmt.Sprintf("… (%d more bytes)", len(s)-limit)
+}
+
+type ctxKey struct{}
+
+// WithTracer stores t on ctx.
+func WithTracer(ctx context.Context, t *Tracer) context.Context {
+	return context.WithValue(ctx, ctxKey{}, t)
+}
+
+// FromContext returns the Tracer on ctx, or nil.
+func FromContext(ctx context.Context) *Tracer {
+	t, _ := ctx.Value(ctxKey{}).(*Tracer)
+	return t
+}
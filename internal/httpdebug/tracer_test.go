@@ -0,0 +1,125 @@
+package httpdebug
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestTracer_LogsAndRecordsRedacted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-42")
+		w.Header().Set("Set-Cookie", "session=s3cr3t")
+		_, _ = io.WriteString(w, `{"data":{"username":"alice","access_token":"bitpat_abcdef"}}`)
+	}))
+	defer srv.Close()
+
+	log := &bytes.Buffer{}
+	tr := New(Options{Log: log, RecordHAR: true, Creator: "bitrise-cli", Version: "1.0.0"})
+	client := &http.Client{Transport: tr.Wrap(nil)}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/users/sign_in?access_token=q1", strings.NewReader(`{"user":{"login":"alice","password":"hunter2"}}`))
+	req.Header.Set("Authorization", "token bitpat_header")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), "bitpat_abcdef") {
+		t.Fatalf("the caller must get the real body, got %s", body)
+	}
+
+	out := log.String()
+	for _, want := range []string{"POST", "/users/sign_in", "200 OK", "x-request-id=req-42", "Authorization: token [REDACTED]", `"username":"alice"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %q:\n%s", want, out)
+		}
+	}
+	har := &bytes.Buffer{}
+	if err := tr.WriteHAR(har); err != nil {
+		t.Fatal(err)
+	}
+	for name, s := range map[string]string{"log": out, "har": har.String()} {
+		for _, secret := range []string{"hunter2", "bitpat_header", "bitpat_abcdef", "s3cr3t", "q1"} {
+			if strings.Contains(s, secret) {
+				t.Errorf("%s leaks %q", name, secret)
+			}
+		}
+	}
+
+	var doc struct {
+		Log struct {
+			Version string `json:"version"`
+			Entries []struct {
+				Request struct {
+					Method string `json:"method"`
+				} `json:"request"`
+				Response struct {
+					Status  int `json:"status"`
+					Content struct {
+						Size int64  `json:"size"`
+						Text string `json:"text"`
+					} `json:"content"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(har.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid HAR: %v", err)
+	}
+	if doc.Log.Version != "1.2" || len(doc.Log.Entries) != 1 {
+		t.Fatalf("HAR = %+v", doc.Log)
+	}
+	e := doc.Log.Entries[0]
+	if e.Request.Method != http.MethodPost || e.Response.Status != http.StatusOK || e.Response.Content.Size != int64(len(body)) {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestTracer_NilIsNoop(t *testing.T) {
+	var tr *Tracer
+	if tr.Wrap(nil) != http.DefaultTransport {
+		t.Error("a nil tracer should hand back the base transport")
+	}
+	tr.Logf("ignored %d", 1)
+	if tr.Logging() {
+		t.Error("a nil tracer doesn't log")
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name, contentType, in, want string
+	}{
+		{"json fields", "application/json", `{"refresh_token":"r","token_type":"Bearer","expires_in":3600}`, `{"expires_in":3600,"refresh_token":"[REDACTED]","token_type":"Bearer"}`},
+		{"secret value", "application/json", `{"key":"API_KEY","value":"v","is_secret":true}`, `{"is_secret":true,"key":"API_KEY","value":"[REDACTED]"}`},
+		{"plain value", "application/json", `{"key":"BRANCH","value":"main","is_secret":false}`, `{"is_secret":false,"key":"BRANCH","value":"main"}`},
+		{"form", "application/x-www-form-urlencoded", "grant_type=refresh_token&refresh_token=r&client_id=c", "client_id=c&grant_type=refresh_token&refresh_token=%5BREDACTED%5D"},
+		{"free text", "text/plain", "use bitwat_xyz please", "use [REDACTED] please"},
+		{"signed url", "application/json", `{"signedUrl":"https://storage.googleapis.com/b/o?X-Goog-Algorithm=GOOG4&X-Goog-Signature=abc123&X-Goog-Expires=900"}`,
+			`{"signedUrl":"https://storage.googleapis.com/b/o?X-Goog-Algorithm=GOOG4\u0026X-Goog-Signature=[REDACTED]\u0026X-Goog-Expires=900"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactBody(tt.contentType, []byte(tt.in)); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactURL_SignedURL(t *testing.T) {
+	u, _ := url.Parse("https://storage.googleapis.com/b/o?X-Goog-Credential=c&X-Goog-Signature=abc123")
+	got := redactURL(u)
+	if strings.Contains(got, "abc123") || !strings.Contains(got, "X-Goog-Credential=c") {
+		t.Errorf("redactURL = %s, want only the signature masked", got)
+	}
+}
@@ -0,0 +1,223 @@
+// Package httpretry is the shared retry policy for the CLI's API clients: an
+// http.RoundTripper that retries rate-limited and transiently failing
+// requests with exponential backoff and jitter.
+//
+// What is retried:
+//   - 429 Too Many Requests, for any method — the server rejected the
+//     request without acting on it.
+//   - 502, 503 and 504, and connection-level errors (reset, refused,
+//     timeouts), for idempotent methods only (GET, HEAD, OPTIONS, PUT,
+//     DELETE), or any request carrying an Idempotency-Key header.
+//
+// The wait honours Retry-After (seconds or an HTTP date) and the
+// X-RateLimit-Remaining / X-RateLimit-Reset pair; otherwise it backs off
+// exponentially from BaseDelay with equal jitter. A wait longer than MaxWait,
+// or one that would outlive the request's deadline, is not attempted: the
+// last response is returned as-is.
+//
+// The request's deadline is one budget for every attempt and every wait
+// between them. An http.Client.Timeout therefore bounds the whole retried
+// call, not each attempt: with a 30s timeout, a first attempt that hangs for
+// 25s leaves 5s for the retries.
+package httpretry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Defaults used when a Policy field is zero.
+const (
+	DefaultMaxRetries = 3
+	DefaultMaxWait    = 20 * time.Second
+	DefaultBaseDelay  = 500 * time.Millisecond
+)
+
+// Policy bounds the retries of a Transport.
+type Policy struct {
+	// MaxRetries is the number of retries after the first attempt; 0
+	// disables retrying.
+	MaxRetries int
+	// MaxWait caps a single wait, including one asked for by the server.
+	MaxWait time.Duration
+	// BaseDelay is the first backoff step; it doubles on every retry.
+	BaseDelay time.Duration
+}
+
+// DefaultPolicy is the policy the CLI uses unless configured otherwise.
+func DefaultPolicy() Policy {
+	return Policy{MaxRetries: DefaultMaxRetries, MaxWait: DefaultMaxWait, BaseDelay: DefaultBaseDelay}
+}
+
+// Transport retries requests according to Policy. The zero value retries
+// nothing; use New.
+type Transport struct {
+	// Base performs the requests; http.DefaultTransport when nil.
+	Base   http.RoundTripper
+	Policy Policy
+	// Logf, when set, is told about every retry (the --debug output).
+	Logf func(format string, args ...any)
+}
+
+// New wraps base with policy. A nil base means http.DefaultTransport.
+func New(base http.RoundTripper, policy Policy, logf func(format string, args ...any)) *Transport {
+	return &Transport{Base: base, Policy: policy, Logf: logf}
+}
+
+// sleep waits for d or until ctx is done. Tests replace it.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// now is the clock used to interpret Retry-After dates. Tests replace it.
+var now = time.Now
+
+// RoundTrip implements http.RoundTripper. Retries send a clone of req with a
+// fresh body; req itself is never modified.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// A body that can't be rewound can only be sent once.
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("rewind request body: %w", err)
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+		resp, err := base.RoundTrip(r)
+		if attempt >= t.Policy.MaxRetries || !replayable || !t.retryable(req, resp, err) {
+			return resp, err
+		}
+		wait, ok := t.delay(attempt, resp)
+		if !ok || !fitsDeadline(req.Context(), wait) {
+			return resp, err
+		}
+		t.logf("retrying %s %s in %s (attempt %d/%d): %s",
+			req.Method, req.URL.Redacted(), wait.Round(time.Millisecond), attempt+2, t.Policy.MaxRetries+1, reason(resp, err))
+		if resp != nil {
+			// Drain so the connection can be reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *Transport) logf(format string, args ...any) {
+	if t.Logf != nil {
+		t.Logf(format, args...)
+	}
+}
+
+// retryable decides whether the outcome of one attempt is worth another.
+func (t *Transport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		return idempotent(req) && transientErr(req.Context(), err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent(req)
+	}
+	return false
+}
+
+// delay returns how long to wait before retry number attempt+1, and false
+// when the server asks for longer than MaxWait.
+func (t *Transport) delay(attempt int, resp *http.Response) (time.Duration, bool) {
+	maxWait := t.Policy.MaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultMaxWait
+	}
+	if d, ok := serverDelay(resp); ok {
+		return d, d <= maxWait
+	}
+	base := t.Policy.BaseDelay
+	if base <= 0 {
+		base = DefaultBaseDelay
+	}
+	ceiling := min(base<<min(attempt, 16), maxWait)
+	// Equal jitter: spread concurrent clients over [ceiling/2, ceiling] while
+	// keeping at least half the backoff.
+	return ceiling/2 + rand.N(ceiling/2+1), true //nolint:gosec // jitter, not security-sensitive
+}
+
+// serverDelay reads the wait the server asked for, if any.
+func serverDelay(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return max(at.Sub(now()), 0), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now()), 0), true
+		}
+	}
+	return 0, false
+}
+
+// fitsDeadline reports whether waiting d still leaves the request's context
+// alive — http.Client.Timeout shows up here as a context deadline.
+func fitsDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || now().Add(d).Before(deadline)
+}
+
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// transientErr reports whether err is a connection-level failure rather than
+// the caller giving up.
+func transientErr(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if _, ok := errors.AsType[net.Error](err); ok {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+func reason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
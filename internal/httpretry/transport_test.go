@@ -0,0 +1,217 @@
+package httpretry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// noSleep replaces sleep for a test, recording the requested waits.
+func noSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	prev := sleep
+	sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	t.Cleanup(func() { sleep = prev })
+	return &waits
+}
+
+// flaky answers with statuses in order, then 200 once they run out; each
+// response carries headers(i) for the i-th call.
+func flaky(t *testing.T, statuses []int, headers func(i int) http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(calls.Add(1)) - 1
+		body, _ := io.ReadAll(r.Body)
+		if headers != nil {
+			for k, v := range headers(i) {
+				w.Header()[k] = v
+			}
+		}
+		if i < len(statuses) {
+			w.WriteHeader(statuses[i])
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func client() *http.Client {
+	return &http.Client{Transport: New(nil, DefaultPolicy(), nil)}
+}
+
+func TestTransport_RetriesRateLimitHonouringRetryAfter(t *testing.T) {
+	waits := noSleep(t)
+	srv, calls := flaky(t, []int{429, 429}, func(int) http.Header {
+		return http.Header{"Retry-After": {"2"}}
+	})
+
+	resp, err := client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("status %d after %d calls, want 200 after 3", resp.StatusCode, calls.Load())
+	}
+	if len(*waits) != 2 || (*waits)[0] != 2*time.Second {
+		t.Errorf("waits = %v, want two of 2s", *waits)
+	}
+}
+
+func TestTransport_RetriesPostBodyOnRateLimit(t *testing.T) {
+	noSleep(t)
+	srv, calls := flaky(t, []int{429}, nil)
+
+	resp, err := client().Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if calls.Load() != 2 || string(body) != "payload" {
+		t.Fatalf("calls=%d body=%q, want the body replayed on the second call", calls.Load(), body)
+	}
+}
+
+func TestTransport_RetryLeavesCallerRequestAlone(t *testing.T) {
+	noSleep(t)
+	srv, calls := flaky(t, []int{429, 429}, nil)
+
+	req, err := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := req.Body
+	resp, err := New(nil, DefaultPolicy(), nil).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if calls.Load() != 3 || string(body) != "payload" {
+		t.Fatalf("calls=%d body=%q, want the body replayed on every retry", calls.Load(), body)
+	}
+	if req.Body != orig {
+		t.Error("RoundTrip replaced the caller's request body")
+	}
+}
+
+func TestTransport_DoesNotRetryNonIdempotentOnBadGateway(t *testing.T) {
+	noSleep(t)
+	srv, calls := flaky(t, []int{502}, nil)
+
+	resp, err := client().Post(srv.URL, "text/plain", strings.NewReader("x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls.Load() != 1 {
+		t.Fatalf("status %d after %d calls, want a single 502", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	waits := noSleep(t)
+	srv, calls := flaky(t, []int{503, 503, 503, 503, 503}, nil)
+
+	resp, err := client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != DefaultMaxRetries+1 {
+		t.Fatalf("status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+	// Exponential backoff with jitter: each wait within [ceiling/2, ceiling].
+	for i, w := range *waits {
+		ceiling := DefaultBaseDelay << i
+		if w < ceiling/2 || w > ceiling {
+			t.Errorf("wait %d = %v, want within [%v, %v]", i, w, ceiling/2, ceiling)
+		}
+	}
+}
+
+func TestTransport_ServerWaitBeyondMaxWaitIsNotAttempted(t *testing.T) {
+	waits := noSleep(t)
+	srv, calls := flaky(t, []int{429}, func(int) http.Header {
+		return http.Header{"Retry-After": {"3600"}}
+	})
+
+	resp, err := client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 || len(*waits) != 0 {
+		t.Fatalf("status %d, calls %d, waits %v; want the 429 returned at once", resp.StatusCode, calls.Load(), *waits)
+	}
+}
+
+func TestTransport_RateLimitResetHeader(t *testing.T) {
+	waits := noSleep(t)
+	fixed := time.Unix(1_700_000_000, 0)
+	prev := now
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = prev })
+	srv, _ := flaky(t, []int{429}, func(int) http.Header {
+		return http.Header{
+			"X-Ratelimit-Remaining": {"0"},
+			"X-Ratelimit-Reset":     {strconv.FormatInt(fixed.Add(5*time.Second).Unix(), 10)},
+		}
+	})
+
+	resp, err := client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if len(*waits) != 1 || (*waits)[0] != 5*time.Second {
+		t.Fatalf("waits = %v, want 5s until the reset", *waits)
+	}
+}
+
+func TestTransport_LogsRetries(t *testing.T) {
+	noSleep(t)
+	srv, _ := flaky(t, []int{504}, nil)
+	var logged []string
+	c := &http.Client{Transport: New(nil, DefaultPolicy(), func(format string, args ...any) {
+		logged = append(logged, format)
+	})}
+
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if len(logged) != 1 {
+		t.Fatalf("logged %d lines, want 1", len(logged))
+	}
+}
+
+func TestTransport_ZeroRetriesDisables(t *testing.T) {
+	noSleep(t)
+	srv, calls := flaky(t, []int{429}, nil)
+	c := &http.Client{Transport: New(nil, Policy{}, nil)}
+
+	resp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want 1 with retries disabled", calls.Load())
+	}
+}
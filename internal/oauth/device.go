@@ -0,0 +1,136 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/bitrise-io/bitrise-cli/internal/auth"
+)
+
+// Device flow polling, per RFC 8628 §3.5: the default interval when the
+// server names none, and the back-off added on every slow_down.
+const (
+	defaultDeviceInterval = 5 * time.Second
+	slowDownStep          = 5 * time.Second
+)
+
+// deviceGrantType is the grant_type for polling the token endpoint.
+const deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+var errDeviceCodeExpired = errors.New("device sign-in timed out: the code expired before it was approved — run 'bitrise-cli auth login --device' again")
+
+// sleep waits d or until ctx is done. Tests replace it to poll instantly.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// deviceAuthorization is the device authorization response (RFC 8628 §3.2).
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// DeviceLogin signs in with the device authorization grant (RFC 8628): it
+// prints a verification URL and user code to stderr for the user to open on
+// any device, polls the token endpoint until they approve, and then does the
+// same JWT→PAT exchange as Login. It suits hosts where the browser can't
+// reach the CLI's loopback address, such as SSH sessions and RDE machines.
+// Like Login it persists nothing.
+func (c Config) DeviceLogin(ctx context.Context, stderr io.Writer) (auth.Auth, error) {
+	if err := c.checkConfigured(); err != nil {
+		return auth.Auth{}, err
+	}
+
+	form := url.Values{
+		"client_id": {c.ClientID},
+		"scope":     {"openid offline_access"},
+	}
+	if c.Resource != "" {
+		form.Set("resource", c.Resource)
+	}
+	var da deviceAuthorization
+	if err := c.postFormDecode(ctx, c.deviceAuthorizationEndpoint(), form, &da); err != nil {
+		return auth.Auth{}, fmt.Errorf("start device sign-in: %w", err)
+	}
+	if da.DeviceCode == "" || da.UserCode == "" || da.VerificationURI == "" {
+		return auth.Auth{}, errors.New("start device sign-in: response is missing device_code, user_code, or verification_uri")
+	}
+
+	msg := fmt.Sprintf("To sign in to Bitrise, open this URL on any device:\n\n  %s\n\nand enter the code:\n\n  %s\n\n", da.VerificationURI, da.UserCode)
+	if da.VerificationURIComplete != "" {
+		msg += fmt.Sprintf("Or open this link, which has the code filled in:\n\n  %s\n\n", da.VerificationURIComplete)
+	}
+	if _, err := fmt.Fprint(stderr, msg+"Waiting for you to approve the sign-in...\n"); err != nil {
+		return auth.Auth{}, err
+	}
+
+	lifetime := loginTimeout
+	if da.ExpiresIn > 0 {
+		lifetime = time.Duration(da.ExpiresIn) * time.Second
+	}
+	pollCtx, cancel := context.WithTimeout(ctx, lifetime)
+	defer cancel()
+
+	jwtResp, err := c.pollDeviceToken(pollCtx, da)
+	if err != nil {
+		return auth.Auth{}, err
+	}
+	return c.finishLogin(ctx, jwtResp)
+}
+
+// pollDeviceToken polls the token endpoint until the user approves or
+// denies the request, or the device code expires. authorization_pending
+// keeps polling; slow_down keeps polling with a longer interval.
+func (c Config) pollDeviceToken(ctx context.Context, da deviceAuthorization) (tokenResponse, error) {
+	interval := defaultDeviceInterval
+	if da.Interval > 0 {
+		interval = time.Duration(da.Interval) * time.Second
+	}
+	form := url.Values{
+		"grant_type":  {deviceGrantType},
+		"device_code": {da.DeviceCode},
+		"client_id":   {c.ClientID},
+	}
+	for {
+		if err := sleep(ctx, interval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return tokenResponse{}, errDeviceCodeExpired
+			}
+			return tokenResponse{}, err
+		}
+		resp, err := c.postForm(ctx, c.tokenEndpoint(), form)
+		if err == nil {
+			return resp, nil
+		}
+		te, ok := errors.AsType[*tokenError](err)
+		if !ok {
+			return tokenResponse{}, fmt.Errorf("poll for device sign-in: %w", err)
+		}
+		switch te.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += slowDownStep
+		case "access_denied":
+			return tokenResponse{}, errors.New("device sign-in was denied")
+		case "expired_token":
+			return tokenResponse{}, errDeviceCodeExpired
+		default:
+			return tokenResponse{}, fmt.Errorf("poll for device sign-in: %w", err)
+		}
+	}
+}
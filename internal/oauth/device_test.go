@@ -0,0 +1,90 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// instantSleep replaces sleep for a test, recording the requested intervals.
+func instantSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	prev := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = prev })
+	return &waits
+}
+
+func TestDeviceLogin_HappyPath(t *testing.T) {
+	waits := instantSleep(t)
+	m := newOAuthMock()
+	defer m.close()
+	m.devicePolls = []string{"authorization_pending", "slow_down", "authorization_pending"}
+
+	stderr := &bytes.Buffer{}
+	a, err := m.config().DeviceLogin(context.Background(), stderr)
+	if err != nil {
+		t.Fatalf("DeviceLogin: %v", err)
+	}
+	if a.Token != "bitpat_minted" || a.RefreshToken != "refresh-1" || !a.IsOAuthManaged() {
+		t.Fatalf("credentials = %+v, want a refreshable PAT", a)
+	}
+	if a.TokenExpiry.IsZero() || a.JWTExpiry.IsZero() {
+		t.Fatal("expiries should be set after login")
+	}
+	out := stderr.String()
+	for _, want := range []string{"WDJB-MJHT", m.server.URL + "/device", "user_code=WDJB-MJHT"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stderr missing %q:\n%s", want, out)
+		}
+	}
+
+	// Server interval 2s, bumped by 5s from the slow_down onwards.
+	want := []time.Duration{2 * time.Second, 2 * time.Second, 7 * time.Second, 7 * time.Second}
+	if len(*waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", *waits, want)
+	}
+	for i := range want {
+		if (*waits)[i] != want[i] {
+			t.Fatalf("waits = %v, want %v", *waits, want)
+		}
+	}
+	if tc, ec := m.counts(); tc != 4 || ec != 1 {
+		t.Fatalf("expected 4 polls + 1 PAT exchange; got token=%d exchange=%d", tc, ec)
+	}
+}
+
+func TestDeviceLogin_TerminalErrors(t *testing.T) {
+	for code, want := range map[string]string{
+		"access_denied": "denied",
+		"expired_token": "expired",
+		"invalid_grant": "invalid_grant",
+	} {
+		t.Run(code, func(t *testing.T) {
+			instantSleep(t)
+			m := newOAuthMock()
+			defer m.close()
+			m.devicePolls = []string{"authorization_pending", code}
+
+			_, err := m.config().DeviceLogin(context.Background(), &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Fatalf("err = %v, want containing %q", err, want)
+			}
+			if _, ec := m.counts(); ec != 0 {
+				t.Fatalf("no PAT exchange expected after %s, got %d", code, ec)
+			}
+		})
+	}
+}
+
+func TestDeviceLogin_GuardsMissingConfig(t *testing.T) {
+	if _, err := (Config{ClientID: "x"}).DeviceLogin(context.Background(), &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "issuer") {
+		t.Fatalf("expected missing-issuer error, got %v", err)
+	}
+}
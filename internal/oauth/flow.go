@@ -19,17 +19,26 @@ const loginTimeout = 5 * time.Minute
 // user must sign in again.
 var ErrLoginRequired = errors.New("OAuth session expired — run 'bitrise-cli auth login --oauth' to sign in again")
 
+// checkConfigured reports a missing issuer or client_id, which both login
+// flows need.
+func (c Config) checkConfigured() error {
+	if c.Issuer == "" {
+		return errors.New("OAuth login is not configured: no issuer (set BITRISE_OAUTH_ISSUER)")
+	}
+	if c.ClientID == "" {
+		return errors.New("OAuth login is not available in this build yet: no client_id is compiled in (a pending setup step — see ER-2774). Use 'auth login' or 'auth login --email' for now")
+	}
+	return nil
+}
+
 // Login runs the full browser authorization + token exchange and returns a
 // populated auth.Auth (PAT + JWT + refresh token + expiries). It does not
 // persist anything; the caller saves the result. openBrowser opens the
 // authorize URL (nil to skip auto-open); progress and the URL are written to
 // stderr so the user can open it manually.
 func (c Config) Login(ctx context.Context, openBrowser func(string) error, stderr io.Writer) (auth.Auth, error) {
-	if c.Issuer == "" {
-		return auth.Auth{}, errors.New("OAuth login is not configured: no issuer (set BITRISE_OAUTH_ISSUER)")
-	}
-	if c.ClientID == "" {
-		return auth.Auth{}, errors.New("OAuth login is not available in this build yet: no client_id is compiled in (a pending setup step — see ER-2774). Use 'auth login' or 'auth login --email' for now")
+	if err := c.checkConfigured(); err != nil {
+		return auth.Auth{}, err
 	}
 
 	state, err := newState()
@@ -71,6 +80,12 @@ func (c Config) Login(ctx context.Context, openBrowser func(string) error, stder
 	if err != nil {
 		return auth.Auth{}, fmt.Errorf("exchange authorization code: %w", err)
 	}
+	return c.finishLogin(ctx, jwtResp)
+}
+
+// finishLogin exchanges the JWT from a completed sign-in for a PAT and
+// assembles the credentials to persist.
+func (c Config) finishLogin(ctx context.Context, jwtResp tokenResponse) (auth.Auth, error) {
 	pat, patExpiry, err := c.exchangeJWTForPAT(ctx, jwtResp.AccessToken)
 	if err != nil {
 		return auth.Auth{}, fmt.Errorf("exchange token for a Bitrise PAT: %w", err)
@@ -107,8 +122,8 @@ func (c Config) authorizeURL(challenge, state, redirectURI string) string {
 // EnsureFreshPAT returns a usable Bitrise PAT, refreshing it without a browser
 // when needed. resolvedToken is the token the config layer already resolved
 // (env handled by the caller; this value is the auth.yaml token in practice);
-// it is returned as-is unless auth.yaml holds an OAuth-managed token that can
-// be refreshed. The ladder:
+// it is returned as-is unless c.Profile's auth.yaml entry holds an
+// OAuth-managed token that can be refreshed. The ladder:
 //
 //	PAT valid             → return it
 //	PAT expired           → exchange JWT → new PAT
@@ -118,7 +133,7 @@ func (c Config) authorizeURL(challenge, state, redirectURI string) string {
 // A manually pasted / email-login token (no refresh token) is returned
 // untouched — manual tokens are never refreshed.
 func (c Config) EnsureFreshPAT(ctx context.Context, resolvedToken string) (string, error) {
-	a, err := auth.Load()
+	a, err := auth.LoadProfile(c.Profile)
 	if err != nil {
 		return "", err
 	}
@@ -138,7 +153,7 @@ func (c Config) EnsureFreshPAT(ctx context.Context, resolvedToken string) (strin
 		pat, expiry, err := c.exchangeJWTForPAT(ctx, a.JWT)
 		if err == nil {
 			a.Token, a.TokenExpiry = pat, expiry
-			if err := auth.Save(a); err != nil {
+			if err := auth.SaveProfile(c.Profile, a); err != nil {
 				return "", err
 			}
 			return pat, nil
@@ -166,7 +181,7 @@ func (c Config) EnsureFreshPAT(ctx context.Context, resolvedToken string) (strin
 		return "", fmt.Errorf("exchange refreshed token for a PAT: %w", err)
 	}
 	a.Token, a.TokenExpiry = pat, expiry
-	if err := auth.Save(a); err != nil {
+	if err := auth.SaveProfile(c.Profile, a); err != nil {
 		return "", err
 	}
 	return pat, nil
@@ -31,6 +31,11 @@ type oauthMock struct {
 	pat          string
 	patExpiresIn int64
 	failRefresh  bool
+
+	// devicePolls are the OAuth error codes answered to device-code polls,
+	// in order, before the poll that succeeds.
+	devicePolls []string
+	deviceCalls int // /oauth2/device_authorization
 }
 
 func newOAuthMock() *oauthMock {
@@ -48,6 +53,22 @@ func newOAuthMock() *oauthMock {
 		fail := m.failRefresh
 		m.mu.Unlock()
 		_ = r.ParseForm()
+		if r.FormValue("grant_type") == deviceGrantType {
+			m.mu.Lock()
+			var code string
+			if len(m.devicePolls) > 0 {
+				code, m.devicePolls = m.devicePolls[0], m.devicePolls[1:]
+			}
+			m.mu.Unlock()
+			if r.FormValue("device_code") != "dev-code" {
+				code = "invalid_grant"
+			}
+			if code != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+				return
+			}
+		}
 		if r.FormValue("grant_type") == "refresh_token" && fail {
 			w.WriteHeader(http.StatusBadRequest)
 			_, _ = io.WriteString(w, `{"error":"invalid_grant"}`)
@@ -60,6 +81,25 @@ func newOAuthMock() *oauthMock {
 			"token_type":    "Bearer",
 		})
 	})
+	mux.HandleFunc("/oauth2/device_authorization", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		m.deviceCalls++
+		m.mu.Unlock()
+		_ = r.ParseForm()
+		if r.FormValue("client_id") == "" || !strings.Contains(r.FormValue("scope"), "offline_access") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"error":"invalid_request"}`)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"device_code":               "dev-code",
+			"user_code":                 "WDJB-MJHT",
+			"verification_uri":          m.server.URL + "/device",
+			"verification_uri_complete": m.server.URL + "/device?user_code=WDJB-MJHT",
+			"expires_in":                600,
+			"interval":                  2,
+		})
+	})
 	mux.HandleFunc("/oidc/token", func(w http.ResponseWriter, _ *http.Request) {
 		m.mu.Lock()
 		m.exchangeCalls++
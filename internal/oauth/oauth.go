@@ -10,6 +10,11 @@
 //  3. exchange that JWT for a Bitrise PAT at the monolith's OIDC token
 //     endpoint (RFC 8693 token exchange — the same call the MCP server makes).
 //
+// Hosts where the browser can't reach a loopback address (SSH sessions, RDE
+// machines) use the device authorization grant (RFC 8628) for steps 1–2
+// instead: the CLI shows a URL and a short code to enter on any device, and
+// polls the token endpoint until the user approves. Step 3 is the same.
+//
 // The PAT is the working credential every command uses, stored on disk exactly
 // like a pasted token. The JWT, refresh token, and expiries are stored
 // alongside it so EnsureFreshPAT can mint a new PAT without a browser when the
@@ -64,6 +69,12 @@ type Config struct {
 	Resource string
 	// HTTPClient, when set, overrides the default client (used by tests).
 	HTTPClient *http.Client
+	// Transport, when set, carries the default client's requests; the CLI
+	// passes its --debug tracer here.
+	Transport http.RoundTripper
+	// Profile names the auth.yaml profile whose token EnsureFreshPAT reads
+	// and refreshes; empty means the default profile.
+	Profile string
 }
 
 // NewConfig returns a Config for the OAuth flow from the supplied (resolved)
@@ -84,13 +95,17 @@ func (c Config) httpClient() *http.Client {
 	if c.HTTPClient != nil {
 		return c.HTTPClient
 	}
-	return &http.Client{Timeout: defaultTimeout}
+	return &http.Client{Timeout: defaultTimeout, Transport: c.Transport}
 }
 
 func (c Config) authorizeEndpoint() string {
 	return strings.TrimRight(c.Issuer, "/") + "/oauth2/authorize"
 }
 
+func (c Config) deviceAuthorizationEndpoint() string {
+	return strings.TrimRight(c.Issuer, "/") + "/oauth2/device_authorization"
+}
+
 func (c Config) tokenEndpoint() string {
 	return strings.TrimRight(c.Issuer, "/") + "/oauth2/token"
 }
@@ -22,6 +22,20 @@ type tokenResponse struct {
 	TokenType    string `json:"token_type"`
 }
 
+// tokenError is a non-200 answer from a token endpoint. Code is the OAuth
+// error code (RFC 6749 §5.2) when the body carries one; the device flow
+// branches on it.
+type tokenError struct {
+	Endpoint   string
+	StatusCode int
+	Code       string
+	Body       string
+}
+
+func (e *tokenError) Error() string {
+	return fmt.Sprintf("token endpoint %s returned %d: %s", e.Endpoint, e.StatusCode, e.Body)
+}
+
 // exchangeCodeForJWT trades an authorization code for a JWT + refresh token at
 // the WorkOS token endpoint (grant_type=authorization_code). redirectURI must
 // match the one sent on the authorize request.
@@ -69,32 +83,48 @@ func (c Config) exchangeJWTForPAT(ctx context.Context, jwt string) (string, time
 }
 
 func (c Config) postForm(ctx context.Context, endpoint string, form url.Values) (tokenResponse, error) {
+	var tr tokenResponse
+	if err := c.postFormDecode(ctx, endpoint, form, &tr); err != nil {
+		return tokenResponse{}, err
+	}
+	return tr, nil
+}
+
+// postFormDecode posts form to endpoint and decodes a 200 JSON answer into
+// v; any other status is a *tokenError.
+func (c Config) postFormDecode(ctx context.Context, endpoint string, form url.Values, v any) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
 	if err != nil {
-		return tokenResponse{}, fmt.Errorf("create token request: %w", err)
+		return fmt.Errorf("create token request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.httpClient().Do(req)
 	if err != nil {
-		return tokenResponse{}, fmt.Errorf("token request: %w", err)
+		return fmt.Errorf("token request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return tokenResponse{}, fmt.Errorf("read token response: %w", err)
+		return fmt.Errorf("read token response: %w", err)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return tokenResponse{}, fmt.Errorf("token endpoint %s returned %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+		te := &tokenError{Endpoint: endpoint, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+		var oe struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &oe) == nil {
+			te.Code = oe.Error
+		}
+		return te
 	}
 
-	var tr tokenResponse
-	if err := json.Unmarshal(body, &tr); err != nil {
-		return tokenResponse{}, fmt.Errorf("parse token response: %w", err)
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parse token response: %w", err)
 	}
-	return tr, nil
+	return nil
 }
 
 // jwtExpiry decides when a freshly obtained JWT expires: prefer the response's
@@ -0,0 +1,171 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/itchyny/gojq"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+)
+
+// Options are the process-wide output refinements set by the persistent
+// --columns, --template, and --jq flags.
+type Options struct {
+	// Columns picks and orders the columns of human tables and of CSV/TSV
+	// output. Names match case-insensitively, with "-" and " " equal to "_".
+	Columns []string
+	// Template is a Go text/template executed against the JSON document.
+	Template string
+	// JQ is a jq expression applied to the JSON document; string results
+	// print raw, everything else as indented JSON.
+	JQ string
+}
+
+// configured is the compiled form of Options.
+type configured struct {
+	columns []string
+	tmpl    *template.Template
+	jq      *gojq.Code
+}
+
+func (c configured) filtering() bool { return c.tmpl != nil || c.jq != nil }
+
+var active configured
+
+// Filtering reports whether --template or --jq is set, i.e. whether Render
+// will run the filter instead of the requested format. Commands that
+// normally pass bytes through untouched (`api`) use it to decide to decode
+// them.
+func Filtering() bool { return active.filtering() }
+
+// FilterFlag names the filter flag in effect, "--jq" or "--template", for
+// error messages; "" when neither is set.
+func FilterFlag() string {
+	switch {
+	case active.jq != nil:
+		return "--jq"
+	case active.tmpl != nil:
+		return "--template"
+	}
+	return ""
+}
+
+// Configure validates and applies opts process-wide. Call once from the cmd
+// layer's persistentPreRun, next to style.Configure. Columns also reach
+// style.Table, so a command's primary human table honours them.
+func Configure(opts Options) error {
+	var c configured
+	if opts.Template != "" && opts.JQ != "" {
+		return errors.New("--template and --jq cannot be combined")
+	}
+	for _, col := range opts.Columns {
+		if col = strings.TrimSpace(col); col != "" {
+			c.columns = append(c.columns, col)
+		}
+	}
+	if opts.Template != "" {
+		t, err := template.New("output").Funcs(templateFuncs).Parse(opts.Template)
+		if err != nil {
+			return fmt.Errorf("parse --template: %w", err)
+		}
+		c.tmpl = t
+	}
+	if opts.JQ != "" {
+		q, err := gojq.Parse(opts.JQ)
+		if err != nil {
+			return fmt.Errorf("parse --jq: %w", err)
+		}
+		code, err := gojq.Compile(q)
+		if err != nil {
+			return fmt.Errorf("compile --jq: %w", err)
+		}
+		c.jq = code
+	}
+	active = c
+	style.SelectColumns(c.columns)
+	return nil
+}
+
+// templateFuncs are the helpers available to --template on top of the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.MarshalIndent(v, "", "  ")
+		return string(b), err
+	},
+	"join": func(sep string, v any) string {
+		list, _ := v.([]any) // null joins to ""
+		parts := make([]string, len(list))
+		for i, e := range list {
+			parts[i] = fmt.Sprint(e)
+		}
+		return strings.Join(parts, sep)
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"truncate": func(n int, s string) string {
+		if r := []rune(s); len(r) > n {
+			return string(r[:max(n-1, 0)]) + "…"
+		}
+		return s
+	},
+}
+
+// renderFiltered runs the configured --template or --jq against v's JSON
+// document.
+func renderFiltered(w io.Writer, v any) error {
+	doc, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	if active.tmpl != nil {
+		if err := active.tmpl.Execute(w, doc); err != nil {
+			return fmt.Errorf("execute --template: %w", err)
+		}
+		return nil
+	}
+	iter := active.jq.Run(doc)
+	for {
+		res, ok := iter.Next()
+		if !ok {
+			return nil
+		}
+		if err, ok := res.(error); ok {
+			if err, ok := err.(*gojq.HaltError); ok && err.Value() == nil {
+				return nil
+			}
+			return fmt.Errorf("--jq: %w", err)
+		}
+		if s, ok := res.(string); ok {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return err
+			}
+			continue
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	}
+}
+
+// toGeneric round-trips v through JSON into the map[string]any / []any form
+// templates and gojq operate on, so they see exactly the JSON field names.
+func toGeneric(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode output: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("decode output: %w", err)
+	}
+	return doc, nil
+}
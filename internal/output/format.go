@@ -1,9 +1,14 @@
-// Package output formats command results as either human-readable text or
-// machine-parseable JSON. JSON mode is the contract used by automation and
-// AI agents; human mode is the default for interactive use.
+// Package output formats command results as human-readable text or one of
+// the machine-parseable formats (JSON, YAML, CSV, TSV). JSON mode is the
+// contract used by automation and AI agents; human mode is the default for
+// interactive use. The process-wide --columns, --template, and --jq options
+// (see Configure) apply to every Render call, so each command gets them
+// without extra code; commands that stream events use Streaming and Emit
+// instead.
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,31 +25,55 @@ const (
 	// JSON emits the response as indented JSON. The schema is part of the
 	// CLI's stable contract — additive changes only.
 	JSON Format = "json"
+	// YAML emits the same document as JSON, as YAML, keeping field order.
+	YAML Format = "yaml"
+	// CSV and TSV flatten the document's rows (a top-level list, or the
+	// first list of objects in it) into a header line plus one line per
+	// row; nested fields become dotted column names.
+	CSV Format = "csv"
+	TSV Format = "tsv"
 )
 
 func (f Format) String() string { return string(f) }
 
+// Structured reports whether f is a machine-readable document format —
+// anything but Human. Commands that behave differently for scripts (no
+// prompts, no progress chatter on stdout, no long-lived streams) branch on
+// it rather than on JSON alone.
+func (f Format) Structured() bool { return f != Human }
+
 // ParseFormat validates a user-supplied --output value. The empty string
 // resolves to Human so callers can pass cmd flag values directly.
 func ParseFormat(s string) (Format, error) {
 	switch s {
-	case "", "human":
+	case "":
 		return Human, nil
-	case "json":
-		return JSON, nil
+	case string(Human), string(JSON), string(YAML), string(CSV), string(TSV):
+		return Format(s), nil
 	default:
-		return "", fmt.Errorf("unsupported output format %q (expected: human, json)", s)
+		return "", fmt.Errorf("unsupported output format %q (expected: human, json, yaml, csv, tsv)", s)
 	}
 }
 
-// Render writes v to w. In JSON mode v is marshaled directly; in Human mode
-// the per-command renderHuman callback formats the value.
+// Render writes v to w. The machine formats are derived from v's JSON
+// encoding; in Human mode the per-command renderHuman callback formats the
+// value. When --jq or --template is configured, its result replaces the
+// format's output.
 func Render[T any](w io.Writer, format Format, v T, renderHuman func(io.Writer, T) error) error {
+	if active.filtering() {
+		return renderFiltered(w, v)
+	}
 	switch format {
 	case JSON:
 		enc := json.NewEncoder(w)
 		enc.SetIndent("", "  ")
 		return enc.Encode(v)
+	case YAML:
+		return renderYAML(w, v)
+	case CSV:
+		return renderDelimited(w, v, ',')
+	case TSV:
+		return renderDelimited(w, v, '\t')
 	case Human:
 		if renderHuman == nil {
 			return fmt.Errorf("no human renderer provided for value of type %T", v)
@@ -54,3 +83,28 @@ func Render[T any](w io.Writer, format Format, v T, renderHuman func(io.Writer,
 		return fmt.Errorf("unknown output format: %q", format)
 	}
 }
+
+// Streaming reports whether a command that streams events (watch,
+// port-forward) should write them with Emit rather than as human lines:
+// under --output json, --template, or --jq.
+func Streaming(format Format) bool {
+	return format == JSON || active.filtering()
+}
+
+// Emit writes one streamed event: v as a single JSON line (NDJSON), or the
+// --template / --jq result for v, ending in a newline so events stay one per
+// line.
+func Emit(w io.Writer, v any) error {
+	if !active.filtering() {
+		return json.NewEncoder(w).Encode(v)
+	}
+	var buf bytes.Buffer
+	if err := renderFiltered(&buf, v); err != nil {
+		return err
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -18,8 +18,11 @@ func TestParseFormat(t *testing.T) {
 		{"", Human, false}, // empty defaults to human
 		{"human", Human, false},
 		{"json", JSON, false},
+		{"yaml", YAML, false},
+		{"csv", CSV, false},
+		{"tsv", TSV, false},
 		{"text", "", true},  // legacy alias is intentionally rejected
-		{"yaml", "", true},  // not yet supported
+		{"yml", "", true},   // no aliases
 		{"HUMAN", "", true}, // case-sensitive on purpose
 	}
 	for _, c := range cases {
@@ -89,7 +92,7 @@ func TestRender_HumanRequiresRenderer(t *testing.T) {
 
 func TestRender_UnknownFormat(t *testing.T) {
 	var buf bytes.Buffer
-	err := Render[sample](&buf, Format("yaml"), sample{}, nil)
+	err := Render[sample](&buf, Format("xml"), sample{}, nil)
 	if err == nil {
 		t.Fatal("expected error for unknown format")
 	}
@@ -104,3 +107,167 @@ func TestRender_PropagatesHumanError(t *testing.T) {
 		t.Fatalf("got %v, want %v", err, wantErr)
 	}
 }
+
+// configure applies opts for one test.
+func configure(t *testing.T, opts Options) {
+	t.Helper()
+	if err := Configure(opts); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	t.Cleanup(func() { _ = Configure(Options{}) })
+}
+
+type page struct {
+	Items []item `json:"items"`
+	Next  string `json:"next,omitempty"`
+}
+
+type item struct {
+	Slug  string   `json:"slug"`
+	Title string   `json:"title"`
+	Owner owner    `json:"owner"`
+	Tags  []string `json:"tags"`
+	Build *int     `json:"build"`
+}
+
+type owner struct {
+	Name string `json:"name"`
+}
+
+var samplePage = page{Items: []item{
+	{Slug: "a1", Title: "Alpha, Inc", Owner: owner{"ann"}, Tags: []string{"ios", "prod"}},
+	{Slug: "b2", Title: "Beta", Owner: owner{"bob"}},
+}, Next: "b2"}
+
+func TestRender_YAMLKeepsFieldOrder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, YAML, samplePage, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := `items:
+  - slug: a1
+    title: Alpha, Inc
+    owner:
+      name: ann
+    tags:
+      - ios
+      - prod
+    build: null
+  - slug: b2
+    title: Beta
+    owner:
+      name: bob
+    tags: null
+    build: null
+next: b2
+`
+	if buf.String() != want {
+		t.Errorf("YAML:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestRender_CSVAndTSVFlattenRows(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, CSV, samplePage, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "slug,title,owner.name,tags,build\na1,\"Alpha, Inc\",ann,\"ios,prod\",\nb2,Beta,bob,,\n"
+	if buf.String() != want {
+		t.Errorf("CSV = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	configure(t, Options{Columns: []string{"OWNER.NAME", "slug"}})
+	if err := Render(&buf, TSV, samplePage, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "owner.name\tslug\nann\ta1\nbob\tb2\n"; buf.String() != want {
+		t.Errorf("TSV with --columns = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	configure(t, Options{Columns: []string{"slug", "omitted"}})
+	if err := Render(&buf, CSV, page{Items: []item{}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "slug,omitted\n"; buf.String() != want {
+		t.Errorf("empty page = %q, want just the header %q", buf.String(), want)
+	}
+}
+
+func TestRender_JQAndTemplate(t *testing.T) {
+	var buf bytes.Buffer
+	configure(t, Options{JQ: ".items[] | .slug"})
+	if err := Render(&buf, Human, samplePage, func(io.Writer, page) error { return errors.New("human renderer must not run") }); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "a1\nb2\n" {
+		t.Errorf("--jq strings = %q", buf.String())
+	}
+
+	buf.Reset()
+	configure(t, Options{JQ: "{n: (.items | length)}"})
+	if err := Render(&buf, JSON, samplePage, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "{\n  \"n\": 2\n}\n" {
+		t.Errorf("--jq object = %q", buf.String())
+	}
+
+	buf.Reset()
+	configure(t, Options{Template: `{{range .items}}{{.slug}}={{upper .owner.name}} {{join "+" .tags}}{{"\n"}}{{end}}`})
+	if err := Render(&buf, JSON, samplePage, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "a1=ANN ios+prod\nb2=BOB \n" {
+		t.Errorf("--template = %q", buf.String())
+	}
+}
+
+func TestEmit_OneLinePerEvent(t *testing.T) {
+	type event struct {
+		Type string `json:"type"`
+		N    int    `json:"n"`
+	}
+	var buf bytes.Buffer
+	configure(t, Options{})
+	if Streaming(Human) || !Streaming(JSON) {
+		t.Error("without a filter only --output json streams")
+	}
+	_ = Emit(&buf, event{"ready", 1})
+	if buf.String() != "{\"type\":\"ready\",\"n\":1}\n" {
+		t.Errorf("NDJSON = %q", buf.String())
+	}
+
+	buf.Reset()
+	configure(t, Options{Template: "{{.type}}:{{.n}}"})
+	if !Streaming(Human) {
+		t.Error("--template should stream events")
+	}
+	_ = Emit(&buf, event{"ready", 1})
+	_ = Emit(&buf, event{"gone", 2})
+	if buf.String() != "ready:1\ngone:2\n" {
+		t.Errorf("--template per event = %q", buf.String())
+	}
+
+	buf.Reset()
+	configure(t, Options{JQ: `select(.type == "gone") | .n`})
+	_ = Emit(&buf, event{"ready", 1})
+	_ = Emit(&buf, event{"gone", 2})
+	if buf.String() != "2\n" {
+		t.Errorf("--jq per event = %q", buf.String())
+	}
+}
+
+func TestConfigure_RejectsBadInput(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Options{}) })
+	for _, opts := range []Options{
+		{JQ: ".items[", Template: ""},
+		{Template: "{{.x"},
+		{JQ: ".", Template: "x"},
+	} {
+		if err := Configure(opts); err == nil {
+			t.Errorf("Configure(%+v) should fail", opts)
+		}
+	}
+}
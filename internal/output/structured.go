@@ -0,0 +1,218 @@
+package output
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bitrise-io/bitrise-cli/internal/output/style"
+)
+
+// renderYAML writes v's JSON document as YAML. Going through JSON keeps the
+// field names and omitempty behaviour of the JSON contract, and building the
+// node tree from the token stream keeps the struct field order.
+func renderYAML(w io.Writer, v any) error {
+	n, err := toNode(v)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return fmt.Errorf("encode YAML: %w", err)
+	}
+	return enc.Close()
+}
+
+// renderDelimited writes the rows of v's JSON document as CSV (or TSV with
+// comma '\t'): a header line, then one line per row.
+func renderDelimited(w io.Writer, v any, comma rune) error {
+	n, err := toNode(v)
+	if err != nil {
+		return err
+	}
+	var (
+		header []string
+		rows   []map[string]string
+	)
+	for _, r := range tableRows(n) {
+		row := map[string]string{}
+		flatten(r, "", row, &header)
+		rows = append(rows, row)
+	}
+	header = selectColumns(header, active.columns)
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		rec := make([]string, len(header))
+		for i, h := range header {
+			rec[i] = row[h]
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// tableRows picks the rows of a document: the elements of a top-level list,
+// else those of its "items" or "data" list (a page, possibly empty), else
+// those of the first list of objects among its fields, else the document
+// itself as a single row.
+func tableRows(n *yaml.Node) []*yaml.Node {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		return n.Content
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			if k, f := n.Content[i-1].Value, n.Content[i]; f.Kind == yaml.SequenceNode && (k == "items" || k == "data") {
+				return f.Content
+			}
+		}
+		for i := 1; i < len(n.Content); i += 2 {
+			if f := n.Content[i]; f.Kind == yaml.SequenceNode && len(f.Content) > 0 && f.Content[0].Kind == yaml.MappingNode {
+				return f.Content
+			}
+		}
+	}
+	return []*yaml.Node{n}
+}
+
+// flatten writes the cells of n into row, naming nested fields with dotted
+// paths and appending new column names to header in first-seen order. A
+// list of scalars becomes one comma-separated cell; any other list is
+// written as compact JSON.
+func flatten(n *yaml.Node, prefix string, row map[string]string, header *[]string) {
+	set := func(v string) {
+		key := cmp.Or(prefix, "value")
+		if _, seen := row[key]; !seen && !slices.Contains(*header, key) {
+			*header = append(*header, key)
+		}
+		row[key] = v
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(n.Content[i+1], key, row, header)
+		}
+	case yaml.SequenceNode:
+		parts := make([]string, 0, len(n.Content))
+		for _, e := range n.Content {
+			if e.Kind != yaml.ScalarNode {
+				var generic any
+				_ = n.Decode(&generic)
+				b, _ := json.Marshal(generic)
+				set(string(b))
+				return
+			}
+			parts = append(parts, scalarText(e))
+		}
+		set(strings.Join(parts, ","))
+	default:
+		set(scalarText(n))
+	}
+}
+
+func scalarText(n *yaml.Node) string {
+	if n.Tag == "!!null" {
+		return ""
+	}
+	return n.Value
+}
+
+// selectColumns applies --columns to the column names found in the rows.
+// Without a selection every column is kept. A selected column no row has is
+// written empty rather than rejected: optional fields are omitted from the
+// JSON when unset, so which columns appear depends on the data.
+func selectColumns(available, selected []string) []string {
+	if len(selected) == 0 {
+		return available
+	}
+	out := make([]string, 0, len(selected))
+	for _, s := range selected {
+		if i := slices.IndexFunc(available, func(a string) bool { return style.ColumnMatches(a, s) }); i >= 0 {
+			s = available[i]
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// toNode converts v's JSON encoding into a YAML node tree, keeping object
+// key order and number literals as they were encoded.
+func toNode(v any) (*yaml.Node, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode output: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	n, err := decodeNode(dec)
+	if err != nil {
+		return nil, fmt.Errorf("decode output: %w", err)
+	}
+	return n, nil
+}
+
+func decodeNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			n.Kind, n.Tag = yaml.MappingNode, "!!map"
+		}
+		for dec.More() {
+			if n.Kind == yaml.MappingNode {
+				tok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ := tok.(string)
+				n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key})
+			}
+			child, err := decodeNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, child)
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+		return n, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(t)}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+	return nil, errors.New("unexpected JSON token")
+}
@@ -95,6 +95,9 @@ const BrandColor = lipgloss.Color("#7B61FF")
 var (
 	forceNoColor bool
 	forcedTheme  = ThemeAuto
+	// selectedColumns is the --columns selection Table and SectionTable
+	// honour.
+	selectedColumns []string
 )
 
 // Configure applies process-wide style settings. Call once from the cmd
@@ -104,6 +107,19 @@ func Configure(noColor bool, theme Theme) {
 	forcedTheme = theme
 }
 
+// SelectColumns limits Table to the named columns, in that order (the
+// --columns flag). Nil restores all columns. Set via output.Configure.
+func SelectColumns(cols []string) {
+	selectedColumns = cols
+}
+
+// ColumnMatches reports whether the column header matches a --columns name:
+// case-insensitively, with "-" and " " treated as "_".
+func ColumnMatches(header, name string) bool {
+	norm := strings.NewReplacer("-", "_", " ", "_")
+	return strings.EqualFold(norm.Replace(header), norm.Replace(name))
+}
+
 // Styles bundles the semantic styles used across human renderers. It is
 // constructed per-writer so the writer's terminal capabilities (or lack
 // thereof) control whether ANSI is emitted.
@@ -252,8 +268,38 @@ type CellStyler func(row, col int, content string) string
 // are computed using lipgloss.Width so ANSI codes don't break alignment.
 //
 // hdrStyle is applied to header cells uniformly. cellStyler may be nil to
-// emit unstyled cells.
+// emit unstyled cells. Table is for a command's primary listing: a
+// --columns selection (SelectColumns) is applied here, and naming a column
+// the table lacks is an error. cellStyler still sees the original column
+// indexes.
 func Table(w io.Writer, headers []string, rows [][]string, hdrStyle lipgloss.Style, cellStyler CellStyler) error {
+	if len(selectedColumns) > 0 {
+		var err error
+		if headers, rows, cellStyler, err = projectColumns(headers, rows, cellStyler); err != nil {
+			return err
+		}
+	}
+	return renderTable(w, headers, rows, hdrStyle, cellStyler)
+}
+
+// SectionTable is Table for one section of a larger view (the outputs of
+// `step view`, the profiles under `auth status`). --columns targets a
+// command's primary listing, so a section applies it only when it has
+// every selected column and is printed in full otherwise.
+func SectionTable(w io.Writer, headers []string, rows [][]string, hdrStyle lipgloss.Style, cellStyler CellStyler) error {
+	if len(selectedColumns) > 0 {
+		if h, r, c, err := projectColumns(headers, rows, cellStyler); err == nil {
+			headers, rows, cellStyler = h, r, c
+		}
+	}
+	return renderTable(w, headers, rows, hdrStyle, cellStyler)
+}
+
+// TableFunc is the signature of Table and SectionTable, for renderers
+// shared between a listing command and a view that embeds it.
+type TableFunc func(w io.Writer, headers []string, rows [][]string, hdrStyle lipgloss.Style, cellStyler CellStyler) error
+
+func renderTable(w io.Writer, headers []string, rows [][]string, hdrStyle lipgloss.Style, cellStyler CellStyler) error {
 	cols := len(headers)
 	if cols == 0 {
 		return nil
@@ -300,3 +346,43 @@ func Table(w io.Writer, headers []string, rows [][]string, hdrStyle lipgloss.Sty
 	_, err := io.WriteString(w, sb.String())
 	return err
 }
+
+// projectColumns narrows a table to selectedColumns, remapping cellStyler's
+// column index back to the original layout.
+func projectColumns(headers []string, rows [][]string, cellStyler CellStyler) ([]string, [][]string, CellStyler, error) {
+	idx := make([]int, len(selectedColumns))
+	for i, name := range selectedColumns {
+		idx[i] = -1
+		for j, h := range headers {
+			if ColumnMatches(h, name) {
+				idx[i] = j
+				break
+			}
+		}
+		if idx[i] < 0 {
+			avail := make([]string, len(headers))
+			for j, h := range headers {
+				avail[j] = strings.ToLower(h)
+			}
+			return nil, nil, nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(avail, ", "))
+		}
+	}
+	pick := func(row []string) []string {
+		out := make([]string, len(idx))
+		for i, j := range idx {
+			if j < len(row) {
+				out[i] = row[j]
+			}
+		}
+		return out
+	}
+	projected := make([][]string, len(rows))
+	for r, row := range rows {
+		projected[r] = pick(row)
+	}
+	styler := cellStyler
+	if cellStyler != nil {
+		styler = func(row, col int, content string) string { return cellStyler(row, idx[col], content) }
+	}
+	return pick(headers), projected, styler, nil
+}
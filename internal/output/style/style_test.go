@@ -244,3 +244,53 @@ func TestTable_EmptyHeadersIsNoop(t *testing.T) {
 		t.Errorf("expected no output for empty headers, got %q", buf.String())
 	}
 }
+
+func TestTable_SelectedColumns(t *testing.T) {
+	SelectColumns([]string{"branch", "number"})
+	t.Cleanup(func() { SelectColumns(nil) })
+
+	var buf bytes.Buffer
+	var styled []int
+	styler := func(_, col int, content string) string {
+		styled = append(styled, col)
+		return content
+	}
+	headers := []string{"NUMBER", "STATUS", "BRANCH"}
+	rows := [][]string{{"42", "success", "main"}}
+	if err := Table(&buf, headers, rows, New(&buf).Header, styler); err != nil {
+		t.Fatal(err)
+	}
+	if want := "BRANCH  NUMBER\nmain    42\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if len(styled) != 2 || styled[0] != 2 || styled[1] != 0 {
+		t.Errorf("styler saw columns %v, want the original indexes [2 0]", styled)
+	}
+
+	SelectColumns([]string{"elapsed"})
+	err := Table(&buf, headers, rows, New(&buf).Header, nil)
+	if err == nil || !strings.Contains(err.Error(), "available: number, status, branch") {
+		t.Errorf("unknown column: err = %v", err)
+	}
+}
+
+func TestSectionTable_PrintsInFullWithoutTheSelectedColumns(t *testing.T) {
+	SelectColumns([]string{"branch"})
+	t.Cleanup(func() { SelectColumns(nil) })
+
+	var buf bytes.Buffer
+	if err := SectionTable(&buf, []string{"NAME", "TITLE"}, [][]string{{"a", "b"}}, New(&buf).Header, nil); err != nil {
+		t.Fatalf("a section without the column must not fail: %v", err)
+	}
+	if want := "NAME  TITLE\na     b\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := SectionTable(&buf, []string{"NUMBER", "BRANCH"}, [][]string{{"42", "main"}}, New(&buf).Header, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "BRANCH\nmain\n"; buf.String() != want {
+		t.Errorf("got %q, want the selection applied", buf.String())
+	}
+}
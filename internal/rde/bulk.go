@@ -0,0 +1,138 @@
+package rde
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionFilter selects the sessions a bulk operation applies to. Every set
+// field must match.
+type SessionFilter struct {
+	// LabelSelectors are "key=value" exact matches, ANDed and evaluated by
+	// the backend.
+	LabelSelectors []string
+	// Statuses matches any of the given session statuses ("running",
+	// "terminated", …). Empty matches every status.
+	Statuses []string
+	// OlderThan matches sessions created at least this long before Now.
+	// Zero matches every session.
+	OlderThan time.Duration
+	// Now is the reference time for OlderThan. Zero means time.Now().
+	Now time.Time
+}
+
+// Empty reports whether the filter would match every session.
+func (f SessionFilter) Empty() bool {
+	return len(f.LabelSelectors) == 0 && len(f.Statuses) == 0 && f.OlderThan == 0
+}
+
+// Matches reports whether sess passes the client-side half of the filter
+// (status and age); label selectors are applied by the backend.
+func (f SessionFilter) Matches(sess Session) bool {
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, st := range f.Statuses {
+			if strings.EqualFold(st, sess.Status) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.OlderThan > 0 {
+		if sess.CreatedAt == nil {
+			return false
+		}
+		now := f.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		if now.Sub(*sess.CreatedAt) < f.OlderThan {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectSessions lists the workspace's sessions matching the filter.
+func (s *Service) SelectSessions(ctx context.Context, workspaceID string, f SessionFilter) ([]Session, error) {
+	sessions, err := s.ListSessions(ctx, workspaceID, f.LabelSelectors)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Session, 0, len(sessions))
+	for _, sess := range sessions {
+		if f.Matches(sess) {
+			out = append(out, sess)
+		}
+	}
+	return out, nil
+}
+
+// Bulk item outcomes.
+const (
+	BulkResultDryRun = "dry_run"
+	BulkResultOK     = "ok"
+	BulkResultFailed = "failed"
+)
+
+// BulkItemResult is the outcome of a bulk operation on one session. Status
+// is the session's status after the operation (or before it, on a dry run
+// or failure).
+type BulkItemResult struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status,omitempty"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkApply runs op on every session with at most concurrency calls in
+// flight and returns one result per session, in input order. A failing
+// session doesn't stop the others; once ctx is cancelled the sessions not
+// yet started fail with the context's error.
+func BulkApply(ctx context.Context, sessions []Session, concurrency int, op func(ctx context.Context, sess Session) (Session, error)) []BulkItemResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]BulkItemResult, len(sessions))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, sess := range sessions {
+		results[i] = BulkItemResult{ID: sess.ID, Name: sess.Name, Status: sess.Status}
+		acquired := false
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+				acquired = true
+			case <-ctx.Done():
+			}
+		}
+		if !acquired {
+			results[i].Result = BulkResultFailed
+			results[i].Error = ctx.Err().Error()
+			continue
+		}
+		wg.Add(1)
+		go func(res *BulkItemResult, sess Session) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			updated, err := op(ctx, sess)
+			if err != nil {
+				res.Result = BulkResultFailed
+				res.Error = err.Error()
+				return
+			}
+			res.Result = BulkResultOK
+			if updated.Status != "" {
+				res.Status = updated.Status
+			}
+		}(&results[i], sess)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,91 @@
+package rde
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSessionFilter_Matches(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-72 * time.Hour)
+	recent := now.Add(-time.Hour)
+	f := SessionFilter{Statuses: []string{"running"}, OlderThan: 48 * time.Hour, Now: now}
+
+	for _, tc := range []struct {
+		sess Session
+		want bool
+	}{
+		{Session{Status: "running", CreatedAt: &old}, true},
+		{Session{Status: "running", CreatedAt: &recent}, false},
+		{Session{Status: "terminated", CreatedAt: &old}, false},
+		{Session{Status: "running"}, false},
+	} {
+		if got := f.Matches(tc.sess); got != tc.want {
+			t.Errorf("Matches(%+v) = %v, want %v", tc.sess, got, tc.want)
+		}
+	}
+	if !(SessionFilter{}).Matches(Session{}) {
+		t.Error("an empty filter should match everything")
+	}
+}
+
+func TestBulkApply_KeepsOrderAndBoundsConcurrency(t *testing.T) {
+	sessions := []Session{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}}
+	var inFlight, peak atomic.Int32
+
+	results := BulkApply(context.Background(), sessions, 2, func(_ context.Context, sess Session) (Session, error) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+		if sess.ID == "c" {
+			return Session{}, errors.New("boom")
+		}
+		return Session{ID: sess.ID, Status: "terminating"}, nil
+	})
+
+	if peak.Load() > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", peak.Load())
+	}
+	for i, res := range results {
+		if res.ID != sessions[i].ID {
+			t.Errorf("results[%d].ID = %s, want %s", i, res.ID, sessions[i].ID)
+		}
+		wantResult, wantStatus := BulkResultOK, "terminating"
+		if res.ID == "c" {
+			wantResult, wantStatus = BulkResultFailed, ""
+		}
+		if res.Result != wantResult || res.Status != wantStatus {
+			t.Errorf("results[%d] = %+v, want %s/%q", i, res, wantResult, wantStatus)
+		}
+	}
+	if results[2].Error != "boom" {
+		t.Errorf("failed item error = %q, want boom", results[2].Error)
+	}
+}
+
+func TestBulkApply_CancelledContextFailsRemaining(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls atomic.Int32
+	results := BulkApply(ctx, []Session{{ID: "a"}, {ID: "b"}}, 1, func(context.Context, Session) (Session, error) {
+		calls.Add(1)
+		return Session{}, nil
+	})
+	if calls.Load() != 0 {
+		t.Errorf("op ran %d times after cancellation, want 0", calls.Load())
+	}
+	for _, res := range results {
+		if res.Result != BulkResultFailed || res.Error != context.Canceled.Error() {
+			t.Errorf("%s = %+v, want failed with context canceled", res.ID, res)
+		}
+	}
+}
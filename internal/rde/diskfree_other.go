@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package rde
+
+// diskFree can't tell on this platform; the spool write fails instead when
+// the disk fills up.
+func diskFree(string) (uint64, bool) { return 0, false }
+
+func diskFull(error) bool { return false }
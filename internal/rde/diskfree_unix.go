@@ -0,0 +1,21 @@
+//go:build unix
+
+package rde
+
+import (
+	"errors"
+	"syscall"
+)
+
+// diskFree returns the bytes available to this user on the filesystem of
+// dir.
+func diskFree(dir string) (uint64, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, false
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), true //nolint:gosec,unconvert // field types differ across platforms
+}
+
+// diskFull reports whether err is a write that ran out of space.
+func diskFull(err error) bool { return errors.Is(err, syscall.ENOSPC) }
@@ -0,0 +1,27 @@
+//go:build windows
+
+package rde
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// diskFree returns the bytes available to this user on the volume of dir.
+func diskFree(dir string) (uint64, bool) {
+	p, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, false
+	}
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &free, nil, nil); err != nil {
+		return 0, false
+	}
+	return free, true
+}
+
+// diskFull reports whether err is a write that ran out of space.
+func diskFull(err error) bool {
+	return errors.Is(err, windows.ERROR_DISK_FULL) || errors.Is(err, windows.ERROR_HANDLE_DISK_FULL)
+}
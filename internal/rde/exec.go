@@ -69,3 +69,24 @@ func (s *Service) Execute(ctx context.Context, workspaceID, sessionID, command s
 
 	return client.run(execCtx, prefix+command)
 }
+
+// RunPostCreate runs a repo's post-create commands (see RepoSessionConfig)
+// on a session in order, each like Execute with DefaultExecuteTimeout.
+// onResult, when non-nil, sees each command's result as it finishes. The
+// first command that fails to run or exits non-zero stops the sequence with
+// an error; the session itself is left as it is.
+func (s *Service) RunPostCreate(ctx context.Context, workspaceID, sessionID string, commands []string, env []EnvVar, onResult func(command string, res ExecResult)) error {
+	for i, command := range commands {
+		res, err := s.Execute(ctx, workspaceID, sessionID, command, env, DefaultExecuteTimeout)
+		if err != nil {
+			return fmt.Errorf("post-create command %d (%s): %w", i+1, command, err)
+		}
+		if onResult != nil {
+			onResult(command, res)
+		}
+		if res.ExitCode != 0 {
+			return fmt.Errorf("post-create command %d (%s) exited with status %d", i+1, command, res.ExitCode)
+		}
+	}
+	return nil
+}
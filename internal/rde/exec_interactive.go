@@ -23,6 +23,12 @@ var ErrConnectionLost = errors.New("connection to the session was lost")
 // dial and agent-forwarding posture, but stdin/stdout/stderr are streamed live
 // instead of captured.
 func (s *Service) ExecuteInteractive(ctx context.Context, workspaceID, sessionID, command string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	return s.executeInteractive(ctx, workspaceID, sessionID, command, true, stdin, stdout, stderr)
+}
+
+// executeInteractive is ExecuteInteractive with the agent-forwarding choice
+// exposed: Shell only forwards the local agent when asked to (-A).
+func (s *Service) executeInteractive(ctx context.Context, workspaceID, sessionID, command string, forwardAgent bool, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
 	if s.client == nil {
 		return -1, errClient()
 	}
@@ -42,6 +48,7 @@ func (s *Service) ExecuteInteractive(ctx context.Context, workspaceID, sessionID
 	if err != nil {
 		return -1, err
 	}
+	target.NoAgentForwarding = !forwardAgent
 
 	// Retry the dial: the backend reports SSH ready a moment before the port
 	// actually accepts connections, so the first attempts can be refused.
@@ -30,6 +30,11 @@ type sshTarget struct {
 	Port     int
 	User     string
 	Password string
+	// NoAgentForwarding keeps the local agent out of the session: it is still
+	// offered for authentication, but never forwarded. Zero value forwards
+	// (the exec/claude posture); `rde session ssh` sets it unless -A is given,
+	// like OpenSSH.
+	NoAgentForwarding bool
 }
 
 // ExecResult is the captured result of a remote command execution. Field
@@ -54,9 +59,10 @@ type ExecResult struct {
 // copy this pattern into any context where the session VM is shared or
 // third-party-controlled.
 type sshClient struct {
-	client      *ssh.Client
-	localAgent  agent.ExtendedAgent
-	agentSocket io.Closer
+	client       *ssh.Client
+	localAgent   agent.ExtendedAgent
+	agentSocket  io.Closer
+	forwardAgent bool
 }
 
 const sshHandshakeTimeout = 15 * time.Second
@@ -171,7 +177,8 @@ func dialSSH(ctx context.Context, t sshTarget) (*sshClient, error) {
 
 	client := ssh.NewClient(sshConn, chans, reqs)
 
-	if localAgent != nil {
+	forward := localAgent != nil && !t.NoAgentForwarding
+	if forward {
 		if err := agent.ForwardToAgent(client, localAgent); err != nil {
 			_ = client.Close()
 			if agentSocket != nil {
@@ -182,9 +189,10 @@ func dialSSH(ctx context.Context, t sshTarget) (*sshClient, error) {
 	}
 
 	return &sshClient{
-		client:      client,
-		localAgent:  localAgent,
-		agentSocket: agentSocket,
+		client:       client,
+		localAgent:   localAgent,
+		agentSocket:  agentSocket,
+		forwardAgent: forward,
 	}, nil
 }
 
@@ -317,7 +325,7 @@ func (c *sshClient) run(ctx context.Context, userCmd string) (ExecResult, error)
 	}
 	defer session.Close() //nolint:errcheck // run errors take precedence; nothing actionable on close failure
 
-	if c.localAgent != nil {
+	if c.forwardAgent {
 		// Best-effort. If remote sshd refuses (AllowAgentForwarding=no), the
 		// user's command runs without a forwarded agent — any git-over-SSH
 		// step then fails with an auth error, surfaced through stderr.
@@ -379,6 +387,50 @@ func (c *sshClient) run(ctx context.Context, userCmd string) (ExecResult, error)
 	return result, fmt.Errorf("ssh run: %w", runErr)
 }
 
+// runPipe runs userCmd under sh with stdin and stdout streamed, for commands
+// that move data (tar, file lists) rather than run the user's code. It skips
+// the login shell: profile output would corrupt the stream, and sh keeps the
+// command's meaning independent of the account's shell. A non-zero exit is an
+// error carrying the command's stderr. stdin may be nil.
+func (c *sshClient) runPipe(ctx context.Context, userCmd string, stdin io.Reader, stdout io.Writer) error {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("ssh new session: %w", err)
+	}
+	defer session.Close() //nolint:errcheck // run errors take precedence; nothing actionable on close failure
+
+	var stderr bytes.Buffer
+	if stdin != nil {
+		session.Stdin = stdin
+	}
+	session.Stdout = stdout
+	session.Stderr = &stderr
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.client.Close() // same reasoning as run: unblock a stuck transfer
+		case <-done:
+		}
+	}()
+	defer close(done)
+	go c.keepAlive(done)
+
+	runErr := session.Run("sh -c " + shellSingleQuote(userCmd))
+	if runErr == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(runErr, &exitErr) {
+		return fmt.Errorf("exit status %d: %s", exitErr.ExitStatus(), strings.TrimSpace(stderr.String()))
+	}
+	return fmt.Errorf("%w: %v", ErrConnectionLost, runErr)
+}
+
 func buildLoginShellCmd(userCmd string) string {
 	return "bash -i -l -c '" + strings.ReplaceAll(userCmd, "'", `'\''`) + "'"
 }
@@ -516,7 +568,7 @@ func (c *sshClient) runInteractive(ctx context.Context, userCmd string, stdin io
 	}
 	defer session.Close() //nolint:errcheck // run errors take precedence; nothing actionable on close failure
 
-	if c.localAgent != nil {
+	if c.forwardAgent {
 		// Best-effort, same posture as run: a refusing remote sshd just means
 		// agent-backed auth inside the session is unavailable.
 		_ = agent.RequestAgentForwarding(session)
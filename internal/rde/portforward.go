@@ -0,0 +1,370 @@
+package rde
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Forward kinds, matching the OpenSSH flags they mirror: -L, -R, -D.
+const (
+	ForwardLocal   = "local"
+	ForwardRemote  = "remote"
+	ForwardDynamic = "dynamic"
+)
+
+// defaultForwardBind is the listen host when a spec names none: loopback on
+// either side, so a forwarded port is never exposed to the network by
+// accident.
+const defaultForwardBind = "127.0.0.1"
+
+// Forward is one port forward of a PortForward tunnel.
+type Forward struct {
+	Kind string
+	// Bind is the host:port listened on — on this machine for local and
+	// dynamic forwards, on the session for remote ones. Port 0 picks a free
+	// port.
+	Bind string
+	// Target is the host:port each connection is dialed to — from the session
+	// for a local forward, from this machine for a remote one. Empty for a
+	// dynamic (SOCKS5) forward, where the client names it.
+	Target string
+}
+
+// BoundForward is a Forward with its actual listen address, as reported in
+// the ready event. JSON tags are part of `rde session port-forward --output
+// json`.
+type BoundForward struct {
+	Kind   string `json:"kind"`
+	Listen string `json:"listen"`
+	Target string `json:"target,omitempty"`
+}
+
+// ParseForward parses a forward spec in the OpenSSH shape:
+//
+//	local, remote  [bind_address:]port:host:hostport, port:hostport, or port
+//	dynamic        [bind_address:]port
+//
+// A bare port forwards to the same port on localhost; port:hostport (the
+// kubectl shape) to hostport on localhost. IPv6 addresses go in brackets.
+func ParseForward(kind, spec string) (Forward, error) {
+	parts, err := splitForwardSpec(spec)
+	if err != nil {
+		return Forward{}, fmt.Errorf("%s forward %q: %w", kind, spec, err)
+	}
+	bind, port, host, hostPort := defaultForwardBind, "", "localhost", ""
+	switch kind {
+	case ForwardDynamic:
+		switch len(parts) {
+		case 1:
+			port = parts[0]
+		case 2:
+			bind, port = parts[0], parts[1]
+		default:
+			return Forward{}, fmt.Errorf("dynamic forward %q: expected [bind_address:]port", spec)
+		}
+	case ForwardLocal, ForwardRemote:
+		switch len(parts) {
+		case 1:
+			port, hostPort = parts[0], parts[0]
+		case 2:
+			port, hostPort = parts[0], parts[1]
+		case 3:
+			port, host, hostPort = parts[0], parts[1], parts[2]
+		case 4:
+			bind, port, host, hostPort = parts[0], parts[1], parts[2], parts[3]
+		default:
+			return Forward{}, fmt.Errorf("%s forward %q: expected [bind_address:]port:host:hostport", kind, spec)
+		}
+	default:
+		return Forward{}, fmt.Errorf("unknown forward kind %q", kind)
+	}
+
+	if _, err := parseForwardPort(port, true); err != nil {
+		return Forward{}, fmt.Errorf("%s forward %q: %w", kind, spec, err)
+	}
+	f := Forward{Kind: kind, Bind: net.JoinHostPort(bind, port)}
+	if kind != ForwardDynamic {
+		if _, err := parseForwardPort(hostPort, false); err != nil {
+			return Forward{}, fmt.Errorf("%s forward %q: %w", kind, spec, err)
+		}
+		if host == "" {
+			return Forward{}, fmt.Errorf("%s forward %q: empty host", kind, spec)
+		}
+		f.Target = net.JoinHostPort(host, hostPort)
+	}
+	return f, nil
+}
+
+// splitForwardSpec splits spec on colons outside [brackets], unwrapping
+// bracketed IPv6 hosts.
+func splitForwardSpec(spec string) ([]string, error) {
+	var parts []string
+	var cur strings.Builder
+	inBracket := false
+	for _, r := range spec {
+		switch {
+		case r == '[' && !inBracket && cur.Len() == 0:
+			inBracket = true
+		case r == ']' && inBracket:
+			inBracket = false
+		case r == ':' && !inBracket:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if inBracket {
+		return nil, fmt.Errorf("unterminated '['")
+	}
+	return append(parts, cur.String()), nil
+}
+
+func parseForwardPort(s string, allowZero bool) (int, error) {
+	p, err := strconv.Atoi(s)
+	if err != nil || p < 0 || p > 65535 || (p == 0 && !allowZero) {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return p, nil
+}
+
+// PortForwardOptions configures PortForward.
+type PortForwardOptions struct {
+	// ReconnectInterval paces reconnect attempts; zero means
+	// DefaultShellReconnectInterval.
+	ReconnectInterval time.Duration
+	// OnReady, when non-nil, is called after every (re)connect once all
+	// forwards are listening. Remote forwards on port 0 may get a different
+	// port on each connect.
+	OnReady func(bound []BoundForward)
+	// OnReconnect, when non-nil, is called before each reconnect attempt with
+	// its 1-based number (counted from the latest drop) and the error that
+	// ended the previous connection or attempt.
+	OnReconnect func(attempt int, err error)
+}
+
+// PortForward opens an SSH connection to the session and serves forwards
+// over it until ctx is cancelled, which is a clean stop (nil). It is the
+// multi-port generalization of ForwardVNC: local forwards (ssh -L) and SOCKS5
+// dynamic forwards (ssh -D) listen on this machine and dial out from the
+// session; remote forwards (ssh -R) listen on the session and dial out from
+// here.
+//
+// Local listeners are bound once, up front — a port already in use fails
+// immediately — and survive reconnects, so clients keep a stable address.
+// When the connection drops (detected by keepalives), PortForward redials
+// every ReconnectInterval, re-fetching the session so rotated credentials are
+// picked up, and re-opens the remote listeners. Connections accepted while
+// disconnected are refused. A session that stops running, or an
+// authentication failure, ends the tunnel with an error. So does a remote
+// listener that can't be opened on the first connect; after a reconnect the
+// same failure is retried, since sshd may still hold the port for the
+// connection that just died.
+func (s *Service) PortForward(ctx context.Context, workspaceID, sessionID string, forwards []Forward, opts PortForwardOptions) error {
+	if s.client == nil {
+		return errClient()
+	}
+	if len(forwards) == 0 {
+		return fmt.Errorf("at least one forward is required")
+	}
+	interval := opts.ReconnectInterval
+	if interval <= 0 {
+		interval = DefaultShellReconnectInterval
+	}
+
+	t := &tunnel{}
+	var local []BoundForward
+	for _, f := range forwards {
+		if f.Kind == ForwardRemote {
+			continue
+		}
+		ln, err := net.Listen("tcp", f.Bind)
+		if err != nil {
+			t.closeListeners()
+			return fmt.Errorf("listen on %s: %w", f.Bind, err)
+		}
+		t.listeners = append(t.listeners, ln)
+		local = append(local, BoundForward{Kind: f.Kind, Listen: ln.Addr().String(), Target: f.Target})
+		go t.serveLocal(ctx, ln, f)
+	}
+	defer t.closeListeners()
+	// Closing the listeners is what unblocks the accept loops on cancel.
+	stopListeners := context.AfterFunc(ctx, t.closeListeners)
+	defer stopListeners()
+
+	attempt := 0
+	everConnected := false
+	for {
+		connected, err := s.serveTunnel(ctx, workspaceID, sessionID, forwards, t, local, everConnected, opts.OnReady)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !errors.Is(err, ErrConnectionLost) {
+			return err
+		}
+		if connected {
+			attempt = 0
+			everConnected = true
+		}
+		attempt++
+		if opts.OnReconnect != nil {
+			opts.OnReconnect(attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// serveTunnel runs one connection of PortForward: dial, open the remote
+// listeners, report ready, and block until the connection dies (returned as
+// ErrConnectionLost) or ctx is cancelled. connected reports whether it got as
+// far as ready, so the caller can restart its attempt count. reconnecting
+// makes a remote listen failure retryable.
+func (s *Service) serveTunnel(ctx context.Context, workspaceID, sessionID string, forwards []Forward, t *tunnel, local []BoundForward, reconnecting bool, onReady func([]BoundForward)) (connected bool, err error) {
+	sess, err := s.GetSession(ctx, workspaceID, sessionID)
+	if err != nil {
+		if isRetryableDialErr(err) {
+			return false, fmt.Errorf("%w: %v", ErrConnectionLost, err)
+		}
+		return false, fmt.Errorf("fetch session: %w", err)
+	}
+	target, err := sshTargetForSession(sess)
+	if err != nil {
+		return false, err
+	}
+	client, err := dialSSHWithRetry(ctx, target)
+	if err != nil {
+		if isRetryableDialErr(err) {
+			return false, fmt.Errorf("%w: %v", ErrConnectionLost, err)
+		}
+		return false, err
+	}
+	defer client.Close() //nolint:errcheck // the connection is being torn down; nothing actionable on close failure
+
+	// Remote listeners are not closed individually: their Close asks the
+	// server to cancel the forward and waits for a reply, which never comes
+	// on a dead connection. Closing the client ends them either way.
+	bound := append([]BoundForward(nil), local...)
+	for _, f := range forwards {
+		if f.Kind != ForwardRemote {
+			continue
+		}
+		ln, err := client.listenRemote(f.Bind)
+		if err != nil {
+			if reconnecting {
+				return false, fmt.Errorf("%w: listen on %s on the session: %v", ErrConnectionLost, f.Bind, err)
+			}
+			return false, fmt.Errorf("listen on %s on the session: %w", f.Bind, err)
+		}
+		bound = append(bound, BoundForward{Kind: f.Kind, Listen: ln.Addr().String(), Target: f.Target})
+		go serveRemote(ctx, ln, f.Target)
+	}
+
+	t.setClient(client)
+	defer t.setClient(nil)
+	if onReady != nil {
+		onReady(bound)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go client.keepAlive(done)
+	waitErr := make(chan error, 1)
+	go func() { waitErr <- client.client.Wait() }()
+	select {
+	case <-ctx.Done():
+		return true, nil
+	case err := <-waitErr:
+		if err == nil {
+			err = errors.New("connection closed") // Wait returns nil on a clean close
+		}
+		return true, fmt.Errorf("%w: %v", ErrConnectionLost, err)
+	}
+}
+
+// tunnel holds PortForward's local listeners and the SSH connection they
+// currently dial through (nil while reconnecting).
+type tunnel struct {
+	mu        sync.Mutex
+	client    *sshClient
+	listeners []net.Listener
+	closed    bool
+}
+
+func (t *tunnel) setClient(c *sshClient) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.client = c
+}
+
+func (t *tunnel) currentClient() *sshClient {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.client
+}
+
+func (t *tunnel) closeListeners() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	for _, ln := range t.listeners {
+		_ = ln.Close()
+	}
+}
+
+// serveLocal accepts on a local or dynamic forward's listener until it is
+// closed, bridging each connection over the current SSH connection.
+func (t *tunnel) serveLocal(ctx context.Context, ln net.Listener, f Forward) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		client := t.currentClient()
+		if client == nil {
+			_ = conn.Close() // reconnecting: refuse rather than hang the client
+			continue
+		}
+		if f.Kind == ForwardDynamic {
+			go serveSOCKS5(ctx, conn, func(addr string) (net.Conn, error) {
+				return client.client.Dial("tcp", addr)
+			})
+			continue
+		}
+		go client.forwardConn(ctx, conn, f.Target)
+	}
+}
+
+// serveRemote accepts connections the session opens on a remote forward's
+// listener and bridges each to target, dialed from this machine. It returns
+// once the listener closes (the connection dropped or the tunnel stopped).
+func serveRemote(ctx context.Context, ln net.Listener, target string) {
+	for {
+		remote, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() { _ = remote.Close() }()
+			d := net.Dialer{Timeout: sshHandshakeTimeout}
+			local, err := d.DialContext(ctx, "tcp", target)
+			if err != nil {
+				return
+			}
+			defer func() { _ = local.Close() }()
+			bridgeConn(ctx, remote, local)
+		}()
+	}
+}
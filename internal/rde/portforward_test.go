@@ -0,0 +1,258 @@
+package rde
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
+)
+
+func TestParseForward(t *testing.T) {
+	cases := []struct {
+		kind, spec string
+		want       Forward
+		wantErr    string
+	}{
+		{kind: ForwardLocal, spec: "3000", want: Forward{Kind: ForwardLocal, Bind: "127.0.0.1:3000", Target: "localhost:3000"}},
+		{kind: ForwardLocal, spec: "8080:80", want: Forward{Kind: ForwardLocal, Bind: "127.0.0.1:8080", Target: "localhost:80"}},
+		{kind: ForwardLocal, spec: "8080:localhost:80", want: Forward{Kind: ForwardLocal, Bind: "127.0.0.1:8080", Target: "localhost:80"}},
+		{kind: ForwardLocal, spec: "0.0.0.0:8080:10.0.0.5:80", want: Forward{Kind: ForwardLocal, Bind: "0.0.0.0:8080", Target: "10.0.0.5:80"}},
+		{kind: ForwardLocal, spec: "0:localhost:9229", want: Forward{Kind: ForwardLocal, Bind: "127.0.0.1:0", Target: "localhost:9229"}},
+		{kind: ForwardRemote, spec: "9000:localhost:9000", want: Forward{Kind: ForwardRemote, Bind: "127.0.0.1:9000", Target: "localhost:9000"}},
+		{kind: ForwardLocal, spec: "[::1]:8080:[::1]:80", want: Forward{Kind: ForwardLocal, Bind: "[::1]:8080", Target: "[::1]:80"}},
+		{kind: ForwardDynamic, spec: "1080", want: Forward{Kind: ForwardDynamic, Bind: "127.0.0.1:1080"}},
+		{kind: ForwardDynamic, spec: "localhost:1080", want: Forward{Kind: ForwardDynamic, Bind: "localhost:1080"}},
+		{kind: ForwardLocal, spec: "http", wantErr: `invalid port "http"`},
+		{kind: ForwardLocal, spec: "8080:localhost:0", wantErr: `invalid port "0"`},
+		{kind: ForwardLocal, spec: "70000", wantErr: "invalid port"},
+		{kind: ForwardLocal, spec: "a:1:b:2:c", wantErr: "expected [bind_address:]port:host:hostport"},
+		{kind: ForwardDynamic, spec: "1080:localhost:80", wantErr: "expected [bind_address:]port"},
+		{kind: ForwardLocal, spec: "[::1:80", wantErr: "unterminated"},
+	}
+	for _, tc := range cases {
+		got, err := ParseForward(tc.kind, tc.spec)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ParseForward(%s, %q) err = %v, want %q", tc.kind, tc.spec, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseForward(%s, %q): %v", tc.kind, tc.spec, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseForward(%s, %q) = %+v, want %+v", tc.kind, tc.spec, got, tc.want)
+		}
+	}
+}
+
+func TestServeSOCKS5_ConnectsToRequestedAddress(t *testing.T) {
+	client, server := tcpConnPair(t)
+	target, targetPeer := tcpConnPair(t)
+	defer closeConns(client, target, targetPeer)
+
+	dialed := make(chan string, 1)
+	go serveSOCKS5(context.Background(), server, func(addr string) (net.Conn, error) {
+		dialed <- addr
+		return target, nil
+	})
+
+	// Greeting: version 5, one method (no auth).
+	if _, err := client.Write([]byte{5, 1, 0}); err != nil {
+		t.Fatal(err)
+	}
+	readExpect(t, client, "\x05\x00")
+	// CONNECT example.internal:8080 by domain name.
+	req := append([]byte{5, 1, 0, 3, byte(len("example.internal"))}, "example.internal"...)
+	req = append(req, 0x1f, 0x90)
+	if _, err := client.Write(req); err != nil {
+		t.Fatal(err)
+	}
+	readExpect(t, client, "\x05\x00\x00\x01\x00\x00\x00\x00\x00\x00")
+	if got := <-dialed; got != "example.internal:8080" {
+		t.Errorf("dialed %q, want example.internal:8080", got)
+	}
+
+	if _, err := client.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	readExpect(t, targetPeer, "ping")
+	if _, err := targetPeer.Write([]byte("pong")); err != nil {
+		t.Fatal(err)
+	}
+	readExpect(t, client, "pong")
+}
+
+func TestServeSOCKS5_RejectsAuthOnlyClient(t *testing.T) {
+	client, server := tcpConnPair(t)
+	defer closeConns(client)
+	go serveSOCKS5(context.Background(), server, func(string) (net.Conn, error) {
+		t.Error("dial must not be reached")
+		return nil, io.EOF
+	})
+	// Only username/password (0x02) offered.
+	if _, err := client.Write([]byte{5, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	readExpect(t, client, "\x05\xff")
+	_ = client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := client.Read(make([]byte, 1)); err == nil {
+		t.Error("connection should be closed after the rejection")
+	}
+}
+
+// echoThrough dials addr and checks a round trip through the echo server.
+func echoThrough(t *testing.T, addr string) {
+	t.Helper()
+	c, err := net.DialTimeout("tcp", addr, 2*time.Second)
+	if err != nil {
+		t.Fatalf("dial %s: %v", addr, err)
+	}
+	defer closeConns(c)
+	if _, err := c.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	readExpect(t, c, "hello")
+}
+
+func TestPortForward_ForwardsAndReconnects(t *testing.T) {
+	srv := newTestSSHServer(t)
+	svc := srv.sessionAPI()
+	echo := echoListener(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	readies := make(chan []BoundForward, 4)
+	reconnects := make(chan int, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.PortForward(ctx, "ws-1", "s1", []Forward{
+			{Kind: ForwardLocal, Bind: "127.0.0.1:0", Target: echo},
+			{Kind: ForwardRemote, Bind: "127.0.0.1:0", Target: echo},
+		}, PortForwardOptions{
+			ReconnectInterval: time.Millisecond,
+			OnReady:           func(b []BoundForward) { readies <- b },
+			OnReconnect:       func(attempt int, _ error) { reconnects <- attempt },
+		})
+	}()
+	awaitReady := func() []BoundForward {
+		t.Helper()
+		select {
+		case b := <-readies:
+			return b
+		case err := <-done:
+			t.Fatalf("PortForward returned early: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for ready")
+		}
+		return nil
+	}
+
+	bound := awaitReady()
+	if len(bound) != 2 || bound[0].Kind != ForwardLocal || bound[1].Kind != ForwardRemote {
+		t.Fatalf("bound = %+v, want the local then the remote forward", bound)
+	}
+	echoThrough(t, bound[0].Listen)
+	// The test server's "session" shares this machine's loopback, so the
+	// remote listener is reachable here too.
+	echoThrough(t, bound[1].Listen)
+
+	srv.dropConnections()
+	select {
+	case attempt := <-reconnects:
+		if attempt != 1 {
+			t.Errorf("first reconnect attempt = %d, want 1", attempt)
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatal("a dropped connection should trigger a reconnect")
+	}
+	again := awaitReady()
+	if again[0].Listen != bound[0].Listen {
+		t.Errorf("local forward moved from %s to %s; it should stay bound across reconnects", bound[0].Listen, again[0].Listen)
+	}
+	echoThrough(t, again[0].Listen)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("cancel should stop cleanly, got %v", err)
+	}
+}
+
+func TestPortForward_RemoteListenRetriedAfterReconnect(t *testing.T) {
+	srv := newTestSSHServer(t)
+	srv.rejectForward = func(n int) bool { return n == 2 } // the first reconnect
+	svc := srv.sessionAPI()
+	echo := echoListener(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	readies := make(chan []BoundForward, 4)
+	errs := make(chan error, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.PortForward(ctx, "ws-1", "s1", []Forward{{Kind: ForwardRemote, Bind: "127.0.0.1:0", Target: echo}}, PortForwardOptions{
+			ReconnectInterval: time.Millisecond,
+			OnReady:           func(b []BoundForward) { readies <- b },
+			OnReconnect:       func(_ int, err error) { errs <- err },
+		})
+	}()
+	awaitReady := func() []BoundForward {
+		t.Helper()
+		select {
+		case b := <-readies:
+			return b
+		case err := <-done:
+			t.Fatalf("PortForward returned early: %v", err)
+		case <-time.After(20 * time.Second):
+			t.Fatal("timed out waiting for ready")
+		}
+		return nil
+	}
+
+	awaitReady()
+	srv.dropConnections()
+	bound := awaitReady()
+	echoThrough(t, bound[0].Listen)
+	if len(errs) != 2 {
+		t.Fatalf("got %d reconnects, want the drop and then the refused bind", len(errs))
+	}
+	<-errs
+	if err := <-errs; !strings.Contains(err.Error(), "listen on 127.0.0.1:0 on the session") {
+		t.Errorf("second reconnect err = %v, want the refused bind", err)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("cancel should stop cleanly, got %v", err)
+	}
+}
+
+func TestPortForward_RemoteListenFailsFastOnFirstConnect(t *testing.T) {
+	srv := newTestSSHServer(t)
+	srv.rejectForward = func(int) bool { return true }
+	svc := srv.sessionAPI()
+
+	err := svc.PortForward(context.Background(), "ws-1", "s1", []Forward{{Kind: ForwardRemote, Bind: "127.0.0.1:0", Target: "localhost:80"}}, PortForwardOptions{ReconnectInterval: time.Millisecond})
+	if err == nil || errors.Is(err, ErrConnectionLost) || !strings.Contains(err.Error(), "on the session") {
+		t.Fatalf("err = %v, want a terminal listen error", err)
+	}
+}
+
+func TestPortForward_LocalPortInUseFailsFast(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = busy.Close() }()
+	svc := NewService(rdeapi.New("http://127.0.0.1:0", "tok"))
+	err = svc.PortForward(context.Background(), "ws-1", "s1", []Forward{{Kind: ForwardLocal, Bind: busy.Addr().String(), Target: "localhost:80"}}, PortForwardOptions{})
+	if err == nil || !strings.Contains(err.Error(), "listen on") {
+		t.Fatalf("err = %v, want a listen error before any API call", err)
+	}
+}
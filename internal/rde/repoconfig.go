@@ -4,8 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"gopkg.in/yaml.v3"
 )
@@ -21,7 +23,8 @@ const (
 // This is the initial schema; new sections are additive, and unknown keys
 // are ignored so an older CLI keeps working against a newer file.
 type RepoConfig struct {
-	Exec RepoExecConfig `yaml:"exec"`
+	Exec    RepoExecConfig    `yaml:"exec"`
+	Session RepoSessionConfig `yaml:"session"`
 }
 
 // RepoExecConfig configures `rde session exec` for a repo.
@@ -32,6 +35,122 @@ type RepoExecConfig struct {
 	Env []string `yaml:"env"`
 }
 
+// RepoSessionConfig is the dev session a repo is worked on in — what
+// `rde session create --from-repo` (and `rde up`) creates with no flags.
+// Committed, it gives the whole team the same environment. It has no inline
+// secret inputs: the file lives in git, so secrets are referenced as saved
+// inputs each developer stores under the same key.
+type RepoSessionConfig struct {
+	// Name is the session name; empty means the repo directory's name.
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Template is a template ID or name. Without one, Stack and MachineType
+	// are both required (a template-less session); with one, they override
+	// the template's defaults.
+	Template    string `yaml:"template"`
+	Stack       string `yaml:"stack"`
+	MachineType string `yaml:"machine_type"`
+	Cluster     string `yaml:"cluster"`
+	// Inputs are plain session input values, by key.
+	Inputs map[string]string `yaml:"inputs"`
+	// SavedInputs maps session input keys to saved-input IDs.
+	SavedInputs map[string]string `yaml:"saved_inputs"`
+	// MapSavedInputs auto-fills the remaining inputs from the developer's
+	// own saved inputs with matching keys.
+	MapSavedInputs bool              `yaml:"map_saved_inputs"`
+	Labels         map[string]string `yaml:"labels"`
+	// AutoTerminateMinutes is left to the backend default when unset; 0
+	// disables auto-termination.
+	AutoTerminateMinutes *int     `yaml:"auto_terminate_minutes"`
+	FeatureFlags         []string `yaml:"feature_flags"`
+	AIPrompt             string   `yaml:"ai_prompt"`
+	// PostCreate are shell command lines run in order on the new session
+	// once it's running, like `rde session exec --shell`.
+	PostCreate []string `yaml:"post_create"`
+}
+
+// Defined reports whether the file has a usable session section.
+func (c RepoSessionConfig) Defined() bool {
+	return c.Template != "" || c.Stack != "" || c.MachineType != ""
+}
+
+// Validate checks the section as a whole; path names the file in errors.
+func (c RepoSessionConfig) Validate(path string) error {
+	if !c.Defined() {
+		return fmt.Errorf("%s has no session section (set session.template, or session.stack and session.machine_type)", path)
+	}
+	if c.Template == "" && (c.Stack == "" || c.MachineType == "") {
+		return fmt.Errorf("%s: session needs a template, or both stack and machine_type for a session without a template", path)
+	}
+	for k, id := range c.SavedInputs {
+		if k == "" || id == "" {
+			return fmt.Errorf("%s: session.saved_inputs entries need a key and a saved-input ID", path)
+		}
+		if _, dup := c.Inputs[k]; dup {
+			return fmt.Errorf("%s: session input %q is set in both inputs and saved_inputs", path, k)
+		}
+	}
+	for k := range c.Inputs {
+		if k == "" {
+			return fmt.Errorf("%s: session.inputs has an empty key", path)
+		}
+	}
+	for i, cmd := range c.PostCreate {
+		if cmd == "" {
+			return fmt.Errorf("%s: session.post_create[%d] is empty", path, i)
+		}
+	}
+	return nil
+}
+
+// CreateRequest is the create-session request the section describes, named
+// name. Inputs are ordered by key so the request is deterministic.
+func (c RepoSessionConfig) CreateRequest(name string) CreateSessionRequest {
+	req := CreateSessionRequest{
+		Name:                    name,
+		Description:             c.Description,
+		TemplateID:              c.Template,
+		StackID:                 c.Stack,
+		MachineType:             c.MachineType,
+		Cluster:                 c.Cluster,
+		AIPrompt:                c.AIPrompt,
+		MapSavedToSessionInputs: c.MapSavedInputs,
+		EnabledFeatureFlagNames: append([]string(nil), c.FeatureFlags...),
+	}
+	if c.AutoTerminateMinutes != nil {
+		m := *c.AutoTerminateMinutes
+		req.AutoTerminateMinutes = &m
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.Inputs)) {
+		req.SessionInputs = append(req.SessionInputs, SessionInputValue{Key: k, Value: c.Inputs[k]})
+	}
+	for _, k := range slices.Sorted(maps.Keys(c.SavedInputs)) {
+		req.SessionInputs = append(req.SessionInputs, SessionInputValue{Key: k, SavedInputID: c.SavedInputs[k]})
+	}
+	if len(c.Labels) > 0 {
+		req.Labels = make(map[string]string, len(c.Labels))
+		for k, v := range c.Labels {
+			req.Labels[k] = v
+		}
+	}
+	return req
+}
+
+// SessionName is the session name to use: Name, or else the name of the
+// repo directory holding the dotfile at path.
+func (c RepoSessionConfig) SessionName(path string) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return filepath.Base(RepoConfigRoot(path))
+}
+
+// RepoConfigRoot is the repo directory a dotfile at path belongs to (the
+// parent of its .bitrise directory).
+func RepoConfigRoot(path string) string {
+	return filepath.Dir(filepath.Dir(path))
+}
+
 // LoadRepoConfig searches the current working directory and its ancestors
 // for the repo-level RDE dotfile (.bitrise/rde.yml). Returns the parsed
 // config, the path of the file that was used (empty if none found), and any
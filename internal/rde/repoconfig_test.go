@@ -3,6 +3,7 @@ package rde
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -99,3 +100,69 @@ func TestLoadRepoConfig_IgnoresUnknownKeys(t *testing.T) {
 		t.Fatalf("Exec.Env = %v, want [FOO]", got.Exec.Env)
 	}
 }
+
+func TestRepoSessionConfig_CreateRequest(t *testing.T) {
+	root := t.TempDir()
+	p := writeRepoConfig(t, root, `session:
+  template: ios-dev
+  stack: osx-xcode-16.0.x-edge
+  inputs:
+    z: last
+    a: first
+  saved_inputs:
+    gh-token: sv-1
+  map_saved_inputs: true
+  labels:
+    team: mobile
+  auto_terminate_minutes: 0
+  feature_flags: [f1]
+  ai_prompt: hello
+  post_create:
+    - make bootstrap
+`)
+	cfg, _, err := loadRepoConfigFrom(root)
+	if err != nil {
+		t.Fatalf("loadRepoConfigFrom: %v", err)
+	}
+	if err := cfg.Session.Validate(p); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if got := cfg.Session.SessionName(p); got != filepath.Base(root) {
+		t.Errorf("SessionName = %q, want the repo directory name %q", got, filepath.Base(root))
+	}
+	req := cfg.Session.CreateRequest("dev")
+	wantInputs := []SessionInputValue{{Key: "a", Value: "first"}, {Key: "z", Value: "last"}, {Key: "gh-token", SavedInputID: "sv-1"}}
+	if !reflect.DeepEqual(req.SessionInputs, wantInputs) {
+		t.Errorf("SessionInputs = %+v, want %+v", req.SessionInputs, wantInputs)
+	}
+	if req.AutoTerminateMinutes == nil || *req.AutoTerminateMinutes != 0 {
+		t.Errorf("AutoTerminateMinutes = %v, want an explicit 0", req.AutoTerminateMinutes)
+	}
+	if req.Name != "dev" || req.TemplateID != "ios-dev" || req.StackID != "osx-xcode-16.0.x-edge" ||
+		!req.MapSavedToSessionInputs || req.AIPrompt != "hello" || req.Labels["team"] != "mobile" {
+		t.Errorf("request = %+v", req)
+	}
+	if !reflect.DeepEqual(cfg.Session.PostCreate, []string{"make bootstrap"}) {
+		t.Errorf("PostCreate = %v", cfg.Session.PostCreate)
+	}
+}
+
+func TestRepoSessionConfig_Validate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cfg  RepoSessionConfig
+		want string
+	}{
+		"empty":                   {RepoSessionConfig{}, "no session section"},
+		"stack without type":      {RepoSessionConfig{Stack: "s"}, "both stack and machine_type"},
+		"input set twice":         {RepoSessionConfig{Template: "t", Inputs: map[string]string{"k": "v"}, SavedInputs: map[string]string{"k": "sv"}}, `"k" is set in both`},
+		"saved input without ID":  {RepoSessionConfig{Template: "t", SavedInputs: map[string]string{"k": ""}}, "saved-input ID"},
+		"empty post-create entry": {RepoSessionConfig{Template: "t", PostCreate: []string{""}}, "post_create[0]"},
+	} {
+		if err := tc.cfg.Validate("rde.yml"); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Validate = %v, want %q", name, err, tc.want)
+		}
+	}
+	if err := (RepoSessionConfig{Stack: "s", MachineType: "m"}).Validate("rde.yml"); err != nil {
+		t.Errorf("template-less config: %v", err)
+	}
+}
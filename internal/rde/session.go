@@ -2,6 +2,7 @@ package rde
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -296,6 +297,7 @@ func (s *Service) RestoreSession(ctx context.Context, workspaceID, sessionID str
 	if err != nil {
 		return Session{}, err
 	}
+	recordStatus(sessionID, "starting", time.Now())
 	return sessionFromAPI(w), nil
 }
 
@@ -309,6 +311,7 @@ func (s *Service) TerminateSession(ctx context.Context, workspaceID, sessionID s
 	if err != nil {
 		return Session{}, err
 	}
+	recordStatus(sessionID, "terminating", time.Now())
 	return sessionFromAPI(w), nil
 }
 
@@ -543,6 +546,77 @@ func (s *Service) WaitForTerminated(ctx context.Context, workspaceID, sessionID
 	}
 }
 
+// ErrSessionStopped is returned by EnsureSSHReady for a terminated, stopped,
+// or failed session when restore was not requested.
+var ErrSessionStopped = errors.New("session is not running")
+
+// Phases reported by EnsureSSHReady's onPhase callback.
+const (
+	PhaseRestoring  = "restoring"
+	PhaseBooting    = "booting"
+	PhaseSSHWaiting = "waiting-for-ssh"
+)
+
+// EnsureSSHReady brings a session to the point where it accepts SSH and
+// returns it: a running session only waits for its SSH credentials, one still
+// provisioning is waited on first, and a terminated/stopped/failed one is
+// restored (when restore is set; otherwise ErrSessionStopped) and booted. It
+// is the pre-flight every attach-style command shares — `rde session ssh`,
+// the ssh-proxy, `rde up` — so they agree on which states are recoverable.
+//
+// onPhase, when non-nil, is called with PhaseRestoring, PhaseBooting, or
+// PhaseSSHWaiting as each slow step starts, so a caller can say what it's
+// waiting for. Nothing is reported for a session that's already reachable.
+func (s *Service) EnsureSSHReady(ctx context.Context, workspaceID, sessionID string, restore bool, interval time.Duration, onPhase func(phase string)) (Session, error) {
+	if s.client == nil {
+		return Session{}, errClient()
+	}
+	phase := func(p string) {
+		if onPhase != nil {
+			onPhase(p)
+		}
+	}
+	sess, err := s.GetSession(ctx, workspaceID, sessionID)
+	if err != nil {
+		return Session{}, err
+	}
+	switch sess.Status {
+	case "running":
+		if sess.SSHConnectionOpen && sess.SSHAddress != "" && sess.SSHPassword != "" {
+			return sess, nil
+		}
+	case "terminated", "stopped", "failed":
+		if !restore {
+			return Session{}, fmt.Errorf("%w (status: %q)", ErrSessionStopped, sess.Status)
+		}
+		if sess.PersistentDiskStatus == DiskStatusUnavailable {
+			return Session{}, fmt.Errorf("session %s cannot be restored: its persistent disk is no longer available", sessionID)
+		}
+		phase(PhaseRestoring)
+		if _, err := s.RestoreSession(ctx, workspaceID, sessionID); err != nil {
+			return Session{}, fmt.Errorf("restore session: %w", err)
+		}
+		fallthrough
+	case "", "pending", "starting", "unknown":
+		phase(PhaseBooting)
+		ready, err := s.WaitForReady(ctx, workspaceID, sessionID, interval, nil)
+		if err != nil {
+			return Session{}, fmt.Errorf("waiting for session: %w", err)
+		}
+		if ready.Status != "running" {
+			return Session{}, fmt.Errorf("session ended provisioning with status %q (expected running)", ready.Status)
+		}
+	default:
+		return Session{}, fmt.Errorf("session is %q and can't be connected to right now; try again shortly", sess.Status)
+	}
+	phase(PhaseSSHWaiting)
+	sess, err = s.WaitForSSHReady(ctx, workspaceID, sessionID, interval)
+	if err != nil {
+		return Session{}, fmt.Errorf("waiting for SSH access: %w", err)
+	}
+	return sess, nil
+}
+
 // DeleteSession permanently removes a session.
 func (s *Service) DeleteSession(ctx context.Context, workspaceID, sessionID string) error {
 	if s.client == nil {
@@ -0,0 +1,132 @@
+package rde
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ShellOptions configures Shell.
+type ShellOptions struct {
+	// Command is the TTY program to run (already shell-quoted); empty opens
+	// the remote user's login shell.
+	Command string
+	// ForwardAgent forwards the local SSH agent into the session (ssh -A).
+	ForwardAgent bool
+	// NoTmux runs the program directly instead of inside a tmux session, so
+	// it does not survive a dropped connection.
+	NoTmux bool
+	// ReconnectInterval paces reattach attempts; zero means
+	// DefaultShellReconnectInterval.
+	ReconnectInterval time.Duration
+	// OnReconnect, when non-nil, is called before each reattach attempt with
+	// its 1-based number and the error that ended the previous one.
+	OnReconnect func(attempt int, err error)
+}
+
+// DefaultShellReconnectInterval is the pause between reattach attempts after
+// the connection drops — the same pace as the `rde claude` reattach.
+const DefaultShellReconnectInterval = 3 * time.Second
+
+// shellLostExitCode is what the reattach command exits with when the tmux
+// session is gone without leaving an exit status behind (the VM rebooted, or
+// tmux was killed): there is nothing left to reattach to.
+const shellLostExitCode = 255
+
+// Shell attaches the caller's terminal to a login shell (or opts.Command) on
+// the session and blocks until it exits, returning its exit code. It is
+// ExecuteInteractive plus the survivability `rde claude` gets from tmux: when
+// stdin is a terminal, the program runs in a uniquely named tmux session
+// (if the image has tmux), so when the connection drops Shell reattaches to
+// it, retrying every ReconnectInterval until it is reachable again or ctx is
+// cancelled. A command's exit status is recorded on the session and handed
+// back even when it finished while the connection was down.
+//
+// Without tmux (opts.NoTmux, no TTY, or none installed on the image) a
+// dropped shell is replaced by a fresh one on reconnect, and a dropped
+// command ends the run with ErrConnectionLost — rerunning it could repeat
+// its side effects.
+func (s *Service) Shell(ctx context.Context, workspaceID, sessionID string, opts ShellOptions, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	if s.client == nil {
+		return -1, errClient()
+	}
+	name := ""
+	if _, tty := ttyFd(stdin); tty && !opts.NoTmux {
+		name = "rde-ssh-" + strings.ToLower(rand.Text()[:8])
+	}
+	interval := opts.ReconnectInterval
+	if interval <= 0 {
+		interval = DefaultShellReconnectInterval
+	}
+
+	code, err := s.executeInteractive(ctx, workspaceID, sessionID, shellLaunchCommand(opts.Command, name), opts.ForwardAgent, stdin, stdout, stderr)
+	if !errors.Is(err, ErrConnectionLost) || (name == "" && opts.Command != "") {
+		return code, err
+	}
+	for attempt := 1; ; attempt++ {
+		if opts.OnReconnect != nil {
+			opts.OnReconnect(attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return -1, fmt.Errorf("reconnect interrupted: %w", ctx.Err())
+		case <-time.After(interval):
+		}
+		code, err = s.executeInteractive(ctx, workspaceID, sessionID, shellReattachCommand(opts.Command, name), opts.ForwardAgent, stdin, stdout, stderr)
+		if !errors.Is(err, ErrConnectionLost) {
+			if err != nil && ctx.Err() != nil {
+				return -1, fmt.Errorf("reconnect interrupted: %w", ctx.Err())
+			}
+			return code, err
+		}
+	}
+}
+
+// loginShell replaces the wrapping bash with the user's own login shell.
+const loginShell = `exec "${SHELL:-bash}" -l`
+
+// shellStatusFile is where a command run under tmux leaves its exit status,
+// since tmux itself always exits 0.
+func shellStatusFile(name string) string {
+	return `"${TMPDIR:-/tmp}/` + name + `.status"`
+}
+
+// shellLaunchCommand builds the first attach. With a tmux session name it
+// starts (or joins) that session when tmux is installed and falls back to
+// running the program directly when it isn't.
+func shellLaunchCommand(command, name string) string {
+	direct := loginShell
+	if command != "" {
+		direct = command
+	}
+	if name == "" {
+		return direct
+	}
+	inTmux := "exec tmux new-session -A -s " + name
+	if command != "" {
+		f := shellStatusFile(name)
+		inTmux = "tmux new-session -A -s " + name + " " + shellSingleQuote(command+"; echo $? >"+f) +
+			"; s=$(cat " + f + " 2>/dev/null); rm -f " + f + `; exit "${s:-0}"`
+	}
+	return "if command -v tmux >/dev/null 2>&1; then " + inTmux + "; else " + direct + "; fi"
+}
+
+// shellReattachCommand builds every attach after a dropped connection. A
+// shell simply relaunches: `new-session -A` joins the surviving session, or
+// starts a fresh shell if it's gone. A command reattaches only — if it
+// already finished, its recorded exit status is returned; if it vanished
+// without one, the attach fails with shellLostExitCode rather than rerunning
+// it.
+func shellReattachCommand(command, name string) string {
+	if command == "" {
+		return shellLaunchCommand("", name)
+	}
+	f := shellStatusFile(name)
+	return "if tmux has-session -t " + name + " 2>/dev/null; then tmux attach-session -t " + name +
+		"; elif [ ! -e " + f + " ]; then echo 'rde: the command did not survive the disconnect' >&2; exit " + fmt.Sprint(shellLostExitCode) +
+		"; fi; s=$(cat " + f + " 2>/dev/null); rm -f " + f + `; exit "${s:-0}"`
+}
@@ -0,0 +1,143 @@
+package rde
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
+)
+
+// runBash runs script the way the session's wrapping bash would, with a PATH
+// holding only cat and rm — so `command -v tmux` fails — and TMPDIR at dir.
+func runBash(t *testing.T, dir, script string) int {
+	t.Helper()
+	bin := t.TempDir()
+	for _, tool := range []string{"cat", "rm"} {
+		p, err := exec.LookPath(tool)
+		if err != nil {
+			t.Skipf("%s not available: %v", tool, err)
+		}
+		if err := os.Symlink(p, filepath.Join(bin, tool)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skipf("bash not available: %v", err)
+	}
+	cmd := exec.Command(bash, "-c", script) //nolint:gosec // test-built script
+	cmd.Env = []string{"PATH=" + bin, "TMPDIR=" + dir, "SHELL=" + bash}
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("run %q: %v", script, err)
+	}
+	return 0
+}
+
+func TestShellLaunchCommand_WithoutTmuxName(t *testing.T) {
+	if got := shellLaunchCommand("", ""); got != loginShell {
+		t.Errorf("shell = %q, want %q", got, loginShell)
+	}
+	if got := shellLaunchCommand("top", ""); got != "top" {
+		t.Errorf("command = %q, want top", got)
+	}
+}
+
+func TestShellLaunchCommand_FallsBackWithoutTmux(t *testing.T) {
+	dir := t.TempDir()
+	if got := runBash(t, dir, shellLaunchCommand("exit 3", "rde-ssh-test")); got != 3 {
+		t.Errorf("exit = %d, want the command's 3", got)
+	}
+}
+
+func TestShellReattachCommand_ReportsRecordedStatus(t *testing.T) {
+	dir := t.TempDir()
+	status := filepath.Join(dir, "rde-ssh-test.status")
+	if err := os.WriteFile(status, []byte("7\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := runBash(t, dir, shellReattachCommand("make", "rde-ssh-test")); got != 7 {
+		t.Errorf("exit = %d, want the recorded 7", got)
+	}
+	if _, err := os.Stat(status); !os.IsNotExist(err) {
+		t.Errorf("status file should be removed after it's read (stat err: %v)", err)
+	}
+	if got := runBash(t, dir, shellReattachCommand("make", "rde-ssh-test")); got != shellLostExitCode {
+		t.Errorf("exit without a session or status = %d, want %d", got, shellLostExitCode)
+	}
+}
+
+func TestShellReattachCommand_ShellRelaunches(t *testing.T) {
+	if got, want := shellReattachCommand("", "rde-ssh-x"), shellLaunchCommand("", "rde-ssh-x"); got != want {
+		t.Errorf("reattach = %q, want the launch command %q", got, want)
+	}
+	if !strings.Contains(shellLaunchCommand("", "rde-ssh-x"), "new-session -A -s rde-ssh-x") {
+		t.Error("launch should join an existing tmux session of the same name")
+	}
+}
+
+func TestEnsureSSHReady_TerminatedNeedsRestore(t *testing.T) {
+	rs := newRecordingServer(t, `{"session":{"id":"s1","status":"SESSION_STATUS_TERMINATED"}}`)
+	_, err := rs.service().EnsureSSHReady(context.Background(), "ws-1", "s1", false, time.Millisecond, nil)
+	if !errors.Is(err, ErrSessionStopped) {
+		t.Fatalf("err = %v, want ErrSessionStopped", err)
+	}
+	if rs.lastMethod != http.MethodGet {
+		t.Errorf("without restore only a GET should be sent, last was %s", rs.lastMethod)
+	}
+}
+
+func TestEnsureSSHReady_RestoresAndWaitsForSSH(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var restored bool
+	var phases []string
+	gets := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if !strings.HasSuffix(r.URL.Path, "/sessions/s1/restore") {
+				t.Errorf("unexpected POST %s", r.URL.Path)
+			}
+			restored = true
+			_, _ = io.WriteString(w, `{"session":{"id":"s1","status":"SESSION_STATUS_STARTING"}}`)
+			return
+		}
+		gets++
+		switch {
+		case !restored:
+			_, _ = io.WriteString(w, `{"session":{"id":"s1","status":"SESSION_STATUS_TERMINATED","persistentDiskStatus":"PERSISTENT_DISK_STATUS_AVAILABLE"}}`)
+		case gets < 4:
+			_, _ = io.WriteString(w, `{"session":{"id":"s1","status":"SESSION_STATUS_RUNNING"}}`)
+		default:
+			_, _ = io.WriteString(w, `{"session":{"id":"s1","status":"SESSION_STATUS_RUNNING","sshConnectionOpen":true,"sshAddress":"ssh ubuntu@h -p 22","sshPassword":"pw"}}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	svc := NewService(rdeapi.New(srv.URL, "tok"))
+	sess, err := svc.EnsureSSHReady(context.Background(), "ws-1", "s1", true, time.Millisecond, func(p string) { phases = append(phases, p) })
+	if err != nil {
+		t.Fatalf("EnsureSSHReady: %v", err)
+	}
+	if !restored {
+		t.Error("terminated session should have been restored")
+	}
+	if sess.SSHPassword != "pw" {
+		t.Errorf("returned session should carry SSH credentials, got %+v", sess)
+	}
+	if want := []string{PhaseRestoring, PhaseBooting, PhaseSSHWaiting}; strings.Join(phases, ",") != strings.Join(want, ",") {
+		t.Errorf("phases = %v, want %v", phases, want)
+	}
+}
@@ -0,0 +1,358 @@
+package rde
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // storage reports MD5; it's an integrity check, not a security one
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cloud-storage transfer tuning. Vars so tests can shrink them.
+var (
+	// gcsIdleTimeout abandons a transfer leg on which no bytes moved for this
+	// long. It is an idle timeout, not a total one: a multi-GB transfer on a
+	// slow link may take far longer, as long as it keeps moving.
+	gcsIdleTimeout = 2 * time.Minute
+	// transferRetryBackoff paces retries of a failed leg.
+	transferRetryBackoff = 2 * time.Second
+	// resumableChunkSize is the PUT size for resumable uploads; storage
+	// requires a multiple of 256 KiB.
+	resumableChunkSize int64 = 16 << 20
+)
+
+// transferMaxAttempts bounds consecutive failed attempts at a leg. An attempt
+// that moved bytes resets the count, so a long transfer survives any number
+// of spread-out drops.
+const transferMaxAttempts = 5
+
+// statusError is a storage response with an error status.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.code, strings.TrimSpace(e.body))
+}
+
+func newStatusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+	return &statusError{code: resp.StatusCode, body: string(body)}
+}
+
+// retryableTransferErr reports whether a failed leg is worth another
+// attempt: a network error or an idle timeout, or a status storage documents
+// as transient.
+func retryableTransferErr(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code == http.StatusRequestTimeout || se.code == http.StatusTooManyRequests || se.code >= 500
+	}
+	var ce *checksumError
+	return !errors.As(err, &ce)
+}
+
+// checksums hashes a payload the way storage reports it (x-goog-hash).
+type checksums struct {
+	md5    hash.Hash
+	crc32c hash.Hash32
+}
+
+func newChecksums() *checksums {
+	return &checksums{md5: md5.New(), crc32c: crc32.New(crc32.MakeTable(crc32.Castagnoli))} //nolint:gosec // see import
+}
+
+func (c *checksums) Write(p []byte) (int, error) {
+	_, _ = c.md5.Write(p)
+	_, _ = c.crc32c.Write(p)
+	return len(p), nil
+}
+
+func (c *checksums) reset() {
+	c.md5.Reset()
+	c.crc32c.Reset()
+}
+
+// checksumError is a payload whose checksum doesn't match storage's.
+type checksumError struct{ algo string }
+
+func (e *checksumError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch: the transferred data is corrupt", e.algo)
+}
+
+// verify compares against the x-goog-hash values in h ("crc32c=…", "md5=…",
+// possibly comma-joined). MD5 is preferred; composite objects carry only
+// CRC32C. Without either there is nothing to check against.
+func (c *checksums) verify(h http.Header) error {
+	reported := map[string]string{}
+	for _, v := range h.Values("X-Goog-Hash") {
+		for _, kv := range strings.Split(v, ",") {
+			if k, val, ok := strings.Cut(strings.TrimSpace(kv), "="); ok {
+				reported[k] = val
+			}
+		}
+	}
+	if want, ok := reported["md5"]; ok {
+		if base64.StdEncoding.EncodeToString(c.md5.Sum(nil)) != want {
+			return &checksumError{algo: "MD5"}
+		}
+		return nil
+	}
+	if want, ok := reported["crc32c"]; ok {
+		if base64.StdEncoding.EncodeToString(c.crc32c.Sum(nil)) != want {
+			return &checksumError{algo: "CRC32C"}
+		}
+	}
+	return nil
+}
+
+// withIdleTimeout derives a context that is cancelled once m sees no bytes
+// for gcsIdleTimeout. The returned stop must be called when the leg ends.
+func withIdleTimeout(ctx context.Context, m *meter) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	t := time.AfterFunc(gcsIdleTimeout, cancel)
+	m.setKick(func() { t.Reset(gcsIdleTimeout) })
+	return ctx, func() { m.setKick(nil); t.Stop(); cancel() }
+}
+
+// retryWait sleeps before the next attempt, or returns ctx's error.
+func retryWait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(transferRetryBackoff):
+		return nil
+	}
+}
+
+// isResumableSessionURL reports whether signedURL is a storage resumable
+// upload session (it carries an upload_id) rather than a plain signed PUT.
+func isResumableSessionURL(signedURL string) bool {
+	u, err := url.Parse(signedURL)
+	return err == nil && u.Query().Get("upload_id") != ""
+}
+
+// putToSignedURL uploads the first size bytes of spool. A resumable session
+// URL is fed in chunks and resumed from the last acknowledged byte after a
+// failure; a plain signed URL, which can only take the whole object in one
+// request, is retried from the start. The object storage ends up with is
+// checked against sums when it reports a checksum.
+func putToSignedURL(ctx context.Context, signedURL string, spool io.ReaderAt, size int64, sums *checksums, m *meter) error {
+	if isResumableSessionURL(signedURL) {
+		return putResumable(ctx, signedURL, spool, size, sums, m)
+	}
+	for attempt := 1; ; attempt++ {
+		m.set(0)
+		err := putOnce(ctx, signedURL, spool, size, sums, m)
+		if err == nil || ctx.Err() != nil || !retryableTransferErr(err) || attempt == transferMaxAttempts {
+			return err
+		}
+		if err := retryWait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func putOnce(ctx context.Context, signedURL string, spool io.ReaderAt, size int64, sums *checksums, m *meter) error {
+	ctx, stop := withIdleTimeout(ctx, m)
+	defer stop()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, signedURL, meteredReader{io.NewSectionReader(spool, 0, size), m})
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // body drained or abandoned; close error is non-actionable
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("upload failed (%w)", newStatusError(resp))
+	}
+	return sums.verify(resp.Header)
+}
+
+// putResumable drives a resumable upload session: PUT chunks with
+// Content-Range, and after a failure ask storage how much it persisted and
+// continue from there.
+func putResumable(ctx context.Context, sessionURL string, spool io.ReaderAt, size int64, sums *checksums, m *meter) error {
+	var offset int64
+	failures := 0
+	for {
+		end := min(offset+resumableChunkSize, size)
+		m.set(offset)
+		next, resp, err := putChunk(ctx, sessionURL, spool, offset, end, size, m)
+		if err == nil && resp != nil {
+			return sums.verify(resp.Header)
+		}
+		if err == nil {
+			offset, failures = next, 0
+			continue
+		}
+		if ctx.Err() != nil || !retryableTransferErr(err) {
+			return err
+		}
+		if failures++; failures == transferMaxAttempts {
+			return err
+		}
+		if err := retryWait(ctx); err != nil {
+			return err
+		}
+		// Ask where to resume: storage may have persisted part of the chunk.
+		next, resp, err = putChunk(ctx, sessionURL, nil, 0, 0, size, m)
+		if err == nil && resp != nil {
+			return sums.verify(resp.Header)
+		}
+		if err == nil {
+			offset = next
+		}
+	}
+}
+
+// putChunk sends bytes [start, end) of a size-byte object to a resumable
+// session; a nil spool sends none and just queries the session's state. It
+// returns either the offset storage has persisted up to (upload incomplete)
+// or the final response (upload complete, body already closed).
+func putChunk(ctx context.Context, sessionURL string, spool io.ReaderAt, start, end, size int64, m *meter) (int64, *http.Response, error) {
+	ctx, stop := withIdleTimeout(ctx, m)
+	defer stop()
+	var body io.Reader = http.NoBody
+	contentRange := fmt.Sprintf("bytes */%d", size)
+	if spool != nil && end > start {
+		body = meteredReader{io.NewSectionReader(spool, start, end-start), m}
+		contentRange = fmt.Sprintf("bytes %d-%d/%d", start, end-1, size)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURL, body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("create request: %w", err)
+	}
+	if spool != nil {
+		req.ContentLength = end - start
+	}
+	req.Header.Set("Content-Range", contentRange)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("execute request: %w", err)
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated:
+		return size, resp, nil
+	case resp.StatusCode == http.StatusPermanentRedirect: // 308: incomplete
+		// Range: bytes=0-N names the last persisted byte; absent means none.
+		if _, last, ok := strings.Cut(resp.Header.Get("Range"), "-"); ok {
+			if n, err := strconv.ParseInt(last, 10, 64); err == nil {
+				return n + 1, nil, nil
+			}
+		}
+		return 0, nil, nil
+	}
+	return 0, nil, fmt.Errorf("upload failed (%w)", &statusError{code: resp.StatusCode})
+}
+
+// getFromSignedURL streams the object at signedURL into spool and returns
+// its size. A dropped connection resumes with a range request from the last
+// byte written. The result is checked against the checksum storage reports.
+func getFromSignedURL(ctx context.Context, signedURL string, spool *os.File, m *meter) (int64, error) {
+	sums := newChecksums()
+	var offset int64
+	failures := 0
+	for {
+		n, done, header, err := getOnce(ctx, signedURL, spool, offset, sums, m)
+		if err == nil && done {
+			return n, sums.verify(header)
+		}
+		if err == nil || n > offset {
+			failures = 0 // progress, or a clean short read: resume right away
+		}
+		offset = n
+		if err != nil {
+			if ctx.Err() != nil || !retryableTransferErr(err) {
+				return 0, err
+			}
+			if failures++; failures == transferMaxAttempts {
+				return 0, err
+			}
+			if err := retryWait(ctx); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// getOnce fetches the object from offset on, appending to spool. It returns
+// the bytes spool now holds, whether that's the whole object, and the
+// response header (for its checksums).
+func getOnce(ctx context.Context, signedURL string, spool *os.File, offset int64, sums *checksums, m *meter) (int64, bool, http.Header, error) {
+	ctx, stop := withIdleTimeout(ctx, m)
+	defer stop()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signedURL, nil)
+	if err != nil {
+		return offset, false, nil, fmt.Errorf("create request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return offset, false, nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // body consumed below; close error is non-actionable
+
+	var total int64 = -1
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		if _, t, ok := strings.Cut(resp.Header.Get("Content-Range"), "/"); ok {
+			if n, err := strconv.ParseInt(t, 10, 64); err == nil {
+				total = n
+			}
+		}
+	case resp.StatusCode == http.StatusOK:
+		// Full object: either the first request, or storage ignored the
+		// range — then start over.
+		if offset > 0 {
+			if err := restartSpool(spool, sums, m); err != nil {
+				return 0, false, nil, err
+			}
+			offset = 0
+		}
+		total = resp.ContentLength
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		return offset, true, resp.Header, nil // we already hold it all
+	default:
+		return offset, false, nil, fmt.Errorf("download failed (%w)", newStatusError(resp))
+	}
+	if total >= 0 {
+		m.setTotal(total)
+	}
+	m.set(offset)
+	n, err := io.Copy(io.MultiWriter(spool, sums, m), resp.Body)
+	offset += n
+	if err != nil {
+		return offset, false, nil, fmt.Errorf("read response: %w", err)
+	}
+	return offset, total < 0 || offset >= total, resp.Header, nil
+}
+
+func restartSpool(spool *os.File, sums *checksums, m *meter) error {
+	if err := spool.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	sums.reset()
+	m.set(0)
+	return nil
+}
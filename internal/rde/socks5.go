@@ -0,0 +1,134 @@
+package rde
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// SOCKS5 (RFC 1928) constants for the subset serveSOCKS5 speaks: no
+// authentication, CONNECT only.
+const (
+	socksVersion       = 0x05
+	socksNoAuth        = 0x00
+	socksNoAcceptable  = 0xff
+	socksCmdConnect    = 0x01
+	socksAddrIPv4      = 0x01
+	socksAddrDomain    = 0x03
+	socksAddrIPv6      = 0x04
+	socksReplySuccess  = 0x00
+	socksReplyRefused  = 0x05
+	socksReplyCmd      = 0x07
+	socksReplyAddrType = 0x08
+)
+
+// socksHandshakeTimeout bounds the negotiation, so a client that connects and
+// goes silent doesn't pin a goroutine.
+const socksHandshakeTimeout = 10 * time.Second
+
+// serveSOCKS5 handles one client of a dynamic forward: it negotiates SOCKS5
+// (no auth, CONNECT), dials the requested address with dial — over the SSH
+// connection, so names resolve and connections originate on the session —
+// and bridges the two until either side closes. It owns and closes conn.
+// Authentication is unnecessary because the listener is loopback-only unless
+// the user binds it elsewhere explicitly, as with ssh -D.
+func serveSOCKS5(ctx context.Context, conn net.Conn, dial func(addr string) (net.Conn, error)) {
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(socksHandshakeTimeout))
+	addr, err := socksHandshake(conn)
+	if err != nil {
+		return
+	}
+	remote, err := dial(addr)
+	if err != nil {
+		_ = socksReply(conn, socksReplyRefused)
+		return
+	}
+	defer func() { _ = remote.Close() }()
+	if socksReply(conn, socksReplySuccess) != nil {
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+	bridgeConn(ctx, conn, remote)
+}
+
+// socksHandshake reads the method negotiation and the request, answering
+// everything but the final reply, and returns the CONNECT target.
+func socksHandshake(rw io.ReadWriter) (string, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(rw, hdr[:]); err != nil {
+		return "", err
+	}
+	if hdr[0] != socksVersion {
+		return "", fmt.Errorf("socks: unsupported version %d", hdr[0])
+	}
+	methods := make([]byte, hdr[1])
+	if _, err := io.ReadFull(rw, methods); err != nil {
+		return "", err
+	}
+	method := byte(socksNoAcceptable)
+	for _, m := range methods {
+		if m == socksNoAuth {
+			method = socksNoAuth
+		}
+	}
+	if _, err := rw.Write([]byte{socksVersion, method}); err != nil {
+		return "", err
+	}
+	if method == socksNoAcceptable {
+		return "", fmt.Errorf("socks: client offers no supported auth method")
+	}
+
+	var req [4]byte
+	if _, err := io.ReadFull(rw, req[:]); err != nil {
+		return "", err
+	}
+	if req[0] != socksVersion {
+		return "", fmt.Errorf("socks: unsupported version %d", req[0])
+	}
+	var host string
+	switch req[3] {
+	case socksAddrIPv4, socksAddrIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if req[3] == socksAddrIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err := io.ReadFull(rw, ip); err != nil {
+			return "", err
+		}
+		host = ip.String()
+	case socksAddrDomain:
+		var n [1]byte
+		if _, err := io.ReadFull(rw, n[:]); err != nil {
+			return "", err
+		}
+		name := make([]byte, n[0])
+		if _, err := io.ReadFull(rw, name); err != nil {
+			return "", err
+		}
+		host = string(name)
+	default:
+		_ = socksReply(rw, socksReplyAddrType)
+		return "", fmt.Errorf("socks: unsupported address type %d", req[3])
+	}
+	var port [2]byte
+	if _, err := io.ReadFull(rw, port[:]); err != nil {
+		return "", err
+	}
+	if req[1] != socksCmdConnect {
+		_ = socksReply(rw, socksReplyCmd)
+		return "", fmt.Errorf("socks: unsupported command %d", req[1])
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port[:])))), nil
+}
+
+// socksReply writes a reply with an all-zero IPv4 bound address: clients use
+// the tunnel, not the address the session dialed from.
+func socksReply(w io.Writer, code byte) error {
+	_, err := w.Write([]byte{socksVersion, code, 0x00, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,81 @@
+package rde
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+)
+
+// spoolDir is where UploadFile and DownloadFile keep the archive in flight:
+// on disk under the CLI's config directory rather than in the system temp
+// directory, which is often a RAM-backed tmpfs.
+func spoolDir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache", "transfer"), nil
+}
+
+// createSpool creates an archive spool file, first checking that the disk
+// has room for need bytes when need is known (> 0).
+func createSpool(pattern string, need int64) (*os.File, error) {
+	dir, err := spoolDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if free, ok := diskFree(dir); ok && need > 0 && uint64(need) > free {
+		return nil, fmt.Errorf("not enough disk space in %s for the archive: it needs about %s, %s is free (set XDG_CONFIG_HOME to use another disk)",
+			dir, formatSpoolBytes(uint64(need)), formatSpoolBytes(free))
+	}
+	return os.CreateTemp(dir, pattern)
+}
+
+// spoolErr explains a write that ran out of disk space.
+func spoolErr(spool *os.File, err error) error {
+	if diskFull(err) {
+		return fmt.Errorf("not enough disk space in %s for the archive (set XDG_CONFIG_HOME to use another disk): %w", filepath.Dir(spool.Name()), err)
+	}
+	return err
+}
+
+// archiveSizeEstimate is an upper bound of the archive writeTarGz makes of
+// sourcePath before compression: every regular file's data padded to tar's
+// 512-byte blocks, plus a header block per entry.
+func archiveSizeEstimate(sourcePath string) (int64, error) {
+	var n int64
+	err := filepath.WalkDir(sourcePath, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		n += 512
+		if d.Type().IsRegular() {
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			n += (fi.Size() + 511) &^ 511
+		}
+		return nil
+	})
+	return n + 1024, err
+}
+
+func formatSpoolBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,273 @@
+package rde
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+)
+
+// sessionSSHDAddr is the session's sshd as seen from inside the VM. The proxy
+// tunnels to it over the CLI's own connection, so the user's ssh client talks
+// to the real sshd end to end. A var so tests can point it elsewhere.
+var sessionSSHDAddr = "127.0.0.1:22"
+
+// proxyKeyComment tags the CLI-managed key in the session's authorized_keys.
+const proxyKeyComment = "bitrise-cli-rde"
+
+// ProxyKey is the CLI-managed key pair that `rde session ssh-config` points
+// IdentityFile at. Session passwords are ephemeral and rotate on restore, so
+// an ssh client can't be handed one; instead ProxySSH installs this key on the
+// session before tunnelling, and the client authenticates with it.
+type ProxyKey struct {
+	// Path is the private key file.
+	Path string
+	// AuthorizedKey is the public key as an authorized_keys line.
+	AuthorizedKey string
+}
+
+// proxyKeyPath is where the CLI-managed key lives: next to the rest of the
+// CLI's state, not in ~/.ssh, so it never shadows the user's own keys.
+func proxyKeyPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rde", "ssh", "id_ed25519"), nil
+}
+
+// EnsureProxyKey returns the CLI-managed key pair, generating it on first use.
+func EnsureProxyKey() (ProxyKey, error) {
+	path, err := proxyKeyPath()
+	if err != nil {
+		return ProxyKey{}, err
+	}
+	if data, err := os.ReadFile(path); err == nil { //nolint:gosec // path is under the CLI's config dir
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return ProxyKey{}, fmt.Errorf("parse %s: %w (delete it to have a new key generated)", path, err)
+		}
+		return ProxyKey{Path: path, AuthorizedKey: authorizedKeyLine(signer.PublicKey())}, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return ProxyKey{}, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return ProxyKey{}, fmt.Errorf("generate ssh key: %w", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, proxyKeyComment)
+	if err != nil {
+		return ProxyKey{}, fmt.Errorf("encode ssh key: %w", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return ProxyKey{}, fmt.Errorf("encode ssh key: %w", err)
+	}
+	line := authorizedKeyLine(sshPub)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return ProxyKey{}, fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	// OpenSSH refuses a private key readable by anyone else.
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		return ProxyKey{}, fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.WriteFile(path+".pub", []byte(line+"\n"), 0o600); err != nil {
+		return ProxyKey{}, fmt.Errorf("write %s.pub: %w", path, err)
+	}
+	return ProxyKey{Path: path, AuthorizedKey: line}, nil
+}
+
+func authorizedKeyLine(pub ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub))) + " " + proxyKeyComment
+}
+
+// installAuthorizedKeyCmd appends line to ~/.ssh/authorized_keys unless it is
+// already there, creating the file with the permissions sshd insists on.
+func installAuthorizedKeyCmd(line string) string {
+	q := shellSingleQuote(line)
+	return "umask 077; mkdir -p ~/.ssh && touch ~/.ssh/authorized_keys && " +
+		"{ grep -qxF " + q + " ~/.ssh/authorized_keys || printf '%s\\n' " + q + " >> ~/.ssh/authorized_keys; }"
+}
+
+// ProxySSH makes the session's sshd reachable on stdin/stdout, for use as an
+// OpenSSH ProxyCommand. It fetches the session's current ephemeral address and
+// password through GetSession, dials it with them, installs authorizedKey when
+// non-empty (see ProxyKey), and then pipes stdio to the session's own sshd
+// until either side closes or ctx is cancelled. The user's ssh client runs its
+// protocol through the pipe, so scp, rsync, and IDE remotes work unchanged.
+// The session must be running; EnsureSSHReady gets it there.
+func (s *Service) ProxySSH(ctx context.Context, workspaceID, sessionID, authorizedKey string, stdin io.Reader, stdout io.Writer) error {
+	if s.client == nil {
+		return errClient()
+	}
+	sess, err := s.GetSession(ctx, workspaceID, sessionID)
+	if err != nil {
+		return fmt.Errorf("fetch session: %w", err)
+	}
+	target, err := sshTargetForSession(sess)
+	if err != nil {
+		return err
+	}
+	target.NoAgentForwarding = true
+	client, err := dialSSHWithRetry(ctx, target)
+	if err != nil {
+		return err
+	}
+	defer client.Close() //nolint:errcheck // the proxy is done; nothing actionable on close failure
+
+	if authorizedKey != "" {
+		res, err := client.run(ctx, installAuthorizedKeyCmd(authorizedKey))
+		if err != nil {
+			return fmt.Errorf("install ssh key on the session: %w", err)
+		}
+		if res.ExitCode != 0 {
+			return fmt.Errorf("install ssh key on the session: exit status %d: %s", res.ExitCode, strings.TrimSpace(res.Stderr))
+		}
+	}
+
+	conn, err := client.client.Dial("tcp", sessionSSHDAddr)
+	if err != nil {
+		return fmt.Errorf("connect to the session's sshd: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	done := make(chan struct{})
+	defer close(done)
+	go client.keepAlive(done)
+
+	// stdin EOF only half-closes: the client may still be reading the
+	// server's last bytes. The server closing ends the proxy.
+	go func() { _, _ = io.Copy(conn, stdin); halfCloseWrite(conn) }()
+	copied := make(chan error, 1)
+	go func() { _, err := io.Copy(stdout, conn); copied <- err }()
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-copied:
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrConnectionLost, err)
+		}
+		return nil
+	}
+}
+
+// SSHConfigHost is one `Host` block of `rde session ssh-config`. JSON tags are
+// part of its --output json contract.
+type SSHConfigHost struct {
+	Host         string `json:"host"`
+	SessionID    string `json:"session_id"`
+	SessionName  string `json:"session_name,omitempty"`
+	User         string `json:"user,omitempty"`
+	ProxyCommand string `json:"proxy_command"`
+	IdentityFile string `json:"identity_file"`
+}
+
+// SSHConfigHostPrefix starts every generated Host alias, keeping them apart
+// from the user's own hosts.
+const SSHConfigHostPrefix = "rde-"
+
+var hostAliasUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// SSHConfigHosts builds a Host block per session. proxyCommand returns the
+// ProxyCommand for a session ID. Aliases are SSHConfigHostPrefix plus the
+// session name made ssh-safe (the ID when it has none); a name shared by
+// several sessions gets a short ID suffix so every alias is unique. User comes
+// from the session's SSH address and is left empty when the session has none
+// yet (it isn't running).
+func SSHConfigHosts(sessions []Session, keyPath string, proxyCommand func(sessionID string) string) []SSHConfigHost {
+	aliases := make([]string, len(sessions))
+	counts := map[string]int{}
+	for i, sess := range sessions {
+		alias := strings.Trim(hostAliasUnsafe.ReplaceAllString(strings.ToLower(sess.Name), "-"), "-.")
+		if alias == "" {
+			alias = sess.ID
+		}
+		aliases[i] = SSHConfigHostPrefix + alias
+		counts[aliases[i]]++
+	}
+	out := make([]SSHConfigHost, 0, len(sessions))
+	for i, sess := range sessions {
+		alias := aliases[i]
+		if counts[alias] > 1 && len(sess.ID) >= 8 {
+			alias += "-" + sess.ID[:8]
+		}
+		h := SSHConfigHost{
+			Host:         alias,
+			SessionID:    sess.ID,
+			SessionName:  sess.Name,
+			ProxyCommand: proxyCommand(sess.ID),
+			IdentityFile: keyPath,
+		}
+		if t, err := parseSSHAddress(sess.SSHAddress); err == nil {
+			h.User = t.User
+		}
+		out = append(out, h)
+	}
+	return out
+}
+
+// WriteSSHConfig writes hosts in ssh_config(5) syntax. The session's host key
+// changes with every VM, and the hop to it is already authenticated by the
+// CLI's own connection, so host-key checking is turned off for these hosts
+// only.
+func WriteSSHConfig(w io.Writer, hosts []SSHConfigHost) error {
+	var b strings.Builder
+	for i, h := range hosts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# Bitrise RDE session %s", h.SessionID)
+		if h.SessionName != "" {
+			fmt.Fprintf(&b, " (%s)", h.SessionName)
+		}
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "Host %s\n", h.Host)
+		if h.User != "" {
+			fmt.Fprintf(&b, "  User %s\n", h.User)
+		} else {
+			b.WriteString("  # User unknown until the session runs; re-run ssh-config or pass -l USER.\n")
+		}
+		fmt.Fprintf(&b, "  ProxyCommand %s\n", h.ProxyCommand)
+		fmt.Fprintf(&b, "  IdentityFile %s\n", sshConfigArg(h.IdentityFile))
+		b.WriteString("  IdentitiesOnly yes\n")
+		b.WriteString("  StrictHostKeyChecking no\n")
+		b.WriteString("  UserKnownHostsFile /dev/null\n")
+		b.WriteString("  LogLevel ERROR\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sshConfigArg escapes s for an ssh_config value that expands % tokens, and
+// double-quotes it when it contains whitespace (ssh_config has no escape for a
+// literal double quote).
+func sshConfigArg(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if strings.ContainsAny(s, " \t") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// SSHProxyCommand builds a ProxyCommand line running exe with args. Arguments
+// containing whitespace are double-quoted, which both the POSIX shell OpenSSH
+// runs it through and Windows OpenSSH understand.
+func SSHProxyCommand(exe string, args ...string) string {
+	parts := make([]string, 0, len(args)+1)
+	for _, a := range append([]string{exe}, args...) {
+		parts = append(parts, sshConfigArg(a))
+	}
+	return strings.Join(parts, " ")
+}
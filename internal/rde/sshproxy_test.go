@@ -0,0 +1,123 @@
+package rde
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnsureProxyKey_GeneratedOnceAndReused(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	first, err := EnsureProxyKey()
+	if err != nil {
+		t.Fatalf("EnsureProxyKey: %v", err)
+	}
+	if !strings.HasPrefix(first.AuthorizedKey, "ssh-ed25519 ") || !strings.HasSuffix(first.AuthorizedKey, " "+proxyKeyComment) {
+		t.Errorf("authorized key = %q, want an ed25519 line tagged %s", first.AuthorizedKey, proxyKeyComment)
+	}
+	if runtime.GOOS != "windows" {
+		fi, err := os.Stat(first.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != 0o600 {
+			t.Errorf("private key mode = %o, want 600 (OpenSSH rejects looser)", fi.Mode().Perm())
+		}
+	}
+	second, err := EnsureProxyKey()
+	if err != nil {
+		t.Fatalf("EnsureProxyKey (again): %v", err)
+	}
+	if second != first {
+		t.Errorf("second call = %+v, want the existing key %+v", second, first)
+	}
+}
+
+func TestInstallAuthorizedKeyCmd_Idempotent(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skipf("bash not available: %v", err)
+	}
+	home := t.TempDir()
+	line := "ssh-ed25519 AAAAkey it's-mine"
+	for range 2 {
+		cmd := exec.Command(bash, "-c", installAuthorizedKeyCmd(line)) //nolint:gosec // test-built script
+		cmd.Env = []string{"HOME=" + home, "PATH=" + os.Getenv("PATH")}
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("install: %v: %s", err, out)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".ssh", "authorized_keys"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != line+"\n" {
+		t.Errorf("authorized_keys = %q, want the key exactly once", got)
+	}
+}
+
+func TestProxySSH_PipesStdioToSessionSSHD(t *testing.T) {
+	srv := newTestSSHServer(t)
+	svc := srv.sessionAPI()
+	orig := sessionSSHDAddr
+	sessionSSHDAddr = echoListener(t)
+	t.Cleanup(func() { sessionSSHDAddr = orig })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var out bytes.Buffer
+	err := svc.ProxySSH(ctx, "ws-1", "s1", "ssh-ed25519 AAAAkey "+proxyKeyComment, strings.NewReader("SSH-2.0-probe\r\n"), &out)
+	if err != nil {
+		t.Fatalf("ProxySSH: %v", err)
+	}
+	if out.String() != "SSH-2.0-probe\r\n" {
+		t.Errorf("stdout = %q, want the bytes echoed back through the tunnel", out.String())
+	}
+	cmds := srv.commands()
+	if len(cmds) != 1 || !strings.Contains(cmds[0], "ssh-ed25519 AAAAkey") {
+		t.Errorf("exec commands = %q, want one installing the key", cmds)
+	}
+}
+
+func TestSSHConfigHosts(t *testing.T) {
+	sessions := []Session{
+		{ID: "11111111-aaaa", Name: "My App", SSHAddress: "ssh -p 2222 vagrant@1.2.3.4"},
+		{ID: "22222222-bbbb", Name: "dup"},
+		{ID: "33333333-cccc", Name: "dup"},
+		{ID: "44444444-dddd"},
+	}
+	hosts := SSHConfigHosts(sessions, "/k/id", func(id string) string { return SSHProxyCommand("/bin/bitrise cli", "rde", "ssh-proxy", id) })
+	var aliases []string
+	for _, h := range hosts {
+		aliases = append(aliases, h.Host)
+	}
+	want := []string{"rde-my-app", "rde-dup-22222222", "rde-dup-33333333", "rde-44444444-dddd"}
+	if strings.Join(aliases, ",") != strings.Join(want, ",") {
+		t.Errorf("aliases = %v, want %v", aliases, want)
+	}
+	if hosts[0].User != "vagrant" || hosts[1].User != "" {
+		t.Errorf("users = %q, %q; want vagrant from the address, and empty without one", hosts[0].User, hosts[1].User)
+	}
+
+	var b bytes.Buffer
+	if err := WriteSSHConfig(&b, hosts[:1]); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"Host rde-my-app\n",
+		"  User vagrant\n",
+		`  ProxyCommand "/bin/bitrise cli" rde ssh-proxy 11111111-aaaa` + "\n",
+		"  IdentityFile /k/id\n",
+		"  StrictHostKeyChecking no\n",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("config missing %q:\n%s", line, b.String())
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package rde
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strconv"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
+)
+
+// testSSHPassword is the password testSSHServer accepts.
+const testSSHPassword = "pw" // #nosec G101 -- test fixture
+
+// testSSHServer is an in-process sshd speaking just enough of the protocol
+// for the tunnel code: password auth, direct-tcpip channels (ssh -L),
+// tcpip-forward requests (ssh -R), and exec requests, which are recorded and
+// succeed without running anything unless runCommands is set. Everything it
+// dials, listens on, or runs is on this machine, so "the session" and "here"
+// share one loopback and filesystem.
+type testSSHServer struct {
+	t     *testing.T
+	cfg   *ssh.ServerConfig
+	ln    net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+	execs []string
+	// runCommands makes exec requests run under the local sh, with the
+	// channel as stdin, stdout, and stderr.
+	runCommands bool
+	// rejectForward, when set, is asked about every tcpip-forward request
+	// by its 1-based number; true refuses it as a taken port would.
+	rejectForward func(n int) bool
+	forwards      int
+}
+
+func newTestSSHServer(t *testing.T) *testSSHServer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &ssh.ServerConfig{PasswordCallback: func(_ ssh.ConnMetadata, pw []byte) (*ssh.Permissions, error) {
+		if string(pw) != testSSHPassword {
+			return nil, errors.New("wrong password")
+		}
+		return nil, nil
+	}}
+	cfg.AddHostKey(signer)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testSSHServer{t: t, cfg: cfg, ln: ln}
+	t.Cleanup(func() { _ = ln.Close(); s.dropConnections() })
+	go s.serve()
+	return s
+}
+
+// sessionAPI returns an RDE API stub reporting a running session whose SSH
+// endpoint is this server, and a Service talking to it. Auth falls back to the
+// password only: no agent, no key files.
+func (s *testSSHServer) sessionAPI() *Service {
+	s.t.Setenv("SSH_AUTH_SOCK", "")
+	s.t.Setenv("HOME", s.t.TempDir())
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"session":{"id":"s1","status":"SESSION_STATUS_RUNNING","sshConnectionOpen":true,"sshAddress":"ssh -p %s ubuntu@%s","sshPassword":%q}}`, port, host, testSSHPassword)
+	}))
+	s.t.Cleanup(api.Close)
+	return NewService(rdeapi.New(api.URL, "tok"))
+}
+
+// dropConnections severs every client connection, as a network blip would.
+func (s *testSSHServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	closeConns(s.conns...)
+	s.conns = nil
+}
+
+func (s *testSSHServer) serve() {
+	for {
+		nc, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, nc)
+		s.mu.Unlock()
+		go s.handle(nc)
+	}
+}
+
+func (s *testSSHServer) handle(nc net.Conn) {
+	sc, chans, reqs, err := ssh.NewServerConn(nc, s.cfg)
+	if err != nil {
+		return
+	}
+	go s.globalRequests(sc, reqs)
+	for nch := range chans {
+		if nch.ChannelType() == "session" {
+			go s.session(nch)
+			continue
+		}
+		if nch.ChannelType() != "direct-tcpip" {
+			_ = nch.Reject(ssh.UnknownChannelType, nch.ChannelType())
+			continue
+		}
+		var p struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if err := ssh.Unmarshal(nch.ExtraData(), &p); err != nil {
+			_ = nch.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		conn, err := net.Dial("tcp", net.JoinHostPort(p.Host, strconv.Itoa(int(p.Port))))
+		if err != nil {
+			_ = nch.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		ch, in, err := nch.Accept()
+		if err != nil {
+			_ = conn.Close()
+			continue
+		}
+		go ssh.DiscardRequests(in)
+		go pipeChannel(ch, conn)
+	}
+}
+
+// session serves an exec channel: it records the command and exits 0.
+func (s *testSSHServer) session(nch ssh.NewChannel) {
+	ch, in, err := nch.Accept()
+	if err != nil {
+		return
+	}
+	defer func() { _ = ch.Close() }()
+	for r := range in {
+		if r.Type != "exec" {
+			if r.WantReply {
+				_ = r.Reply(false, nil)
+			}
+			continue
+		}
+		var p struct{ Command string }
+		_ = ssh.Unmarshal(r.Payload, &p)
+		s.mu.Lock()
+		s.execs = append(s.execs, p.Command)
+		s.mu.Unlock()
+		_ = r.Reply(true, nil)
+		status := 0
+		if s.runCommands {
+			status = runLocally(p.Command, ch)
+		}
+		_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)})) //nolint:gosec // an exit status fits
+		return
+	}
+}
+
+// runLocally runs command under sh with ch as its stdio and returns its exit
+// status.
+func runLocally(command string, ch ssh.Channel) int {
+	cmd := exec.Command("sh", "-c", command) //nolint:gosec // test server runs what the code under test sends
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = ch, ch, ch.Stderr()
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		return 127
+	}
+	return 0
+}
+
+// commands returns the exec commands received so far.
+func (s *testSSHServer) commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.execs...)
+}
+
+func (s *testSSHServer) globalRequests(sc *ssh.ServerConn, reqs <-chan *ssh.Request) {
+	for r := range reqs {
+		if r.Type != "tcpip-forward" {
+			if r.WantReply {
+				_ = r.Reply(false, nil)
+			}
+			continue
+		}
+		var p struct {
+			Addr string
+			Port uint32
+		}
+		if err := ssh.Unmarshal(r.Payload, &p); err != nil {
+			_ = r.Reply(false, nil)
+			continue
+		}
+		s.mu.Lock()
+		s.forwards++
+		reject := s.rejectForward != nil && s.rejectForward(s.forwards)
+		s.mu.Unlock()
+		if reject {
+			_ = r.Reply(false, nil)
+			continue
+		}
+		ln, err := net.Listen("tcp", net.JoinHostPort(p.Addr, strconv.Itoa(int(p.Port))))
+		if err != nil {
+			_ = r.Reply(false, nil)
+			continue
+		}
+		port := uint32(ln.Addr().(*net.TCPAddr).Port) //nolint:gosec // a TCP port fits
+		_ = r.Reply(true, ssh.Marshal(struct{ Port uint32 }{port}))
+		go func() {
+			defer func() { _ = ln.Close() }()
+			go func() { _ = sc.Wait(); _ = ln.Close() }()
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				origin := conn.RemoteAddr().(*net.TCPAddr)
+				ch, in, err := sc.OpenChannel("forwarded-tcpip", ssh.Marshal(struct {
+					Addr       string
+					Port       uint32
+					OriginAddr string
+					OriginPort uint32
+				}{p.Addr, port, origin.IP.String(), uint32(origin.Port)})) //nolint:gosec // a TCP port fits
+				if err != nil {
+					_ = conn.Close()
+					continue
+				}
+				go ssh.DiscardRequests(in)
+				go pipeChannel(ch, conn)
+			}
+		}()
+	}
+}
+
+// pipeChannel bridges ch and conn, passing a half-close in either direction
+// on like sshd does.
+func pipeChannel(ch ssh.Channel, conn net.Conn) {
+	done := make(chan struct{})
+	go func() { _, _ = io.Copy(ch, conn); _ = ch.CloseWrite(); close(done) }()
+	_, _ = io.Copy(conn, ch)
+	halfCloseWrite(conn)
+	<-done
+	_ = conn.Close()
+	_ = ch.Close()
+}
+
+// echoListener accepts connections and echoes every byte back.
+func echoListener(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() { _, _ = io.Copy(c, c); _ = c.Close() }()
+		}
+	}()
+	return ln.Addr().String()
+}
@@ -0,0 +1,113 @@
+package rde
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+)
+
+// statusLogMu serializes recordStatus within the process, so goroutines
+// (say, a watch and a terminate) don't drop each other's changes.
+var statusLogMu sync.Mutex
+
+// statusLogMax caps the changes kept per session; older ones are dropped.
+const statusLogMax = 100
+
+// StatusChange is a session status this CLI has seen, and when.
+type StatusChange struct {
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// The API keeps no status history, so the CLI logs the status changes it
+// causes or sees (terminate, restore, watch) to let usage reports split a
+// session's runtime where it was terminated.
+func statusLogPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rde", "status-log.json"), nil
+}
+
+// loadStatusLog returns the logged status changes per session ID, oldest
+// first.
+func loadStatusLog() (map[string][]StatusChange, error) {
+	p, err := statusLogPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p) //nolint:gosec // path is under the CLI's config dir
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read session status log: %w", err)
+	}
+	var st struct {
+		Sessions map[string][]StatusChange `json:"sessions"`
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse session status log %s: %w", p, err)
+	}
+	return st.Sessions, nil
+}
+
+// recordStatus logs that sessionID had status at the given time. It is best
+// effort: a usage estimate isn't worth failing the command that changed the
+// session.
+func recordStatus(sessionID, status string, at time.Time) {
+	if sessionID == "" || status == "" {
+		return
+	}
+	statusLogMu.Lock()
+	defer statusLogMu.Unlock()
+	log, err := loadStatusLog()
+	if err != nil {
+		return
+	}
+	if log == nil {
+		log = make(map[string][]StatusChange)
+	}
+	changes := append(log[sessionID], StatusChange{Status: status, At: at.UTC()})
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].At.Before(changes[j].At) })
+	if len(changes) > statusLogMax {
+		changes = changes[len(changes)-statusLogMax:]
+	}
+	log[sessionID] = changes
+
+	p, err := statusLogPath()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(struct {
+		Sessions map[string][]StatusChange `json:"sessions"`
+	}{log})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return
+	}
+	// Write-then-rename so a concurrent reader never sees half a file; the
+	// temp file is this call's own, so another CLI process writing at the
+	// same time can't interleave with it.
+	tmp, err := os.CreateTemp(filepath.Dir(p), filepath.Base(p)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // best-effort cleanup if rename already moved it
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err != nil || cerr != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), p)
+}
@@ -0,0 +1,883 @@
+package rde
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/bitrise-cli/internal/config"
+)
+
+// Sync directions.
+const (
+	// SyncPush makes the session's directory match the local one.
+	SyncPush = "push"
+	// SyncPull makes the local directory match the session's.
+	SyncPull = "pull"
+	// SyncBoth carries changes made on either side since the last sync to the
+	// other, reporting files changed on both as conflicts.
+	SyncBoth = "both"
+)
+
+// DefaultSyncWatchInterval is how often SyncWatch retries a failed pass, and
+// rescans the local tree where file events are unavailable.
+const DefaultSyncWatchInterval = time.Second
+
+// SyncOptions configures Sync and SyncWatch.
+type SyncOptions struct {
+	LocalDir string
+	// RemoteDir is the directory on the session. A leading ~ is the session
+	// user's home; a relative path is taken from the home too.
+	RemoteDir string
+	// Direction is SyncPush, SyncPull, or SyncBoth; empty means SyncPush.
+	Direction string
+	// Delete removes files from the destination that the source lacks, like
+	// rsync --delete. SyncBoth always propagates deletions it can attribute
+	// to one side.
+	Delete bool
+	// DryRun computes the plan without transferring or deleting anything.
+	DryRun bool
+	// Excludes are extra patterns in .gitignore syntax, relative to the root.
+	Excludes []string
+	// NoGitignore disables reading .gitignore files; Excludes and .git still
+	// apply.
+	NoGitignore bool
+}
+
+// SyncResult reports what a sync pass did (or, for a dry run, would do).
+// Paths are slash-separated and relative to the synced directories. JSON tags
+// are part of `rde session sync --output json`.
+// The byte counts are file data before compression; a file sent as a block
+// delta counts only the data that travelled, and a dry run counts whole
+// files.
+type SyncResult struct {
+	Uploaded        []string       `json:"uploaded"`
+	Downloaded      []string       `json:"downloaded"`
+	DeletedRemote   []string       `json:"deleted_remote"`
+	DeletedLocal    []string       `json:"deleted_local"`
+	Conflicts       []SyncConflict `json:"conflicts"`
+	BytesUploaded   int64          `json:"bytes_uploaded"`
+	BytesDownloaded int64          `json:"bytes_downloaded"`
+	DryRun          bool           `json:"dry_run,omitempty"`
+}
+
+// SyncConflict is a file SyncBoth left alone because both sides changed it.
+type SyncConflict struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// Changed reports whether the pass had anything to do or report.
+func (r SyncResult) Changed() bool {
+	return len(r.Uploaded)+len(r.Downloaded)+len(r.DeletedRemote)+len(r.DeletedLocal)+len(r.Conflicts) > 0
+}
+
+// Sync reconciles opts.LocalDir with opts.RemoteDir on the session over one
+// SSH connection, transferring only files whose content differs. Each side is
+// listed with a SHA-256 per file (computed on the session with its own
+// sha256sum or shasum), the lists are compared, and the changed files travel
+// as one gzipped tar stream, except that a file of at least syncDeltaMin
+// bytes that the receiving side has an older copy of goes as a block delta
+// (see uploadDelta and downloadDelta). Files excluded by .gitignore (and .git itself)
+// are neither listed nor touched on either side. Only regular files are
+// synced; symlinks and empty directories are skipped.
+//
+// SyncBoth keeps a record of the last synced state under the CLI's config
+// directory, which is how it tells an edit on one side from an edit on the
+// other.
+func (s *Service) Sync(ctx context.Context, workspaceID, sessionID string, opts SyncOptions) (SyncResult, error) {
+	sy, err := s.newSyncer(ctx, workspaceID, sessionID, opts)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	defer sy.close()
+	local, err := sy.scanLocal()
+	if err != nil {
+		return SyncResult{}, err
+	}
+	return sy.pass(ctx, local, true)
+}
+
+// SyncWatchOptions configures SyncWatch.
+type SyncWatchOptions struct {
+	// Interval paces retries of a failed pass, and the local rescans when
+	// file events are unavailable; zero means DefaultSyncWatchInterval.
+	Interval time.Duration
+	// OnSync is called after the initial sync and after every pass that
+	// changed something.
+	OnSync func(SyncResult)
+	// OnError is called when a pass fails. Watching continues: the pass is
+	// retried over a fresh connection after Interval.
+	OnError func(error)
+	// OnPolling is called once if file events can't be used (no fsnotify
+	// support, or the OS watch limit is reached) and SyncWatch falls back
+	// to rescanning every Interval.
+	OnPolling func(error)
+}
+
+// SyncWatch runs an initial push Sync and then keeps pushing local edits
+// until ctx is cancelled, which is a clean stop (nil). Every synced
+// directory is watched with fsnotify; after a burst of file events settles
+// the tree is rescanned — a stat per file, hashing only files whose size or
+// modification time moved — and a pass runs if something changed. Without
+// file events the tree is rescanned every Interval instead.
+// Passes after the first compare against the session state the previous
+// passes produced instead of re-listing the session, so each edit costs one
+// round trip. Edits made on the session while watching are not picked up.
+func (s *Service) SyncWatch(ctx context.Context, workspaceID, sessionID string, opts SyncOptions, wopts SyncWatchOptions) error {
+	if opts.Direction != "" && opts.Direction != SyncPush {
+		return fmt.Errorf("watching only supports the %s direction", SyncPush)
+	}
+	if opts.DryRun {
+		return fmt.Errorf("watching cannot be combined with a dry run")
+	}
+	interval := wopts.Interval
+	if interval <= 0 {
+		interval = DefaultSyncWatchInterval
+	}
+	sy, err := s.newSyncer(ctx, workspaceID, sessionID, opts)
+	if err != nil {
+		return err
+	}
+	defer sy.close()
+
+	watcher, err := newDirWatcher()
+	if err != nil {
+		if wopts.OnPolling != nil {
+			wopts.OnPolling(err)
+		}
+	}
+	defer watcher.close()
+
+	var last map[string]syncFile
+	refresh := true
+	for {
+		local, err := sy.scanLocal()
+		if err == nil && watcher != nil {
+			if werr := watcher.watch(sy.dirs); werr != nil {
+				watcher.close()
+				watcher = nil
+				if wopts.OnPolling != nil {
+					wopts.OnPolling(werr)
+				}
+			}
+		}
+		switch {
+		case err != nil:
+			if wopts.OnError != nil {
+				wopts.OnError(err)
+			}
+		case last == nil || refresh || !sameSyncFiles(local, last):
+			if sy.client == nil {
+				sy.client, err = s.dialSession(ctx, workspaceID, sessionID)
+			}
+			var res SyncResult
+			if err == nil {
+				res, err = sy.pass(ctx, local, refresh)
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				// Reconnect and re-list the session on the next tick: the
+				// failed pass may have left it partly updated.
+				sy.close()
+				refresh = true
+				if wopts.OnError != nil {
+					wopts.OnError(err)
+				}
+				break
+			}
+			if (last == nil || res.Changed()) && wopts.OnSync != nil {
+				wopts.OnSync(res)
+			}
+			last, refresh = local, false
+		}
+		if !awaitSyncChange(ctx, watcher, interval, err != nil || refresh) {
+			return nil
+		}
+	}
+}
+
+// syncFile is one regular file as listed by a sync scan.
+type syncFile struct {
+	Hash    string
+	Size    int64
+	ModTime time.Time
+	Mode    fs.FileMode
+}
+
+func sameSyncFiles(a, b map[string]syncFile) bool {
+	return maps.EqualFunc(a, b, func(x, y syncFile) bool { return x.Hash == y.Hash })
+}
+
+// syncer holds the state of Sync and SyncWatch across passes.
+type syncer struct {
+	opts      SyncOptions
+	key       string
+	localRoot string
+	remoteDir string
+	client    *sshClient
+	// hashes caches local file hashes by path, reused while size and
+	// modification time are unchanged.
+	hashes map[string]syncFile
+	// remote is the session's file list as of the last pass.
+	remote map[string]string
+	// dirs are the local directories the last scan walked, for SyncWatch
+	// to watch.
+	dirs []string
+	// ignore holds the exclude rules of the last scan, nested .gitignore
+	// files included, so the session's list is filtered the same way.
+	ignore *ignoreMatcher
+}
+
+func (s *Service) newSyncer(ctx context.Context, workspaceID, sessionID string, opts SyncOptions) (*syncer, error) {
+	if s.client == nil {
+		return nil, errClient()
+	}
+	switch opts.Direction {
+	case "":
+		opts.Direction = SyncPush
+	case SyncPush, SyncPull, SyncBoth:
+	default:
+		return nil, fmt.Errorf("unknown sync direction %q (want %s, %s, or %s)", opts.Direction, SyncPush, SyncPull, SyncBoth)
+	}
+	if opts.RemoteDir == "" {
+		return nil, fmt.Errorf("remote directory is required")
+	}
+	root, err := filepath.Abs(opts.LocalDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve local directory: %w", err)
+	}
+	if fi, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("local directory: %w", err)
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("local directory %s is not a directory", root)
+	}
+	client, err := s.dialSession(ctx, workspaceID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{workspaceID, sessionID, root, opts.RemoteDir}, "\x00")))
+	return &syncer{
+		opts:      opts,
+		key:       hex.EncodeToString(sum[:8]),
+		localRoot: root,
+		remoteDir: remoteDirExpr(opts.RemoteDir),
+		client:    client,
+		hashes:    map[string]syncFile{},
+	}, nil
+}
+
+// dialSession connects to a running session over SSH, without agent
+// forwarding.
+func (s *Service) dialSession(ctx context.Context, workspaceID, sessionID string) (*sshClient, error) {
+	sess, err := s.GetSession(ctx, workspaceID, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch session: %w", err)
+	}
+	target, err := sshTargetForSession(sess)
+	if err != nil {
+		return nil, err
+	}
+	target.NoAgentForwarding = true
+	return dialSSHWithRetry(ctx, target)
+}
+
+func (sy *syncer) close() {
+	if sy.client != nil {
+		_ = sy.client.Close()
+		sy.client = nil
+	}
+}
+
+// remoteDirExpr renders dir for a remote shell: quoted, with a leading ~
+// left outside the quotes so the shell still expands it.
+func remoteDirExpr(dir string) string {
+	switch {
+	case dir == "~":
+		return `"$HOME"`
+	case strings.HasPrefix(dir, "~/"):
+		return `"$HOME"/` + shellSingleQuote(dir[2:])
+	case strings.HasPrefix(dir, "/"):
+		return shellSingleQuote(dir)
+	}
+	return `"$HOME"/` + shellSingleQuote(dir)
+}
+
+// matcher builds the exclude rules that apply before any nested .gitignore:
+// the user's patterns and the root .gitignore.
+func (sy *syncer) matcher() (*ignoreMatcher, error) {
+	m := &ignoreMatcher{}
+	if !sy.opts.NoGitignore {
+		if err := m.addFile("", filepath.Join(sy.localRoot, ".gitignore")); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range sy.opts.Excludes {
+		m.add("", p)
+	}
+	return m, nil
+}
+
+// scanLocal lists the local tree's regular files, skipping excluded paths
+// and picking up nested .gitignore files on the way down.
+func (sy *syncer) scanLocal() (map[string]syncFile, error) {
+	m, err := sy.matcher()
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]syncFile{}
+	var dirs []string
+	err = filepath.WalkDir(sy.localRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == sy.localRoot {
+			dirs = append(dirs, p)
+			return nil
+		}
+		rel, err := filepath.Rel(sy.localRoot, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if m.matchOne(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			dirs = append(dirs, p)
+			if !sy.opts.NoGitignore {
+				return m.addFile(rel, filepath.Join(p, ".gitignore"))
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		f := syncFile{Size: fi.Size(), ModTime: fi.ModTime(), Mode: fi.Mode()}
+		if c, ok := sy.hashes[rel]; ok && c.Size == f.Size && c.ModTime.Equal(f.ModTime) {
+			f.Hash = c.Hash
+		} else if f.Hash, err = hashFile(p); err != nil {
+			return err
+		}
+		out[rel] = f
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", sy.localRoot, err)
+	}
+	sy.hashes, sy.dirs, sy.ignore = out, dirs, m
+	return out, nil
+}
+
+func hashFile(p string) (string, error) {
+	f, err := os.Open(p) //nolint:gosec // a file inside the tree being synced
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() //nolint:errcheck // read-only
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// remoteListCmd lists every regular file under the remote directory as
+// sha256sum output, pruning .git and the given directory name patterns. A
+// missing directory lists as empty.
+func remoteListCmd(dir string, prune []string) string {
+	names := []string{"-name " + syncAlwaysExcluded}
+	for _, p := range prune {
+		names = append(names, `\( -type d -name `+shellSingleQuote(p)+` \)`)
+	}
+	return "cd " + dir + " 2>/dev/null || exit 0\n" +
+		"if command -v sha256sum >/dev/null 2>&1; then h=sha256sum; else h='shasum -a 256'; fi\n" +
+		`find . \( ` + strings.Join(names, " -o ") + ` \) -prune -o -type f -print0 | xargs -0 $h`
+}
+
+// parseRemoteList parses sha256sum/shasum output into path → hash. Names
+// the tools had to escape (containing a newline or backslash) are skipped.
+func parseRemoteList(out []byte) map[string]string {
+	files := map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		if len(line) < 68 || line[0] == '\\' || line[64] != ' ' {
+			continue
+		}
+		name := strings.TrimPrefix(line[66:], "./")
+		if line[65] == '*' || line[65] == ' ' {
+			if name != "-" && name != "" {
+				files[name] = line[:64]
+			}
+		}
+	}
+	return files
+}
+
+// fetchRemote lists the session side, filtered through the exclude rules of
+// the last local scan (root and nested .gitignore files alike) plus those of
+// .gitignore files only the session has.
+func (sy *syncer) fetchRemote(ctx context.Context) (map[string]string, error) {
+	m := &ignoreMatcher{}
+	if sy.ignore != nil {
+		m.rules = slices.Clone(sy.ignore.rules)
+	} else {
+		var err error
+		if m, err = sy.matcher(); err != nil {
+			return nil, err
+		}
+	}
+	var out bytes.Buffer
+	if err := sy.client.runPipe(ctx, remoteListCmd(sy.remoteDir, m.pruneNames()), nil, &out); err != nil {
+		return nil, fmt.Errorf("list %s on the session: %w", sy.opts.RemoteDir, err)
+	}
+	files := parseRemoteList(out.Bytes())
+	if !sy.opts.NoGitignore {
+		if err := sy.addRemoteIgnores(ctx, m, files); err != nil {
+			return nil, err
+		}
+	}
+	for p := range files {
+		if m.excluded(p) {
+			delete(files, p)
+		}
+	}
+	return files, nil
+}
+
+// addRemoteIgnores adds to m the rules of the .gitignore files in the
+// session's list that have no local copy (the local ones are already in m),
+// read in one round trip, shallowest first.
+func (sy *syncer) addRemoteIgnores(ctx context.Context, m *ignoreMatcher, files map[string]string) error {
+	var paths []string
+	for p := range files {
+		if path.Base(p) != ".gitignore" || m.excluded(p) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(sy.localRoot, filepath.FromSlash(p))); err == nil {
+			continue
+		}
+		paths = append(paths, p)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	slices.SortFunc(paths, func(a, b string) int {
+		if d := strings.Count(a, "/") - strings.Count(b, "/"); d != 0 {
+			return d
+		}
+		return strings.Compare(a, b)
+	})
+	var out bytes.Buffer
+	cmd := "cd " + sy.remoteDir + " && tar -cf - --null -T -"
+	if err := sy.client.runPipe(ctx, cmd, strings.NewReader(strings.Join(paths, "\x00")+"\x00"), &out); err != nil {
+		return fmt.Errorf("read .gitignore files on the session: %w", err)
+	}
+	tr := tar.NewReader(&out)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read .gitignore files on the session: %w", err)
+		}
+		base := path.Dir(strings.TrimPrefix(hdr.Name, "./"))
+		if base == "." {
+			base = ""
+		}
+		sc := bufio.NewScanner(tr)
+		for sc.Scan() {
+			m.add(base, sc.Text())
+		}
+		if err := sc.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// syncPlan is what a pass will do.
+type syncPlan struct {
+	upload, download, deleteRemote, deleteLocal []string
+	conflicts                                   []SyncConflict
+}
+
+// planSync compares the two sides. base is the state both sides had after
+// the previous SyncBoth pass (nil when there was none); other directions
+// ignore it.
+func planSync(direction string, deleteExtra bool, local map[string]syncFile, remote, base map[string]string) syncPlan {
+	var p syncPlan
+	paths := map[string]bool{}
+	for k := range local {
+		paths[k] = true
+	}
+	for k := range remote {
+		paths[k] = true
+	}
+	if direction == SyncBoth {
+		for k := range base {
+			paths[k] = true
+		}
+	}
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		l, r := local[path].Hash, remote[path]
+		if l == r {
+			continue
+		}
+		switch direction {
+		case SyncPush:
+			if l != "" {
+				p.upload = append(p.upload, path)
+			} else if deleteExtra {
+				p.deleteRemote = append(p.deleteRemote, path)
+			}
+		case SyncPull:
+			if r != "" {
+				p.download = append(p.download, path)
+			} else if deleteExtra {
+				p.deleteLocal = append(p.deleteLocal, path)
+			}
+		case SyncBoth:
+			b, known := base[path]
+			switch {
+			case known && l == b && r == "":
+				p.deleteLocal = append(p.deleteLocal, path)
+			case known && l == b:
+				p.download = append(p.download, path)
+			case known && r == b && l == "":
+				p.deleteRemote = append(p.deleteRemote, path)
+			case known && r == b:
+				p.upload = append(p.upload, path)
+			case !known && l == "":
+				p.download = append(p.download, path)
+			case !known && r == "":
+				p.upload = append(p.upload, path)
+			default:
+				p.conflicts = append(p.conflicts, SyncConflict{Path: path, Reason: conflictReason(known, l, r)})
+			}
+		}
+	}
+	return p
+}
+
+func conflictReason(known bool, local, remote string) string {
+	switch {
+	case !known:
+		return "exists on both sides with different content"
+	case local == "":
+		return "deleted locally, changed on the session"
+	case remote == "":
+		return "changed locally, deleted on the session"
+	}
+	return "changed on both sides"
+}
+
+// pass runs one sync: plan against the session's file list (re-listed when
+// refresh is set or none is known yet) and carry the plan out.
+func (sy *syncer) pass(ctx context.Context, local map[string]syncFile, refresh bool) (SyncResult, error) {
+	if refresh || sy.remote == nil {
+		remote, err := sy.fetchRemote(ctx)
+		if err != nil {
+			return SyncResult{}, err
+		}
+		sy.remote = remote
+	}
+	var base map[string]string
+	if sy.opts.Direction == SyncBoth {
+		var err error
+		if base, err = loadSyncBase(sy.key); err != nil {
+			return SyncResult{}, err
+		}
+	}
+	plan := planSync(sy.opts.Direction, sy.opts.Delete, local, sy.remote, base)
+	res := SyncResult{
+		Uploaded:      nonNil(plan.upload),
+		Downloaded:    nonNil(plan.download),
+		DeletedRemote: nonNil(plan.deleteRemote),
+		DeletedLocal:  nonNil(plan.deleteLocal),
+		Conflicts:     plan.conflicts,
+		DryRun:        sy.opts.DryRun,
+	}
+	if res.Conflicts == nil {
+		res.Conflicts = []SyncConflict{}
+	}
+	for _, p := range plan.upload {
+		res.BytesUploaded += local[p].Size
+	}
+	if sy.opts.DryRun {
+		return res, nil
+	}
+
+	sent, err := sy.upload(ctx, plan.upload, local)
+	res.BytesUploaded = sent
+	if err != nil {
+		return res, err
+	}
+	for _, p := range plan.upload {
+		sy.remote[p] = local[p].Hash
+	}
+	if err := sy.deleteRemote(ctx, plan.deleteRemote); err != nil {
+		return res, err
+	}
+	for _, p := range plan.deleteRemote {
+		delete(sy.remote, p)
+	}
+	n, err := sy.download(ctx, plan.download, local)
+	res.BytesDownloaded = n
+	if err != nil {
+		return res, err
+	}
+	for _, p := range plan.deleteLocal {
+		if err := os.Remove(filepath.Join(sy.localRoot, filepath.FromSlash(p))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return res, fmt.Errorf("delete %s: %w", p, err)
+		}
+	}
+
+	if sy.opts.Direction == SyncBoth {
+		next := maps.Clone(sy.remote)
+		for _, c := range plan.conflicts {
+			if h, ok := base[c.Path]; ok {
+				next[c.Path] = h
+			} else {
+				delete(next, c.Path)
+			}
+		}
+		if err := saveSyncBase(sy.key, next); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// upload sends paths to the remote directory and returns the bytes sent
+// (before compression). Large files the session already has an older copy
+// of go as a block delta; the rest, and any delta that fails, stream as one
+// gzipped tar.
+func (sy *syncer) upload(ctx context.Context, paths []string, local map[string]syncFile) (int64, error) {
+	var sent int64
+	var delta, whole []string
+	for _, p := range paths {
+		if _, ok := sy.remote[p]; ok && local[p].Size >= syncDeltaMin {
+			delta = append(delta, p)
+		} else {
+			whole = append(whole, p)
+		}
+	}
+	if len(delta) > 0 {
+		n, rest, err := sy.uploadDelta(ctx, delta, local)
+		sent += n
+		if err != nil {
+			return sent, err
+		}
+		whole = append(whole, rest...)
+		slices.Sort(whole)
+	}
+	if len(whole) == 0 {
+		return sent, nil
+	}
+	pr, pw := io.Pipe()
+	go func() { _ = pw.CloseWithError(writeSyncTar(pw, sy.localRoot, whole)) }()
+	cmd := "mkdir -p " + sy.remoteDir + " && tar -xzf - -C " + sy.remoteDir
+	if err := sy.client.runPipe(ctx, cmd, pr, io.Discard); err != nil {
+		_ = pr.CloseWithError(err)
+		return sent, fmt.Errorf("upload to the session: %w", err)
+	}
+	for _, p := range whole {
+		sent += local[p].Size
+	}
+	return sent, nil
+}
+
+func writeSyncTar(w io.Writer, root string, paths []string) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, p := range paths {
+		if err := addSyncTarFile(tw, root, p); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func addSyncTarFile(tw *tar.Writer, root, rel string) error {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(rel))) //nolint:gosec // a file found by the scan of root
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read-only
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr, err := tar.FileInfoHeader(fi, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = rel
+	// Ownership is the session user's; local uid/gid mean nothing there.
+	hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, f, hdr.Size)
+	return err
+}
+
+func (sy *syncer) deleteRemote(ctx context.Context, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	list := strings.Join(paths, "\x00") + "\x00"
+	cmd := "cd " + sy.remoteDir + " && xargs -0 rm -f --"
+	if err := sy.client.runPipe(ctx, cmd, strings.NewReader(list), io.Discard); err != nil {
+		return fmt.Errorf("delete on the session: %w", err)
+	}
+	return nil
+}
+
+// download fetches paths from the remote directory into the local root and
+// returns the bytes received (before compression). Large files with an
+// older local copy are fetched block by block; the rest, and any that fail
+// that way, stream as one gzipped tar.
+func (sy *syncer) download(ctx context.Context, paths []string, local map[string]syncFile) (int64, error) {
+	var fetched int64
+	var delta []string
+	whole := paths[:0:0]
+	for _, p := range paths {
+		if f, ok := local[p]; ok && f.Size >= syncDeltaMin {
+			delta = append(delta, p)
+		} else {
+			whole = append(whole, p)
+		}
+	}
+	if len(delta) > 0 {
+		n, rest, err := sy.downloadDelta(ctx, delta, sy.remote)
+		fetched += n
+		if err != nil {
+			return fetched, err
+		}
+		whole = append(whole, rest...)
+	}
+	if len(whole) == 0 {
+		return fetched, nil
+	}
+	n, err := sy.downloadWhole(ctx, whole)
+	return fetched + n, err
+}
+
+// downloadWhole streams paths from the remote directory as a gzipped tar and
+// extracts it into the local root, returning the bytes written.
+func (sy *syncer) downloadWhole(ctx context.Context, paths []string) (int64, error) {
+	pr, pw := io.Pipe()
+	extracted := make(chan error, 1)
+	go func() {
+		err := extractTarGz(pr, sy.localRoot)
+		_ = pr.CloseWithError(err)
+		extracted <- err
+	}()
+	list := strings.Join(paths, "\x00") + "\x00"
+	cmd := "cd " + sy.remoteDir + " && tar -czf - --null -T -"
+	runErr := sy.client.runPipe(ctx, cmd, strings.NewReader(list), pw)
+	_ = pw.CloseWithError(runErr)
+	if err := <-extracted; err != nil && runErr == nil {
+		return 0, fmt.Errorf("extract download: %w", err)
+	}
+	if runErr != nil {
+		return 0, fmt.Errorf("download from the session: %w", runErr)
+	}
+	var n int64
+	for _, p := range paths {
+		if fi, err := os.Stat(filepath.Join(sy.localRoot, filepath.FromSlash(p))); err == nil {
+			n += fi.Size()
+		}
+	}
+	return n, nil
+}
+
+// syncBasePath is where SyncBoth records the last synced state of one
+// (session, local dir, remote dir) pair.
+func syncBasePath(key string) (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rde", "sync", key+".json"), nil
+}
+
+func loadSyncBase(key string) (map[string]string, error) {
+	p, err := syncBasePath(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p) //nolint:gosec // path is under the CLI's config dir
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read sync state: %w", err)
+	}
+	var st struct {
+		Files map[string]string `json:"files"`
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse sync state %s: %w", p, err)
+	}
+	return st.Files, nil
+}
+
+func saveSyncBase(key string, files map[string]string) error {
+	p, err := syncBasePath(key)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(struct {
+		Files map[string]string `json:"files"`
+	}{files})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return fmt.Errorf("write sync state: %w", err)
+	}
+	if err := os.WriteFile(p, data, 0o600); err != nil {
+		return fmt.Errorf("write sync state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,451 @@
+package rde
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	m := &ignoreMatcher{}
+	for _, line := range []string{"# comment", "*.log", "!keep.log", "build/", "/root-only", "docs/**/*.tmp", "\\#hash"} {
+		m.add("", line)
+	}
+	m.add("sub", "local.txt")
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"a.log", true},
+		{"deep/dir/a.log", true},
+		{"keep.log", false},
+		{"build/out.o", true},
+		{"src/build/out.o", true},
+		{"build", false}, // a file named build: the pattern is directory-only
+		{"root-only", true},
+		{"sub/root-only", false},
+		{"docs/a/b/x.tmp", true},
+		{"docs/x.tmp", true},
+		{"x.tmp", false},
+		{"#hash", true},
+		{"sub/local.txt", true},
+		{"local.txt", false},
+		{".git/config", true},
+		{"main.go", false},
+	} {
+		if got := m.excluded(tc.path); got != tc.want {
+			t.Errorf("excluded(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+	if got := m.pruneNames(); got != nil {
+		t.Errorf("pruneNames with a negation = %v, want none", got)
+	}
+	m2 := &ignoreMatcher{}
+	m2.add("", "node_modules/")
+	m2.add("", "*.o")
+	if got := m2.pruneNames(); !reflect.DeepEqual(got, []string{"node_modules"}) {
+		t.Errorf("pruneNames = %v, want [node_modules]", got)
+	}
+}
+
+func TestParseRemoteList(t *testing.T) {
+	h := strings.Repeat("a", 64)
+	out := h + "  ./src/main.go\n" + h + " *./bin/tool\n\\" + h + "  ./odd\\nname\n" + h + "  -\n"
+	got := parseRemoteList([]byte(out))
+	want := map[string]string{"src/main.go": h, "bin/tool": h}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRemoteList = %v, want %v", got, want)
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	local := map[string]syncFile{"same": {Hash: "1"}, "edited": {Hash: "2"}, "new": {Hash: "3"}}
+	remote := map[string]string{"same": "1", "edited": "x", "extra": "4"}
+
+	push := planSync(SyncPush, true, local, remote, nil)
+	if !reflect.DeepEqual(push.upload, []string{"edited", "new"}) || !reflect.DeepEqual(push.deleteRemote, []string{"extra"}) {
+		t.Errorf("push plan = %+v", push)
+	}
+	if keep := planSync(SyncPush, false, local, remote, nil); keep.deleteRemote != nil {
+		t.Errorf("push without delete should keep extras, got %+v", keep)
+	}
+	pull := planSync(SyncPull, true, local, remote, nil)
+	if !reflect.DeepEqual(pull.download, []string{"edited", "extra"}) || !reflect.DeepEqual(pull.deleteLocal, []string{"new"}) {
+		t.Errorf("pull plan = %+v", pull)
+	}
+
+	base := map[string]string{"a": "1", "b": "1", "c": "1", "d": "1", "e": "1", "f": "1"}
+	local = map[string]syncFile{"a": {Hash: "2"}, "b": {Hash: "1"}, "c": {Hash: "2"}, "e": {Hash: "1"}, "g": {Hash: "9"}, "h": {Hash: "7"}}
+	remote = map[string]string{"a": "1", "b": "2", "c": "3", "d": "1", "f": "2", "h": "8"}
+	both := planSync(SyncBoth, false, local, remote, base)
+	if !reflect.DeepEqual(both.upload, []string{"a", "g"}) ||
+		!reflect.DeepEqual(both.download, []string{"b"}) ||
+		!reflect.DeepEqual(both.deleteRemote, []string{"d"}) ||
+		!reflect.DeepEqual(both.deleteLocal, []string{"e"}) {
+		t.Errorf("both plan = %+v", both)
+	}
+	want := []SyncConflict{
+		{"c", "changed on both sides"},
+		{"f", "deleted locally, changed on the session"},
+		{"h", "exists on both sides with different content"},
+	}
+	if !reflect.DeepEqual(both.conflicts, want) {
+		t.Errorf("conflicts = %+v, want %+v", both.conflicts, want)
+	}
+}
+
+func TestRemoteDirExpr(t *testing.T) {
+	for in, want := range map[string]string{
+		"~":             `"$HOME"`,
+		"~/src/my app":  `"$HOME"/'src/my app'`,
+		"/Users/it's":   `'/Users/it'\''s'`,
+		"relative/path": `"$HOME"/'relative/path'`,
+	} {
+		if got := remoteDirExpr(in); got != want {
+			t.Errorf("remoteDirExpr(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestCksumCRC(t *testing.T) {
+	// What POSIX cksum prints for "123456789".
+	if got := cksumFinish(crcRaw([]byte("123456789")), 9); got != 930766865 {
+		t.Errorf("cksum = %d, want 930766865", got)
+	}
+	data := randomBytes(100)
+	roller := newCRCRoller(10)
+	crc := crcRaw(data[:10])
+	for i := 10; i < len(data); i++ {
+		crc = roller.roll(crc, data[i-10], data[i])
+		if want := crcRaw(data[i-9 : i+1]); crc != want {
+			t.Fatalf("rolled CRC at %d = %x, want %x", i, crc, want)
+		}
+	}
+}
+
+func TestComputeDelta_ReusesShiftedBlocks(t *testing.T) {
+	const bs = 1024
+	old := randomBytes(64 * bs)
+	// Insert a few bytes near the top and overwrite some in the middle.
+	edited := append(append(append([]byte{}, old[:100]...), "inserted"...), old[100:]...)
+	copy(edited[30*bs:], "overwritten")
+
+	sigs := &blockSigs{weak: map[uint32][]int{}}
+	for i := 0; i*bs < len(old); i++ {
+		block := old[i*bs : (i+1)*bs]
+		sum := sha256.Sum256(block)
+		sigs.hashs = append(sigs.hashs, hex.EncodeToString(sum[:]))
+		weak := cksumFinish(crcRaw(block), bs)
+		sigs.weak[weak] = append(sigs.weak[weak], i)
+	}
+
+	var rebuilt bytes.Buffer
+	var literal int
+	err := computeDelta(bytes.NewReader(edited), bs, sigs,
+		func(data []byte) error {
+			literal += len(data)
+			rebuilt.Write(data)
+			return nil
+		},
+		func(block int) error {
+			rebuilt.Write(old[block*bs : (block+1)*bs])
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("computeDelta: %v", err)
+	}
+	if !bytes.Equal(rebuilt.Bytes(), edited) {
+		t.Fatal("rebuilt file differs from the edited one")
+	}
+	if literal > 4*bs {
+		t.Errorf("sent %d literal bytes, want about the blocks around the two edits", literal)
+	}
+}
+
+// syncFixture is a Service whose "session" runs commands on this machine, so
+// a sync's remote directory is just another local directory.
+func syncFixture(t *testing.T) (*Service, string, string) {
+	t.Helper()
+	for _, tool := range []string{"sh", "tar", "find", "xargs", "sha256sum"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available: %v", tool, err)
+		}
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	srv := newTestSSHServer(t)
+	srv.runCommands = true
+	return srv.sessionAPI(), t.TempDir(), t.TempDir()
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for p, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func randomBytes(n int) []byte {
+	r := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // test data
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(r.Uint32())
+	}
+	return b
+}
+
+func readFile(t *testing.T, root, p string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(p)))
+	if err != nil {
+		return "<missing>"
+	}
+	return string(data)
+}
+
+func TestSync_PushTransfersOnlyChangesAndHonorsGitignore(t *testing.T) {
+	svc, local, remote := syncFixture(t)
+	writeFiles(t, local, map[string]string{
+		".gitignore":      "build/\n*.log\n",
+		"main.go":         "package main",
+		"pkg/util.go":     "package pkg",
+		"build/app":       "binary",
+		"debug.log":       "noise",
+		"pkg/.gitignore":  "gen.go\n",
+		"pkg/gen.go":      "generated",
+		".git/HEAD":       "ref: main",
+		"docs/readme.txt": "hi",
+	})
+	writeFiles(t, remote, map[string]string{"stale.txt": "old", "build/cache": "remote build output"})
+	ctx := context.Background()
+	opts := SyncOptions{LocalDir: local, RemoteDir: remote, Delete: true}
+
+	res, err := svc.Sync(ctx, "ws-1", "s1", opts)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if want := []string{".gitignore", "docs/readme.txt", "main.go", "pkg/.gitignore", "pkg/util.go"}; !reflect.DeepEqual(res.Uploaded, want) {
+		t.Errorf("uploaded = %v, want %v", res.Uploaded, want)
+	}
+	if !reflect.DeepEqual(res.DeletedRemote, []string{"stale.txt"}) {
+		t.Errorf("deleted = %v, want only stale.txt (build/ is excluded, so left alone)", res.DeletedRemote)
+	}
+	if got := readFile(t, remote, "pkg/util.go"); got != "package pkg" {
+		t.Errorf("remote pkg/util.go = %q", got)
+	}
+	if got := readFile(t, remote, "build/cache"); got != "remote build output" {
+		t.Errorf("excluded remote file was touched: %q", got)
+	}
+	for _, p := range []string{"debug.log", "pkg/gen.go", ".git/HEAD", "stale.txt"} {
+		if got := readFile(t, remote, p); got != "<missing>" {
+			t.Errorf("remote %s = %q, want it absent", p, got)
+		}
+	}
+
+	writeFiles(t, local, map[string]string{"main.go": "package main // edited"})
+	res, err = svc.Sync(ctx, "ws-1", "s1", opts)
+	if err != nil {
+		t.Fatalf("Sync (again): %v", err)
+	}
+	if !reflect.DeepEqual(res.Uploaded, []string{"main.go"}) || len(res.DeletedRemote) != 0 {
+		t.Errorf("second sync = %+v, want just main.go", res)
+	}
+}
+
+func TestSync_PushDeleteKeepsFilesUnderNestedIgnores(t *testing.T) {
+	svc, local, remote := syncFixture(t)
+	writeFiles(t, local, map[string]string{
+		"app/.gitignore": "node_modules/\n",
+		"app/index.js":   "main()",
+	})
+	writeFiles(t, remote, map[string]string{"app/node_modules/x.js": "installed on the session", "stale.txt": "old"})
+
+	res, err := svc.Sync(context.Background(), "ws-1", "s1", SyncOptions{LocalDir: local, RemoteDir: remote, Delete: true})
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !reflect.DeepEqual(res.DeletedRemote, []string{"stale.txt"}) {
+		t.Errorf("deleted = %v, want only stale.txt (app/node_modules/ is ignored by app/.gitignore)", res.DeletedRemote)
+	}
+	if got := readFile(t, remote, "app/node_modules/x.js"); got != "installed on the session" {
+		t.Errorf("remote app/node_modules/x.js = %q, want it kept", got)
+	}
+}
+
+func TestSync_PullHonorsSessionOnlyNestedIgnores(t *testing.T) {
+	svc, local, remote := syncFixture(t)
+	writeFiles(t, remote, map[string]string{
+		"lib/.gitignore": "*.tmp\n",
+		"lib/a.go":       "package lib",
+		"lib/a.tmp":      "scratch",
+	})
+
+	res, err := svc.Sync(context.Background(), "ws-1", "s1", SyncOptions{LocalDir: local, RemoteDir: remote, Direction: SyncPull})
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if want := []string{"lib/.gitignore", "lib/a.go"}; !reflect.DeepEqual(res.Downloaded, want) {
+		t.Errorf("downloaded = %v, want %v", res.Downloaded, want)
+	}
+	if got := readFile(t, local, "lib/a.tmp"); got != "<missing>" {
+		t.Errorf("local lib/a.tmp = %q, want it absent", got)
+	}
+}
+
+func TestSync_LargeFilesTravelAsDelta(t *testing.T) {
+	if _, err := exec.LookPath("split"); err != nil {
+		t.Skipf("split not available: %v", err)
+	}
+	svc, local, remote := syncFixture(t)
+	data := randomBytes(3 << 20)
+	writeFiles(t, local, map[string]string{"big.bin": string(data)})
+	ctx := context.Background()
+	if _, err := svc.Sync(ctx, "ws-1", "s1", SyncOptions{LocalDir: local, RemoteDir: remote}); err != nil {
+		t.Fatalf("initial Sync: %v", err)
+	}
+
+	// An insertion shifts everything after it; the rolling checksum still
+	// finds the blocks.
+	pushed := append(append(append([]byte{}, data[:1<<20]...), "a few new bytes"...), data[1<<20:]...)
+	writeFiles(t, local, map[string]string{"big.bin": string(pushed)})
+	res, err := svc.Sync(ctx, "ws-1", "s1", SyncOptions{LocalDir: local, RemoteDir: remote})
+	if err != nil {
+		t.Fatalf("push Sync: %v", err)
+	}
+	if got := readFile(t, remote, "big.bin"); got != string(pushed) {
+		t.Fatal("remote big.bin doesn't match the local one")
+	}
+	if !reflect.DeepEqual(res.Uploaded, []string{"big.bin"}) || res.BytesUploaded > 64<<10 {
+		t.Errorf("push = %+v, want big.bin as a delta of at most 64 KiB", res)
+	}
+
+	// An edit in place on the session comes back as the changed block.
+	pulled := bytes.Clone(pushed)
+	copy(pulled[2<<20:], "edited on the session")
+	writeFiles(t, remote, map[string]string{"big.bin": string(pulled)})
+	res, err = svc.Sync(ctx, "ws-1", "s1", SyncOptions{LocalDir: local, RemoteDir: remote, Direction: SyncPull})
+	if err != nil {
+		t.Fatalf("pull Sync: %v", err)
+	}
+	if got := readFile(t, local, "big.bin"); got != string(pulled) {
+		t.Fatal("local big.bin doesn't match the remote one")
+	}
+	if !reflect.DeepEqual(res.Downloaded, []string{"big.bin"}) || res.BytesDownloaded > 64<<10 {
+		t.Errorf("pull = %+v, want big.bin as a delta of at most 64 KiB", res)
+	}
+}
+
+func TestSync_BothPropagatesEachSideAndReportsConflicts(t *testing.T) {
+	svc, local, remote := syncFixture(t)
+	writeFiles(t, local, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+	ctx := context.Background()
+	opts := SyncOptions{LocalDir: local, RemoteDir: remote, Direction: SyncBoth}
+	if _, err := svc.Sync(ctx, "ws-1", "s1", opts); err != nil {
+		t.Fatalf("initial Sync: %v", err)
+	}
+
+	writeFiles(t, local, map[string]string{"a.txt": "a local", "c.txt": "c local"})
+	writeFiles(t, remote, map[string]string{"b.txt": "b remote", "c.txt": "c remote", "new.txt": "from session"})
+	res, err := svc.Sync(ctx, "ws-1", "s1", opts)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !reflect.DeepEqual(res.Uploaded, []string{"a.txt"}) || !reflect.DeepEqual(res.Downloaded, []string{"b.txt", "new.txt"}) {
+		t.Errorf("result = %+v", res)
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0].Path != "c.txt" {
+		t.Errorf("conflicts = %+v, want c.txt", res.Conflicts)
+	}
+	if got := readFile(t, remote, "a.txt"); got != "a local" {
+		t.Errorf("remote a.txt = %q", got)
+	}
+	if got := readFile(t, local, "new.txt"); got != "from session" {
+		t.Errorf("local new.txt = %q", got)
+	}
+	if local, remote := readFile(t, local, "c.txt"), readFile(t, remote, "c.txt"); local != "c local" || remote != "c remote" {
+		t.Errorf("conflicting file changed: local %q, remote %q", local, remote)
+	}
+
+	// Resolving the conflict locally makes the next pass push it.
+	writeFiles(t, remote, map[string]string{"c.txt": "c local"})
+	res, err = svc.Sync(ctx, "ws-1", "s1", opts)
+	if err != nil {
+		t.Fatalf("Sync (resolved): %v", err)
+	}
+	if res.Changed() {
+		t.Errorf("after resolving, nothing should be left to do: %+v", res)
+	}
+}
+
+func TestSyncWatch_PushesEdits(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		interval time.Duration
+		noEvents bool
+	}{
+		// An hour-long interval: only a file event can trigger the pass.
+		{"file events", time.Hour, false},
+		{"rescan fallback", 10 * time.Millisecond, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.noEvents {
+				prev := newDirWatcher
+				newDirWatcher = func() (*dirWatcher, error) { return nil, errors.New("inotify: too many watches") }
+				t.Cleanup(func() { newDirWatcher = prev })
+			}
+			svc, local, remote := syncFixture(t)
+			writeFiles(t, local, map[string]string{"a.txt": "v1"})
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+			defer cancel()
+
+			synced := make(chan SyncResult, 10)
+			errs := make(chan error, 1)
+			var polling error
+			go func() {
+				errs <- svc.SyncWatch(ctx, "ws-1", "s1", SyncOptions{LocalDir: local, RemoteDir: remote}, SyncWatchOptions{
+					Interval:  tt.interval,
+					OnSync:    func(r SyncResult) { synced <- r },
+					OnError:   func(err error) { t.Errorf("watch pass: %v", err) },
+					OnPolling: func(err error) { polling = err },
+				})
+			}()
+			if r := <-synced; !reflect.DeepEqual(r.Uploaded, []string{"a.txt"}) {
+				t.Fatalf("initial pass = %+v", r)
+			}
+			// A new directory is picked up and watched too.
+			writeFiles(t, local, map[string]string{"sub/b.txt": "new"})
+			if r := <-synced; !reflect.DeepEqual(r.Uploaded, []string{"sub/b.txt"}) {
+				t.Errorf("edit pass = %+v, want just sub/b.txt", r)
+			}
+			writeFiles(t, local, map[string]string{"sub/b.txt": "newer"})
+			if r := <-synced; !reflect.DeepEqual(r.Uploaded, []string{"sub/b.txt"}) {
+				t.Errorf("nested edit pass = %+v, want just sub/b.txt", r)
+			}
+			if got := readFile(t, remote, "sub/b.txt"); got != "newer" {
+				t.Errorf("remote sub/b.txt = %q", got)
+			}
+			cancel()
+			if err := <-errs; err != nil {
+				t.Errorf("SyncWatch: %v", err)
+			}
+			if (polling != nil) != tt.noEvents {
+				t.Errorf("OnPolling err = %v, want a call only without file events", polling)
+			}
+		})
+	}
+}
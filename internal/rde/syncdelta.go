@@ -0,0 +1,541 @@
+package rde
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Files at least this large that the other side already has (in an older
+// version) are transferred as a block delta instead of whole.
+const syncDeltaMin = 1 << 20
+
+// deltaLiteralMax caps one run of literal data in an upload delta, so a
+// largely rewritten file doesn't have to be buffered whole.
+const deltaLiteralMax = 1 << 20
+
+// deltaBlockSize picks the block size for a file of n bytes: about 2048
+// blocks, but no smaller than 16 KiB, since the session forks a process per
+// block to checksum it.
+func deltaBlockSize(n int64) int64 {
+	const minBlock, maxBlock = 16 << 10, 1 << 20
+	bs := (n/2048 + 1023) &^ 1023
+	return min(max(bs, minBlock), maxBlock)
+}
+
+// The upload delta is rsync's: the session sends a weak and a strong
+// checksum for each block of its copy, the local side rolls the weak
+// checksum over its file byte by byte, and only the bytes that match no
+// block travel. The session has no rsync, so the weak checksum is the CRC
+// that POSIX cksum prints (which GNU split --filter can run per block), and
+// the strong one is SHA-256.
+
+// cksumTable is the CRC-32 table (polynomial 0x04C11DB7, MSB first) of POSIX
+// cksum.
+var cksumTable = func() (t [256]uint32) {
+	for i := range t {
+		c := uint32(i) << 24
+		for range 8 {
+			if c&0x80000000 != 0 {
+				c = c<<1 ^ 0x04C11DB7
+			} else {
+				c <<= 1
+			}
+		}
+		t[i] = c
+	}
+	return t
+}()
+
+func crcUpdate(crc uint32, b byte) uint32 {
+	return crc<<8 ^ cksumTable[byte(crc>>24)^b]
+}
+
+// crcRaw is the CRC of data without cksum's length suffix and final
+// inversion, which is what rolls.
+func crcRaw(data []byte) uint32 {
+	var crc uint32
+	for _, b := range data {
+		crc = crcUpdate(crc, b)
+	}
+	return crc
+}
+
+// cksumFinish turns the raw CRC of n bytes into what cksum prints for them.
+func cksumFinish(crc uint32, n int64) uint32 {
+	for ; n > 0; n >>= 8 {
+		crc = crcUpdate(crc, byte(n))
+	}
+	return ^crc
+}
+
+// crcRoller slides a raw CRC along a window of fixed size.
+type crcRoller struct {
+	// out[a] is the raw CRC of byte a followed by a window of zeros: XORing
+	// it in drops a from the front of the window.
+	out [256]uint32
+}
+
+func newCRCRoller(window int64) *crcRoller {
+	r := &crcRoller{}
+	for a := range r.out {
+		crc := crcUpdate(0, byte(a))
+		for range window {
+			crc = crcUpdate(crc, 0)
+		}
+		r.out[a] = crc
+	}
+	return r
+}
+
+// roll moves the window one byte: out leaves at the front, in enters at the
+// back.
+func (r *crcRoller) roll(crc uint32, out, in byte) uint32 {
+	return crcUpdate(crc, in) ^ r.out[out]
+}
+
+// blockSigs indexes the full-size blocks of the session's copy of a file.
+type blockSigs struct {
+	weak  map[uint32][]int
+	hashs []string
+}
+
+// remoteSigsCmd prints, for the file rel under dir, the cksum line of each
+// block, a "--" line, and the sha256sum line of each block.
+func remoteSigsCmd(dir, rel string, bs int64) string {
+	q := shellSingleQuote(rel)
+	n := strconv.FormatInt(bs, 10)
+	return "cd " + dir + " && split -b " + n + " --filter=cksum -- " + q +
+		" && echo -- && split -b " + n + " --filter=sha256sum -- " + q
+}
+
+// parseBlockSigs parses remoteSigsCmd's output.
+func parseBlockSigs(out []byte, bs int64) (*blockSigs, error) {
+	weakPart, strongPart, ok := strings.Cut(string(out), "--\n")
+	if !ok {
+		return nil, errors.New("no block checksums")
+	}
+	weak := strings.Fields(weakPart)
+	strong := strings.Fields(strongPart)
+	if len(weak)%2 != 0 || len(strong)%2 != 0 || len(weak) != len(strong) {
+		return nil, errors.New("malformed block checksums")
+	}
+	sigs := &blockSigs{weak: map[uint32][]int{}}
+	for i := 0; i < len(weak); i += 2 {
+		crc, err1 := strconv.ParseUint(weak[i], 10, 32)
+		n, err2 := strconv.ParseInt(weak[i+1], 10, 64)
+		if err1 != nil || err2 != nil {
+			return nil, errors.New("malformed block checksums")
+		}
+		sigs.hashs = append(sigs.hashs, strong[i])
+		if n == bs {
+			sigs.weak[uint32(crc)] = append(sigs.weak[uint32(crc)], i/2)
+		}
+	}
+	return sigs, nil
+}
+
+// match returns the session block whose content is window, if any.
+func (s *blockSigs) match(weak uint32, window []byte) (int, bool) {
+	cands := s.weak[weak]
+	if len(cands) == 0 {
+		return 0, false
+	}
+	sum := sha256.Sum256(window)
+	strong := hex.EncodeToString(sum[:])
+	for _, i := range cands {
+		if s.hashs[i] == strong {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// computeDelta reads the new version of a file from r and calls lit for
+// runs of bytes the session doesn't have and cp for each session block
+// that can be reused, in file order.
+func computeDelta(r io.Reader, bs int64, sigs *blockSigs, lit func([]byte) error, cp func(block int) error) error {
+	br := bufio.NewReaderSize(r, 1<<16)
+	roller := newCRCRoller(bs)
+	buf := make([]byte, 0, int(bs)+deltaLiteralMax)
+
+	// fill reads a fresh window after a match; it reports false at the end
+	// of the file, with the short tail left in buf.
+	fill := func() (bool, error) {
+		buf = buf[:bs]
+		n, err := io.ReadFull(br, buf)
+		buf = buf[:n]
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	flush := func() error {
+		if len(buf) == 0 {
+			return nil
+		}
+		return lit(buf)
+	}
+
+	full, err := fill()
+	if err != nil {
+		return err
+	}
+	if !full {
+		return flush()
+	}
+	// buf[:w] is pending literal data and buf[w:] the window.
+	w := 0
+	crc := crcRaw(buf)
+	for {
+		if block, ok := sigs.match(cksumFinish(crc, bs), buf[w:]); ok {
+			if w > 0 {
+				if err := lit(buf[:w]); err != nil {
+					return err
+				}
+			}
+			if err := cp(block); err != nil {
+				return err
+			}
+			w = 0
+			if full, err = fill(); err != nil {
+				return err
+			}
+			if !full {
+				return flush()
+			}
+			crc = crcRaw(buf)
+			continue
+		}
+		b, err := br.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return flush()
+		}
+		if err != nil {
+			return err
+		}
+		crc = roller.roll(crc, buf[w], b)
+		buf = append(buf, b)
+		w++
+		if w >= deltaLiteralMax {
+			if err := lit(buf[:w]); err != nil {
+				return err
+			}
+			buf = append(buf[:0], buf[w:]...)
+			w = 0
+		}
+	}
+}
+
+// uploadDelta sends each of paths, which the session has an older copy of,
+// as a block delta: one round trip per file for the session's checksums,
+// then a single tar of the literal data plus a script that rebuilds the
+// files from it and their old copies. A rebuilt file replaces the old copy
+// only if its SHA-256 matches. It returns the bytes of literal data sent and
+// the paths that have to be sent whole instead.
+func (sy *syncer) uploadDelta(ctx context.Context, paths []string, local map[string]syncFile) (int64, []string, error) {
+	type deltaFile struct {
+		rel  string
+		bs   int64
+		sigs *blockSigs
+	}
+	var whole []string
+	var planned []deltaFile
+	for _, p := range paths {
+		bs := deltaBlockSize(local[p].Size)
+		var out bytes.Buffer
+		if err := sy.client.runPipe(ctx, remoteSigsCmd(sy.remoteDir, p, bs), nil, &out); err != nil {
+			// No GNU split on the session, or the file went away: send it whole.
+			whole = append(whole, p)
+			continue
+		}
+		sigs, err := parseBlockSigs(out.Bytes(), bs)
+		if err != nil {
+			whole = append(whole, p)
+			continue
+		}
+		planned = append(planned, deltaFile{p, bs, sigs})
+	}
+	if len(planned) == 0 {
+		return 0, whole, nil
+	}
+
+	var sent int64
+	pr, pw := io.Pipe()
+	wrote := make(chan error, 1)
+	go func() {
+		gw := gzip.NewWriter(pw)
+		tw := tar.NewWriter(gw)
+		var script strings.Builder
+		entry := 0
+		addLiteral := func(data []byte) (string, error) {
+			name := strconv.Itoa(entry)
+			entry++
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+				return "", err
+			}
+			_, err := tw.Write(data)
+			sent += int64(len(data))
+			return name, err
+		}
+		write := func() error {
+			for _, df := range planned {
+				parts, err := deltaParts(sy.localRoot, df.rel, df.bs, df.sigs, addLiteral)
+				if err != nil {
+					return err
+				}
+				script.WriteString(applyDeltaScript(df.rel, df.bs, local[df.rel], parts))
+			}
+			s := script.String()
+			if err := tw.WriteHeader(&tar.Header{Name: "apply", Mode: 0o600, Size: int64(len(s)), Typeflag: tar.TypeReg}); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(tw, s); err != nil {
+				return err
+			}
+			if err := tw.Close(); err != nil {
+				return err
+			}
+			return gw.Close()
+		}
+		err := write()
+		_ = pw.CloseWithError(err)
+		wrote <- err
+	}()
+	var failed bytes.Buffer
+	cmd := `d=$(mktemp -d) || exit 1
+trap 'rm -rf "$d"' EXIT
+tar -xzf - -C "$d" && cd ` + sy.remoteDir + ` && sh "$d/apply" "$d"`
+	runErr := sy.client.runPipe(ctx, cmd, pr, &failed)
+	_ = pr.CloseWithError(errors.Join(runErr, io.ErrClosedPipe))
+	if err := <-wrote; err != nil && runErr != nil {
+		return sent, nil, fmt.Errorf("upload delta to the session: %w", errors.Join(err, runErr))
+	}
+	if runErr != nil {
+		return sent, nil, fmt.Errorf("upload delta to the session: %w", runErr)
+	}
+	for _, p := range strings.Split(failed.String(), "\x00") {
+		if p != "" {
+			whole = append(whole, p)
+		}
+	}
+	return sent, whole, nil
+}
+
+// deltaPart is a step of rebuilding a file: count session blocks from
+// block on, or (with count 0) the literal tar entry named lit.
+type deltaPart struct {
+	block, count int
+	lit          string
+}
+
+// deltaParts diffs the local file rel against the session's block sums,
+// storing literal data through addLiteral, and returns the rebuild steps
+// with runs of consecutive blocks merged.
+func deltaParts(root, rel string, bs int64, sigs *blockSigs, addLiteral func([]byte) (string, error)) ([]deltaPart, error) {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(rel))) //nolint:gosec // a file found by the scan of root
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck // read-only
+	var parts []deltaPart
+	err = computeDelta(f, bs, sigs,
+		func(data []byte) error {
+			name, err := addLiteral(data)
+			parts = append(parts, deltaPart{lit: name})
+			return err
+		},
+		func(block int) error {
+			if n := len(parts); n > 0 && parts[n-1].count > 0 && parts[n-1].block+parts[n-1].count == block {
+				parts[n-1].count++
+			} else {
+				parts = append(parts, deltaPart{block: block, count: 1})
+			}
+			return nil
+		})
+	return parts, err
+}
+
+// applyDeltaScript renders the shell that rebuilds rel on the session from
+// parts into a temporary file next to it, and moves it into place if the
+// result has the expected hash; otherwise it prints rel, NUL-terminated, to
+// have it sent whole. $1 is the directory the literal entries were
+// extracted to.
+func applyDeltaScript(rel string, bs int64, f syncFile, parts []deltaPart) string {
+	q := shellSingleQuote(rel)
+	tmp := shellSingleQuote(rel + ".rde-sync")
+	steps := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p.count == 0 {
+			steps = append(steps, `cat "$1"/`+p.lit)
+		} else {
+			steps = append(steps, fmt.Sprintf("dd if=%s bs=%d skip=%d count=%d 2>/dev/null", q, bs, p.block, p.count))
+		}
+	}
+	if len(steps) == 0 {
+		steps = append(steps, ":")
+	}
+	return fmt.Sprintf("if { %s; } > %s && [ \"$(sha256sum < %s | cut -c1-64)\" = %s ] && chmod %o %s && mv -f %s %s; then :; else rm -f %s; printf '%%s\\0' %s; fi\n",
+		strings.Join(steps, " && "), tmp, tmp, f.Hash, f.Mode.Perm(), tmp, tmp, q, tmp, q)
+}
+
+// The download delta is simpler: the session can checksum its blocks but
+// not roll a checksum, so only blocks at the same offset are compared. That
+// covers edits in place and appends (logs, databases, build outputs); an
+// insertion near the top of a file makes every later block differ.
+
+// downloadDelta fetches each of paths, which exist locally in an older
+// version, block by block: the session sends the SHA-256 of each of its
+// blocks and then just the blocks that differ from the local ones. A file
+// whose result doesn't hash to want[path] is left alone and returned to be
+// downloaded whole. It returns the bytes fetched.
+func (sy *syncer) downloadDelta(ctx context.Context, paths []string, want map[string]string) (int64, []string, error) {
+	var fetched int64
+	var whole []string
+	for _, p := range paths {
+		n, ok, err := sy.downloadFileDelta(ctx, p, want[p])
+		fetched += n
+		if err != nil {
+			return fetched, nil, err
+		}
+		if !ok {
+			whole = append(whole, p)
+		}
+	}
+	return fetched, whole, nil
+}
+
+func (sy *syncer) downloadFileDelta(ctx context.Context, rel, want string) (int64, bool, error) {
+	target := filepath.Join(sy.localRoot, filepath.FromSlash(rel))
+	old, err := os.Open(target) //nolint:gosec // a file found by the scan of root
+	if err != nil {
+		return 0, false, nil
+	}
+	defer old.Close() //nolint:errcheck // read-only
+	fi, err := old.Stat()
+	if err != nil {
+		return 0, false, nil
+	}
+	bs := deltaBlockSize(fi.Size())
+	q := shellSingleQuote(rel)
+	var out bytes.Buffer
+	if err := sy.client.runPipe(ctx, "cd "+sy.remoteDir+" && split -b "+strconv.FormatInt(bs, 10)+" --filter=sha256sum -- "+q, nil, &out); err != nil {
+		return 0, false, nil
+	}
+	fields := strings.Fields(out.String())
+	if len(fields)%2 != 0 {
+		return 0, false, nil
+	}
+	var remote []string
+	for i := 0; i < len(fields); i += 2 {
+		remote = append(remote, fields[i])
+	}
+
+	// Compare with the local blocks at the same offsets; runs of differing
+	// blocks are fetched with one dd each.
+	same := make([]bool, len(remote))
+	var fetch []string
+	buf := make([]byte, bs)
+	for i := range remote {
+		n, err := io.ReadFull(old, buf)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, false, err
+		}
+		if n > 0 {
+			sum := sha256.Sum256(buf[:n])
+			same[i] = hex.EncodeToString(sum[:]) == remote[i]
+		}
+	}
+	for i := 0; i < len(remote); {
+		if same[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(remote) && !same[j] {
+			j++
+		}
+		fetch = append(fetch, fmt.Sprintf("dd if=%s bs=%d skip=%d count=%d 2>/dev/null", q, bs, i, j-i))
+		i = j
+	}
+	if len(fetch) == 0 {
+		// Every block matches, yet the hashes differed: the session's copy
+		// must have changed since it was listed.
+		return 0, false, nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".rde-sync-*")
+	if err != nil {
+		return 0, false, err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone after the rename
+	defer tmp.Close()           //nolint:errcheck // closed explicitly on success
+
+	pr, pw := io.Pipe()
+	got := make(chan error, 1)
+	var fetched int64
+	go func() {
+		err := func() error {
+			h := sha256.New()
+			w := io.MultiWriter(tmp, h)
+			for i := range remote {
+				if same[i] {
+					if _, err := io.Copy(w, io.NewSectionReader(old, int64(i)*bs, min(bs, fi.Size()-int64(i)*bs))); err != nil {
+						return err
+					}
+					continue
+				}
+				// A block from the session is bs bytes, or less at the end.
+				n, err := io.CopyN(w, pr, bs)
+				fetched += n
+				if err != nil && !errors.Is(err, io.EOF) {
+					return err
+				}
+			}
+			if hex.EncodeToString(h.Sum(nil)) != want {
+				return errDeltaMismatch
+			}
+			return nil
+		}()
+		_, _ = io.Copy(io.Discard, pr)
+		got <- err
+	}()
+	cmd := "cd " + sy.remoteDir + " && " + strings.Join(fetch, " && ")
+	runErr := sy.client.runPipe(ctx, cmd, nil, pw)
+	_ = pw.CloseWithError(runErr)
+	err = <-got
+	if runErr != nil {
+		return fetched, false, fmt.Errorf("download delta from the session: %w", runErr)
+	}
+	if errors.Is(err, errDeltaMismatch) {
+		return fetched, false, nil
+	}
+	if err != nil {
+		return fetched, false, err
+	}
+	if err := tmp.Chmod(fi.Mode().Perm()); err != nil {
+		return fetched, false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return fetched, false, err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fetched, false, err
+	}
+	return fetched, true, nil
+}
+
+// errDeltaMismatch marks a rebuilt file whose hash isn't the expected one
+// (say, the session's copy changed mid-transfer).
+var errDeltaMismatch = errors.New("rebuilt file doesn't match")
@@ -0,0 +1,185 @@
+package rde
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// syncAlwaysExcluded is excluded from every sync: the repository metadata is
+// local state, and each side keeps its own.
+const syncAlwaysExcluded = ".git"
+
+// ignoreRule is one compiled .gitignore pattern. base is the slash-separated
+// directory of the .gitignore it came from ("" for the root); the pattern
+// only applies below it.
+type ignoreRule struct {
+	raw      string
+	base     string
+	re       *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ignoreMatcher implements the subset of gitignore(5) sync needs: comments,
+// negation, directory-only and anchored patterns, and *, ?, [...] and **
+// wildcards. As in git, the last matching rule wins, and nothing below an
+// excluded directory can be re-included.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// add compiles one pattern line read from the .gitignore in base.
+func (m *ignoreMatcher) add(base, line string) {
+	line = strings.TrimRight(line, "\r")
+	if t := strings.TrimRight(line, " "); !strings.HasSuffix(t, `\`) {
+		line = t
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+	r := ignoreRule{raw: line, base: base}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return
+	}
+	re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return // a malformed [class]; git ignores such patterns too
+	}
+	r.re = re
+	m.rules = append(m.rules, r)
+}
+
+// addFile reads the .gitignore at file, whose rules apply below base. A
+// missing file adds nothing.
+func (m *ignoreMatcher) addFile(base, file string) error {
+	f, err := os.Open(file) //nolint:gosec // a .gitignore inside the tree being synced
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read-only
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		m.add(base, sc.Text())
+	}
+	return sc.Err()
+}
+
+// matchOne reports whether rel itself (not its ancestors) is excluded.
+func (m *ignoreMatcher) matchOne(rel string, isDir bool) bool {
+	if path.Base(rel) == syncAlwaysExcluded {
+		return true
+	}
+	excluded := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		p := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			p = rel[len(r.base)+1:]
+		}
+		if !r.anchored {
+			p = path.Base(p)
+		}
+		if r.re.MatchString(p) {
+			excluded = !r.negate
+		}
+	}
+	return excluded
+}
+
+// excluded reports whether the file at rel is excluded, directly or through
+// an excluded ancestor directory. Used for paths that weren't found by a
+// pruning walk, such as the session's file list.
+func (m *ignoreMatcher) excluded(rel string) bool {
+	for i := range len(rel) {
+		if rel[i] == '/' && m.matchOne(rel[:i], true) {
+			return true
+		}
+	}
+	return m.matchOne(rel, false)
+}
+
+// pruneNames returns directory name patterns the session-side file listing
+// can skip outright: root-level, directory-only, unanchored patterns. With
+// any negation present nothing is pruned beyond .git, to keep the remote
+// listing a superset of what the matcher would keep.
+func (m *ignoreMatcher) pruneNames() []string {
+	var out []string
+	for _, r := range m.rules {
+		if r.negate {
+			return nil
+		}
+	}
+	for _, r := range m.rules {
+		if r.base == "" && r.dirOnly && !r.anchored && !strings.Contains(r.raw, "**") {
+			out = append(out, strings.TrimSuffix(r.raw, "/"))
+		}
+	}
+	return out
+}
+
+// globToRegexp translates a gitignore glob to a regexp body: * and ? stop at
+// a slash, ** crosses them, and [...] classes pass through.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
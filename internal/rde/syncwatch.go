@@ -0,0 +1,107 @@
+package rde
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// syncWatchSettle is how long SyncWatch waits after a file event for the
+// burst to end (an editor's save, a checkout) before it rescans.
+const syncWatchSettle = 100 * time.Millisecond
+
+// newDirWatcher is replaced by tests to simulate a platform without file
+// events.
+var newDirWatcher = func() (*dirWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &dirWatcher{w: w, dirs: map[string]bool{}}, nil
+}
+
+// dirWatcher reports changes under the synced tree through fsnotify. Watches
+// don't recurse, so every directory a scan walked is watched on its own, and
+// the set follows the tree as directories come and go.
+type dirWatcher struct {
+	w    *fsnotify.Watcher
+	dirs map[string]bool
+}
+
+// watch makes the watched set exactly dirs.
+func (d *dirWatcher) watch(dirs []string) error {
+	want := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		want[dir] = true
+		if d.dirs[dir] {
+			continue
+		}
+		if err := d.w.Add(dir); err != nil {
+			return err
+		}
+		d.dirs[dir] = true
+	}
+	for dir := range d.dirs {
+		if !want[dir] {
+			// A removed directory drops its watch on its own; Remove then
+			// fails, which is fine.
+			_ = d.w.Remove(dir)
+			delete(d.dirs, dir)
+		}
+	}
+	return nil
+}
+
+func (d *dirWatcher) close() {
+	if d != nil {
+		_ = d.w.Close()
+	}
+}
+
+// awaitSyncChange blocks until the local tree may have changed and reports
+// false once ctx is done. With a watcher that is a file event followed by a
+// quiet spell of syncWatchSettle; a watcher error (such as a dropped event
+// queue) counts as a change. Without one, or when poll asks to retry a
+// failed pass, it is also the end of interval.
+func awaitSyncChange(ctx context.Context, d *dirWatcher, interval time.Duration, poll bool) bool {
+	var (
+		events <-chan fsnotify.Event
+		errs   <-chan error
+		tick   <-chan time.Time
+	)
+	if d != nil {
+		events, errs = d.w.Events, d.w.Errors
+	}
+	if d == nil || poll {
+		timer := time.NewTimer(interval)
+		defer timer.Stop()
+		tick = timer.C
+	}
+	for changed := false; !changed; {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-tick:
+			return true
+		case <-errs:
+			changed = true
+		case ev := <-events:
+			// Attribute-only changes (Spotlight, chmod) don't alter content.
+			changed = ev.Op != fsnotify.Chmod
+		}
+	}
+	settle := time.NewTimer(syncWatchSettle)
+	defer settle.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-events:
+		case <-errs:
+		case <-settle.C:
+			return true
+		}
+		settle.Reset(syncWatchSettle)
+	}
+}
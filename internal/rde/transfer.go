@@ -2,29 +2,167 @@ package rde
 
 import (
 	"archive/tar"
-	"bytes"
 	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
 )
 
-// gcsHTTPTimeout caps each cloud-storage transfer leg (PUT during upload,
-// GET during download). Sessions can hold large workspaces, so 10 minutes
-// matches what the MCP uses.
-const gcsHTTPTimeout = 10 * time.Minute
+// Transfer phases reported through TransferProgress.
+const (
+	PhaseArchiving   = "archiving"
+	PhaseUploading   = "uploading"
+	PhaseDownloading = "downloading"
+	PhaseExtracting  = "extracting"
+)
+
+// progressInterval throttles TransferOptions.Progress calls.
+const progressInterval = 200 * time.Millisecond
+
+// TransferProgress is a snapshot of one phase of an upload or download.
+type TransferProgress struct {
+	Phase string
+	// Done is the bytes processed so far; Total is the phase's size, or 0
+	// while it isn't known (archiving).
+	Done, Total int64
+	Elapsed     time.Duration
+	// Final is set on the last report of a phase.
+	Final bool
+}
+
+// Rate is the average throughput so far, in bytes per second.
+func (p TransferProgress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Done) / p.Elapsed.Seconds()
+}
+
+// ETA estimates the time left at the average rate; 0 when unknown.
+func (p TransferProgress) ETA() time.Duration {
+	rate := p.Rate()
+	if p.Total <= 0 || rate <= 0 || p.Done >= p.Total {
+		return 0
+	}
+	return time.Duration(float64(p.Total-p.Done) / rate * float64(time.Second))
+}
+
+// TransferOptions configures UploadFile and DownloadFile.
+type TransferOptions struct {
+	// Progress, when non-nil, is called as bytes move (throttled) and once
+	// with Final set at the end of each phase.
+	Progress func(TransferProgress)
+}
+
+// meter counts the bytes of one transfer phase and reports them.
+type meter struct {
+	mu     sync.Mutex
+	phase  string
+	total  int64
+	done   int64
+	start  time.Time
+	last   time.Time
+	report func(TransferProgress)
+	// kick, when non-nil, is called on every movement (the idle watchdog).
+	kick func()
+}
+
+func newMeter(phase string, total int64, report func(TransferProgress)) *meter {
+	now := time.Now()
+	return &meter{phase: phase, total: total, start: now, last: now, report: report}
+}
+
+func (m *meter) add(n int64) {
+	m.mu.Lock()
+	m.done += n
+	kick := m.kick
+	var p *TransferProgress
+	if m.report != nil && time.Since(m.last) >= progressInterval {
+		m.last = time.Now()
+		snap := m.snapshotLocked(false)
+		p = &snap
+	}
+	m.mu.Unlock()
+	if kick != nil {
+		kick()
+	}
+	if p != nil {
+		m.report(*p)
+	}
+}
 
-// UploadFile uploads a local file or directory to a session: tars the
-// source, gzips it, PUTs it to the signed URL the backend returns, then
-// calls complete-file-upload to trigger extraction at destFolder.
-func (s *Service) UploadFile(ctx context.Context, workspaceID, sessionID, sourcePath, destFolder string) error {
+// set moves the count to n, for a retry or resume that restarts from there.
+func (m *meter) set(n int64) {
+	m.mu.Lock()
+	m.done = n
+	m.mu.Unlock()
+}
+
+// setTotal records the phase size once it is known.
+func (m *meter) setTotal(n int64) {
+	m.mu.Lock()
+	m.total = n
+	m.mu.Unlock()
+}
+
+func (m *meter) setKick(kick func()) {
+	m.mu.Lock()
+	m.kick = kick
+	m.mu.Unlock()
+}
+
+func (m *meter) finish() {
+	if m.report == nil {
+		return
+	}
+	m.mu.Lock()
+	p := m.snapshotLocked(true)
+	m.mu.Unlock()
+	m.report(p)
+}
+
+func (m *meter) snapshotLocked(final bool) TransferProgress {
+	return TransferProgress{Phase: m.phase, Done: m.done, Total: m.total, Elapsed: time.Since(m.start), Final: final}
+}
+
+func (m *meter) Write(p []byte) (int, error) {
+	m.add(int64(len(p)))
+	return len(p), nil
+}
+
+// meteredReader counts what is read through it.
+type meteredReader struct {
+	r io.Reader
+	m *meter
+}
+
+func (r meteredReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.m.add(int64(n))
+	return n, err
+}
+
+// UploadFile uploads a local file or directory to a session: tars and
+// gzips the source, PUTs it to the signed URL the backend returns, then calls
+// complete-file-upload to trigger extraction at destFolder.
+//
+// Memory use is constant regardless of size: the archive is streamed to a
+// spool file on disk under the CLI's config directory (hashed on the way),
+// then streamed from it to storage. The spool gives the PUT a known length
+// and lets a failed leg retry — or, for a resumable storage session URL,
+// resume from the last byte storage acknowledged. It needs up to the
+// source's size in free disk space, which is checked before archiving. The stored object's checksum is verified against the local
+// one when storage reports it. Symlinks, permission bits, and modification
+// times travel in the archive.
+func (s *Service) UploadFile(ctx context.Context, workspaceID, sessionID, sourcePath, destFolder string, opts TransferOptions) error {
 	if s.client == nil {
 		return errClient()
 	}
@@ -34,7 +172,11 @@ func (s *Service) UploadFile(ctx context.Context, workspaceID, sessionID, source
 	if destFolder == "" {
 		return fmt.Errorf("destination folder is required")
 	}
-	if _, err := os.Stat(sourcePath); err != nil {
+	if _, err := os.Lstat(sourcePath); err != nil {
+		return fmt.Errorf("stat source: %w", err)
+	}
+	need, err := archiveSizeEstimate(sourcePath)
+	if err != nil {
 		return fmt.Errorf("stat source: %w", err)
 	}
 
@@ -45,14 +187,24 @@ func (s *Service) UploadFile(ctx context.Context, workspaceID, sessionID, source
 		return fmt.Errorf("start file upload: %w", err)
 	}
 
-	archive, err := createTarGz(sourcePath)
+	spool, err := createSpool("upload-*.tar.gz", need)
 	if err != nil {
 		return fmt.Errorf("create archive: %w", err)
 	}
+	defer func() { _ = spool.Close(); _ = os.Remove(spool.Name()) }()
+	sums := newChecksums()
+	archiving := newMeter(PhaseArchiving, 0, opts.Progress)
+	if err := writeTarGz(io.MultiWriter(spool, sums, archiving), sourcePath); err != nil {
+		return fmt.Errorf("create archive: %w", spoolErr(spool, err))
+	}
+	archiving.finish()
+	size := archiving.done
 
-	if err := putToSignedURL(ctx, start.SignedURL, archive); err != nil {
+	uploading := newMeter(PhaseUploading, size, opts.Progress)
+	if err := putToSignedURL(ctx, start.SignedURL, spool, size, sums, uploading); err != nil {
 		return fmt.Errorf("upload to cloud storage: %w", err)
 	}
+	uploading.finish()
 
 	if err := s.client.SessionCompleteFileUpload(ctx, workspaceID, sessionID, rdeapi.CompleteFileUploadRequest{
 		UploadID:          start.UploadID,
@@ -66,7 +218,14 @@ func (s *Service) UploadFile(ctx context.Context, workspaceID, sessionID, source
 // DownloadFile downloads remote sourcePath from the session into localDest.
 // When onlyContents is true and the remote path is a directory, only the
 // directory's contents are extracted (not the directory itself).
-func (s *Service) DownloadFile(ctx context.Context, workspaceID, sessionID, sourcePath, localDest string, onlyContents bool) error {
+//
+// The archive is streamed to a spool file on disk under the CLI's config
+// directory — resuming with a range request when the connection drops
+// mid-way — and checked against the checksum storage reports before
+// anything is extracted, so a corrupt download never half-overwrites
+// localDest. That takes the archive's size in free disk space on top of the
+// extracted files.
+func (s *Service) DownloadFile(ctx context.Context, workspaceID, sessionID, sourcePath, localDest string, onlyContents bool, opts TransferOptions) error {
 	if s.client == nil {
 		return errClient()
 	}
@@ -82,28 +241,37 @@ func (s *Service) DownloadFile(ctx context.Context, workspaceID, sessionID, sour
 		return fmt.Errorf("request download: %w", err)
 	}
 
-	archive, err := getFromSignedURL(ctx, resp.SignedURL)
+	spool, err := createSpool("download-*.tar.gz", 0)
 	if err != nil {
 		return fmt.Errorf("download from cloud storage: %w", err)
 	}
+	defer func() { _ = spool.Close(); _ = os.Remove(spool.Name()) }()
+	downloading := newMeter(PhaseDownloading, 0, opts.Progress)
+	size, err := getFromSignedURL(ctx, resp.SignedURL, spool, downloading)
+	if err != nil {
+		return fmt.Errorf("download from cloud storage: %w", spoolErr(spool, err))
+	}
+	downloading.finish()
 
-	if err := extractTarGz(archive, localDest); err != nil {
+	extracting := newMeter(PhaseExtracting, size, opts.Progress)
+	if err := extractTarGz(meteredReader{io.NewSectionReader(spool, 0, size), extracting}, localDest); err != nil {
 		return fmt.Errorf("extract archive: %w", err)
 	}
+	extracting.finish()
 	return nil
 }
 
-// createTarGz walks sourcePath and returns a gzipped tar archive of its
-// contents. A directory becomes an archive of its tree (relative paths);
-// a single file becomes an archive containing just that file.
-func createTarGz(sourcePath string) ([]byte, error) {
-	var buf bytes.Buffer
-	gw := gzip.NewWriter(&buf)
+// writeTarGz writes a gzipped tar archive of sourcePath to w as it walks it.
+// A directory becomes an archive of its tree (relative paths); a single file
+// becomes an archive containing just that file. Symlinks are archived as
+// links, not followed.
+func writeTarGz(w io.Writer, sourcePath string) error {
+	gw := gzip.NewWriter(w)
 	tw := tar.NewWriter(gw)
 
-	info, err := os.Stat(sourcePath)
+	info, err := os.Lstat(sourcePath)
 	if err != nil {
-		return nil, fmt.Errorf("stat source: %w", err)
+		return fmt.Errorf("stat source: %w", err)
 	}
 
 	baseDir := filepath.Dir(sourcePath)
@@ -112,11 +280,17 @@ func createTarGz(sourcePath string) ([]byte, error) {
 	}
 
 	addEntry := func(path string, fi os.FileInfo, name string) error {
-		header, err := tar.FileInfoHeader(fi, "")
+		var link string
+		if fi.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return fmt.Errorf("read link: %w", err)
+			}
+		}
+		header, err := tar.FileInfoHeader(fi, link)
 		if err != nil {
 			return fmt.Errorf("file info header: %w", err)
 		}
-		header.Name = name
+		header.Name = filepath.ToSlash(name)
 		if err := tw.WriteHeader(header); err != nil {
 			return fmt.Errorf("write header: %w", err)
 		}
@@ -128,7 +302,9 @@ func createTarGz(sourcePath string) ([]byte, error) {
 			return fmt.Errorf("open file: %w", err)
 		}
 		defer f.Close() //nolint:errcheck // copy error takes precedence
-		if _, err := io.Copy(tw, f); err != nil {
+		// CopyN: a file growing while it's archived must not overrun the
+		// size already written in its header.
+		if _, err := io.CopyN(tw, f, header.Size); err != nil {
 			return fmt.Errorf("copy file: %w", err)
 		}
 		return nil
@@ -150,81 +326,56 @@ func createTarGz(sourcePath string) ([]byte, error) {
 			return addEntry(path, fi, rel)
 		})
 		if walkErr != nil {
-			return nil, fmt.Errorf("walk directory: %w", walkErr)
+			return fmt.Errorf("walk directory: %w", walkErr)
 		}
 	} else {
 		if err := addEntry(sourcePath, info, filepath.Base(sourcePath)); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	if err := tw.Close(); err != nil {
-		return nil, fmt.Errorf("close tar: %w", err)
+		return fmt.Errorf("close tar: %w", err)
 	}
 	if err := gw.Close(); err != nil {
-		return nil, fmt.Errorf("close gzip: %w", err)
-	}
-	return buf.Bytes(), nil
-}
-
-func putToSignedURL(ctx context.Context, signedURL string, data []byte) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, signedURL, bytes.NewReader(data))
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/gzip")
-
-	client := http.Client{Timeout: gcsHTTPTimeout}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("execute request: %w", err)
-	}
-	defer resp.Body.Close() //nolint:errcheck // body fully consumed below; close error is non-actionable
-
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("upload failed (status %d): %s", resp.StatusCode, string(body))
+		return fmt.Errorf("close gzip: %w", err)
 	}
 	return nil
 }
 
-func getFromSignedURL(ctx context.Context, signedURL string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, signedURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-	client := http.Client{Timeout: gcsHTTPTimeout}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
-	}
-	defer resp.Body.Close() //nolint:errcheck // body fully consumed below; close error is non-actionable
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("download failed (status %d): %s", resp.StatusCode, string(body))
-	}
-	return io.ReadAll(resp.Body)
-}
-
-// extractTarGz extracts a gzipped tar archive into destDir. Symlinks and
-// other non-regular non-dir entries are skipped silently. The slashlash
-// rejection prevents zip-slip — entry paths that escape destDir abort the
-// whole extraction (no partial recovery).
-func extractTarGz(data []byte, destDir string) error {
+// extractTarGz extracts a gzipped tar archive read from r into destDir as it
+// arrives. Regular files, directories, and symlinks are restored with their
+// permission bits (setuid/setgid/sticky dropped) and modification times;
+// other entry types are skipped. Entry paths and symlink targets that would
+// escape destDir abort the whole extraction (no partial recovery) — the
+// zip-slip guard.
+func extractTarGz(r io.Reader, destDir string) error {
 	if err := os.MkdirAll(destDir, 0o750); err != nil {
 		return fmt.Errorf("create destination: %w", err)
 	}
 	absDest, err := filepath.Abs(destDir)
+	if err == nil {
+		absDest, err = filepath.EvalSymlinks(absDest)
+	}
 	if err != nil {
 		return fmt.Errorf("resolve destination: %w", err)
 	}
 
-	gr, err := gzip.NewReader(bytes.NewReader(data))
+	gr, err := gzip.NewReader(r)
 	if err != nil {
 		return fmt.Errorf("open gzip: %w", err)
 	}
 	defer gr.Close() //nolint:errcheck // closing a reader, fine to ignore
 
+	// Directory modes and times are applied last: a read-only directory
+	// must still receive its entries, and writing them bumps its mtime.
+	type dirMeta struct {
+		path  string
+		mode  os.FileMode
+		mtime time.Time
+	}
+	var dirs []dirMeta
+
 	tr := tar.NewReader(gr)
 	for {
 		header, err := tr.Next()
@@ -236,37 +387,78 @@ func extractTarGz(data []byte, destDir string) error {
 		}
 
 		target := filepath.Join(absDest, header.Name) //nolint:gosec // zip-slip guarded immediately below
-		// Zip-slip guard: refuse entries whose joined path escapes destDir.
-		if rel, relErr := filepath.Rel(absDest, target); relErr != nil || rel == ".." || filepath.IsAbs(rel) || (len(rel) >= 3 && rel[:3] == ".."+string(filepath.Separator)) {
+		if !withinDir(absDest, target) {
 			return fmt.Errorf("archive entry %q would escape destination", header.Name)
 		}
+		// Mode comes from a trusted in-process tar header parse; mask to the
+		// standard 9 permission bits before casting to satisfy gosec G115
+		// (int64 → FileMode/uint32) without losing the rwxrwxrwx bits we
+		// actually care about.
+		mode := os.FileMode(header.Mode & 0o777) //nolint:gosec // masked to 9 perm bits
 
 		switch header.Typeflag {
 		case tar.TypeDir:
 			if err := os.MkdirAll(target, 0o750); err != nil {
 				return fmt.Errorf("create dir: %w", err)
 			}
+			dirs = append(dirs, dirMeta{target, mode, header.ModTime})
 		case tar.TypeReg:
 			if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
 				return fmt.Errorf("create parent dir: %w", err)
 			}
-			// Mode comes from a trusted in-process tar header parse; mask
-			// to the standard 9 permission bits before casting to satisfy
-			// gosec G115 (int64 → FileMode/uint32) without losing the
-			// rwxrwxrwx bits we actually care about.
-			mode := os.FileMode(header.Mode & 0o777) //nolint:gosec // masked to 9 perm bits
 			if err := writeTarFile(tr, target, mode); err != nil {
 				return err
 			}
+			_ = os.Chtimes(target, header.ModTime, header.ModTime)
+		case tar.TypeSymlink:
+			// A link may only point inside destDir, so no later entry can be
+			// written through it to somewhere else. The target is resolved
+			// from the link's real parent: an earlier link in its path moves
+			// where a relative target lands.
+			if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+				return fmt.Errorf("create parent dir: %w", err)
+			}
+			parent, err := filepath.EvalSymlinks(filepath.Dir(target))
+			if err != nil {
+				return fmt.Errorf("resolve parent dir: %w", err)
+			}
+			if filepath.IsAbs(header.Linkname) || !withinDir(absDest, filepath.Join(parent, header.Linkname)) {
+				return fmt.Errorf("archive symlink %q → %q would escape destination", header.Name, header.Linkname)
+			}
+			if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("replace %s: %w", header.Name, err)
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return fmt.Errorf("create symlink: %w", err)
+			}
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].mode|0o700); err != nil { //nolint:gosec // owner keeps access so the tree stays usable
+			return fmt.Errorf("chmod: %w", err)
 		}
+		_ = os.Chtimes(dirs[i].path, dirs[i].mtime, dirs[i].mtime)
 	}
 	return nil
 }
 
+// withinDir reports whether path is dir or below it. Both must be absolute
+// and clean.
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !filepath.IsAbs(rel) && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // writeTarFile copies the current tar reader entry into a regular file at
 // target with the given mode. Split out of extractTarGz so the defer in
-// the loop body doesn't leak file descriptors across many entries.
+// the loop body doesn't leak file descriptors across many entries. An
+// existing symlink at target is replaced, not written through.
 func writeTarFile(tr *tar.Reader, target string, mode os.FileMode) error {
+	if fi, err := os.Lstat(target); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(target); err != nil {
+			return fmt.Errorf("replace symlink: %w", err)
+		}
+	}
 	f, err := os.Create(target) //nolint:gosec // target is the verified absolute path inside destDir
 	if err != nil {
 		return fmt.Errorf("create file: %w", err)
@@ -0,0 +1,338 @@
+package rde
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5" //nolint:gosec // mirrors storage's x-goog-hash
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func shortRetries(t *testing.T) {
+	t.Helper()
+	backoff := transferRetryBackoff
+	transferRetryBackoff = time.Millisecond
+	t.Cleanup(func() { transferRetryBackoff = backoff })
+}
+
+func googHash(data []byte) string {
+	sum := md5.Sum(data) //nolint:gosec // see import
+	return "md5=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// spoolOf is an *os.File holding data, the way UploadFile hands it over.
+func spoolOf(t *testing.T, data []byte) (*os.File, *checksums) {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	sums := newChecksums()
+	if _, err := io.MultiWriter(f, sums).Write(data); err != nil {
+		t.Fatal(err)
+	}
+	return f, sums
+}
+
+func TestTarGz_RoundTripKeepsSymlinksModesAndTimes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks and POSIX modes")
+	}
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{"bin/tool": "#!/bin/sh", "data/a.txt": "a"})
+	if err := os.Chmod(filepath.Join(src, "bin/tool"), 0o755); err != nil { //nolint:gosec // executable fixture
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../data/a.txt", filepath.Join(src, "bin/link")); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(src, "data/a.txt"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTarGz(&buf, src); err != nil {
+		t.Fatalf("writeTarGz: %v", err)
+	}
+	dest := t.TempDir()
+	if err := extractTarGz(&buf, dest); err != nil {
+		t.Fatalf("extractTarGz: %v", err)
+	}
+
+	fi, err := os.Stat(filepath.Join(dest, "bin/tool"))
+	if err != nil || fi.Mode().Perm() != 0o755 {
+		t.Errorf("bin/tool mode = %v (%v), want 0755", fi.Mode(), err)
+	}
+	if link, err := os.Readlink(filepath.Join(dest, "bin/link")); err != nil || link != "../data/a.txt" {
+		t.Errorf("bin/link = %q (%v), want a symlink to ../data/a.txt", link, err)
+	}
+	if got := readFile(t, dest, "bin/link"); got != "a" {
+		t.Errorf("reading through bin/link = %q", got)
+	}
+	if fi, err := os.Stat(filepath.Join(dest, "data/a.txt")); err != nil || !fi.ModTime().Equal(mtime) {
+		t.Errorf("data/a.txt mtime = %v (%v), want %v", fi.ModTime(), err, mtime)
+	}
+}
+
+func TestExtractTarGz_RejectsEscapingSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks")
+	}
+	for name, links := range map[string][][2]string{
+		"absolute": {{"evil", "/etc"}},
+		"relative": {{"evil", "../outside"}},
+		// Lexically a/b/l/m → ../.. stays inside, but l points two levels
+		// up, so m would really resolve above the destination.
+		"through an earlier link": {{"a/b/l", "../.."}, {"a/b/l/m", "../.."}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Built by hand: on disk, the second link would be created
+			// through the first.
+			var buf bytes.Buffer
+			gw := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gw)
+			for _, l := range links {
+				if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: l[0], Linkname: l[1], Mode: 0o777}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := gw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			err := extractTarGz(&buf, filepath.Join(t.TempDir(), "dest"))
+			if err == nil || !strings.Contains(err.Error(), "would escape destination") {
+				t.Errorf("extractTarGz = %v, want an escape error", err)
+			}
+		})
+	}
+}
+
+func TestPutToSignedURL_RetriesTransientFailure(t *testing.T) {
+	shortRetries(t)
+	data := bytes.Repeat([]byte("payload "), 1000)
+	var mu sync.Mutex
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		calls++
+		n := calls
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if !bytes.Equal(body, data) || r.ContentLength != int64(len(data)) {
+			t.Errorf("PUT body: %d bytes, content-length %d", len(body), r.ContentLength)
+		}
+		w.Header().Set("X-Goog-Hash", "crc32c=AAAAAA==,"+googHash(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	spool, sums := spoolOf(t, data)
+	m := newMeter(PhaseUploading, int64(len(data)), nil)
+	if err := putToSignedURL(context.Background(), srv.URL, spool, int64(len(data)), sums, m); err != nil {
+		t.Fatalf("putToSignedURL: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("PUT calls = %d, want 2 (one 503, one success)", calls)
+	}
+	if m.done != int64(len(data)) {
+		t.Errorf("meter = %d, want %d (reset on retry)", m.done, len(data))
+	}
+}
+
+func TestPutToSignedURL_DetectsChecksumMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("X-Goog-Hash", googHash([]byte("something else")))
+	}))
+	defer srv.Close()
+	data := []byte("payload")
+	spool, sums := spoolOf(t, data)
+	err := putToSignedURL(context.Background(), srv.URL, spool, int64(len(data)), sums, newMeter(PhaseUploading, 0, nil))
+	var ce *checksumError
+	if !errors.As(err, &ce) {
+		t.Errorf("putToSignedURL = %v, want a checksum error", err)
+	}
+}
+
+func TestPutToSignedURL_ResumableSessionResumesFromPersistedOffset(t *testing.T) {
+	shortRetries(t)
+	chunk := resumableChunkSize
+	resumableChunkSize = 4
+	t.Cleanup(func() { resumableChunkSize = chunk })
+
+	data := []byte("0123456789")
+	var (
+		mu      sync.Mutex
+		stored  []byte
+		ranges  []string
+		dropped bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		cr := r.Header.Get("Content-Range")
+		ranges = append(ranges, cr)
+		if strings.HasPrefix(cr, "bytes */") { // status query
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(stored)-1))
+			w.WriteHeader(http.StatusPermanentRedirect)
+			return
+		}
+		// The second chunk is only half persisted before the connection
+		// "drops".
+		if len(stored) == 4 && !dropped {
+			dropped = true
+			stored = append(stored, body[:2]...)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		stored = append(stored, body...)
+		if len(stored) < len(data) {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(stored)-1))
+			w.WriteHeader(http.StatusPermanentRedirect)
+			return
+		}
+		w.Header().Set("X-Goog-Hash", googHash(stored))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	spool, sums := spoolOf(t, data)
+	url := srv.URL + "/upload?upload_id=abc"
+	if err := putToSignedURL(context.Background(), url, spool, int64(len(data)), sums, newMeter(PhaseUploading, 0, nil)); err != nil {
+		t.Fatalf("putToSignedURL: %v", err)
+	}
+	if string(stored) != string(data) {
+		t.Errorf("stored = %q, want %q", stored, data)
+	}
+	want := []string{"bytes 0-3/10", "bytes 4-7/10", "bytes */10", "bytes 6-9/10"}
+	if strings.Join(ranges, " | ") != strings.Join(want, " | ") {
+		t.Errorf("Content-Range sequence = %q, want %q", ranges, want)
+	}
+}
+
+func TestGetFromSignedURL_ResumesWithRangeAfterDrop(t *testing.T) {
+	shortRetries(t)
+	data := bytes.Repeat([]byte("0123456789"), 100)
+	var (
+		mu     sync.Mutex
+		ranges []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		first := len(ranges) == 1
+		mu.Unlock()
+		w.Header().Set("X-Goog-Hash", googHash(data))
+		if first {
+			// Promise everything, send 300 bytes, then cut the connection.
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			_, _ = w.Write(data[:300])
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+			return
+		}
+		from, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.Header.Get("Range"), "bytes="), "-"))
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", from, len(data)-1, len(data)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(data[from:])
+	}))
+	defer srv.Close()
+
+	spool, err := os.CreateTemp(t.TempDir(), "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close() //nolint:errcheck // test cleanup
+	m := newMeter(PhaseDownloading, 0, nil)
+	n, err := getFromSignedURL(context.Background(), srv.URL, spool, m)
+	if err != nil {
+		t.Fatalf("getFromSignedURL: %v", err)
+	}
+	got, _ := os.ReadFile(spool.Name())
+	if n != int64(len(data)) || !bytes.Equal(got, data) {
+		t.Errorf("downloaded %d bytes, content match %v", n, bytes.Equal(got, data))
+	}
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != "bytes=300-" {
+		t.Errorf("Range headers = %q, want a plain GET then bytes=300-", ranges)
+	}
+	if m.total != int64(len(data)) {
+		t.Errorf("meter total = %d, want %d", m.total, len(data))
+	}
+}
+
+func TestGetFromSignedURL_DetectsChecksumMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Goog-Hash", googHash([]byte("original")))
+		_, _ = w.Write([]byte("corrupted"))
+	}))
+	defer srv.Close()
+	spool, err := os.CreateTemp(t.TempDir(), "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close() //nolint:errcheck // test cleanup
+	_, err = getFromSignedURL(context.Background(), srv.URL, spool, newMeter(PhaseDownloading, 0, nil))
+	if err == nil || !strings.Contains(err.Error(), "MD5 checksum mismatch") {
+		t.Errorf("getFromSignedURL = %v, want an MD5 mismatch", err)
+	}
+}
+
+func TestTransferProgress_RateAndETA(t *testing.T) {
+	p := TransferProgress{Done: 25 << 20, Total: 100 << 20, Elapsed: 5 * time.Second}
+	if got := p.Rate(); got != 5<<20 {
+		t.Errorf("Rate = %v, want 5 MiB/s", got)
+	}
+	if got := p.ETA(); got != 15*time.Second {
+		t.Errorf("ETA = %v, want 15s", got)
+	}
+	if got := (TransferProgress{Done: 10, Elapsed: time.Second}).ETA(); got != 0 {
+		t.Errorf("ETA with unknown total = %v, want 0", got)
+	}
+}
+
+func TestCreateSpool_UnderConfigDirWithFreeSpaceCheck(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir, err := spoolDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := createSpool("upload-*.tar.gz", 1024)
+	if err != nil {
+		t.Fatalf("createSpool: %v", err)
+	}
+	_ = f.Close()
+	if filepath.Dir(f.Name()) != dir {
+		t.Errorf("spool at %s, want it in %s", f.Name(), dir)
+	}
+	if _, ok := diskFree(dir); !ok {
+		t.Skip("free disk space unknown on this platform")
+	}
+	if _, err := createSpool("upload-*.tar.gz", 1<<62); err == nil || !strings.Contains(err.Error(), "not enough disk space") {
+		t.Errorf("err = %v, want a disk space error", err)
+	}
+}
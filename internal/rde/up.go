@@ -0,0 +1,206 @@
+package rde
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Labels `rde up` tags a repo's dev session with, and finds it by again.
+const (
+	UpLabelRepo   = "repo"
+	UpLabelBranch = "branch"
+)
+
+// What Up did to get the session running.
+const (
+	UpActionCreated  = "created"
+	UpActionRestored = "restored"
+	UpActionRunning  = "running"
+)
+
+// RepoSlugFromURL extracts "owner/repo" from a clone URL (ssh, scp-like, or
+// https), trimming any ".git" suffix. Returns "" when it can't parse one.
+func RepoSlugFromURL(raw string) string {
+	s := strings.TrimSpace(raw)
+	scheme := false
+	for _, p := range []string{"ssh://", "https://", "http://", "git://"} {
+		if rest, ok := strings.CutPrefix(s, p); ok {
+			s = rest
+			scheme = true
+			break
+		}
+	}
+	if _, after, ok := strings.Cut(s, "@"); ok {
+		s = after
+	}
+	var path string
+	if scheme {
+		// host[:port]/owner/repo
+		_, p, ok := strings.Cut(s, "/")
+		if !ok {
+			return ""
+		}
+		path = p
+	} else {
+		// scp-like: host:owner/repo
+		_, p, ok := strings.Cut(s, ":")
+		if !ok {
+			return ""
+		}
+		path = p
+	}
+	path = strings.TrimSuffix(path, ".git")
+	return strings.Trim(path, "/")
+}
+
+// UpLabels are the labels identifying the dev session for repoSlug at
+// branch. Characters the backend doesn't accept in label values become "-".
+func UpLabels(repoSlug, branch string) map[string]string {
+	return map[string]string{
+		UpLabelRepo:   sanitizeLabelValue(repoSlug),
+		UpLabelBranch: sanitizeLabelValue(branch),
+	}
+}
+
+func sanitizeLabelValue(v string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("._/-:+", r):
+			return r
+		}
+		return '-'
+	}, v)
+}
+
+// labelSelectors turns labels into ListSessions selectors, in key order.
+func labelSelectors(labels map[string]string) []string {
+	out := make([]string, 0, len(labels))
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		out = append(out, k+"="+labels[k])
+	}
+	return out
+}
+
+// FindUpSession returns the dev session labelled for repoSlug at branch, and
+// whether there is one. Sessions that can no longer be brought back (disk
+// gone) don't count. When several match, a live one wins over a terminated
+// one, then the newest.
+func (s *Service) FindUpSession(ctx context.Context, workspaceID, repoSlug, branch string) (Session, bool, error) {
+	if s.client == nil {
+		return Session{}, false, errClient()
+	}
+	sessions, err := s.ListSessions(ctx, workspaceID, labelSelectors(UpLabels(repoSlug, branch)))
+	if err != nil {
+		return Session{}, false, err
+	}
+	var candidates []Session
+	for _, sess := range sessions {
+		if sess.Resumable() {
+			candidates = append(candidates, sess)
+		}
+	}
+	if len(candidates) == 0 {
+		return Session{}, false, nil
+	}
+	live := func(sess Session) bool {
+		switch sess.Status {
+		case "terminated", "stopped", "failed":
+			return false
+		}
+		return true
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if live(a) != live(b) {
+			return live(a)
+		}
+		return a.CreatedAt != nil && (b.CreatedAt == nil || a.CreatedAt.After(*b.CreatedAt))
+	})
+	return candidates[0], true, nil
+}
+
+// UpOptions configures Up.
+type UpOptions struct {
+	RepoSlug, Branch string
+	// Create is the session to create when there's none for the repo and
+	// branch yet; nil makes that an error. Its template may be a name. The
+	// repo and branch labels are added to it.
+	Create *CreateSessionRequest
+	// OnPhase, when non-nil, sees PhaseCreating and EnsureSSHReady's phases.
+	OnPhase func(phase string)
+	// PollInterval paces the readiness polling; zero uses the default.
+	PollInterval time.Duration
+}
+
+// ErrNoUpSession is returned by Up when the repo and branch have no session
+// yet and UpOptions.Create is nil.
+var ErrNoUpSession = errors.New("no session for this repo and branch yet")
+
+// PhaseCreating is reported by Up before it creates a session.
+const PhaseCreating = "creating"
+
+// UpResult is the session Up brought up and how.
+type UpResult struct {
+	Session Session `json:"session"`
+	Action  string  `json:"action"`
+}
+
+// Up makes sure the dev session for a repo and branch exists and accepts
+// SSH: an existing one (see FindUpSession) is waited on, or restored when
+// terminated; without one, opts.Create is created and waited on.
+func (s *Service) Up(ctx context.Context, workspaceID string, opts UpOptions) (UpResult, error) {
+	if s.client == nil {
+		return UpResult{}, errClient()
+	}
+	phase := func(p string) {
+		if opts.OnPhase != nil {
+			opts.OnPhase(p)
+		}
+	}
+	sess, found, err := s.FindUpSession(ctx, workspaceID, opts.RepoSlug, opts.Branch)
+	if err != nil {
+		return UpResult{}, fmt.Errorf("look up session: %w", err)
+	}
+	action := UpActionRunning
+	if found {
+		switch sess.Status {
+		case "terminated", "stopped", "failed":
+			action = UpActionRestored
+		}
+	} else {
+		if opts.Create == nil {
+			return UpResult{}, fmt.Errorf("%w (%s @ %s)", ErrNoUpSession, opts.RepoSlug, opts.Branch)
+		}
+		req := *opts.Create
+		if req.TemplateID != "" {
+			if req.TemplateID, err = s.ResolveTemplateID(ctx, workspaceID, req.TemplateID); err != nil {
+				return UpResult{}, err
+			}
+		}
+		labels := make(map[string]string, len(req.Labels)+2)
+		for k, v := range req.Labels {
+			labels[k] = v
+		}
+		for k, v := range UpLabels(opts.RepoSlug, opts.Branch) {
+			labels[k] = v
+		}
+		req.Labels = labels
+		phase(PhaseCreating)
+		created, err := s.CreateSession(ctx, workspaceID, req)
+		if err != nil {
+			return UpResult{}, fmt.Errorf("create session: %w", err)
+		}
+		sess, action = created.Session, UpActionCreated
+	}
+	ready, err := s.EnsureSSHReady(ctx, workspaceID, sess.ID, true, opts.PollInterval, opts.OnPhase)
+	if err != nil {
+		return UpResult{Session: sess, Action: action}, err
+	}
+	return UpResult{Session: ready, Action: action}, nil
+}
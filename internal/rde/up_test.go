@@ -0,0 +1,113 @@
+package rde
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
+)
+
+func TestRepoSlugFromURL(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"git@github.com:org/repo.git", "org/repo"},
+		{"git@github.com:org/repo", "org/repo"},
+		{"https://github.com/org/repo.git", "org/repo"},
+		{"https://github.com/org/repo", "org/repo"},
+		{"ssh://git@github.com/org/repo.git", "org/repo"},
+		{"git@gitlab.com:grp/sub/repo.git", "grp/sub/repo"},
+		{"https://user@github.com/org/repo.git", "org/repo"},
+		{"not a url", ""},
+		{"", ""},
+	} {
+		if got := RepoSlugFromURL(tc.in); got != tc.want {
+			t.Errorf("RepoSlugFromURL(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestUpLabels_SanitizesValues(t *testing.T) {
+	got := UpLabels("org/repo", "feature/add #12@x")
+	want := map[string]string{UpLabelRepo: "org/repo", UpLabelBranch: "feature/add--12-x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UpLabels = %v, want %v", got, want)
+	}
+}
+
+func TestFindUpSession_PrefersLiveThenNewest(t *testing.T) {
+	rs := newRecordingServer(t, `{"sessions":[
+		{"id":"old-term","status":"SESSION_STATUS_TERMINATED","persistentDiskStatus":"PERSISTENT_DISK_STATUS_AVAILABLE","createdAt":"2026-01-01T00:00:00Z"},
+		{"id":"gone","status":"SESSION_STATUS_TERMINATED","persistentDiskStatus":"PERSISTENT_DISK_STATUS_UNAVAILABLE","createdAt":"2026-03-01T00:00:00Z"},
+		{"id":"live-old","status":"SESSION_STATUS_RUNNING","createdAt":"2026-01-02T00:00:00Z"},
+		{"id":"live-new","status":"SESSION_STATUS_STARTING","createdAt":"2026-02-01T00:00:00Z"}]}`)
+	sess, found, err := rs.service().FindUpSession(context.Background(), "ws-1", "org/repo", "main")
+	if err != nil || !found {
+		t.Fatalf("FindUpSession = %v, %v", found, err)
+	}
+	if sess.ID != "live-new" {
+		t.Errorf("picked %s, want live-new", sess.ID)
+	}
+	if want := "labelSelectors=branch%3Dmain&labelSelectors=repo%3Dorg%2Frepo"; rs.lastQuery != want {
+		t.Errorf("query = %q, want %q", rs.lastQuery, want)
+	}
+}
+
+func TestUp_CreatesLabelledSessionWhenNoneExists(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		created map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/workspaces/ws-1/sessions":
+			_, _ = io.WriteString(w, `{"sessions":[]}`)
+		case r.Method == http.MethodPost:
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			_, _ = io.WriteString(w, `{"session":{"id":"s-new","status":"SESSION_STATUS_PENDING"}}`)
+		default:
+			_, _ = io.WriteString(w, `{"session":{"id":"s-new","status":"SESSION_STATUS_RUNNING","sshConnectionOpen":true,"sshAddress":"ssh ubuntu@h -p 22","sshPassword":"pw"}}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	svc := NewService(rdeapi.New(srv.URL, "tok"))
+	var phases []string
+	res, err := svc.Up(context.Background(), "ws-1", UpOptions{
+		RepoSlug: "org/repo", Branch: "main",
+		Create:       &CreateSessionRequest{Name: "repo-main", StackID: "st", MachineType: "mt", Labels: map[string]string{"team": "mobile"}},
+		OnPhase:      func(p string) { phases = append(phases, p) },
+		PollInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if res.Action != UpActionCreated || res.Session.ID != "s-new" {
+		t.Errorf("result = %+v", res)
+	}
+	want := map[string]any{"team": "mobile", UpLabelRepo: "org/repo", UpLabelBranch: "main"}
+	if !reflect.DeepEqual(created["labels"], want) {
+		t.Errorf("created labels = %v, want %v", created["labels"], want)
+	}
+	if len(phases) == 0 || phases[0] != PhaseCreating {
+		t.Errorf("phases = %v, want PhaseCreating first", phases)
+	}
+}
+
+func TestUp_WithoutSessionOrCreateIsErrNoUpSession(t *testing.T) {
+	rs := newRecordingServer(t, `{"sessions":[]}`)
+	_, err := rs.service().Up(context.Background(), "ws-1", UpOptions{RepoSlug: "org/repo", Branch: "main"})
+	if !errors.Is(err, ErrNoUpSession) {
+		t.Errorf("Up = %v, want ErrNoUpSession", err)
+	}
+}
@@ -0,0 +1,372 @@
+package rde
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// UsageUnknown is the group key for sessions missing the grouped attribute
+// (no user label, no template, …).
+const UsageUnknown = "(unknown)"
+
+// UsageOptions scopes a usage report. The API keeps no per-session runtime
+// ledger, so runtimes are estimated: a session runs from created_at until
+// now, or until its last update once terminated/stopped/failed, minus the
+// terminated spans this CLI has observed. Those come from a log kept on this
+// machine (terminate, restore and watch record status changes in it), so a
+// session terminated and restored from another machine, the web UI, or
+// another user's CLI is counted as running in between. Deleted sessions are
+// no longer listed by the API and drop out of the report entirely, runtime
+// included — treat the numbers as an estimate of the current sessions, not
+// an invoice.
+type UsageOptions struct {
+	// Since and Until bound the reporting window; runtimes are clipped to it.
+	// A zero Until means Now.
+	Since time.Time
+	Until time.Time
+	// Now is the reference time for live sessions and idle checks. Zero
+	// means time.Now().
+	Now time.Time
+	// UserLabel is the session label naming who the session belongs to.
+	// Sessions don't carry an owner field, so per-user grouping relies on
+	// a label convention; sessions without it group under UsageUnknown.
+	UserLabel string
+	// Rates maps machine type names to credits per minute. The machine
+	// catalog carries no pricing, so credit estimates are only produced for
+	// machine types listed here.
+	Rates map[string]float64
+	// IdleAfter flags running sessions with no agent status change and no
+	// notification for at least this long. Zero disables idle detection.
+	IdleAfter time.Duration
+}
+
+// UsageGroup is the aggregated runtime of the sessions sharing one key.
+// Credits is nil when no rates were supplied.
+type UsageGroup struct {
+	Key      string   `json:"key"`
+	Title    string   `json:"title,omitempty"`
+	Sessions int      `json:"sessions"`
+	Minutes  float64  `json:"minutes"`
+	Credits  *float64 `json:"credits,omitempty"`
+}
+
+// UsageSession is one session's estimated runtime inside the window.
+type UsageSession struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Status      string    `json:"status,omitempty"`
+	User        string    `json:"user"`
+	Template    string    `json:"template"`
+	Stack       string    `json:"stack"`
+	MachineType string    `json:"machine_type"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Minutes     float64   `json:"minutes"`
+	Credits     *float64  `json:"credits,omitempty"`
+	// Runs is how many separate run intervals make up Minutes; more than
+	// one when an observed termination split the session.
+	Runs int `json:"runs"`
+}
+
+// IdleSession is a running session that shows no sign of activity.
+type IdleSession struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	User            string     `json:"user"`
+	MachineType     string     `json:"machine_type"`
+	AgentStatus     string     `json:"agent_session_status,omitempty"`
+	LastActivity    time.Time  `json:"last_activity"`
+	IdleMinutes     int        `json:"idle_minutes"`
+	AutoTerminateAt *time.Time `json:"auto_terminate_at,omitempty"`
+}
+
+// UsageReport is the result of Service.Usage.
+type UsageReport struct {
+	Since         time.Time      `json:"since"`
+	Until         time.Time      `json:"until"`
+	Sessions      int            `json:"sessions"`
+	Minutes       float64        `json:"minutes"`
+	Credits       *float64       `json:"credits,omitempty"`
+	ByUser        []UsageGroup   `json:"by_user"`
+	ByTemplate    []UsageGroup   `json:"by_template"`
+	ByStack       []UsageGroup   `json:"by_stack"`
+	ByMachineType []UsageGroup   `json:"by_machine_type"`
+	Items         []UsageSession `json:"items"`
+	Idle          []IdleSession  `json:"idle"`
+	// UnpricedMachineTypes lists machine types that used runtime in the
+	// window but have no rate, so their minutes are missing from Credits.
+	UnpricedMachineTypes []string `json:"unpriced_machine_types,omitempty"`
+}
+
+// Usage aggregates the estimated session runtime in the workspace per user,
+// template, stack and machine type, and flags long-idle running sessions.
+// See UsageOptions for how runtimes are estimated.
+func (s *Service) Usage(ctx context.Context, workspaceID string, opts UsageOptions) (UsageReport, error) {
+	if s.client == nil {
+		return UsageReport{}, errClient()
+	}
+	sessions, err := s.ListSessions(ctx, workspaceID, nil)
+	if err != nil {
+		return UsageReport{}, err
+	}
+	machineTypes, err := s.ListMachineTypes(ctx, workspaceID)
+	if err != nil {
+		return UsageReport{}, err
+	}
+	lastNotified := make(map[string]time.Time)
+	if opts.IdleAfter > 0 {
+		for _, sess := range sessions {
+			if sess.Status != "running" {
+				continue
+			}
+			notes, err := s.ListSessionNotifications(ctx, workspaceID, sess.ID, ListSessionNotificationsOptions{Limit: 1, Order: "desc"})
+			if err != nil {
+				return UsageReport{}, err
+			}
+			if len(notes) > 0 && notes[0].CreatedAt != nil {
+				lastNotified[sess.ID] = *notes[0].CreatedAt
+			}
+		}
+	}
+	history, err := loadStatusLog()
+	if err != nil {
+		return UsageReport{}, err
+	}
+	return buildUsageReport(sessions, machineTypes, lastNotified, history, opts), nil
+}
+
+// buildUsageReport is the pure half of Usage. history holds the status
+// changes observed per session, oldest first.
+func buildUsageReport(sessions []Session, machineTypes []MachineType, lastNotified map[string]time.Time, history map[string][]StatusChange, opts UsageOptions) UsageReport {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	until := opts.Until
+	if until.IsZero() {
+		until = now
+	}
+	priced := len(opts.Rates) > 0
+	titles := make(map[string]string, len(machineTypes))
+	for _, mt := range machineTypes {
+		if mt.Title != "" {
+			titles[mt.Name] = mt.Title
+		}
+	}
+
+	rep := UsageReport{Since: opts.Since.UTC(), Until: until.UTC(), Items: []UsageSession{}, Idle: []IdleSession{}}
+	if priced {
+		rep.Credits = new(float64)
+	}
+	byUser := newUsageGroups(priced)
+	byTemplate := newUsageGroups(priced)
+	byStack := newUsageGroups(priced)
+	byMachineType := newUsageGroups(priced)
+	unpriced := make(map[string]struct{})
+
+	for _, sess := range sessions {
+		item := UsageSession{
+			ID:          sess.ID,
+			Name:        sess.Name,
+			Status:      sess.Status,
+			User:        orUnknown(sess.Labels[opts.UserLabel]),
+			Template:    orUnknown(usageTemplate(sess)),
+			Stack:       UsageUnknown,
+			MachineType: UsageUnknown,
+		}
+		if snap := sess.TemplateSnapshot; snap != nil {
+			item.Stack = orUnknown(snap.StackID)
+			item.MachineType = orUnknown(snap.MachineType)
+		}
+
+		if idle, ok := idleSession(sess, item, lastNotified[sess.ID], now, opts.IdleAfter); ok {
+			rep.Idle = append(rep.Idle, idle)
+		}
+
+		for _, run := range sessionRuns(sess, history[sess.ID], now) {
+			start, end := run.start, run.end
+			if start.Before(opts.Since) {
+				start = opts.Since
+			}
+			if end.After(until) {
+				end = until
+			}
+			if !end.After(start) {
+				continue
+			}
+			if item.Runs == 0 {
+				item.Start = start.UTC()
+			}
+			item.End = end.UTC()
+			item.Minutes += end.Sub(start).Minutes()
+			item.Runs++
+		}
+		if item.Runs == 0 {
+			continue
+		}
+		if rate, ok := opts.Rates[item.MachineType]; ok {
+			c := item.Minutes * rate
+			item.Credits = &c
+			*rep.Credits += c
+		} else if priced {
+			unpriced[item.MachineType] = struct{}{}
+		}
+
+		rep.Sessions++
+		rep.Minutes += item.Minutes
+		byUser.add(item.User, item)
+		byTemplate.add(item.Template, item)
+		byStack.add(item.Stack, item)
+		byMachineType.add(item.MachineType, item)
+		rep.Items = append(rep.Items, item)
+	}
+
+	rep.ByUser = byUser.sorted(nil)
+	rep.ByTemplate = byTemplate.sorted(nil)
+	rep.ByStack = byStack.sorted(nil)
+	rep.ByMachineType = byMachineType.sorted(titles)
+	rep.UnpricedMachineTypes = slices.Sorted(maps.Keys(unpriced))
+	sort.SliceStable(rep.Items, func(i, j int) bool { return rep.Items[i].Minutes > rep.Items[j].Minutes })
+	sort.SliceStable(rep.Idle, func(i, j int) bool { return rep.Idle[i].IdleMinutes > rep.Idle[j].IdleMinutes })
+	return rep
+}
+
+type usageRun struct{ start, end time.Time }
+
+// sessionRuns estimates when the session held a machine: from its creation,
+// closed by each observed stop in history and reopened by the next observed
+// live status, with the last run ending at the session's last update if it
+// is stopped now, or at now. Sessions without a creation time can't be
+// placed and have no runs.
+func sessionRuns(sess Session, history []StatusChange, now time.Time) []usageRun {
+	if sess.CreatedAt == nil {
+		return nil
+	}
+	var runs []usageRun
+	start, running := *sess.CreatedAt, true
+	for _, c := range history {
+		if c.At.Before(*sess.CreatedAt) {
+			continue
+		}
+		switch stopped := usageStopped(c.Status); {
+		case running && stopped:
+			runs = append(runs, usageRun{start, c.At})
+			running = false
+		case !running && !stopped:
+			start, running = c.At, true
+		}
+	}
+	if running {
+		end := now
+		if usageStopped(sess.Status) {
+			end = start
+			if sess.UpdatedAt != nil && sess.UpdatedAt.After(start) {
+				end = *sess.UpdatedAt
+			}
+		}
+		runs = append(runs, usageRun{start, end})
+	}
+	return runs
+}
+
+// usageStopped reports whether a session in status holds no machine. A
+// terminate request counts from when it was made.
+func usageStopped(status string) bool {
+	switch status {
+	case "terminating", "terminated", "stopped", "failed":
+		return true
+	}
+	return false
+}
+
+// idleSession reports a running session whose latest sign of activity — its
+// creation, its last agent status change or its newest notification — is at
+// least idleAfter old.
+func idleSession(sess Session, item UsageSession, lastNotified, now time.Time, idleAfter time.Duration) (IdleSession, bool) {
+	if idleAfter <= 0 || sess.Status != "running" || sess.CreatedAt == nil {
+		return IdleSession{}, false
+	}
+	last := *sess.CreatedAt
+	if t := sess.AgentSessionStatusUpdatedAt; t != nil && t.After(last) {
+		last = *t
+	}
+	if lastNotified.After(last) {
+		last = lastNotified
+	}
+	idleFor := now.Sub(last)
+	if idleFor < idleAfter {
+		return IdleSession{}, false
+	}
+	return IdleSession{
+		ID:              sess.ID,
+		Name:            sess.Name,
+		User:            item.User,
+		MachineType:     item.MachineType,
+		AgentStatus:     sess.AgentSessionStatus,
+		LastActivity:    last.UTC(),
+		IdleMinutes:     int(idleFor.Minutes()),
+		AutoTerminateAt: sess.AutoTerminateAt,
+	}, true
+}
+
+func usageTemplate(sess Session) string {
+	if sess.TemplateName != "" {
+		return sess.TemplateName
+	}
+	if sess.TemplateSnapshot != nil && sess.TemplateSnapshot.TemplateName != "" {
+		return sess.TemplateSnapshot.TemplateName
+	}
+	return sess.TemplateID
+}
+
+func orUnknown(v string) string {
+	if v == "" {
+		return UsageUnknown
+	}
+	return v
+}
+
+type usageGroups struct {
+	priced bool
+	groups map[string]*UsageGroup
+}
+
+func newUsageGroups(priced bool) *usageGroups {
+	return &usageGroups{priced: priced, groups: make(map[string]*UsageGroup)}
+}
+
+func (g *usageGroups) add(key string, item UsageSession) {
+	grp, ok := g.groups[key]
+	if !ok {
+		grp = &UsageGroup{Key: key}
+		if g.priced {
+			grp.Credits = new(float64)
+		}
+		g.groups[key] = grp
+	}
+	grp.Sessions++
+	grp.Minutes += item.Minutes
+	if item.Credits != nil {
+		*grp.Credits += *item.Credits
+	}
+}
+
+// sorted returns the groups by descending runtime, filling in display titles
+// when given.
+func (g *usageGroups) sorted(titles map[string]string) []UsageGroup {
+	out := make([]UsageGroup, 0, len(g.groups))
+	for key, grp := range g.groups {
+		grp.Title = titles[key]
+		out = append(out, *grp)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Minutes != out[j].Minutes {
+			return out[i].Minutes > out[j].Minutes
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
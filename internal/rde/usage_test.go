@@ -0,0 +1,255 @@
+package rde
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
+)
+
+func usageTime(t *testing.T, s string) *time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &v
+}
+
+func TestBuildUsageReport_GroupsClipsAndPrices(t *testing.T) {
+	now := *usageTime(t, "2026-10-01T12:00:00Z")
+	snap := func(stack, mt string) *SessionTemplateSnapshot {
+		return &SessionTemplateSnapshot{StackID: stack, MachineType: mt}
+	}
+	sessions := []Session{
+		{
+			ID: "s1", Name: "a", Status: "terminated", TemplateName: "ios",
+			TemplateSnapshot: snap("xcode-16", "m2.large"), Labels: map[string]string{"user": "ana"},
+			CreatedAt: usageTime(t, "2026-10-01T08:00:00Z"), UpdatedAt: usageTime(t, "2026-10-01T10:00:00Z"),
+		},
+		{
+			// Started before the window: only the in-window 12h count.
+			ID: "s2", Name: "b", Status: "running", TemplateName: "ios",
+			TemplateSnapshot: snap("xcode-16", "m2.small"), Labels: map[string]string{"user": "bo"},
+			CreatedAt: usageTime(t, "2026-09-30T00:00:00Z"),
+		},
+		{
+			// Ended before the window: excluded.
+			ID: "s3", Name: "c", Status: "terminated", TemplateName: "android",
+			CreatedAt: usageTime(t, "2026-09-01T00:00:00Z"), UpdatedAt: usageTime(t, "2026-09-02T00:00:00Z"),
+		},
+	}
+	catalog := []MachineType{{Name: "m2.large", Title: "M2 Large"}}
+
+	rep := buildUsageReport(sessions, catalog, nil, nil, UsageOptions{
+		Since:     *usageTime(t, "2026-10-01T00:00:00Z"),
+		Now:       now,
+		UserLabel: "user",
+		Rates:     map[string]float64{"m2.large": 2},
+	})
+	if rep.Sessions != 2 {
+		t.Fatalf("sessions = %d, want 2", rep.Sessions)
+	}
+	if rep.Minutes != 120+720 {
+		t.Errorf("minutes = %v, want 840", rep.Minutes)
+	}
+	if rep.Credits == nil || *rep.Credits != 240 {
+		t.Errorf("credits = %v, want 240", rep.Credits)
+	}
+	if got := rep.UnpricedMachineTypes; len(got) != 1 || got[0] != "m2.small" {
+		t.Errorf("unpriced = %v, want [m2.small]", got)
+	}
+	if len(rep.ByUser) != 2 || rep.ByUser[0].Key != "bo" || rep.ByUser[0].Minutes != 720 {
+		t.Errorf("by user = %+v, want bo first with 720 minutes", rep.ByUser)
+	}
+	if len(rep.ByTemplate) != 1 || rep.ByTemplate[0].Sessions != 2 {
+		t.Errorf("by template = %+v, want one ios group of 2", rep.ByTemplate)
+	}
+	var large UsageGroup
+	for _, g := range rep.ByMachineType {
+		if g.Key == "m2.large" {
+			large = g
+		}
+	}
+	if large.Title != "M2 Large" || large.Credits == nil || *large.Credits != 240 {
+		t.Errorf("m2.large group = %+v, want titled and priced at 240", large)
+	}
+}
+
+func TestBuildUsageReport_NoRatesOmitsCredits(t *testing.T) {
+	rep := buildUsageReport([]Session{{
+		ID: "s1", Status: "running", CreatedAt: usageTime(t, "2026-10-01T00:00:00Z"),
+	}}, nil, nil, nil, UsageOptions{Now: *usageTime(t, "2026-10-01T01:00:00Z")})
+
+	if rep.Credits != nil || rep.ByUser[0].Credits != nil || rep.Items[0].Credits != nil {
+		t.Errorf("credits should be omitted without rates: %+v", rep)
+	}
+	if rep.ByUser[0].Key != UsageUnknown || rep.ByStack[0].Key != UsageUnknown {
+		t.Errorf("missing attributes should group as %q: %+v", UsageUnknown, rep)
+	}
+}
+
+func TestBuildUsageReport_SubtractsObservedTerminatedSpans(t *testing.T) {
+	now := *usageTime(t, "2026-10-01T12:00:00Z")
+	change := func(status, at string) StatusChange { return StatusChange{Status: status, At: *usageTime(t, at)} }
+	sessions := []Session{
+		// Terminated at 02:00, restored at 06:00, still running.
+		{ID: "restored", Status: "running", CreatedAt: usageTime(t, "2026-10-01T00:00:00Z")},
+		// Restored at 04:00 as seen here, terminated elsewhere at 05:00.
+		{ID: "terminated", Status: "terminated",
+			CreatedAt: usageTime(t, "2026-10-01T00:00:00Z"), UpdatedAt: usageTime(t, "2026-10-01T05:00:00Z")},
+		// A change logged before the session existed belongs to another
+		// session that had the same ID.
+		{ID: "reused", Status: "running", CreatedAt: usageTime(t, "2026-10-01T10:00:00Z")},
+	}
+	history := map[string][]StatusChange{
+		"restored":   {change("terminating", "2026-10-01T02:00:00Z"), change("terminated", "2026-10-01T02:01:00Z"), change("starting", "2026-10-01T06:00:00Z")},
+		"terminated": {change("terminating", "2026-10-01T01:00:00Z"), change("starting", "2026-10-01T04:00:00Z")},
+		"reused":     {change("terminating", "2026-10-01T09:00:00Z")},
+	}
+
+	rep := buildUsageReport(sessions, nil, nil, history, UsageOptions{Since: *usageTime(t, "2026-10-01T01:00:00Z"), Now: now})
+
+	want := map[string]struct {
+		minutes float64
+		runs    int
+	}{
+		"restored":   {60 + 360, 2}, // clipped to the window from 01:00
+		"terminated": {0 + 60, 1},   // the 00:00–01:00 run is outside the window
+		"reused":     {120, 1},
+	}
+	for _, item := range rep.Items {
+		if w := want[item.ID]; item.Minutes != w.minutes || item.Runs != w.runs {
+			t.Errorf("%s: %v minutes in %d runs, want %v in %d", item.ID, item.Minutes, item.Runs, w.minutes, w.runs)
+		}
+	}
+	if len(rep.Items) != len(want) {
+		t.Errorf("items = %+v, want %d", rep.Items, len(want))
+	}
+}
+
+func TestBuildUsageReport_IdleUsesLatestActivity(t *testing.T) {
+	now := *usageTime(t, "2026-10-01T12:00:00Z")
+	sessions := []Session{
+		{ID: "idle", Status: "running", AgentSessionStatus: "waiting",
+			CreatedAt: usageTime(t, "2026-10-01T00:00:00Z"), AgentSessionStatusUpdatedAt: usageTime(t, "2026-10-01T08:00:00Z")},
+		{ID: "notified", Status: "running",
+			CreatedAt: usageTime(t, "2026-10-01T00:00:00Z")},
+		{ID: "stopped", Status: "terminated",
+			CreatedAt: usageTime(t, "2026-10-01T00:00:00Z")},
+	}
+	lastNotified := map[string]time.Time{"notified": *usageTime(t, "2026-10-01T11:30:00Z")}
+
+	rep := buildUsageReport(sessions, nil, lastNotified, nil, UsageOptions{Now: now, IdleAfter: 2 * time.Hour})
+
+	if len(rep.Idle) != 1 {
+		t.Fatalf("idle = %+v, want only the session without recent activity", rep.Idle)
+	}
+	got := rep.Idle[0]
+	if got.ID != "idle" || got.IdleMinutes != 240 || got.AgentStatus != "waiting" {
+		t.Errorf("idle = %+v, want idle for 240m with agent status", got)
+	}
+}
+
+func TestUsage_FetchesLatestNotificationForRunningSessions(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var notificationQueries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/workspaces/ws-1/sessions":
+			_, _ = io.WriteString(w, `{"sessions":[
+				{"id":"s1","status":"SESSION_STATUS_RUNNING","createdAt":"2026-10-01T00:00:00Z"},
+				{"id":"s2","status":"SESSION_STATUS_TERMINATED","createdAt":"2026-10-01T00:00:00Z","updatedAt":"2026-10-01T01:00:00Z"}
+			]}`)
+		case r.URL.Path == "/v1/workspaces/ws-1/machine-types":
+			_, _ = io.WriteString(w, `{"machineTypes":[]}`)
+		case strings.HasSuffix(r.URL.Path, "/notifications"):
+			notificationQueries = append(notificationQueries, r.URL.Path+"?"+r.URL.RawQuery)
+			_, _ = io.WriteString(w, `{"notifications":[{"id":"n1","createdAt":"2026-10-01T11:00:00Z"}]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	// s1 was terminated from 02:00 until its restore at 11:00.
+	recordStatus("s1", "terminating", *usageTime(t, "2026-10-01T02:00:00Z"))
+	recordStatus("s1", "starting", *usageTime(t, "2026-10-01T11:00:00Z"))
+	rep, err := NewService(rdeapi.New(srv.URL, "tok")).Usage(context.Background(), "ws-1", UsageOptions{
+		Now:       *usageTime(t, "2026-10-01T12:00:00Z"),
+		IdleAfter: 2 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if len(notificationQueries) != 1 || !strings.Contains(notificationQueries[0], "/sessions/s1/notifications") ||
+		!strings.Contains(notificationQueries[0], "limit=1") || !strings.Contains(notificationQueries[0], "order=SORT_ORDER_DESC") {
+		t.Errorf("notification queries = %v, want one latest-first lookup for s1", notificationQueries)
+	}
+	if len(rep.Idle) != 0 {
+		t.Errorf("idle = %+v, want none (notified an hour ago)", rep.Idle)
+	}
+	if rep.Minutes != 120+60+60 {
+		t.Errorf("minutes = %v, want 240", rep.Minutes)
+	}
+}
+
+func TestTerminateAndRestore_LogStatus(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"session":{"id":"s1"}}`)
+	}))
+	defer srv.Close()
+	svc := NewService(rdeapi.New(srv.URL, "tok"))
+
+	if _, err := svc.TerminateSession(context.Background(), "ws-1", "s1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RestoreSession(context.Background(), "ws-1", "s1"); err != nil {
+		t.Fatal(err)
+	}
+	log, err := loadStatusLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := log["s1"]; len(got) != 2 || got[0].Status != "terminating" || got[1].Status != "starting" {
+		t.Errorf("logged = %+v, want terminating then starting", got)
+	}
+}
+
+func TestRecordStatus_ConcurrentCallsKeepEveryChange(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recordStatus(fmt.Sprintf("s%d", i), "terminating", at)
+		}()
+	}
+	wg.Wait()
+
+	log, err := loadStatusLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 20 {
+		t.Errorf("logged %d sessions, want 20", len(log))
+	}
+	p, err := statusLogPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left, _ := filepath.Glob(filepath.Join(filepath.Dir(p), "*.tmp")); len(left) > 0 {
+		t.Errorf("temp files left behind: %v", left)
+	}
+}
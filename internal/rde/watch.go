@@ -0,0 +1,336 @@
+package rde
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Watch event types.
+const (
+	// WatchEventSession reports a session as first seen by the watch, with
+	// its full state in Session.
+	WatchEventSession = "session"
+	// WatchEventStatus reports a session status change (From → To).
+	WatchEventStatus = "status"
+	// WatchEventAgentStatus reports an agent status change (From → To).
+	WatchEventAgentStatus = "agent_status"
+	// WatchEventSSH reports SSH becoming reachable (To "ready") or going
+	// away (To "unavailable").
+	WatchEventSSH = "ssh"
+	// WatchEventNotification carries a new session notification.
+	WatchEventNotification = "notification"
+	// WatchEventGone reports a watched session that no longer exists.
+	WatchEventGone = "gone"
+	// WatchEventError reports a failed poll; the watch keeps going.
+	WatchEventError = "error"
+)
+
+// SSH readiness values carried by WatchEventSSH.
+const (
+	SSHReady       = "ready"
+	SSHUnavailable = "unavailable"
+)
+
+// WatchEvent is one change observed by Service.Watch. JSON tags define the
+// NDJSON event stream of `rde session watch --output json`.
+type WatchEvent struct {
+	Type         string               `json:"type"`
+	Time         time.Time            `json:"time"`
+	SessionID    string               `json:"session_id,omitempty"`
+	SessionName  string               `json:"session_name,omitempty"`
+	From         string               `json:"from,omitempty"`
+	To           string               `json:"to,omitempty"`
+	Session      *Session             `json:"session,omitempty"`
+	Notification *SessionNotification `json:"notification,omitempty"`
+	// Replayed marks a notification that already existed when the watch
+	// started, shown because of WatchOptions.Since.
+	Replayed bool   `json:"replayed,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// WatchOptions scopes Service.Watch.
+type WatchOptions struct {
+	// SessionIDs are the sessions to watch. Empty watches every session in
+	// the workspace matching LabelSelectors, picking up new ones as they
+	// appear.
+	SessionIDs     []string
+	LabelSelectors []string
+	// Since replays notifications created after it. Zero starts after the
+	// newest notification each session already has; the cursor comes from
+	// the server's timestamps, never the local clock.
+	Since time.Time
+	// Interval between polls. Zero means 5s.
+	Interval time.Duration
+}
+
+// SessionSSHReady reports whether sess accepts SSH connections right now.
+func SessionSSHReady(sess Session) bool {
+	return sess.Status == "running" && sess.SSHConnectionOpen && sess.SSHAddress != ""
+}
+
+// AgentAwaitingUser reports whether an agent status reads as the agent being
+// blocked on the user — a permission prompt or a question. The backend's
+// agent statuses are passed through as-is, so this matches on wording
+// rather than a fixed list.
+func AgentAwaitingUser(status string) bool {
+	for _, w := range []string{"waiting", "permission", "input", "approval"} {
+		if strings.Contains(status, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// watched is the last known state of one watched session.
+type watched struct {
+	sess Session
+	// after is the notifications cursor: the newest notification time seen.
+	after time.Time
+	// seen dedupes notifications sharing the cursor's timestamp.
+	seen map[string]struct{}
+	// seed asks the next notifications fetch to first look up the newest
+	// existing notification: the cursor without Since, and the end of the
+	// replay with it.
+	seed bool
+	// existing is the creation time of the newest notification the
+	// session had when the watch started.
+	existing time.Time
+}
+
+// Watch polls the sessions selected by opts until ctx is done, calling emit
+// for every change: each session once as it's first seen, then status,
+// agent status and SSH readiness changes and new notifications as they
+// arrive. Notifications are fetched incrementally, created after the newest
+// one already seen. A failing first poll is returned as the error; later
+// failures are reported as WatchEventError and polling continues. Watch
+// returns nil when ctx is cancelled, or once every session named in
+// opts.SessionIDs is gone.
+func (s *Service) Watch(ctx context.Context, workspaceID string, opts WatchOptions, emit func(WatchEvent)) error {
+	if s.client == nil {
+		return errClient()
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	w := &watcher{
+		svc:         s,
+		workspaceID: workspaceID,
+		opts:        opts,
+		state:       make(map[string]*watched),
+		gone:        make(map[string]struct{}),
+		emit:        emit,
+	}
+	w.opts.SessionIDs = uniqueStrings(opts.SessionIDs)
+	opts = w.opts
+	for first := true; ; first = false {
+		if err := w.poll(ctx, first); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if first {
+				return err
+			}
+			emit(WatchEvent{Type: WatchEventError, Time: time.Now().UTC(), Error: err.Error()})
+		}
+		if len(opts.SessionIDs) > 0 && len(w.gone) == len(opts.SessionIDs) {
+			// Every explicitly watched session is gone; nothing is left.
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// watcher is the state Watch carries between polls.
+type watcher struct {
+	svc         *Service
+	workspaceID string
+	opts        WatchOptions
+	state       map[string]*watched
+	// gone holds explicitly watched IDs already reported gone, so they
+	// aren't fetched again.
+	gone map[string]struct{}
+	emit func(WatchEvent)
+}
+
+// poll fetches the sessions and their new notifications. Sessions already
+// there on the first poll have their notifications cursor seeded; ones
+// appearing later are new, so all their notifications are too.
+func (w *watcher) poll(ctx context.Context, first bool) error {
+	sessions, gone, err := w.sessions(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, id := range gone {
+		name := w.state[id].sess.Name
+		delete(w.state, id)
+		w.emit(WatchEvent{Type: WatchEventGone, Time: now, SessionID: id, SessionName: name})
+	}
+	for _, sess := range sessions {
+		st, ok := w.state[sess.ID]
+		if !ok {
+			st = &watched{after: w.opts.Since, seen: make(map[string]struct{}), seed: first}
+			w.state[sess.ID] = st
+			snapshot := sess
+			w.emit(WatchEvent{Type: WatchEventSession, Time: now, SessionID: sess.ID, SessionName: sess.Name, Session: &snapshot})
+		} else {
+			for _, ev := range diffWatchedSession(st.sess, sess, now) {
+				if ev.Type == WatchEventStatus {
+					recordStatus(sess.ID, ev.To, now)
+				}
+				w.emit(ev)
+			}
+		}
+		st.sess = sess
+	}
+	for _, sess := range sessions {
+		switch sess.Status {
+		case "terminated", "stopped", "failed":
+			// No agent runs on a stopped session, so nothing new can arrive.
+			continue
+		}
+		if err := w.notifications(ctx, w.state[sess.ID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sessions fetches the current state of the watched sessions and reports
+// the IDs of previously seen ones that no longer exist.
+func (w *watcher) sessions(ctx context.Context) ([]Session, []string, error) {
+	var (
+		sessions []Session
+		gone     []string
+	)
+	if len(w.opts.SessionIDs) > 0 {
+		for _, id := range w.opts.SessionIDs {
+			if _, ok := w.gone[id]; ok {
+				continue
+			}
+			sess, err := w.svc.GetSession(ctx, w.workspaceID, id)
+			if IsNotFound(err) && w.state[id] != nil {
+				w.gone[id] = struct{}{}
+				gone = append(gone, id)
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			sessions = append(sessions, sess)
+		}
+		return sessions, gone, nil
+	}
+	sessions, err := w.svc.ListSessions(ctx, w.workspaceID, w.opts.LabelSelectors)
+	if err != nil {
+		return nil, nil, err
+	}
+	present := make(map[string]struct{}, len(sessions))
+	for _, sess := range sessions {
+		present[sess.ID] = struct{}{}
+	}
+	for _, id := range slices.Sorted(maps.Keys(w.state)) {
+		if _, ok := present[id]; !ok {
+			gone = append(gone, id)
+		}
+	}
+	return sessions, gone, nil
+}
+
+// notifications emits the session's notifications created since the last
+// poll.
+func (w *watcher) notifications(ctx context.Context, st *watched) error {
+	if st.seed {
+		latest, err := w.svc.ListSessionNotifications(ctx, w.workspaceID, st.sess.ID, ListSessionNotificationsOptions{Limit: 1, Order: "desc"})
+		if err != nil {
+			return err
+		}
+		st.seed = false
+		if len(latest) > 0 && latest[0].CreatedAt != nil {
+			st.existing = *latest[0].CreatedAt
+			if st.after.IsZero() {
+				st.after = st.existing
+				st.seen[latest[0].ID] = struct{}{}
+			}
+		}
+	}
+	opts := ListSessionNotificationsOptions{Order: "asc"}
+	// A zero cursor (a new session with no notifications yet) means
+	// everything; otherwise the filter is exclusive, so step back a second
+	// so notifications sharing the cursor's (second-precision) timestamp
+	// aren't lost. seen drops the ones already emitted.
+	if !st.after.IsZero() {
+		opts.CreatedAfter = st.after.Add(-time.Second).UTC().Format(time.RFC3339)
+	}
+	notes, err := w.svc.ListSessionNotifications(ctx, w.workspaceID, st.sess.ID, opts)
+	if err != nil {
+		return err
+	}
+	for _, n := range notes {
+		if _, dup := st.seen[n.ID]; dup {
+			continue
+		}
+		if n.CreatedAt != nil {
+			if n.CreatedAt.Before(st.after) {
+				continue
+			}
+			if n.CreatedAt.After(st.after) {
+				st.after = *n.CreatedAt
+				st.seen = make(map[string]struct{})
+			}
+		}
+		st.seen[n.ID] = struct{}{}
+		note := n
+		at := time.Now().UTC()
+		replayed := false
+		if n.CreatedAt != nil {
+			at = *n.CreatedAt
+			replayed = !at.After(st.existing)
+		}
+		w.emit(WatchEvent{Type: WatchEventNotification, Time: at, SessionID: st.sess.ID, SessionName: st.sess.Name, Notification: &note, Replayed: replayed})
+	}
+	return nil
+}
+
+// diffWatchedSession returns the events that take a session from prev to
+// cur.
+func diffWatchedSession(prev, cur Session, now time.Time) []WatchEvent {
+	var events []WatchEvent
+	add := func(typ, from, to string) {
+		events = append(events, WatchEvent{Type: typ, Time: now, SessionID: cur.ID, SessionName: cur.Name, From: from, To: to})
+	}
+	if prev.Status != cur.Status {
+		add(WatchEventStatus, prev.Status, cur.Status)
+	}
+	if prev.AgentSessionStatus != cur.AgentSessionStatus {
+		add(WatchEventAgentStatus, prev.AgentSessionStatus, cur.AgentSessionStatus)
+	}
+	if was, is := SessionSSHReady(prev), SessionSSHReady(cur); was != is {
+		if is {
+			add(WatchEventSSH, SSHUnavailable, SSHReady)
+		} else {
+			add(WatchEventSSH, SSHReady, SSHUnavailable)
+		}
+	}
+	return events
+}
+
+func uniqueStrings(in []string) []string {
+	seen := make(map[string]struct{}, len(in))
+	out := make([]string, 0, len(in))
+	for _, v := range in {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,298 @@
+package rde
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	rdeapi "github.com/bitrise-io/bitrise-cli/bitriseapi/rde"
+)
+
+// TestWatch_EmitsChangesNotificationsAndGone drives a watch through the
+// polls of one session: starting, then (twice) running with SSH and the
+// agent waiting on a permission prompt plus a notification, then deleted.
+func TestWatch_EmitsChangesNotificationsAndGone(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var (
+		mu          sync.Mutex
+		gets        int
+		noteQueries []string
+		// afterNote are the queries made once n1 was served: by then the
+		// cursor is set.
+		afterNote []string
+	)
+	note := `{"id":"n1","title":"Permission needed","type":"SESSION_NOTIFICATION_TYPE_PERMISSION_PROMPT","createdAt":"` +
+		time.Now().Add(time.Minute).UTC().Format(time.RFC3339) + `"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/v1/workspaces/ws-1/sessions/s1":
+			gets++
+			switch gets {
+			case 1:
+				_, _ = io.WriteString(w, `{"session":{"id":"s1","name":"dev","status":"SESSION_STATUS_STARTING"}}`)
+			case 2, 3:
+				_, _ = io.WriteString(w, `{"session":{"id":"s1","name":"dev","status":"SESSION_STATUS_RUNNING",
+					"agentSessionStatus":"AGENT_SESSION_STATUS_WAITING_FOR_PERMISSION",
+					"sshConnectionOpen":true,"sshAddress":"ssh ubuntu@h -p 22"}}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, `{"message":"not found"}`)
+			}
+		case r.URL.Path == "/v1/workspaces/ws-1/sessions/s1/notifications":
+			noteQueries = append(noteQueries, r.URL.RawQuery)
+			if gets >= 3 {
+				afterNote = append(afterNote, r.URL.RawQuery)
+			}
+			if gets >= 2 {
+				// Returned on every later poll too: the watch must not
+				// repeat it.
+				_, _ = io.WriteString(w, `{"notifications":[`+note+`]}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"notifications":[]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	var events []WatchEvent
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := NewService(rdeapi.New(srv.URL, "tok")).Watch(ctx, "ws-1", WatchOptions{
+		SessionIDs: []string{"s1", "s1"},
+		Interval:   time.Millisecond,
+	}, func(ev WatchEvent) { events = append(events, ev) })
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("Watch should end on its own once the only watched session is gone")
+	}
+
+	var got []string
+	for _, ev := range events {
+		got = append(got, ev.Type+":"+ev.From+">"+ev.To)
+	}
+	want := []string{
+		"session:>",
+		"status:starting>running",
+		"agent_status:>waiting_for_permission",
+		"ssh:unavailable>ready",
+		"notification:>",
+		"gone:>",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if n := events[4].Notification; n == nil || n.ID != "n1" || n.Type != "permission_prompt" {
+		t.Errorf("notification event = %+v, want n1", events[4].Notification)
+	}
+	if len(noteQueries) == 0 || !strings.Contains(noteQueries[0], "limit=1") || !strings.Contains(noteQueries[0], "order=SORT_ORDER_DESC") {
+		t.Fatalf("notification queries = %v, want the newest existing one looked up first", noteQueries)
+	}
+	for _, q := range noteQueries[1:] {
+		if !strings.Contains(q, "order=SORT_ORDER_ASC") {
+			t.Errorf("notification query %q should ask for notifications oldest first", q)
+		}
+	}
+	if strings.Contains(noteQueries[1], "createdAfter=") {
+		t.Errorf("notification query %q: with no notification yet there's no cursor to send", noteQueries[1])
+	}
+	if len(afterNote) == 0 {
+		t.Fatal("no notification query after the first notification")
+	}
+	for _, q := range afterNote {
+		if !strings.Contains(q, "createdAfter=") {
+			t.Errorf("notification query %q should ask for newer notifications", q)
+		}
+	}
+}
+
+func TestWatch_NotificationCursorFollowsServerTime(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	// The server's clock runs an hour ahead: a local-time cursor would
+	// take the existing notification for a new one.
+	existing := `{"id":"n0","title":"old","createdAt":"` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}`
+	fresh := `{"id":"n1","title":"new","createdAt":"` + time.Now().Add(2*time.Hour).UTC().Format(time.RFC3339) + `"}`
+	for _, tc := range []struct {
+		name  string
+		since time.Time
+		want  string
+	}{
+		{"no since", time.Time{}, "n1"},
+		{"since replays", time.Now().Add(-time.Hour), "n0(replayed) n1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				mu    sync.Mutex
+				polls int
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				if !strings.HasSuffix(r.URL.Path, "/notifications") {
+					polls++
+					_, _ = io.WriteString(w, `{"session":{"id":"s1","status":"SESSION_STATUS_RUNNING"}}`)
+					return
+				}
+				switch {
+				case r.URL.Query().Get("limit") == "1":
+					_, _ = io.WriteString(w, `{"notifications":[`+existing+`]}`)
+				case polls == 1:
+					_, _ = io.WriteString(w, `{"notifications":[`+existing+`]}`)
+				default:
+					_, _ = io.WriteString(w, `{"notifications":[`+existing+`,`+fresh+`]}`)
+				}
+			}))
+			defer srv.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var got []string
+			err := NewService(rdeapi.New(srv.URL, "tok")).Watch(ctx, "ws-1", WatchOptions{
+				SessionIDs: []string{"s1"},
+				Since:      tc.since,
+				Interval:   time.Millisecond,
+			}, func(ev WatchEvent) {
+				if ev.Type != WatchEventNotification {
+					return
+				}
+				id := ev.Notification.ID
+				if ev.Replayed {
+					id += "(replayed)"
+				}
+				got = append(got, id)
+				if ev.Notification.ID == "n1" {
+					cancel()
+				}
+			})
+			if err != nil {
+				t.Fatalf("Watch: %v", err)
+			}
+			if strings.Join(got, " ") != tc.want {
+				t.Errorf("notifications = %v, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWatch_NewSessionListsNotificationsWithoutCursor(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var (
+		mu           sync.Mutex
+		polls        int
+		createdAfter []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/notifications") {
+			createdAfter = append(createdAfter, r.URL.Query().Get("createdAfter"))
+			_, _ = io.WriteString(w, `{"notifications":[{"id":"n1","title":"hi","createdAt":"2026-10-01T12:00:00Z"}]}`)
+			return
+		}
+		polls++
+		if polls == 1 {
+			_, _ = io.WriteString(w, `{"sessions":[]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"sessions":[{"id":"b","name":"b","status":"SESSION_STATUS_RUNNING"}]}`)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := NewService(rdeapi.New(srv.URL, "tok")).Watch(ctx, "ws-1", WatchOptions{Interval: time.Millisecond}, func(ev WatchEvent) {
+		if ev.Type == WatchEventNotification {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(createdAfter) == 0 || createdAfter[0] != "" {
+		t.Errorf("createdAfter = %q, want none on the new session's first list", createdAfter)
+	}
+}
+
+func TestWatch_FirstPollErrorIsReturned(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `{"message":"nope"}`)
+	}))
+	defer srv.Close()
+	err := NewService(rdeapi.New(srv.URL, "tok")).Watch(context.Background(), "ws-1", WatchOptions{Interval: time.Millisecond}, func(WatchEvent) {
+		t.Error("no event expected")
+	})
+	if err == nil {
+		t.Fatal("expected the first poll's error")
+	}
+}
+
+func TestWatch_ListModeReportsNewAndGoneSessions(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var (
+		mu    sync.Mutex
+		polls int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/notifications") {
+			_, _ = io.WriteString(w, `{"notifications":[]}`)
+			return
+		}
+		if r.URL.RawQuery != "labelSelectors=team%3Dmobile" {
+			t.Errorf("query = %q, want the label selector", r.URL.RawQuery)
+		}
+		polls++
+		if polls == 1 {
+			_, _ = io.WriteString(w, `{"sessions":[{"id":"a","name":"a","status":"SESSION_STATUS_RUNNING"}]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"sessions":[{"id":"b","name":"b","status":"SESSION_STATUS_PENDING"}]}`)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []string
+	err := NewService(rdeapi.New(srv.URL, "tok")).Watch(ctx, "ws-1", WatchOptions{
+		LabelSelectors: []string{"team=mobile"},
+		Interval:       time.Millisecond,
+	}, func(ev WatchEvent) {
+		got = append(got, ev.Type+":"+ev.SessionID)
+		if len(got) == 3 {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if strings.Join(got, " ") != "session:a gone:a session:b" {
+		t.Errorf("events = %v", got)
+	}
+}
+
+func TestAgentAwaitingUser(t *testing.T) {
+	for status, want := range map[string]bool{
+		"waiting_for_permission": true,
+		"awaiting_input":         true,
+		"working":                false,
+		"":                       false,
+	} {
+		if got := AgentAwaitingUser(status); got != want {
+			t.Errorf("AgentAwaitingUser(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/bitrise-io/bitrise-cli/bitriseapi"
+	"github.com/bitrise-io/bitrise-cli/bitriseapi/steplib"
 )
 
 // StepInput describes a single step input or output.
@@ -51,14 +52,17 @@ type InputsResult struct {
 	Items   []StepInput `json:"items"`
 }
 
-// Service exposes step operations to the cmd layer.
+// Service exposes step operations to the cmd layer. Search and Inputs use
+// the Bitrise API; View, Versions and Changelog read the StepLib itself.
+// Either client may be nil when the caller only needs the other.
 type Service struct {
 	client *bitriseapi.Client
+	lib    *steplib.Client
 }
 
-// NewService returns a Service backed by the given API client.
-func NewService(client *bitriseapi.Client) *Service {
-	return &Service{client: client}
+// NewService returns a Service backed by the given API and StepLib clients.
+func NewService(client *bitriseapi.Client, lib *steplib.Client) *Service {
+	return &Service{client: client, lib: lib}
 }
 
 // Search returns steps matching query and filters.
@@ -0,0 +1,279 @@
+package step
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/bitrise-cli/bitriseapi/steplib"
+)
+
+// Version is one published version of a step.
+type Version struct {
+	Version      string    `json:"version"`
+	PublishedAt  time.Time `json:"published_at,omitzero"`
+	IsDeprecated bool      `json:"is_deprecated,omitempty"`
+	ReleaseNotes string    `json:"release_notes,omitempty"`
+}
+
+// Detail is the full metadata of one step version.
+type Detail struct {
+	ID               string      `json:"id"`
+	StepRef          string      `json:"step_ref"`
+	Version          string      `json:"version"`
+	Title            string      `json:"title"`
+	Summary          string      `json:"summary,omitempty"`
+	Description      string      `json:"description,omitempty"`
+	Maintainer       string      `json:"maintainer,omitempty"`
+	SourceCodeURL    string      `json:"source_code_url,omitempty"`
+	SupportURL       string      `json:"support_url,omitempty"`
+	Website          string      `json:"website,omitempty"`
+	Platforms        []string    `json:"platforms,omitempty"`
+	ProjectTypes     []string    `json:"project_types,omitempty"`
+	Categories       []string    `json:"categories,omitempty"`
+	PublishedAt      time.Time   `json:"published_at,omitzero"`
+	IsDeprecated     bool        `json:"is_deprecated,omitempty"`
+	DeprecationNotes string      `json:"deprecation_notes,omitempty"`
+	LatestVersion    string      `json:"latest_version,omitempty"`
+	Inputs           []StepInput `json:"inputs"`
+	Outputs          []StepInput `json:"outputs"`
+	Versions         []Version   `json:"versions"`
+}
+
+// VersionsResult lists a step's versions, newest first.
+type VersionsResult struct {
+	StepID string    `json:"step_id"`
+	Items  []Version `json:"items"`
+}
+
+// ChangelogResult holds the release notes of the versions after From up to
+// and including To, newest first.
+type ChangelogResult struct {
+	StepID string    `json:"step_id"`
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to"`
+	Items  []Version `json:"items"`
+}
+
+// View returns the metadata of a step version together with all published
+// versions of the step. stepRef is STEP_ID or STEP_ID@VERSION; without a
+// version the latest is shown.
+func (s *Service) View(ctx context.Context, stepRef string) (Detail, error) {
+	id, version := splitStepRef(stepRef)
+	if id == "" {
+		return Detail{}, fmt.Errorf("step ID is required")
+	}
+	lib, err := s.library(ctx, id)
+	if err != nil {
+		return Detail{}, err
+	}
+	if version == "" {
+		version = lib.latest
+	} else if !slices.ContainsFunc(lib.versions.Items, func(v Version) bool { return v.Version == version }) {
+		return Detail{}, fmt.Errorf("step %q not found", stepRef)
+	}
+	raw := lib.latestStep
+	if version != lib.latest {
+		if raw, err = s.lib.Step(ctx, id, version); err != nil {
+			return Detail{}, notFound(err, stepRef)
+		}
+	}
+
+	d := Detail{
+		ID:               id,
+		StepRef:          id + "@" + version,
+		Version:          version,
+		Title:            raw.Title,
+		Summary:          raw.Summary,
+		Description:      raw.Description,
+		Maintainer:       lib.info.Maintainer,
+		SourceCodeURL:    raw.SourceCodeURL,
+		SupportURL:       raw.SupportURL,
+		Website:          raw.Website,
+		Platforms:        raw.HostOSTags,
+		ProjectTypes:     raw.ProjectTypeTags,
+		Categories:       raw.TypeTags,
+		PublishedAt:      raw.PublishedAt,
+		IsDeprecated:     lib.deprecated(),
+		DeprecationNotes: lib.info.DeprecateNotes,
+		LatestVersion:    lib.latest,
+		Inputs:           make([]StepInput, 0, len(raw.Inputs)),
+		Outputs:          make([]StepInput, 0, len(raw.Outputs)),
+		Versions:         lib.versions.Items,
+	}
+	for _, in := range raw.Inputs {
+		d.Inputs = append(d.Inputs, inputFromStepLib(in))
+	}
+	for _, out := range raw.Outputs {
+		d.Outputs = append(d.Outputs, inputFromStepLib(out))
+	}
+	return d, nil
+}
+
+// Versions returns every published version of a step, newest first. Release
+// dates and notes come from the GitHub releases of the step's source
+// repository; versions without a release have neither. The StepLib
+// deprecates whole steps, so either every version is deprecated or none is.
+func (s *Service) Versions(ctx context.Context, stepID string) (VersionsResult, error) {
+	if stepID == "" {
+		return VersionsResult{}, fmt.Errorf("step ID is required")
+	}
+	if strings.Contains(stepID, "@") {
+		return VersionsResult{}, fmt.Errorf("expected a step ID without a version, got %q", stepID)
+	}
+	lib, err := s.library(ctx, stepID)
+	if err != nil {
+		return VersionsResult{}, err
+	}
+	return lib.versions, nil
+}
+
+// stepLibrary is what the StepLib knows about one step.
+type stepLibrary struct {
+	versions   VersionsResult
+	latest     string
+	latestStep steplib.Step
+	info       steplib.Info
+}
+
+func (l stepLibrary) deprecated() bool {
+	return l.info.DeprecateNotes != "" || l.info.RemovalDate != ""
+}
+
+// library reads stepID's versions, step-info.yml, latest step.yml, and the
+// releases of its source repository.
+func (s *Service) library(ctx context.Context, stepID string) (stepLibrary, error) {
+	if s.lib == nil {
+		return stepLibrary{}, fmt.Errorf("step library client not configured")
+	}
+	names, err := s.lib.Versions(ctx, stepID)
+	if err != nil {
+		return stepLibrary{}, notFound(err, stepID)
+	}
+	if len(names) == 0 {
+		return stepLibrary{}, fmt.Errorf("step %q has no published versions", stepID)
+	}
+	slices.SortFunc(names, func(a, b string) int { return compareVersions(b, a) })
+	l := stepLibrary{latest: names[0]}
+	if l.info, err = s.lib.Info(ctx, stepID); err != nil {
+		return stepLibrary{}, err
+	}
+	if l.latestStep, err = s.lib.Step(ctx, stepID, l.latest); err != nil {
+		return stepLibrary{}, notFound(err, stepID+"@"+l.latest)
+	}
+	releases, err := s.lib.Releases(ctx, l.latestStep.SourceCodeURL)
+	if err != nil && !steplib.IsNotFound(err) {
+		return stepLibrary{}, fmt.Errorf("read releases of %s: %w", l.latestStep.SourceCodeURL, err)
+	}
+	byTag := make(map[string]steplib.Release, len(releases))
+	for _, r := range releases {
+		byTag[strings.TrimPrefix(r.TagName, "v")] = r
+	}
+
+	l.versions = VersionsResult{StepID: stepID, Items: make([]Version, 0, len(names))}
+	for _, name := range names {
+		r := byTag[name]
+		l.versions.Items = append(l.versions.Items, Version{
+			Version:      name,
+			PublishedAt:  r.PublishedAt,
+			IsDeprecated: l.deprecated(),
+			ReleaseNotes: r.Body,
+		})
+	}
+	return l, nil
+}
+
+func inputFromStepLib(e steplib.EnvItem) StepInput {
+	return StepInput{
+		Name:         e.Key,
+		Title:        e.Opts.Title,
+		Summary:      e.Opts.Summary,
+		Description:  e.Opts.Description,
+		DefaultValue: e.Value,
+		IsRequired:   e.Opts.IsRequired,
+		IsSensitive:  e.Opts.IsSensitive,
+		ValueOptions: e.Opts.ValueOptions,
+	}
+}
+
+// Changelog returns the release notes of the versions after from up to and
+// including to. An empty from starts at the first version; an empty to ends
+// at the latest. Both must name published versions.
+func (s *Service) Changelog(ctx context.Context, stepID, from, to string) (ChangelogResult, error) {
+	versions, err := s.Versions(ctx, stepID)
+	if err != nil {
+		return ChangelogResult{}, err
+	}
+	if len(versions.Items) == 0 {
+		return ChangelogResult{}, fmt.Errorf("step %q has no published versions", stepID)
+	}
+	known := func(v string) bool {
+		return slices.ContainsFunc(versions.Items, func(x Version) bool { return x.Version == v })
+	}
+	for _, v := range []string{from, to} {
+		if v != "" && !known(v) {
+			return ChangelogResult{}, fmt.Errorf("step %s has no version %q (see 'step versions %s')", stepID, v, stepID)
+		}
+	}
+	if to == "" {
+		to = versions.Items[0].Version
+	}
+	if from != "" && compareVersions(from, to) >= 0 {
+		return ChangelogResult{}, fmt.Errorf("version %s is not older than %s", from, to)
+	}
+
+	result := ChangelogResult{StepID: stepID, From: from, To: to, Items: []Version{}}
+	for _, v := range versions.Items {
+		if compareVersions(v.Version, to) > 0 {
+			continue
+		}
+		if from != "" && compareVersions(v.Version, from) <= 0 {
+			break
+		}
+		result.Items = append(result.Items, v)
+	}
+	return result, nil
+}
+
+// splitStepRef splits STEP_ID@VERSION; the version is "" when absent.
+func splitStepRef(ref string) (id, version string) {
+	id, version, _ = strings.Cut(ref, "@")
+	return id, version
+}
+
+// notFound turns a StepLib 404 for what into a readable error.
+func notFound(err error, what string) error {
+	if steplib.IsNotFound(err) {
+		return fmt.Errorf("step %q not found", what)
+	}
+	return err
+}
+
+// compareVersions orders dotted version numbers numerically (1.10.0 after
+// 1.9.2, 1.0 equal to 1.0.0). Non-numeric parts compare as strings.
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range max(len(pa), len(pb)) {
+		x, y := "0", "0"
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		switch {
+		case errX == nil && errY == nil:
+			if c := nx - ny; c != 0 {
+				return c
+			}
+		case x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,206 @@
+// Package token holds the business-logic layer for managing Personal Access
+// Tokens and introspecting the token in use.
+//
+// All methods call the Bitrise API via the bitriseapi client. Token secrets
+// pass through only on Create; the cmd layer decides whether to store them.
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/bitrise-cli/bitriseapi"
+)
+
+// Token is a Personal Access Token's metadata, trimmed to the fields the CLI
+// surfaces. It never carries the secret.
+type Token struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description"`
+	Scopes      []string   `json:"scopes,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Created is a freshly minted token. Token is the secret; the API returns
+// it only once.
+type Created struct {
+	Token
+	Secret string `json:"token"`
+}
+
+// CreateInput is the payload for Create. A nil ExpiresIn leaves the
+// server's default lifetime; a zero one mints a token that never expires.
+type CreateInput struct {
+	Description string
+	ExpiresIn   *time.Duration
+}
+
+// Info describes the token in use: who it belongs to, when it expires and
+// what it may do. Type is "personal" or "workspace".
+type Info struct {
+	Type          string     `json:"type"`
+	ID            string     `json:"id,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	Username      string     `json:"username,omitempty"`
+	Email         string     `json:"email,omitempty"`
+	WorkspaceID   string     `json:"workspace_id,omitempty"`
+	WorkspaceName string     `json:"workspace_name,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Scopes        []string   `json:"scopes,omitempty"`
+}
+
+// Service exposes token management to the cmd layer.
+type Service struct {
+	client *bitriseapi.Client
+}
+
+// NewService returns a Service backed by the given API client. The client
+// must be non-nil — every method in this Service makes a network call.
+func NewService(client *bitriseapi.Client) *Service {
+	return &Service{client: client}
+}
+
+// List returns the authenticated user's Personal Access Tokens.
+// Endpoint: GET /me/access-tokens.
+func (s *Service) List(ctx context.Context) ([]Token, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("API client not configured")
+	}
+	tokens, err := s.client.AccessTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Token, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, fromAPI(t))
+	}
+	return out, nil
+}
+
+// Create mints a new Personal Access Token for the authenticated user.
+// Endpoint: POST /me/access-tokens.
+func (s *Service) Create(ctx context.Context, in CreateInput) (Created, error) {
+	if s.client == nil {
+		return Created{}, fmt.Errorf("API client not configured")
+	}
+	desc := strings.TrimSpace(in.Description)
+	if desc == "" {
+		return Created{}, fmt.Errorf("description is required")
+	}
+	req := bitriseapi.CreateAccessTokenRequest{Description: desc}
+	if d := in.ExpiresIn; d != nil {
+		if *d < 0 {
+			return Created{}, fmt.Errorf("expiry must not be negative")
+		}
+		if *d > 0 && *d < time.Second {
+			return Created{}, fmt.Errorf("expiry must be at least one second")
+		}
+		secs := int64(*d / time.Second)
+		req.ExpiresIn = &secs
+	}
+	c, err := s.client.CreateAccessToken(ctx, req)
+	if err != nil {
+		return Created{}, err
+	}
+	if c.Token == "" {
+		return Created{}, fmt.Errorf("server returned an empty token")
+	}
+	return Created{Token: fromAPI(c.AccessToken), Secret: c.Token}, nil
+}
+
+// Revoke deletes one of the authenticated user's Personal Access Tokens.
+// Endpoint: DELETE /me/access-tokens/{id}.
+func (s *Service) Revoke(ctx context.Context, id string) error {
+	if s.client == nil {
+		return fmt.Errorf("API client not configured")
+	}
+	if id == "" {
+		return fmt.Errorf("token ID is required")
+	}
+	if err := s.client.RevokeAccessToken(ctx, id); err != nil {
+		if apiErr, ok := errors.AsType[*bitriseapi.APIError](err); ok && apiErr.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("access token %q not found (run 'bitrise-cli auth token list' to see token IDs)", id)
+		}
+		return err
+	}
+	return nil
+}
+
+// Current introspects the token the client authenticates with.
+// Endpoint: GET /token-info.
+func (s *Service) Current(ctx context.Context) (Info, error) {
+	if s.client == nil {
+		return Info{}, fmt.Errorf("API client not configured")
+	}
+	ti, err := s.client.TokenInfo(ctx)
+	if err != nil {
+		return Info{}, err
+	}
+	info := Info{
+		Type:        ti.Type,
+		ID:          ti.ID,
+		Description: ti.Description,
+		ExpiresAt:   timePtr(ti.ExpiresAt),
+		Scopes:      ti.Scopes,
+	}
+	if ti.User != nil {
+		info.Username, info.Email = ti.User.Username, ti.User.Email
+	}
+	if ti.Workspace != nil {
+		info.WorkspaceID, info.WorkspaceName = ti.Workspace.Slug, ti.Workspace.Name
+	}
+	return info, nil
+}
+
+// ParseExpiresIn parses a token lifetime: a Go duration ("720h"), a number of
+// days ("90d"), or "never"/"0" for a token that doesn't expire. An empty
+// value is nil: the server's default lifetime.
+func ParseExpiresIn(v string) (*time.Duration, error) {
+	var d time.Duration
+	v = strings.TrimSpace(v)
+	switch v {
+	case "":
+		return nil, nil
+	case "0", "never":
+		return &d, nil
+	}
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid expiry %q: want a positive number of days, e.g. 90d", v)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+		return &d, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return nil, fmt.Errorf("invalid expiry %q: want a duration (720h), days (90d) or \"never\"", v)
+	}
+	return &d, nil
+}
+
+func fromAPI(t bitriseapi.AccessToken) Token {
+	return Token{
+		ID:          t.ID,
+		Description: t.Description,
+		Scopes:      t.Scopes,
+		CreatedAt:   timePtr(t.CreatedAt),
+		ExpiresAt:   timePtr(t.ExpiresAt),
+		LastUsedAt:  timePtr(t.LastUsedAt),
+	}
+}
+
+// timePtr maps the API's zero "not set" time to nil so it drops out of JSON.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,155 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/bitrise-cli/bitriseapi"
+)
+
+func fakeAPI(t *testing.T, handler http.HandlerFunc) *bitriseapi.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return bitriseapi.New(srv.URL, "test-token")
+}
+
+func TestService_List_MapsAPIShape(t *testing.T) {
+	client := fakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/me/access-tokens" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"data":[
+  {"id":"tok-1","description":"ci","scopes":["apps:read"],"created_at":"2026-01-02T03:04:05Z","expires_at":"2026-04-02T03:04:05Z"},
+  {"id":"tok-2","description":"laptop"}
+]}`))
+	})
+
+	got, err := NewService(client).List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "tok-1" || got[0].Scopes[0] != "apps:read" {
+		t.Fatalf("got %+v", got)
+	}
+	if got[0].ExpiresAt == nil || !got[0].ExpiresAt.Equal(time.Date(2026, 4, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("expires_at = %v", got[0].ExpiresAt)
+	}
+	if got[1].ExpiresAt != nil || got[1].CreatedAt != nil {
+		t.Errorf("unset timestamps should stay nil: %+v", got[1])
+	}
+}
+
+func TestService_Create_SendsLifetimeInSeconds(t *testing.T) {
+	never, twoDays := time.Duration(0), 48*time.Hour
+	tests := []struct {
+		name      string
+		expiresIn *time.Duration
+		want      string
+	}{
+		{"unset", nil, `{"description":"ci"}`},
+		{"never", &never, `{"description":"ci","expires_in":0}`},
+		{"48h", &twoDays, `{"description":"ci","expires_in":172800}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			client := fakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/me/access-tokens" {
+					t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+				}
+				body, _ = io.ReadAll(r.Body)
+				_, _ = w.Write([]byte(`{"data":{"id":"tok-3","description":"ci","token":"bitpat_new"}}`))
+			})
+
+			got, err := NewService(client).Create(context.Background(), CreateInput{Description: " ci ", ExpiresIn: tt.expiresIn})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(bytes.TrimSpace(body)) != tt.want {
+				t.Errorf("request = %s, want %s", body, tt.want)
+			}
+			if got.ID != "tok-3" || got.Secret != "bitpat_new" {
+				t.Errorf("got %+v", got)
+			}
+		})
+	}
+}
+
+func TestService_Create_Validates(t *testing.T) {
+	svc := NewService(fakeAPI(t, func(_ http.ResponseWriter, _ *http.Request) {
+		t.Error("no request expected")
+	}))
+	negative := -time.Hour
+	for _, in := range []CreateInput{{Description: " "}, {Description: "ci", ExpiresIn: &negative}} {
+		if _, err := svc.Create(context.Background(), in); err == nil {
+			t.Errorf("Create(%+v): expected an error", in)
+		}
+	}
+}
+
+func TestService_Revoke_NotFound(t *testing.T) {
+	client := fakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/me/access-tokens/tok-9" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+	})
+
+	err := NewService(client).Revoke(context.Background(), "tok-9")
+	if err == nil || !strings.Contains(err.Error(), `"tok-9" not found`) {
+		t.Fatalf("err = %v, want not-found", err)
+	}
+}
+
+func TestService_Current_MapsOwner(t *testing.T) {
+	client := fakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token-info" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"data":{"type":"workspace","id":"wat-1","scopes":["builds:write"],
+  "workspace":{"slug":"ws-1","name":"Acme"}}}`))
+	})
+
+	got, err := NewService(client).Current(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != "workspace" || got.WorkspaceID != "ws-1" || got.WorkspaceName != "Acme" || got.Username != "" || got.ExpiresAt != nil {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestParseExpiresIn(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string // "" for nil
+		wantErr bool
+	}{
+		{"", "", false},
+		{"never", "0s", false},
+		{"0", "0s", false},
+		{"90d", "2160h0m0s", false},
+		{"36h", "36h0m0s", false},
+		{"0d", "", true},
+		{"-1h", "", true},
+		{"soon", "", true},
+	}
+	for _, tt := range tests {
+		d, err := ParseExpiresIn(tt.in)
+		got := ""
+		if d != nil {
+			got = d.String()
+		}
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseExpiresIn(%q) = %s, %v; want %q, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
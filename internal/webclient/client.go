@@ -54,6 +54,13 @@ func (c *Client) WithHTTPClient(hc *http.Client) *Client {
 	return c
 }
 
+// WithTransport sets the RoundTripper of the client's http.Client, keeping
+// its cookie jar. The CLI passes its --debug tracer here.
+func (c *Client) WithTransport(rt http.RoundTripper) *Client {
+	c.httpClient.Transport = rt
+	return c
+}
+
 // metaCSRFRegexp matches the meta tag Rails emits on every page:
 //
 //	<meta name="csrf-token" content="<token>" />
@@ -0,0 +1,54 @@
+package yml
+
+import "testing"
+
+func TestUnifiedDiff_Identical(t *testing.T) {
+	if got := UnifiedDiff("a\nb\n", "a\nb\n", "x", "y"); got != "" {
+		t.Errorf("UnifiedDiff = %q, want empty", got)
+	}
+	if got := UnifiedDiff("a\nb", "a\nb\n", "x", "y"); got != "" {
+		t.Errorf("trailing newline only: UnifiedDiff = %q, want empty", got)
+	}
+}
+
+func TestUnifiedDiff_SingleHunk(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	b := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n"
+	want := `--- old
++++ new
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`
+	if got := UnifiedDiff(a, b, "old", "new"); got != want {
+		t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiff_SeparateHunksAndEdges(t *testing.T) {
+	a := "a\n1\n2\n3\n4\n5\n6\n7\n8\nz\n"
+	b := "A\n1\n2\n3\n4\n5\n6\n7\n8\n"
+	want := `--- old
++++ new
+@@ -1,4 +1,4 @@
+-a
++A
+ 1
+ 2
+ 3
+@@ -7,4 +7,3 @@
+ 6
+ 7
+ 8
+-z
+`
+	if got := UnifiedDiff(a, b, "old", "new"); got != want {
+		t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,241 @@
+package yml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// topLevelOrder is the canonical order of bitrise.yml top-level keys. Keys
+// not listed here keep their relative order after the known ones.
+var topLevelOrder = []string{
+	"format_version",
+	"default_step_lib_source",
+	"project_type",
+	"title",
+	"summary",
+	"description",
+	"include",
+	"app",
+	"meta",
+	"tools",
+	"containers",
+	"services",
+	"trigger_map",
+	"pipelines",
+	"stages",
+	"workflows",
+	"step_bundles",
+}
+
+// envRefRe matches a $NAME or ${NAME} reference inside an env value.
+var envRefRe = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Format returns the canonical layout of the bitrise.yml in data:
+//
+//   - top-level keys in a stable order (format_version first, workflows and
+//     step_bundles last; unknown keys after the known ones);
+//   - two-space indentation throughout;
+//   - step references that carry a version or a source (git-clone@8,
+//     path::./step, …) double-quoted, plain ids (script) unquoted;
+//   - env lists (app, workflow, and step bundle envs) sorted by name, but
+//     only where that can't change behavior: every item defines exactly one
+//     variable, names are unique, and no value references another variable
+//     of the same list.
+//
+// Comments are preserved. Formatting is idempotent: formatting the output
+// again returns it unchanged.
+func Format(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, errors.New("document is empty")
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("top level must be a mapping")
+	}
+
+	// A comment directly above the first key is usually a file header rather
+	// than a note about that key; keep it at the top after reordering.
+	var header string
+	if len(root.Content) > 0 {
+		header, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	sortMappingKeys(root, topLevelOrder)
+	if len(root.Content) > 0 && header != "" {
+		root.Content[0].HeadComment = joinComments(header, root.Content[0].HeadComment)
+	}
+	if app := mappingValue(root, "app"); app != nil {
+		sortEnvs(mappingValue(app, "envs"))
+	}
+	for _, section := range []string{"workflows", "step_bundles"} {
+		forEachMappingValue(mappingValue(root, section), func(def *yaml.Node) {
+			sortEnvs(mappingValue(def, "envs"))
+			normalizeStepRefs(mappingValue(def, "steps"))
+		})
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// mappingValue returns the value node for key in mapping m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func joinComments(a, b string) string {
+	if b == "" {
+		return a
+	}
+	return a + "\n" + b
+}
+
+// forEachMappingValue calls fn for every value of mapping m (nil-safe).
+func forEachMappingValue(m *yaml.Node, fn func(*yaml.Node)) {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 1; i < len(m.Content); i += 2 {
+		fn(m.Content[i])
+	}
+}
+
+// sortMappingKeys reorders the pairs of mapping m so keys in order come first,
+// in that order; the remaining keys keep their relative order.
+func sortMappingKeys(m *yaml.Node, order []string) {
+	type pair struct{ k, v *yaml.Node }
+	pairs := make([]pair, 0, len(m.Content)/2)
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		pairs = append(pairs, pair{m.Content[i], m.Content[i+1]})
+	}
+	rank := func(key string) int {
+		if i := slices.Index(order, key); i >= 0 {
+			return i
+		}
+		return len(order)
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int { return rank(a.k.Value) - rank(b.k.Value) })
+	m.Content = m.Content[:0]
+	for _, p := range pairs {
+		m.Content = append(m.Content, p.k, p.v)
+	}
+}
+
+// normalizeStepRefs gives the step reference keys of a steps list one
+// quoting style: double quotes for refs with a version or a source (any "@"
+// or ":"), which keeps them strings for every consumer that re-parses them,
+// and no quotes for plain ids.
+func normalizeStepRefs(steps *yaml.Node) {
+	if steps == nil || steps.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, item := range steps.Content {
+		if item.Kind != yaml.MappingNode || len(item.Content) != 2 {
+			continue
+		}
+		key := item.Content[0]
+		if key.Kind != yaml.ScalarNode {
+			continue
+		}
+		if strings.ContainsAny(key.Value, "@:") {
+			key.Style = yaml.DoubleQuotedStyle
+		} else {
+			key.Style = 0
+		}
+	}
+}
+
+// sortEnvs sorts an env list by variable name when doing so is safe (see
+// Format); otherwise it leaves the list untouched.
+func sortEnvs(envs *yaml.Node) {
+	if envs == nil || envs.Kind != yaml.SequenceNode || len(envs.Content) < 2 {
+		return
+	}
+	names := make([]string, len(envs.Content))
+	seen := map[string]bool{}
+	for i, item := range envs.Content {
+		name, ok := envName(item)
+		if !ok || seen[name] {
+			return
+		}
+		names[i] = name
+		seen[name] = true
+	}
+	// Bail out if any value refers to a variable defined in the same list:
+	// envs expand in order, so moving one could change what the other sees.
+	for _, item := range envs.Content {
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "opts" {
+				continue
+			}
+			for _, m := range envRefRe.FindAllStringSubmatch(item.Content[i+1].Value, -1) {
+				if seen[m[1]] {
+					return
+				}
+			}
+		}
+	}
+	idx := make([]int, len(envs.Content))
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(a, b int) int {
+		switch {
+		case names[a] < names[b]:
+			return -1
+		case names[a] > names[b]:
+			return 1
+		}
+		return 0
+	})
+	sorted := make([]*yaml.Node, len(idx))
+	for i, j := range idx {
+		sorted[i] = envs.Content[j]
+	}
+	envs.Content = sorted
+}
+
+// envName returns the variable an env list item defines. An item must be a
+// mapping with exactly one key besides the optional opts.
+func envName(item *yaml.Node) (string, bool) {
+	if item.Kind != yaml.MappingNode {
+		return "", false
+	}
+	name := ""
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		k := item.Content[i].Value
+		if k == "opts" {
+			continue
+		}
+		if name != "" {
+			return "", false
+		}
+		name = k
+	}
+	return name, name != ""
+}
@@ -0,0 +1,108 @@
+package yml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat_CanonicalLayout(t *testing.T) {
+	in := `# project config
+workflows:
+  primary:
+    envs:
+    - B: two # keep
+    - A: one
+    steps:
+    - "git-clone@8": {}
+    - 'script@1':
+        inputs:
+        - content: echo hi
+    - path::./steps/local: {}
+    - "script": {}
+app:
+  envs:
+  - Z: $A
+  - A: x
+format_version: "13"
+`
+	want := `# project config
+format_version: "13"
+app:
+  envs:
+    - Z: $A
+    - A: x
+workflows:
+  primary:
+    envs:
+      - A: one
+      - B: two # keep
+    steps:
+      - "git-clone@8": {}
+      - "script@1":
+          inputs:
+            - content: echo hi
+      - "path::./steps/local": {}
+      - script: {}
+`
+	got, err := Format([]byte(in))
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Format =\n%s\nwant\n%s", got, want)
+	}
+
+	again, err := Format(got)
+	if err != nil {
+		t.Fatalf("Format(formatted): %v", err)
+	}
+	if string(again) != string(got) {
+		t.Errorf("Format is not idempotent:\n%s", UnifiedDiff(string(got), string(again), "once", "twice"))
+	}
+}
+
+func TestFormat_LeavesUnsafeEnvListsAlone(t *testing.T) {
+	tests := []struct {
+		name string
+		envs string
+	}{
+		{"reference", "    - B: ${A}/bin\n    - A: x\n"},
+		{"duplicate", "    - B: one\n    - B: two\n    - A: x\n"},
+		{"two keys", "    - B: one\n      C: two\n    - A: x\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := "app:\n  envs:\n" + tt.envs
+			got, err := Format([]byte(in))
+			if err != nil {
+				t.Fatalf("Format: %v", err)
+			}
+			if string(got) != in {
+				t.Errorf("env list reordered:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestFormat_KeepsOptsWithItsEnv(t *testing.T) {
+	in := "app:\n  envs:\n    - B: two\n      opts:\n        is_expand: false\n    - A: one\n"
+	got, err := Format([]byte(in))
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	want := "app:\n  envs:\n    - A: one\n    - B: two\n      opts:\n        is_expand: false\n"
+	if string(got) != want {
+		t.Errorf("Format =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormat_Errors(t *testing.T) {
+	for _, in := range []string{"", "- a\n- b\n", "workflows: [unclosed\n"} {
+		if _, err := Format([]byte(in)); err == nil {
+			t.Errorf("Format(%q) succeeded, want error", in)
+		}
+	}
+	if _, err := Format([]byte("")); err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Errorf("empty document error = %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package yml
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/bitrise-cli/bitriseapi"
+)
+
+// DefaultHistoryBuilds is how many recent builds History scans when the
+// caller doesn't say otherwise. Each scanned build costs one API call.
+const DefaultHistoryBuilds = 50
+
+// revisionIDLen is the length of the short revision ID shown to users.
+const revisionIDLen = 12
+
+// minRevisionPrefix is the shortest revision prefix Show accepts, so a typo
+// doesn't silently pick an arbitrary revision.
+const minRevisionPrefix = 4
+
+// historyPageSize is the builds page size used while scanning.
+const historyPageSize = 50
+
+// Revision is one distinct bitrise.yml seen across an app's recent builds.
+// Two builds share a revision when they ran with byte-identical YAML.
+type Revision struct {
+	ID               string `json:"id"`
+	Hash             string `json:"hash"`
+	FirstBuildNumber int    `json:"first_build_number"`
+	FirstBuildSlug   string `json:"first_build_id"`
+	LastBuildNumber  int    `json:"last_build_number"`
+	LastBuildSlug    string `json:"last_build_id"`
+	BuildCount       int    `json:"build_count"`
+	// IntroducedBy is who triggered the revision's first build. It is empty
+	// when that build is the oldest one scanned and older builds exist, as
+	// the revision may predate the scan.
+	IntroducedBy string    `json:"introduced_by,omitempty"`
+	FirstSeenAt  time.Time `json:"first_seen_at,omitzero"`
+	LastSeenAt   time.Time `json:"last_seen_at,omitzero"`
+
+	content   string
+	firstUser string
+}
+
+// HistoryResult lists the distinct revisions found, newest first.
+type HistoryResult struct {
+	AppSlug       string     `json:"app_id"`
+	BuildsScanned int        `json:"builds_scanned"`
+	Items         []Revision `json:"items"`
+}
+
+// ShowResult is one revision together with its YAML.
+type ShowResult struct {
+	AppSlug  string   `json:"app_id"`
+	Revision Revision `json:"revision"`
+	Content  string   `json:"content"`
+}
+
+// RollbackPlan describes restoring a past revision as the app's stored
+// bitrise.yml. Diff is the unified diff from the current stored YAML to the
+// revision; it is empty when they are already identical.
+type RollbackPlan struct {
+	AppSlug  string   `json:"app_id"`
+	Revision Revision `json:"revision"`
+	Diff     string   `json:"diff"`
+	Applied  bool     `json:"applied"`
+
+	content string
+}
+
+// History scans up to builds of the app's most recent builds (newest first)
+// and groups them by the bitrise.yml each ran with. Builds whose yml isn't
+// available (e.g. aborted before they started) are skipped. builds <= 0
+// scans DefaultHistoryBuilds.
+func (s *Service) History(ctx context.Context, appSlug string, builds int) (HistoryResult, error) {
+	if s.client == nil {
+		return HistoryResult{}, fmt.Errorf("API client not configured")
+	}
+	if appSlug == "" {
+		return HistoryResult{}, fmt.Errorf("app ID is required")
+	}
+	if builds <= 0 {
+		builds = DefaultHistoryBuilds
+	}
+
+	result := HistoryResult{AppSlug: appSlug, Items: []Revision{}}
+	index := map[string]int{}
+	cursor := ""
+	// oldest is the oldest build with a yml; complete is whether the scan
+	// reached the app's first build.
+	var (
+		oldest   string
+		complete bool
+	)
+	for result.BuildsScanned < builds {
+		page, err := s.client.Builds(ctx, appSlug, bitriseapi.BuildsListOptions{
+			Limit: min(builds-result.BuildsScanned, historyPageSize),
+			Next:  cursor,
+		})
+		if err != nil {
+			if apiErr, ok := errors.AsType[*bitriseapi.APIError](err); ok && apiErr.StatusCode == http.StatusNotFound {
+				return HistoryResult{}, fmt.Errorf("app %q not found", appSlug)
+			}
+			return HistoryResult{}, err
+		}
+		cut := false
+		for _, b := range page.Items {
+			if result.BuildsScanned >= builds {
+				cut = true
+				break
+			}
+			result.BuildsScanned++
+			content, err := s.client.BuildBitriseYML(ctx, appSlug, b.Slug)
+			if err != nil {
+				if apiErr, ok := errors.AsType[*bitriseapi.APIError](err); ok && apiErr.StatusCode == http.StatusNotFound {
+					continue
+				}
+				return HistoryResult{}, fmt.Errorf("build #%d: %w", b.BuildNumber, err)
+			}
+			hash := contentHash(content)
+			i, seen := index[hash]
+			if !seen {
+				// Builds arrive newest first, so the first sighting of a
+				// revision is its most recent use.
+				index[hash] = len(result.Items)
+				result.Items = append(result.Items, Revision{
+					ID:              hash[:revisionIDLen],
+					Hash:            hash,
+					LastBuildNumber: b.BuildNumber,
+					LastBuildSlug:   b.Slug,
+					LastSeenAt:      b.TriggeredAt,
+					content:         content,
+				})
+				i = len(result.Items) - 1
+			}
+			rev := &result.Items[i]
+			rev.BuildCount++
+			rev.FirstBuildNumber = b.BuildNumber
+			rev.FirstBuildSlug = b.Slug
+			rev.FirstSeenAt = b.TriggeredAt
+			rev.firstUser = b.TriggeredBy
+			oldest = b.Slug
+		}
+		if !page.Paging.HasMore() || len(page.Items) == 0 {
+			complete = !cut
+			break
+		}
+		cursor = page.Paging.Next
+	}
+	for i := range result.Items {
+		rev := &result.Items[i]
+		if complete || rev.FirstBuildSlug != oldest {
+			rev.IntroducedBy = rev.firstUser
+		}
+	}
+	return result, nil
+}
+
+// Show finds the revision whose ID starts with rev among the app's recent
+// builds (see History) and returns its YAML.
+func (s *Service) Show(ctx context.Context, appSlug, rev string, builds int) (ShowResult, error) {
+	if len(rev) < minRevisionPrefix {
+		return ShowResult{}, fmt.Errorf("revision %q is too short (use at least %d characters of an ID from 'yml history')", rev, minRevisionPrefix)
+	}
+	h, err := s.History(ctx, appSlug, builds)
+	if err != nil {
+		return ShowResult{}, err
+	}
+	found, err := findRevision(h, rev)
+	if err != nil {
+		return ShowResult{}, err
+	}
+	return ShowResult{AppSlug: appSlug, Revision: found, Content: found.content}, nil
+}
+
+// PlanRollback looks up rev like Show and diffs it against the app's current
+// stored bitrise.yml. Nothing is uploaded; pass the plan to Rollback to apply.
+func (s *Service) PlanRollback(ctx context.Context, appSlug, rev string, builds int) (RollbackPlan, error) {
+	shown, err := s.Show(ctx, appSlug, rev, builds)
+	if err != nil {
+		return RollbackPlan{}, err
+	}
+	current, err := s.Get(ctx, appSlug, "")
+	if err != nil {
+		return RollbackPlan{}, err
+	}
+	return RollbackPlan{
+		AppSlug:  appSlug,
+		Revision: shown.Revision,
+		Diff:     UnifiedDiff(current.Content, shown.Content, "current", shown.Revision.ID),
+		content:  shown.Content,
+	}, nil
+}
+
+// Rollback uploads the plan's revision as the app's stored bitrise.yml and
+// returns the plan marked as applied. A plan with an empty diff is a no-op.
+func (s *Service) Rollback(ctx context.Context, plan RollbackPlan) (RollbackPlan, error) {
+	if plan.Diff == "" {
+		return plan, nil
+	}
+	if err := s.Update(ctx, plan.AppSlug, plan.content); err != nil {
+		return RollbackPlan{}, err
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+// findRevision returns the single revision in h whose ID or full hash starts
+// with prefix.
+func findRevision(h HistoryResult, prefix string) (Revision, error) {
+	prefix = strings.ToLower(prefix)
+	var matches []Revision
+	for _, r := range h.Items {
+		if strings.HasPrefix(r.Hash, prefix) {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return Revision{}, fmt.Errorf("revision %q not found in the last %d builds (scan further back with --limit)", prefix, h.BuildsScanned)
+	default:
+		ids := make([]string, len(matches))
+		for i, m := range matches {
+			ids[i] = m.ID
+		}
+		return Revision{}, fmt.Errorf("revision %q is ambiguous: %s", prefix, strings.Join(ids, ", "))
+	}
+}
+
+// contentHash identifies a bitrise.yml revision by the SHA-256 of its text.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
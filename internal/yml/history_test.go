@@ -0,0 +1,160 @@
+package yml
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-cli/bitriseapi"
+)
+
+func fakeAPI(t *testing.T, handler http.HandlerFunc) *bitriseapi.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return bitriseapi.New(srv.URL, "test-token")
+}
+
+const (
+	ymlV1 = "format_version: \"13\"\nworkflows:\n  primary: {}\n"
+	ymlV2 = "format_version: \"13\"\nworkflows:\n  primary: {}\n  deploy: {}\n"
+)
+
+// historyAPI serves five builds, newest first: #5 and #4 ran v2, #3 has no
+// yml, #2 and #1 ran v1. The app's stored yml is v2.
+func historyAPI(t *testing.T, uploaded *string) *bitriseapi.Client {
+	t.Helper()
+	ymls := map[string]string{"b5": ymlV2, "b4": ymlV2, "b2": ymlV1, "b1": ymlV1}
+	return fakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/apps/app/builds":
+			_, _ = io.WriteString(w, `{"data":[
+				{"slug":"b5","build_number":5,"triggered_by":"carol","triggered_at":"2026-05-05T00:00:00Z"},
+				{"slug":"b4","build_number":4,"triggered_by":"bob","triggered_at":"2026-05-04T00:00:00Z"},
+				{"slug":"b3","build_number":3,"triggered_by":"bob","triggered_at":"2026-05-03T00:00:00Z"},
+				{"slug":"b2","build_number":2,"triggered_by":"alice","triggered_at":"2026-05-02T00:00:00Z"},
+				{"slug":"b1","build_number":1,"triggered_by":"dave","triggered_at":"2026-05-01T00:00:00Z"}
+			],"paging":{}}`)
+		case r.URL.Path == "/apps/app/bitrise.yml" && r.Method == http.MethodGet:
+			_, _ = io.WriteString(w, ymlV2)
+		case r.URL.Path == "/apps/app/bitrise.yml" && r.Method == http.MethodPost:
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			b, _ := json.Marshal(body["app_config_datastore_yaml"])
+			*uploaded = string(b)
+			_, _ = io.WriteString(w, `{}`)
+		case strings.HasPrefix(r.URL.Path, "/apps/app/builds/") && strings.HasSuffix(r.URL.Path, "/bitrise.yml"):
+			slug := strings.Split(r.URL.Path, "/")[4]
+			y, ok := ymls[slug]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = io.WriteString(w, y)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+}
+
+func TestHistory_GroupsBuildsByRevision(t *testing.T) {
+	svc := NewService(historyAPI(t, nil))
+
+	h, err := svc.History(context.Background(), "app", 0)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if h.BuildsScanned != 5 {
+		t.Errorf("BuildsScanned = %d, want 5", h.BuildsScanned)
+	}
+	if len(h.Items) != 2 {
+		t.Fatalf("got %d revisions, want 2: %+v", len(h.Items), h.Items)
+	}
+	newest, oldest := h.Items[0], h.Items[1]
+	if newest.Hash != contentHash(ymlV2) || newest.FirstBuildNumber != 4 || newest.LastBuildNumber != 5 || newest.BuildCount != 2 {
+		t.Errorf("newest revision = %+v", newest)
+	}
+	if newest.IntroducedBy != "bob" {
+		t.Errorf("IntroducedBy = %q, want the first build's trigger (bob), not a later one's", newest.IntroducedBy)
+	}
+	if oldest.FirstBuildNumber != 1 || oldest.LastBuildNumber != 2 || len(oldest.ID) != revisionIDLen || oldest.IntroducedBy != "dave" {
+		t.Errorf("oldest revision = %+v", oldest)
+	}
+}
+
+func TestHistory_IntroducedByUnknownPastTheScan(t *testing.T) {
+	svc := NewService(historyAPI(t, nil))
+
+	// Builds 5–3: revision v2 first shows up in #4, the oldest scanned
+	// build with a yml, so it may have been introduced earlier.
+	h, err := svc.History(context.Background(), "app", 3)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(h.Items) != 1 || h.Items[0].FirstBuildNumber != 4 || h.Items[0].IntroducedBy != "" {
+		t.Errorf("revisions = %+v, want v2 with no IntroducedBy", h.Items)
+	}
+}
+
+func TestShow_PrefixLookup(t *testing.T) {
+	svc := NewService(historyAPI(t, nil))
+	id := contentHash(ymlV1)[:6]
+
+	got, err := svc.Show(context.Background(), "app", strings.ToUpper(id), 0)
+	if err != nil {
+		t.Fatalf("Show: %v", err)
+	}
+	if got.Content != ymlV1 {
+		t.Errorf("Content = %q, want v1", got.Content)
+	}
+
+	if _, err := svc.Show(context.Background(), "app", "abc", 0); err == nil || !strings.Contains(err.Error(), "too short") {
+		t.Errorf("short prefix: err = %v", err)
+	}
+	if _, err := svc.Show(context.Background(), "app", "zzzzzz", 0); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("unknown revision: err = %v", err)
+	}
+}
+
+func TestRollback_UploadsRevision(t *testing.T) {
+	var uploaded string
+	svc := NewService(historyAPI(t, &uploaded))
+
+	plan, err := svc.PlanRollback(context.Background(), "app", contentHash(ymlV1)[:8], 0)
+	if err != nil {
+		t.Fatalf("PlanRollback: %v", err)
+	}
+	if !strings.Contains(plan.Diff, "-  deploy: {}") {
+		t.Errorf("diff should remove the deploy workflow:\n%s", plan.Diff)
+	}
+	if uploaded != "" {
+		t.Fatal("PlanRollback must not upload")
+	}
+	plan, err = svc.Rollback(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if !plan.Applied || strings.Contains(uploaded, "deploy") || !strings.Contains(uploaded, "primary") {
+		t.Errorf("applied=%v uploaded=%s", plan.Applied, uploaded)
+	}
+}
+
+func TestRollback_NoopWhenAlreadyCurrent(t *testing.T) {
+	var uploaded string
+	svc := NewService(historyAPI(t, &uploaded))
+
+	plan, err := svc.PlanRollback(context.Background(), "app", contentHash(ymlV2)[:8], 0)
+	if err != nil {
+		t.Fatalf("PlanRollback: %v", err)
+	}
+	if plan.Diff != "" {
+		t.Errorf("Diff = %q, want empty", plan.Diff)
+	}
+	if plan, err = svc.Rollback(context.Background(), plan); err != nil || plan.Applied || uploaded != "" {
+		t.Errorf("Rollback of current revision should be a no-op: applied=%v err=%v uploaded=%q", plan.Applied, err, uploaded)
+	}
+}
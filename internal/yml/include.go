@@ -0,0 +1,310 @@
+package yml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeKey is the top-level bitrise.yml key that lists modules to merge in.
+const includeKey = "include"
+
+// maxIncludeDepth caps how deeply includes may nest (a file including a file
+// including a file …) so a runaway chain fails fast with a clear error.
+const maxIncludeDepth = 5
+
+// MergeResult is a bitrise.yml with its local includes resolved into a single
+// document.
+type MergeResult struct {
+	// Content is the merged YAML. When the root file has no include key it is
+	// the file's original text, untouched, so comments and layout survive.
+	Content string `json:"content"`
+	// Files lists every file that contributed to Content, in merge order: the
+	// included modules first (depth-first), the root file last.
+	Files []string `json:"files"`
+
+	// lines maps a 1-based line of Content to the file and line it came from.
+	// Nil when Content is the root file verbatim (every line maps to itself).
+	lines []SourcePos
+}
+
+// ParseError reports a file in a modular bitrise.yml that is not valid YAML
+// or not shaped like a bitrise.yml.
+type ParseError struct {
+	File string
+	Err  error
+}
+
+func (e *ParseError) Error() string { return "parse " + e.File + ": " + e.Err.Error() }
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// SourcePos is a position in one of the files that make up a merged document.
+type SourcePos struct {
+	File string
+	Line int
+}
+
+// HasIncludes reports whether any module was merged into the root file.
+func (r MergeResult) HasIncludes() bool { return len(r.Files) > 1 }
+
+// Source returns the original file and line for line (1-based) of Content.
+// Lines emitted without a node of their own (e.g. the continuation of a
+// multi-line string) map to the nearest preceding line that has one.
+func (r MergeResult) Source(line int) (SourcePos, bool) {
+	if r.lines == nil {
+		if line < 1 || len(r.Files) == 0 {
+			return SourcePos{}, false
+		}
+		return SourcePos{File: r.Files[len(r.Files)-1], Line: line}, true
+	}
+	if line < 1 {
+		return SourcePos{}, false
+	}
+	for i := min(line, len(r.lines)) - 1; i >= 0; i-- {
+		if r.lines[i].File != "" {
+			return r.lines[i], true
+		}
+	}
+	return SourcePos{}, false
+}
+
+// lineRefRe matches the "line N" references that YAML parse errors and the
+// validation endpoint use to point into the submitted document.
+var lineRefRe = regexp.MustCompile(`\bline (\d+)\b`)
+
+// Remap rewrites every "line N" reference in msg, which points into the merged
+// Content, to "line M of FILE" in the file that line came from. Messages for a
+// document without includes are returned unchanged.
+func (r MergeResult) Remap(msg string) string {
+	if !r.HasIncludes() {
+		return msg
+	}
+	return lineRefRe.ReplaceAllStringFunc(msg, func(m string) string {
+		n, err := strconv.Atoi(lineRefRe.FindStringSubmatch(m)[1])
+		if err != nil {
+			return m
+		}
+		pos, ok := r.Source(n)
+		if !ok {
+			return m
+		}
+		return fmt.Sprintf("line %d of %s", pos.Line, pos.File)
+	})
+}
+
+// Merge resolves the include key of the bitrise.yml in data, whose display
+// name is name. Relative include paths resolve against dir (the directory of
+// the root file, or the working directory for stdin).
+//
+// Each include entry names a module by path; modules may include further
+// modules up to maxIncludeDepth, and include cycles are rejected. Modules are
+// merged in the order listed, then the including file is merged on top:
+// mappings merge key by key (recursively), while sequences and scalars from
+// the later file replace the earlier value outright. Entries that point at
+// another repository are rejected — only local modules are resolved.
+//
+// name is also taken as the root file's path for cycle checks, so a module
+// that includes the root file is rejected; a name that is not a path (such as
+// "<stdin>") never matches an include.
+func Merge(name string, data []byte, dir string) (MergeResult, error) {
+	m := &merger{files: map[*yaml.Node]string{}}
+	var stack []includeFrame
+	if abs, err := filepath.Abs(name); err == nil {
+		stack = []includeFrame{{abs: abs, name: name}}
+	}
+	root, err := m.load(name, data, dir, stack, 0)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	if len(m.order) == 1 {
+		return MergeResult{Content: string(data), Files: m.order}, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return MergeResult{}, fmt.Errorf("encode merged bitrise.yml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return MergeResult{}, fmt.Errorf("encode merged bitrise.yml: %w", err)
+	}
+
+	// Re-parse the encoded output: it has the same shape as root, so walking
+	// both trees in step pairs every output line with the node it came from.
+	var out yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &out); err != nil {
+		return MergeResult{}, fmt.Errorf("re-parse merged bitrise.yml: %w", err)
+	}
+	lines := make([]SourcePos, bytes.Count(buf.Bytes(), []byte("\n"))+1)
+	if len(out.Content) == 1 {
+		m.mapLines(root, out.Content[0], lines)
+	}
+	return MergeResult{Content: buf.String(), Files: m.order, lines: lines}, nil
+}
+
+// merger carries the state of one Merge call.
+type merger struct {
+	// files records which file every loaded node was parsed from.
+	files map[*yaml.Node]string
+	// order lists contributing files in merge order.
+	order []string
+}
+
+// includeFrame is one file on the chain of includes being loaded.
+type includeFrame struct {
+	abs  string // absolute path, compared for cycle checks
+	name string // display name, for errors
+}
+
+// includeEntry is one element of the include list.
+type includeEntry struct {
+	Path       string `yaml:"path"`
+	Repository string `yaml:"repository"`
+	Branch     string `yaml:"branch"`
+	Tag        string `yaml:"tag"`
+	Commit     string `yaml:"commit"`
+}
+
+// load parses data (displayed as name), merges its includes, and returns the
+// resulting root mapping. stack holds the files that led here, this one
+// included, for cycle checks; depth counts the includes above this file.
+func (m *merger) load(name string, data []byte, dir string, stack []includeFrame, depth int) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, &ParseError{File: name, Err: err}
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(doc.Content) == 1 {
+		root = doc.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, &ParseError{File: name, Err: errors.New("top level must be a mapping")}
+	}
+	m.tag(root, name)
+
+	includes, err := takeIncludes(name, root)
+	if err != nil {
+		return nil, err
+	}
+	if len(includes) > 0 && depth >= maxIncludeDepth {
+		return nil, fmt.Errorf("%s: includes nested deeper than %d levels", name, maxIncludeDepth)
+	}
+
+	var merged *yaml.Node
+	for _, inc := range includes {
+		mod, err := m.loadInclude(name, inc, dir, stack, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeNodes(merged, mod)
+	}
+	m.order = append(m.order, name)
+	return mergeNodes(merged, root), nil
+}
+
+// loadInclude reads and loads the module inc refers to.
+func (m *merger) loadInclude(from string, inc includeEntry, dir string, stack []includeFrame, depth int) (*yaml.Node, error) {
+	if inc.Repository != "" {
+		return nil, fmt.Errorf("%s: include %q from repository %s: only local includes are supported", from, inc.Path, inc.Repository)
+	}
+	if inc.Path == "" {
+		return nil, fmt.Errorf("%s: include entry is missing path", from)
+	}
+	p := inc.Path
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return nil, fmt.Errorf("%s: resolve include %q: %w", from, inc.Path, err)
+	}
+	for i, seen := range stack {
+		if seen.abs == abs {
+			chain := make([]string, 0, len(stack)-i+1)
+			for _, f := range stack[i:] {
+				chain = append(chain, f.name)
+			}
+			chain = append(chain, filepath.Clean(p))
+			return nil, fmt.Errorf("%s: include cycle: %s", from, strings.Join(chain, " → "))
+		}
+	}
+	data, err := os.ReadFile(abs) //nolint:gosec // include paths come from the user's own bitrise.yml
+	if err != nil {
+		return nil, fmt.Errorf("%s: read include: %w", from, err)
+	}
+	name := filepath.Clean(p)
+	return m.load(name, data, filepath.Dir(p), append(stack[:len(stack):len(stack)], includeFrame{abs: abs, name: name}), depth)
+}
+
+// takeIncludes removes the include key from root and decodes its entries.
+func takeIncludes(name string, root *yaml.Node) ([]includeEntry, error) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != includeKey {
+			continue
+		}
+		var entries []includeEntry
+		if err := root.Content[i+1].Decode(&entries); err != nil {
+			return nil, &ParseError{File: name, Err: fmt.Errorf("%s: %w", includeKey, err)}
+		}
+		root.Content = append(root.Content[:i], root.Content[i+2:]...)
+		return entries, nil
+	}
+	return nil, nil
+}
+
+// tag records name as the source file of n and all of its descendants.
+func (m *merger) tag(n *yaml.Node, name string) {
+	m.files[n] = name
+	for _, c := range n.Content {
+		m.tag(c, name)
+	}
+}
+
+// mergeNodes merges over onto base and returns the result. Mappings merge key
+// by key, recursing into values present on both sides; anything else from
+// over replaces base. base is modified in place.
+func mergeNodes(base, over *yaml.Node) *yaml.Node {
+	if base == nil {
+		return over
+	}
+	if base.Kind != yaml.MappingNode || over.Kind != yaml.MappingNode {
+		return over
+	}
+	index := make(map[string]int, len(base.Content)/2)
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		index[base.Content[i].Value] = i + 1
+	}
+	for i := 0; i+1 < len(over.Content); i += 2 {
+		key, val := over.Content[i], over.Content[i+1]
+		if j, ok := index[key.Value]; ok {
+			base.Content[j] = mergeNodes(base.Content[j], val)
+			continue
+		}
+		base.Content = append(base.Content, key, val)
+		index[key.Value] = len(base.Content) - 1
+	}
+	return base
+}
+
+// mapLines walks src (the merged tree) and out (its re-parsed encoding) in
+// step, recording the origin of the first node that starts on each line.
+func (m *merger) mapLines(src, out *yaml.Node, lines []SourcePos) {
+	if i := out.Line - 1; i >= 0 && i < len(lines) && lines[i].File == "" {
+		lines[i] = SourcePos{File: m.files[src], Line: src.Line}
+	}
+	if len(src.Content) != len(out.Content) {
+		return
+	}
+	for i := range src.Content {
+		m.mapLines(src.Content[i], out.Content[i], lines)
+	}
+}
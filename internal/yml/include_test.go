@@ -0,0 +1,219 @@
+package yml
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func writeFile(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil { //nolint:gosec // test-only tempdir, perms don't matter
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func mergeFrom(t *testing.T, root string) (MergeResult, error) {
+	t.Helper()
+	data, err := os.ReadFile(root) //nolint:gosec // test-only tempdir path
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Merge(root, data, filepath.Dir(root))
+}
+
+func TestMerge_NoIncludesReturnsVerbatim(t *testing.T) {
+	body := "# keep me\nformat_version: \"13\"\nworkflows:\n  primary: {}\n"
+	got, err := Merge("bitrise.yml", []byte(body), t.TempDir())
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if got.Content != body {
+		t.Errorf("Content = %q, want verbatim %q", got.Content, body)
+	}
+	if got.HasIncludes() {
+		t.Errorf("HasIncludes = true, want false")
+	}
+	if msg := got.Remap("error at line 3"); msg != "error at line 3" {
+		t.Errorf("Remap changed a message without includes: %q", msg)
+	}
+}
+
+func TestMerge_IncludingFileWinsAndMapsMerge(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "ci/workflows.yml", `workflows:
+  test:
+    steps:
+    - script@1: {}
+  deploy:
+    envs:
+    - A: one
+`)
+	root := writeFile(t, dir, "bitrise.yml", `format_version: "13"
+include:
+- path: ci/workflows.yml
+workflows:
+  deploy:
+    envs:
+    - B: two
+`)
+
+	got, err := mergeFrom(t, root)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	var doc struct {
+		FormatVersion string                    `yaml:"format_version"`
+		Include       any                       `yaml:"include"`
+		Workflows     map[string]map[string]any `yaml:"workflows"`
+	}
+	if err := yaml.Unmarshal([]byte(got.Content), &doc); err != nil {
+		t.Fatalf("merged output is not YAML: %v\n%s", err, got.Content)
+	}
+	if doc.Include != nil {
+		t.Errorf("include key should be removed from merged output")
+	}
+	if doc.FormatVersion != "13" {
+		t.Errorf("format_version = %q, want 13", doc.FormatVersion)
+	}
+	if _, ok := doc.Workflows["test"]; !ok {
+		t.Errorf("workflow from module missing: %v", doc.Workflows)
+	}
+	envs, _ := doc.Workflows["deploy"]["envs"].([]any)
+	if len(envs) != 1 || !strings.Contains(got.Content, "B: two") || strings.Contains(got.Content, "A: one") {
+		t.Errorf("list from including file should replace the module's list:\n%s", got.Content)
+	}
+	wantFiles := []string{filepath.Join(dir, "ci/workflows.yml"), root}
+	if strings.Join(got.Files, ",") != strings.Join(wantFiles, ",") {
+		t.Errorf("Files = %v, want %v", got.Files, wantFiles)
+	}
+}
+
+func TestMerge_RemapPointsIntoModule(t *testing.T) {
+	dir := t.TempDir()
+	mod := writeFile(t, dir, "mod.yml", "workflows:\n  test:\n    title: Test\n")
+	root := writeFile(t, dir, "bitrise.yml", "format_version: \"13\"\ninclude:\n- path: mod.yml\n")
+
+	got, err := mergeFrom(t, root)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	line := 0
+	for i, l := range strings.Split(got.Content, "\n") {
+		if strings.Contains(l, "title: Test") {
+			line = i + 1
+		}
+	}
+	if line == 0 {
+		t.Fatalf("merged output missing module content:\n%s", got.Content)
+	}
+	pos, ok := got.Source(line)
+	if !ok || pos.File != mod || pos.Line != 3 {
+		t.Errorf("Source(%d) = %+v, %v; want %s line 3", line, pos, ok, mod)
+	}
+	msg := got.Remap("invalid title at line " + strconv.Itoa(line))
+	if want := "invalid title at line 3 of " + mod; msg != want {
+		t.Errorf("Remap = %q, want %q", msg, want)
+	}
+}
+
+func TestMerge_NestedIncludesResolveRelativeToModule(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a/b/leaf.yml", "app:\n  envs:\n  - LEAF: yes\n")
+	writeFile(t, dir, "a/mid.yml", "include:\n- path: b/leaf.yml\nworkflows:\n  mid: {}\n")
+	root := writeFile(t, dir, "bitrise.yml", "include:\n- path: a/mid.yml\n")
+
+	got, err := mergeFrom(t, root)
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if !strings.Contains(got.Content, "LEAF") || !strings.Contains(got.Content, "mid:") {
+		t.Errorf("nested module content missing:\n%s", got.Content)
+	}
+	if len(got.Files) != 3 {
+		t.Errorf("Files = %v, want 3 entries", got.Files)
+	}
+}
+
+func TestMerge_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "missing module",
+			files: map[string]string{"bitrise.yml": "include:\n- path: nope.yml\n"},
+			want:  "read include",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"bitrise.yml": "include:\n- path: a.yml\n",
+				"a.yml":       "include:\n- path: b.yml\n",
+				"b.yml":       "include:\n- path: a.yml\n",
+			},
+			want: "include cycle",
+		},
+		{
+			name:  "repository include",
+			files: map[string]string{"bitrise.yml": "include:\n- path: x.yml\n  repository: org/shared\n"},
+			want:  "only local includes",
+		},
+		{
+			name: "malformed module",
+			files: map[string]string{
+				"bitrise.yml": "include:\n- path: bad.yml\n",
+				"bad.yml":     "workflows: [unclosed\n",
+			},
+			want: "bad.yml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, body := range tt.files {
+				writeFile(t, dir, name, body)
+			}
+			_, err := mergeFrom(t, filepath.Join(dir, "bitrise.yml"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge_CycleThroughRoot(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "bitrise.yml", "include:\n- path: a.yml\n")
+	writeFile(t, dir, "a.yml", "include:\n- path: bitrise.yml\n")
+	root, a := filepath.Join(dir, "bitrise.yml"), filepath.Join(dir, "a.yml")
+
+	_, err := mergeFrom(t, root)
+	want := a + ": include cycle: " + root + " → " + a + " → " + root
+	if err == nil || err.Error() != want {
+		t.Errorf("err = %v, want %q", err, want)
+	}
+}
+
+func TestMerge_DepthLimit(t *testing.T) {
+	dir := t.TempDir()
+	for i := range maxIncludeDepth + 1 {
+		writeFile(t, dir, "m"+strconv.Itoa(i)+".yml", "include:\n- path: m"+strconv.Itoa(i+1)+".yml\n")
+	}
+	writeFile(t, dir, "m"+strconv.Itoa(maxIncludeDepth+1)+".yml", "workflows: {}\n")
+
+	_, err := mergeFrom(t, filepath.Join(dir, "m0.yml"))
+	if err == nil || !strings.Contains(err.Error(), "nested deeper") {
+		t.Errorf("err = %v, want depth error", err)
+	}
+}
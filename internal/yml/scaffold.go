@@ -0,0 +1,470 @@
+package yml
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bitrise-io/bitrise-cli/bitriseapi"
+)
+
+// Scaffold project types, in detection priority order. Flutter and React
+// Native come first because their checkouts also contain native iOS and
+// Android projects.
+const (
+	ProjectFlutter     = "flutter"
+	ProjectReactNative = "react-native"
+	ProjectIOS         = "ios"
+	ProjectAndroid     = "android"
+	ProjectFastlane    = "fastlane"
+	ProjectNode        = "node"
+	ProjectOther       = "other"
+)
+
+// ScaffoldProjectTypes lists the project types Scaffold has a template for.
+var ScaffoldProjectTypes = []string{
+	ProjectFlutter, ProjectReactNative, ProjectIOS, ProjectAndroid, ProjectFastlane, ProjectNode, ProjectOther,
+}
+
+// detectMaxDepth is how many directory levels below the project root Detect
+// looks into.
+const detectMaxDepth = 3
+
+// skipDirs are never descended into while detecting: they hold dependencies
+// or build output whose project files would be misleading.
+var skipDirs = map[string]bool{
+	"node_modules": true, "Pods": true, "Carthage": true, "build": true,
+	"DerivedData": true, "vendor": true,
+}
+
+// Project is what Detect found in a local checkout. Paths are relative to the
+// scanned directory, with forward slashes.
+type Project struct {
+	Type string `json:"type"`
+	// Marker is the file that identified Type.
+	Marker string `json:"marker,omitempty"`
+	// AlsoDetected lists other project types found in the same checkout.
+	AlsoDetected []string `json:"also_detected,omitempty"`
+
+	xcodeProject string // .xcworkspace, .xcodeproj, or Package.swift
+	cocoaPods    bool
+	gradleDir    string // directory holding gradlew
+	flutterDir   string
+	packageDir   string // directory holding package.json
+	yarn         bool
+	fastlaneDir  string // directory holding the fastlane/ folder
+	markers      map[string]string
+}
+
+// WithType returns p scaffolded as typ instead of the detected type, e.g.
+// to pick the Android app in a checkout that also has an iOS one.
+func (p Project) WithType(typ string) Project {
+	p.Type, p.Marker, p.AlsoDetected = typ, p.markers[typ], nil
+	return p
+}
+
+// ScaffoldResult is a proposed bitrise.yml for a detected project.
+type ScaffoldResult struct {
+	Project  Project  `json:"project"`
+	Content  string   `json:"content"`
+	Warnings []string `json:"warnings"`
+}
+
+// Detect inspects the checkout at dir for Gradle, Xcode (workspace, project,
+// or Swift package), Flutter, React Native, Node, and fastlane files and
+// returns the most specific project type found. An unrecognized checkout
+// yields ProjectOther.
+func Detect(dir string) (Project, error) {
+	var (
+		p     Project
+		found = map[string]string{} // type → marker, shallowest wins
+	)
+	note := func(typ, marker string) {
+		if _, ok := found[typ]; !ok {
+			found[typ] = marker
+		}
+	}
+
+	// Breadth-first so the shallowest match of each kind wins.
+	level := []string{"."}
+	for depth := 0; depth <= detectMaxDepth && len(level) > 0; depth++ {
+		var next []string
+		for _, rel := range level {
+			entries, err := os.ReadDir(filepath.Join(dir, rel))
+			if err != nil {
+				if rel == "." {
+					return Project{}, err
+				}
+				continue
+			}
+			for _, e := range entries {
+				sub := filepath.ToSlash(filepath.Join(rel, e.Name()))
+				if e.IsDir() {
+					name := e.Name()
+					switch {
+					case name == "fastlane":
+						if fileExists(filepath.Join(dir, sub, "Fastfile")) && p.fastlaneDir == "" {
+							p.fastlaneDir = rel
+							note(ProjectFastlane, sub+"/Fastfile")
+						}
+					case strings.HasSuffix(name, ".xcworkspace"):
+						// Bundles are opaque; a workspace beats a bare project.
+						if !strings.HasSuffix(p.xcodeProject, ".xcworkspace") {
+							p.xcodeProject = sub
+						}
+						note(ProjectIOS, sub)
+					case strings.HasSuffix(name, ".xcodeproj"):
+						if p.xcodeProject == "" {
+							p.xcodeProject = sub
+						}
+						note(ProjectIOS, sub)
+					case !strings.HasPrefix(name, ".") && !skipDirs[name]:
+						next = append(next, sub)
+					}
+					continue
+				}
+				detectFile(dir, rel, sub, e.Name(), &p, note)
+			}
+		}
+		level = next
+	}
+
+	if p.xcodeProject != "" {
+		found[ProjectIOS] = p.xcodeProject // the workspace, if there is one
+	}
+	p.markers = found
+	for _, typ := range ScaffoldProjectTypes {
+		marker, ok := found[typ]
+		if !ok {
+			continue
+		}
+		if p.Type == "" {
+			p.Type, p.Marker = typ, marker
+			continue
+		}
+		if implied(p.Type, typ) {
+			continue
+		}
+		p.AlsoDetected = append(p.AlsoDetected, typ)
+	}
+	if p.Type == "" {
+		p.Type = ProjectOther
+	}
+	if p.xcodeProject != "" {
+		p.cocoaPods = fileExists(filepath.Join(dir, filepath.Dir(p.xcodeProject), "Podfile"))
+	}
+	if p.packageDir != "" {
+		p.yarn = fileExists(filepath.Join(dir, p.packageDir, "yarn.lock"))
+	}
+	return p, nil
+}
+
+// detectFile records what a single regular file says about the project.
+func detectFile(dir, rel, sub, name string, p *Project, note func(typ, marker string)) {
+	switch name {
+	case "pubspec.yaml":
+		if fileContains(filepath.Join(dir, sub), "flutter:") && p.flutterDir == "" {
+			p.flutterDir = rel
+			note(ProjectFlutter, sub)
+		}
+	case "package.json":
+		if p.packageDir == "" {
+			p.packageDir = rel
+		}
+		if fileContains(filepath.Join(dir, sub), `"react-native"`) {
+			note(ProjectReactNative, sub)
+		}
+		note(ProjectNode, sub)
+	case "Package.swift":
+		if p.xcodeProject == "" {
+			p.xcodeProject = sub
+		}
+		note(ProjectIOS, sub)
+	case "gradlew":
+		if p.gradleDir == "" {
+			p.gradleDir = rel
+		}
+		note(ProjectAndroid, sub)
+	}
+}
+
+// implied reports whether other is just part of a primary project (the
+// native shells of a Flutter or React Native app, or its package.json).
+func implied(primary, other string) bool {
+	switch primary {
+	case ProjectFlutter:
+		return other == ProjectIOS || other == ProjectAndroid
+	case ProjectReactNative:
+		return other == ProjectIOS || other == ProjectAndroid || other == ProjectNode
+	}
+	return false
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func fileContains(path, s string) bool {
+	data, err := os.ReadFile(path) //nolint:gosec // path is inside the checkout being scanned
+	return err == nil && bytes.Contains(data, []byte(s))
+}
+
+// scaffoldStep is one step of a scaffolded workflow, referenced by step ID
+// until its version is resolved.
+type scaffoldStep struct {
+	id     string
+	inputs [][2]string
+}
+
+type scaffoldWorkflow struct {
+	name    string
+	summary string
+	steps   []scaffoldStep
+}
+
+// Scaffold proposes a bitrise.yml for p. Unless offline is set, each step is
+// pinned to the major version of its latest release in the step library;
+// offline (or for steps the library doesn't know) steps are left unversioned
+// and resolve to the latest version at build time.
+func (s *Service) Scaffold(ctx context.Context, p Project, offline bool) (ScaffoldResult, error) {
+	if !offline && s.client == nil {
+		return ScaffoldResult{}, fmt.Errorf("API client not configured")
+	}
+	if !slices.Contains(ScaffoldProjectTypes, p.Type) {
+		return ScaffoldResult{}, fmt.Errorf("unsupported project type %q (valid: %s)", p.Type, strings.Join(ScaffoldProjectTypes, ", "))
+	}
+	envs, workflows := scaffoldTemplate(p)
+
+	result := ScaffoldResult{Project: p, Warnings: []string{}}
+	if p.Marker == "" && p.Type != ProjectOther {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("no %s project files found; review the app envs before use", p.Type))
+	}
+	refs := map[string]string{}
+	for _, wf := range workflows {
+		for _, st := range wf.steps {
+			if _, ok := refs[st.id]; ok {
+				continue
+			}
+			refs[st.id] = st.id
+			if offline {
+				continue
+			}
+			version, err := s.latestStepVersion(ctx, st.id)
+			if err != nil {
+				return ScaffoldResult{}, fmt.Errorf("look up step %s: %w", st.id, err)
+			}
+			if version == "" {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("step %s not found in the step library; left unversioned", st.id))
+				continue
+			}
+			major, _, _ := strings.Cut(version, ".")
+			refs[st.id] = st.id + "@" + major
+		}
+	}
+
+	content, err := renderScaffold(p, envs, workflows, refs)
+	if err != nil {
+		return ScaffoldResult{}, err
+	}
+	result.Content = content
+	return result, nil
+}
+
+// latestStepVersion returns the latest version of the step with the given
+// ID, or "" when the step library has no such step.
+func (s *Service) latestStepVersion(ctx context.Context, id string) (string, error) {
+	steps, err := s.client.SearchSteps(ctx, bitriseapi.StepSearchOptions{Query: id})
+	if err != nil {
+		return "", err
+	}
+	for _, st := range steps {
+		if st.ID != id {
+			continue
+		}
+		if st.LatestVersionNumber != "" {
+			return st.LatestVersionNumber, nil
+		}
+		return st.Version, nil
+	}
+	return "", nil
+}
+
+// scaffoldTemplate returns the app envs and workflows proposed for p.
+func scaffoldTemplate(p Project) ([][2]string, []scaffoldWorkflow) {
+	var (
+		envs          [][2]string
+		test, release []scaffoldStep
+	)
+	withDir := func(st scaffoldStep, input, dir string) scaffoldStep {
+		if dir != "" && dir != "." {
+			st.inputs = append(st.inputs, [2]string{input, "$BITRISE_SOURCE_DIR/" + dir})
+		}
+		return st
+	}
+
+	switch p.Type {
+	case ProjectIOS:
+		envs = [][2]string{
+			{"BITRISE_PROJECT_PATH", p.xcodeProject},
+			{"BITRISE_SCHEME", xcodeScheme(p.xcodeProject)},
+		}
+		xcode := [][2]string{{"project_path", "$BITRISE_PROJECT_PATH"}, {"scheme", "$BITRISE_SCHEME"}}
+		var restore, save []scaffoldStep
+		switch {
+		case p.cocoaPods:
+			restore = []scaffoldStep{{id: "restore-cocoapods-cache"}, {id: "cocoapods-install"}}
+			save = []scaffoldStep{{id: "save-cocoapods-cache"}}
+		default:
+			restore = []scaffoldStep{{id: "restore-spm-cache"}}
+			save = []scaffoldStep{{id: "save-spm-cache"}}
+		}
+		test = slices.Concat(restore, []scaffoldStep{{id: "xcode-test", inputs: xcode}}, save)
+		release = slices.Concat(restore, []scaffoldStep{{
+			id:     "xcode-archive",
+			inputs: append(slices.Clone(xcode), [2]string{"distribution_method", "development"}),
+		}})
+	case ProjectAndroid:
+		envs = [][2]string{
+			{"PROJECT_LOCATION", cmp.Or(p.gradleDir, ".")},
+			{"MODULE", "app"},
+			{"VARIANT", "debug"},
+		}
+		test = []scaffoldStep{
+			{id: "restore-gradle-cache"},
+			{id: "android-unit-test", inputs: [][2]string{{"project_location", "$PROJECT_LOCATION"}, {"variant", "$VARIANT"}}},
+			{id: "save-gradle-cache"},
+		}
+		release = []scaffoldStep{
+			{id: "restore-gradle-cache"},
+			{id: "android-build", inputs: [][2]string{{"project_location", "$PROJECT_LOCATION"}, {"module", "$MODULE"}, {"variant", "release"}}},
+		}
+	case ProjectFlutter:
+		envs = [][2]string{{"BITRISE_FLUTTER_PROJECT_LOCATION", cmp.Or(p.flutterDir, ".")}}
+		loc := [][2]string{{"project_location", "$BITRISE_FLUTTER_PROJECT_LOCATION"}}
+		test = []scaffoldStep{
+			{id: "restore-dart-cache"},
+			{id: "flutter-analyze", inputs: loc},
+			{id: "flutter-test", inputs: loc},
+			{id: "save-dart-cache"},
+		}
+		release = []scaffoldStep{
+			{id: "restore-dart-cache"},
+			{id: "flutter-build", inputs: append(slices.Clone(loc), [2]string{"platform", "both"})},
+		}
+	case ProjectReactNative, ProjectNode:
+		tool := "npm"
+		if p.yarn {
+			tool = "yarn"
+		}
+		test = []scaffoldStep{
+			{id: "restore-npm-cache"},
+			withDir(scaffoldStep{id: tool, inputs: [][2]string{{"command", "install"}}}, "workdir", p.packageDir),
+			withDir(scaffoldStep{id: tool, inputs: [][2]string{{"command", "test"}}}, "workdir", p.packageDir),
+			{id: "save-npm-cache"},
+		}
+	case ProjectFastlane:
+		test = []scaffoldStep{withDir(scaffoldStep{id: "fastlane", inputs: [][2]string{{"lane", "test"}}}, "work_dir", p.fastlaneDir)}
+	default:
+		test = []scaffoldStep{{id: "script", inputs: [][2]string{{"content", "#!/usr/bin/env bash\nset -euxo pipefail\n\necho \"Add your build commands here\"\n"}}}}
+	}
+
+	checkout := []scaffoldStep{{id: "activate-ssh-key"}, {id: "git-clone"}}
+	deploy := []scaffoldStep{{id: "deploy-to-bitrise-io"}}
+	workflows := []scaffoldWorkflow{{
+		name:    "primary",
+		summary: "Run tests on every push and pull request.",
+		steps:   slices.Concat(checkout, test, deploy),
+	}}
+	if release != nil {
+		workflows = append(workflows, scaffoldWorkflow{
+			name:    "deploy",
+			summary: "Build a release artifact and make it available on Bitrise.",
+			steps:   slices.Concat(checkout, release, deploy),
+		})
+	}
+	return envs, workflows
+}
+
+// xcodeScheme guesses the shared scheme from the project file name; it is
+// the Xcode default for new projects and packages.
+func xcodeScheme(project string) string {
+	if strings.HasSuffix(project, "Package.swift") {
+		return filepath.Base(filepath.Dir(filepath.Join("/", project)))
+	}
+	return strings.TrimSuffix(filepath.Base(project), filepath.Ext(project))
+}
+
+// renderScaffold builds the bitrise.yml document for a template.
+func renderScaffold(p Project, envs [][2]string, workflows []scaffoldWorkflow, refs map[string]string) (string, error) {
+	projectType := p.Type
+	if projectType == ProjectNode || projectType == ProjectFastlane {
+		projectType = ProjectOther
+	}
+
+	root := mapNode(
+		strNode("format_version"), strNode("13"),
+		strNode("default_step_lib_source"), strNode("https://github.com/bitrise-io/bitrise-steplib.git"),
+		strNode("project_type"), strNode(projectType),
+	)
+	root.Content[0].HeadComment = "Generated by 'bitrise-cli yml init'. Review before committing, then\ncheck it with 'bitrise-cli yml validate --file bitrise.yml'."
+	if len(envs) > 0 {
+		list := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, e := range envs {
+			list.Content = append(list.Content, mapNode(strNode(e[0]), strNode(e[1])))
+		}
+		root.Content = append(root.Content, strNode("app"), mapNode(strNode("envs"), list))
+	}
+	wfs := &yaml.Node{Kind: yaml.MappingNode}
+	for _, wf := range workflows {
+		steps := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, st := range wf.steps {
+			body := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+			if len(st.inputs) > 0 {
+				inputs := &yaml.Node{Kind: yaml.SequenceNode}
+				for _, in := range st.inputs {
+					v := strNode(in[1])
+					if strings.Contains(in[1], "\n") {
+						v.Style = yaml.LiteralStyle
+					}
+					inputs.Content = append(inputs.Content, mapNode(strNode(in[0]), v))
+				}
+				body = mapNode(strNode("inputs"), inputs)
+			}
+			steps.Content = append(steps.Content, mapNode(strNode(refs[st.id]), body))
+		}
+		wfs.Content = append(wfs.Content, strNode(wf.name), mapNode(strNode("summary"), strNode(wf.summary), strNode("steps"), steps))
+	}
+	root.Content = append(root.Content, strNode("workflows"), wfs)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return "", fmt.Errorf("encode: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("encode: %w", err)
+	}
+	formatted, err := Format(buf.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
+
+func strNode(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+}
+
+// mapNode builds a mapping node from alternating key and value nodes.
+func mapNode(content ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Content: content}
+}
@@ -0,0 +1,162 @@
+package yml
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		dirs   []string
+		want   string
+		marker string
+		also   []string
+	}{
+		{
+			name:   "flutter hides its native shells",
+			files:  map[string]string{"pubspec.yaml": "name: app\nflutter:\n  uses-material-design: true\n", "android/gradlew": ""},
+			dirs:   []string{"ios/Runner.xcworkspace"},
+			want:   ProjectFlutter,
+			marker: "pubspec.yaml",
+		},
+		{
+			name:   "react native",
+			files:  map[string]string{"package.json": `{"dependencies":{"react-native":"0.74.0"}}`, "android/gradlew": ""},
+			want:   ProjectReactNative,
+			marker: "package.json",
+		},
+		{
+			name:   "ios with android alongside",
+			files:  map[string]string{"android/gradlew": ""},
+			dirs:   []string{"ios/App.xcworkspace", "ios/App.xcodeproj"},
+			want:   ProjectIOS,
+			marker: "ios/App.xcworkspace",
+			also:   []string{ProjectAndroid},
+		},
+		{
+			name:   "swift package",
+			files:  map[string]string{"Package.swift": "// swift-tools-version:5.9\n"},
+			want:   ProjectIOS,
+			marker: "Package.swift",
+		},
+		{
+			name:   "dependencies are ignored",
+			files:  map[string]string{"node_modules/x/package.json": "{}", "fastlane/Fastfile": "lane :test do\nend\n"},
+			want:   ProjectFastlane,
+			marker: "fastlane/Fastfile",
+		},
+		{
+			name: "nothing recognizable",
+			want: ProjectOther,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, body := range tt.files {
+				writeFile(t, dir, name, body)
+			}
+			for _, d := range tt.dirs {
+				if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil { //nolint:gosec // test-only tempdir, perms don't matter
+					t.Fatal(err)
+				}
+			}
+			p, err := Detect(dir)
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+			if p.Type != tt.want || p.Marker != tt.marker || !slices.Equal(p.AlsoDetected, tt.also) {
+				t.Errorf("Detect = %s (%s, also %v), want %s (%s, also %v)", p.Type, p.Marker, p.AlsoDetected, tt.want, tt.marker, tt.also)
+			}
+		})
+	}
+}
+
+func TestScaffold_PinsMajorVersions(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "gradlew", "")
+	p, err := Detect(dir)
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+
+	client := fakeAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("query")
+		if id == "save-gradle-cache" {
+			_, _ = w.Write([]byte("[]"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"id": id + "-extra", "version": "99.0.0"},
+			{"id": id, "latest_version_number": "8.3.1"},
+		})
+	})
+	got, err := NewService(client).Scaffold(context.Background(), p, false)
+	if err != nil {
+		t.Fatalf("Scaffold: %v", err)
+	}
+
+	var doc struct {
+		ProjectType string `yaml:"project_type"`
+		Workflows   map[string]struct {
+			Steps []map[string]any `yaml:"steps"`
+		} `yaml:"workflows"`
+	}
+	if err := yaml.Unmarshal([]byte(got.Content), &doc); err != nil {
+		t.Fatalf("scaffold is not YAML: %v\n%s", err, got.Content)
+	}
+	if doc.ProjectType != ProjectAndroid {
+		t.Errorf("project_type = %q", doc.ProjectType)
+	}
+	var refs []string
+	for _, st := range doc.Workflows["primary"].Steps {
+		for ref := range st {
+			refs = append(refs, ref)
+		}
+	}
+	want := []string{"activate-ssh-key@8", "git-clone@8", "restore-gradle-cache@8", "android-unit-test@8", "save-gradle-cache", "deploy-to-bitrise-io@8"}
+	if !slices.Equal(refs, want) {
+		t.Errorf("primary steps = %v, want %v", refs, want)
+	}
+	if _, ok := doc.Workflows["deploy"]; !ok {
+		t.Errorf("android scaffold has no deploy workflow:\n%s", got.Content)
+	}
+	if len(got.Warnings) != 1 || !strings.Contains(got.Warnings[0], "save-gradle-cache") {
+		t.Errorf("Warnings = %v, want one for the unknown step", got.Warnings)
+	}
+
+	if again, err := Format([]byte(got.Content)); err != nil || string(again) != got.Content {
+		t.Errorf("scaffold is not in fmt layout (err %v)", err)
+	}
+}
+
+func TestScaffold_OfflineAndOverride(t *testing.T) {
+	p, err := Detect(t.TempDir())
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	got, err := NewService(nil).Scaffold(context.Background(), p.WithType(ProjectIOS), true)
+	if err != nil {
+		t.Fatalf("Scaffold: %v", err)
+	}
+	if !strings.Contains(got.Content, "- xcode-test:") {
+		t.Errorf("offline scaffold should leave steps unversioned:\n%s", got.Content)
+	}
+	if len(got.Warnings) != 1 || !strings.Contains(got.Warnings[0], "no ios project files") {
+		t.Errorf("Warnings = %v", got.Warnings)
+	}
+
+	if _, err := NewService(nil).Scaffold(context.Background(), p.WithType("cobol"), true); err == nil {
+		t.Error("expected error for unknown project type")
+	}
+}
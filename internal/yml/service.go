@@ -24,6 +24,9 @@ type ValidateResult struct {
 	Valid    bool     `json:"valid"`
 	Errors   []string `json:"errors"`
 	Warnings []string `json:"warnings"`
+	// Files lists the files merged into the validated document; set only
+	// when the bitrise.yml includes other modules.
+	Files []string `json:"files,omitempty"`
 }
 
 // Service exposes bitrise.yml operations to the cmd layer.
@@ -120,3 +123,37 @@ func (s *Service) Validate(ctx context.Context, rawYAML, appSlug string) (Valida
 		Warnings: warns,
 	}, nil
 }
+
+// ValidateModular resolves the includes of the bitrise.yml in data (see
+// Merge for name and dir) and validates the merged document. Line references
+// in the returned errors and warnings point into the original files rather
+// than the merged document.
+//
+// A root document that doesn't parse is sent as-is, so the API reports it the
+// same way it reports any invalid bitrise.yml. Problems resolving the includes
+// themselves (a missing or malformed module, a cycle) are reported as an
+// invalid result without calling the API.
+func (s *Service) ValidateModular(ctx context.Context, name string, data []byte, dir, appSlug string) (ValidateResult, error) {
+	merged, err := Merge(name, data, dir)
+	if pe, ok := errors.AsType[*ParseError](err); ok && pe.File == name {
+		return s.Validate(ctx, string(data), appSlug)
+	}
+	if err != nil {
+		return ValidateResult{Valid: false, Errors: []string{err.Error()}, Warnings: []string{}}, nil
+	}
+	result, err := s.Validate(ctx, merged.Content, appSlug)
+	if err != nil {
+		return ValidateResult{}, err
+	}
+	if !merged.HasIncludes() {
+		return result, nil
+	}
+	for i, e := range result.Errors {
+		result.Errors[i] = merged.Remap(e)
+	}
+	for i, w := range result.Warnings {
+		result.Warnings[i] = merged.Remap(w)
+	}
+	result.Files = merged.Files
+	return result, nil
+}
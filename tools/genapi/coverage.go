@@ -0,0 +1,260 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// clientMethod is a hand-written client method and the endpoint its doc
+// comment names, by the bitriseapi convention "Endpoint: GET /apps/{app-slug}.".
+type clientMethod struct {
+	Func   string
+	Method string
+	Path   string
+}
+
+var (
+	endpointDocRe = regexp.MustCompile(`Endpoint:\s+(GET|HEAD|POST|PUT|PATCH|DELETE)\s+(/\S*)`)
+	pathParamRe   = regexp.MustCompile(`{[^}]*}`)
+)
+
+// endpointKey normalizes an endpoint for matching: path parameters lose
+// their names, since the spec and the doc comments spell them differently.
+func endpointKey(method, path string) string {
+	path = strings.TrimRight(path, ".,;")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return method + " " + pathParamRe.ReplaceAllString(path, "{}")
+}
+
+// scanClient lists the exported *Client methods in dir whose doc comment
+// names their endpoint.
+func scanClient(dir string) ([]clientMethod, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var out []clientMethod
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil || !fn.Name.IsExported() || !isClientRecv(fn.Recv) {
+				continue
+			}
+			for _, m := range endpointDocRe.FindAllStringSubmatch(fn.Doc.Text(), -1) {
+				out = append(out, clientMethod{Func: fn.Name.Name, Method: m[1], Path: strings.TrimRight(m[2], ".,;")})
+			}
+		}
+	}
+	return out, nil
+}
+
+func isClientRecv(recv *ast.FieldList) bool {
+	if recv == nil || len(recv.List) != 1 {
+		return false
+	}
+	star, ok := recv.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	id, ok := star.X.(*ast.Ident)
+	return ok && id.Name == "Client"
+}
+
+// usage records, per package directory under the scanned roots, which
+// client methods it calls and which packages it imports.
+type usage struct {
+	calls   map[string]map[string]bool // method → calling package dirs
+	imports map[string]map[string]bool // package dir → imported paths
+}
+
+// scanUsage walks roots for calls of methods on a client-named value (
+// s.client.Apps, client.Apps) in files importing one of clientImports.
+// Test files and testdata are skipped.
+func scanUsage(roots []string, clientImports []string, methods map[string]bool) (*usage, error) {
+	u := &usage{calls: map[string]map[string]bool{}, imports: map[string]map[string]bool{}}
+	fset := token.NewFileSet()
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == "testdata" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+			if err != nil {
+				return err
+			}
+			dir := filepath.ToSlash(filepath.Dir(path))
+			if u.imports[dir] == nil {
+				u.imports[dir] = map[string]bool{}
+			}
+			importsClient := false
+			for _, imp := range f.Imports {
+				p, _ := strconv.Unquote(imp.Path.Value)
+				u.imports[dir][p] = true
+				importsClient = importsClient || slices.Contains(clientImports, p)
+			}
+			if !importsClient {
+				return nil
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				sel, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || !methods[sel.Sel.Name] || !clientNamed(sel.X) {
+					return true
+				}
+				if u.calls[sel.Sel.Name] == nil {
+					u.calls[sel.Sel.Name] = map[string]bool{}
+				}
+				u.calls[sel.Sel.Name][dir] = true
+				return true
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// clientNamed reports whether x is a value named like an API client.
+func clientNamed(x ast.Expr) bool {
+	var name string
+	switch x := x.(type) {
+	case *ast.Ident:
+		name = x.Name
+	case *ast.SelectorExpr:
+		name = x.Sel.Name
+	}
+	return strings.HasSuffix(strings.ToLower(name), "client")
+}
+
+// commands returns the command packages reaching method: those calling it
+// directly, or importing (transitively, through non-command packages) a
+// package that does. "cmd/rde/session" reads as "rde session"; shared
+// helpers such as cmd/cmdutil are listed by name, not expanded.
+func (u *usage) commands(method, module string) []string {
+	set := map[string]bool{}
+	seen := map[string]bool{}
+	queue := slices.Sorted(maps.Keys(u.calls[method]))
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if dir == "cmd" || strings.HasPrefix(dir, "cmd/") {
+			name := strings.ReplaceAll(strings.TrimPrefix(strings.TrimPrefix(dir, "cmd"), "/"), "/", " ")
+			set[cmp.Or(name, "(root)")] = true
+			continue
+		}
+		for importer, imps := range u.imports {
+			if imps[module+"/"+dir] {
+				queue = append(queue, importer)
+			}
+		}
+	}
+	return slices.Sorted(maps.Keys(set))
+}
+
+// coverageSection renders the report for one API.
+func coverageSection(t target, doc *document, gen map[string]string, hand []clientMethod, u *usage, module string) (string, error) {
+	eps, err := doc.endpoints()
+	if err != nil {
+		return "", err
+	}
+	handBy := map[string][]string{}
+	for _, h := range hand {
+		k := endpointKey(h.Method, h.Path)
+		handBy[k] = append(handBy[k], h.Func)
+	}
+
+	var (
+		rows        strings.Builder
+		nHand, nCmd int
+		specKeys    = map[string]bool{}
+	)
+	rows.WriteString("| Endpoint | Generated method | Hand-written method | Commands |\n|---|---|---|---|\n")
+	for _, ep := range eps {
+		k := endpointKey(ep.Method, ep.Path)
+		specKeys[k] = true
+		var cmds []string
+		fns := handBy[k]
+		for _, fn := range fns {
+			cmds = append(cmds, u.commands(fn, module)...)
+		}
+		genName := gen[ep.Method+" "+ep.Path]
+		if genName != "" {
+			cmds = append(cmds, u.commands(genName, module)...)
+		}
+		slices.Sort(cmds)
+		cmds = slices.Compact(cmds)
+		if len(fns) > 0 {
+			nHand++
+		}
+		if len(cmds) > 0 {
+			nCmd++
+		}
+		fmt.Fprintf(&rows, "| `%s %s` | %s | %s | %s |\n", ep.Method, ep.Path, code(genName), code(fns...), strings.Join(cmds, ", "))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", t.Name)
+	fmt.Fprintf(&b, "%s %s, package `%s`. %d endpoints: %d with a hand-written client method in `%s`, %d reachable from a command.\n\n",
+		doc.Info.Title, doc.Info.Version, t.OutDir, len(eps), nHand, t.ClientDir, nCmd)
+	b.WriteString(rows.String())
+
+	var drift []string
+	for _, h := range hand {
+		if !specKeys[endpointKey(h.Method, h.Path)] {
+			drift = append(drift, fmt.Sprintf("| `%s %s` | `%s` |\n", h.Method, h.Path, h.Func))
+		}
+	}
+	if len(drift) > 0 {
+		b.WriteString("\nHand-written methods naming an endpoint the document does not have:\n\n| Endpoint | Hand-written method |\n|---|---|\n")
+		b.WriteString(strings.Join(drift, ""))
+	}
+	return b.String(), nil
+}
+
+func code(names ...string) string {
+	var parts []string
+	for _, n := range names {
+		if n != "" {
+			parts = append(parts, "`"+n+"`")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
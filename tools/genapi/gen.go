@@ -0,0 +1,587 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// target is one API the generator produces a package for.
+type target struct {
+	Name   string // heading in the coverage report, e.g. "Bitrise API v0.1"
+	Spec   string // default spec location (URL or file)
+	OutDir string // package directory, relative to the module root
+	Pkg    string // package name
+
+	// ClientImport and ClientPkg name the hand-written client package the
+	// generated code sends its requests through (its RawRequest); ClientDir is
+	// where the coverage report looks for hand-written endpoint methods.
+	ClientImport string
+	ClientPkg    string
+	ClientDir    string
+}
+
+// generator renders one package: the models of every definition the
+// endpoints reach, and one client method per endpoint.
+type generator struct {
+	doc *document
+	t   target
+
+	types   map[string]string // Go type name → rendered declaration
+	pending []func() error    // definitions to render, queued while rendering others
+	named   map[string]string // definition name → Go type name
+	methods []string
+	names   map[string]string // "METHOD /path" → generated method name
+	skipped []string          // endpoints that could not be generated, with the reason
+}
+
+// generated is the output of generate.
+type generated struct {
+	Source  []byte            // gofmt'ed package source
+	Methods map[string]string // "METHOD /path" → method name
+	Skipped []string
+}
+
+// generate renders t's package from doc. source names the document in the
+// generated header.
+func generate(doc *document, t target, source string) (*generated, error) {
+	g := &generator{doc: doc, t: t, types: map[string]string{}, named: map[string]string{}, names: map[string]string{}}
+	eps, err := doc.endpoints()
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, ep := range eps {
+		name := methodName(ep.Op.OperationID, ep.Method, ep.Path)
+		if used[name] {
+			name = goName(ep.Op.OperationID + " " + ep.Method)
+		}
+		used[name] = true
+		if err := g.endpoint(ep, name); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", ep.Method, ep.Path, err)
+		}
+	}
+	for len(g.pending) > 0 {
+		next := g.pending[0]
+		g.pending = g.pending[1:]
+		if err := next(); err != nil {
+			return nil, err
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by tools/genapi from %s; DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "// Package %s holds the models and client methods generated from the\n// %s swagger document (%s %s).\n", t.Pkg, t.Name, doc.Info.Title, doc.Info.Version)
+	if len(g.skipped) > 0 {
+		b.WriteString("//\n// Endpoints not generated:\n")
+		for _, s := range g.skipped {
+			fmt.Fprintf(&b, "//   - %s\n", s)
+		}
+	}
+	fmt.Fprintf(&b, "package %s\n\n", t.Pkg)
+	fmt.Fprintf(&b, `import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	%q
+)
+
+// Client calls the generated endpoints through the hand-written client's
+// authenticated RawRequest, so base URL, auth, retries, and caching are
+// shared with the rest of the CLI.
+type Client struct {
+	raw *%s.Client
+}
+
+// New returns a Client sending its requests through raw.
+func New(raw *%[2]s.Client) *Client {
+	return &Client{raw: raw}
+}
+
+// do sends in (if non-nil) as the JSON body and decodes a 2xx response into
+// out (if non-nil). A non-2xx response is returned as the hand-written
+// client's API error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var (
+		body   io.Reader
+		header http.Header
+	)
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %%w", err)
+		}
+		body, header = bytes.NewReader(data), http.Header{"Content-Type": {"application/json"}}
+	}
+	resp, err := c.raw.RawRequest(ctx, method, path, query, header, body)
+	if err != nil {
+		return err
+	}
+	if err := resp.Err(); err != nil {
+		return err
+	}
+	if out == nil || len(resp.Body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Body, out); err != nil {
+		return fmt.Errorf("decode response: %%w", err)
+	}
+	return nil
+}
+`, t.ClientImport, t.ClientPkg)
+	for _, m := range g.methods {
+		b.WriteString("\n" + m)
+	}
+	for _, name := range slices.Sorted(maps.Keys(g.types)) {
+		b.WriteString("\n" + g.types[name])
+	}
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return &generated{Source: src, Methods: g.names, Skipped: g.skipped}, nil
+}
+
+// endpoint renders the client method for ep.
+func (g *generator) endpoint(ep endpoint, name string) error {
+	var (
+		args   []string // after ctx
+		query  []*parameter
+		body   *parameter
+		pathGo = ep.Path
+	)
+	for _, p := range ep.Params {
+		switch p.In {
+		case "path":
+			arg := argName(p.Name)
+			args = append(args, arg+" string")
+			pathGo = strings.Replace(pathGo, "{"+p.Name+"}", `" + url.PathEscape(`+arg+`) + "`, 1)
+		case "query":
+			query = append(query, p)
+		case "body":
+			body = p
+		case "formData":
+			g.skipped = append(g.skipped, fmt.Sprintf("%s %s (form-data body)", ep.Method, ep.Path))
+			return nil
+		}
+	}
+	pathExpr := strings.TrimSuffix(`"`+pathGo+`"`, ` + ""`)
+
+	var bodyArg string
+	if body != nil {
+		typ, err := g.goType(body.Schema, name+"Body")
+		if err != nil {
+			return err
+		}
+		bodyArg = "body"
+		args = append(args, "body "+pointerTo(typ))
+	}
+	var paramsType string
+	if len(query) > 0 {
+		paramsType = name + "Params"
+		if err := g.paramsType(paramsType, ep, query); err != nil {
+			return err
+		}
+		args = append(args, "params "+paramsType)
+	}
+
+	var result string
+	if s := successSchema(ep.Op); s != nil {
+		typ, err := g.goType(s, name+"Response")
+		if err != nil {
+			return err
+		}
+		result = typ
+	}
+
+	var b strings.Builder
+	writeDoc(&b, "", name+" calls "+ep.Method+" "+ep.Path+".", firstNonEmpty(ep.Op.Summary, ep.Op.Description))
+	if ep.Op.Deprecated {
+		b.WriteString("//\n// Deprecated: the API marks this endpoint deprecated.\n")
+	}
+	sig := strings.Join(append([]string{"ctx context.Context"}, args...), ", ")
+	if result != "" {
+		fmt.Fprintf(&b, "func (c *Client) %s(%s) (%s, error) {\n", name, sig, pointerTo(result))
+	} else {
+		fmt.Fprintf(&b, "func (c *Client) %s(%s) error {\n", name, sig)
+	}
+	queryArg := "nil"
+	if paramsType != "" {
+		queryArg = "params.values()"
+	}
+	in := "nil"
+	if bodyArg != "" {
+		in = bodyArg
+	}
+	method := "http.Method" + methodConst(ep.Method)
+	if result != "" {
+		fmt.Fprintf(&b, "\tvar out %s\n", result)
+		fmt.Fprintf(&b, "\tif err := c.do(ctx, %s, %s, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", method, pathExpr, queryArg, in)
+		ret := "out"
+		if pointerTo(result) != result {
+			ret = "&out"
+		}
+		fmt.Fprintf(&b, "\treturn %s, nil\n}\n", ret)
+	} else {
+		fmt.Fprintf(&b, "\treturn c.do(ctx, %s, %s, %s, %s, nil)\n}\n", method, pathExpr, queryArg, in)
+	}
+	g.methods = append(g.methods, b.String())
+	g.names[ep.Method+" "+ep.Path] = name
+	return nil
+}
+
+// paramsType renders the query-parameter struct of an endpoint and its
+// values method.
+func (g *generator) paramsType(name string, ep endpoint, query []*parameter) error {
+	var decl, enc strings.Builder
+	writeDoc(&decl, "", name+" are the query parameters of "+ep.Method+" "+ep.Path+".", "")
+	fmt.Fprintf(&decl, "type %s struct {\n", name)
+	fields := map[string]bool{}
+	for _, p := range query {
+		field := uniqueField(goName(p.Name), fields)
+		typ, err := g.goType(&schema{Type: p.Type, Format: p.Format, Items: p.Items}, name+field)
+		if err != nil {
+			return err
+		}
+		writeDoc(&decl, "\t", p.Description, "")
+		fmt.Fprintf(&decl, "\t%s %s\n", field, typ)
+
+		switch {
+		case strings.HasPrefix(typ, "[]"):
+			fmt.Fprintf(&enc, "\tfor _, v := range p.%s {\n\t\tq.Add(%q, fmt.Sprint(v))\n\t}\n", field, p.Name)
+		case typ == "bool":
+			fmt.Fprintf(&enc, "\tif p.%s {\n\t\tq.Set(%q, \"true\")\n\t}\n", field, p.Name)
+		case typ == "string":
+			fmt.Fprintf(&enc, "\tif p.%s != \"\" {\n\t\tq.Set(%q, p.%[1]s)\n\t}\n", field, p.Name)
+		default:
+			fmt.Fprintf(&enc, "\tif p.%s != 0 {\n\t\tq.Set(%q, fmt.Sprint(p.%[1]s))\n\t}\n", field, p.Name)
+		}
+	}
+	decl.WriteString("}\n\n")
+	fmt.Fprintf(&decl, "func (p %s) values() url.Values {\n\tq := url.Values{}\n%s\treturn q\n}\n", name, enc.String())
+	g.types[name] = decl.String()
+	return nil
+}
+
+// goType returns the Go type for s, queuing the declaration of any named
+// type it needs. ctxName names an inline object schema.
+func (g *generator) goType(s *schema, ctxName string) (string, error) {
+	if s == nil {
+		return "json.RawMessage", nil
+	}
+	if s.Ref != "" {
+		return g.ref(s.Ref)
+	}
+	if len(s.AllOf) == 1 && len(s.Properties) == 0 {
+		return g.goType(s.AllOf[0], ctxName)
+	}
+	switch {
+	case len(s.Properties) > 0 || len(s.AllOf) > 0:
+		if _, done := g.types[ctxName]; !done {
+			g.types[ctxName] = "" // reserve, so recursion terminates
+			decl, err := g.structType(ctxName, ctxName+" is an inline object of the document.", s)
+			if err != nil {
+				return "", err
+			}
+			g.types[ctxName] = decl
+		}
+		return ctxName, nil
+	case s.Type == "object":
+		if a := s.additional(); a != nil {
+			elem, err := g.goType(a, ctxName+"Value")
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + elem, nil
+		}
+		return "map[string]any", nil
+	case s.Type == "array":
+		elem, err := g.goType(s.Items, ctxName+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case s.Type == "string":
+		// Timestamps and grpc-gateway's string-encoded int64s stay strings,
+		// so one unexpected format can't fail a whole response.
+		return "string", nil
+	case s.Type == "integer" && s.Format == "int64":
+		return "int64", nil
+	case s.Type == "integer" && s.Format == "int32":
+		return "int32", nil
+	case s.Type == "integer":
+		return "int", nil
+	case s.Type == "number":
+		return "float64", nil
+	case s.Type == "boolean":
+		return "bool", nil
+	}
+	return "json.RawMessage", nil
+}
+
+// ref returns the Go type of a definition, queuing its declaration on first
+// use.
+func (g *generator) ref(ref string) (string, error) {
+	defName, s, err := g.doc.definition(ref)
+	if err != nil {
+		return "", err
+	}
+	if name, ok := g.named[defName]; ok {
+		return name, nil
+	}
+	name := goName(defName)
+	g.named[defName] = name
+	if len(s.Properties) == 0 && len(s.AllOf) == 0 {
+		// A named scalar, list, or map: declare it as such.
+		g.types[name] = ""
+		g.pending = append(g.pending, func() error {
+			under, err := g.goType(s, name+"Item")
+			if err != nil {
+				return err
+			}
+			var b strings.Builder
+			doc := firstNonEmpty(s.Description, s.Title)
+			if len(s.Enum) > 0 {
+				doc = strings.TrimSpace(doc + "\n\nOne of: " + enumList(s.Enum) + ".")
+			}
+			writeDoc(&b, "", name+" is the "+defName+" definition.", doc)
+			fmt.Fprintf(&b, "type %s %s\n", name, under)
+			g.types[name] = b.String()
+			return nil
+		})
+		return name, nil
+	}
+	g.types[name] = ""
+	g.pending = append(g.pending, func() error {
+		decl, err := g.structType(name, name+" is the "+defName+" definition.", s)
+		if err != nil {
+			return err
+		}
+		g.types[name] = decl
+		return nil
+	})
+	return name, nil
+}
+
+// structType renders the struct declaration of an object schema, merging
+// the properties of its allOf parts.
+func (g *generator) structType(name, lead string, s *schema) (string, error) {
+	props := map[string]*schema{}
+	var collect func(s *schema) error
+	collect = func(s *schema) error {
+		if s.Ref != "" {
+			_, def, err := g.doc.definition(s.Ref)
+			if err != nil {
+				return err
+			}
+			s = def
+		}
+		for _, part := range s.AllOf {
+			if err := collect(part); err != nil {
+				return err
+			}
+		}
+		maps.Copy(props, s.Properties)
+		return nil
+	}
+	if err := collect(s); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	writeDoc(&b, "", lead, firstNonEmpty(s.Description, s.Title))
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	fields := map[string]bool{}
+	for _, prop := range slices.Sorted(maps.Keys(props)) {
+		ps := props[prop]
+		field := uniqueField(goName(prop), fields)
+		typ, err := g.goType(ps, name+field)
+		if err != nil {
+			return "", err
+		}
+		if g.isStruct(ps, name+field) {
+			typ = pointerTo(typ)
+		}
+		doc := ps.Description
+		if len(ps.Enum) > 0 {
+			doc = strings.TrimSpace(doc + " One of: " + enumList(ps.Enum) + ".")
+		}
+		writeDoc(&b, "\t", doc, "")
+		fmt.Fprintf(&b, "\t%s %s `json:\"%s,omitempty\"`\n", field, typ, prop)
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// isStruct reports whether s renders as a struct type (fields of those are
+// pointers, so an unset one is omitted from request bodies).
+func (g *generator) isStruct(s *schema, ctxName string) bool {
+	if s.Ref != "" {
+		_, def, err := g.doc.definition(s.Ref)
+		return err == nil && (len(def.Properties) > 0 || len(def.AllOf) > 0)
+	}
+	_, inline := g.types[ctxName]
+	return inline && (len(s.Properties) > 0 || len(s.AllOf) > 0)
+}
+
+// successSchema returns the schema of the first 2xx response that has one.
+func successSchema(op *operation) *schema {
+	for _, code := range slices.Sorted(maps.Keys(op.Responses)) {
+		if strings.HasPrefix(code, "2") && op.Responses[code] != nil && op.Responses[code].Schema != nil {
+			return op.Responses[code].Schema
+		}
+	}
+	return nil
+}
+
+func pointerTo(typ string) string {
+	if strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") || typ == "json.RawMessage" || typ == "any" {
+		return typ
+	}
+	return "*" + typ
+}
+
+func methodConst(m string) string {
+	return m[:1] + strings.ToLower(m[1:])
+}
+
+// methodName derives a client method name from an operationId: "app-list"
+// becomes AppList, and grpc-gateway's "Service_ListSessions" becomes
+// ListSessions. Without an operationId it is built from method and path.
+func methodName(opID, method, path string) string {
+	if opID == "" {
+		return goName(strings.ToLower(method) + " " + path)
+	}
+	if i := strings.LastIndex(opID, "_"); i >= 0 && i < len(opID)-1 {
+		opID = opID[i+1:]
+	}
+	return goName(opID)
+}
+
+// initialisms are written in upper case, per Go naming conventions.
+var initialisms = map[string]string{
+	"api": "API", "cpu": "CPU", "html": "HTML", "http": "HTTP", "https": "HTTPS",
+	"id": "ID", "ids": "IDs", "ip": "IP", "json": "JSON", "os": "OS", "sha": "SHA",
+	"ssh": "SSH", "ttl": "TTL", "ui": "UI", "uri": "URI", "url": "URL", "urls": "URLs",
+	"uuid": "UUID", "vnc": "VNC", "xml": "XML",
+}
+
+var wordRe = regexp.MustCompile(`[A-Z]+[a-z0-9]*|[a-z0-9]+`)
+
+// goName turns an identifier from the spec (kebab, snake, camel, or dotted)
+// into an exported Go name.
+func goName(s string) string {
+	var b strings.Builder
+	for _, w := range wordRe.FindAllString(splitAcronyms(s), -1) {
+		if up, ok := initialisms[strings.ToLower(w)]; ok {
+			b.WriteString(up)
+			continue
+		}
+		if w == strings.ToUpper(w) {
+			b.WriteString(w) // an acronym, or digits
+			continue
+		}
+		r := []rune(strings.ToLower(w))
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+// splitAcronyms separates an upper-case run from a following word
+// ("HTTPServer" → "HTTP Server") so wordRe sees both.
+func splitAcronyms(s string) string {
+	r := []rune(s)
+	var b strings.Builder
+	for i, c := range r {
+		if i > 0 && i+1 < len(r) && unicode.IsUpper(c) && unicode.IsUpper(r[i-1]) && unicode.IsLower(r[i+1]) {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// reservedArgs are identifiers the generated methods already use.
+var reservedArgs = map[string]bool{
+	"c": true, "ctx": true, "body": true, "params": true, "out": true,
+	"url": true, "http": true, "json": true, "fmt": true,
+}
+
+// argName is goName with a lower-case first word, for parameters.
+func argName(s string) string {
+	name := goName(s)
+	for i, w := range wordRe.FindAllString(splitAcronyms(name), -1) {
+		if i == 0 {
+			name = strings.ToLower(w) + name[len(w):]
+		}
+	}
+	if token.IsKeyword(name) || reservedArgs[name] {
+		name += "Param"
+	}
+	return name
+}
+
+func uniqueField(name string, seen map[string]bool) string {
+	base := name
+	for i := 2; seen[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	seen[name] = true
+	return name
+}
+
+func enumList(vals []any) string {
+	parts := make([]string, len(vals))
+	for i, v := range vals {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// writeDoc writes a comment: the lead sentence, then text, each line
+// prefixed with indent.
+func writeDoc(b *strings.Builder, indent, lead, text string) {
+	var lines []string
+	if lead != "" {
+		lines = append(lines, lead)
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		if lead != "" {
+			lines = append(lines, "")
+		}
+		for l := range strings.SplitSeq(text, "\n") {
+			lines = append(lines, strings.TrimRight(l, " \t\r"))
+		}
+	}
+	for _, l := range lines {
+		if l == "" {
+			fmt.Fprintf(b, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(b, "%s// %s\n", indent, l)
+	}
+}
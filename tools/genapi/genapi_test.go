@@ -0,0 +1,140 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func loadFixture(t *testing.T) *document {
+	t.Helper()
+	data, err := os.ReadFile("testdata/swagger.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := parseDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestGenerate(t *testing.T) {
+	gen, err := generate(loadFixture(t), v01, "testdata/swagger.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(gen.Source)
+	for _, want := range []string{
+		"package v01gen",
+		`"github.com/bitrise-io/bitrise-cli/bitriseapi"`,
+		"func (c *Client) AppList(ctx context.Context, params AppListParams) (*V0AppListResponseModel, error) {",
+		// Path-level $ref parameters become positional arguments.
+		`func (c *Client) AppShow(ctx context.Context, appSlug string) (*V0AppResponseModel, error) {`,
+		`"/apps/"+url.PathEscape(appSlug)+"/builds/"+url.PathEscape(buildSlug)+"/abort"`,
+		"func (c *Client) BuildAbort(ctx context.Context, appSlug string, buildSlug string, body *V0BuildAbortParams) (*BuildAbortResponse, error) {",
+		"func (c *Client) AppDelete(ctx context.Context, appSlug string) error {",
+		"// Deprecated: the API marks this endpoint deprecated.",
+		`q.Add("project_type", fmt.Sprint(v))`,
+		// Slices are returned as they are, not through a pointer.
+		"func (c *Client) StepSearch(ctx context.Context) ([]string, error) {",
+		"\treturn out, nil\n",
+		// Nested objects are pointers, so unset ones are omitted.
+		"Owner    *V0AppModelOwner  `json:\"owner,omitempty\"`",
+		"RepoURL  string            `json:\"repo_url,omitempty\"`",
+		"Meta     map[string]string `json:\"meta,omitempty\"`",
+		// allOf parts are merged into one struct.
+		"AbortReason       string `json:\"abort_reason,omitempty\"`",
+		"SkipNotifications bool   `json:\"skip_notifications,omitempty\"`",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source is missing %q", want)
+		}
+	}
+	if want := []string{"POST /apps/{app-slug}/avatar (form-data body)"}; !slices.Equal(gen.Skipped, want) {
+		t.Errorf("Skipped = %q, want %q", gen.Skipped, want)
+	}
+	if gen.Methods["GET /apps/{app-slug}"] != "AppShow" {
+		t.Errorf("Methods = %v, want GET /apps/{app-slug} → AppShow", gen.Methods)
+	}
+	typeCheck(t, gen.Source)
+}
+
+// typeCheck compiles a generated package against the module's real
+// packages, so a change that emits valid-looking but broken Go (a wrong
+// RawRequest signature, an unused import) fails here rather than in
+// `make api`.
+func typeCheck(t *testing.T, src []byte) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, outFile, src, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatalf("generated source does not parse: %v", err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check(f.Name.Name, fset, []*ast.File{f}, nil); err != nil {
+		t.Errorf("generated source does not compile: %v", err)
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"app-list":                            "AppList",
+		"v0.AppResponseModel":                 "V0AppResponseModel",
+		"repo_url":                            "RepoURL",
+		"workspaceId":                         "WorkspaceID",
+		"RDEService_ListSessions":             "RDEServiceListSessions",
+		"v1GetSessionVNCResponse":             "V1GetSessionVNCResponse",
+		"sessions:delete-terminated":          "SessionsDeleteTerminated",
+		"2fa":                                 "X2fa",
+		"HTTPServer":                          "HTTPServer",
+		"build_trigger_params.hook_info.type": "BuildTriggerParamsHookInfoType",
+	} {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := methodName("RDEService_ListSessions", "GET", "/x"); got != "ListSessions" {
+		t.Errorf("methodName = %q, want ListSessions", got)
+	}
+	if got := argName("type"); got != "typeParam" {
+		t.Errorf("argName(type) = %q, want typeParam", got)
+	}
+}
+
+func TestCoverageSection(t *testing.T) {
+	hand, err := scanClient("../../bitriseapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(hand, clientMethod{Func: "App", Method: "GET", Path: "/apps/{app-slug}"}) {
+		t.Fatalf("scanClient did not find App's endpoint: %v", hand)
+	}
+
+	u := &usage{
+		calls:   map[string]map[string]bool{"App": {"internal/app": true}},
+		imports: map[string]map[string]bool{"cmd/app": {module + "/internal/app": true}, "internal/app": {}},
+	}
+	doc := loadFixture(t)
+	section, err := coverageSection(v01, doc, map[string]string{"GET /apps/{app-slug}": "AppShow"}, hand, u, module)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"6 endpoints: 4 with a hand-written client method in `bitriseapi`, 1 reachable from a command.",
+		"| `GET /apps/{app-slug}` | `AppShow` | `App` | app |",
+		"| `DELETE /apps/{app-slug}` |  |  |  |",
+		// Documented by the client, missing from the (fixture) document.
+		"| `GET /me` | `Me` |",
+	} {
+		if !strings.Contains(section, want) {
+			t.Errorf("report is missing %q:\n%s", want, section)
+		}
+	}
+}
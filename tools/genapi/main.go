@@ -0,0 +1,120 @@
+// Command genapi generates typed models and client methods from the
+// swagger documents of the Bitrise API (v0.1) and the Remote Dev
+// Environments API, and writes a coverage report of which endpoints the CLI
+// exposes as commands.
+//
+// Run it via `make api` from the module root. It reads the copies of both
+// documents vendored under tools/genapi/specs, so it runs offline and the
+// committed output only changes when a spec or the generator does; pass
+// -v01 / -rde to generate from another file or URL, and see `make
+// api-update` for refreshing the vendored copies.
+// Each API gets a package under bitriseapi/gen whose Client sends requests
+// through the hand-written client's RawRequest, so auth, retries, caching,
+// and error parsing are shared with the rest of the CLI. Wiring a new
+// endpoint into a command then means calling the generated method rather
+// than hand-rolling its JSON tags.
+//
+// The report (docs/api-coverage.md) lists every endpoint with its generated
+// method, the hand-written bitriseapi method documenting it ("Endpoint: GET
+// /apps."), and the command packages that reach that method.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	module     = "github.com/bitrise-io/bitrise-cli"
+	reportPath = "docs/api-coverage.md"
+	outFile    = "generated.go"
+)
+
+var (
+	v01 = target{
+		Name:         "Bitrise API v0.1",
+		Spec:         "tools/genapi/specs/v0.1.json",
+		OutDir:       "bitriseapi/gen/v01gen",
+		Pkg:          "v01gen",
+		ClientImport: module + "/bitriseapi",
+		ClientPkg:    "bitriseapi",
+		ClientDir:    "bitriseapi",
+	}
+	rde = target{
+		Name:         "Remote Dev Environments API",
+		Spec:         "tools/genapi/specs/rde.json",
+		OutDir:       "bitriseapi/gen/rdegen",
+		Pkg:          "rdegen",
+		ClientImport: module + "/bitriseapi/rde",
+		ClientPkg:    "rde",
+		ClientDir:    "bitriseapi/rde",
+	}
+)
+
+func main() {
+	flag.StringVar(&v01.Spec, "v01", v01.Spec, "v0.1 swagger document (URL or file)")
+	flag.StringVar(&rde.Spec, "rde", rde.Spec, "RDE swagger document (URL or file)")
+	flag.Parse()
+	if err := run(context.Background(), []target{v01, rde}); err != nil {
+		fmt.Fprintln(os.Stderr, "genapi:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, targets []target) error {
+	var report strings.Builder
+	report.WriteString("# API coverage\n\n")
+	report.WriteString("<!-- generated by `make api`; do not edit by hand -->\n\n")
+	report.WriteString("Every endpoint of the swagger documents vendored under `tools/genapi/specs`, with the method `tools/genapi` generated for it, the hand-written client method whose doc comment names it, and the command packages that reach either.\n")
+	for _, t := range targets {
+		doc, err := loadDocument(ctx, t.Spec)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.Name, err)
+		}
+		gen, err := generate(doc, t, t.Spec)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.Name, err)
+		}
+		if err := os.MkdirAll(t.OutDir, 0o750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(t.OutDir, outFile), gen.Source, 0o600); err != nil { //nolint:gosec // OutDir is a package-level target, not user input
+			return err
+		}
+		for _, s := range gen.Skipped {
+			fmt.Fprintf(os.Stderr, "genapi: %s: skipped %s\n", t.Name, s)
+		}
+
+		section, err := coverage(t, doc, gen.Methods)
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.Name, err)
+		}
+		report.WriteString("\n" + section)
+	}
+	return os.WriteFile(reportPath, []byte(report.String()), 0o600)
+}
+
+// coverage scans the hand-written client and the CLI's packages and
+// renders t's report section.
+func coverage(t target, doc *document, genMethods map[string]string) (string, error) {
+	hand, err := scanClient(t.ClientDir)
+	if err != nil {
+		return "", err
+	}
+	methods := map[string]bool{}
+	for _, h := range hand {
+		methods[h.Func] = true
+	}
+	for _, m := range genMethods {
+		methods[m] = true
+	}
+	u, err := scanUsage([]string{"internal", "cmd"}, []string{t.ClientImport, module + "/" + t.OutDir}, methods)
+	if err != nil {
+		return "", err
+	}
+	return coverageSection(t, doc, genMethods, hand, u, module)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is the subset of a Swagger 2.0 document the generator reads. Both
+// the v0.1 API and the RDE API (grpc-gateway) publish Swagger 2.0.
+type document struct {
+	Swagger string `json:"swagger"`
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	BasePath    string                `json:"basePath"`
+	Paths       map[string]pathItem   `json:"paths"`
+	Definitions map[string]*schema    `json:"definitions"`
+	Parameters  map[string]*parameter `json:"parameters"`
+}
+
+type pathItem struct {
+	Get        *operation   `json:"get"`
+	Put        *operation   `json:"put"`
+	Post       *operation   `json:"post"`
+	Delete     *operation   `json:"delete"`
+	Patch      *operation   `json:"patch"`
+	Head       *operation   `json:"head"`
+	Options    *operation   `json:"options"`
+	Parameters []*parameter `json:"parameters"`
+}
+
+type operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Tags        []string             `json:"tags"`
+	Deprecated  bool                 `json:"deprecated"`
+	Parameters  []*parameter         `json:"parameters"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query, header, body, formData
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Type        string  `json:"type"`
+	Format      string  `json:"format"`
+	Items       *schema `json:"items"`
+	Schema      *schema `json:"schema"` // in: body
+}
+
+type response struct {
+	Description string  `json:"description"`
+	Schema      *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Title                string             `json:"title"`
+	Description          string             `json:"description"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	AllOf                []*schema          `json:"allOf"`
+	Enum                 []any              `json:"enum"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+}
+
+// additional returns the schema of additionalProperties, if it is one (it
+// may also be a bare true/false).
+func (s *schema) additional() *schema {
+	if len(s.AdditionalProperties) == 0 || s.AdditionalProperties[0] != '{' {
+		return nil
+	}
+	var a schema
+	if json.Unmarshal(s.AdditionalProperties, &a) != nil {
+		return nil
+	}
+	return &a
+}
+
+// endpoint is one operation of the document, with path-level and $ref
+// parameters resolved.
+type endpoint struct {
+	Method string // upper case
+	Path   string // as in the document, relative to basePath
+	Op     *operation
+	Params []*parameter
+}
+
+// loadDocument reads a Swagger document, JSON or YAML, from a file or an
+// http(s) URL.
+func loadDocument(ctx context.Context, src string) (*document, error) {
+	var data []byte
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req) //nolint:gosec // the spec URL is a maintainer-supplied flag
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s: %w", src, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch %s: HTTP %d", src, resp.StatusCode)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, fmt.Errorf("fetch %s: %w", src, err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(src); err != nil { //nolint:gosec // the spec path is a maintainer-supplied flag
+			return nil, err
+		}
+	}
+	return parseDocument(data)
+}
+
+func parseDocument(data []byte) (*document, error) {
+	if t := bytes.TrimSpace(data); len(t) > 0 && t[0] != '{' {
+		var generic any
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, fmt.Errorf("parse spec: %w", err)
+		}
+		var err error
+		if data, err = json.Marshal(generic); err != nil {
+			return nil, fmt.Errorf("parse spec: %w", err)
+		}
+	}
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+	if doc.Swagger != "2.0" {
+		return nil, fmt.Errorf("unsupported spec version (swagger %q, openapi %q): only Swagger 2.0 is supported", doc.Swagger, doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// endpoints lists every operation, sorted by path, then method.
+func (d *document) endpoints() ([]endpoint, error) {
+	var out []endpoint
+	for path, item := range d.Paths {
+		for _, m := range []struct {
+			method string
+			op     *operation
+		}{
+			{http.MethodGet, item.Get}, {http.MethodPut, item.Put}, {http.MethodPost, item.Post},
+			{http.MethodDelete, item.Delete}, {http.MethodPatch, item.Patch},
+			{http.MethodHead, item.Head}, {http.MethodOptions, item.Options},
+		} {
+			if m.op == nil {
+				continue
+			}
+			params, err := d.params(item.Parameters, m.op.Parameters)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", m.method, path, err)
+			}
+			out = append(out, endpoint{Method: m.method, Path: path, Op: m.op, Params: params})
+		}
+	}
+	slices.SortFunc(out, func(a, b endpoint) int {
+		return cmp.Or(strings.Compare(a.Path, b.Path), strings.Compare(a.Method, b.Method))
+	})
+	return out, nil
+}
+
+// params merges path-level and operation-level parameters (the latter win)
+// and resolves $refs to the document's shared parameters.
+func (d *document) params(pathLevel, opLevel []*parameter) ([]*parameter, error) {
+	var out []*parameter
+	for _, p := range slices.Concat(pathLevel, opLevel) {
+		if p.Ref != "" {
+			name, ok := strings.CutPrefix(p.Ref, "#/parameters/")
+			if !ok || d.Parameters[name] == nil {
+				return nil, fmt.Errorf("unresolvable parameter %q", p.Ref)
+			}
+			p = d.Parameters[name]
+		}
+		if i := slices.IndexFunc(out, func(q *parameter) bool { return q.Name == p.Name && q.In == p.In }); i >= 0 {
+			out[i] = p
+			continue
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// definition resolves a "#/definitions/Name" reference.
+func (d *document) definition(ref string) (string, *schema, error) {
+	name, ok := strings.CutPrefix(ref, "#/definitions/")
+	if !ok || d.Definitions[name] == nil {
+		return "", nil, fmt.Errorf("unresolvable schema %q", ref)
+	}
+	return name, d.Definitions[name], nil
+}